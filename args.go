@@ -28,6 +28,7 @@ type Args struct {
 	SingleTransaction bool
 	Variables         []string
 	PVariables        []string
+	OnError           string
 }
 
 func (args *Args) Next() (string, bool, error) {
@@ -98,6 +99,7 @@ func NewArgs() *Args {
 	kingpin.Flag("out", "output file").Short('o').StringVar(&args.Out)
 	kingpin.Flag("password", "force password prompt (should happen automatically)").Short('W').BoolVar(&args.ForcePassword)
 	kingpin.Flag("single-transaction", "execute as a single transaction (if non-interactive)").Short('1').BoolVar(&args.SingleTransaction)
+	kingpin.Flag("on-error", "stop at, or continue past, a failed command or file").PlaceHolder("stop|continue").EnumVar(&args.OnError, "stop", "continue")
 	kingpin.Flag("set", "set variable NAME to VALUE").Short('v').PlaceHolder(", --variable=NAME=VALUE").StringsVar(&args.Variables)
 	// pset
 	kingpin.Flag("pset", `set printing option VAR to ARG (see \pset command)`).Short('P').PlaceHolder("VAR[=ARG]").StringsVar(&args.PVariables)
@@ -145,6 +147,14 @@ func NewArgs() *Args {
 		os.Exit(0)
 		return nil
 	}).Bool()
+	// complete connections with __complete in the shell completion scripts
+	kingpin.BashCompletionTemplate = text.BashCompletionScript()
+	kingpin.ZshCompletionTemplate = text.ZshCompletionScript()
+	kingpin.Flag("completion-script-fish", "Generate completion script for fish.").Hidden().PreAction(func(*kingpin.ParseContext) error {
+		fmt.Fprint(os.Stdout, text.FishCompletionScript())
+		os.Exit(0)
+		return nil
+	}).Bool()
 	// hide help flag
 	kingpin.HelpFlag.Short('h').Hidden()
 	// parse
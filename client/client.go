@@ -0,0 +1,238 @@
+// Package client provides an API for embedding usql in Go programs, opening
+// database urls, executing statements, and streaming or rendering their
+// results with usql's output encoders, without the interactive shell.
+//
+// Drivers are registered by importing their packages, as usql does:
+//
+//	import (
+//		"github.com/ildus/usql/client"
+//		_ "github.com/ildus/usql/drivers/postgres"
+//	)
+//
+//	c, err := client.Open(ctx, "pg://user:pass@localhost/db")
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	res, err := c.Exec(ctx, "SELECT * FROM authors")
+//	if err != nil {
+//		return err
+//	}
+//	defer res.Close()
+//	return res.Rows.Encode(os.Stdout, map[string]string{"format": "csv"})
+package client
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/dburl/credential"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/stmt"
+)
+
+// Conn is a database connection.
+type Conn struct {
+	u  *dburl.URL
+	db *sql.DB
+}
+
+// Option is a connection option.
+type Option func(*options)
+
+// options are the connection options.
+type options struct {
+	stdout, stderr io.Writer
+}
+
+// WithOutput is a connection option to set the writers used by drivers
+// writing messages (such as the notices of PostgreSQL), defaulting to
+// os.Stdout and os.Stderr.
+func WithOutput(stdout, stderr io.Writer) Option {
+	return func(o *options) {
+		o.stdout, o.stderr = stdout, stderr
+	}
+}
+
+// Open opens a connection to the database url, resolving the url shorthands
+// and credentials as usql does.
+func Open(ctx context.Context, urlstr string, opts ...Option) (*Conn, error) {
+	urlstr, err := dburl.Resolve(ctx, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	u, err := dburl.Parse(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	drivers.ForceParams(u)
+	return OpenURL(ctx, u, opts...)
+}
+
+// OpenURL opens a connection to the parsed database url.
+func OpenURL(ctx context.Context, u *dburl.URL, opts ...Option) (*Conn, error) {
+	o := options{stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ru, err := credential.Resolve(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	stdout := func() io.Writer { return o.stdout }
+	stderr := func() io.Writer { return o.stderr }
+	db, err := drivers.Open(ctx, ru, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	if err := drivers.Ping(ctx, u, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Conn{u: u, db: db}, nil
+}
+
+// URL returns the database url of the connection.
+func (c *Conn) URL() *dburl.URL {
+	return c.u
+}
+
+// DB returns the database of the connection.
+func (c *Conn) DB() *sql.DB {
+	return c.db
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.db.Close()
+}
+
+// Statements splits sqlstr into statements, using the statement parsing rules
+// of the driver.
+func (c *Conn) Statements(sqlstr string) ([]string, error) {
+	return drivers.Statements(c.u, strings.NewReader(sqlstr), nil)
+}
+
+// Result is the result of an executed statement.
+type Result struct {
+	// Command is the type of the statement, such as SELECT or INSERT.
+	Command string
+	// RowsAffected is the number of rows affected by a statement not
+	// returning rows.
+	RowsAffected int64
+	// Rows are the rows returned by a query, and are nil for other
+	// statements.
+	Rows *Rows
+}
+
+// Close closes the rows of the result, if any.
+func (r *Result) Close() error {
+	if r.Rows == nil {
+		return nil
+	}
+	return r.Rows.Close()
+}
+
+// Exec executes a single statement, with the args as its query parameters.
+// The rows of a query must be closed after use, and are canceled using the
+// driver's native cancellation when ctx is done.
+func (c *Conn) Exec(ctx context.Context, sqlstr string, args ...interface{}) (*Result, error) {
+	typ, sqlstr, qtyp, err := drivers.Process(c.u, stmt.FindPrefix(sqlstr, true, true, true), sqlstr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, stop := drivers.WithCancel(ctx, c.u, c.db, conn)
+	closeConn := func() {
+		stop()
+		conn.Close()
+	}
+	if !qtyp {
+		defer closeConn()
+		r, err := conn.ExecContext(ctx, sqlstr, args...)
+		if err != nil {
+			return nil, err
+		}
+		count, err := drivers.RowsAffected(c.u, r)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Command: typ, RowsAffected: count}, nil
+	}
+	rows, err := conn.QueryContext(ctx, sqlstr, args...)
+	if err != nil {
+		closeConn()
+		return nil, err
+	}
+	return &Result{
+		Command: typ,
+		Rows:    &Rows{Rows: rows, u: c.u, close: closeConn},
+	}, nil
+}
+
+// Rows are the rows returned by a query, read as with sql.Rows.
+type Rows struct {
+	*sql.Rows
+	u     *dburl.URL
+	close func()
+}
+
+// Columns returns the column names, as displayed by usql.
+func (r *Rows) Columns() ([]string, error) {
+	return drivers.Columns(r.u, r.Rows)
+}
+
+// Values returns the values of the current row, as returned by the driver.
+func (r *Rows) Values() ([]interface{}, error) {
+	cols, err := r.Rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := r.Rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// Close closes the rows, releasing their connection.
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.close != nil {
+		r.close()
+		r.close = nil
+	}
+	return err
+}
+
+// Encode writes the rows (and any following result sets) to w, formatted
+// with usql's output encoders. params are the display settings (see \pset)
+// overriding the current settings, with format selecting the encoder.
+func (r *Rows) Encode(w io.Writer, params map[string]string) error {
+	p := env.Pall()
+	for k, v := range params {
+		p[k] = v
+	}
+	if p["expanded"] == "auto" {
+		p["expanded"] = "off"
+	}
+	if drivers.LowerColumnNames(r.u) {
+		p["lower_column_names"] = "true"
+	}
+	if drivers.UseColumnTypes(r.u) {
+		p["use_column_types"] = "true"
+	}
+	return env.EncodeAll(w, r.Rows, p)
+}
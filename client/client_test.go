@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "github.com/ildus/usql/drivers/sqlite3"
+)
+
+func TestConn(t *testing.T) {
+	ctx := context.Background()
+	c, err := Open(ctx, "sqlite3:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer c.Close()
+	stmts, err := c.Statements("CREATE TABLE t (a INTEGER, b TEXT);\nINSERT INTO t VALUES (1, 'x;y'), (2, NULL);")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got: %d", len(stmts))
+	}
+	var affected []int64
+	for _, s := range stmts {
+		res, err := c.Exec(ctx, s)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if res.Rows != nil {
+			t.Fatalf("expected no rows for %q", s)
+		}
+		affected = append(affected, res.RowsAffected)
+	}
+	if exp := []int64{0, 2}; !reflect.DeepEqual(affected, exp) {
+		t.Errorf("expected rows affected %v, got: %v", exp, affected)
+	}
+	// typed row stream
+	res, err := c.Exec(ctx, "SELECT a, b FROM t WHERE a > ? ORDER BY a", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if res.Command != "SELECT" || res.Rows == nil {
+		t.Fatalf("expected SELECT with rows, got: %s", res.Command)
+	}
+	var rows [][]interface{}
+	for res.Rows.Next() {
+		vals, err := res.Rows.Values()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		rows = append(rows, vals)
+	}
+	if err := res.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := [][]interface{}{{int64(1), "x;y"}, {int64(2), nil}}; !reflect.DeepEqual(rows, exp) {
+		t.Errorf("expected rows %v, got: %v", exp, rows)
+	}
+	// encoded
+	res, err = c.Exec(ctx, "SELECT a, b FROM t ORDER BY a")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer res.Close()
+	var sb strings.Builder
+	if err := res.Rows.Encode(&sb, map[string]string{"format": "csv"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s, exp := strings.TrimSpace(sb.String()), "a,b\n1,x;y\n2,"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/dburl/passfile"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/handler"
+	"github.com/ildus/usql/text"
+)
+
+// maxRecentConns is the maximum number of recently used connections
+// completed.
+const maxRecentConns = 20
+
+// fileDrivers are the drivers of database urls completed with file paths.
+var fileDrivers = map[string]bool{
+	"csvq":          true,
+	"duckdb":        true,
+	"genji":         true,
+	"moderncsqlite": true,
+	"ql":            true,
+	"sqlite3":       true,
+}
+
+// connectRE matches \c and \connect commands in the history file.
+var connectRE = regexp.MustCompile(`^\s*\\c(?:onnect)?\s+(\S+)`)
+
+// complete writes the completions of the last of args (the word being
+// completed) for the shell completion scripts, one per line: the file paths
+// of file database urls (such as sqlite3:path), or the connection strings of
+// the passfile entries and the recently used connections.
+func complete(args []string, u *user.User) error {
+	var word string
+	if len(args) != 0 {
+		word = args[len(args)-1]
+	}
+	var completions []string
+	if scheme, path, ok := strings.Cut(word, ":"); ok && !strings.HasPrefix(path, "//") && fileScheme(scheme) {
+		completions = completeFiles(scheme+":", path)
+	} else {
+		entries, _ := passfile.ParseFile(passfile.Path(u.HomeDir, text.PassfileName))
+		seen := make(map[string]bool)
+		for _, s := range append(recentConns(u), handler.PassfileConnStrings(entries)...) {
+			if !seen[s] && strings.HasPrefix(s, word) {
+				completions, seen[s] = append(completions, s), true
+			}
+		}
+	}
+	for _, s := range completions {
+		fmt.Fprintln(os.Stdout, s)
+	}
+	return nil
+}
+
+// fileScheme returns true when the scheme is a file database url scheme.
+func fileScheme(scheme string) bool {
+	driver, _ := dburl.SchemeDriverAndAliases(strings.ToLower(scheme))
+	return fileDrivers[driver]
+}
+
+// completeFiles returns the file paths starting with path, prefixed with the
+// scheme. Directories end with a path separator.
+func completeFiles(scheme, path string) []string {
+	matches, _ := filepath.Glob(globEscape(path) + "*")
+	sort.Strings(matches)
+	completions := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if fi, err := os.Stat(match); err == nil && fi.IsDir() {
+			match += string(filepath.Separator)
+		}
+		completions = append(completions, scheme+match)
+	}
+	return completions
+}
+
+// globEscape escapes the glob meta characters in path.
+func globEscape(path string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(path)
+}
+
+// recentConns returns the connections most recently opened with \connect,
+// from the history file, most recent first.
+func recentConns(u *user.User) []string {
+	f, err := os.Open(env.HistoryFile(u))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var conns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if m := connectRE.FindStringSubmatch(s.Text()); m != nil {
+			conns = append(conns, m[1])
+		}
+	}
+	recent := make([]string, 0, maxRecentConns)
+	seen := make(map[string]bool)
+	for i := len(conns) - 1; i >= 0 && len(recent) < maxRecentConns; i-- {
+		if !seen[conns[i]] {
+			recent, seen[conns[i]] = append(recent, conns[i]), true
+		}
+	}
+	return recent
+}
@@ -0,0 +1,134 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// AccessTokenParam is the query parameter containing the access token used to
+// authenticate with Azure SQL.
+const AccessTokenParam = "accesstoken"
+
+// azureSQLResource is the resource of Azure SQL access tokens.
+const azureSQLResource = "https://database.windows.net/"
+
+// azureIMDSURL is the URL of the Azure Instance Metadata Service token
+// endpoint.
+var azureIMDSURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// AzureSQL resolves azsql://[USER[:PASSWORD]@]SERVER[/DBNAME] URLs, where
+// SERVER is the name of an Azure SQL server (or its fully qualified host
+// name).
+//
+// When no user is provided, a Microsoft Entra ID access token is retrieved
+// using the Azure CLI (az account get-access-token), or the managed identity
+// of the Azure host (using AZURE_CLIENT_ID, when set, to select a user
+// assigned identity), and set as the accesstoken query parameter.
+func AzureSQL(ctx context.Context, urlstr string) (string, error) {
+	s, err := parse(urlstr)
+	if err != nil {
+		return "", err
+	}
+	host, port, err := net.SplitHostPort(s.Host)
+	if err != nil {
+		host, port = s.Host, ""
+	}
+	if !strings.Contains(host, ".") {
+		host += ".database.windows.net"
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	s.setDefault("encrypt", "true")
+	if s.User == nil && !s.Query.Has(AccessTokenParam) {
+		token, err := azureToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		s.Query.Set(AccessTokenParam, token)
+	}
+	return s.String("sqlserver", host, 0), nil
+}
+
+// azureToken retrieves an Azure SQL access token, using the Azure CLI or the
+// managed identity of the host.
+func azureToken(ctx context.Context) (string, error) {
+	token, cliErr := azureCLIToken(ctx)
+	if cliErr == nil {
+		return token, nil
+	}
+	token, err := azureIMDSToken(ctx)
+	if err == nil {
+		return token, nil
+	}
+	return "", fmt.Errorf("%w: azure cli: %v, managed identity: %v", ErrNoToken, cliErr, err)
+}
+
+// azureCLIToken retrieves an access token using the Azure CLI.
+func azureCLIToken(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "az", "account", "get-access-token", "--resource", azureSQLResource, "--query", "accessToken", "--output", "tsv")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	buf, err := cmd.Output()
+	switch {
+	case err != nil && stderr.Len() != 0:
+		return "", fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	case err != nil:
+		return "", err
+	}
+	token := string(bytes.TrimSpace(buf))
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+// azureIMDSToken retrieves an access token for the managed identity of the
+// host from the Azure Instance Metadata Service.
+func azureIMDSToken(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	q := url.Values{
+		"api-version": []string{"2018-02-01"},
+		"resource":    []string{azureSQLResource},
+	}
+	if id := os.Getenv("AZURE_CLIENT_ID"); id != "" {
+		q.Set("client_id", id)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureIMDSURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	var v struct {
+		AccessToken      string `json:"access_token"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		return "", err
+	}
+	switch {
+	case v.ErrorDescription != "":
+		return "", errors.New(v.ErrorDescription)
+	case res.StatusCode != http.StatusOK:
+		return "", errors.New(res.Status)
+	case v.AccessToken == "":
+		return "", ErrNoToken
+	}
+	return v.AccessToken, nil
+}
@@ -0,0 +1,125 @@
+// Package cloud provides URL resolvers for the database services of cloud
+// providers, expanding URL shorthands to database URLs with credentials
+// retrieved using the provider's native authentication.
+//
+// The following shorthands are registered (see dburl.RegisterResolver):
+//
+//	rds://[USER@]INSTANCE[/DBNAME][?region=REGION]
+//	cloudsql://[USER@]PROJECT:REGION:INSTANCE[/DBNAME][?ip=public|private]
+//	azsql://[USER[:PASSWORD]@]SERVER[/DBNAME]
+//
+// Any other query parameters are passed through to the database URL.
+package cloud
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+)
+
+func init() {
+	dburl.RegisterResolver("rds", RDS)
+	dburl.RegisterResolver("cloudsql", CloudSQL)
+	dburl.RegisterResolver("azsql", AzureSQL)
+}
+
+// shorthand is a parsed URL shorthand.
+type shorthand struct {
+	User   *url.Userinfo
+	Host   string
+	DBName string
+	Query  url.Values
+}
+
+// parse parses a URL shorthand. The host is not parsed as a standard URL
+// host, as it can contain multiple colons (such as a Cloud SQL instance
+// connection name).
+func parse(urlstr string) (*shorthand, error) {
+	_, rest, ok := strings.Cut(urlstr, "://")
+	if !ok {
+		return nil, ErrInvalidURL
+	}
+	authority, tail := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i != -1 {
+		authority, tail = rest[:i], rest[i:]
+	}
+	userinfo, host := "", authority
+	if i := strings.LastIndex(authority, "@"); i != -1 {
+		userinfo, host = authority[:i+1], authority[i+1:]
+	}
+	if host == "" {
+		return nil, ErrInvalidURL
+	}
+	v, err := url.Parse("x://" + userinfo + "localhost" + tail)
+	if err != nil {
+		return nil, err
+	}
+	return &shorthand{
+		User:   v.User,
+		Host:   host,
+		DBName: strings.TrimPrefix(v.Path, "/"),
+		Query:  v.Query(),
+	}, nil
+}
+
+// hasPassword returns true when the shorthand has a password.
+func (s *shorthand) hasPassword() bool {
+	if s.User == nil {
+		return false
+	}
+	_, ok := s.User.Password()
+	return ok
+}
+
+// username returns the user name of the shorthand, or def when not set.
+func (s *shorthand) username(def string) string {
+	if s.User != nil && s.User.Username() != "" {
+		return s.User.Username()
+	}
+	return def
+}
+
+// setDefault sets the query parameter when not already set.
+func (s *shorthand) setDefault(name, value string) {
+	if !s.Query.Has(name) {
+		s.Query.Set(name, value)
+	}
+}
+
+// String builds the database URL for the scheme, host, and port.
+func (s *shorthand) String(scheme, host string, port int64) string {
+	if port != 0 {
+		host += ":" + strconv.FormatInt(port, 10)
+	}
+	u := url.URL{
+		Scheme:   scheme,
+		User:     s.User,
+		Host:     host,
+		Path:     "/" + s.DBName,
+		RawQuery: s.Query.Encode(),
+	}
+	return u.String()
+}
+
+// Error is an error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+const (
+	// ErrInvalidURL is the invalid URL error.
+	ErrInvalidURL Error = "invalid URL"
+	// ErrInvalidInstance is the invalid instance error.
+	ErrInvalidInstance Error = "invalid instance, expected PROJECT:REGION:INSTANCE"
+	// ErrUnsupportedEngine is the unsupported engine error.
+	ErrUnsupportedEngine Error = "unsupported database engine"
+	// ErrNoAddress is the no address error.
+	ErrNoAddress Error = "instance has no address of the requested type"
+	// ErrNoToken is the no token error.
+	ErrNoToken Error = "unable to retrieve access token"
+)
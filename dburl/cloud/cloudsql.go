@@ -0,0 +1,157 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// cloudSQLScopes are the OAuth2 scopes used to retrieve the instance
+// information from the Cloud SQL Admin API, and to log in as an IAM user.
+var cloudSQLScopes = []string{
+	"https://www.googleapis.com/auth/sqlservice.admin",
+	"https://www.googleapis.com/auth/sqlservice.login",
+}
+
+// cloudSQLAdminURL is the URL of the Cloud SQL Admin API.
+var cloudSQLAdminURL = "https://sqladmin.googleapis.com/v1"
+
+// CloudSQL resolves cloudsql://[USER@]PROJECT:REGION:INSTANCE[/DBNAME] URLs,
+// where PROJECT:REGION:INSTANCE is the connection name of a Google Cloud SQL
+// instance, using the Application Default Credentials. The instance's public
+// IP address is used, unless the ip query parameter is private.
+//
+// When IAM database authentication is enabled for a PostgreSQL or MySQL
+// instance, and a user without password is provided, the OAuth2 access token
+// of the credentials is used as the password.
+func CloudSQL(ctx context.Context, urlstr string) (string, error) {
+	s, err := parse(urlstr)
+	if err != nil {
+		return "", err
+	}
+	v := strings.Split(s.Host, ":")
+	if len(v) != 3 {
+		return "", ErrInvalidInstance
+	}
+	project, region, instance := v[0], v[1], v[2]
+	typ := "PRIMARY"
+	switch ip := s.Query.Get("ip"); ip {
+	case "", "public":
+	case "private":
+		typ = "PRIVATE"
+	default:
+		return "", fmt.Errorf("invalid ip type %q, allowed types are public, private", ip)
+	}
+	s.Query.Del("ip")
+	ts, err := google.DefaultTokenSource(ctx, cloudSQLScopes...)
+	if err != nil {
+		return "", err
+	}
+	inst, err := cloudSQLInstance(ctx, ts, project, instance)
+	switch {
+	case err != nil:
+		return "", err
+	case inst.Region != region:
+		return "", fmt.Errorf("instance %s is in region %s, not %s", instance, inst.Region, region)
+	}
+	var addr string
+	for _, ip := range inst.IPAddresses {
+		if ip.Type == typ {
+			addr = ip.IPAddress
+			break
+		}
+	}
+	if addr == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoAddress, strings.ToLower(typ))
+	}
+	var scheme, flag string
+	switch {
+	case strings.HasPrefix(inst.DatabaseVersion, "POSTGRES"):
+		scheme, flag = "postgres", "cloudsql.iam_authentication"
+		s.setDefault("sslmode", "require")
+	case strings.HasPrefix(inst.DatabaseVersion, "MYSQL"):
+		scheme, flag = "mysql", "cloudsql_iam_authentication"
+		// server certificates are issued for the instance name, not its
+		// address
+		s.setDefault("tls", "skip-verify")
+	case strings.HasPrefix(inst.DatabaseVersion, "SQLSERVER"):
+		scheme = "sqlserver"
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedEngine, inst.DatabaseVersion)
+	}
+	if s.User != nil && !s.hasPassword() && inst.flag(flag) {
+		tok, err := ts.Token()
+		if err != nil {
+			return "", err
+		}
+		s.User = url.UserPassword(s.User.Username(), tok.AccessToken)
+		if scheme == "mysql" {
+			s.setDefault("allowCleartextPasswords", "true")
+		}
+	}
+	return s.String(scheme, addr, 0), nil
+}
+
+// cloudSQLInstanceInfo is the instance information returned by the Cloud SQL
+// Admin API.
+type cloudSQLInstanceInfo struct {
+	DatabaseVersion string `json:"databaseVersion"`
+	Region          string `json:"region"`
+	IPAddresses     []struct {
+		Type      string `json:"type"`
+		IPAddress string `json:"ipAddress"`
+	} `json:"ipAddresses"`
+	Settings struct {
+		DatabaseFlags []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"databaseFlags"`
+	} `json:"settings"`
+}
+
+// flag returns true when the database flag is on.
+func (inst *cloudSQLInstanceInfo) flag(name string) bool {
+	for _, f := range inst.Settings.DatabaseFlags {
+		if f.Name == name {
+			return strings.EqualFold(f.Value, "on")
+		}
+	}
+	return false
+}
+
+// cloudSQLInstance retrieves the instance information from the Cloud SQL
+// Admin API.
+func cloudSQLInstance(ctx context.Context, ts oauth2.TokenSource, project, instance string) (*cloudSQLInstanceInfo, error) {
+	urlstr := cloudSQLAdminURL + "/projects/" + url.PathEscape(project) + "/instances/" + url.PathEscape(instance)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlstr, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := oauth2.NewClient(ctx, ts).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		var v struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&v); err != nil || v.Error.Message == "" {
+			return nil, fmt.Errorf("cloud sql admin api: %s", res.Status)
+		}
+		return nil, fmt.Errorf("cloud sql admin api: %s", v.Error.Message)
+	}
+	inst := new(cloudSQLInstanceInfo)
+	if err := json.NewDecoder(res.Body).Decode(inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
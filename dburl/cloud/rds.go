@@ -0,0 +1,123 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+)
+
+// RDS resolves rds://[USER@]INSTANCE[/DBNAME] URLs, where INSTANCE is the
+// identifier of an Amazon RDS instance or Aurora cluster, using the standard
+// AWS configuration (AWS_PROFILE, AWS_REGION, shared config and credentials
+// files, and instance roles). The region can be set with the region query
+// parameter.
+//
+// When IAM database authentication is enabled for a PostgreSQL or MySQL
+// instance, and no password is provided, an IAM authentication token is
+// generated for the user (by default, the instance's master user name).
+func RDS(ctx context.Context, urlstr string) (string, error) {
+	s, err := parse(urlstr)
+	if err != nil {
+		return "", err
+	}
+	cfg := aws.NewConfig()
+	if region := s.Query.Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	s.Query.Del("region")
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return "", err
+	}
+	inst, err := rdsInstance(ctx, rds.New(sess), s.Host)
+	if err != nil {
+		return "", err
+	}
+	var scheme string
+	switch {
+	case strings.HasSuffix(inst.engine, "postgresql"), inst.engine == "postgres":
+		scheme = "postgres"
+		s.setDefault("sslmode", "require")
+	case strings.HasSuffix(inst.engine, "mysql"), inst.engine == "aurora", inst.engine == "mariadb":
+		scheme = "mysql"
+	case strings.Contains(inst.engine, "sqlserver"):
+		scheme = "sqlserver"
+	case strings.Contains(inst.engine, "oracle"):
+		scheme = "oracle"
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedEngine, inst.engine)
+	}
+	if inst.iam && !s.hasPassword() && (scheme == "postgres" || scheme == "mysql") {
+		user := s.username(inst.user)
+		endpoint := fmt.Sprintf("%s:%d", inst.address, inst.port)
+		token, err := rdsutils.BuildAuthToken(endpoint, aws.StringValue(sess.Config.Region), user, sess.Config.Credentials)
+		if err != nil {
+			return "", err
+		}
+		s.User = url.UserPassword(user, token)
+		if scheme == "mysql" {
+			// tokens are sent in clear text, over TLS
+			s.setDefault("tls", "skip-verify")
+			s.setDefault("allowCleartextPasswords", "true")
+		}
+	}
+	return s.String(scheme, inst.address, inst.port), nil
+}
+
+// rdsInstanceInfo is the connection information of a RDS instance.
+type rdsInstanceInfo struct {
+	engine  string
+	address string
+	port    int64
+	user    string
+	iam     bool
+}
+
+// rdsInstance returns the connection information of the instance, or of the
+// cluster when no instance has the identifier.
+func rdsInstance(ctx context.Context, svc *rds.RDS, id string) (*rdsInstanceInfo, error) {
+	res, err := svc.DescribeDBInstancesWithContext(ctx, &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(id),
+	})
+	switch e, ok := err.(awserr.Error); {
+	case ok && e.Code() == rds.ErrCodeDBInstanceNotFoundFault:
+	case err != nil:
+		return nil, err
+	case len(res.DBInstances) != 0 && res.DBInstances[0].Endpoint != nil:
+		inst := res.DBInstances[0]
+		return &rdsInstanceInfo{
+			engine:  aws.StringValue(inst.Engine),
+			address: aws.StringValue(inst.Endpoint.Address),
+			port:    aws.Int64Value(inst.Endpoint.Port),
+			user:    aws.StringValue(inst.MasterUsername),
+			iam:     aws.BoolValue(inst.IAMDatabaseAuthenticationEnabled),
+		}, nil
+	}
+	clusters, err := svc.DescribeDBClustersWithContext(ctx, &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(id),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case len(clusters.DBClusters) == 0:
+		return nil, fmt.Errorf("%w: %s", ErrNoAddress, id)
+	}
+	cluster := clusters.DBClusters[0]
+	return &rdsInstanceInfo{
+		engine:  aws.StringValue(cluster.Engine),
+		address: aws.StringValue(cluster.Endpoint),
+		port:    aws.Int64Value(cluster.Port),
+		user:    aws.StringValue(cluster.MasterUsername),
+		iam:     aws.BoolValue(cluster.IAMDatabaseAuthenticationEnabled),
+	}, nil
+}
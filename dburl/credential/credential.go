@@ -0,0 +1,187 @@
+// Package credential provides a mechanism for retrieving database credentials
+// from external secret sources at connect time.
+//
+// A credential is requested by adding a credential parameter to a database
+// URL, consisting of the provider name and a provider specific reference:
+//
+//	postgres://user@localhost/db?credential=ldap:cn=reports,ou=services
+//
+// The env, vault (see Vault), and secretsmanager (see SecretsManager)
+// providers are built in. Additional providers are registered with Register.
+// When no provider is registered for a name, a usql-credential-<name>
+// executable on the PATH is used as a plugin (see Exec).
+package credential
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/ildus/usql/dburl"
+)
+
+// Param is the URL query parameter containing the credential reference.
+const Param = "credential"
+
+// Credential is a database credential.
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Provider is the interface for credential providers.
+type Provider interface {
+	// Credential retrieves the credential for the reference.
+	Credential(ctx context.Context, u *dburl.URL, ref string) (*Credential, error)
+}
+
+// ProviderFunc is a type wrapper for a single func satisfying
+// Provider.Credential.
+type ProviderFunc func(context.Context, *dburl.URL, string) (*Credential, error)
+
+// Credential satisfies the Provider interface.
+func (f ProviderFunc) Credential(ctx context.Context, u *dburl.URL, ref string) (*Credential, error) {
+	return f(ctx, u, ref)
+}
+
+// providers are the registered credential providers.
+var providers = struct {
+	sync.RWMutex
+	m map[string]Provider
+}{
+	m: map[string]Provider{
+		"env": ProviderFunc(Env),
+	},
+}
+
+// Register registers a credential provider.
+func Register(name string, provider Provider) {
+	providers.Lock()
+	defer providers.Unlock()
+	providers.m[name] = provider
+}
+
+// Resolve resolves the credential parameter of the URL, if present, returning
+// a URL with the credential parameter removed and the retrieved credential
+// set as its user info. When the URL does not have a credential parameter, u
+// is returned unchanged.
+func Resolve(ctx context.Context, u *dburl.URL) (*dburl.URL, error) {
+	q := u.Query()
+	v := q.Get(Param)
+	if v == "" {
+		return u, nil
+	}
+	name, ref, _ := strings.Cut(v, ":")
+	providers.RLock()
+	provider, ok := providers.m[name]
+	providers.RUnlock()
+	if !ok {
+		provider = Exec(name)
+	}
+	cred, err := provider.Credential(ctx, u, ref)
+	if err != nil {
+		return nil, &Error{name, err}
+	}
+	// rebuild url
+	z := *u
+	q.Del(Param)
+	z.RawQuery = q.Encode()
+	username := cred.Username
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+	}
+	z.User = url.UserPassword(username, cred.Password)
+	return dburl.Parse(z.String())
+}
+
+// Env is a credential provider that retrieves the password from the
+// environment variable named by the reference.
+func Env(_ context.Context, _ *dburl.URL, ref string) (*Credential, error) {
+	s, ok := os.LookupEnv(ref)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return &Credential{Password: s}, nil
+}
+
+// Exec returns a credential provider that executes the
+// usql-credential-<name> plugin executable with the reference as its
+// argument, and the URL's driver, host, port, database name, and user name in
+// the USQL_CREDENTIAL_* environment variables.
+//
+// The plugin writes the credential to standard output, either as a JSON
+// object with username and password fields, or as a single line containing
+// the password.
+func Exec(name string) Provider {
+	return ProviderFunc(func(ctx context.Context, u *dburl.URL, ref string) (*Credential, error) {
+		cmd := exec.CommandContext(ctx, "usql-credential-"+name, ref)
+		var username string
+		if u.User != nil {
+			username = u.User.Username()
+		}
+		cmd.Env = append(
+			os.Environ(),
+			"USQL_CREDENTIAL_DRIVER="+u.Driver,
+			"USQL_CREDENTIAL_HOST="+u.Hostname(),
+			"USQL_CREDENTIAL_PORT="+u.Port(),
+			"USQL_CREDENTIAL_DBNAME="+strings.TrimPrefix(u.Path, "/"),
+			"USQL_CREDENTIAL_USER="+username,
+		)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		buf, err := cmd.Output()
+		switch {
+		case err != nil && stderr.Len() != 0:
+			return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		case err != nil:
+			return nil, err
+		}
+		return parseOutput(buf)
+	})
+}
+
+// parseOutput parses the output of a plugin executable.
+func parseOutput(buf []byte) (*Credential, error) {
+	buf = bytes.TrimSpace(buf)
+	if bytes.HasPrefix(buf, []byte("{")) {
+		cred := new(Credential)
+		if err := json.Unmarshal(buf, cred); err != nil {
+			return nil, err
+		}
+		return cred, nil
+	}
+	password, _, _ := bytes.Cut(buf, []byte("\n"))
+	if len(password) == 0 {
+		return nil, ErrEmptyCredential
+	}
+	return &Credential{Password: string(bytes.TrimRight(password, "\r"))}, nil
+}
+
+// Error is a credential provider error.
+type Error struct {
+	Provider string
+	Err      error
+}
+
+// Error satisfies the error interface.
+func (err *Error) Error() string {
+	return fmt.Sprintf("credential provider %s: %v", err.Provider, err.Err)
+}
+
+// Unwrap satisfies the unwrap interface.
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// ErrEmptyCredential is the empty credential error.
+var ErrEmptyCredential = errors.New("empty credential")
+
+// ErrVaultTokenNotSet is the vault token not set error.
+var ErrVaultTokenNotSet = errors.New("VAULT_TOKEN is not set")
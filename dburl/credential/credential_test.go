@@ -0,0 +1,122 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+)
+
+func TestResolve(t *testing.T) {
+	Register("test", ProviderFunc(func(_ context.Context, u *dburl.URL, ref string) (*Credential, error) {
+		return &Credential{Username: "svc_" + ref, Password: "p@ss:" + u.Hostname()}, nil
+	}))
+	t.Setenv("USQL_TEST_PASSWORD", "s3cret")
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"pg://user@localhost/db", "dbname=db host=localhost user=user"},
+		{"pg://user@localhost/db?credential=test:reports&sslmode=disable", "dbname=db host=localhost password=p@ss:localhost sslmode=disable user=svc_reports"},
+		{"my://user@localhost/db?credential=env:USQL_TEST_PASSWORD", "user:s3cret@tcp(localhost:3306)/db"},
+	}
+	for i, test := range tests {
+		u, err := dburl.Parse(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		z, err := Resolve(context.Background(), u)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if z.DSN != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, z.DSN)
+		}
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp Credential
+	}{
+		{"s3cret\n", Credential{Password: "s3cret"}},
+		{"s3cret\r\nignored\n", Credential{Password: "s3cret"}},
+		{`{"username":"svc","password":"s3cret"}`, Credential{Username: "svc", Password: "s3cret"}},
+	}
+	for i, test := range tests {
+		cred, err := parseOutput([]byte(test.s))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if *cred != test.exp {
+			t.Errorf("test %d expected %+v, got: %+v", i, test.exp, *cred)
+		}
+	}
+	if _, err := parseOutput([]byte("\n")); err != ErrEmptyCredential {
+		t.Errorf("expected ErrEmptyCredential, got: %v", err)
+	}
+}
+
+func TestVault(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "t0ken" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/database/creds/readonly":
+			fmt.Fprint(w, `{"lease_id":"database/creds/readonly/abc","lease_duration":3600,"renewable":false,"data":{"username":"v-token-readonly","password":"s3cret"}}`)
+		case "/v1/secret/data/db":
+			fmt.Fprint(w, `{"data":{"data":{"username":"svc","password":"kv"},"metadata":{"version":1}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+	defer s.Close()
+	t.Setenv("VAULT_ADDR", s.URL)
+	t.Setenv("VAULT_TOKEN", "t0ken")
+	tests := []struct {
+		ref string
+		exp Credential
+	}{
+		{"database/creds/readonly", Credential{Username: "v-token-readonly", Password: "s3cret"}},
+		{"secret/data/db", Credential{Username: "svc", Password: "kv"}},
+	}
+	for i, test := range tests {
+		cred, err := Vault(context.Background(), nil, test.ref)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if *cred != test.exp {
+			t.Errorf("test %d expected %+v, got: %+v", i, test.exp, *cred)
+		}
+	}
+	if _, err := Vault(context.Background(), nil, "secret/data/missing"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestParseSecret(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp Credential
+	}{
+		{"s3cret", Credential{Password: "s3cret"}},
+		{`{"engine":"postgres","host":"db","username":"svc","password":"s3cret"}`, Credential{Username: "svc", Password: "s3cret"}},
+	}
+	for i, test := range tests {
+		cred, err := parseSecret(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if *cred != test.exp {
+			t.Errorf("test %d expected %+v, got: %+v", i, test.exp, *cred)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/ildus/usql/dburl"
+)
+
+// SecretsManager is a credential provider that retrieves credentials from AWS
+// Secrets Manager, using the standard AWS configuration (AWS_PROFILE,
+// AWS_REGION, shared config and credentials files, and instance roles).
+//
+// The reference is the name or ARN of the secret. The secret value is either
+// a JSON object with username and password fields (such as the secrets
+// managed for RDS), or the password.
+func SecretsManager(ctx context.Context, _ *dburl.URL, ref string) (*Credential, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cfgs []*aws.Config
+	if a, err := arn.Parse(ref); err == nil && a.Region != "" {
+		cfgs = append(cfgs, aws.NewConfig().WithRegion(a.Region))
+	}
+	res, err := secretsmanager.New(sess, cfgs...).GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(ref),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseSecret(aws.StringValue(res.SecretString))
+}
+
+// parseSecret parses a secret value.
+func parseSecret(s string) (*Credential, error) {
+	s = strings.TrimSpace(s)
+	cred := &Credential{Password: s}
+	if strings.HasPrefix(s, "{") {
+		cred = new(Credential)
+		if err := json.Unmarshal([]byte(s), cred); err != nil {
+			return nil, err
+		}
+	}
+	if cred.Password == "" {
+		return nil, ErrEmptyCredential
+	}
+	return cred, nil
+}
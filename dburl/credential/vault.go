@@ -0,0 +1,146 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/dburl"
+)
+
+// Vault is a credential provider that retrieves credentials from a HashiCorp
+// Vault server, using the VAULT_ADDR, VAULT_TOKEN (or ~/.vault-token), and
+// VAULT_NAMESPACE environment variables.
+//
+// The reference is the path of a secret containing username and password
+// fields, either dynamic database credentials (database/creds/<role>) or a
+// KV secret (secret/data/<name>). Renewable leases of dynamic credentials are
+// renewed in the background until they reach their maximum time to live.
+func Vault(ctx context.Context, _ *dburl.URL, ref string) (*Credential, error) {
+	c, err := newVaultClient()
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		LeaseID       string          `json:"lease_id"`
+		LeaseDuration int             `json:"lease_duration"`
+		Renewable     bool            `json:"renewable"`
+		Data          json.RawMessage `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, strings.TrimPrefix(ref, "/"), nil, &res); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Credential
+		// Data contains the secret of KV version 2 secrets.
+		Data *Credential `json:"data"`
+	}
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		return nil, err
+	}
+	cred := &data.Credential
+	if data.Data != nil {
+		cred = data.Data
+	}
+	if cred.Password == "" {
+		return nil, ErrEmptyCredential
+	}
+	if res.Renewable && res.LeaseID != "" && res.LeaseDuration > 0 {
+		go c.renew(res.LeaseID, time.Duration(res.LeaseDuration)*time.Second)
+	}
+	return cred, nil
+}
+
+// vaultClient is a minimal Vault HTTP API client.
+type vaultClient struct {
+	addr      string
+	token     string
+	namespace string
+	cl        *http.Client
+}
+
+// newVaultClient creates a Vault client from the environment.
+func newVaultClient() (*vaultClient, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = "https://127.0.0.1:8200"
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			buf, _ := os.ReadFile(filepath.Join(home, ".vault-token"))
+			token = strings.TrimSpace(string(buf))
+		}
+	}
+	if token == "" {
+		return nil, ErrVaultTokenNotSet
+	}
+	return &vaultClient{
+		addr:      strings.TrimSuffix(addr, "/"),
+		token:     token,
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		cl:        &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// do performs a request on the Vault API, decoding the response into v.
+func (c *vaultClient) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+"/v1/"+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	res, err := c.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&e)
+		if len(e.Errors) != 0 {
+			return fmt.Errorf("vault: %s: %s", res.Status, strings.Join(e.Errors, ", "))
+		}
+		return fmt.Errorf("vault: %s", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// renew renews the lease at two thirds of its duration, until the lease is
+// no longer renewable or renewal fails.
+func (c *vaultClient) renew(leaseID string, d time.Duration) {
+	for {
+		time.Sleep(d * 2 / 3)
+		var res struct {
+			LeaseDuration int  `json:"lease_duration"`
+			Renewable     bool `json:"renewable"`
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := c.do(ctx, http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": leaseID}, &res)
+		cancel()
+		if err != nil || !res.Renewable || res.LeaseDuration <= 0 {
+			return
+		}
+		d = time.Duration(res.LeaseDuration) * time.Second
+	}
+}
@@ -201,6 +201,61 @@ func GenDatabend(u *URL) (string, error) {
 	return u.String(), nil
 }
 
+// GenDatabricks generates a databricks DSN from the passed URL. The path is
+// the HTTP path of the SQL warehouse or cluster, and the personal access token
+// is the password (or, when there is no password, the user name).
+func GenDatabricks(u *URL) (string, error) {
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		return "", ErrMissingHost
+	}
+	if u.Path == "" || u.Path == "/" {
+		return "", ErrMissingPath
+	}
+	if port == "" {
+		port = "443"
+	}
+	var token string
+	if u.User != nil {
+		if token, _ = u.User.Password(); token == "" {
+			token = u.User.Username()
+		}
+	}
+	if token != "" {
+		token = "token:" + url.PathEscape(token) + "@"
+	}
+	return token + host + ":" + port + u.Path + genQueryOptions(u.Query()), nil
+}
+
+// GenDruid generates a druid DSN from the passed URL, using the Avatica
+// (protobuf) SQL endpoint of the broker (or router). The user and password are
+// passed using basic authentication.
+func GenDruid(u *URL) (string, error) {
+	host, port, path := u.Hostname(), u.Port(), u.Path
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "8082"
+	}
+	if path == "" || path == "/" {
+		path = "/druid/v2/sql/avatica-protobuf/"
+	}
+	q := u.Query()
+	scheme := "http"
+	if q.Get("ssl") == "true" {
+		scheme = "https"
+	}
+	q.Del("ssl")
+	if u.User != nil {
+		q.Set("authentication", "BASIC")
+		q.Set("avaticaUser", u.User.Username())
+		pass, _ := u.User.Password()
+		q.Set("avaticaPassword", pass)
+	}
+	return scheme + "://" + host + ":" + port + path + genQueryOptions(q), nil
+}
+
 // GenExasol generates a exasol DSN from the passed URL.
 func GenExasol(u *URL) (string, error) {
 	host, port, dbname := u.Hostname(), u.Port(), strings.TrimPrefix(u.Path, "/")
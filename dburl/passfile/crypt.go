@@ -0,0 +1,117 @@
+package passfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/pem"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted passfiles are stored as a PEM block, containing the scrypt salt,
+// the AES-256-GCM nonce, and the sealed entries.
+const (
+	// pemType is the PEM block type of encrypted passfiles.
+	pemType = "ENCRYPTED PASSFILE"
+	// saltLen is the length of the scrypt salt.
+	saltLen = 16
+	// scryptN, scryptR, and scryptP are the scrypt cost parameters.
+	scryptN, scryptR, scryptP = 1 << 15, 8, 1
+	// scryptMaxCost is the maximum product of the scrypt cost parameters
+	// accepted when decrypting, bounding the memory and time used to derive
+	// the key from untrusted headers.
+	scryptMaxCost = 16 * scryptN * scryptR * scryptP
+)
+
+// Passphrase returns the master passphrase used to decrypt an encrypted
+// passfile. Applications reading encrypted passfiles set it to retrieve the
+// passphrase, for example by prompting the user.
+var Passphrase = func(file string) (string, error) {
+	return "", ErrPassphraseRequired
+}
+
+// IsEncrypted returns true when buf contains an encrypted passfile.
+func IsEncrypted(buf []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(buf), []byte("-----BEGIN "+pemType+"-----"))
+}
+
+// Encrypt encrypts the contents of a passfile with the passphrase.
+func Encrypt(buf []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	data := append(append(salt, nonce...), aead.Seal(nil, nonce, buf, nil)...)
+	return pem.EncodeToMemory(&pem.Block{
+		Type: pemType,
+		Headers: map[string]string{
+			"Cipher": "AES-256-GCM",
+			"KDF":    "scrypt",
+			"N":      strconv.Itoa(scryptN),
+			"R":      strconv.Itoa(scryptR),
+			"P":      strconv.Itoa(scryptP),
+		},
+		Bytes: data,
+	}), nil
+}
+
+// Decrypt decrypts an encrypted passfile with the passphrase.
+func Decrypt(buf []byte, passphrase string) ([]byte, error) {
+	block, _ := pem.Decode(bytes.TrimSpace(buf))
+	if block == nil || block.Type != pemType {
+		return nil, ErrInvalidEncryptedPassfile
+	}
+	params := make([]int, 3)
+	for i, k := range []string{"N", "R", "P"} {
+		n, err := strconv.Atoi(block.Headers[k])
+		if err != nil || n <= 0 || n > scryptMaxCost {
+			return nil, ErrInvalidEncryptedPassfile
+		}
+		params[i] = n
+	}
+	if params[0]*params[1] > scryptMaxCost || params[0]*params[1]*params[2] > scryptMaxCost {
+		return nil, ErrInvalidEncryptedPassfile
+	}
+	if len(block.Bytes) < saltLen {
+		return nil, ErrInvalidEncryptedPassfile
+	}
+	salt, data := block.Bytes[:saltLen], block.Bytes[saltLen:]
+	aead, err := newAEAD(passphrase, salt, params[0], params[1], params[2])
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrInvalidEncryptedPassfile
+	}
+	nonce, data := data[:aead.NonceSize()], data[aead.NonceSize():]
+	buf, err = aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrIncorrectPassphrase
+	}
+	return buf, nil
+}
+
+// newAEAD creates the AES-256-GCM cipher for the key derived from the
+// passphrase.
+func newAEAD(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
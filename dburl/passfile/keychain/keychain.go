@@ -0,0 +1,146 @@
+// Package keychain provides a mechanism for storing and reading database
+// credentials from the operating system's keychain (macOS Keychain, Windows
+// Credential Manager, or a Secret Service/KWallet provider).
+package keychain
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/99designs/keyring"
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/dburl/passfile"
+)
+
+// ServiceName is the service name the entries are stored under in the
+// keychain.
+var ServiceName = "usql"
+
+// open opens the keychain.
+func open() (keyring.Keyring, error) {
+	return keyring.Open(keyring.Config{
+		ServiceName: ServiceName,
+		AllowedBackends: []keyring.BackendType{
+			keyring.KeychainBackend,
+			keyring.WinCredBackend,
+			keyring.SecretServiceBackend,
+			keyring.KWalletBackend,
+		},
+		KeychainTrustApplication: true,
+		KeychainSynchronizable:   false,
+	})
+}
+
+// Available returns true when a keychain is available.
+func Available() bool {
+	_, err := open()
+	return err == nil
+}
+
+// Entries returns the entries stored in the keychain.
+func Entries() ([]passfile.Entry, error) {
+	kr, err := open()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	keys, err := kr.Keys()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	sort.Strings(keys)
+	var entries []passfile.Entry
+	for _, key := range keys {
+		v := strings.Split(key, ":")
+		if len(v) != 5 {
+			continue
+		}
+		item, err := kr.Get(key)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		entries = append(entries, passfile.NewEntry(append(v, string(item.Data))))
+	}
+	return entries, nil
+}
+
+// Add adds the entry to the keychain, replacing any existing entry for the
+// same protocol, host, port, database, and username.
+func Add(entry passfile.Entry) error {
+	kr, err := open()
+	if err != nil {
+		return wrapErr(err)
+	}
+	key := Key(entry)
+	return wrapErr(kr.Set(keyring.Item{
+		Key:         key,
+		Data:        []byte(entry.Password),
+		Label:       ServiceName + " " + key,
+		Description: "database password",
+	}))
+}
+
+// Remove removes the entry from the keychain.
+func Remove(entry passfile.Entry) error {
+	kr, err := open()
+	if err != nil {
+		return wrapErr(err)
+	}
+	return wrapErr(kr.Remove(Key(entry)))
+}
+
+// Match returns a Userinfo from a keychain entry matching the database URL.
+// Returns nil when no keychain is available.
+func Match(u *dburl.URL) (*url.Userinfo, error) {
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return nil, nil
+		}
+	}
+	entries, err := Entries()
+	switch {
+	case errors.Is(err, ErrNotAvailable):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return passfile.MatchEntries(u, entries, dburl.Protocols(u.Driver)...)
+}
+
+// Key returns the keychain key for the entry.
+func Key(entry passfile.Entry) string {
+	return strings.Join([]string{
+		entry.Protocol,
+		entry.Host,
+		entry.Port,
+		entry.DBName,
+		entry.Username,
+	}, ":")
+}
+
+// Error is a error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+const (
+	// ErrNotAvailable is the keychain not available error.
+	ErrNotAvailable Error = "keychain not available"
+	// ErrNotFound is the entry not found error.
+	ErrNotFound Error = "entry not found in keychain"
+)
+
+// wrapErr converts keyring errors.
+func wrapErr(err error) error {
+	switch {
+	case errors.Is(err, keyring.ErrNoAvailImpl):
+		return ErrNotAvailable
+	case errors.Is(err, keyring.ErrKeyNotFound):
+		return ErrNotFound
+	}
+	return err
+}
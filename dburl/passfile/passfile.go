@@ -4,6 +4,7 @@ package passfile
 
 import (
 	"bufio"
+	"bytes"
 	"database/sql"
 	"fmt"
 	"io"
@@ -38,6 +39,36 @@ func NewEntry(v []string) Entry {
 	}
 }
 
+// String satisfies the fmt.Stringer interface, returning the entry as a
+// passfile line.
+func (entry Entry) String() string {
+	return strings.Join([]string{
+		entry.Protocol,
+		entry.Host,
+		entry.Port,
+		entry.DBName,
+		entry.Username,
+		entry.Password,
+	}, ":")
+}
+
+// Validate returns an error when a field of the entry contains a character
+// that cannot be written to a passfile, as passfile fields are not escaped.
+func (entry Entry) Validate() error {
+	for _, s := range []string{entry.Protocol, entry.Host, entry.Port, entry.DBName, entry.Username, entry.Password} {
+		if strings.ContainsAny(s, ":#\r\n") {
+			return ErrInvalidCharacter
+		}
+	}
+	return nil
+}
+
+// key returns the fields of the entry identifying the credentials, without the
+// password.
+func (entry Entry) key() [5]string {
+	return [5]string{entry.Protocol, entry.Host, entry.Port, entry.DBName, entry.Username}
+}
+
 // Parse parses passfile entries from the reader.
 func Parse(r io.Reader) ([]Entry, error) {
 	var entries []Entry
@@ -68,38 +99,126 @@ func Parse(r io.Reader) ([]Entry, error) {
 // commentRE matches comment entries in a passfile.
 var commentRE = regexp.MustCompile(`#.*`)
 
-// ParseFile parses passfile entries contained in file.
-func ParseFile(file string) ([]Entry, error) {
+// ReadFile reads the contents of file, decrypting the contents when the file
+// is encrypted, and returning whether it is encrypted. Returns no contents
+// when the file does not exist.
+func ReadFile(file string) ([]byte, bool, error) {
 	fi, err := os.Stat(file)
 	switch {
 	case err != nil && os.IsNotExist(err):
-		return nil, nil
+		return nil, false, nil
 	case err != nil:
-		return nil, &FileError{file, err}
+		return nil, false, &FileError{file, err}
 	case fi.IsDir():
 		// ensure not a directory
-		return nil, &FileError{file, ErrMustNotBeDirectory}
+		return nil, false, &FileError{file, ErrMustNotBeDirectory}
 	case runtime.GOOS != "windows" && fi.Mode()&0x3f != 0:
 		// ensure not group/world readable/writable/executable
-		return nil, &FileError{file, ErrHasGroupOrWorldAccess}
+		return nil, false, &FileError{file, ErrHasGroupOrWorldAccess}
 	}
-	// open
-	f, err := os.OpenFile(file, os.O_RDONLY, 0)
+	// read
+	buf, err := os.ReadFile(file)
 	if err != nil {
-		return nil, &FileError{file, err}
+		return nil, false, &FileError{file, err}
 	}
-	// parse
-	entries, err := Parse(f)
+	// decrypt
+	if !IsEncrypted(buf) {
+		return buf, false, nil
+	}
+	passphrase, err := Passphrase(file)
 	if err != nil {
-		defer f.Close()
-		return nil, &FileError{file, err}
+		return nil, true, &FileError{file, err}
+	}
+	if buf, err = Decrypt(buf, passphrase); err != nil {
+		return nil, true, &FileError{file, err}
+	}
+	return buf, true, nil
+}
+
+// ParseFile parses passfile entries contained in file.
+func ParseFile(file string) ([]Entry, error) {
+	buf, _, err := ReadFile(file)
+	if err != nil || buf == nil {
+		return nil, err
 	}
-	if err := f.Close(); err != nil {
+	// parse
+	entries, err := Parse(bytes.NewReader(buf))
+	if err != nil {
 		return nil, &FileError{file, err}
 	}
 	return entries, nil
 }
 
+// Update returns the contents of a passfile with its entries replaced by
+// entries. Comments, blank lines, and the lines of unchanged entries are
+// preserved, the lines of entries with a changed password are rewritten, the
+// lines of entries missing from entries are removed, and new entries are
+// appended.
+func Update(buf []byte, entries []Entry) ([]byte, error) {
+	for _, entry := range entries {
+		if err := entry.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	remaining := append([]Entry{}, entries...)
+	var b bytes.Buffer
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		line := s.Text()
+		comment := commentRE.FindString(line)
+		v := strings.Split(strings.TrimSpace(strings.TrimSuffix(line, comment)), ":")
+		if len(v) != 6 {
+			// keep comments, blank lines, and invalid entries
+			fmt.Fprintln(&b, line)
+			continue
+		}
+		entry := NewEntry(v)
+		i := 0
+		for ; i < len(remaining) && remaining[i].key() != entry.key(); i++ {
+		}
+		switch {
+		case i == len(remaining):
+			// removed entry
+			continue
+		case remaining[i] == entry:
+			fmt.Fprintln(&b, line)
+		case comment != "":
+			fmt.Fprintln(&b, remaining[i].String()+" "+comment)
+		default:
+			fmt.Fprintln(&b, remaining[i].String())
+		}
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	for _, entry := range remaining {
+		fmt.Fprintln(&b, entry.String())
+	}
+	return b.Bytes(), nil
+}
+
+// WriteFile writes the contents of a passfile to file, encrypting the file
+// with the passphrase when not empty.
+func WriteFile(file string, buf []byte, passphrase string) error {
+	if passphrase != "" {
+		var err error
+		if buf, err = Encrypt(buf, passphrase); err != nil {
+			return &FileError{file, err}
+		}
+	}
+	if err := os.WriteFile(file, buf, 0o600); err != nil {
+		return &FileError{file, err}
+	}
+	// ensure permissions of an existing file
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(file, 0o600); err != nil {
+			return &FileError{file, err}
+		}
+	}
+	return nil
+}
+
 // Equals returns true when v matches the entry.
 func (entry Entry) Equals(v Entry, protocols ...string) bool {
 	return (entry.Protocol == "*" || contains(protocols, entry.Protocol)) &&
@@ -241,6 +360,14 @@ const (
 	ErrMustNotBeDirectory Error = "must not be directory"
 	// ErrHasGroupOrWorldAccess is the has group or world access error.
 	ErrHasGroupOrWorldAccess Error = "has group or world access"
+	// ErrPassphraseRequired is the passphrase required error.
+	ErrPassphraseRequired Error = "passphrase required"
+	// ErrIncorrectPassphrase is the incorrect passphrase error.
+	ErrIncorrectPassphrase Error = "incorrect passphrase"
+	// ErrInvalidEncryptedPassfile is the invalid encrypted passfile error.
+	ErrInvalidEncryptedPassfile Error = "invalid encrypted passfile"
+	// ErrInvalidCharacter is the invalid character error.
+	ErrInvalidCharacter Error = "entry fields cannot contain ':', '#', or line breaks"
 )
 
 // FileError is a file error.
@@ -31,6 +31,82 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestEncrypt(t *testing.T) {
+	buf, err := Encrypt([]byte(passfile), "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !IsEncrypted(buf) {
+		t.Fatalf("expected encrypted passfile, got:\n%s", buf)
+	}
+	if _, err := Decrypt(buf, "wrong"); err != ErrIncorrectPassphrase {
+		t.Errorf("expected %v, got: %v", ErrIncorrectPassphrase, err)
+	}
+	dec, err := Decrypt(buf, "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := string(dec); s != passfile {
+		t.Errorf("expected decrypted passfile to equal:\n%s\ngot:\n%s", passfile, s)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		s       string
+		entries []Entry
+		exp     string
+	}{
+		{
+			"",
+			[]Entry{{"pg", "*", "*", "*", "postgres", "P4ssw0rd"}},
+			"pg:*:*:*:postgres:P4ssw0rd\n",
+		},
+		{
+			"# comment\npg:*:*:*:postgres:old # trailing\n\nmysql:*:*:*:root:P4ssw0rd\n",
+			[]Entry{{"pg", "*", "*", "*", "postgres", "new"}, {"mysql", "*", "*", "*", "root", "P4ssw0rd"}},
+			"# comment\npg:*:*:*:postgres:new # trailing\n\nmysql:*:*:*:root:P4ssw0rd\n",
+		},
+		{
+			"# comment\npg:*:*:*:postgres:P4ssw0rd\nmysql:*:*:*:root:P4ssw0rd\n",
+			[]Entry{{"mysql", "*", "*", "*", "root", "P4ssw0rd"}, {"cql", "*", "*", "*", "cassandra", "cassandra"}},
+			"# comment\nmysql:*:*:*:root:P4ssw0rd\ncql:*:*:*:cassandra:cassandra\n",
+		},
+	}
+	for i, test := range tests {
+		buf, err := Update([]byte(test.s), test.entries)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := string(buf); s != test.exp {
+			t.Errorf("test %d expected:\n%q\ngot:\n%q", i, test.exp, s)
+		}
+	}
+	for _, password := range []string{"a:b", "a#b", "a\nb"} {
+		entries := []Entry{{"pg", "*", "*", "*", "postgres", password}}
+		if _, err := Update(nil, entries); err != ErrInvalidCharacter {
+			t.Errorf("password %q expected %v, got: %v", password, ErrInvalidCharacter, err)
+		}
+	}
+}
+
+func TestDecryptCost(t *testing.T) {
+	buf, err := Encrypt([]byte(passfile), "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, n := range []string{"N: 32768", "N: 1073741824", "N: -1"} {
+		b := strings.Replace(string(buf), "N: 32768", n, 1)
+		_, err := Decrypt([]byte(b), "secret")
+		switch {
+		case n == "N: 32768" && err != nil:
+			t.Errorf("%s expected no error, got: %v", n, err)
+		case n != "N: 32768" && err != ErrInvalidEncryptedPassfile:
+			t.Errorf("%s expected %v, got: %v", n, ErrInvalidEncryptedPassfile, err)
+		}
+	}
+}
+
 const passfile = `# sample ~/.usqlpass file
 # 
 # format is:
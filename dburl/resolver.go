@@ -0,0 +1,43 @@
+package dburl
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves a URL shorthand, such as a cloud provider's instance URL,
+// to a database URL that can be [Parse]'d.
+type Resolver func(ctx context.Context, urlstr string) (string, error)
+
+// resolvers are the registered URL resolvers.
+var resolvers = struct {
+	sync.RWMutex
+	m map[string]Resolver
+}{
+	m: make(map[string]Resolver),
+}
+
+// RegisterResolver registers a [Resolver] for URLs with the scheme. Resolvers
+// are consulted by [Resolve] before parsing.
+func RegisterResolver(scheme string, resolver Resolver) {
+	resolvers.Lock()
+	defer resolvers.Unlock()
+	resolvers.m[strings.ToLower(scheme)] = resolver
+}
+
+// Resolve resolves urlstr using the [Resolver] registered for its scheme,
+// returning urlstr unchanged when no resolver is registered for the scheme.
+func Resolve(ctx context.Context, urlstr string) (string, error) {
+	scheme, _, ok := strings.Cut(urlstr, ":")
+	if !ok {
+		return urlstr, nil
+	}
+	resolvers.RLock()
+	resolver, ok := resolvers.m[strings.ToLower(scheme)]
+	resolvers.RUnlock()
+	if !ok {
+		return urlstr, nil
+	}
+	return resolver(ctx, urlstr)
+}
@@ -75,6 +75,8 @@ func BaseSchemes() []Scheme {
 		{"cql", GenCassandra, 0, false, []string{"ca", "cassandra", "datastax", "scy", "scylla"}, ""},
 		{"csvq", GenOpaque, 0, true, []string{"csv", "tsv", "json"}, ""},
 		{"databend", GenDatabend, 0, false, []string{"dd", "bend"}, ""},
+		{"databricks", GenDatabricks, 0, false, []string{"dbr", "dbx"}, ""},
+		{"druid", GenDruid, 0, false, nil, ""},
 		{"exasol", GenExasol, 0, false, []string{"ex", "exa"}, ""},
 		{"firebirdsql", GenFirebird, 0, false, []string{"fb", "firebird"}, ""},
 		{"flightsql", GenScheme("flightsql"), 0, false, []string{"fl", "flight"}, ""},
@@ -92,6 +94,7 @@ func BaseSchemes() []Scheme {
 		{"ots", GenTableStore, TransportAny, false, []string{"tablestore"}, ""},
 		{"presto", GenPresto, 0, false, []string{"prestodb", "prestos", "prs", "prestodbs"}, ""},
 		{"ql", GenOpaque, 0, true, []string{"ql", "cznic", "cznicql"}, ""},
+		{"sidecar", GenOpaque, 0, true, []string{"sc"}, ""},
 		{"snowflake", GenSnowflake, 0, false, []string{"sf"}, ""},
 		{"spanner", GenSpanner, 0, false, []string{"sp"}, ""},
 		{"tds", GenFromURL("http://localhost:5000/"), 0, false, []string{"ax", "ase", "sapase"}, ""},
@@ -4,10 +4,57 @@
 package bigquery
 
 import (
+	"context"
+	"database/sql"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"google.golang.org/api/option"
 	_ "gorm.io/driver/bigquery/driver" // DRIVER
 )
 
+// clientOptions are the API client options of the opened databases, used to
+// list projects with the same credentials as the connection.
+var clientOptions sync.Map
+
 func init() {
-	drivers.Register("bigquery", drivers.Driver{})
+	drivers.Register("bigquery", drivers.Driver{
+		Open: func(_ context.Context, u *dburl.URL, _, _ func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			opts := newClientOptions(u.Query())
+			return func(driver, dsn string) (*sql.DB, error) {
+				db, err := sql.Open(driver, dsn)
+				if err != nil {
+					return nil, err
+				}
+				clientOptions.Store(db, opts)
+				return db, nil
+			}, nil
+		},
+		NewMetadataReader: NewMetadataReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			return metadata.NewDefaultWriter(NewMetadataReader(db, opts...))(db, w)
+		},
+	})
+}
+
+// newClientOptions returns the API client options for the connection
+// parameters, as used by the driver: the scopes, the endpoint, and whether
+// authentication is disabled.
+func newClientOptions(q url.Values) []option.ClientOption {
+	var opts []option.ClientOption
+	if scopes := strings.Trim(q.Get("scopes"), ","); scopes != "" {
+		opts = append(opts, option.WithScopes(strings.Split(scopes, ",")...))
+	}
+	if endpoint := q.Get("endpoint"); endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	if q.Get("disable_auth") == "true" {
+		opts = append(opts, option.WithoutAuthentication())
+	}
+	return opts
 }
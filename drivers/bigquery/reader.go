@@ -0,0 +1,336 @@
+package bigquery
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/text"
+	bqapi "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+// MetadataReader reads the metadata of BigQuery projects. Datasets are
+// schemas, and projects are catalogs.
+type MetadataReader struct {
+	metadata.LoggingReader
+	db drivers.DB
+}
+
+var (
+	_ metadata.CatalogReader = &MetadataReader{}
+	_ metadata.SchemaReader  = &MetadataReader{}
+	_ metadata.TableReader   = &MetadataReader{}
+	_ metadata.ColumnReader  = &MetadataReader{}
+)
+
+// NewMetadataReader creates the metadata reader for BigQuery.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+		db:            db,
+	}
+}
+
+// Project is a BigQuery project.
+type Project struct {
+	metadata.Catalog
+	Name string
+}
+
+func (p Project) Values() []interface{} {
+	return []interface{}{p.Catalog.Catalog, p.Name}
+}
+
+func (p Project) GetCatalog() metadata.Catalog {
+	return p.Catalog
+}
+
+// Catalogs lists the projects accessible with the connection's credentials,
+// as there is no INFORMATION_SCHEMA view of projects.
+func (r MetadataReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	db, ok := r.db.(*sql.DB)
+	if !ok {
+		return nil, text.ErrNotSupported
+	}
+	opts, ok := clientOptions.Load(db)
+	if !ok {
+		return nil, text.ErrNotSupported
+	}
+	ctx := context.Background()
+	svc, err := bqapi.NewService(ctx, opts.([]option.ClientOption)...)
+	if err != nil {
+		return nil, err
+	}
+	var re *regexp.Regexp
+	if f.Name != "" {
+		re = likeRE(f.Name)
+	}
+	var results []metadata.Result
+	err = svc.Projects.List().Pages(ctx, func(l *bqapi.ProjectList) error {
+		for _, p := range l.Projects {
+			if p.ProjectReference == nil || re != nil && !re.MatchString(p.ProjectReference.ProjectId) {
+				continue
+			}
+			results = append(results, &Project{
+				Catalog: metadata.Catalog{Catalog: p.ProjectReference.ProjectId},
+				Name:    p.FriendlyName,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewCatalogSetWithColumns(results, []string{"Catalog", "Name"}), nil
+}
+
+// Schemas lists the datasets of the project in the filter, or of the
+// connection's project.
+func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	qstr := `SELECT schema_name, catalog_name
+FROM ` + qualify(f.Catalog, "", "INFORMATION_SCHEMA.SCHEMATA")
+	var vals []interface{}
+	if f.Name != "" {
+		qstr += "\nWHERE schema_name LIKE ?"
+		vals = append(vals, f.Name)
+	}
+	qstr += "\nORDER BY schema_name"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSchemaSet(nil), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Schema
+	for rows.Next() {
+		var rec metadata.Schema
+		if err := rows.Scan(&rec.Schema, &rec.Catalog); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// Tables lists the tables of the datasets matching the filter, or of the
+// default dataset. Clones, snapshots, and external tables are listed as
+// tables.
+func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	datasets, err := r.datasets(f)
+	if err != nil {
+		return nil, err
+	}
+	var conds []string
+	var vals []interface{}
+	if f.Name != "" {
+		conds = append(conds, "table_name LIKE ?")
+		vals = append(vals, f.Name)
+	}
+	if len(f.Types) != 0 {
+		var types []string
+		for _, typ := range f.Types {
+			vals = append(vals, typ)
+			types = append(types, "?")
+			if typ == "BASE TABLE" {
+				vals = append(vals, "CLONE", "SNAPSHOT", "EXTERNAL")
+				types = append(types, "?", "?", "?")
+			}
+		}
+		conds = append(conds, "table_type IN ("+strings.Join(types, ", ")+")")
+	}
+	var results []metadata.Table
+	for _, dataset := range datasets {
+		qstr := `SELECT table_catalog, table_schema, table_name, table_type
+FROM ` + qualify(f.Catalog, dataset, "INFORMATION_SCHEMA.TABLES")
+		if len(conds) != 0 {
+			qstr += "\nWHERE " + strings.Join(conds, " AND ")
+		}
+		qstr += "\nORDER BY table_name"
+		tables, err := r.tables(qstr, vals)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, tables...)
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// tables reads the tables of a dataset, closing the rows before the next
+// dataset is queried.
+func (r MetadataReader) tables(qstr string, vals []interface{}) ([]metadata.Table, error) {
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Table
+	for rows.Next() {
+		var rec metadata.Table
+		if err := rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return results, nil
+}
+
+// Columns lists the columns of the tables matching the filter. Partitioning
+// and clustering columns are flagged in their data type, with clustering
+// columns numbered in clustering order.
+func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	datasets, err := r.datasets(f)
+	if err != nil {
+		return nil, err
+	}
+	var conds []string
+	var vals []interface{}
+	if f.Parent != "" {
+		conds = append(conds, "table_name LIKE ?")
+		vals = append(vals, f.Parent)
+	}
+	if f.Name != "" {
+		conds = append(conds, "column_name LIKE ?")
+		vals = append(vals, f.Name)
+	}
+	var results []metadata.Column
+	for _, dataset := range datasets {
+		qstr := `SELECT
+  table_catalog,
+  table_schema,
+  table_name,
+  column_name,
+  ordinal_position,
+  data_type,
+  IFNULL(column_default, 'NULL'),
+  is_nullable,
+  is_partitioning_column,
+  clustering_ordinal_position
+FROM ` + qualify(f.Catalog, dataset, "INFORMATION_SCHEMA.COLUMNS")
+		if len(conds) != 0 {
+			qstr += "\nWHERE " + strings.Join(conds, " AND ")
+		}
+		qstr += "\nORDER BY table_name, ordinal_position"
+		columns, err := r.columns(qstr, vals)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, columns...)
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// columns reads the columns of a dataset, closing the rows before the next
+// dataset is queried.
+func (r MetadataReader) columns(qstr string, vals []interface{}) ([]metadata.Column, error) {
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Column
+	for rows.Next() {
+		var rec metadata.Column
+		var partitioning string
+		var clustering sql.NullInt64
+		err := rows.Scan(
+			&rec.Catalog,
+			&rec.Schema,
+			&rec.Table,
+			&rec.Name,
+			&rec.OrdinalPosition,
+			&rec.DataType,
+			&rec.Default,
+			&rec.IsNullable,
+			&partitioning,
+			&clustering,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if rec.Default == "NULL" {
+			rec.Default = ""
+		}
+		var flags []string
+		if partitioning == "YES" {
+			flags = append(flags, "partitioning")
+		}
+		if clustering.Valid {
+			flags = append(flags, fmt.Sprintf("clustering %d", clustering.Int64))
+		}
+		if len(flags) != 0 {
+			rec.DataType += " (" + strings.Join(flags, ", ") + ")"
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return results, nil
+}
+
+// datasets returns the datasets matching the schema pattern of the filter,
+// or the default dataset (an empty string) when there is no schema pattern.
+func (r MetadataReader) datasets(f metadata.Filter) ([]string, error) {
+	if f.Schema == "" {
+		return []string{""}, nil
+	}
+	res, err := r.Schemas(metadata.Filter{Catalog: f.Catalog, Name: f.Schema})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	var datasets []string
+	for res.Next() {
+		datasets = append(datasets, res.Get().Schema)
+	}
+	return datasets, nil
+}
+
+// qualify qualifies the INFORMATION_SCHEMA view with the non-empty project
+// and dataset.
+func qualify(project, dataset, view string) string {
+	var s []string
+	for _, name := range []string{project, dataset} {
+		if name != "" {
+			s = append(s, "`"+strings.ReplaceAll(name, "`", "\\`")+"`")
+		}
+	}
+	return strings.Join(append(s, view), ".")
+}
+
+// likeRE converts a LIKE pattern to a regexp.
+func likeRE(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
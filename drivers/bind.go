@@ -0,0 +1,128 @@
+package drivers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/text"
+)
+
+// Bind prepares a query to be executed with the parameters bound by \bind,
+// returning the query and the values of its placeholders. For drivers with
+// their own placeholders (ie, ? or :1), the positional $1, $2, ...
+// parameters in the query are rewritten to the driver's placeholders, with the
+// values ordered accordingly. Queries without any positional parameters are
+// assumed to use the driver's placeholders, and are bound the values as is.
+func Bind(u *dburl.URL, sqlstr string, params []string) (string, []interface{}, error) {
+	sqlstr, pos := Placeholders(u, sqlstr)
+	args, err := BindArgs(pos, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return sqlstr, args, nil
+}
+
+// Placeholders rewrites the positional $1, $2, ... parameters in a query to
+// the driver's placeholders, returning the rewritten query and the number of
+// the parameter for each placeholder. Returns the query unchanged and nil
+// positions when the driver uses positional parameters, or when the query
+// does not contain any.
+func Placeholders(u *dburl.URL, sqlstr string) (string, []int) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Placeholder == nil {
+		return sqlstr, nil
+	}
+	return rewriteParams(sqlstr, d.Placeholder)
+}
+
+// BindArgs returns the values of the placeholders from the bound parameters,
+// using the parameter numbers from Placeholders. When pos is nil, the
+// parameters are returned as is.
+func BindArgs(pos []int, params []string) ([]interface{}, error) {
+	if len(pos) == 0 {
+		args := make([]interface{}, len(params))
+		for i, p := range params {
+			args[i] = p
+		}
+		return args, nil
+	}
+	args := make([]interface{}, len(pos))
+	for i, n := range pos {
+		if n > len(params) {
+			return nil, fmt.Errorf(text.BindMissingParameter, n, len(params))
+		}
+		args[i] = params[n-1]
+	}
+	return args, nil
+}
+
+// NumParams returns the highest numbered positional $1, $2, ... parameter in
+// a query.
+func NumParams(sqlstr string) int {
+	_, pos := rewriteParams(sqlstr, func(n int) string {
+		return ""
+	})
+	var v int
+	for _, n := range pos {
+		v = max(v, n)
+	}
+	return v
+}
+
+// rewriteParams rewrites the $1, $2, ... parameters in sqlstr outside of
+// quoted strings, identifiers, and comments using f, returning the rewritten
+// query and the number of each rewritten parameter in order.
+func rewriteParams(sqlstr string, f func(int) string) (string, []int) {
+	var sb strings.Builder
+	var pos []int
+	r := []rune(sqlstr)
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(r) && r[j] != c; j++ {
+			}
+			sb.WriteString(string(r[i:min(j+1, len(r))]))
+			i = j
+			continue
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			j := i
+			for ; j < len(r) && r[j] != '\n'; j++ {
+			}
+			sb.WriteString(string(r[i:j]))
+			i = j - 1
+			continue
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			j := i + 2
+			for ; j+1 < len(r) && (r[j] != '*' || r[j+1] != '/'); j++ {
+			}
+			j = min(j+2, len(r))
+			sb.WriteString(string(r[i:j]))
+			i = j - 1
+			continue
+		case c == '$' && (i == 0 || !isIdentRune(r[i-1])):
+			j := i + 1
+			for ; j < len(r) && '0' <= r[j] && r[j] <= '9'; j++ {
+			}
+			if j > i+1 && (j == len(r) || !isIdentRune(r[j]) && r[j] != '$') {
+				n, err := strconv.Atoi(string(r[i+1 : j]))
+				if err == nil && n > 0 {
+					sb.WriteString(f(len(pos) + 1))
+					pos = append(pos, n)
+					i = j - 1
+					continue
+				}
+			}
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String(), pos
+}
+
+// isIdentRune determines if c is part of an identifier.
+func isIdentRune(c rune) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
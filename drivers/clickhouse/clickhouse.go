@@ -4,9 +4,15 @@
 package clickhouse
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"math"
+	"strings"
+	"time"
 
-	_ "github.com/ClickHouse/clickhouse-go/v2" // DRIVER
+	"github.com/ClickHouse/clickhouse-go/v2" // DRIVER
 	"github.com/ildus/usql/drivers"
 )
 
@@ -16,7 +22,128 @@ func init() {
 		RowsAffected: func(sql.Result) (int64, error) {
 			return 0, nil
 		},
-		Copy:              drivers.CopyWithInsert(func(int) string { return "?" }),
+		User: func(ctx context.Context, db drivers.DB) (string, error) {
+			var user string
+			if err := db.QueryRowContext(ctx, `SELECT currentUser()`).Scan(&user); err != nil {
+				return "", err
+			}
+			return user, nil
+		},
+		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
+			_, err := db.Exec(`ALTER USER ` + quoteIdentifier(user) + ` IDENTIFIED BY ` + quoteLiteral(newpw))
+			return err
+		},
+		Copy:        drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:      drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder: func(int) string { return "?" },
+		QueryID: func(ctx context.Context, _ drivers.Conn) (context.Context, string, error) {
+			if id, ok := ctx.Value(queryIDKey{}).(string); ok {
+				return ctx, id, nil
+			}
+			id, err := newQueryID()
+			if err != nil {
+				return nil, "", err
+			}
+			return clickhouse.Context(ctx, clickhouse.WithQueryID(id)), id, nil
+		},
+		ResultID: func(ctx context.Context) (context.Context, func() string) {
+			id, err := newQueryID()
+			if err != nil {
+				return ctx, func() string { return "" }
+			}
+			ctx = context.WithValue(ctx, queryIDKey{}, id)
+			return clickhouse.Context(ctx, clickhouse.WithQueryID(id)), func() string { return id }
+		},
+		QueryResult: queryResult,
+		QueryTag: func(ctx context.Context, _ drivers.Conn, tag string) (context.Context, error) {
+			return withSetting(ctx, "log_comment", tag), nil
+		},
+		StatementTimeout: func(ctx context.Context, _ drivers.Conn, d time.Duration) (context.Context, error) {
+			return withSetting(ctx, "max_execution_time", int(math.Ceil(d.Seconds()))), nil
+		},
+		Cancel: func(ctx context.Context, db drivers.DB, id string) error {
+			_, err := db.ExecContext(ctx, `KILL QUERY WHERE query_id = `+quoteLiteral(id))
+			return err
+		},
+		Kill: func(ctx context.Context, db drivers.DB, id string) error {
+			_, err := db.ExecContext(ctx, `KILL QUERY WHERE query_id = `+quoteLiteral(id)+` SYNC`)
+			return err
+		},
 		NewMetadataReader: NewMetadataReader,
+		Explain:           Explain,
+		Sample:            sample,
+		QuoteLiteral:      quoteLiteral,
+		QuoteIdentifier:   quoteIdentifier,
+		CommentOn:         commentOn,
 	})
 }
+
+// commentOn returns the statement setting the comment of a table, or of a
+// column qualified by its table.
+func commentOn(typ, name, comment string) (string, error) {
+	if typ == "TABLE" {
+		return "ALTER TABLE " + name + " MODIFY COMMENT " + quoteLiteral(comment), nil
+	}
+	i := strings.LastIndex(name, ".")
+	return "ALTER TABLE " + name[:i] + " COMMENT COLUMN " + name[i+1:] + " " + quoteLiteral(comment), nil
+}
+
+// queryIDKey is the context key of the query id assigned to a query.
+type queryIDKey struct{}
+
+// settingsKey is the context key of the settings of a query.
+type settingsKey struct{}
+
+// withSetting adds the setting to the settings of the query executed with the
+// returned context, as the settings of a query are replaced, not merged.
+func withSetting(ctx context.Context, name string, value interface{}) context.Context {
+	settings := clickhouse.Settings{name: value}
+	if prev, ok := ctx.Value(settingsKey{}).(clickhouse.Settings); ok {
+		for k, v := range prev {
+			if k != name {
+				settings[k] = v
+			}
+		}
+	}
+	ctx = context.WithValue(ctx, settingsKey{}, settings)
+	return clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+}
+
+// newQueryID returns a new random query id.
+func newQueryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// queryResult returns the status of the query with the query id, from the
+// running queries or the query log, as results are not kept by the server.
+func queryResult(ctx context.Context, db drivers.DB, id string) (*sql.Rows, error) {
+	return db.QueryContext(ctx, `SELECT * FROM (
+  SELECT query_id, 'Running' AS status, toUInt64(elapsed * 1000) AS duration_ms, read_rows, 0 AS result_rows, '' AS exception, query
+  FROM system.processes
+  WHERE query_id = `+quoteLiteral(id)+`
+  UNION ALL
+  SELECT query_id, toString(type), query_duration_ms, read_rows, result_rows, exception, query
+  FROM system.query_log
+  WHERE query_id = `+quoteLiteral(id)+` AND type != 'QueryStart'
+)`)
+}
+
+// quoteLiteral quotes s as a string literal.
+func quoteLiteral(s string) string {
+	return "'" + literalReplacer.Replace(s) + "'"
+}
+
+// quoteIdentifier quotes s as an identifier using backticks.
+func quoteIdentifier(s string) string {
+	return "`" + identifierReplacer.Replace(s) + "`"
+}
+
+// literalReplacer escapes the special characters of a string literal.
+var literalReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// identifierReplacer escapes the special characters of a quoted identifier.
+var identifierReplacer = strings.NewReplacer(`\`, `\\`, "`", "\\`")
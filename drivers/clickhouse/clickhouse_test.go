@@ -1,6 +1,7 @@
 package clickhouse_test
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -8,8 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	dt "github.com/ory/dockertest/v3"
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
 	"github.com/ildus/usql/drivers/clickhouse"
 	"github.com/ildus/usql/drivers/metadata"
 	"github.com/yookoala/realpath"
@@ -119,6 +123,38 @@ func TestColumns(t *testing.T) {
 	checkNames(t, "column", res, colNames()...)
 }
 
+func TestCancel(t *testing.T) {
+	const query = "SELECT count() FROM system.numbers"
+	conn, err := db.db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("could not get connection: %v", err)
+	}
+	defer conn.Close()
+	// interrupt the query, as with Ctrl-C
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	ctx, stop := drivers.WithCancel(ctx, &dburl.URL{Driver: "clickhouse"}, db.db, conn)
+	defer stop()
+	if _, err := conn.ExecContext(ctx, query); err == nil {
+		t.Fatalf("expected query to be interrupted")
+	}
+	// the query should stop running on the server
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var n uint64
+		if err := db.db.QueryRow(`SELECT count() FROM system.processes WHERE query = '` + query + `'`).Scan(&n); err != nil {
+			t.Fatalf("could not check running queries: %v", err)
+		}
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected query to be stopped on the server, still running")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 func checkNames(t *testing.T, typ string, res interface{ Next() bool }, exp ...string) {
 	n := make(map[string]bool)
 	for _, s := range exp {
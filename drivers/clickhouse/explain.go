@@ -0,0 +1,51 @@
+package clickhouse
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// Explain retrieves the query plan for a query using EXPLAIN PLAN, which
+// returns the plan as indented lines of text.
+func Explain(ctx context.Context, db drivers.DB, query string, analyze bool) (*drivers.ExplainNode, error) {
+	if analyze {
+		return nil, text.ErrExplainAnalyzeNotSupported
+	}
+	rows, err := db.QueryContext(ctx, "EXPLAIN PLAN actions = 0, indexes = 1 "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		lines = append(lines, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return drivers.ParseIndentedPlan("QUERY PLAN", lines), nil
+}
+
+// sample returns the query returning a sample of n rows of the table, using
+// SAMPLE when the table has a sampling key, and otherwise ordering the rows
+// randomly.
+func sample(ctx context.Context, db drivers.DB, table string, n int) (string, error) {
+	database, name, ok := strings.Cut(strings.ReplaceAll(table, "`", ""), ".")
+	if !ok {
+		database, name = "", database
+	}
+	var key string
+	_ = db.QueryRowContext(ctx, `SELECT sampling_key FROM system.tables WHERE database = if(? = '', currentDatabase(), ?) AND name = ?`, database, database, name).Scan(&key)
+	if key != "" {
+		return "SELECT * FROM " + table + " SAMPLE " + strconv.Itoa(n) + " LIMIT " + strconv.Itoa(n), nil
+	}
+	return drivers.SampleRandom("rand()")(ctx, db, table, n)
+}
@@ -2,7 +2,11 @@ package clickhouse
 
 import (
 	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ildus/usql/drivers"
 	"github.com/ildus/usql/drivers/metadata"
@@ -10,12 +14,14 @@ import (
 
 type MetadataReader struct {
 	metadata.LoggingReader
+	udfs *udfCache
 }
 
 // NewMetadataReader creates the metadata reader for clickhouse databases.
 func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
 	return &MetadataReader{
 		LoggingReader: metadata.NewLoggingReader(db, opts...),
+		udfs:          new(udfCache),
 	}
 }
 
@@ -27,8 +33,10 @@ func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
     IF(database LIKE 'system', 'SYSTEM TABLE', null),
     IF(is_temporary,'LOCAL TEMPORARY', null),
     IF(engine LIKE 'View', 'VIEW', null),
+    IF(engine LIKE 'MaterializedView', 'MATERIALIZED VIEW', null),
     'TABLE'
   ) AS Type,
+  COALESCE(total_rows, 0) AS Rows,
   COALESCE(total_bytes, 0) AS Size,
   comment as Comment
 FROM
@@ -64,7 +72,7 @@ FROM
 	var results []metadata.Table
 	for rows.Next() {
 		var rec metadata.Table
-		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.Size, &rec.Comment); err != nil {
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.Rows, &rec.Size, &rec.Comment); err != nil {
 			return nil, err
 		}
 		results = append(results, rec)
@@ -75,13 +83,18 @@ FROM
 	return metadata.NewTableSet(results), nil
 }
 
+// Columns lists the columns of a table. Besides DEFAULT columns, ClickHouse
+// has MATERIALIZED, ALIAS, and EPHEMERAL columns, that are computed from their
+// expression and not inserted, so their kind precedes their expression in the
+// column default.
 func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
 	qstr := `SELECT
   position,
   database as schema,
   name,
   type,
-  COALESCE(default_expression, '')
+  IF(default_kind IN ('', 'DEFAULT'), default_expression, trim(default_kind || ' ' || default_expression)),
+  comment
 FROM
   system.columns`
 	vals := []interface{}{f.Parent}
@@ -121,6 +134,7 @@ FROM
 			&rec.Name,
 			&rec.DataType,
 			&rec.Default,
+			&rec.Comment,
 		); err != nil {
 			return nil, err
 		}
@@ -166,7 +180,9 @@ func (r MetadataReader) Functions(f metadata.Filter) (*metadata.FunctionSet, err
 	qstr := `SELECT
   name AS specific_name,
   name AS routine_name,
-  (IF(is_aggregate = 1,'AGGREGATE','FUNCTION')) AS type
+  (IF(is_aggregate = 1,'AGGREGATE','FUNCTION')) AS type,
+  toString(origin) AS origin,
+  create_query
 FROM
   system.functions`
 	var conds []string
@@ -186,6 +202,17 @@ FROM
 		}
 	}
 	rows, closeRows, err := r.query(qstr, conds, "name, type", vals...)
+	if err != nil {
+		// origin and create_query are not available before 21.10
+		rows, closeRows, err = r.query(`SELECT
+  name AS specific_name,
+  name AS routine_name,
+  (IF(is_aggregate = 1,'AGGREGATE','FUNCTION')) AS type,
+  'System' AS origin,
+  '' AS create_query
+FROM
+  system.functions`, conds, "name, type", vals...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -193,13 +220,27 @@ FROM
 	var results []metadata.Function
 	for rows.Next() {
 		var rec metadata.Function
+		var origin, createQuery string
 		if err := rows.Scan(
 			&rec.SpecificName,
 			&rec.Name,
 			&rec.Type,
+			&origin,
+			&createQuery,
 		); err != nil {
 			return nil, err
 		}
+		switch origin {
+		case "SQLUserDefined":
+			rec.Language = "sql"
+			if udf, ok := parseUDF(createQuery); ok {
+				rec.Source = udf.body
+			}
+		case "ExecutableUserDefined":
+			rec.Language = "executable"
+		default:
+			rec.Language = "internal"
+		}
 		results = append(results, rec)
 	}
 	if rows.Err() != nil {
@@ -208,6 +249,959 @@ FROM
 	return metadata.NewFunctionSet(results), nil
 }
 
+// FunctionColumns returns the arguments of SQL user-defined functions. As the
+// lambda arguments of SQL user-defined functions are untyped, only their
+// names are available.
+func (r MetadataReader) FunctionColumns(f metadata.Filter) (*metadata.FunctionColumnSet, error) {
+	udfs, err := r.udfs.load(r)
+	if err != nil {
+		return nil, err
+	}
+	var results []metadata.FunctionColumn
+	for i, arg := range udfs[f.Parent].args {
+		results = append(results, metadata.FunctionColumn{
+			Name:            arg,
+			FunctionName:    f.Parent,
+			OrdinalPosition: i + 1,
+			Type:            "IN",
+		})
+	}
+	return metadata.NewFunctionColumnSet(results), nil
+}
+
+// udf is a parsed SQL user-defined function.
+type udf struct {
+	args []string
+	body string
+}
+
+// udfCache caches the SQL user-defined functions, as the function arguments
+// are retrieved for each listed function.
+type udfCache struct {
+	once  sync.Once
+	funcs map[string]udf
+	err   error
+}
+
+// load loads the SQL user-defined functions on first use.
+func (c *udfCache) load(r MetadataReader) (map[string]udf, error) {
+	c.once.Do(func() {
+		c.funcs = make(map[string]udf)
+		rows, closeRows, err := r.Query(`SELECT name, create_query FROM system.functions WHERE origin = 'SQLUserDefined'`)
+		if err != nil {
+			// not supported before 21.10
+			return
+		}
+		defer closeRows()
+		for rows.Next() {
+			var name, createQuery string
+			if c.err = rows.Scan(&name, &createQuery); c.err != nil {
+				return
+			}
+			if udf, ok := parseUDF(createQuery); ok {
+				c.funcs[name] = udf
+			}
+		}
+		c.err = rows.Err()
+	})
+	return c.funcs, c.err
+}
+
+// udfRE matches the CREATE FUNCTION statement of a SQL user-defined function,
+// capturing the lambda expression.
+var udfRE = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:OR\s+REPLACE\s+)?FUNCTION\s+(?:IF\s+NOT\s+EXISTS\s+)?\S+(?:\s+ON\s+CLUSTER\s+\S+)?\s+AS\s+(.*?)\s*;?\s*$`)
+
+// parseUDF parses the argument names and body of the lambda expression in the
+// CREATE FUNCTION statement of a SQL user-defined function, such as:
+//
+//	CREATE FUNCTION linear_equation AS (x, k, b) -> ((k * x) + b)
+func parseUDF(createQuery string) (udf, bool) {
+	m := udfRE.FindStringSubmatch(createQuery)
+	if m == nil {
+		return udf{}, false
+	}
+	lambda, depth, quote := m[1], 0, rune(0)
+	for i, c := range lambda {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '-' && depth == 0 && strings.HasPrefix(lambda[i:], "->"):
+			params := strings.TrimSpace(lambda[:i])
+			if strings.HasPrefix(params, "(") && strings.HasSuffix(params, ")") {
+				params = params[1 : len(params)-1]
+			}
+			var args []string
+			for _, arg := range strings.Split(params, ",") {
+				if arg = strings.TrimSpace(arg); arg != "" {
+					args = append(args, arg)
+				}
+			}
+			return udf{
+				args: args,
+				body: strings.TrimSpace(lambda[i+2:]),
+			}, true
+		}
+	}
+	return udf{}, false
+}
+
+func (r MetadataReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  database AS Schema,
+  name AS Name,
+  formatReadableSize(COALESCE(total_bytes, 0)) AS Size,
+  comment AS Comment,
+  as_select AS Definition
+FROM
+  system.tables`
+	conds := []string{"engine LIKE 'MaterializedView'"}
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "database NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "database LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "Schema, Name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.MaterializedView
+	for rows.Next() {
+		rec := metadata.MaterializedView{
+			RefreshMode: "ON INSERT",
+		}
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Size, &rec.Comment, &rec.Definition); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	refreshes, err := r.viewRefreshes()
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if v, ok := refreshes[results[i].Schema+"."+results[i].Name]; ok {
+			results[i].IsPopulated = v.IsPopulated
+			results[i].LastRefresh = v.LastRefresh
+			results[i].RefreshMode = v.RefreshMode
+		}
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
+// ForeignTables lists the tables using engines that read data stored outside
+// of the server (including the tables created from table functions such as
+// s3() or url(), which use the engine of the function), and the external
+// dictionaries. Server is the first quoted argument or setting of the engine,
+// usually the host, URL, or path the data is read from.
+func (r MetadataReader) ForeignTables(f metadata.Filter) (*metadata.ForeignTableSet, error) {
+	qstr := `SELECT * FROM (
+  SELECT
+    database AS Schema,
+    name AS Name,
+    'EXTERNAL TABLE' AS Type,
+    extract(engine_full, '''([^'']*)''') AS Server,
+    engine AS Wrapper,
+    engine_full AS Options,
+    comment AS Comment
+  FROM
+    system.tables
+  WHERE
+    engine IN (` + externalEngines + `)
+  UNION ALL
+  SELECT
+    database AS Schema,
+    name AS Name,
+    'DICTIONARY' AS Type,
+    source AS Server,
+    type AS Wrapper,
+    concat('LIFETIME(MIN ', toString(lifetime_min), ' MAX ', toString(lifetime_max), ')') AS Options,
+    comment AS Comment
+  FROM
+    system.dictionaries
+)`
+	var conds []string
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "Schema NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "Schema LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "Name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "Schema, Name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.ForeignTable
+	for rows.Next() {
+		var rec metadata.ForeignTable
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.Server, &rec.Wrapper, &rec.Options, &rec.Comment); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewForeignTableSet(results), nil
+}
+
+// Dictionaries lists the dictionaries, with their status, layout, source,
+// and cache hit rate, and the statement creating them, when created with DDL
+// instead of the server configuration.
+func (r MetadataReader) Dictionaries(f metadata.Filter) (*metadata.DictionarySet, error) {
+	qstr := `SELECT
+  d.database,
+  d.name,
+  toString(d.status),
+  d.type,
+  d.source,
+  concat('MIN ', toString(d.lifetime_min), ' MAX ', toString(d.lifetime_max)),
+  if(d.query_count = 0, '', concat(toString(round(d.hit_rate * 100, 2)), '%')),
+  toInt64(d.element_count),
+  formatReadableSize(d.bytes_allocated),
+  if(toUnixTimestamp(d.last_successful_update_time) = 0, '', toString(d.last_successful_update_time)),
+  d.last_exception,
+  t.create_table_query,
+  d.comment
+FROM
+  system.dictionaries d
+  LEFT JOIN system.tables t ON t.database = d.database AND t.name = d.name`
+	var conds []string
+	var vals []interface{}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "d.database LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "d.name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "d.database, d.name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Dictionary
+	for rows.Next() {
+		var rec metadata.Dictionary
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Status, &rec.Layout, &rec.Source, &rec.Lifetime, &rec.HitRate, &rec.Elements, &rec.Size, &rec.LastUpdate, &rec.LastError, &rec.Definition, &rec.Comment); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewDictionarySet(results), nil
+}
+
+// QueryLog lists the n most recent queries of system.query_log, finished or
+// failed, matching the query id of the filter.
+func (r MetadataReader) QueryLog(f metadata.Filter, n int) (*metadata.LoggedQuerySet, error) {
+	qstr := `SELECT
+  query_id,
+  user,
+  current_database,
+  toString(type),
+  toString(query_start_time),
+  concat(toString(query_duration_ms), ' ms'),
+  toInt64(read_rows),
+  formatReadableSize(read_bytes),
+  toInt64(written_rows),
+  toInt64(result_rows),
+  formatReadableSize(memory_usage),
+  exception,
+  query
+FROM
+  system.query_log`
+	conds := []string{"type != 'QueryStart'"}
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "query_id LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "event_time_microseconds DESC\nLIMIT "+strconv.Itoa(n), vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.LoggedQuery
+	for rows.Next() {
+		var rec metadata.LoggedQuery
+		if err := rows.Scan(&rec.ID, &rec.User, &rec.Database, &rec.Type, &rec.Started, &rec.Duration, &rec.ReadRows, &rec.ReadBytes, &rec.WrittenRows, &rec.ResultRows, &rec.Memory, &rec.Error, &rec.Query); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewLoggedQuerySet(results), nil
+}
+
+// externalEngines are the table engines reading data stored outside of the
+// server.
+const externalEngines = `'AzureBlobStorage', 'AzureQueue', 'DeltaLake', 'ExternalDistributed', 'File', 'HDFS', 'Hive', 'Hudi', 'Iceberg', 'JDBC', 'Kafka', 'MongoDB', 'MySQL', 'NATS', 'ODBC', 'PostgreSQL', 'RabbitMQ', 'Redis', 'S3', 'S3Queue', 'SQLite', 'URL'`
+
+// viewRefreshes returns the refresh state of refreshable materialized views,
+// keyed by their qualified name. Servers older than 23.12 do not have
+// system.view_refreshes, so an empty map is returned when it is missing.
+func (r MetadataReader) viewRefreshes() (map[string]metadata.MaterializedView, error) {
+	qstr := `SELECT
+  database,
+  view,
+  IF(last_refresh_time IS NULL, 'NO', 'YES'),
+  COALESCE(toString(last_refresh_time), ''),
+  'REFRESH (' || status || ')'
+FROM
+  system.view_refreshes`
+	refreshes := make(map[string]metadata.MaterializedView)
+	rows, closeRows, err := r.query(qstr, nil, "")
+	if err != nil {
+		// not supported by this server version
+		return refreshes, nil
+	}
+	defer closeRows()
+	for rows.Next() {
+		var rec metadata.MaterializedView
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.IsPopulated, &rec.LastRefresh, &rec.RefreshMode); err != nil {
+			return nil, err
+		}
+		refreshes[rec.Schema+"."+rec.Name] = rec
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return refreshes, nil
+}
+
+func (r MetadataReader) SpatialColumns(f metadata.Filter) (*metadata.SpatialColumnSet, error) {
+	qstr := `SELECT
+  database AS Schema,
+  table AS Table,
+  name AS Name,
+  type AS Type
+FROM
+  system.columns`
+	conds := []string{"type IN ('Point', 'Ring', 'LineString', 'MultiLineString', 'Polygon', 'MultiPolygon')"}
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "database NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "database LIKE ?")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "table LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "Schema, Table, position", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.SpatialColumn
+	for rows.Next() {
+		// geo types are planar, without a spatial reference system or index
+		rec := metadata.SpatialColumn{
+			Dimensions: "2",
+		}
+		if err := rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &rec.Type); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSpatialColumnSet(results), nil
+}
+
+// Projections lists the projections of tables. Older servers do not have
+// system.projections, so only the names of the projections with active parts
+// are listed from system.projection_parts.
+func (r MetadataReader) Projections(f metadata.Filter) (*metadata.ProjectionSet, error) {
+	var conds []string
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "database NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "database LIKE ?")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "table LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(`SELECT
+  database AS Schema,
+  table AS Table,
+  name AS Name,
+  upper(toString(type)) AS Type,
+  query AS Definition
+FROM
+  system.projections`, conds, "Schema, Table, Name", vals...)
+	if err != nil {
+		rows, closeRows, err = r.query(`SELECT DISTINCT
+  database AS Schema,
+  table AS Table,
+  name AS Name,
+  '' AS Type,
+  '' AS Definition
+FROM
+  system.projection_parts`, append(conds, "active"), "Schema, Table, Name", vals...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Projection
+	for rows.Next() {
+		var rec metadata.Projection
+		if err := rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &rec.Type, &rec.Definition); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewProjectionSet(results), nil
+}
+
+func (r MetadataReader) ServerConfig(f metadata.Filter) (*metadata.ServerConfigSet, error) {
+	qstr := `SELECT
+  name AS Name,
+  value AS Value,
+  IF(changed, 'YES', 'NO') AS Changed,
+  type AS Type,
+  description AS Description
+FROM
+  system.settings`
+	var conds []string
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "Name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.ServerConfig
+	for rows.Next() {
+		rec := metadata.ServerConfig{}
+		if err := rows.Scan(&rec.Name, &rec.Value, &rec.Changed, &rec.Unit, &rec.Description); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewServerConfigSet(results), nil
+}
+
+func (r MetadataReader) Activity(f metadata.Filter) (*metadata.ActivitySet, error) {
+	qstr := `SELECT
+  query_id AS ID,
+  user AS User,
+  current_database AS Database,
+  toString(address) AS Client,
+  IF(is_cancelled, 'cancelled', 'running') AS State,
+  toString(now() - toIntervalSecond(toUInt64(elapsed))) AS Started,
+  formatReadableTimeDelta(elapsed) AS Duration,
+  query AS Query
+FROM
+  system.processes`
+	var conds []string
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "user LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "elapsed DESC", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Activity
+	for rows.Next() {
+		rec := metadata.Activity{}
+		if err := rows.Scan(&rec.ID, &rec.User, &rec.Database, &rec.Client, &rec.State, &rec.Started, &rec.Duration, &rec.Query); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewActivitySet(results), nil
+}
+
+// Sizes of tables, from their data parts. Parts that are no longer active
+// (ie, replaced by merges) and are waiting to be removed are reported as bloat.
+func (r MetadataReader) Sizes(f metadata.Filter) (*metadata.SizeSet, error) {
+	qstr := `SELECT
+  database AS Schema,
+  table AS Name,
+  toString(sumIf(rows, active)) AS Rows,
+  formatReadableSize(sumIf(data_compressed_bytes, active)) AS Size,
+  formatReadableSize(sumIf(marks_bytes, active)) AS IndexSize,
+  formatReadableSize(sumIf(bytes_on_disk, active)) AS TotalSize,
+  IF(sumIf(bytes_on_disk, NOT active) = 0, '',
+    concat(toString(round(100 * sumIf(bytes_on_disk, NOT active) / sum(bytes_on_disk), 1)), '% inactive')) AS Bloat
+FROM
+  system.parts`
+	var conds []string
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "database NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "database LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "table LIKE ?")
+	}
+	if len(conds) != 0 {
+		qstr += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += "\nGROUP BY database, table"
+	rows, closeRows, err := r.query(qstr, nil, "sumIf(bytes_on_disk, active) DESC, Schema, Name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Size
+	for rows.Next() {
+		rec := metadata.Size{Type: "TABLE"}
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Rows, &rec.Size, &rec.IndexSize, &rec.TotalSize, &rec.Bloat); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSizeSet(results), nil
+}
+
+// Partitions of tables, summarized from their active data parts. ClickHouse
+// partitions are not declared individually, so a partition is named by its
+// value and has no bound.
+func (r MetadataReader) Partitions(f metadata.Filter) (*metadata.PartitionSet, error) {
+	qstr := `SELECT
+  p.database AS Schema,
+  p.table AS Table,
+  p.partition AS Name,
+  t.partition_key AS Key,
+  toString(sum(p.rows)) AS Rows,
+  formatReadableSize(sum(p.bytes_on_disk)) AS Size,
+  toString(count()) AS Parts
+FROM
+  system.parts p
+  JOIN system.tables t ON t.database = p.database AND t.name = p.table`
+	conds := []string{"p.active", "t.partition_key != ''"}
+	var vals []interface{}
+	if !f.WithSystem {
+		conds = append(conds, "p.database NOT LIKE 'system'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "p.database LIKE ?")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "p.table LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "p.partition LIKE ?")
+	}
+	qstr += "\nWHERE " + strings.Join(conds, " AND ")
+	qstr += "\nGROUP BY p.database, p.table, p.partition, t.partition_key"
+	rows, closeRows, err := r.query(qstr, nil, "Schema, Table, Name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Partition
+	for rows.Next() {
+		var rec metadata.Partition
+		if err := rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &rec.Key, &rec.Rows, &rec.Size, &rec.Parts); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewPartitionSet(results), nil
+}
+
+// Roles lists the users and roles, with the roles granted to them. Users and
+// roles granted ALL privileges on all databases are superusers. Older servers
+// do not report when the password of a user expires.
+func (r MetadataReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	var conds []string
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "Name LIKE ?")
+	}
+	rows, closeRows, err := r.query(`SELECT * FROM (
+  SELECT name AS Name, 'YES' AS Login, ifNull(toString(valid_until), '') AS ValidUntil FROM system.users
+  UNION ALL
+  SELECT name AS Name, 'NO' AS Login, '' AS ValidUntil FROM system.roles
+)`, conds, "Name", vals...)
+	if err != nil {
+		rows, closeRows, err = r.query(`SELECT * FROM (
+  SELECT name AS Name, 'YES' AS Login, '' AS ValidUntil FROM system.users
+  UNION ALL
+  SELECT name AS Name, 'NO' AS Login, '' AS ValidUntil FROM system.roles
+)`, conds, "Name", vals...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Role
+	for rows.Next() {
+		rec := metadata.Role{Superuser: metadata.NO}
+		if err := rows.Scan(&rec.Name, &rec.Login, &rec.ValidUntil); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	if len(results) == 0 {
+		return metadata.NewRoleSet(results), nil
+	}
+	// privileges
+	privs := map[string][2]bool{}
+	rows, closeRows, err = r.query(`SELECT
+  coalesce(user_name, role_name) AS Name,
+  max(access_type = 'ALL' AND database IS NULL) AS All,
+  max(grant_option) AS GrantOption
+FROM
+  system.grants
+GROUP BY Name`, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	for rows.Next() {
+		var name string
+		var all, grantOption uint8
+		if err := rows.Scan(&name, &all, &grantOption); err != nil {
+			return nil, err
+		}
+		privs[name] = [2]bool{all != 0, grantOption != 0}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	// granted roles
+	members := map[string][]string{}
+	rows, closeRows, err = r.query(`SELECT
+  coalesce(user_name, role_name) AS Name,
+  granted_role_name AS Role,
+  with_admin_option AS Admin
+FROM
+  system.role_grants`, nil, "Name, Role")
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	for rows.Next() {
+		var name, role string
+		var admin uint8
+		if err := rows.Scan(&name, &role, &admin); err != nil {
+			return nil, err
+		}
+		if admin != 0 {
+			role += " (admin)"
+		}
+		members[name] = append(members[name], role)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	for i := range results {
+		p := privs[results[i].Name]
+		if p[0] {
+			results[i].Superuser = metadata.YES
+		}
+		if p[1] {
+			results[i].Attributes = "Grant option"
+		}
+		results[i].MemberOf = strings.Join(members[results[i].Name], ", ")
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// CurrentSchema returns the current database of the session.
+func (r MetadataReader) CurrentSchema() ([]string, error) {
+	var schema string
+	if err := r.scanRow(`SELECT currentDatabase()`, []interface{}{&schema}); err != nil {
+		return nil, err
+	}
+	return []string{schema}, nil
+}
+
+// columnStatsSampleRows is the maximum number of rows of a table sampled by
+// ColumnStats.
+const columnStatsSampleRows = 100000
+
+// columnStatsTopN is the number of most common values retrieved by
+// ColumnStats.
+const columnStatsTopN = 10
+
+// ColumnStats lists the statistics of table columns. The average width is
+// computed from the uncompressed size of the column in system.columns. The
+// remaining statistics are computed with uniq() and topK() over a sample of at
+// most columnStatsSampleRows rows, using the table's SAMPLE BY key when
+// defined, and are only computed for MergeTree, Memory, and Log tables, as
+// reading from other engines (such as Kafka) may have side effects.
+func (r MetadataReader) ColumnStats(f metadata.Filter) (*metadata.ColumnStatSet, error) {
+	qstr := `SELECT
+  c.database,
+  c.table,
+  c.name,
+  c.type,
+  c.default_kind,
+  COALESCE(t.total_rows, 0) AS total,
+  IF(total > 0, intDiv(c.data_uncompressed_bytes, total), 0),
+  t.sampling_key != '',
+  t.engine LIKE '%MergeTree' OR t.engine IN ('Memory', 'Log', 'TinyLog', 'StripeLog')
+FROM
+  system.columns c
+  JOIN system.tables t ON t.database = c.database AND t.name = c.table`
+	vals := []interface{}{f.Parent}
+	conds := []string{"c.table LIKE ?"}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "c.database LIKE ?")
+	} else {
+		conds = append(conds, "c.database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema')")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "c.name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "c.database, c.table, c.position", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.ColumnStat
+	var tables []columnStatsTable
+	for rows.Next() {
+		var rec metadata.ColumnStat
+		var typ, kind string
+		var n int64
+		var sampling, readable bool
+		if err := rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &typ, &kind, &n, &rec.AvgWidth, &sampling, &readable); err != nil {
+			return nil, err
+		}
+		if len(tables) == 0 || tables[len(tables)-1].schema != rec.Schema || tables[len(tables)-1].name != rec.Table {
+			tables = append(tables, columnStatsTable{
+				schema:   rec.Schema,
+				name:     rec.Table,
+				rows:     n,
+				sampling: sampling,
+				readable: readable,
+			})
+		}
+		if kind != "EPHEMERAL" && sampleableType(typ) {
+			t := &tables[len(tables)-1]
+			t.cols, t.types = append(t.cols, len(results)), append(t.types, typ)
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	extended := false
+	for _, typ := range f.Types {
+		extended = extended || typ == "extended"
+	}
+	for _, t := range tables {
+		if !t.readable || len(t.cols) == 0 {
+			continue
+		}
+		if err := r.sampleColumnStats(results, t, extended); err != nil {
+			return nil, err
+		}
+	}
+	return metadata.NewColumnStatSet(results), nil
+}
+
+// columnStatsTable is a table whose column statistics are sampled.
+type columnStatsTable struct {
+	schema, name       string
+	rows               int64
+	sampling, readable bool
+	// cols are the indexes of the sampled columns in the results.
+	cols  []int
+	types []string
+}
+
+// sampleColumnStats computes the statistics of the table's columns from a
+// sample of the table's rows.
+func (r MetadataReader) sampleColumnStats(results []metadata.ColumnStat, t columnStatsTable, extended bool) error {
+	src := quoteIdentifier(t.schema) + "." + quoteIdentifier(t.name)
+	if t.sampling {
+		src += fmt.Sprintf(" SAMPLE %d", columnStatsSampleRows)
+	}
+	names := make([]string, len(t.cols))
+	for i, j := range t.cols {
+		names[i] = quoteIdentifier(results[j].Name)
+	}
+	src = fmt.Sprintf("(SELECT %s FROM %s LIMIT %d)", strings.Join(names, ", "), src, columnStatsSampleRows)
+	exprs := []string{"count()"}
+	for i, name := range names {
+		exprs = append(exprs, "countIf(isNull("+name+"))", "uniq("+name+")")
+		if !extended {
+			continue
+		}
+		mean := "''"
+		if numericTypeRE.MatchString(t.types[i]) {
+			mean = "toString(avg(" + name + "))"
+		}
+		exprs = append(exprs,
+			"toString(min("+name+"))",
+			"toString(max("+name+"))",
+			mean,
+			fmt.Sprintf("arrayMap(x -> toString(x), topK(%d)(%s))", columnStatsTopN, name),
+		)
+	}
+	var n int64
+	dest := []interface{}{&n}
+	nulls := make([]int64, len(t.cols))
+	for i, j := range t.cols {
+		rec := &results[j]
+		dest = append(dest, &nulls[i], &rec.NumDistinct)
+		if extended {
+			dest = append(dest, &rec.Min, &rec.Max, &rec.Mean, &rec.TopN)
+		}
+	}
+	if err := r.scanRow("SELECT "+strings.Join(exprs, ", ")+" FROM "+src, dest); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	for i, j := range t.cols {
+		rec := &results[j]
+		rec.NullFrac = float64(nulls[i]) / float64(n)
+		// a column unique in the sample is assumed to be unique in the table
+		if rec.NumDistinct == n && n < t.rows {
+			rec.NumDistinct = t.rows
+		}
+	}
+	if !extended {
+		return nil
+	}
+	// count the most common values in the sample
+	exprs, vals := nil, []interface{}{}
+	for i, j := range t.cols {
+		for _, v := range results[j].TopN {
+			exprs, vals = append(exprs, "countIf(toString("+names[i]+") = ?)"), append(vals, v)
+		}
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+	counts := make([]int64, len(exprs))
+	dest = make([]interface{}, len(exprs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := r.scanRow("SELECT "+strings.Join(exprs, ", ")+" FROM "+src, dest, vals...); err != nil {
+		return err
+	}
+	for _, j := range t.cols {
+		rec := &results[j]
+		rec.TopNFreqs = make([]float64, len(rec.TopN))
+		for k := range rec.TopN {
+			rec.TopNFreqs[k], counts = float64(counts[0])/float64(n), counts[1:]
+		}
+	}
+	return nil
+}
+
+// scanRow scans the single row returned by the query into dest.
+func (r MetadataReader) scanRow(qstr string, dest []interface{}, vals ...interface{}) error {
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		return err
+	}
+	defer closeRows()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dest...)
+}
+
+// numericTypeRE matches numeric column types.
+var numericTypeRE = regexp.MustCompile(`^(Nullable\(|LowCardinality\()*(U?Int|Float|Decimal)`)
+
+// sampleableType returns true when statistics can be computed for columns of
+// the type.
+func sampleableType(typ string) bool {
+	for _, s := range []string{"AggregateFunction(", "Object(", "JSON", "Map("} {
+		if strings.Contains(typ, s) {
+			return false
+		}
+	}
+	return true
+}
+
 func (r MetadataReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
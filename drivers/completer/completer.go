@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"unicode"
@@ -258,6 +259,16 @@ func WithBeforeComplete(f CompleteFunc) Option {
 	}
 }
 
+// WithIdentifierQuoting option, quoting the completed identifiers that need
+// quoting with quote. fold returns the case of an unquoted identifier as
+// stored by the database, such as strings.ToLower for PostgreSQL, and is nil
+// when unquoted identifiers are not folded.
+func WithIdentifierQuoting(quote, fold func(string) string) Option {
+	return func(c *completer) {
+		c.quote, c.fold = quote, fold
+	}
+}
+
 // completer based on https://github.com/postgres/postgres/blob/9f3665fbfc34b963933e51778c7feaa8134ac885/src/bin/psql/tab-complete.c
 type completer struct {
 	db                metadata.DB
@@ -268,6 +279,8 @@ type completer struct {
 	backslashCommands []string
 	connStrings       []string
 	beforeComplete    CompleteFunc
+	quote             func(string) string
+	fold              func(string) string
 }
 
 // CompleteFunc returns patterns completing current text, using previous words as context
@@ -326,12 +339,12 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	}
 	if len(previousWords) == 0 {
 		/* If no previous word, suggest one of the basic sql commands */
-		return CompleteFromList(text, c.sqlStartCommands...)
+		return CompleteKeywords(text, c.sqlStartCommands...)
 	}
 	/* DELETE --- can be inside EXPLAIN, RULE, etc */
 	/* ... despite which, only complete DELETE with FROM at start of line */
 	if matches(IGNORE_CASE, previousWords, "DELETE") {
-		return CompleteFromList(text, "FROM")
+		return CompleteKeywords(text, "FROM")
 	}
 	/* Complete DELETE FROM with a list of tables */
 	if TailMatches(IGNORE_CASE, previousWords, "DELETE", "FROM") {
@@ -339,24 +352,24 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	}
 	/* Complete DELETE FROM <table> */
 	if TailMatches(IGNORE_CASE, previousWords, "DELETE", "FROM", "*") {
-		return CompleteFromList(text, "USING", "WHERE")
+		return CompleteKeywords(text, "USING", "WHERE")
 	}
 	/* XXX: implement tab completion for DELETE ... USING */
 
 	/* Complete CREATE */
 	if TailMatches(IGNORE_CASE, previousWords, "CREATE") {
-		return CompleteFromList(text, "DATABASE", "SEQUENCE", "TABLE", "VIEW", "TEMPORARY")
+		return CompleteKeywords(text, "DATABASE", "SEQUENCE", "TABLE", "VIEW", "TEMPORARY")
 	}
 	if TailMatches(IGNORE_CASE, previousWords, "CREATE", "TEMP|TEMPORARY") {
-		return CompleteFromList(text, "TABLE", "VIEW")
+		return CompleteKeywords(text, "TABLE", "VIEW")
 	}
 	if TailMatches(IGNORE_CASE, previousWords, "CREATE", "TABLE", "*") || TailMatches(IGNORE_CASE, previousWords, "CREATE", "TEMP|TEMPORARY", "TABLE", "*") {
-		return CompleteFromList(text, "(")
+		return CompleteKeywords(text, "(")
 	}
 	/* INSERT --- can be inside EXPLAIN, RULE, etc */
 	/* Complete INSERT with "INTO" */
 	if TailMatches(IGNORE_CASE, previousWords, "INSERT") {
-		return CompleteFromList(text, "INTO")
+		return CompleteKeywords(text, "INTO")
 	}
 	/* Complete INSERT INTO with table names */
 	if TailMatches(IGNORE_CASE, previousWords, "INSERT", "INTO") {
@@ -372,7 +385,7 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	 * "TABLE" or "DEFAULT VALUES" or "OVERRIDING"
 	 */
 	if TailMatches(IGNORE_CASE, previousWords, "INSERT", "INTO", "*") {
-		return CompleteFromList(text, "(", "DEFAULT VALUES", "SELECT", "TABLE", "VALUES", "OVERRIDING")
+		return CompleteKeywords(text, "(", "DEFAULT VALUES", "SELECT", "TABLE", "VALUES", "OVERRIDING")
 	}
 
 	/*
@@ -381,22 +394,22 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	 */
 	if TailMatches(IGNORE_CASE, previousWords, "INSERT", "INTO", "*", "*") &&
 		strings.HasSuffix(previousWords[0], ")") {
-		return CompleteFromList(text, "SELECT", "TABLE", "VALUES", "OVERRIDING")
+		return CompleteKeywords(text, "SELECT", "TABLE", "VALUES", "OVERRIDING")
 	}
 
 	/* Complete OVERRIDING */
 	if TailMatches(IGNORE_CASE, previousWords, "OVERRIDING") {
-		return CompleteFromList(text, "SYSTEM VALUE", "USER VALUE")
+		return CompleteKeywords(text, "SYSTEM VALUE", "USER VALUE")
 	}
 
 	/* Complete after OVERRIDING clause */
 	if TailMatches(IGNORE_CASE, previousWords, "OVERRIDING", "*", "VALUE") {
-		return CompleteFromList(text, "SELECT", "TABLE", "VALUES")
+		return CompleteKeywords(text, "SELECT", "TABLE", "VALUES")
 	}
 
 	/* Insert an open parenthesis after "VALUES" */
 	if TailMatches(IGNORE_CASE, previousWords, "VALUES") && !TailMatches(IGNORE_CASE, previousWords, "DEFAULT", "VALUES") {
-		return CompleteFromList(text, "(")
+		return CompleteKeywords(text, "(")
 	}
 	/* UPDATE --- can be inside EXPLAIN, RULE, etc */
 	/* If prev. word is UPDATE suggest a list of tables */
@@ -405,7 +418,7 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	}
 	/* Complete UPDATE <table> with "SET" */
 	if TailMatches(IGNORE_CASE, previousWords, "UPDATE", "*") {
-		return CompleteFromList(text, "SET")
+		return CompleteKeywords(text, "SET")
 	}
 	/* Complete UPDATE <table> SET with list of attributes */
 	if TailMatches(IGNORE_CASE, previousWords, "UPDATE", "*", "SET") {
@@ -413,7 +426,7 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 	}
 	/* UPDATE <table> SET <attr> = */
 	if TailMatches(IGNORE_CASE, previousWords, "UPDATE", "*", "SET", "!*=") {
-		return CompleteFromList(text, "=")
+		return CompleteKeywords(text, "=")
 	}
 	/* WHERE */
 	/* Simple case of the word before the where being the table name */
@@ -482,9 +495,9 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 		return c.completeWithCatalogs(text)
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`) {
-		return CompleteFromList(text, `border`, `columns`, `expanded`, `fieldsep`, `fieldsep_zero`,
-			`footer`, `format`, `linestyle`, `null`, `numericlocale`, `pager`, `pager_min_lines`,
-			`recordsep`, `recordsep_zero`, `tableattr`, `title`, `title`, `tuples_only`,
+		return CompleteFromList(text, `border`, `chart_type`, `columns`, `expanded`, `fieldsep`, `fieldsep_zero`,
+			`float_precision`, `footer`, `format`, `linestyle`, `maxcolwidth`, `null`, `nullstyle`, `numericlocale`, `pager`, `pager_min_lines`,
+			`recordsep`, `recordsep_zero`, `tableattr`, `thousands_sep`, `title`, `title`, `tuples_only`,
 			`unicode_border_linestyle`, `unicode_column_linestyle`, `unicode_header_linestyle`)
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `expanded`) {
@@ -497,7 +510,13 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 		return CompleteFromList(text, "on", "off")
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `format`) {
-		return CompleteFromList(text, "unaligned", "aligned", "wrapped", "html", "asciidoc", "latex", "latex-longtable", "troff-ms", "csv", "json", "vertical")
+		return CompleteFromList(text, "unaligned", "aligned", "wrapped", "html", "asciidoc", "latex", "latex-longtable", "troff-ms", "csv", "json", "vertical", "chart", "interactive", "count")
+	}
+	if TailMatches(MATCH_CASE, previousWords, `\pset`, `chart_type`) {
+		return CompleteFromList(text, "bar", "line", "sparkline")
+	}
+	if TailMatches(MATCH_CASE, previousWords, `\pset`, `nullstyle`) {
+		return CompleteFromList(text, "text", "color", "symbol")
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `linestyle`) {
 		return CompleteFromList(text, "ascii", "old-ascii", "unicode")
@@ -509,11 +528,14 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 		TailMatches(MATCH_CASE, previousWords, `\pset`, `*`, `*`) {
 		return nil
 	}
+	if TailMatches(MATCH_CASE, previousWords, `\set`, `COMP_KEYWORD_CASE`) {
+		return CompleteFromList(text, "upper", "lower", "preserve-upper", "preserve-lower")
+	}
 	if TailMatches(MATCH_CASE, previousWords, `\?`) {
 		return CompleteFromList(text, "commands", "options", "variables")
 	}
 	// is suggesting basic sql commands better than nothing?
-	return CompleteFromList(text, c.sqlCommands...)
+	return CompleteKeywords(text, c.sqlCommands...)
 }
 
 func getPreviousWords(point int, buf []rune) []string {
@@ -686,6 +708,99 @@ func CompleteFromListCase(ct caseType, text []rune, options ...string) [][]rune
 	return result
 }
 
+// CompleteKeywords where keywords starts with text, ignoring case, in the case
+// set by the COMP_KEYWORD_CASE variable:
+//
+//	upper           complete keywords in upper case
+//	lower           complete keywords in lower case
+//	preserve-upper  complete keywords in the case of text, or upper case
+//	preserve-lower  complete keywords in the case of text, or lower case
+//
+// As only the remainder of a keyword is completed, text is kept as typed.
+func CompleteKeywords(text []rune, keywords ...string) [][]rune {
+	upper := true
+	switch env.All()["COMP_KEYWORD_CASE"] {
+	case "upper":
+	case "lower":
+		upper = false
+	case "preserve-lower":
+		upper = len(text) != 0 && unicode.IsUpper(text[0])
+	default:
+		upper = len(text) == 0 || !unicode.IsLower(text[0])
+	}
+	result := CompleteFromList(text, keywords...)
+	for i, match := range result {
+		if upper {
+			result[i] = []rune(strings.ToUpper(string(match)))
+		} else {
+			result[i] = []rune(strings.ToLower(string(match)))
+		}
+	}
+	return result
+}
+
+// completeIdentifiers where identifiers starts with text. Quoted identifiers
+// are matched in their case, and other identifiers ignoring case.
+func completeIdentifiers(text []rune, identifiers ...string) [][]rune {
+	if len(identifiers) == 0 {
+		return nil
+	}
+	prefix := string(text)
+	result := make([][]rune, 0, len(identifiers))
+	for _, id := range identifiers {
+		if len(id) < len(prefix) {
+			continue
+		}
+		if strings.ContainsAny(id, identifierQuotes) {
+			if !strings.HasPrefix(id, prefix) {
+				continue
+			}
+		} else if !strings.EqualFold(id[:len(prefix)], prefix) {
+			continue
+		}
+		result = append(result, []rune(id[len(prefix):]))
+	}
+	return result
+}
+
+// identifierQuotes are the characters starting a quoted identifier.
+const identifierQuotes = "\"`["
+
+// bareIdentifierRE matches identifiers not needing quotes.
+var bareIdentifierRE = regexp.MustCompile(`^[\pL_][\pL\pN_$]*$`)
+
+// reservedWords are the reserved keywords quoted when used as identifiers.
+var reservedWords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "AS": true, "ASC": true,
+	"BETWEEN": true, "BY": true, "CASE": true, "CHECK": true, "COLUMN": true,
+	"CONSTRAINT": true, "CREATE": true, "CROSS": true, "DEFAULT": true,
+	"DELETE": true, "DESC": true, "DISTINCT": true, "DROP": true, "ELSE": true,
+	"END": true, "EXCEPT": true, "FALSE": true, "FETCH": true, "FOR": true,
+	"FOREIGN": true, "FROM": true, "FULL": true, "GRANT": true, "GROUP": true,
+	"HAVING": true, "IN": true, "INNER": true, "INSERT": true,
+	"INTERSECT": true, "INTO": true, "IS": true, "JOIN": true, "LEFT": true,
+	"LIKE": true, "LIMIT": true, "NATURAL": true, "NOT": true, "NULL": true,
+	"OFFSET": true, "ON": true, "OR": true, "ORDER": true, "OUTER": true,
+	"PRIMARY": true, "REFERENCES": true, "RIGHT": true, "SELECT": true,
+	"SET": true, "TABLE": true, "THEN": true, "TO": true, "TRUE": true,
+	"UNION": true, "UNIQUE": true, "UPDATE": true, "USER": true, "USING": true,
+	"VALUES": true, "WHEN": true, "WHERE": true, "WITH": true,
+}
+
+// quoteIdentifier quotes name when it needs quoting: when it is not a bare
+// identifier, is a reserved keyword, or, for databases folding the case of
+// unquoted identifiers, is not in the folded case.
+func (c completer) quoteIdentifier(name string) string {
+	if c.quote == nil || name == "" {
+		return name
+	}
+	if bareIdentifierRE.MatchString(name) && !reservedWords[strings.ToUpper(name)] &&
+		(c.fold == nil || c.fold(name) == name) {
+		return name
+	}
+	return c.quote(name)
+}
+
 func completeFromVariables(text []rune, prefix, suffix string, needValue bool) [][]rune {
 	vars := env.All()
 	names := make([]string, 0, len(vars))
@@ -708,7 +823,7 @@ func (c completer) completeWithSelectables(text []rune) [][]rune {
 			},
 			func(res interface{}) string {
 				t := res.(*metadata.TableSet).Get()
-				return qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
+				return c.qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
 			},
 		)
 		names = append(names, tables...)
@@ -720,7 +835,7 @@ func (c completer) completeWithSelectables(text []rune) [][]rune {
 			},
 			func(res interface{}) string {
 				f := res.(*metadata.FunctionSet).Get()
-				return qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
+				return c.qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
 			},
 		)
 		names = append(names, functions...)
@@ -732,14 +847,13 @@ func (c completer) completeWithSelectables(text []rune) [][]rune {
 			},
 			func(res interface{}) string {
 				s := res.(*metadata.SequenceSet).Get()
-				return qualifiedIdentifier(filter, s.Catalog, s.Schema, s.Name)
+				return c.qualifiedIdentifier(filter, s.Catalog, s.Schema, s.Name)
 			},
 		)
 		names = append(names, sequences...)
 	}
 	sort.Strings(names)
-	// TODO make sure CompleteFromList would properly handle quoted identifiers
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithTables(text []rune, types []string) [][]rune {
@@ -757,12 +871,12 @@ func (c completer) completeWithTables(text []rune, types []string) [][]rune {
 		},
 		func(res interface{}) string {
 			t := res.(*metadata.TableSet).Get()
-			return qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
+			return c.qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
 		},
 	)
 	names = append(names, tables...)
 	sort.Strings(names)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithFunctions(text []rune, types []string) [][]rune {
@@ -779,12 +893,12 @@ func (c completer) completeWithFunctions(text []rune, types []string) [][]rune {
 		},
 		func(res interface{}) string {
 			f := res.(*metadata.FunctionSet).Get()
-			return qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
+			return c.qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
 		},
 	)
 	names = append(names, functions...)
 	sort.Strings(names)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithIndexes(text []rune) [][]rune {
@@ -800,12 +914,12 @@ func (c completer) completeWithIndexes(text []rune) [][]rune {
 		},
 		func(res interface{}) string {
 			f := res.(*metadata.IndexSet).Get()
-			return qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
+			return c.qualifiedIdentifier(filter, f.Catalog, f.Schema, f.Name)
 		},
 	)
 	names = append(names, indexes...)
 	sort.Strings(names)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithSequences(text []rune) [][]rune {
@@ -821,12 +935,12 @@ func (c completer) completeWithSequences(text []rune) [][]rune {
 		},
 		func(res interface{}) string {
 			s := res.(*metadata.SequenceSet).Get()
-			return qualifiedIdentifier(filter, s.Catalog, s.Schema, s.Name)
+			return c.qualifiedIdentifier(filter, s.Catalog, s.Schema, s.Name)
 		},
 	)
 	names = append(names, sequences...)
 	sort.Strings(names)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithSchemas(text []rune) [][]rune {
@@ -845,10 +959,10 @@ func (c completer) completeWithSchemas(text []rune) [][]rune {
 		},
 		func(res interface{}) string {
 			s := res.(*metadata.SchemaSet).Get()
-			return qualifiedIdentifier(filter, "", s.Catalog, s.Schema)
+			return c.qualifiedIdentifier(filter, "", s.Catalog, s.Schema)
 		},
 	)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithCatalogs(text []rune) [][]rune {
@@ -863,10 +977,10 @@ func (c completer) completeWithCatalogs(text []rune) [][]rune {
 		},
 		func(res interface{}) string {
 			s := res.(*metadata.CatalogSet).Get()
-			return s.Catalog
+			return c.quoteIdentifier(s.Catalog)
 		},
 	)
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) completeWithUpdatables(text []rune) [][]rune {
@@ -881,14 +995,13 @@ func (c completer) completeWithUpdatables(text []rune) [][]rune {
 			},
 			func(res interface{}) string {
 				t := res.(*metadata.TableSet).Get()
-				return qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
+				return c.qualifiedIdentifier(filter, t.Catalog, t.Schema, t.Name)
 			},
 		)
 		names = append(names, tables...)
 	}
 	sort.Strings(names)
-	// TODO make sure CompleteFromList would properly handle quoted identifiers
-	return CompleteFromList(text, names...)
+	return completeIdentifiers(text, names...)
 }
 
 func (c completer) getNamespaces(f metadata.Filter) []string {
@@ -898,7 +1011,7 @@ func (c completer) getNamespaces(f metadata.Filter) []string {
 			catalogs := c.getNames(
 				func() (iterator, error) { return r.Catalogs(metadata.Filter{}) },
 				func(res interface{}) string {
-					return res.(*metadata.CatalogSet).Get().Catalog
+					return c.quoteIdentifier(res.(*metadata.CatalogSet).Get().Catalog)
 				},
 			)
 			names = append(names, catalogs...)
@@ -919,7 +1032,7 @@ func (c completer) getNamespaces(f metadata.Filter) []string {
 			},
 			func(res interface{}) string {
 				s := res.(*metadata.SchemaSet).Get()
-				return qualifiedIdentifier(f, "", s.Catalog, s.Schema)
+				return c.qualifiedIdentifier(f, "", s.Catalog, s.Schema)
 			},
 		)
 		names = append(names, schemas...)
@@ -936,7 +1049,7 @@ func (c completer) completeWithAttributes(ct caseType, selectable string, text [
 				return r.Columns(parent)
 			},
 			func(res interface{}) string {
-				return res.(*metadata.ColumnSet).Get().Name
+				return c.quoteIdentifier(res.(*metadata.ColumnSet).Get().Name)
 			},
 		)
 		names = append(names, columns...)
@@ -950,32 +1063,29 @@ func (c completer) completeWithAttributes(ct caseType, selectable string, text [
 				return r.Functions(filter)
 			},
 			func(res interface{}) string {
-				return res.(*metadata.FunctionSet).Get().Name
+				return c.quoteIdentifier(res.(*metadata.FunctionSet).Get().Name)
 			},
 		)
 		names = append(names, functions...)
 	}
-	names = append(names, options...)
-	return CompleteFromList(text, names...)
+	return append(completeIdentifiers(text, names...), CompleteKeywords(text, options...)...)
 }
 
 // parseIdentifier into catalog, schema and name
 func parseIdentifier(name string) metadata.Filter {
-	// TODO handle quoted identifiers
 	result := metadata.Filter{}
-	if !strings.ContainsRune(name, '.') {
-		result.Name = name + "%"
+	parts := splitIdentifier(name)
+	switch len(parts) {
+	case 1:
+		result.Name = parts[0] + "%"
 		result.OnlyVisible = true
-	} else {
-		parts := strings.SplitN(name, ".", 3)
-		if len(parts) == 2 {
-			result.Schema = parts[0]
-			result.Name = parts[1] + "%"
-		} else {
-			result.Catalog = parts[0]
-			result.Schema = parts[1]
-			result.Name = parts[2] + "%"
-		}
+	case 2:
+		result.Schema = parts[0]
+		result.Name = parts[1] + "%"
+	default:
+		result.Catalog = parts[0]
+		result.Schema = parts[1]
+		result.Name = parts[2] + "%"
 	}
 
 	if result.Schema != "" || len(result.Name) > 3 {
@@ -986,21 +1096,19 @@ func parseIdentifier(name string) metadata.Filter {
 
 // parseParentIdentifier into catalog, schema and parent
 func parseParentIdentifier(name string) metadata.Filter {
-	// TODO handle quoted identifiers
 	result := metadata.Filter{}
-	if !strings.ContainsRune(name, '.') {
-		result.Parent = name
+	parts := splitIdentifier(name)
+	switch len(parts) {
+	case 1:
+		result.Parent = parts[0]
 		result.OnlyVisible = true
-	} else {
-		parts := strings.SplitN(name, ".", 3)
-		if len(parts) == 2 {
-			result.Schema = parts[0]
-			result.Parent = parts[1]
-		} else {
-			result.Catalog = parts[0]
-			result.Schema = parts[1]
-			result.Parent = parts[2]
-		}
+	case 2:
+		result.Schema = parts[0]
+		result.Parent = parts[1]
+	default:
+		result.Catalog = parts[0]
+		result.Schema = parts[1]
+		result.Parent = parts[2]
 	}
 
 	if result.Schema != "" {
@@ -1009,15 +1117,32 @@ func parseParentIdentifier(name string) metadata.Filter {
 	return result
 }
 
-func qualifiedIdentifier(filter metadata.Filter, catalog, schema, name string) string {
-	// TODO handle quoted identifiers
+// splitIdentifier splits a (possibly partially typed) qualified identifier
+// into at most 3 parts, removing the quotes of quoted parts.
+func splitIdentifier(name string) []string {
+	parts := strings.SplitN(name, ".", 3)
+	for i, part := range parts {
+		if part == "" || !strings.ContainsRune(identifierQuotes, rune(part[0])) {
+			continue
+		}
+		q, end := part[:1], part[:1]
+		if q == "[" {
+			end = "]"
+		}
+		part = strings.TrimSuffix(part[1:], end)
+		parts[i] = strings.ReplaceAll(part, end+end, end)
+	}
+	return parts
+}
+
+func (c completer) qualifiedIdentifier(filter metadata.Filter, catalog, schema, name string) string {
 	if filter.Catalog != "" && filter.Schema != "" {
-		return catalog + "." + schema + "." + name
+		return c.quoteIdentifier(catalog) + "." + c.quoteIdentifier(schema) + "." + c.quoteIdentifier(name)
 	}
 	if filter.Schema != "" {
-		return schema + "." + name
+		return c.quoteIdentifier(schema) + "." + c.quoteIdentifier(name)
 	}
-	return name
+	return c.quoteIdentifier(name)
 }
 
 func (c completer) getNames(query func() (iterator, error), mapper func(interface{}) string) []string {
@@ -1,9 +1,11 @@
 package completer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/env"
 )
 
 func TestCompleter(t *testing.T) {
@@ -392,3 +394,58 @@ func (r mockReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
 		},
 	}), nil
 }
+
+func TestCompleteKeywords(t *testing.T) {
+	tests := []struct {
+		keywordCase string
+		text        string
+		exp         string
+	}{
+		{"preserve-upper", "", "SELECT"},
+		{"preserve-upper", "se", "lect"},
+		{"preserve-upper", "Se", "LECT"},
+		{"preserve-lower", "", "select"},
+		{"preserve-lower", "se", "lect"},
+		{"preserve-lower", "Se", "LECT"},
+		{"upper", "se", "LECT"},
+		{"lower", "SE", "lect"},
+	}
+	defer env.Set("COMP_KEYWORD_CASE", "preserve-upper")
+	for i, test := range tests {
+		if err := env.Set("COMP_KEYWORD_CASE", test.keywordCase); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		res := CompleteKeywords([]rune(test.text), "SELECT")
+		if len(res) != 1 || string(res[0]) != test.exp {
+			t.Errorf("test %d %s %q expected %q, got: %d matches", i, test.keywordCase, test.text, test.exp, len(res))
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	c := NewDefaultCompleter(WithIdentifierQuoting(quote, strings.ToLower)).(completer)
+	tests := []struct {
+		name, exp string
+	}{
+		{"film", "film"},
+		{"Film", `"Film"`},
+		{"film actor", `"film actor"`},
+		{"user", `"user"`},
+		{"1film", `"1film"`},
+		{`fi"lm`, `"fi""lm"`},
+	}
+	for i, test := range tests {
+		if s := c.quoteIdentifier(test.name); s != test.exp {
+			t.Errorf("test %d expected %s, got: %s", i, test.exp, s)
+		}
+	}
+	if f := parseIdentifier(`public."Fi`); f.Schema != "public" || f.Name != "Fi%" {
+		t.Errorf("expected public and Fi%%, got: %s and %s", f.Schema, f.Name)
+	}
+	if res := completeIdentifiers([]rune(`"Fi`), `"Film"`, `"film"`, "films"); len(res) != 1 || string(res[0]) != `lm"` {
+		t.Errorf("expected %q, got: %d matches", `lm"`, len(res))
+	}
+}
@@ -0,0 +1,234 @@
+package drivers
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/text"
+)
+
+// CopyInOptions are the options for copying data into a table.
+type CopyInOptions struct {
+	// Format is the format of the data, either csv, or text (tab separated
+	// values, with \N as null).
+	Format string
+	// Header skips the first line of the data.
+	Header bool
+	// Statement is the statement copying data from standard input the options
+	// were parsed from, if any, for drivers passing it verbatim to the server.
+	Statement string
+	// Other are the names of the other options of the statement, which drivers
+	// not passing the statement to the server cannot honour.
+	Other []string
+}
+
+// CopyIn copies the data read from r into the table on the database for a
+// driver, streaming the data to the native bulk load of the driver when
+// available.
+func CopyIn(ctx context.Context, u *dburl.URL, db *sql.DB, r io.Reader, table string, opts CopyInOptions) (int64, error) {
+	d, ok := drivers[u.Driver]
+	if !ok {
+		return 0, WrapErr(u.Driver, text.ErrDriverNotAvailable)
+	}
+	if d.CopyIn == nil {
+		return 0, fmt.Errorf(text.NotSupportedByDriver, `\copyin`, u.Driver)
+	}
+	switch {
+	case opts.Format == "":
+		opts.Format = "csv"
+	case opts.Format == "csv", opts.Format == "text":
+	case opts.Statement == "":
+		// other formats are only passed to the server with the statement
+		return 0, text.ErrInvalidCopyFormat
+	}
+	return d.CopyIn(ctx, db, r, table, opts)
+}
+
+// ParseCopyIn parses a statement copying data from standard input for a
+// driver, such as COPY ... FROM STDIN, returning the table (with optional
+// column list) and options of the copy.
+func ParseCopyIn(u *dburl.URL, sqlstr string) (string, CopyInOptions, bool) {
+	if d, ok := drivers[u.Driver]; ok && d.ParseCopyIn != nil && d.CopyIn != nil {
+		return d.ParseCopyIn(sqlstr)
+	}
+	return "", CopyInOptions{}, false
+}
+
+// NewRecordReader returns a func that reads the records of the data in r, one
+// at a time, returning io.EOF after the last record. Null values are returned
+// as nil, which for csv are empty fields.
+func NewRecordReader(r io.Reader, opts CopyInOptions) func() ([]interface{}, error) {
+	var next func() ([]interface{}, error)
+	switch opts.Format {
+	case "text":
+		br := bufio.NewReader(r)
+		next = func() ([]interface{}, error) {
+			line, err := br.ReadString('\n')
+			switch {
+			case err == io.EOF && line == "":
+				return nil, io.EOF
+			case err != nil && err != io.EOF:
+				return nil, err
+			}
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if line == `\.` {
+				return nil, io.EOF
+			}
+			fields := strings.Split(line, "\t")
+			values := make([]interface{}, len(fields))
+			for i, field := range fields {
+				if field != `\N` {
+					values[i] = textUnescaper.Replace(field)
+				}
+			}
+			return values, nil
+		}
+	default:
+		cr := csv.NewReader(r)
+		next = func() ([]interface{}, error) {
+			fields, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(fields))
+			for i, field := range fields {
+				if field != "" {
+					values[i] = field
+				}
+			}
+			return values, nil
+		}
+	}
+	if !opts.Header {
+		return next
+	}
+	return func() ([]interface{}, error) {
+		if opts.Header {
+			opts.Header = false
+			if _, err := next(); err != nil {
+				return nil, err
+			}
+		}
+		return next()
+	}
+}
+
+// textUnescaper unescapes the backslash sequences of the text format.
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\t`, "\t",
+	`\n`, "\n",
+	`\r`, "\r",
+	`\b`, "\b",
+	`\f`, "\f",
+	`\v`, "\v",
+)
+
+// CopyInWithInsert builds a copy in handler based on insert, reading the
+// records of the data one at a time and inserting them in a single
+// transaction.
+func CopyInWithInsert(placeholder func(int) string) func(ctx context.Context, db *sql.DB, r io.Reader, table string, opts CopyInOptions) (int64, error) {
+	if placeholder == nil {
+		placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+	return func(ctx context.Context, db *sql.DB, r io.Reader, table string, opts CopyInOptions) (int64, error) {
+		// determine target column types, to convert the values to
+		colQuery := "SELECT * FROM " + table + " WHERE 1=0"
+		if leftParen := strings.IndexRune(table, '('); leftParen != -1 {
+			colQuery = "SELECT " + table[leftParen+1:len(table)-1] + " FROM " + table[:leftParen] + " WHERE 1=0"
+		}
+		colRows, err := db.QueryContext(ctx, colQuery)
+		if err != nil {
+			return 0, fmt.Errorf("failed to execute query to determine target table columns: %w", err)
+		}
+		columnTypes, err := colRows.ColumnTypes()
+		colRows.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch target table column types: %w", err)
+		}
+		clen := len(columnTypes)
+		placeholders := make([]string, clen)
+		for i := 0; i < clen; i++ {
+			placeholders[i] = placeholder(i + 1)
+		}
+		query := "INSERT INTO " + table + " VALUES (" + strings.Join(placeholders, ", ") + ")"
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prepare insert query: %w", err)
+		}
+		defer stmt.Close()
+		next := NewRecordReader(r, opts)
+		var n int64
+		for {
+			values, err := next()
+			switch {
+			case err == io.EOF:
+				if err := tx.Commit(); err != nil {
+					return n, fmt.Errorf("failed to commit transaction: %w", err)
+				}
+				return n, nil
+			case err != nil:
+				return n, fmt.Errorf("failed to read record %d: %w", n+1, err)
+			case len(values) != clen:
+				return n, fmt.Errorf("record %d has %d values, expected %d", n+1, len(values), clen)
+			}
+			for i, v := range values {
+				if values[i], err = convertValue(v, columnTypes[i].ScanType()); err != nil {
+					return n, fmt.Errorf("failed to convert value of column %s of record %d: %w", columnTypes[i].Name(), n+1, err)
+				}
+			}
+			if _, err := stmt.ExecContext(ctx, values...); err != nil {
+				return n, fmt.Errorf("failed to exec insert: %w", err)
+			}
+			n++
+		}
+	}
+}
+
+// convertValue converts a string value to the boolean and numeric kinds of
+// the scan type of a column, for drivers that do not convert strings.
+func convertValue(v interface{}, typ reflect.Type) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || typ == nil {
+		return v, nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(typ).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(typ).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(f).Convert(typ).Interface(), nil
+	}
+	return v, nil
+}
@@ -33,6 +33,8 @@ func init() {
 			}
 			return "CSVQ " + ver, nil
 		},
-		Copy: drivers.CopyWithInsert(func(int) string { return "?" }),
+		Copy:        drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:      drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder: func(int) string { return "?" },
 	})
 }
@@ -0,0 +1,34 @@
+// Package databricks defines and registers usql's Databricks SQL driver.
+//
+// Group: all
+// See: https://github.com/databricks/databricks-sql-go
+package databricks
+
+import (
+	"errors"
+	"io"
+
+	_ "github.com/databricks/databricks-sql-go" // DRIVER
+	dbsqlerr "github.com/databricks/databricks-sql-go/errors"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+func init() {
+	drivers.Register("databricks", drivers.Driver{
+		AllowMultilineComments: true,
+		Err: func(err error) (string, string) {
+			var e dbsqlerr.DBExecutionError
+			if errors.As(err, &e) {
+				return e.SqlState(), e.Error()
+			}
+			return "", err.Error()
+		},
+		NewMetadataReader: NewMetadataReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			return metadata.NewDefaultWriter(NewMetadataReader(db, opts...))(db, w)
+		},
+		Copy:        drivers.CopyWithInsert(func(int) string { return "?" }),
+		Placeholder: func(int) string { return "?" },
+	})
+}
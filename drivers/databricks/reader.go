@@ -0,0 +1,245 @@
+package databricks
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// MetadataReader reads the metadata of Databricks catalogs, using SHOW and
+// DESCRIBE commands, which are supported by both Unity Catalog and the legacy
+// Hive metastore.
+type MetadataReader struct {
+	metadata.LoggingReader
+}
+
+var (
+	_ metadata.CatalogReader = &MetadataReader{}
+	_ metadata.SchemaReader  = &MetadataReader{}
+	_ metadata.TableReader   = &MetadataReader{}
+	_ metadata.ColumnReader  = &MetadataReader{}
+)
+
+// NewMetadataReader creates the metadata reader for Databricks.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	}
+}
+
+// Catalogs lists the catalogs.
+func (r MetadataReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	names, err := r.list(`SHOW CATALOGS`, 0, f.Name)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Catalog, len(names))
+	for i, name := range names {
+		results[i].Catalog = name[0]
+	}
+	return metadata.NewCatalogSet(results), nil
+}
+
+// Schemas lists the schemas of the current catalog, or of the catalog in the
+// filter.
+func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	schemas, err := r.schemas(f.Catalog, f.Name, f.WithSystem)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Schema, len(schemas))
+	for i, schema := range schemas {
+		results[i] = metadata.Schema{
+			Catalog: f.Catalog,
+			Schema:  schema,
+		}
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// Tables lists the tables and views of the schemas matching the filter, or of
+// the current schema.
+func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	schemas := []string{""}
+	if f.Schema != "" {
+		var err error
+		if schemas, err = r.schemas(f.Catalog, f.Schema, f.WithSystem); err != nil {
+			return nil, err
+		}
+	}
+	types := make(map[string]bool, len(f.Types))
+	for _, typ := range f.Types {
+		types[typ] = true
+	}
+	var results []metadata.Table
+	for _, schema := range schemas {
+		in := ""
+		if schema != "" {
+			in = ` IN ` + quote(f.Catalog, schema)
+		}
+		views, err := r.list(`SHOW VIEWS`+in, 1, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		isView := make(map[string]bool, len(views))
+		for _, v := range views {
+			isView[v[1]] = true
+		}
+		// database, tableName, isTemporary
+		tables, err := r.list(`SHOW TABLES`+in, 1, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tables {
+			typ := "TABLE"
+			switch {
+			case isView[t[1]]:
+				typ = "VIEW"
+			case len(t) > 2 && strings.EqualFold(t[2], "true"):
+				typ = "LOCAL TEMPORARY"
+			}
+			if len(types) != 0 && !types[typ] {
+				continue
+			}
+			results = append(results, metadata.Table{
+				Catalog: f.Catalog,
+				Schema:  t[0],
+				Name:    t[1],
+				Type:    typ,
+			})
+		}
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns lists the columns of the table in the filter, as reported by
+// DESCRIBE TABLE.
+func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	rows, closeRows, err := r.Query(`DESCRIBE TABLE ` + quote(f.Catalog, f.Schema, f.Parent))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewColumnSet(nil), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Column
+	for rows.Next() {
+		var name, typ, comment sql.NullString
+		if err := rows.Scan(&name, &typ, &comment); err != nil {
+			return nil, err
+		}
+		// partitioning and other details follow the columns, after an empty
+		// line or a # header
+		if name.String == "" || strings.HasPrefix(name.String, "#") {
+			break
+		}
+		results = append(results, metadata.Column{
+			Catalog:         f.Catalog,
+			Schema:          f.Schema,
+			Table:           f.Parent,
+			Name:            name.String,
+			OrdinalPosition: len(results) + 1,
+			DataType:        typ.String,
+		})
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// schemas returns the schemas of catalog matching the LIKE pattern, excluding
+// information_schema unless withSystem is set.
+func (r MetadataReader) schemas(catalog, pattern string, withSystem bool) ([]string, error) {
+	qstr := `SHOW SCHEMAS`
+	if catalog != "" {
+		qstr += ` IN ` + quote(catalog)
+	}
+	rows, err := r.list(qstr, 0, pattern)
+	if err != nil {
+		return nil, err
+	}
+	var schemas []string
+	for _, row := range rows {
+		if !withSystem && strings.EqualFold(row[0], "information_schema") {
+			continue
+		}
+		schemas = append(schemas, row[0])
+	}
+	return schemas, nil
+}
+
+// list returns the string columns of the results of qstr, where column i
+// matches the LIKE pattern when not empty.
+func (r MetadataReader) list(qstr string, i int, pattern string) ([][]string, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		re = likeRE(pattern)
+	}
+	rows, closeRows, err := r.Query(qstr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var res [][]string
+	for rows.Next() {
+		vals, ptrs := make([]sql.NullString, len(cols)), make([]interface{}, len(cols))
+		for j := range vals {
+			ptrs[j] = &vals[j]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(vals))
+		for j, v := range vals {
+			row[j] = v.String
+		}
+		if re == nil || re.MatchString(row[i]) {
+			res = append(res, row)
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return res, nil
+}
+
+// quote quotes and joins the non-empty names as a qualified identifier.
+func quote(names ...string) string {
+	var s []string
+	for _, name := range names {
+		if name != "" {
+			s = append(s, "`"+strings.ReplaceAll(name, "`", "``")+"`")
+		}
+	}
+	return strings.Join(s, ".")
+}
+
+// likeRE converts a LIKE pattern to a regexp.
+func likeRE(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,54 @@
+package drivers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/text"
+)
+
+// ColumnDesc describes a result column of a query, as returned by a driver's
+// Describe.
+type ColumnDesc struct {
+	// Name is the column name.
+	Name string
+	// Type is the database type name of the column.
+	Type string
+}
+
+// Describe returns the names and types of the result columns of a query for
+// a driver, without executing it (\gdesc). Uses the driver's Describe when
+// defined (which can return text.ErrNotSupported, such as when inside a
+// transaction), otherwise the query is wrapped as a subquery returning no rows
+// and the column types of the empty result are used. Returns no columns for
+// queries that do not return rows.
+func Describe(ctx context.Context, u *dburl.URL, db DB, query string, qtyp bool, args ...interface{}) ([]ColumnDesc, error) {
+	if d, ok := drivers[u.Driver]; ok && d.Describe != nil {
+		cols, err := d.Describe(ctx, db, query)
+		if err != text.ErrNotSupported {
+			return cols, WrapErr(u.Driver, err)
+		}
+	}
+	if !qtyp {
+		return nil, nil
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	rows, err := db.QueryContext(ctx, `SELECT * FROM (`+query+`) gdesc WHERE 1=0`, args...)
+	if err != nil {
+		return nil, WrapErr(u.Driver, err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, WrapErr(u.Driver, err)
+	}
+	cols := make([]ColumnDesc, len(types))
+	for i, typ := range types {
+		cols[i] = ColumnDesc{
+			Name: typ.Name(),
+			Type: strings.ToLower(typ.DatabaseTypeName()),
+		}
+	}
+	return cols, nil
+}
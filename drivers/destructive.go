@@ -0,0 +1,274 @@
+package drivers
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// Destructive is a destructive statement: a DROP or TRUNCATE statement, or a
+// DELETE or UPDATE statement without a WHERE clause.
+type Destructive struct {
+	// Type is the statement type, such as DROP TABLE or DELETE.
+	Type string
+	// Objects are the names of the affected objects, as in the statement.
+	Objects []string
+}
+
+// Keyword returns the first keyword of the statement type, which is typed to
+// confirm the statement.
+func (d Destructive) Keyword() string {
+	keyword, _, _ := strings.Cut(d.Type, " ")
+	return keyword
+}
+
+// dropIgnore are the keywords of a DROP statement preceding the names of the
+// dropped objects, that are not part of the statement type.
+var dropIgnore = map[string]bool{
+	"IF":           true,
+	"EXISTS":       true,
+	"CONCURRENTLY": true,
+	"PROCEDURAL":   true,
+	"TEMPORARY":    true,
+}
+
+// ParseDestructive parses the statement, returning false when it is not
+// destructive.
+func ParseDestructive(sqlstr string) (Destructive, bool) {
+	tokens := sqlTokens(sqlstr)
+	// skip common table expressions
+	if len(tokens) != 0 && strings.EqualFold(tokens[0], "WITH") {
+		for i, t := range tokens {
+			if s := strings.ToUpper(t); s == "DELETE" || s == "UPDATE" {
+				tokens = tokens[i:]
+				break
+			}
+		}
+	}
+	if len(tokens) < 2 {
+		return Destructive{}, false
+	}
+	var d Destructive
+	i := 1
+	switch typ := strings.ToUpper(tokens[0]); typ {
+	case "DROP":
+		v := []string{typ}
+		for ; i < len(tokens) && isKeyword(tokens[i]); i++ {
+			if s := strings.ToUpper(tokens[i]); !dropIgnore[s] {
+				v = append(v, s)
+			}
+		}
+		d.Type = strings.Join(v, " ")
+	case "TRUNCATE", "DELETE", "UPDATE":
+		for _, t := range tokens[i:] {
+			if strings.EqualFold(t, "WHERE") && typ != "TRUNCATE" {
+				return Destructive{}, false
+			}
+		}
+		for ; i < len(tokens) && (isKeyword(tokens[i]) || tokens[i] == "("); i++ {
+		}
+		d.Type = typ
+	default:
+		return Destructive{}, false
+	}
+	// names of the objects, separated by commas
+	for ; i < len(tokens) && !isKeyword(tokens[i]); i += 2 {
+		d.Objects = append(d.Objects, tokens[i])
+		if i+1 >= len(tokens) || tokens[i+1] != "," {
+			break
+		}
+	}
+	return d, true
+}
+
+// destructiveKeywords are the keywords that precede the names of the objects
+// of DELETE, UPDATE, and TRUNCATE statements.
+var destructiveKeywords = map[string]bool{
+	"FROM":  true,
+	"ONLY":  true,
+	"TABLE": true,
+	"TOP":   true,
+}
+
+// isKeyword returns true when the token is a keyword preceding the names of
+// the objects of a destructive statement, such as TABLE, FROM, or IF EXISTS.
+func isKeyword(t string) bool {
+	s := strings.ToUpper(t)
+	if destructiveKeywords[s] || dropIgnore[s] {
+		return true
+	}
+	// DROP object types
+	switch s {
+	case "ACCESS", "AGGREGATE", "CAST", "CATALOG", "COLLATION", "CONSTRAINT",
+		"CONVERSION", "DATABASE", "DOMAIN", "EVENT", "EXTENSION", "EXTERNAL",
+		"FOREIGN", "FUNCTION", "GLOBAL", "GROUP", "INDEX", "LANGUAGE",
+		"MATERIALIZED", "METHOD", "OPERATOR", "PACKAGE", "POLICY", "PROCEDURE",
+		"PUBLICATION", "ROLE", "ROUTINE", "RULE", "SCHEMA", "SEQUENCE",
+		"SERVER", "STATISTICS", "SUBSCRIPTION", "SYNONYM", "TABLESPACE",
+		"TEMP", "TRIGGER", "TYPE", "UNIQUE", "USER", "VIEW", "WRAPPER":
+		return true
+	}
+	return false
+}
+
+// sqlTokens splits the statement into its top-level tokens: names (including
+// quoted and qualified names), string literals (as '), parenthesized
+// expressions (as "("), and punctuation. Comments are skipped.
+func sqlTokens(sqlstr string) []string {
+	r := []rune(sqlstr)
+	var tokens []string
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			i += 2
+			for i < len(r) && !(r[i-1] == '*' && r[i] == '/') {
+				i++
+			}
+			i++
+		case c == '\'':
+			i = skipQuoted(r, i, '\'')
+			tokens = append(tokens, "'")
+		case c == '(':
+			for depth := 0; i < len(r); i++ {
+				switch r[i] {
+				case '\'', '"', '`':
+					i = skipQuoted(r, i, r[i]) - 1
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			i++
+			tokens = append(tokens, "(")
+		case isNameRune(c) || c == '"' || c == '`' || c == '[':
+			start := i
+			for i < len(r) && (isNameRune(r[i]) || r[i] == '.' || r[i] == '"' || r[i] == '`' || r[i] == '[') {
+				switch r[i] {
+				case '"', '`':
+					i = skipQuoted(r, i, r[i])
+				case '[':
+					i = skipQuoted(r, i, ']')
+				default:
+					i++
+				}
+			}
+			tokens = append(tokens, string(r[start:i]))
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipQuoted returns the position after the quoted text starting at i, ending
+// with the quote rune.
+func skipQuoted(r []rune, i int, quote rune) int {
+	for i++; i < len(r); i++ {
+		if r[i] == quote {
+			// doubled quotes
+			if i+1 < len(r) && r[i+1] == quote && quote != ']' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
+
+// isNameRune returns true when c is part of an unquoted name.
+func isNameRune(c rune) bool {
+	return c == '_' || c == '$' || c == '#' || c == '@' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// AffectedObject is an object affected by a destructive statement.
+type AffectedObject struct {
+	// Name is the name of the object, as in the statement.
+	Name string
+	// Type is the type of the object, or empty when unknown.
+	Type string
+	// Rows is the estimated number of rows of the object, or -1 when unknown.
+	Rows int64
+}
+
+// DestructiveObjects returns the metadata of the objects affected by the
+// destructive statement, read on db with the driver's table reader when
+// available. The rows of the tables found by the table reader are estimated by
+// the planner when the table reader does not provide them, as counting them
+// would scan the tables. Objects that are not found are returned with unknown
+// metadata.
+func DestructiveObjects(ctx context.Context, u *dburl.URL, db DB, d Destructive) []AffectedObject {
+	objects := make([]AffectedObject, len(d.Objects))
+	var tr metadata.TableReader
+	if r, err := NewMetadataReader(ctx, u, db, nil); err == nil {
+		tr, _ = r.(metadata.TableReader)
+	}
+	for i, name := range d.Objects {
+		obj := AffectedObject{Name: name, Rows: -1}
+		t, ok := findTable(tr, name)
+		switch {
+		case ok:
+			obj.Type = t.Type
+			if t.Rows > 0 {
+				obj.Rows = t.Rows
+			}
+		case strings.HasPrefix(d.Type, "DROP "):
+			obj.Type = strings.ToLower(strings.TrimPrefix(d.Type, "DROP "))
+		}
+		// only estimate the rows of existing tables, as a failed query aborts
+		// the transaction on some databases
+		if ok && obj.Rows == -1 && strings.Contains(strings.ToLower(obj.Type), "table") {
+			if n, ok := EstimateRows(ctx, u, db, "SELECT * FROM "+name); ok {
+				obj.Rows = n
+			}
+		}
+		objects[i] = obj
+	}
+	return objects
+}
+
+// findTable finds the table with the (optionally schema qualified) name, using
+// the table reader.
+func findTable(tr metadata.TableReader, name string) (metadata.Table, bool) {
+	if tr == nil {
+		return metadata.Table{}, false
+	}
+	schema, table := "", unquoteName(name)
+	if i := strings.LastIndex(table, "."); i != -1 {
+		schema, table = table[:i], table[i+1:]
+		if j := strings.LastIndex(schema, "."); j != -1 {
+			schema = schema[j+1:]
+		}
+	}
+	res, err := tr.Tables(metadata.Filter{Schema: schema, Name: table, OnlyVisible: schema == ""})
+	if err != nil {
+		return metadata.Table{}, false
+	}
+	defer res.Close()
+	for res.Next() {
+		if t := res.Get(); strings.EqualFold(t.Name, table) {
+			return *t, true
+		}
+	}
+	return metadata.Table{}, false
+}
+
+// unquoteName removes the identifier quotes from the name.
+func unquoteName(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+}
@@ -0,0 +1,61 @@
+package drivers_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ildus/usql/drivers"
+)
+
+func TestParseDestructive(t *testing.T) {
+	tests := []struct {
+		sqlstr  string
+		ok      bool
+		typ     string
+		objects []string
+	}{
+		{`SELECT * FROM t`, false, "", nil},
+		{`INSERT INTO t VALUES (1)`, false, "", nil},
+		{`DELETE FROM t`, true, "DELETE", []string{"t"}},
+		{`delete from public.t;`, true, "DELETE", []string{"public.t"}},
+		{`DELETE FROM t WHERE a = 1`, false, "", nil},
+		{`DELETE FROM ONLY "my table"`, true, "DELETE", []string{`"my table"`}},
+		{`DELETE TOP (10) FROM t`, true, "DELETE", []string{"t"}},
+		{`UPDATE t SET a = 1`, true, "UPDATE", []string{"t"}},
+		{`UPDATE t SET a = 1 WHERE b = 2`, false, "", nil},
+		{`UPDATE t SET a = 1 where b = 2`, false, "", nil},
+		// WHERE in subqueries, strings, names, and comments
+		{`UPDATE t SET a = (SELECT b FROM u WHERE c = 1)`, true, "UPDATE", []string{"t"}},
+		{`DELETE FROM t USING (SELECT a FROM u WHERE b) AS v`, true, "DELETE", []string{"t"}},
+		{`UPDATE t SET a = 'where'`, true, "UPDATE", []string{"t"}},
+		{`UPDATE t SET "where" = 1`, true, "UPDATE", []string{"t"}},
+		{`DELETE FROM t -- WHERE a = 1`, true, "DELETE", []string{"t"}},
+		{`DELETE FROM t /* WHERE a = 1 */`, true, "DELETE", []string{"t"}},
+		{`UPDATE t SET a = 'it''s' WHERE b = 1`, false, "", nil},
+		// common table expressions
+		{`WITH x AS (SELECT 1 WHERE true) DELETE FROM t`, true, "DELETE", []string{"t"}},
+		{`WITH x AS (SELECT 1) DELETE FROM t WHERE a IN (SELECT * FROM x)`, false, "", nil},
+		{`WITH x AS (SELECT 1) SELECT * FROM x`, false, "", nil},
+		{`TRUNCATE t`, true, "TRUNCATE", []string{"t"}},
+		{`TRUNCATE TABLE a, b RESTART IDENTITY`, true, "TRUNCATE", []string{"a", "b"}},
+		{`DROP TABLE t`, true, "DROP TABLE", []string{"t"}},
+		{`DROP TABLE IF EXISTS a, s.b, "c d" CASCADE`, true, "DROP TABLE", []string{"a", "s.b", `"c d"`}},
+		{`drop materialized view if exists v`, true, "DROP MATERIALIZED VIEW", []string{"v"}},
+		{`DROP INDEX CONCURRENTLY i`, true, "DROP INDEX", []string{"i"}},
+		{`DROP TEMPORARY TABLE [t]`, true, "DROP TABLE", []string{"[t]"}},
+		{`DROP`, false, "", nil},
+	}
+	for i, test := range tests {
+		d, ok := drivers.ParseDestructive(test.sqlstr)
+		if ok != test.ok {
+			t.Errorf("test %d expected ok %t, got: %t", i, test.ok, ok)
+			continue
+		}
+		if d.Type != test.typ {
+			t.Errorf("test %d expected type %q, got: %q", i, test.typ, d.Type)
+		}
+		if !reflect.DeepEqual(d.Objects, test.objects) {
+			t.Errorf("test %d expected objects %q, got: %q", i, test.objects, d.Objects)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package drivers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ildus/usql/text"
+)
+
+// DiffKind is the kind of a row level difference.
+type DiffKind rune
+
+// Diff kinds.
+const (
+	// DiffAdded is a row only present in the second result set.
+	DiffAdded DiffKind = '+'
+	// DiffRemoved is a row only present in the first result set.
+	DiffRemoved DiffKind = '-'
+	// DiffChanged is a row present in both result sets with different values.
+	DiffChanged DiffKind = '~'
+)
+
+// DiffRow is a row level difference between two result sets.
+type DiffRow struct {
+	// Kind is the kind of difference.
+	Kind DiffKind
+	// Columns are the column names of Values.
+	Columns []string
+	// Values are the values of the row. For removed rows, only the values of
+	// the key columns are available.
+	Values []string
+	// Changed are the names of the changed columns of a changed row.
+	Changed []string
+}
+
+// DiffStats are the counts of the differences between two result sets.
+type DiffStats struct {
+	Added, Removed, Changed, Same int64
+}
+
+// diffEntry is the retained state of a row of the first result set.
+type diffEntry struct {
+	pos  int
+	key  []string
+	sums [][sha256.Size]byte
+	seen bool
+}
+
+// Diff compares the results of srcQuery on src with the results of dstQuery
+// on dst, calling f for each row that was added, removed or changed. Rows are
+// matched using the values of the key columns, which must be unique.
+//
+// The first result set is read in full before the second query is executed,
+// retaining the key values and a SHA-256 hash of each column of its rows,
+// while the second result set is streamed. Memory use is therefore proportional to the
+// size of the keys of the first result set.
+//
+// When no keys are specified, all columns are the key: the values of every row
+// of the first result set are retained, a row with changed values is reported
+// as a removed row and an added row, and duplicate rows are an error.
+func Diff(ctx context.Context, src, dst DB, srcQuery, dstQuery string, keys []string, f func(DiffRow) error) (DiffStats, error) {
+	var stats DiffStats
+	// read first result set
+	srcRows, err := src.QueryContext(ctx, srcQuery)
+	if err != nil {
+		return stats, err
+	}
+	defer srcRows.Close()
+	cols, err := srcRows.Columns()
+	if err != nil {
+		return stats, err
+	}
+	keyIdx, err := diffKeys(cols, keys)
+	if err != nil {
+		return stats, err
+	}
+	keyCols := make([]string, len(keyIdx))
+	for i, j := range keyIdx {
+		keyCols[i] = cols[j]
+	}
+	entries := make(map[string]*diffEntry)
+	vals := diffScanner(len(cols))
+	for n := 0; srcRows.Next(); n++ {
+		row, err := vals(srcRows)
+		if err != nil {
+			return stats, err
+		}
+		id, key := diffKey(row, keyIdx)
+		if _, ok := entries[id]; ok {
+			return stats, fmt.Errorf(text.DiffDuplicateKey, strings.Join(key, ", "))
+		}
+		entries[id] = &diffEntry{
+			pos:  n,
+			key:  key,
+			sums: diffSums(row),
+		}
+	}
+	if err := srcRows.Err(); err != nil {
+		return stats, err
+	}
+	srcRows.Close()
+	// compare second result set
+	dstRows, err := dst.QueryContext(ctx, dstQuery)
+	if err != nil {
+		return stats, err
+	}
+	defer dstRows.Close()
+	dstCols, err := dstRows.Columns()
+	if err != nil {
+		return stats, err
+	}
+	idx, err := diffColumns(cols, dstCols)
+	if err != nil {
+		return stats, err
+	}
+	dstVals := diffScanner(len(dstCols))
+	for dstRows.Next() {
+		r, err := dstVals(dstRows)
+		if err != nil {
+			return stats, err
+		}
+		// reorder to the column order of the first result set
+		row := make([]*string, len(cols))
+		for i, j := range idx {
+			row[i] = r[j]
+		}
+		id, _ := diffKey(row, keyIdx)
+		e, ok := entries[id]
+		switch {
+		case !ok:
+			stats.Added++
+			err = f(DiffRow{Kind: DiffAdded, Columns: cols, Values: diffStrings(row)})
+		case e.seen:
+			return stats, fmt.Errorf(text.DiffDuplicateKey, strings.Join(e.key, ", "))
+		default:
+			e.seen = true
+			var changed []string
+			for i, sum := range diffSums(row) {
+				if sum != e.sums[i] {
+					changed = append(changed, cols[i])
+				}
+			}
+			if len(changed) == 0 {
+				stats.Same++
+				continue
+			}
+			stats.Changed++
+			err = f(DiffRow{Kind: DiffChanged, Columns: cols, Values: diffStrings(row), Changed: changed})
+		}
+		if err != nil {
+			return stats, err
+		}
+	}
+	if err := dstRows.Err(); err != nil {
+		return stats, err
+	}
+	// report remaining rows of the first result set, in their original order
+	var removed []*diffEntry
+	for _, e := range entries {
+		if !e.seen {
+			removed = append(removed, e)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].pos < removed[j].pos
+	})
+	for _, e := range removed {
+		stats.Removed++
+		if err := f(DiffRow{Kind: DiffRemoved, Columns: keyCols, Values: e.key}); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// diffKeys returns the positions of the key columns in cols.
+func diffKeys(cols, keys []string) ([]int, error) {
+	if len(keys) == 0 {
+		idx := make([]int, len(cols))
+		for i := range cols {
+			idx[i] = i
+		}
+		return idx, nil
+	}
+	idx := make([]int, len(keys))
+	for i, k := range keys {
+		j := diffIndex(cols, k)
+		if j == -1 {
+			return nil, fmt.Errorf(text.DiffUnknownColumn, k)
+		}
+		idx[i] = j
+	}
+	return idx, nil
+}
+
+// diffColumns returns the positions of cols in dstCols, requiring both result
+// sets to have the same columns.
+func diffColumns(cols, dstCols []string) ([]int, error) {
+	if len(cols) != len(dstCols) {
+		return nil, text.ErrDiffColumnsMismatch
+	}
+	idx := make([]int, len(cols))
+	for i, c := range cols {
+		j := diffIndex(dstCols, c)
+		if j == -1 {
+			return nil, text.ErrDiffColumnsMismatch
+		}
+		idx[i] = j
+	}
+	return idx, nil
+}
+
+// diffIndex returns the position of name in cols, ignoring case.
+func diffIndex(cols []string, name string) int {
+	for i, c := range cols {
+		if strings.EqualFold(c, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// diffScanner returns a func that scans a row as strings, where a nil value
+// is a NULL.
+func diffScanner(n int) func(*sql.Rows) ([]*string, error) {
+	vals := make([]interface{}, n)
+	ptrs := make([]interface{}, n)
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	return func(rows *sql.Rows) ([]*string, error) {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make([]*string, n)
+		for i, v := range vals {
+			var s string
+			switch x := v.(type) {
+			case nil:
+				continue
+			case []byte:
+				s = string(x)
+			default:
+				s = fmt.Sprintf("%v", x)
+			}
+			row[i] = &s
+		}
+		return row, nil
+	}
+}
+
+// diffKey returns the map key and the values of the key columns of row.
+func diffKey(row []*string, keyIdx []int) (string, []string) {
+	var sb strings.Builder
+	key := make([]string, len(keyIdx))
+	for i, j := range keyIdx {
+		if row[j] == nil {
+			sb.WriteByte(0)
+			key[i] = "NULL"
+			continue
+		}
+		sb.WriteByte(1)
+		sb.WriteString(fmt.Sprintf("%d:", len(*row[j])))
+		sb.WriteString(*row[j])
+		key[i] = *row[j]
+	}
+	return sb.String(), key
+}
+
+// diffSums returns the SHA-256 hash of each value of row, where a NULL is the
+// zero hash. A cryptographic hash is used, so that a changed value can not go
+// unnoticed due to a hash collision.
+func diffSums(row []*string) [][sha256.Size]byte {
+	sums := make([][sha256.Size]byte, len(row))
+	for i, v := range row {
+		if v == nil {
+			continue
+		}
+		sums[i] = sha256.Sum256([]byte(*v))
+	}
+	return sums
+}
+
+// diffStrings converts row to strings, displaying NULLs as NULL.
+func diffStrings(row []*string) []string {
+	s := make([]string, len(row))
+	for i, v := range row {
+		if v == nil {
+			s[i] = "NULL"
+			continue
+		}
+		s[i] = *v
+	}
+	return s
+}
@@ -0,0 +1,184 @@
+package drivers_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+func init() {
+	sql.Register("difftest", diffDriver{})
+}
+
+// diffResults are the fake result sets returned by the difftest driver, keyed
+// by query. The first row of each result set contains the column names.
+var diffResults = map[string][][]driver.Value{
+	"src": {
+		{"id", "name"},
+		{int64(1), "one"},
+		{int64(2), "two"},
+		{int64(3), "three"},
+		{int64(4), nil},
+	},
+	"dst": {
+		{"name", "id"},
+		{"one", int64(1)},
+		{"TWO", int64(2)},
+		{nil, int64(4)},
+		{"five", int64(5)},
+	},
+	"dup": {
+		{"id", "name"},
+		{int64(1), "one"},
+		{int64(1), "uno"},
+	},
+	"other": {
+		{"id", "value"},
+		{int64(1), "one"},
+	},
+}
+
+func TestDiff(t *testing.T) {
+	db, err := sql.Open("difftest", "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer db.Close()
+	tests := []struct {
+		src, dst string
+		keys     []string
+		exp      []string
+		stats    drivers.DiffStats
+		err      error
+	}{
+		{"src", "src", []string{"id"}, nil, drivers.DiffStats{Same: 4}, nil},
+		{
+			"src", "dst", []string{"id"},
+			[]string{"~ id=2, name=TWO (name)", "+ id=5, name=five", "- id=3"},
+			drivers.DiffStats{Added: 1, Removed: 1, Changed: 1, Same: 2}, nil,
+		},
+		{
+			"dst", "src", []string{"ID"},
+			[]string{"~ name=two, id=2 (name)", "+ name=three, id=3", "- id=5"},
+			drivers.DiffStats{Added: 1, Removed: 1, Changed: 1, Same: 2}, nil,
+		},
+		// without keys, a changed row is a removed and an added row
+		{
+			"src", "dst", nil,
+			[]string{"+ id=2, name=TWO", "+ id=5, name=five", "- id=2, name=two", "- id=3, name=three"},
+			drivers.DiffStats{Added: 2, Removed: 2, Same: 2}, nil,
+		},
+		{"src", "dst", []string{"missing"}, nil, drivers.DiffStats{}, fmt.Errorf(text.DiffUnknownColumn, "missing")},
+		{"dup", "src", []string{"id"}, nil, drivers.DiffStats{}, fmt.Errorf(text.DiffDuplicateKey, "1")},
+		{"src", "dup", []string{"id"}, nil, drivers.DiffStats{}, fmt.Errorf(text.DiffDuplicateKey, "1")},
+		{"src", "other", nil, nil, drivers.DiffStats{}, text.ErrDiffColumnsMismatch},
+	}
+	for i, test := range tests {
+		var rows []string
+		stats, err := drivers.Diff(context.Background(), db, db, test.src, test.dst, test.keys, func(row drivers.DiffRow) error {
+			vals := make([]string, len(row.Values))
+			for j, v := range row.Values {
+				vals[j] = row.Columns[j] + "=" + v
+			}
+			s := string(row.Kind) + " " + strings.Join(vals, ", ")
+			if len(row.Changed) != 0 {
+				s += " (" + strings.Join(row.Changed, ", ") + ")"
+			}
+			rows = append(rows, s)
+			return nil
+		})
+		switch {
+		case test.err != nil && (err == nil || err.Error() != test.err.Error()):
+			t.Errorf("test %d expected error %v, got: %v", i, test.err, err)
+		case test.err == nil && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		if test.err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(rows, test.exp) {
+			t.Errorf("test %d expected rows %q, got: %q", i, test.exp, rows)
+		}
+		if stats != test.stats {
+			t.Errorf("test %d expected stats %+v, got: %+v", i, test.stats, stats)
+		}
+	}
+}
+
+// diffDriver is a database/sql driver returning the fake diffResults.
+type diffDriver struct{}
+
+func (diffDriver) Open(string) (driver.Conn, error) {
+	return diffConn{}, nil
+}
+
+type diffConn struct{}
+
+func (diffConn) Prepare(query string) (driver.Stmt, error) {
+	res, ok := diffResults[query]
+	if !ok {
+		return nil, fmt.Errorf("unknown query %q", query)
+	}
+	return diffStmt(res), nil
+}
+
+func (diffConn) Close() error {
+	return nil
+}
+
+func (diffConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type diffStmt [][]driver.Value
+
+func (diffStmt) Close() error {
+	return nil
+}
+
+func (diffStmt) NumInput() int {
+	return 0
+}
+
+func (diffStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s diffStmt) Query([]driver.Value) (driver.Rows, error) {
+	cols := make([]string, len(s[0]))
+	for i, v := range s[0] {
+		cols[i] = v.(string)
+	}
+	return &diffRows{cols: cols, rows: s[1:]}, nil
+}
+
+type diffRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *diffRows) Columns() []string {
+	return r.cols
+}
+
+func (r *diffRows) Close() error {
+	return nil
+}
+
+func (r *diffRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -5,11 +5,18 @@ package drivers
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 	"unicode"
 
@@ -36,6 +43,14 @@ type DB interface {
 	PrepareContext(context.Context, string) (*sql.Stmt, error)
 }
 
+// Conn is the common interface for operations on a single database
+// connection, compatible with database/sql.Conn and database/sql.Tx.
+type Conn interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
 // Driver holds funcs for a driver.
 type Driver struct {
 	// Name is a name to override the driver name with.
@@ -52,6 +67,10 @@ type Driver struct {
 	// AllowHashComments will be passed to query buffers to enable hash (#)
 	// style comments.
 	AllowHashComments bool
+	// Terminator will be passed to query buffers to enable driver specific
+	// statement termination (ie, GO batches, / terminators, DELIMITER, or
+	// BEGIN ... END blocks) if defined.
+	Terminator *stmt.Terminator
 	// RequirePreviousPassword will be used by RequirePreviousPassword.
 	RequirePreviousPassword bool
 	// LexerName is the name of the syntax lexer to use.
@@ -61,8 +80,15 @@ type Driver struct {
 	// UseColumnTypes will cause database's ColumnTypes func to be used for
 	// types.
 	UseColumnTypes bool
+	// ProcedureResults indicates that stored procedure calls (CALL and EXEC
+	// statements) return multiple result sets, and will cause them to be
+	// queried.
+	ProcedureResults bool
 	// ForceParams will be used to force parameters if defined.
 	ForceParams func(*dburl.URL)
+	// GSSAPI will be used by ForceParams to translate the auth=gssapi DSN
+	// parameter to the driver's Kerberos configuration if defined.
+	GSSAPI func(*dburl.URL)
 	// Open will be used by Open if defined.
 	Open func(context.Context, *dburl.URL, func() io.Writer, func() io.Writer) (func(string, string) (*sql.DB, error), error)
 	// Version will be used by Version if defined.
@@ -73,6 +99,10 @@ type Driver struct {
 	ChangePassword func(DB, string, string, string) error
 	// IsPasswordErr will be used by IsPasswordErr if defined.
 	IsPasswordErr func(error) bool
+	// IsSerializationErr will be used by IsSerializationErr if defined.
+	IsSerializationErr func(error) bool
+	// IsConnErr will be used by IsConnErr if defined.
+	IsConnErr func(error) bool
 	// Process will be used by Process if defined.
 	Process func(string, string) (string, string, bool, error)
 	// RowsAffected will be used by RowsAffected if defined.
@@ -104,6 +134,90 @@ type Driver struct {
 	NewCompleter func(db DB, opts ...completer.Option) readline.AutoCompleter
 	// Copy rows into the database table
 	Copy func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error)
+	// CopyIn will be used by CopyIn to copy the data read from a reader into
+	// the database table, if defined.
+	CopyIn func(ctx context.Context, db *sql.DB, r io.Reader, table string, opts CopyInOptions) (int64, error)
+	// ParseCopyIn will be used by ParseCopyIn to detect statements copying data
+	// from standard input, returning the table and options of the copy, if
+	// defined.
+	ParseCopyIn func(string) (string, CopyInOptions, bool)
+	// QueryID will be used by WithCancel to identify the queries executed on a
+	// connection, returning the context to execute the queries with, if
+	// defined.
+	QueryID func(context.Context, Conn) (context.Context, string, error)
+	// ResultID will be used by WithResultID to retrieve the id assigned by the
+	// database server to the query executed with the returned context, if
+	// defined.
+	ResultID func(context.Context) (context.Context, func() string)
+	// QueryResult will be used by QueryResult to retrieve the results, or the
+	// status, of a previously executed query by its server query id, if
+	// defined.
+	QueryResult func(ctx context.Context, db DB, id string) (*sql.Rows, error)
+	// Cancel will be used by WithCancel to cancel the identified query on the
+	// database server when the query is interrupted, if defined.
+	Cancel func(context.Context, DB, string) error
+	// Kill will be used by Kill to terminate the identified session (as
+	// listed by \dactivity) on the database server, if defined.
+	Kill func(context.Context, DB, string) error
+	// Explain will be used by Explain to retrieve the query plan for a query,
+	// executing the query when analyze is true.
+	Explain func(ctx context.Context, db DB, query string, analyze bool) (*ExplainNode, error)
+	// ParsePlan will be used by ParsePlan to build the query plan from the
+	// value returned by an EXPLAIN statement, such as a JSON formatted plan,
+	// if defined.
+	ParsePlan func([]byte) (*ExplainNode, error)
+	// EstimateRows will be used by EstimateRows to retrieve the planner's
+	// estimated number of rows returned by a query, without executing it.
+	EstimateRows func(ctx context.Context, db Conn, query string) (int64, error)
+	// Sample will be used by Sample to build the query returning a sample of
+	// the rows of a table, if defined.
+	Sample func(ctx context.Context, db DB, table string, n int) (string, error)
+	// Limit will be used by Limit to limit the rows returned by a SELECT
+	// statement, if defined.
+	Limit func(sqlstr string, n int) string
+	// CommentOn will be used by CommentOn to build the statement setting the
+	// comment of a table or column, if defined.
+	CommentOn func(typ, name, comment string) (string, error)
+	// InlineRows will be used by InlineRows to build the derived table
+	// inlining the rows of a result stored by \store, if defined.
+	InlineRows func(name string, cols []string, rows [][]string) string
+	// Describe will be used by Describe to retrieve the names and types of
+	// the result columns of a query, without executing it.
+	Describe func(ctx context.Context, db DB, query string) ([]ColumnDesc, error)
+	// QuoteLiteral will be used by QuoteLiteral to quote a string literal
+	// if defined.
+	QuoteLiteral func(string) string
+	// QuoteIdentifier will be used by QuoteIdentifier to quote an identifier
+	// if defined.
+	QuoteIdentifier func(string) string
+	// FoldIdentifier will be used by the completer to fold the case of
+	// unquoted identifiers as stored by the database, quoting the completed
+	// identifiers not in the folded case, if defined.
+	FoldIdentifier func(string) string
+	// Savepoint will be used by Savepoint to build the statements to create,
+	// release, and roll back to a savepoint, if defined.
+	Savepoint func(string) (string, string, string)
+	// Placeholder will be used by Bind to rewrite the $1, $2, ... parameters
+	// of a query to the driver's placeholder for the nth parameter, if
+	// defined.
+	Placeholder func(int) string
+	// ColumnType will be used by ColumnType to map the kind of a column
+	// inferred by \import to the driver's column type, if defined.
+	ColumnType func(ColumnKind) string
+	// QueryTag will be used by TagQuery to set the driver-native query tag of
+	// the statements executed on the connection with the returned context,
+	// if defined.
+	QueryTag func(ctx context.Context, conn Conn, tag string) (context.Context, error)
+	// StatementTimeout will be used by StatementTimeout to set the
+	// server-side timeout of the statements executed on the connection with
+	// the returned context, with 0 resetting the timeout to the session
+	// default, if defined.
+	StatementTimeout func(ctx context.Context, conn Conn, d time.Duration) (context.Context, error)
+	// OutParams will be used by OutParams to rewrite a stored procedure call
+	// with output parameters, returning the statement to execute, and the
+	// query retrieving the values of the output parameters after the
+	// statement's results, if defined.
+	OutParams func(string) (string, string)
 }
 
 // drivers are registered drivers.
@@ -151,9 +265,42 @@ func UseColumnTypes(u *dburl.URL) bool {
 	return false
 }
 
+// ProcedureResults returns whether or not stored procedure calls return
+// multiple result sets for a driver.
+func ProcedureResults(u *dburl.URL) bool {
+	if d, ok := drivers[u.Driver]; ok {
+		return d.ProcedureResults
+	}
+	return false
+}
+
+// IsProcedureCall returns true when the statement type is a stored procedure
+// call.
+func IsProcedureCall(typ string) bool {
+	keyword, _, _ := strings.Cut(typ, " ")
+	return keyword == "CALL" || keyword == "EXEC" || keyword == "EXECUTE"
+}
+
+// OutParams rewrites a stored procedure call with output parameters for a
+// driver, returning the statement to execute, and the query retrieving the
+// values of the output parameters (or an empty string when there are none).
+func OutParams(u *dburl.URL, sqlstr string) (string, string) {
+	if d, ok := drivers[u.Driver]; ok && d.OutParams != nil {
+		return d.OutParams(sqlstr)
+	}
+	return sqlstr, ""
+}
+
+// AuthGSSAPI is the value of the auth DSN parameter requesting Kerberos
+// (GSSAPI) authentication.
+const AuthGSSAPI = "gssapi"
+
 // ForceParams forces parameters on the DSN for a driver.
 func ForceParams(u *dburl.URL) {
 	d, ok := drivers[u.Driver]
+	if ok && d.GSSAPI != nil && strings.EqualFold(u.Query().Get("auth"), AuthGSSAPI) {
+		d.GSSAPI(u)
+	}
 	if ok && d.ForceParams != nil {
 		d.ForceParams(u)
 	}
@@ -188,6 +335,7 @@ func stmtOpts(u *dburl.URL) []stmt.Option {
 				stmt.WithAllowMultilineComments(d.AllowMultilineComments),
 				stmt.WithAllowCComments(d.AllowCComments),
 				stmt.WithAllowHashComments(d.AllowHashComments),
+				stmt.WithTerminator(d.Terminator),
 			}
 		}
 	}
@@ -196,6 +344,7 @@ func stmtOpts(u *dburl.URL) []stmt.Option {
 		stmt.WithAllowMultilineComments(true),
 		stmt.WithAllowCComments(true),
 		stmt.WithAllowHashComments(true),
+		stmt.WithTerminator(nil),
 	}
 }
 
@@ -241,6 +390,10 @@ func User(ctx context.Context, u *dburl.URL, db DB) (string, error) {
 
 // Process processes the sql query for a driver.
 func Process(u *dburl.URL, prefix, sqlstr string) (string, string, bool, error) {
+	if ProcedureResults(u) && IsProcedureCall(prefix) {
+		typ, _, _ := strings.Cut(prefix, " ")
+		return typ, sqlstr, true, nil
+	}
 	if d, ok := drivers[u.Driver]; ok && d.Process != nil {
 		a, b, c, err := d.Process(prefix, sqlstr)
 		return a, b, c, WrapErr(u.Driver, err)
@@ -261,6 +414,46 @@ func IsPasswordErr(u *dburl.URL, err error) bool {
 	return false
 }
 
+// IsSerializationErr returns true if an err is a serialization failure (or
+// deadlock) for a driver, meaning the statement or transaction can be retried.
+func IsSerializationErr(u *dburl.URL, err error) bool {
+	drv := u.Driver
+	var e *Error
+	if errors.As(err, &e) {
+		drv, err = e.Driver, e.Err
+	}
+	if d, ok := drivers[drv]; ok && d.IsSerializationErr != nil {
+		return d.IsSerializationErr(err)
+	}
+	return false
+}
+
+// IsConnErr returns true if an err indicates the connection to the database
+// was lost (for example, when a idle session was killed by the server), and
+// the database needs to be reconnected to.
+func IsConnErr(u *dburl.URL, err error) bool {
+	drv := u.Driver
+	var e *Error
+	if errors.As(err, &e) {
+		drv, err = e.Driver, e.Err
+	}
+	if d, ok := drivers[drv]; ok && d.IsConnErr != nil && d.IsConnErr(err) {
+		return true
+	}
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, net.ErrClosed),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE),
+		errors.As(err, &opErr):
+		return true
+	}
+	return false
+}
+
 // RequirePreviousPassword returns true if a driver requires a previous
 // password when changing a user's password.
 func RequirePreviousPassword(u *dburl.URL) bool {
@@ -446,6 +639,20 @@ func ForceQueryParameters(params []string) func(*dburl.URL) {
 	}
 }
 
+// ReplaceQueryParameters is a utility func that wraps removing the params
+// named in remove, and then forcing params of name, value pairs.
+func ReplaceQueryParameters(remove []string, params []string) func(*dburl.URL) {
+	force := ForceQueryParameters(params)
+	return func(u *dburl.URL) {
+		v := u.Query()
+		for _, name := range remove {
+			v.Del(name)
+		}
+		u.RawQuery = v.Encode()
+		force(u)
+	}
+}
+
 // NewMetadataReader wraps creating a new database introspector for a driver.
 func NewMetadataReader(ctx context.Context, u *dburl.URL, db DB, w io.Writer, opts ...metadata.ReaderOption) (metadata.Reader, error) {
 	d, ok := drivers[u.Driver]
@@ -478,6 +685,12 @@ func NewCompleter(ctx context.Context, u *dburl.URL, db DB, readerOpts []metadat
 	if !ok {
 		return nil
 	}
+	// prepend to allow to override default options
+	opts = append([]completer.Option{
+		completer.WithIdentifierQuoting(func(s string) string {
+			return QuoteIdentifier(u, s)
+		}, d.FoldIdentifier),
+	}, opts...)
 	if d.NewCompleter != nil {
 		return d.NewCompleter(db, opts...)
 	}
@@ -514,6 +727,362 @@ func Copy(ctx context.Context, u *dburl.URL, stdout, stderr func() io.Writer, ro
 	return d.Copy(ctx, db, rows, table)
 }
 
+// cancelTimeout is the timeout for canceling a query on the database server.
+const cancelTimeout = 5 * time.Second
+
+// WithCancel prepares ctx for executing queries on conn for a driver, so that
+// the queries are canceled on the database server, using db, when ctx is
+// canceled. Needed for drivers that only close the connection when a query is
+// interrupted, leaving the query running on the server. Returns the context to
+// execute the queries with, and a func to call once the queries are done.
+func WithCancel(ctx context.Context, u *dburl.URL, db DB, conn Conn) (context.Context, func()) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Cancel == nil {
+		return ctx, func() {}
+	}
+	var id string
+	if d.QueryID != nil {
+		qctx, qid, err := d.QueryID(ctx, conn)
+		if err != nil {
+			return ctx, func() {}
+		}
+		ctx, id = qctx, qid
+	}
+	stop := context.AfterFunc(ctx, func() {
+		cctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancel()
+		_ = d.Cancel(cctx, db, id)
+	})
+	return ctx, func() { stop() }
+}
+
+// WithResultID prepares ctx for executing a query for a driver, so that the
+// id assigned to the query by the database server can be retrieved with the
+// returned func once the query was executed. The func returns an empty string
+// when the driver does not support server query ids.
+func WithResultID(ctx context.Context, u *dburl.URL) (context.Context, func() string) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.ResultID == nil {
+		return ctx, func() string { return "" }
+	}
+	return d.ResultID(ctx)
+}
+
+// TagQuery tags the statement executed on the connection with the query tag
+// for a driver, using the driver's native query tag when supported, and
+// otherwise appending a structured comment with the application name and the
+// query tag (in the sqlcommenter format) to the statement.
+func TagQuery(ctx context.Context, u *dburl.URL, conn Conn, tag, sqlstr string) (context.Context, string, error) {
+	if tag == "" {
+		return ctx, sqlstr, nil
+	}
+	if d, ok := drivers[u.Driver]; ok && d.QueryTag != nil {
+		ctx, err := d.QueryTag(ctx, conn, tag)
+		if err != nil {
+			return nil, "", WrapErr(u.Driver, err)
+		}
+		return ctx, sqlstr, nil
+	}
+	// keep the comment out of a trailing line comment
+	sep := " "
+	if i := strings.LastIndex(sqlstr, "\n"); strings.Contains(sqlstr[i+1:], "--") {
+		sep = "\n"
+	}
+	return ctx, sqlstr + sep + "/*application='" + url.PathEscape(text.CommandName) + "',query_tag='" + url.PathEscape(tag) + "'*/", nil
+}
+
+// StatementTimeout sets the server-side timeout of the statements executed on
+// the connection with the returned context for a driver, with 0 resetting the
+// timeout to the session default. Returns ctx unchanged when the driver does not support server-side
+// statement timeouts, in which case only the deadline of ctx applies.
+func StatementTimeout(ctx context.Context, u *dburl.URL, conn Conn, d time.Duration) (context.Context, error) {
+	drv, ok := drivers[u.Driver]
+	if !ok || drv.StatementTimeout == nil {
+		return ctx, nil
+	}
+	ctx, err := drv.StatementTimeout(ctx, conn, d)
+	if err != nil {
+		return nil, WrapErr(u.Driver, err)
+	}
+	return ctx, nil
+}
+
+// QueryResult returns the results, or the status, of a previously executed
+// query by its server query id for a driver.
+func QueryResult(ctx context.Context, u *dburl.URL, db DB, id string) (*sql.Rows, error) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.QueryResult == nil {
+		return nil, fmt.Errorf(text.NotSupportedByDriver, `\qresult`, u.Driver)
+	}
+	rows, err := d.QueryResult(ctx, db, id)
+	if err != nil {
+		return nil, WrapErr(u.Driver, err)
+	}
+	return rows, nil
+}
+
+// Kill terminates the session with id on the database server for a driver.
+func Kill(ctx context.Context, u *dburl.URL, db DB, id string) error {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Kill == nil {
+		return fmt.Errorf(text.NotSupportedByDriver, `\kill`, u.Driver)
+	}
+	if err := d.Kill(ctx, db, id); err != nil {
+		return WrapErr(u.Driver, err)
+	}
+	return nil
+}
+
+// Explain returns the query plan for a query for a driver.
+func Explain(ctx context.Context, u *dburl.URL, db DB, query string, analyze bool) (*ExplainNode, error) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Explain == nil {
+		return nil, fmt.Errorf(text.NotSupportedByDriver, `\explain`, u.Driver)
+	}
+	plan, err := d.Explain(ctx, db, query, analyze)
+	if err != nil {
+		return nil, WrapErr(u.Driver, err)
+	}
+	return plan, nil
+}
+
+// ParsePlan returns the query plan from the value returned by an EXPLAIN
+// statement for a driver. Returns false when not supported by the driver, or
+// when the value is not a query plan.
+func ParsePlan(u *dburl.URL, v interface{}) (*ExplainNode, bool) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.ParsePlan == nil {
+		return nil, false
+	}
+	var buf []byte
+	switch x := v.(type) {
+	case []byte:
+		buf = x
+	case string:
+		buf = []byte(x)
+	case nil:
+		return nil, false
+	default:
+		// decoded json
+		var err error
+		if buf, err = json.Marshal(x); err != nil {
+			return nil, false
+		}
+	}
+	plan, err := d.ParsePlan(buf)
+	if err != nil {
+		return nil, false
+	}
+	return plan, true
+}
+
+// EstimateRows returns the planner's estimated number of rows returned by a
+// query for a driver, as displayed with \pset rowcount_estimate. Returns false
+// when not supported by the driver, or when the query cannot be explained.
+func EstimateRows(ctx context.Context, u *dburl.URL, db Conn, query string) (int64, bool) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.EstimateRows == nil {
+		return 0, false
+	}
+	n, err := d.EstimateRows(ctx, db, query)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Sample returns the query returning a sample of n rows of the table for a
+// driver, as executed by \sample. Returns the first n rows of the table, using
+// LIMIT, when the driver does not define its own sampling query.
+func Sample(ctx context.Context, u *dburl.URL, db DB, table string, n int) (string, error) {
+	if d, ok := drivers[u.Driver]; ok && d.Sample != nil {
+		sqlstr, err := d.Sample(ctx, db, table, n)
+		if err != nil {
+			return "", WrapErr(u.Driver, err)
+		}
+		return sqlstr, nil
+	}
+	return "SELECT * FROM " + table + " LIMIT " + strconv.Itoa(n), nil
+}
+
+// SampleRandom builds a sample handler ordering the rows of the table by the
+// random function, and returning the first n rows using LIMIT.
+func SampleRandom(random string) func(context.Context, DB, string, int) (string, error) {
+	return func(_ context.Context, _ DB, table string, n int) (string, error) {
+		return "SELECT * FROM " + table + " ORDER BY " + random + " LIMIT " + strconv.Itoa(n), nil
+	}
+}
+
+// Limit returns the SELECT statement limited to n rows for a driver. Appends a
+// LIMIT clause when the driver does not define its own. The statement is
+// returned unchanged when it cannot be limited.
+func Limit(u *dburl.URL, sqlstr string, n int) string {
+	if d, ok := drivers[u.Driver]; ok && d.Limit != nil {
+		return d.Limit(sqlstr, n)
+	}
+	return LimitClause("LIMIT %d")(sqlstr, n)
+}
+
+// LimitClause builds a limit handler appending the clause (such as
+// "FETCH FIRST %d ROWS ONLY") formatted with the number of rows.
+func LimitClause(clause string) func(string, int) string {
+	return func(sqlstr string, n int) string {
+		// keep the clause out of a trailing line comment
+		sep := " "
+		if i := strings.LastIndex(sqlstr, "\n"); strings.Contains(sqlstr[i+1:], "--") {
+			sep = "\n"
+		}
+		return sqlstr + sep + fmt.Sprintf(clause, n)
+	}
+}
+
+// LimitSelect builds a limit handler adding the modifier (such as "TOP (%d)"
+// or "FIRST %d") formatted with the number of rows to the SELECT keyword of
+// the statement, after its DISTINCT or ALL modifier when afterDistinct is
+// true, and before it otherwise. Statements with set operations, where the
+// modifier would only limit the first query, are not limited.
+func LimitSelect(modifier string, afterDistinct bool) func(string, int) string {
+	return func(sqlstr string, n int) string {
+		m := limitSelectRE.FindStringSubmatchIndex(sqlstr)
+		if m == nil {
+			return sqlstr
+		}
+		for _, w := range sqlWords(sqlstr) {
+			switch strings.ToUpper(w) {
+			case "UNION", "INTERSECT", "EXCEPT", "MINUS":
+				return sqlstr
+			}
+		}
+		i := m[3]
+		if afterDistinct {
+			i = m[1]
+		}
+		return sqlstr[:i] + " " + fmt.Sprintf(modifier, n) + sqlstr[i:]
+	}
+}
+
+// limitSelectRE matches the SELECT keyword of a statement, and its DISTINCT
+// or ALL modifier.
+var limitSelectRE = regexp.MustCompile(`(?i)^(\s*SELECT)(\s+(DISTINCT|ALL)\b)?`)
+
+// CommentOn returns the statement setting the comment of the table or column
+// (when typ is COLUMN, with name qualified by its table) for a driver, with an
+// empty comment removing the comment. Uses COMMENT ON when the driver does not
+// define its own.
+func CommentOn(u *dburl.URL, typ, name, comment string) (string, error) {
+	if d, ok := drivers[u.Driver]; ok && d.CommentOn != nil {
+		sqlstr, err := d.CommentOn(typ, name, comment)
+		if err != nil {
+			return "", WrapErr(u.Driver, err)
+		}
+		return sqlstr, nil
+	}
+	return "COMMENT ON " + typ + " " + name + " IS " + QuoteLiteral(u, comment), nil
+}
+
+// InlineRows returns the derived table inlining the rows of a result stored by
+// \store for a driver, as substituted for ::name in a statement. The columns
+// are quoted identifiers, and the values of the rows are SQL literals. Uses a
+// UNION ALL of SELECT statements when the driver does not define its own.
+func InlineRows(u *dburl.URL, name string, cols []string, rows [][]string) string {
+	if d, ok := drivers[u.Driver]; ok && d.InlineRows != nil {
+		return d.InlineRows(name, cols, rows)
+	}
+	return InlineSelect("")(name, cols, rows)
+}
+
+// InlineSelect builds an inline rows handler using a UNION ALL of SELECT
+// statements, selecting from the table (such as Oracle's DUAL) when not
+// empty.
+func InlineSelect(from string) func(string, []string, [][]string) string {
+	if from != "" {
+		from = " FROM " + from
+	}
+	return func(name string, cols []string, rows [][]string) string {
+		var sb strings.Builder
+		sb.WriteString("(SELECT ")
+		if len(rows) == 0 {
+			for i, col := range cols {
+				if i != 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteString("NULL AS " + col)
+			}
+			sb.WriteString(from + " WHERE 1=0) " + name)
+			return sb.String()
+		}
+		for i, row := range rows {
+			if i != 0 {
+				sb.WriteString(" UNION ALL SELECT ")
+			}
+			for j, v := range row {
+				if j != 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteString(v)
+				if i == 0 {
+					sb.WriteString(" AS " + cols[j])
+				}
+			}
+			sb.WriteString(from)
+		}
+		sb.WriteString(") " + name)
+		return sb.String()
+	}
+}
+
+// InlineValues is an inline rows handler using a VALUES list with a column
+// alias list, for databases supporting them (such as PostgreSQL).
+func InlineValues(name string, cols []string, rows [][]string) string {
+	if len(rows) == 0 {
+		return InlineSelect("")(name, cols, rows)
+	}
+	var sb strings.Builder
+	sb.WriteString("(VALUES ")
+	for i, row := range rows {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(" + strings.Join(row, ", ") + ")")
+	}
+	sb.WriteString(") AS " + name + " (" + strings.Join(cols, ", ") + ")")
+	return sb.String()
+}
+
+// QuoteLiteral quotes s as a string literal for a driver, as used by :'NAME'
+// variable interpolation. Uses standard SQL quoting, doubling any single
+// quotes, when the driver does not define its own.
+func QuoteLiteral(u *dburl.URL, s string) string {
+	if u != nil {
+		if d, ok := drivers[u.Driver]; ok && d.QuoteLiteral != nil {
+			return d.QuoteLiteral(s)
+		}
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// QuoteIdentifier quotes s as an identifier for a driver, as used by :"NAME"
+// variable interpolation. Uses standard SQL quoting, doubling any double
+// quotes, when the driver does not define its own.
+func QuoteIdentifier(u *dburl.URL, s string) string {
+	if u != nil {
+		if d, ok := drivers[u.Driver]; ok && d.QuoteIdentifier != nil {
+			return d.QuoteIdentifier(s)
+		}
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Savepoint returns the statements to create, release, and roll back to the
+// named savepoint for a driver. The release statement is empty when the
+// driver does not support releasing savepoints.
+func Savepoint(u *dburl.URL, name string) (string, string, string) {
+	if d, ok := drivers[u.Driver]; ok && d.Savepoint != nil {
+		return d.Savepoint(name)
+	}
+	return "SAVEPOINT " + name, "RELEASE SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+}
+
 // CopyWithInsert builds a copy handler based on insert.
 func CopyWithInsert(placeholder func(int) string) func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error) {
 	if placeholder == nil {
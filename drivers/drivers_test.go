@@ -483,6 +483,109 @@ func TestCopy(t *testing.T) {
 	}
 }
 
+func TestCopyIn(t *testing.T) {
+	testCases := []struct {
+		dbName string
+		data   string
+		opts   drivers.CopyInOptions
+	}{
+		{
+			dbName: "pgsql",
+			data:   "id,name\n1,John\n2,\"Doe, Jane\"\n",
+			opts:   drivers.CopyInOptions{Format: "csv", Header: true},
+		},
+		{
+			dbName: "pgx",
+			data:   "1\tJohn\n2\t\\N\n",
+			opts:   drivers.CopyInOptions{Format: "text"},
+		},
+		{
+			dbName: "sqlserver",
+			data:   "1,John\n2,\n",
+			opts:   drivers.CopyInOptions{Format: "csv"},
+		},
+	}
+	for _, test := range testCases {
+		db, ok := dbs[test.dbName]
+		if !ok {
+			continue
+		}
+		_, _ = db.DB.Exec("DROP TABLE staff_copyin")
+		if _, err := db.DB.Exec("CREATE TABLE staff_copyin (id integer, name varchar(50))"); err != nil {
+			log.Fatalf("Failed to create table: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		n, err := drivers.CopyIn(ctx, db.URL, db.DB, strings.NewReader(test.data), "staff_copyin", test.opts)
+		if err != nil {
+			log.Fatalf("Could not copy in: %v", err)
+		}
+		if n != 2 {
+			log.Fatalf("Expected to copy 2 rows but got %d", n)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	testCases := []struct {
+		dbName  string
+		query   string
+		running string
+	}{
+		{
+			dbName:  "pgsql",
+			query:   "SELECT pg_sleep(30)",
+			running: "SELECT COUNT(*) FROM pg_stat_activity WHERE query = 'SELECT pg_sleep(30)' AND state = 'active'",
+		},
+		{
+			dbName:  "mysql",
+			query:   "SELECT SLEEP(30)",
+			running: "SELECT COUNT(*) FROM information_schema.processlist WHERE info = 'SELECT SLEEP(30)'",
+		},
+		{
+			dbName:  "sqlserver",
+			query:   "WAITFOR DELAY '00:00:30'",
+			running: "SELECT COUNT(*) FROM sys.dm_exec_requests r CROSS APPLY sys.dm_exec_sql_text(r.sql_handle) t WHERE t.text = 'WAITFOR DELAY ''00:00:30'''",
+		},
+	}
+	for _, test := range testCases {
+		db, ok := dbs[test.dbName]
+		if !ok {
+			continue
+		}
+		t.Run(test.dbName, func(t *testing.T) {
+			conn, err := db.DB.Conn(context.Background())
+			if err != nil {
+				t.Fatalf("Could not get connection: %v", err)
+			}
+			defer conn.Close()
+			// interrupt the query, as with Ctrl-C
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+			ctx, stop := drivers.WithCancel(ctx, db.URL, db.DB, conn)
+			defer stop()
+			if _, err := conn.ExecContext(ctx, test.query); err == nil {
+				t.Fatalf("Expected query `%s` to be interrupted", test.query)
+			}
+			// the query should stop running on the server
+			deadline := time.Now().Add(10 * time.Second)
+			for {
+				var n int
+				if err := db.DB.QueryRow(test.running).Scan(&n); err != nil {
+					t.Fatalf("Could not check running queries: %v", err)
+				}
+				if n == 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("Expected query `%s` to be stopped on the server, still running", test.query)
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+		})
+	}
+}
+
 // filesEqual compares the files at paths a and b and returns an error if
 // the content is not equal. Ignore is a regex. All matches will be removed
 // from the file contents before comparison.
@@ -513,3 +616,26 @@ func filesEqual(a, b, ignore string) error {
 	}
 	return nil
 }
+
+func TestLimitSelect(t *testing.T) {
+	top, first := drivers.LimitSelect("TOP (%d)", true), drivers.LimitSelect("FIRST %d", false)
+	tests := []struct {
+		s        string
+		expTop   string
+		expFirst string
+	}{
+		{"SELECT * FROM t", "SELECT TOP (10) * FROM t", "SELECT FIRST 10 * FROM t"},
+		{"select distinct a from t", "select distinct TOP (10) a from t", "select FIRST 10 distinct a from t"},
+		{"SELECT 'union' FROM t", "SELECT TOP (10) 'union' FROM t", "SELECT FIRST 10 'union' FROM t"},
+		{"SELECT a FROM t UNION SELECT b FROM u", "SELECT a FROM t UNION SELECT b FROM u", "SELECT a FROM t UNION SELECT b FROM u"},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", "WITH x AS (SELECT 1) SELECT * FROM x", "WITH x AS (SELECT 1) SELECT * FROM x"},
+	}
+	for i, test := range tests {
+		if s := top(test.s, 10); s != test.expTop {
+			t.Errorf("test %d expected %q, got: %q", i, test.expTop, s)
+		}
+		if s := first(test.s, 10); s != test.expFirst {
+			t.Errorf("test %d expected %q, got: %q", i, test.expFirst, s)
+		}
+	}
+}
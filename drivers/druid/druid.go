@@ -0,0 +1,41 @@
+// Package druid defines and registers usql's Apache Druid driver.
+//
+// Connects to Druid's Avatica (protobuf) SQL endpoint on the broker or router.
+//
+// See: https://github.com/apache/calcite-avatica-go
+package druid
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strconv"
+
+	_ "github.com/apache/calcite-avatica-go/v5" // DRIVER
+	avaticaerrors "github.com/apache/calcite-avatica-go/v5/errors"
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+func init() {
+	drivers.Register("druid", drivers.Driver{
+		AllowMultilineComments: true,
+		Open: func(context.Context, *dburl.URL, func() io.Writer, func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			return func(_ string, dsn string) (*sql.DB, error) {
+				return sql.Open("avatica", dsn)
+			}, nil
+		},
+		Err: func(err error) (string, string) {
+			if e, ok := err.(avaticaerrors.ResponseError); ok {
+				return strconv.Itoa(int(e.ErrorCode)), e.ErrorMessage
+			}
+			return "", err.Error()
+		},
+		NewMetadataReader: NewMetadataReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			return metadata.NewDefaultWriter(NewMetadataReader(db, opts...))(db, w)
+		},
+		Placeholder: func(int) string { return "?" },
+	})
+}
@@ -0,0 +1,231 @@
+package druid
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// MetadataReader reads the metadata of Druid datasources from
+// INFORMATION_SCHEMA, and the segments of datasources from sys.segments.
+//
+// Druid identifiers are case sensitive, and INFORMATION_SCHEMA columns are
+// upper case.
+type MetadataReader struct {
+	metadata.LoggingReader
+}
+
+var (
+	_ metadata.SchemaReader = &MetadataReader{}
+	_ metadata.TableReader  = &MetadataReader{}
+	_ metadata.ColumnReader = &MetadataReader{}
+)
+
+// NewMetadataReader creates the metadata reader for Druid.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	}
+}
+
+// systemSchemas are the schemas of Druid's system tables.
+var systemSchemas = []string{"INFORMATION_SCHEMA", "sys"}
+
+// Schemas lists the schemas.
+func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	conds, vals := conditions(f, "SCHEMA_NAME", "SCHEMA_NAME")
+	qstr := `SELECT "SCHEMA_NAME", "CATALOG_NAME" FROM INFORMATION_SCHEMA.SCHEMATA`
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, closeRows, err := r.Query(qstr+` ORDER BY "SCHEMA_NAME"`, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSchemaSet(nil), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Schema
+	for rows.Next() {
+		var rec metadata.Schema
+		if err := rows.Scan(&rec.Schema, &rec.Catalog); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// Tables lists the tables, with the rows, size, and number of the available
+// segments of datasources.
+func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	conds, vals := conditions(f, "TABLE_SCHEMA", "TABLE_NAME")
+	if len(f.Types) != 0 {
+		var types []string
+		for _, typ := range f.Types {
+			switch typ {
+			case "SYSTEM TABLE":
+				typ = "SYSTEM_TABLE"
+			case "BASE TABLE":
+				typ = "TABLE"
+			}
+			types = append(types, "?")
+			vals = append(vals, typ)
+		}
+		conds = append(conds, `"TABLE_TYPE" IN (`+strings.Join(types, ", ")+`)`)
+	}
+	qstr := `SELECT "TABLE_CATALOG", "TABLE_SCHEMA", "TABLE_NAME", "TABLE_TYPE" FROM INFORMATION_SCHEMA.TABLES`
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, closeRows, err := r.Query(qstr+` ORDER BY "TABLE_SCHEMA", "TABLE_NAME"`, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTableSet(nil), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Table
+	for rows.Next() {
+		var rec metadata.Table
+		if err := rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	if err := r.addSegments(results); err != nil {
+		return nil, err
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// addSegments adds the rows, size, and number of the published and available
+// segments of the datasources in tables.
+func (r MetadataReader) addSegments(tables []metadata.Table) error {
+	datasources := make(map[string]*metadata.Table)
+	for i, t := range tables {
+		if t.Schema == "druid" && t.Type == "TABLE" {
+			datasources[t.Name] = &tables[i]
+		}
+	}
+	if len(datasources) == 0 {
+		return nil
+	}
+	rows, closeRows, err := r.Query(`SELECT "datasource", SUM("num_rows"), SUM("size"), COUNT(*)
+FROM sys.segments
+WHERE "is_published" = 1 AND "is_available" = 1 AND "is_overshadowed" = 0
+GROUP BY "datasource"`)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	defer closeRows()
+	for rows.Next() {
+		var name string
+		var numRows, size, segments int64
+		if err := rows.Scan(&name, &numRows, &size, &segments); err != nil {
+			return err
+		}
+		t, ok := datasources[name]
+		if !ok {
+			continue
+		}
+		t.Rows, t.Size = numRows, metadata.FormatSize(size)
+		t.Comment = strconv.FormatInt(segments, 10) + " segments"
+		if segments == 1 {
+			t.Comment = "1 segment"
+		}
+	}
+	return rows.Err()
+}
+
+// Columns lists the columns of the tables.
+func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	conds, vals := conditions(f, "TABLE_SCHEMA", "COLUMN_NAME")
+	if f.Parent != "" {
+		conds = append(conds, `"TABLE_NAME" LIKE ?`)
+		vals = append(vals, f.Parent)
+	}
+	qstr := `SELECT
+  "TABLE_CATALOG",
+  "TABLE_SCHEMA",
+  "TABLE_NAME",
+  "COLUMN_NAME",
+  "ORDINAL_POSITION",
+  "DATA_TYPE",
+  COALESCE("COLUMN_DEFAULT", ''),
+  COALESCE("CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", 0),
+  COALESCE("NUMERIC_SCALE", 0),
+  COALESCE("NUMERIC_PRECISION_RADIX", 10),
+  COALESCE("CHARACTER_OCTET_LENGTH", 0),
+  "IS_NULLABLE"
+FROM INFORMATION_SCHEMA.COLUMNS`
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, closeRows, err := r.Query(qstr+` ORDER BY "TABLE_SCHEMA", "TABLE_NAME", "ORDINAL_POSITION"`, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewColumnSet(nil), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Column
+	for rows.Next() {
+		var rec metadata.Column
+		err := rows.Scan(
+			&rec.Catalog,
+			&rec.Schema,
+			&rec.Table,
+			&rec.Name,
+			&rec.OrdinalPosition,
+			&rec.DataType,
+			&rec.Default,
+			&rec.ColumnSize,
+			&rec.DecimalDigits,
+			&rec.NumPrecRadix,
+			&rec.CharOctetLength,
+			&rec.IsNullable,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// conditions returns the conditions and values matching the schema and name
+// patterns of the filter, excluding system schemas unless requested.
+func conditions(f metadata.Filter, schemaCol, nameCol string) ([]string, []interface{}) {
+	var conds []string
+	var vals []interface{}
+	if f.Schema != "" {
+		conds = append(conds, `"`+schemaCol+`" LIKE ?`)
+		vals = append(vals, f.Schema)
+	} else if !f.WithSystem {
+		conds = append(conds, `"`+schemaCol+`" NOT IN ('`+strings.Join(systemSchemas, "', '")+`')`)
+	}
+	if f.Name != "" {
+		conds = append(conds, `"`+nameCol+`" LIKE ?`)
+		vals = append(vals, f.Name)
+	}
+	return conds, vals
+}
@@ -17,6 +17,8 @@ func init() {
 		AllowMultilineComments: true,
 		LowerColumnNames:       true,
 		Copy:                   drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:                 drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder:            func(int) string { return "?" },
 		Err: func(err error) (string, string) {
 			code, msg := "", err.Error()
 			if m := errCodeRE.FindStringSubmatch(msg); m != nil {
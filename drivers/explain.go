@@ -0,0 +1,141 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ExplainNode is a node of a query plan, as returned by a driver's Explain.
+type ExplainNode struct {
+	// Name is the plan operation (ie, "Seq Scan", "TABLE ACCESS FULL").
+	Name string
+	// Details are additional properties of the operation, such as costs,
+	// estimated rows, and filter conditions.
+	Details []string
+	// Children are the child operations.
+	Children []*ExplainNode
+	// Time is the time spent in the operation, excluding its children, in
+	// milliseconds, when the query was executed (ie, EXPLAIN ANALYZE).
+	Time float64
+	// Flags are warnings about the operation, such as sorts spilled to disk
+	// or misestimated row counts.
+	Flags []string
+}
+
+// Add adds a child node with the name and details, returning the child.
+func (n *ExplainNode) Add(name string, details ...string) *ExplainNode {
+	child := &ExplainNode{
+		Name:    name,
+		Details: details,
+	}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// WriteTo writes the plan as a tree to w. When the plan has timings, each
+// operation is preceded by a bar of its share of the total time.
+func (n *ExplainNode) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	n.write(cw, "", "", "", n.totalTime())
+	return cw.n, cw.err
+}
+
+// write writes the node and its children, recursively.
+func (n *ExplainNode) write(w io.Writer, prefix, branch, indent string, total float64) {
+	name := n.Name
+	if len(n.Details) != 0 {
+		name += " (" + strings.Join(n.Details, ", ") + ")"
+	}
+	if len(n.Flags) != 0 {
+		name += " ⚠ " + strings.Join(n.Flags, "; ")
+	}
+	var bar string
+	switch {
+	case total <= 0:
+	case prefix == "" && branch == "":
+		bar = strings.Repeat(" ", timingBarWidth+8)
+	default:
+		bar = timingBar(n.Time/total) + " "
+	}
+	fmt.Fprintln(w, bar+prefix+branch+name)
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			c.write(w, prefix+indent, "└── ", "    ", total)
+		} else {
+			c.write(w, prefix+indent, "├── ", "│   ", total)
+		}
+	}
+}
+
+// totalTime returns the total time of the node and its children.
+func (n *ExplainNode) totalTime() float64 {
+	total := n.Time
+	for _, c := range n.Children {
+		total += c.totalTime()
+	}
+	return total
+}
+
+// timingBarWidth is the width of the timing bars.
+const timingBarWidth = 10
+
+// timingBar returns a bar and the percentage of the share of the total time.
+func timingBar(share float64) string {
+	share = min(max(share, 0), 1)
+	filled := int(share*timingBarWidth + 0.5)
+	return fmt.Sprintf("%s%s %5.1f%%",
+		strings.Repeat("█", filled),
+		strings.Repeat("░", timingBarWidth-filled),
+		share*100,
+	)
+}
+
+// countWriter is an io.Writer that keeps track of the written byte count and
+// the first error encountered.
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write satisfies the io.Writer interface.
+func (cw *countWriter) Write(buf []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(buf)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+// ParseIndentedPlan builds a plan from lines of text where the nesting of the
+// operations is expressed by indentation, such as the output of ClickHouse's
+// EXPLAIN or MySQL's EXPLAIN ANALYZE. Leading markers (ie, "->") are removed
+// from each operation.
+func ParseIndentedPlan(name string, lines []string) *ExplainNode {
+	root := &ExplainNode{Name: name}
+	type level struct {
+		indent int
+		node   *ExplainNode
+	}
+	stack := []level{{-1, root}}
+	for _, line := range lines {
+		for _, l := range strings.Split(line, "\n") {
+			s := strings.TrimLeftFunc(l, unicode.IsSpace)
+			if s == "" {
+				continue
+			}
+			indent := len(l) - len(s)
+			s = strings.TrimSpace(strings.TrimPrefix(s, "->"))
+			for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			child := stack[len(stack)-1].node.Add(s)
+			stack = append(stack, level{indent, child})
+		}
+	}
+	return root
+}
@@ -13,6 +13,7 @@ import (
 func init() {
 	drivers.Register("firebirdsql", drivers.Driver{
 		AllowMultilineComments: true,
+		Limit:                  drivers.LimitSelect("FIRST %d", false),
 		Version: func(ctx context.Context, db drivers.DB) (string, error) {
 			var ver string
 			err := db.QueryRowContext(
@@ -0,0 +1,335 @@
+package drivers
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/ildus/usql/dburl"
+)
+
+// FormatOptions are the options for formatting statements.
+type FormatOptions struct {
+	// Indent is the string to indent with.
+	Indent string
+	// KeywordCase is the case to write reserved words in, either upper,
+	// lower, or preserve.
+	KeywordCase string
+}
+
+// Format pretty-prints the statements in sqlstr for a driver, tokenizing the
+// statements with the driver's lexer (as used for syntax highlighting).
+// Clauses are written on their own lines, with their contents indented, and
+// subqueries are indented one level deeper. Strings, comments, and the
+// contents of other parentheses (ie, function calls) are kept as is. Only the
+// reserved words of the lexer's dialect are written in the keyword case, as
+// other keywords may be (case sensitive) identifiers.
+func Format(u *dburl.URL, sqlstr string, opts FormatOptions) (string, error) {
+	l := Lexer(u)
+	it, err := l.Tokenise(nil, sqlstr)
+	if err != nil {
+		return "", err
+	}
+	f := &formatter{opts: opts, reserved: formatDialects[l.Config().Name]}
+	var space bool
+	for _, tok := range it.Tokens() {
+		literal := tok.Type.InCategory(chroma.Comment) || tok.Type.InCategory(chroma.LiteralString)
+		switch n := len(f.items); {
+		case tok.Value == "":
+		case strings.TrimSpace(tok.Value) == "" && !literal:
+			space = true
+		case literal && !space && n != 0 && f.items[n-1].typ == tok.Type:
+			// join the pieces of strings and comments
+			f.items[n-1].val += tok.Value
+		default:
+			f.items = append(f.items, formatItem{typ: tok.Type, val: tok.Value, space: space})
+			space = false
+		}
+	}
+	return f.format(), nil
+}
+
+// formatItem is a token of a statement to format, along with whether it was
+// preceded by whitespace.
+type formatItem struct {
+	typ   chroma.TokenType
+	val   string
+	space bool
+}
+
+// keyword returns the upper cased value of the item when it is a keyword.
+func (item formatItem) keyword() string {
+	if !item.typ.InCategory(chroma.Keyword) {
+		return ""
+	}
+	return strings.ToUpper(item.val)
+}
+
+// formatFrame is the state of a parenthesized expression.
+type formatFrame struct {
+	subquery bool
+	level    int
+	clause   string
+	indent   int
+}
+
+// formatter formats statements.
+type formatter struct {
+	opts FormatOptions
+	// reserved are the additional reserved words of the dialect.
+	reserved map[string]bool
+	items    []formatItem
+	buf   []byte
+	// level is the indent level of the clauses of the current (sub)query.
+	level int
+	// indent is the indent level of the current line.
+	indent int
+	// clause is the current clause.
+	clause string
+	// between is set after BETWEEN, until its AND.
+	between bool
+	// bol is set when at the beginning of a line.
+	bol bool
+	// start is set at the start of a (sub)query.
+	start bool
+	// prev is the previous keyword.
+	prev   string
+	frames []formatFrame
+}
+
+// Formatting keywords.
+var (
+	// formatClauses are keywords starting a clause, with the clause's
+	// contents indented on the following lines.
+	formatClauses = map[string]bool{
+		"SELECT": true, "FROM": true, "WHERE": true, "HAVING": true,
+		"LIMIT": true, "OFFSET": true, "VALUES": true, "SET": true,
+		"RETURNING": true, "WINDOW": true, "GROUP BY": true, "ORDER BY": true,
+	}
+	// formatStatements are keywords starting a statement, on their own line.
+	formatStatements = map[string]bool{
+		"INSERT": true, "UPDATE": true, "DELETE": true, "WITH": true,
+	}
+	// formatSetOps are keywords combining queries, on their own line.
+	formatSetOps = map[string]bool{
+		"UNION": true, "INTERSECT": true, "EXCEPT": true,
+	}
+	// formatJoins are the keywords of a join.
+	formatJoins = map[string]bool{
+		"JOIN": true, "LEFT": true, "RIGHT": true, "FULL": true, "INNER": true,
+		"OUTER": true, "CROSS": true, "NATURAL": true,
+	}
+	// formatLists are the clauses whose comma separated items are written on
+	// their own lines.
+	formatLists = map[string]bool{
+		"SELECT": true, "GROUP BY": true, "ORDER BY": true, "SET": true,
+		"RETURNING": true, "VALUES": true,
+	}
+)
+
+// Reserved words.
+var (
+	// formatReserved are the words reserved in all dialects, written in the
+	// keyword case.
+	formatReserved = formatWords(
+		"ALL", "AND", "AS", "ASC", "BETWEEN", "BY", "CASE", "CROSS", "DELETE",
+		"DESC", "DISTINCT", "ELSE", "END", "EXCEPT", "EXISTS", "FROM", "FULL",
+		"GROUP", "HAVING", "IN", "INNER", "INSERT", "INTERSECT", "INTO", "IS",
+		"JOIN", "LEFT", "LIKE", "LIMIT", "NOT", "NULL", "OFFSET", "ON", "OR",
+		"ORDER", "OUTER", "RIGHT", "SELECT", "SET", "THEN", "UNION", "UPDATE",
+		"USING", "VALUES", "WHEN", "WHERE", "WITH",
+	)
+	// formatDialects are the additional reserved words of the dialects, by
+	// lexer name. Dialects without reserved words of their own (such as
+	// ClickHouse, using the generic SQL lexer, where most keywords are valid
+	// identifiers) only use formatReserved.
+	formatDialects = map[string]map[string]bool{
+		"PostgreSQL SQL dialect": formatWords(
+			"ANY", "ARRAY", "CAST", "CHECK", "COLLATE", "CONSTRAINT", "CREATE",
+			"DEFAULT", "FALSE", "FETCH", "FOREIGN", "GRANT", "ILIKE", "LATERAL",
+			"NATURAL", "PRIMARY", "REFERENCES", "RETURNING", "TABLE", "TRUE",
+			"UNIQUE", "WINDOW",
+		),
+		"MySQL": formatWords(
+			"CHECK", "COLLATE", "CONSTRAINT", "CREATE", "DEFAULT", "FALSE",
+			"FOREIGN", "GRANT", "NATURAL", "PRIMARY", "REFERENCES", "REPLACE",
+			"TABLE", "TRUE", "UNIQUE", "WINDOW",
+		),
+		"Transact-SQL": formatWords(
+			"CHECK", "COLLATE", "CONSTRAINT", "CREATE", "DEFAULT", "FOREIGN",
+			"GRANT", "PRIMARY", "REFERENCES", "TABLE", "TOP", "UNIQUE",
+		),
+	}
+)
+
+// formatWords returns a set of words.
+func formatWords(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, word := range words {
+		m[word] = true
+	}
+	return m
+}
+
+// format formats the items.
+func (f *formatter) format() string {
+	f.start = true
+	for i := 0; i < len(f.items); i++ {
+		item := f.items[i]
+		kw := item.keyword()
+		// only format the top level of (sub)queries
+		if n := len(f.frames); n != 0 && !f.frames[n-1].subquery {
+			switch item.val {
+			case "(":
+				f.push(false)
+			case ")":
+				f.pop()
+			}
+			f.write(item)
+			continue
+		}
+		switch {
+		case kw == "GROUP" || kw == "ORDER":
+			if i+1 < len(f.items) && f.items[i+1].keyword() == "BY" {
+				f.newline(f.level)
+				f.write(item)
+				f.write(f.items[i+1])
+				f.clause, f.between = kw+" BY", false
+				f.newline(f.level + 1)
+				i++
+				continue
+			}
+		case kw == "FROM" && (f.prev == "DELETE" || f.prev == "DISTINCT"):
+			// DELETE FROM and IS DISTINCT FROM
+			f.clause = kw
+		case formatClauses[kw]:
+			f.newline(f.level)
+			f.write(item)
+			f.clause, f.between = kw, false
+			f.newline(f.level + 1)
+			continue
+		case formatStatements[kw] && (f.start || f.clause == "WITH"), formatSetOps[kw]:
+			f.newline(f.level)
+			f.clause = kw
+		case formatJoins[kw] && f.isJoin(i):
+			f.newline(f.level + 1)
+			for ; i < len(f.items) && f.items[i].keyword() != "JOIN"; i++ {
+				f.write(f.items[i])
+			}
+			f.write(f.items[i])
+			f.clause = "JOIN"
+			continue
+		case kw == "BETWEEN":
+			f.between = true
+		case kw == "AND" && f.between:
+			f.between = false
+		case (kw == "AND" || kw == "OR") && (f.clause == "WHERE" || f.clause == "HAVING"):
+			f.newline(f.level + 1)
+		case item.val == "(":
+			if i+1 < len(f.items) {
+				if next := f.items[i+1].keyword(); next == "SELECT" || next == "WITH" {
+					f.write(item)
+					f.push(true)
+					continue
+				}
+			}
+			f.write(item)
+			f.push(false)
+			continue
+		case item.val == ")" && len(f.frames) != 0:
+			indent := f.frames[len(f.frames)-1].indent
+			f.pop()
+			f.newline(indent)
+		case item.val == "," && formatLists[f.clause]:
+			f.write(item)
+			f.newline(f.level + 1)
+			continue
+		case item.val == ";":
+			f.write(item)
+			f.level, f.clause, f.between, f.frames = 0, "", false, nil
+			f.newline(0)
+			f.start = true
+			continue
+		}
+		f.write(item)
+	}
+	return strings.TrimSpace(string(f.buf))
+}
+
+// isJoin determines if the join keyword at i is followed by JOIN.
+func (f *formatter) isJoin(i int) bool {
+	for ; i < len(f.items); i++ {
+		switch kw := f.items[i].keyword(); {
+		case kw == "JOIN":
+			return true
+		case !formatJoins[kw]:
+			return false
+		}
+	}
+	return false
+}
+
+// push pushes a parenthesized expression.
+func (f *formatter) push(subquery bool) {
+	f.frames = append(f.frames, formatFrame{subquery: subquery, level: f.level, clause: f.clause, indent: f.indent})
+	if subquery {
+		f.level, f.clause, f.start = f.indent+1, "", true
+	}
+}
+
+// pop pops a parenthesized expression.
+func (f *formatter) pop() {
+	if n := len(f.frames); n != 0 {
+		f.level, f.clause = f.frames[n-1].level, f.frames[n-1].clause
+		f.frames = f.frames[:n-1]
+	}
+}
+
+// newline starts a new line indented to level, unless at the beginning of a
+// line already.
+func (f *formatter) newline(level int) {
+	if len(f.buf) == 0 {
+		return
+	}
+	// remove trailing whitespace, including the indent when already at the
+	// beginning of a line
+	f.buf = bytes.TrimRight(f.buf, " \t")
+	if !f.bol {
+		f.buf = append(f.buf, '\n')
+	}
+	f.buf = append(f.buf, strings.Repeat(f.opts.Indent, level)...)
+	f.bol, f.indent = true, level
+}
+
+// write writes the item, applying the keyword case to reserved words.
+func (f *formatter) write(item formatItem) {
+	s, eol := item.val, false
+	if item.typ.InCategory(chroma.CommentSingle) {
+		s = strings.TrimRight(s, "\r\n")
+		eol = s != item.val
+	}
+	if kw := item.keyword(); formatReserved[kw] || f.reserved[kw] {
+		switch f.opts.KeywordCase {
+		case "upper":
+			s = strings.ToUpper(s)
+		case "lower":
+			s = strings.ToLower(s)
+		}
+	}
+	if item.space && !f.bol && len(f.buf) != 0 {
+		f.buf = append(f.buf, ' ')
+	}
+	f.buf = append(f.buf, s...)
+	f.bol = false
+	if !item.typ.InCategory(chroma.Comment) {
+		f.start, f.prev = false, item.keyword()
+	}
+	// line comments end the line
+	if eol {
+		level := f.level
+		if f.clause != "" {
+			level++
+		}
+		f.newline(level)
+	}
+}
@@ -0,0 +1,65 @@
+package drivers_test
+
+import (
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		urlstr      string
+		sqlstr      string
+		keywordCase string
+		exp         string
+	}{
+		{
+			"pg://", `select a, b from t where a > 1 and b is not null order by a`, "upper",
+			"SELECT\n  a,\n  b\nFROM\n  t\nWHERE\n  a > 1\n  AND b IS NOT NULL\nORDER BY\n  a",
+		},
+		{
+			"pg://", `SELECT a FROM t WHERE b = 'select'`, "lower",
+			"select\n  a\nfrom\n  t\nwhere\n  b = 'select'",
+		},
+		{
+			"pg://", `select a from t where b in (select b from u) -- comment`, "preserve",
+			"select\n  a\nfrom\n  t\nwhere\n  b in (\n    select\n      b\n    from\n      u\n  ) -- comment",
+		},
+		{
+			"pg://", `select count(a) from t left outer join u on t.a = u.a group by b`, "upper",
+			"SELECT\n  count(a)\nFROM\n  t\n  LEFT OUTER JOIN u ON t.a = u.a\nGROUP BY\n  b",
+		},
+		{
+			"pg://", `insert into t (a) values (1) returning a; select 1`, "upper",
+			"INSERT INTO t (a)\nVALUES\n  (1)\nRETURNING\n  a;\nSELECT\n  1",
+		},
+		// unreserved keywords used as identifiers keep their case
+		{
+			"ch://", `select id, type from user where type = 1 and timestamp > 2`, "upper",
+			"SELECT\n  id,\n  type\nFROM\n  user\nWHERE\n  type = 1\n  AND timestamp > 2",
+		},
+		{
+			"my://", `select date from user where date between 1 and 2`, "upper",
+			"SELECT\n  date\nFROM\n  user\nWHERE\n  date BETWEEN 1 AND 2",
+		},
+		// reserved words of other dialects keep their case
+		{
+			"ch://", `select database, table from system.tables`, "upper",
+			"SELECT\n  database,\n  table\nFROM\n  system.tables",
+		},
+	}
+	for i, test := range tests {
+		u, err := dburl.Parse(test.urlstr)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s, err := drivers.Format(u, test.sqlstr, drivers.FormatOptions{Indent: "  ", KeywordCase: test.keywordCase})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s != test.exp {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, test.exp, s)
+		}
+	}
+}
@@ -1,6 +1,5 @@
 // Package genji defines and registers usql's Genji driver.
 //
-// Group: bad
 // See: https://github.com/genjidb/genji
 package genji
 
@@ -10,5 +9,7 @@ import (
 )
 
 func init() {
-	drivers.Register("genji", drivers.Driver{})
+	drivers.Register("genji", drivers.Driver{
+		NewMetadataReader: NewMetadataReader,
+	})
 }
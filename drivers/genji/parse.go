@@ -0,0 +1,221 @@
+package genji
+
+import (
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// tableDef is a table definition parsed from a CREATE TABLE statement.
+type tableDef struct {
+	fields      []fieldDef
+	constraints []constraintDef
+}
+
+// fieldDef is a field constraint of a table definition.
+type fieldDef struct {
+	name    string
+	typ     string
+	def     string
+	notNull bool
+}
+
+// constraintDef is a table constraint of a table definition.
+type constraintDef struct {
+	name  string
+	typ   string
+	paths []string
+	check string
+}
+
+// nullable returns whether the field can be null.
+func (t tableDef) nullable(f fieldDef) metadata.Bool {
+	if f.notNull {
+		return metadata.NO
+	}
+	for _, c := range t.constraints {
+		if c.typ != "PRIMARY KEY" {
+			continue
+		}
+		for _, p := range c.paths {
+			if p == f.name {
+				return metadata.NO
+			}
+		}
+	}
+	return metadata.YES
+}
+
+// parseTable parses the field and table constraints of a CREATE TABLE
+// statement, as stored in the catalog:
+//
+//	CREATE TABLE foo (a INTEGER NOT NULL, b (c TEXT DEFAULT "x"), CONSTRAINT foo_pk PRIMARY KEY (a), ...)
+func parseTable(sqlstr string) tableDef {
+	var t tableDef
+	start := strings.IndexByte(sqlstr, '(')
+	if start == -1 {
+		return t
+	}
+	end := matchParen(sqlstr, start)
+	for _, s := range splitList(sqlstr[start+1 : end]) {
+		switch {
+		case s == "...":
+		case strings.HasPrefix(s, "CONSTRAINT "):
+			t.constraints = append(t.constraints, parseConstraint(strings.TrimPrefix(s, "CONSTRAINT ")))
+		default:
+			t.fields = append(t.fields, parseField("", s)...)
+		}
+	}
+	return t
+}
+
+// parseField parses a field constraint, returning the field and any fields
+// of its anonymous document type, prefixed with the path of the field.
+func parseField(prefix, s string) []fieldDef {
+	name, rest := parseIdent(s)
+	f := fieldDef{
+		name: prefix + name,
+		typ:  "DOCUMENT",
+	}
+	var fields []fieldDef
+	switch {
+	case strings.HasPrefix(rest, "("):
+		end := matchParen(rest, 0)
+		for _, s := range splitList(rest[1:end]) {
+			if s != "..." {
+				fields = append(fields, parseField(f.name+".", s)...)
+			}
+		}
+		rest = rest[end+1:]
+	case strings.HasPrefix(rest, "DOCUMENT (...)"):
+		rest = strings.TrimPrefix(rest, "DOCUMENT (...)")
+	default:
+		f.typ, rest, _ = strings.Cut(rest, " ")
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "NOT NULL") {
+		f.notNull, rest = true, strings.TrimSpace(strings.TrimPrefix(rest, "NOT NULL"))
+	}
+	f.def = strings.TrimPrefix(rest, "DEFAULT ")
+	return append([]fieldDef{f}, fields...)
+}
+
+// parseConstraint parses a table constraint, without the leading CONSTRAINT
+// keyword.
+func parseConstraint(s string) constraintDef {
+	var c constraintDef
+	c.name, s = parseIdent(s)
+	switch {
+	case strings.HasPrefix(s, "CHECK "):
+		c.typ, c.check = "CHECK", parens(strings.TrimPrefix(s, "CHECK "))
+	case strings.HasPrefix(s, "PRIMARY KEY "):
+		c.typ, c.paths = "PRIMARY KEY", splitList(parens(strings.TrimPrefix(s, "PRIMARY KEY ")))
+	case strings.HasPrefix(s, "UNIQUE "):
+		c.typ, c.paths = "UNIQUE", splitList(parens(strings.TrimPrefix(s, "UNIQUE ")))
+	}
+	return c
+}
+
+// parseIndex parses a CREATE INDEX statement, as stored in the catalog,
+// returning whether the index is unique and the indexed paths:
+//
+//	CREATE UNIQUE INDEX foo_a_idx ON foo (a, b.c)
+func parseIndex(sqlstr string) (bool, []string) {
+	start := strings.LastIndexByte(sqlstr, '(')
+	if start == -1 {
+		return false, nil
+	}
+	return strings.HasPrefix(sqlstr, "CREATE UNIQUE "), splitList(parens(sqlstr[start:]))
+}
+
+// parseIdent parses a possibly quoted identifier at the start of s, returning
+// the unquoted identifier and the remainder of s.
+func parseIdent(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ""
+	}
+	if q := s[0]; q == '`' || q == '"' {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != q {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == q {
+				sb.WriteByte(q)
+				i++
+				continue
+			}
+			return sb.String(), strings.TrimSpace(s[i+1:])
+		}
+		return sb.String(), ""
+	}
+	i := strings.IndexAny(s, " (")
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// parens returns the contents of the parenthesized expression at the start
+// of s.
+func parens(s string) string {
+	if !strings.HasPrefix(s, "(") {
+		return s
+	}
+	return s[1:matchParen(s, 0)]
+}
+
+// matchParen returns the position of the parenthesis closing the one at
+// start, skipping quoted strings and identifiers, or the length of s when not
+// closed.
+func matchParen(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// splitList splits a comma separated list, skipping commas within
+// parentheses and quotes.
+func splitList(s string) []string {
+	var items []string
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items, last = append(items, strings.TrimSpace(s[last:i])), i+1
+		}
+	}
+	if s = strings.TrimSpace(s[last:]); s != "" {
+		items = append(items, s)
+	}
+	return items
+}
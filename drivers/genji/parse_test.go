@@ -0,0 +1,65 @@
+package genji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		sqlstr string
+		exp    tableDef
+	}{
+		{"CREATE TABLE foo", tableDef{}},
+		{
+			"CREATE TABLE `foo bar` (a INTEGER NOT NULL DEFAULT 1, b (c DOUBLE, d TEXT, ...), e TEXT, " +
+				`CONSTRAINT foo_pk PRIMARY KEY (a), CONSTRAINT "foo_b.d_unique" UNIQUE (b.d), ` +
+				`CONSTRAINT foo_check CHECK (e != "a, (b)"), ...)`,
+			tableDef{
+				fields: []fieldDef{
+					{name: "a", typ: "INTEGER", def: "1", notNull: true},
+					{name: "b", typ: "DOCUMENT"},
+					{name: "b.c", typ: "DOUBLE"},
+					{name: "b.d", typ: "TEXT"},
+					{name: "e", typ: "TEXT"},
+				},
+				constraints: []constraintDef{
+					{name: "foo_pk", typ: "PRIMARY KEY", paths: []string{"a"}},
+					{name: "foo_b.d_unique", typ: "UNIQUE", paths: []string{"b.d"}},
+					{name: "foo_check", typ: "CHECK", check: `e != "a, (b)"`},
+				},
+			},
+		},
+		{
+			"CREATE TABLE foo (a DOCUMENT (...) NOT NULL, b ANY DEFAULT \"x\")",
+			tableDef{
+				fields: []fieldDef{
+					{name: "a", typ: "DOCUMENT", notNull: true},
+					{name: "b", typ: "ANY", def: `"x"`},
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		if def := parseTable(test.sqlstr); !reflect.DeepEqual(def, test.exp) {
+			t.Errorf("test %d expected %+v, got: %+v", i, test.exp, def)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		sqlstr string
+		unique bool
+		paths  []string
+	}{
+		{"CREATE INDEX foo_a_idx ON foo (a)", false, []string{"a"}},
+		{"CREATE UNIQUE INDEX `foo idx` ON foo (a, b.c)", true, []string{"a", "b.c"}},
+	}
+	for i, test := range tests {
+		unique, paths := parseIndex(test.sqlstr)
+		if unique != test.unique || !reflect.DeepEqual(paths, test.paths) {
+			t.Errorf("test %d expected %t %v, got: %t %v", i, test.unique, test.paths, unique, paths)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package genji
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// MetadataReader reads the metadata of a Genji database from its catalog.
+//
+// Genji does not have a schema for table fields, other than the field
+// constraints declared when creating the table, so the fields and constraints
+// are parsed from the CREATE statements stored in the catalog.
+type MetadataReader struct {
+	metadata.LoggingReader
+}
+
+// NewMetadataReader creates the metadata reader for genji databases.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	}
+}
+
+func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	tables, err := r.tables(f.Name, f.WithSystem)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]bool, len(f.Types))
+	for _, t := range f.Types {
+		types[t] = true
+	}
+	var results []metadata.Table
+	for _, t := range tables {
+		rec := metadata.Table{
+			Name: t.name,
+			Type: "TABLE",
+		}
+		if isSystem(t.name) {
+			rec.Type = "SYSTEM TABLE"
+		}
+		if len(types) != 0 && !types[rec.Type] {
+			continue
+		}
+		results = append(results, rec)
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	tables, err := r.tables(f.Parent, true)
+	if err != nil {
+		return nil, err
+	}
+	var results []metadata.Column
+	for _, t := range tables {
+		def := parseTable(t.sql)
+		for i, field := range def.fields {
+			results = append(results, metadata.Column{
+				Table:           t.name,
+				Name:            field.name,
+				OrdinalPosition: i + 1,
+				DataType:        field.typ,
+				Default:         field.def,
+				IsNullable:      def.nullable(field),
+			})
+		}
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+func (r MetadataReader) Indexes(f metadata.Filter) (*metadata.IndexSet, error) {
+	var results []metadata.Index
+	if f.Name == "" {
+		// the primary key is not an index in the catalog, but is the key
+		// of the table
+		tables, err := r.tables(f.Parent, f.WithSystem)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tables {
+			for _, c := range parseTable(t.sql).constraints {
+				if c.typ == "PRIMARY KEY" {
+					results = append(results, metadata.Index{
+						Table:     t.name,
+						Name:      c.name,
+						IsPrimary: metadata.YES,
+						IsUnique:  metadata.YES,
+						Columns:   strings.Join(c.paths, ", "),
+					})
+				}
+			}
+		}
+	}
+	indexes, err := r.indexes(f.Parent, f.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		rec := metadata.Index{
+			Table:     i.table,
+			Name:      i.name,
+			IsPrimary: metadata.NO,
+			IsUnique:  metadata.NO,
+			Columns:   strings.Join(i.paths, ", "),
+		}
+		if i.unique {
+			rec.IsUnique = metadata.YES
+		}
+		results = append(results, rec)
+	}
+	return metadata.NewIndexSet(results), nil
+}
+
+func (r MetadataReader) IndexColumns(f metadata.Filter) (*metadata.IndexColumnSet, error) {
+	tables, err := r.tables(f.Parent, true)
+	if err != nil {
+		return nil, err
+	}
+	var results []metadata.IndexColumn
+	for _, t := range tables {
+		def := parseTable(t.sql)
+		types := make(map[string]string, len(def.fields))
+		for _, field := range def.fields {
+			types[field.name] = field.typ
+		}
+		add := func(name string, paths []string) {
+			for i, p := range paths {
+				results = append(results, metadata.IndexColumn{
+					Table:           t.name,
+					IndexName:       name,
+					Name:            p,
+					DataType:        types[p],
+					OrdinalPosition: i + 1,
+					KeyPosition:     i + 1,
+				})
+			}
+		}
+		for _, c := range def.constraints {
+			if c.typ == "PRIMARY KEY" && (f.Name == "" || f.Name == c.name) {
+				add(c.name, c.paths)
+			}
+		}
+		indexes, err := r.indexes(t.name, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range indexes {
+			add(i.name, i.paths)
+		}
+	}
+	return metadata.NewIndexColumnSet(results), nil
+}
+
+func (r MetadataReader) Constraints(f metadata.Filter) (*metadata.ConstraintSet, error) {
+	tables, err := r.tables(f.Parent, true)
+	if err != nil {
+		return nil, err
+	}
+	var results []metadata.Constraint
+	for _, t := range tables {
+		for _, c := range parseTable(t.sql).constraints {
+			if f.Name != "" && f.Name != c.name {
+				continue
+			}
+			results = append(results, metadata.Constraint{
+				Table:       t.name,
+				Name:        c.name,
+				Type:        c.typ,
+				CheckClause: c.check,
+			})
+		}
+	}
+	return metadata.NewConstraintSet(results), nil
+}
+
+// catalogTable is a table in the catalog.
+type catalogTable struct {
+	name, sql string
+}
+
+// tables returns the tables in the catalog matching the name pattern, when
+// not empty.
+func (r MetadataReader) tables(name string, withSystem bool) ([]catalogTable, error) {
+	qstr := `SELECT
+  name,
+  sql
+FROM
+  __genji_catalog`
+	conds := []string{"type = 'table'"}
+	var vals []interface{}
+	if name != "" {
+		vals = append(vals, name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []catalogTable
+	for rows.Next() {
+		var rec catalogTable
+		if err := rows.Scan(&rec.name, &rec.sql); err != nil {
+			return nil, err
+		}
+		if !withSystem && isSystem(rec.name) {
+			continue
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return results, nil
+}
+
+// catalogIndex is an index in the catalog.
+type catalogIndex struct {
+	table, name string
+	unique      bool
+	paths       []string
+}
+
+// indexes returns the indexes in the catalog on the tables matching the
+// table pattern, and matching the name pattern, when not empty.
+func (r MetadataReader) indexes(table, name string) ([]catalogIndex, error) {
+	qstr := `SELECT
+  owner.table_name,
+  name,
+  sql
+FROM
+  __genji_catalog`
+	conds := []string{"type = 'index'"}
+	var vals []interface{}
+	if table != "" {
+		vals = append(vals, table)
+		conds = append(conds, "owner.table_name LIKE ?")
+	}
+	if name != "" {
+		vals = append(vals, name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []catalogIndex
+	for rows.Next() {
+		var rec catalogIndex
+		var sqlstr string
+		if err := rows.Scan(&rec.table, &rec.name, &sqlstr); err != nil {
+			return nil, err
+		}
+		rec.unique, rec.paths = parseIndex(sqlstr)
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return results, nil
+}
+
+func (r MetadataReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
+	if len(conds) != 0 {
+		qstr += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	if order != "" {
+		qstr += "\nORDER BY " + order
+	}
+	return r.Query(qstr, vals...)
+}
+
+// isSystem returns true when name is an internal Genji table.
+func isSystem(name string) bool {
+	return strings.HasPrefix(name, "__genji_")
+}
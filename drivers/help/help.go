@@ -0,0 +1,224 @@
+// Package help provides SQL syntax help for database drivers, loaded from
+// embedded per-driver help corpora.
+package help
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ildus/usql/dburl"
+)
+
+// corpora are the embedded help corpora, one file per driver.
+//
+//go:embed corpus/*.txt
+var corpora embed.FS
+
+// aliases are drivers sharing the help corpus of another driver.
+var aliases = map[string]string{
+	"pgx":           "postgres",
+	"mymysql":       "mysql",
+	"moderncsqlite": "sqlite3",
+}
+
+// Topic is the syntax help for a SQL command.
+type Topic struct {
+	Driver string
+	Name   string
+	Desc   string
+	Syntax string
+}
+
+var (
+	once   sync.Once
+	topics map[string][]Topic
+)
+
+// load loads the embedded help corpora.
+//
+// A corpus contains topics starting with a "## NAME" line, followed by a
+// description line, and the command syntax. Lines before the first topic,
+// noting the source and license of the corpus, are ignored.
+func load() {
+	topics = make(map[string][]Topic)
+	entries, _ := corpora.ReadDir("corpus")
+	for _, entry := range entries {
+		buf, err := corpora.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			continue
+		}
+		driver := strings.TrimSuffix(entry.Name(), ".txt")
+		var t *Topic
+		var syntax []string
+		add := func() {
+			if t != nil {
+				t.Syntax = strings.TrimRight(strings.Join(syntax, "\n"), "\n ")
+				topics[driver] = append(topics[driver], *t)
+			}
+		}
+		s := bufio.NewScanner(strings.NewReader(string(buf)))
+		for s.Scan() {
+			line := s.Text()
+			switch {
+			case strings.HasPrefix(line, "## "):
+				add()
+				t, syntax = &Topic{Driver: driver, Name: normalize(line[3:])}, nil
+			case t != nil && t.Desc == "":
+				t.Desc = strings.TrimSpace(line)
+			case t != nil:
+				syntax = append(syntax, line)
+			}
+		}
+		add()
+		sort.Slice(topics[driver], func(i, j int) bool {
+			return topics[driver][i].Name < topics[driver][j].Name
+		})
+	}
+}
+
+// Drivers returns the drivers with a help corpus.
+func Drivers() []string {
+	once.Do(load)
+	var drivers []string
+	for driver := range topics {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
+
+// Has returns true when driver has a help corpus.
+func Has(driver string) bool {
+	once.Do(load)
+	_, ok := topics[corpus(driver)]
+	return ok
+}
+
+// Topics returns the help topics for driver.
+func Topics(driver string) []Topic {
+	once.Do(load)
+	return topics[corpus(driver)]
+}
+
+// Lookup returns the help topics for driver matching name, and whether the
+// topics are only suggestions for a name without a match.
+//
+// A name of "*" matches all topics. Otherwise, topics are matched by their
+// name, by prefix (for example, "ALTER" matches all ALTER commands), or, when
+// trailing words do not match a topic, the longest leading words matching a
+// topic. Failing that, topics with a similar name are suggested.
+func Lookup(driver, name string) ([]Topic, bool) {
+	all, name := Topics(driver), normalize(name)
+	if name == "*" {
+		return all, false
+	}
+	for words := strings.Fields(name); len(words) != 0; words = words[:len(words)-1] {
+		s := strings.Join(words, " ")
+		var matches []Topic
+		for _, t := range all {
+			switch {
+			case t.Name == s:
+				return []Topic{t}, false
+			case strings.HasPrefix(t.Name, s):
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) != 0 {
+			return matches, false
+		}
+	}
+	// suggest similar topics
+	var suggestions []Topic
+	for _, t := range all {
+		if strings.Contains(t.Name, name) || distance(t.Name, name) <= max(2, len(name)/3) {
+			suggestions = append(suggestions, t)
+		}
+	}
+	return suggestions, true
+}
+
+// Write writes the syntax help for the topics to w. When prefix is true, the
+// topic names are prefixed with the driver's short alias.
+func Write(w io.Writer, topics []Topic, prefix bool) {
+	for i, t := range topics {
+		if i != 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "Command:     "+t.title(prefix))
+		fmt.Fprintln(w, "Description: "+t.Desc)
+		fmt.Fprintln(w, "Syntax:")
+		fmt.Fprintln(w, t.Syntax)
+	}
+}
+
+// List writes the names of the topics to w, in columns. When prefix is true,
+// the topic names are prefixed with the driver's short alias.
+func List(w io.Writer, topics []Topic, prefix bool) {
+	names, width := make([]string, len(topics)), 0
+	for i, t := range topics {
+		names[i] = t.title(prefix)
+		width = max(width, len(names[i])+2)
+	}
+	cols := max(1, 78/max(width, 1))
+	rows := (len(names) + cols - 1) / cols
+	for i := 0; i < rows; i++ {
+		var sb strings.Builder
+		for j := i; j < len(names); j += rows {
+			fmt.Fprintf(&sb, "  %-*s", width-2, names[j])
+		}
+		fmt.Fprintln(w, strings.TrimRight(sb.String(), " "))
+	}
+}
+
+// title returns the name of the topic, optionally prefixed with the driver's
+// short alias.
+func (t Topic) title(prefix bool) string {
+	if !prefix {
+		return t.Name
+	}
+	alias := t.Driver
+	if _, aliases := dburl.SchemeDriverAndAliases(t.Driver); len(aliases) != 0 {
+		alias = aliases[0]
+	}
+	return alias + ":" + t.Name
+}
+
+// corpus returns the name of the help corpus for driver.
+func corpus(driver string) string {
+	if s, ok := aliases[driver]; ok {
+		return s
+	}
+	return driver
+}
+
+// normalize normalizes a topic name.
+func normalize(name string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	r, s := []rune(a), []rune(b)
+	prev, cur := make([]int, len(s)+1), make([]int, len(s)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r); i++ {
+		cur[0] = i
+		for j := 1; j <= len(s); j++ {
+			cost := 1
+			if r[i-1] == s[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(s)]
+}
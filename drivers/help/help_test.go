@@ -0,0 +1,48 @@
+package help
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		driver  string
+		name    string
+		exp     []string
+		suggest bool
+	}{
+		{"postgres", "copy", []string{"COPY"}, false},
+		{"pgx", "alter  table", []string{"ALTER TABLE"}, false},
+		{"postgres", "ALTER TABLE books ADD COLUMN", []string{"ALTER TABLE"}, false},
+		{"postgres", "create", []string{"CREATE INDEX", "CREATE TABLE", "CREATE VIEW"}, false},
+		{"clickhouse", "altr tabel", []string{"ALTER TABLE"}, true},
+		{"sqlite3", "zzzzzzzzzz", nil, true},
+		{"ingres", "modify", []string{"MODIFY"}, false},
+		{"mysql", "alter", []string{"ALTER TABLE"}, false},
+		{"csvq", "select", nil, true},
+	}
+	for i, test := range tests {
+		topics, suggest := Lookup(test.driver, test.name)
+		var names []string
+		for _, topic := range topics {
+			names = append(names, topic.Name)
+		}
+		if s, exp := strings.Join(names, ","), strings.Join(test.exp, ","); s != exp {
+			t.Errorf("test %d expected %q, got: %q", i, exp, s)
+		}
+		if suggest != test.suggest {
+			t.Errorf("test %d expected suggest %t, got: %t", i, test.suggest, suggest)
+		}
+	}
+}
+
+func TestCorpora(t *testing.T) {
+	for _, driver := range Drivers() {
+		for _, topic := range Topics(driver) {
+			if topic.Desc == "" || topic.Syntax == "" {
+				t.Errorf("%s topic %q has no description or syntax", driver, topic.Name)
+			}
+		}
+	}
+}
@@ -5,10 +5,17 @@
 package hive
 
 import (
+	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
 	_ "sqlflow.org/gohive" // DRIVER
 )
 
 func init() {
-	drivers.Register("hive", drivers.Driver{})
+	drivers.Register("hive", drivers.Driver{
+		GSSAPI: func(u *dburl.URL) {
+			// gohive handles kerberos natively with its own auth parameter
+			drivers.ForceQueryParameters([]string{"auth", "KERBEROS"})(u)
+		},
+		NewMetadataReader: NewMetadataReader,
+	})
 }
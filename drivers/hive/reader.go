@@ -0,0 +1,175 @@
+package hive
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// MetadataReader reads the metadata of Hive databases.
+type MetadataReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.ForeignTableReader = &MetadataReader{}
+
+// NewMetadataReader creates the metadata reader for Hive databases.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	}
+}
+
+// ForeignTables lists the external tables, as reported by DESCRIBE FORMATTED.
+// Server is the location of the table's data, and Wrapper its storage handler
+// or input format.
+func (r MetadataReader) ForeignTables(f metadata.Filter) (*metadata.ForeignTableSet, error) {
+	schemas := []string{""}
+	if f.Schema != "" {
+		var err error
+		if schemas, err = r.list(`SHOW DATABASES`, f.Schema); err != nil {
+			return nil, err
+		}
+	}
+	var results []metadata.ForeignTable
+	for _, schema := range schemas {
+		qstr := `SHOW TABLES`
+		if schema != "" {
+			qstr += ` IN ` + schema
+		}
+		tables, err := r.list(qstr, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			rec, err := r.describe(schema, table)
+			if err != nil {
+				return nil, err
+			}
+			if rec != nil {
+				results = append(results, *rec)
+			}
+		}
+	}
+	return metadata.NewForeignTableSet(results), nil
+}
+
+// list returns the first column of the results of qstr, matching the LIKE
+// pattern when not empty.
+func (r MetadataReader) list(qstr, pattern string) ([]string, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		re = likeRE(pattern)
+	}
+	rows, closeRows, err := r.Query(qstr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if re == nil || re.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return names, nil
+}
+
+// describe returns the table when it is an external table.
+func (r MetadataReader) describe(schema, table string) (*metadata.ForeignTable, error) {
+	name := table
+	if schema != "" {
+		name = schema + "." + table
+	}
+	rows, closeRows, err := r.Query(`DESCRIBE FORMATTED ` + name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	rec := metadata.ForeignTable{
+		Schema: schema,
+		Name:   table,
+		Type:   "EXTERNAL TABLE",
+	}
+	var external bool
+	var inputFormat string
+	var options []string
+	for rows.Next() {
+		var k, v, param sql.NullString
+		if err := rows.Scan(&k, &v, &param); err != nil {
+			return nil, err
+		}
+		key, value := strings.TrimSpace(k.String), strings.TrimSpace(v.String)
+		switch key {
+		case "Database:":
+			rec.Schema = value
+		case "Owner:":
+			rec.Owner = value
+		case "Location:":
+			rec.Server = value
+		case "Table Type:":
+			external = value == "EXTERNAL_TABLE"
+		case "InputFormat:":
+			inputFormat = value
+		case "":
+			// table parameters
+			switch p := strings.TrimSpace(param.String); value {
+			case "", "EXTERNAL", "numFiles", "numRows", "rawDataSize", "totalSize",
+				"transient_lastDdlTime", "COLUMN_STATS_ACCURATE", "bucketing_version":
+			case "comment":
+				rec.Comment = p
+			case "storage_handler":
+				rec.Wrapper = p
+			default:
+				options = append(options, value+"="+p)
+			}
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	if !external {
+		return nil, nil
+	}
+	if rec.Wrapper == "" {
+		rec.Wrapper = inputFormat
+	}
+	sort.Strings(options)
+	rec.Options = strings.Join(options, ", ")
+	return &rec, nil
+}
+
+// likeRE converts a LIKE pattern to a regexp.
+func likeRE(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
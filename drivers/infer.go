@@ -0,0 +1,168 @@
+package drivers
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/dburl"
+)
+
+// ColumnKind is the kind of the values of a column, inferred from a sample of
+// the values.
+type ColumnKind string
+
+// Column kinds.
+const (
+	// KindInt is an integer column.
+	KindInt ColumnKind = "int"
+	// KindFloat is a floating point column.
+	KindFloat ColumnKind = "float"
+	// KindBool is a boolean column.
+	KindBool ColumnKind = "bool"
+	// KindDate is a date column.
+	KindDate ColumnKind = "date"
+	// KindTimestamp is a date and time column.
+	KindTimestamp ColumnKind = "timestamp"
+	// KindText is a text column.
+	KindText ColumnKind = "text"
+)
+
+// timestampLayouts are the layouts of timestamp values.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// InferColumns infers the names and kinds of the columns of the data read
+// from r, sampling up to n records. When the options have a header, the
+// column names are read from the header, otherwise the columns are named
+// column1, column2, and so on. Null values do not affect the inferred kinds,
+// and columns with only null values are text.
+func InferColumns(r io.Reader, opts CopyInOptions, n int) ([]string, []ColumnKind, error) {
+	header := opts.Header
+	opts.Header = false
+	next := NewRecordReader(r, opts)
+	var names []string
+	if header {
+		values, err := next()
+		if err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		for _, v := range values {
+			name, _ := v.(string)
+			names = append(names, name)
+		}
+	}
+	var kinds []ColumnKind
+	for i := 0; i < n; i++ {
+		values, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for len(kinds) < len(values) {
+			kinds = append(kinds, "")
+		}
+		for j, v := range values {
+			if s, ok := v.(string); ok {
+				kinds[j] = mergeKinds(kinds[j], kindOf(s))
+			}
+		}
+	}
+	for len(kinds) < len(names) {
+		kinds = append(kinds, "")
+	}
+	for i := range kinds {
+		if kinds[i] == "" {
+			kinds[i] = KindText
+		}
+		if i >= len(names) {
+			names = append(names, "")
+		}
+		if names[i] == "" {
+			names[i] = "column" + strconv.Itoa(i+1)
+		}
+	}
+	return names, kinds, nil
+}
+
+// kindOf returns the kind of the value.
+func kindOf(s string) ColumnKind {
+	s = strings.TrimSpace(s)
+	// keep leading zeros, such as of postal codes
+	if len(s) > 1 && s[0] == '0' && s[1] != '.' {
+		return KindText
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return KindInt
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "nNiIxX") {
+		return KindFloat
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "t", "f", "yes", "no":
+		return KindBool
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return KindDate
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return KindTimestamp
+		}
+	}
+	return KindText
+}
+
+// mergeKinds returns the kind of a column containing values of kinds a and b.
+func mergeKinds(a, b ColumnKind) ColumnKind {
+	switch {
+	case a == "", a == b:
+		return b
+	case a == KindInt && b == KindFloat, a == KindFloat && b == KindInt:
+		return KindFloat
+	case a == KindDate && b == KindTimestamp, a == KindTimestamp && b == KindDate:
+		return KindTimestamp
+	}
+	return KindText
+}
+
+// ColumnType returns the column type for the column kind for a driver.
+func ColumnType(u *dburl.URL, kind ColumnKind) string {
+	if d, ok := drivers[u.Driver]; ok && d.ColumnType != nil {
+		if typ := d.ColumnType(kind); typ != "" {
+			return typ
+		}
+	}
+	switch kind {
+	case KindInt:
+		return "BIGINT"
+	case KindFloat:
+		return "DOUBLE PRECISION"
+	case KindBool:
+		return "BOOLEAN"
+	case KindDate:
+		return "DATE"
+	case KindTimestamp:
+		return "TIMESTAMP"
+	}
+	return "TEXT"
+}
+
+// CreateTable returns the statement creating the table with the named columns
+// of the kinds for a driver.
+func CreateTable(u *dburl.URL, table string, names []string, kinds []ColumnKind) string {
+	cols := make([]string, len(names))
+	for i, name := range names {
+		cols[i] = "  " + QuoteIdentifier(u, name) + " " + ColumnType(u, kinds[i])
+	}
+	return "CREATE TABLE " + table + " (\n" + strings.Join(cols, ",\n") + "\n)"
+}
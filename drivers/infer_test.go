@@ -0,0 +1,147 @@
+package drivers_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+)
+
+func TestInferColumns(t *testing.T) {
+	tests := []struct {
+		data   string
+		opts   drivers.CopyInOptions
+		names  []string
+		kinds  []drivers.ColumnKind
+		sample int
+	}{
+		{
+			"a,b,c,d,e,f\n1,1.5,true,2024-01-02,2024-01-02 03:04:05,x\n",
+			drivers.CopyInOptions{Header: true},
+			[]string{"a", "b", "c", "d", "e", "f"},
+			[]drivers.ColumnKind{drivers.KindInt, drivers.KindFloat, drivers.KindBool, drivers.KindDate, drivers.KindTimestamp, drivers.KindText},
+			10,
+		},
+		// mixed ints and floats are floats
+		{
+			"1,2\n2.5,3\n-3,4\n",
+			drivers.CopyInOptions{},
+			[]string{"column1", "column2"},
+			[]drivers.ColumnKind{drivers.KindFloat, drivers.KindInt},
+			10,
+		},
+		// mixed dates and timestamps are timestamps
+		{
+			"2024-01-02,2024-01-02,2024-01-02\n2024-01-02T03:04:05Z,2024-01-03,x\n",
+			drivers.CopyInOptions{},
+			[]string{"column1", "column2", "column3"},
+			[]drivers.ColumnKind{drivers.KindTimestamp, drivers.KindDate, drivers.KindText},
+			10,
+		},
+		// empty cells are null, and do not affect the kinds
+		{
+			"a,b,c\n,,\n1,,x\n,2024-01-02,\n",
+			drivers.CopyInOptions{Header: true},
+			[]string{"a", "b", "c"},
+			[]drivers.ColumnKind{drivers.KindInt, drivers.KindDate, drivers.KindText},
+			10,
+		},
+		// columns with only nulls are text
+		{
+			"a,b\n1,\n2,\n",
+			drivers.CopyInOptions{Header: true},
+			[]string{"a", "b"},
+			[]drivers.ColumnKind{drivers.KindInt, drivers.KindText},
+			10,
+		},
+		// \N is null in the text format
+		{
+			"a\tb\n\\N\t1.5\n7\t\\N\n",
+			drivers.CopyInOptions{Format: "text", Header: true},
+			[]string{"a", "b"},
+			[]drivers.ColumnKind{drivers.KindInt, drivers.KindFloat},
+			10,
+		},
+		// leading zeros, numbers with letters, and mixed kinds are text
+		{
+			"00123,1e3,NaN,true\n00456,2,Inf,1\n",
+			drivers.CopyInOptions{},
+			[]string{"column1", "column2", "column3", "column4"},
+			[]drivers.ColumnKind{drivers.KindText, drivers.KindFloat, drivers.KindText, drivers.KindText},
+			10,
+		},
+		// only the sample is read
+		{
+			"a\n1\n2\nx\n",
+			drivers.CopyInOptions{Header: true},
+			[]string{"a"},
+			[]drivers.ColumnKind{drivers.KindInt},
+			2,
+		},
+		// unnamed and missing header columns
+		{
+			"a\t\n1\t2\t3\n",
+			drivers.CopyInOptions{Format: "text", Header: true},
+			[]string{"a", "column2", "column3"},
+			[]drivers.ColumnKind{drivers.KindInt, drivers.KindInt, drivers.KindInt},
+			10,
+		},
+		{
+			"",
+			drivers.CopyInOptions{Header: true},
+			nil,
+			nil,
+			10,
+		},
+	}
+	for i, test := range tests {
+		names, kinds, err := drivers.InferColumns(strings.NewReader(test.data), test.opts, test.sample)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("test %d expected names %q, got: %q", i, test.names, names)
+		}
+		if !reflect.DeepEqual(kinds, test.kinds) {
+			t.Errorf("test %d expected kinds %q, got: %q", i, test.kinds, kinds)
+		}
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	names := []string{"id", "price", "ok", "day", "at", "note"}
+	kinds := []drivers.ColumnKind{drivers.KindInt, drivers.KindFloat, drivers.KindBool, drivers.KindDate, drivers.KindTimestamp, drivers.KindText}
+	tests := []struct {
+		urlstr string
+		exp    string
+	}{
+		{"pg://", `CREATE TABLE t (
+  "id" BIGINT,
+  "price" DOUBLE PRECISION,
+  "ok" BOOLEAN,
+  "day" DATE,
+  "at" TIMESTAMP,
+  "note" TEXT
+)`},
+		{"my://", "CREATE TABLE t (\n  `id` BIGINT,\n  `price` DOUBLE,\n  `ok` BOOLEAN,\n  `day` DATE,\n  `at` DATETIME(6),\n  `note` TEXT\n)"},
+		{"ms://", `CREATE TABLE t (
+  [id] BIGINT,
+  [price] FLOAT,
+  [ok] BIT,
+  [day] DATE,
+  [at] DATETIME2,
+  [note] NVARCHAR(MAX)
+)`},
+	}
+	for i, test := range tests {
+		u, err := dburl.Parse(test.urlstr)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := drivers.CreateTable(u, "t", names, kinds); s != test.exp {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, test.exp, s)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 func init() {
 	drivers.Register("ingres", drivers.Driver{
 		NewMetadataReader: NewIngresReader,
+		Limit:             drivers.LimitSelect("FIRST %d", false),
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...md.ReaderOption) md.Writer {
 			return NewIngresWriter(NewIngresReader(db, opts...))(db, w)
 		},
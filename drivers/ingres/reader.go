@@ -2,7 +2,12 @@ package ingres
 
 import (
 	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ildus/usql/drivers"
@@ -20,6 +25,134 @@ func NewIngresReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Read
 	}
 }
 
+// Catalog is a database, as listed by iidatabase_info.
+type Catalog struct {
+	metadata.Catalog
+	Owner     string
+	Location  string
+	Collation string
+	Access    string
+	// WorkLocation, CheckpointLocation, JournalLocation, and DumpLocation are
+	// the names of the other locations of the database.
+	WorkLocation       string
+	CheckpointLocation string
+	JournalLocation    string
+	DumpLocation       string
+	// ExtendedLocations are the names of the locations the database is
+	// extended to, from iiextend.
+	ExtendedLocations string
+}
+
+func (s Catalog) Values() []interface{} {
+	return []interface{}{
+		s.Catalog.Catalog, s.Owner, s.Location, s.Collation, s.Access,
+		s.WorkLocation, s.CheckpointLocation, s.JournalLocation, s.DumpLocation, s.ExtendedLocations,
+	}
+}
+
+func (s Catalog) GetCatalog() metadata.Catalog {
+	return s.Catalog
+}
+
+var catalogsColumnName = []string{
+	"Catalog", "Owner", "Location", "Collation", "Access",
+	"Work location", "Checkpoint location", "Journal location", "Dump location", "Extended locations",
+}
+
+// Catalogs lists the databases of the installation from iidatabase_info,
+// which is only available when connected to the iidbdb database. Otherwise,
+// only the current database is listed, using dbmsinfo.
+func (r MetadataReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	qstr := `SELECT
+  database_name,
+  database_owner,
+  data_location,
+  (case when database_name = dbmsinfo('database') then dbmsinfo('collation') else '' end),
+  (case when mod(access, 2) = 1 then 'global' else 'private' end),
+  work_location,
+  ckpt_location,
+  jnl_location,
+  dump_location
+FROM iidatabase_info`
+	var conds []string
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, strings.ReplaceAll(f.Name, "*", "%"))
+		conds = append(conds, "database_name LIKE ~V")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "database_name", vals...)
+	if err != nil {
+		qstr = `SELECT
+  dbmsinfo('database'),
+  dbmsinfo('dba'),
+  '',
+  dbmsinfo('collation'),
+  '',
+  '',
+  '',
+  '',
+  ''`
+		if rows, closeRows, err = r.query(qstr, nil, ""); err != nil {
+			return nil, err
+		}
+	}
+	defer closeRows()
+
+	var results []metadata.Result
+	for rows.Next() {
+		rec := Catalog{}
+		err = rows.Scan(
+			&rec.Catalog.Catalog, &rec.Owner, &rec.Location, &rec.Collation, &rec.Access,
+			&rec.WorkLocation, &rec.CheckpointLocation, &rec.JournalLocation, &rec.DumpLocation,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rec.Catalog.Catalog = strings.TrimSpace(rec.Catalog.Catalog)
+		rec.Owner = strings.TrimSpace(rec.Owner)
+		rec.Location = strings.TrimSpace(rec.Location)
+		rec.WorkLocation = strings.TrimSpace(rec.WorkLocation)
+		rec.CheckpointLocation = strings.TrimSpace(rec.CheckpointLocation)
+		rec.JournalLocation = strings.TrimSpace(rec.JournalLocation)
+		rec.DumpLocation = strings.TrimSpace(rec.DumpLocation)
+		results = append(results, &rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	// extended locations are only listed when connected to iidbdb
+	if extended, err := r.extendedLocations(); err == nil {
+		for _, res := range results {
+			rec := res.(*Catalog)
+			rec.ExtendedLocations = strings.Join(extended[rec.Catalog.Catalog], ", ")
+		}
+	}
+	return metadata.NewCatalogSetWithColumns(results, catalogsColumnName), nil
+}
+
+// extendedLocations returns the names of the locations each database is
+// extended to, from iiextend.
+func (r MetadataReader) extendedLocations() (map[string][]string, error) {
+	rows, closeRows, err := r.query(`SELECT
+  dname,
+  lname
+FROM iiextend`, nil, "dname, lname")
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	extended := map[string][]string{}
+	for rows.Next() {
+		var db, loc string
+		if err := rows.Scan(&db, &loc); err != nil {
+			return nil, err
+		}
+		db = strings.TrimSpace(db)
+		extended[db] = append(extended[db], strings.TrimSpace(loc))
+	}
+	return extended, rows.Err()
+}
+
 func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
 	qstr := `SELECT
   table_name AS Name,
@@ -177,6 +310,218 @@ FROM
 	return metadata.NewColumnSet(results), nil
 }
 
+func (r MetadataReader) ColumnStats(f metadata.Filter) (*metadata.ColumnStatSet, error) {
+	qstr := `SELECT
+  trim(s.table_owner),
+  trim(s.table_name),
+  trim(s.column_name),
+  c.column_internal_length,
+  s.pct_nulls,
+  s.num_unique,
+  s.num_cells,
+  s.hist_data_length,
+  lowercase(trim(c.column_datatype))
+FROM iistats s
+JOIN iicolumns c
+ON c.table_owner = s.table_owner AND c.table_name = s.table_name AND c.column_name = s.column_name`
+	var conds []string
+	var vals []interface{}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "s.table_owner = ~V ")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "s.table_name = ~V ")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "s.column_name = ~V ")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "c.column_sequence", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.ColumnStat
+	var hists []histogram
+	for rows.Next() {
+		var rec metadata.ColumnStat
+		var h histogram
+		var numUnique float64
+		if err := rows.Scan(
+			&rec.Schema,
+			&rec.Table,
+			&rec.Name,
+			&rec.AvgWidth,
+			&rec.NullFrac,
+			&numUnique,
+			&h.cells,
+			&h.width,
+			&h.typ,
+		); err != nil {
+			return nil, err
+		}
+		rec.NumDistinct = int64(numUnique)
+		results = append(results, rec)
+		hists = append(hists, h)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	closeRows()
+	if !contains(f.Types, "extended") {
+		return metadata.NewColumnStatSet(results), nil
+	}
+	// retrieve the histograms after the stats have been read, as the
+	// histogram cells are only needed for the extended stats
+	for i := range results {
+		rec := &results[i]
+		buf, err := r.histogramData(rec.Schema, rec.Table, rec.Name)
+		if err != nil {
+			return nil, err
+		}
+		vals, counts := hists[i].decode(buf)
+		if len(vals) == 0 {
+			continue
+		}
+		rec.Min, rec.Max = vals[0], vals[len(vals)-1]
+		// the histogram only records the fraction of rows of each cell, so
+		// the most common values are reported as the bounds of the cells
+		// containing the most rows
+		for _, j := range histogramTopN(counts) {
+			rec.TopN = append(rec.TopN, histogramBucket(vals, j))
+			rec.TopNFreqs = append(rec.TopNFreqs, counts[j])
+		}
+	}
+	return metadata.NewColumnStatSet(results), nil
+}
+
+// histogramData retrieves the encoded histogram of a column created by
+// optimizedb.
+func (r MetadataReader) histogramData(owner, table, column string) ([]byte, error) {
+	qstr := `SELECT text_segment FROM iihistograms`
+	conds := []string{"table_owner = ~V ", "table_name = ~V ", "column_name = ~V "}
+	rows, closeRows, err := r.query(qstr, conds, "text_sequence", owner, table, column)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var buf []byte
+	for rows.Next() {
+		var seg []byte
+		if err := rows.Scan(&seg); err != nil {
+			return nil, err
+		}
+		buf = append(buf, seg...)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return buf, nil
+}
+
+// columnStatsTopN is the maximum number of histogram cells retrieved by
+// ColumnStats as the most common values.
+const columnStatsTopN = 10
+
+// histogram describes the layout of an Ingres column histogram.
+type histogram struct {
+	cells int
+	width int
+	typ   string
+}
+
+// decode decodes the histogram cells from buf, returning the upper boundary
+// value of each cell and the fraction of rows it contains.
+//
+// The histogram is stored as the cell boundary values, each width bytes in
+// the column's internal format, followed by the cell counts as float4
+// values. The first cell only holds the lower bound of the column's values,
+// and contains no rows, while each following cell contains the rows with
+// values greater than the previous boundary, up to its own boundary. Any
+// data following the cell counts, such as the repetition factors, is
+// ignored.
+//
+// The histogram is written in the byte order of the server, which is
+// assumed to be little endian: histograms created on big endian servers are
+// decoded incorrectly.
+func (h histogram) decode(buf []byte) ([]string, []float64) {
+	if h.cells == 0 || len(buf) < h.cells*(h.width+4) {
+		return nil, nil
+	}
+	vals, counts := make([]string, h.cells), make([]float64, h.cells)
+	for i := 0; i < h.cells; i++ {
+		vals[i] = h.value(buf[i*h.width : (i+1)*h.width])
+		off := h.cells*h.width + i*4
+		counts[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[off : off+4])))
+	}
+	return vals, counts
+}
+
+// histogramTopN returns the positions of the cells containing the most rows,
+// up to columnStatsTopN cells.
+func histogramTopN(counts []float64) []int {
+	idx := make([]int, 0, len(counts))
+	for j, c := range counts {
+		if c > 0 {
+			idx = append(idx, j)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return counts[idx[a]] > counts[idx[b]]
+	})
+	if len(idx) > columnStatsTopN {
+		idx = idx[:columnStatsTopN]
+	}
+	return idx
+}
+
+// histogramBucket returns the range of values of cell i of a histogram with
+// the boundary values vals.
+func histogramBucket(vals []string, i int) string {
+	if i == 0 {
+		return "<=" + vals[0]
+	}
+	return "(" + vals[i-1] + ".." + vals[i] + "]"
+}
+
+// value decodes a histogram boundary value.
+func (h histogram) value(b []byte) string {
+	switch {
+	case strings.HasSuffix(h.typ, "int") || h.typ == "integer":
+		switch len(b) {
+		case 1:
+			return strconv.FormatInt(int64(int8(b[0])), 10)
+		case 2:
+			return strconv.FormatInt(int64(int16(binary.LittleEndian.Uint16(b))), 10)
+		case 4:
+			return strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(b))), 10)
+		case 8:
+			return strconv.FormatInt(int64(binary.LittleEndian.Uint64(b)), 10)
+		}
+	case h.typ == "float" || h.typ == "real" || h.typ == "double precision":
+		switch len(b) {
+		case 4:
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 'g', -1, 32)
+		case 8:
+			return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 'g', -1, 64)
+		}
+	case strings.Contains(h.typ, "char") || h.typ == "c" || h.typ == "text":
+		return strings.TrimRight(string(b), "\x00 ")
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
 	qstr := `SELECT schema_name FROM iischema`
 
@@ -205,9 +550,14 @@ func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error)
 	return metadata.NewSchemaSet(results), nil
 }
 
+// Functions lists the database procedures, with their text. The text of a
+// procedure is stored in segments, one row per segment.
 func (r MetadataReader) Functions(f metadata.Filter) (*metadata.FunctionSet, error) {
 	qstr := `SELECT
-  procedure_name AS name
+  procedure_name AS name,
+  procedure_owner,
+  text_sequence,
+  text_segment
 FROM
   iiprocedures`
 	var conds []string
@@ -226,19 +576,32 @@ FROM
 			conds = append(conds, "proc_subtype IN ("+strings.Join(pholders, ", ")+")")
 		}
 	}
-	rows, closeRows, err := r.query(qstr, conds, "procedure_name", vals...)
+	rows, closeRows, err := r.query(qstr, conds, "procedure_name, procedure_owner, text_sequence", vals...)
 	if err != nil {
 		return nil, err
 	}
 	defer closeRows()
 	var results []metadata.Function
+	var owner string
 	for rows.Next() {
 		var rec metadata.Function
+		var procOwner, segment string
+		var seq int64
 		if err := rows.Scan(
 			&rec.Name,
+			&procOwner,
+			&seq,
+			&segment,
 		); err != nil {
 			return nil, err
 		}
+		rec.Name, procOwner = strings.TrimSpace(rec.Name), strings.TrimSpace(procOwner)
+		// append the segment to the text of the previous segments
+		if n := len(results); n != 0 && results[n-1].Name == rec.Name && owner == procOwner {
+			results[n-1].Source += segment
+			continue
+		}
+		rec.Source, owner = segment, procOwner
 		results = append(results, rec)
 	}
 	if rows.Err() != nil {
@@ -294,10 +657,13 @@ func (r MetadataReader) IndexColumns(f metadata.Filter) (*metadata.IndexColumnSe
   rm.relid as Table,
   ri.relid as IndexName,
   attname as Name,
-  uppercase(iitypename(ii_ext_type(a.attfrmt, a.attfrml))) as DataType
+  uppercase(iitypename(ii_ext_type(a.attfrmt, a.attfrml))) as DataType,
+  a.attkdom as KeyPosition,
+  case when ic.sort_direction = 'D' then 'DESC' else 'ASC' end as SortOrder
 FROM iiattribute a
 LEFT JOIN iirelation rm ON rm.reltid = attrelid and rm.reltidx = 0
-LEFT JOIN iirelation ri ON ri.reltid = attrelid and ri.reltidx = attrelidx`
+LEFT JOIN iirelation ri ON ri.reltid = attrelid and ri.reltidx = attrelidx
+LEFT JOIN iiindex_columns ic ON ic.index_name = ri.relid and ic.index_owner = ri.relowner and ic.column_name = a.attname`
 	vals := []interface{}{f.Parent}
 	conds := []string{"varchar(rm.relid) = ~V "}
 
@@ -319,9 +685,14 @@ LEFT JOIN iirelation ri ON ri.reltid = attrelid and ri.reltidx = attrelidx`
 			&rec.IndexName,
 			&rec.Name,
 			&rec.DataType,
+			&rec.KeyPosition,
+			&rec.SortOrder,
 		); err != nil {
 			return nil, err
 		}
+		if rec.KeyPosition == 0 {
+			rec.SortOrder = ""
+		}
 		results = append(results, rec)
 	}
 	if rows.Err() != nil {
@@ -435,6 +806,111 @@ FROM iisequences
 	return metadata.NewSequenceSet(results), nil
 }
 
+// Roles lists the users (iiusers) and roles (iirole), with the roles granted
+// to them (iirolegrants). Users and roles with the security privilege are
+// superusers.
+func (r MetadataReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	qstr := `SELECT
+  user_name,
+  'YES',
+  security_admin,
+  create_database,
+  maintain_users,
+  operator,
+  auditor,
+  varchar(expire_date)
+FROM iiusers`
+	var vals []interface{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		qstr += "\nWHERE user_name LIKE ~V"
+	}
+	qstr += `
+UNION ALL
+SELECT
+  role_name,
+  'NO',
+  security_admin,
+  create_database,
+  maintain_users,
+  operator,
+  auditor,
+  varchar('')
+FROM iirole`
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		qstr += "\nWHERE role_name LIKE ~V"
+	}
+	rows, closeRows, err := r.query(qstr, nil, "1", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Role
+	for rows.Next() {
+		var rec metadata.Role
+		var security, createDB, maintainUsers, operator, auditor string
+		if err := rows.Scan(&rec.Name, &rec.Login, &security, &createDB, &maintainUsers, &operator, &auditor, &rec.ValidUntil); err != nil {
+			return nil, err
+		}
+		rec.Name, rec.ValidUntil = strings.TrimSpace(rec.Name), strings.TrimSpace(rec.ValidUntil)
+		rec.Superuser = metadata.NO
+		if security == "Y" {
+			rec.Superuser = metadata.YES
+		}
+		var attrs []string
+		for _, a := range []struct{ v, name string }{
+			{createDB, "Create DB"},
+			{maintainUsers, "Maintain users"},
+			{operator, "Operator"},
+			{auditor, "Auditor"},
+		} {
+			if a.v == "Y" {
+				attrs = append(attrs, a.name)
+			}
+		}
+		rec.Attributes = strings.Join(attrs, ", ")
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	members, err := r.roleGrants()
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].MemberOf = strings.Join(members[results[i].Name], ", ")
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// roleGrants returns the roles granted to each user and role.
+func (r MetadataReader) roleGrants() (map[string][]string, error) {
+	rows, closeRows, err := r.query(`SELECT
+  grantee_name,
+  role_name,
+  admin_option
+FROM iirolegrants`, nil, "role_name")
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	members := map[string][]string{}
+	for rows.Next() {
+		var grantee, role, admin string
+		if err := rows.Scan(&grantee, &role, &admin); err != nil {
+			return nil, err
+		}
+		grantee, role = strings.TrimSpace(grantee), strings.TrimSpace(role)
+		if admin == "Y" {
+			role += " (admin)"
+		}
+		members[grantee] = append(members[grantee], role)
+	}
+	return members, rows.Err()
+}
+
 func (r MetadataReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
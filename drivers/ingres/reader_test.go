@@ -0,0 +1,80 @@
+package ingres
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestHistogramDecode(t *testing.T) {
+	// histograms encoded in the layout documented on histogram.decode
+	tests := []struct {
+		h      histogram
+		data   string
+		vals   []string
+		counts []float64
+		topN   []string
+	}{
+		// integer4 column with 5 cells, followed by the repetition factors
+		{
+			histogram{cells: 5, width: 4, typ: "integer"},
+			"000000000a000000140000001e00000028000000" +
+				"000000000000003e0000003f0000003e0000803e" +
+				"0000803f0000803f0000803f0000803f0000803f",
+			[]string{"0", "10", "20", "30", "40"},
+			[]float64{0, 0.125, 0.5, 0.125, 0.25},
+			[]string{"(10..20]", "(30..40]", "(0..10]", "(20..30]"},
+		},
+		// char(4) column with 4 cells
+		{
+			histogram{cells: 4, width: 4, typ: "char"},
+			"61202020642020206d2020207a7a2020" +
+				"000000000000c03e0000003f0000003e",
+			[]string{"a", "d", "m", "zz"},
+			[]float64{0, 0.375, 0.5, 0.125},
+			[]string{"(d..m]", "(a..d]", "(m..zz]"},
+		},
+		// truncated data
+		{
+			histogram{cells: 4, width: 4, typ: "char"},
+			"61202020642020206d2020207a7a2020",
+			nil, nil, nil,
+		},
+	}
+	for i, test := range tests {
+		buf, err := hex.DecodeString(test.data)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		vals, counts := test.h.decode(buf)
+		if !reflect.DeepEqual(vals, test.vals) {
+			t.Errorf("test %d expected values %q, got: %q", i, test.vals, vals)
+		}
+		if !reflect.DeepEqual(counts, test.counts) {
+			t.Errorf("test %d expected counts %v, got: %v", i, test.counts, counts)
+		}
+		var topN []string
+		for _, j := range histogramTopN(counts) {
+			topN = append(topN, histogramBucket(vals, j))
+		}
+		if !reflect.DeepEqual(topN, test.topN) {
+			t.Errorf("test %d expected top n %q, got: %q", i, test.topN, topN)
+		}
+	}
+}
+
+func TestHistogramTopN(t *testing.T) {
+	counts := make([]float64, 2*columnStatsTopN)
+	for i := range counts {
+		counts[i] = float64(i)
+	}
+	idx := histogramTopN(counts)
+	if len(idx) != columnStatsTopN {
+		t.Fatalf("expected %d cells, got: %d", columnStatsTopN, len(idx))
+	}
+	for i, j := range idx {
+		if exp := len(counts) - 1 - i; j != exp {
+			t.Errorf("test %d expected cell %d, got: %d", i, exp, j)
+		}
+	}
+}
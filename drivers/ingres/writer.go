@@ -4,6 +4,8 @@ import (
 	"fmt"
 	md "github.com/ildus/usql/drivers/metadata"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/xo/tblfmt"
@@ -354,7 +356,7 @@ func (w IngresWriter) describeTableIndexes(out io.Writer, sp, tp string) error {
 		if i.IsUnique == md.YES {
 			unique = "UNIQUE, "
 		}
-		i.Columns, err = w.getIndexColumns(i.Catalog, i.Schema, i.Table, i.Name)
+		i.Columns, err = w.getIndexColumns(i.Catalog, i.Schema, i.Table, i.Name, i.Type)
 		if err != nil {
 			return fmt.Errorf("failed to get columns of index %s: %w", i.Name, err)
 		}
@@ -363,19 +365,54 @@ func (w IngresWriter) describeTableIndexes(out io.Writer, sp, tp string) error {
 	return nil
 }
 
-func (w IngresWriter) getIndexColumns(c, s, t, i string) (string, error) {
+// getIndexColumns returns the key columns of the index in key order, with
+// their sort order when the storage structure is ordered.
+func (w IngresWriter) getIndexColumns(c, s, t, i, structure string) (string, error) {
 	r := w.r.(md.IndexColumnReader)
 	cols, err := r.IndexColumns(md.Filter{Catalog: c, Schema: s, Parent: t, Name: i})
 	if err != nil {
 		return "", err
 	}
-	result := []string{}
+	var all, keys []*md.IndexColumn
 	for cols.Next() {
-		result = append(result, cols.Get().Name)
+		col := cols.Get()
+		if col.KeyPosition != 0 {
+			keys = append(keys, col)
+		}
+		all = append(all, col)
+	}
+	if len(keys) == 0 {
+		// heap structures have no key
+		keys = all
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].KeyPosition < keys[j].KeyPosition
+	})
+	result := []string{}
+	for _, col := range keys {
+		name := col.Name
+		if order := sortOrder(structure, col); order == "DESC" {
+			name += " " + order
+		}
+		result = append(result, name)
 	}
 	return strings.Join(result, ", "), nil
 }
 
+// sortOrder returns the sort order of the index column for the storage
+// structure. HASH structures are unordered, and ISAM structures are always
+// in ascending order.
+func sortOrder(structure string, col *md.IndexColumn) string {
+	structure = strings.ToUpper(strings.TrimSpace(structure))
+	switch {
+	case col.KeyPosition == 0, strings.HasSuffix(structure, "HASH"):
+		return ""
+	case strings.HasSuffix(structure, "ISAM"):
+		return "ASC"
+	}
+	return col.SortOrder
+}
+
 func (w IngresWriter) describeTableConstraints(out io.Writer, filter md.Filter, postFilter func(r md.Result) bool, label string, printer func(io.Writer, *md.Constraint) error) error {
 	r, ok := w.r.(md.ConstraintReader)
 	if !ok {
@@ -461,10 +498,14 @@ func (w IngresWriter) describeIndex(i *md.Index) error {
 		return nil
 	}
 
-	res.SetColumns([]string{"Name", "Type"})
+	res.SetColumns([]string{"Name", "Type", "Key", "Order"})
 	res.SetScanValues(func(r md.Result) []interface{} {
 		f := r.(*md.IndexColumn)
-		return []interface{}{f.Name, f.DataType}
+		key := ""
+		if f.KeyPosition != 0 {
+			key = strconv.Itoa(f.KeyPosition)
+		}
+		return []interface{}{f.Name, f.DataType, key, sortOrder(i.Type, f)}
 	})
 
 	params := env.Pall()
@@ -494,6 +535,21 @@ func (w IngresWriter) ListAllDbs(u *dburl.URL, pattern string, verbose bool) err
 	}
 	defer res.Close()
 
+	columns := []string{"Name", "Owner", "Location"}
+	if verbose {
+		columns = append(columns, "Collation", "Access", "Work location", "Checkpoint location",
+			"Journal location", "Dump location", "Extended locations")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r md.Result) []interface{} {
+		c := r.(*Catalog)
+		v := []interface{}{c.Catalog.Catalog, c.Owner, c.Location}
+		if verbose {
+			v = append(v, c.Collation, c.Access, c.WorkLocation, c.CheckpointLocation,
+				c.JournalLocation, c.DumpLocation, c.ExtendedLocations)
+		}
+		return v
+	})
 	params := env.Pall()
 	params["title"] = "List of databases"
 	return tblfmt.EncodeAll(w.w, res, params)
@@ -664,7 +720,7 @@ func (w IngresWriter) ShowStats(u *dburl.URL, statTypes, pattern string, verbose
 	}
 	columns := []string{"Schema", "Table", "Name", "Average width", "Nulls fraction", "Distinct values", "Dist. fraction"}
 	if verbose {
-		columns = append(columns, "Minimum value", "Maximum value", "Mean value", "Top N common values", "Top N values freqs")
+		columns = append(columns, "Minimum value", "Maximum value", "Mean value", "Top N common value ranges", "Top N ranges freqs")
 	}
 	res.SetColumns(columns)
 	res.SetScanValues(func(r md.Result) []interface{} {
@@ -756,6 +812,118 @@ func (w IngresWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, showS
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
+// ListSpatialColumns matching pattern
+func (w IngresWriter) ListSpatialColumns(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dgs`, u.Driver)
+}
+
+// ListServerConfig matching pattern
+func (w IngresWriter) ListServerConfig(u *dburl.URL, pattern string, verbose bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dconfig`, u.Driver)
+}
+
+// ListActivity of sessions of users matching pattern
+func (w IngresWriter) ListActivity(u *dburl.URL, pattern string, verbose bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dactivity`, u.Driver)
+}
+
+// ListLocks on objects matching pattern
+func (w IngresWriter) ListLocks(u *dburl.URL, pattern string, verbose bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dlocks`, u.Driver)
+}
+
+// ListSizes of tables matching pattern
+func (w IngresWriter) ListSizes(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dsize`, u.Driver)
+}
+
+// ListForeignTables matching pattern
+func (w IngresWriter) ListForeignTables(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\det`, u.Driver)
+}
+
+// ListRoles matching pattern
+func (w IngresWriter) ListRoles(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(md.RoleReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\du`, u.Driver)
+	}
+	res, err := r.Roles(md.Filter{Name: strings.ReplaceAll(pattern, "*", "%"), WithSystem: showSystem})
+	if err != nil {
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer res.Close()
+
+	res.SetColumns([]string{"Role name", "Login", "Superuser", "Attributes", "Member of", "Valid until"})
+	res.SetScanValues(func(r md.Result) []interface{} {
+		role := r.(*md.Role)
+		return []interface{}{role.Name, role.Login, role.Superuser, role.Attributes, role.MemberOf, role.ValidUntil}
+	})
+	params := env.Pall()
+	params["title"] = "List of roles"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListTypes matching pattern
+func (w IngresWriter) ListTypes(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dT`, u.Driver)
+}
+
+// ListDictionaries matching pattern
+func (w IngresWriter) ListDictionaries(u *dburl.URL, pattern string, verbose bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\ddict`, u.Driver)
+}
+
+// ListIndexStats matching pattern
+func (w IngresWriter) ListIndexStats(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\dxstat`, u.Driver)
+}
+
+// ListQueryLog of the most recent queries
+func (w IngresWriter) ListQueryLog(u *dburl.URL, id string, n int, verbose bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\qlog`, u.Driver)
+}
+
+// DescribeDependencies of the object matching pattern
+func (w IngresWriter) DescribeDependencies(u *dburl.URL, pattern string, showSystem bool) error {
+	return fmt.Errorf(text.NotSupportedByDriver, `\ddep`, u.Driver)
+}
+
+// ShowFunctionSource writes the text of the database procedure, stored in
+// iiprocedures, with line numbers when numbered.
+func (w IngresWriter) ShowFunctionSource(u *dburl.URL, name string, numbered bool) error {
+	r, ok := w.r.(md.FunctionReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\sf`, u.Driver)
+	}
+	if i := strings.IndexRune(name, '('); i != -1 {
+		name = strings.TrimSpace(name[:i])
+	}
+	_, tp, err := parsePattern(name)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.Functions(md.Filter{Name: tp})
+	if err != nil {
+		return fmt.Errorf("failed to list functions: %w", err)
+	}
+	defer res.Close()
+	var f *md.Function
+	for res.Next() {
+		if f != nil {
+			return fmt.Errorf(text.FunctionNotUnique, name)
+		}
+		f = res.Get()
+	}
+	switch {
+	case f == nil:
+		return fmt.Errorf(text.FunctionNotFound, name)
+	case f.Source == "":
+		return fmt.Errorf(text.FunctionNoSource, name)
+	}
+	return md.WriteSource(w.w, f.Source, numbered)
+}
+
 func parsePattern(pattern string) (string, string, error) {
 	// TODO do proper escaping, quoting etc
 	if strings.ContainsRune(pattern, '.') {
@@ -0,0 +1,82 @@
+// Package kerberos provides shared Kerberos (GSSAPI) support for usql's
+// database drivers, using the user's credentials cache.
+package kerberos
+
+import (
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+)
+
+// ConfigPath returns the path to the Kerberos configuration file, as set by
+// the KRB5_CONFIG environment variable, defaulting to /etc/krb5.conf.
+func ConfigPath() string {
+	if s, ok := os.LookupEnv("KRB5_CONFIG"); ok && s != "" {
+		return s
+	}
+	return "/etc/krb5.conf"
+}
+
+// CCachePath returns the path to the user's credentials cache, as set by the
+// KRB5CCNAME environment variable, defaulting to /tmp/krb5cc_<uid>.
+func CCachePath() string {
+	if s, ok := os.LookupEnv("KRB5CCNAME"); ok && s != "" {
+		return strings.TrimPrefix(s, "FILE:")
+	}
+	uid := "0"
+	if u, err := user.Current(); err == nil {
+		uid = u.Uid
+	}
+	return "/tmp/krb5cc_" + uid
+}
+
+// LoadConfig loads the Kerberos configuration.
+func LoadConfig() (*config.Config, error) {
+	return config.Load(ConfigPath())
+}
+
+// Ticket holds information about a ticket in the credentials cache.
+type Ticket struct {
+	Server    string
+	StartTime time.Time
+	EndTime   time.Time
+	RenewTill time.Time
+}
+
+// Expired returns whether or not the ticket has expired.
+func (t Ticket) Expired() bool {
+	return time.Now().After(t.EndTime)
+}
+
+// Cache holds the state of the user's credentials cache.
+type Cache struct {
+	Path      string
+	Principal string
+	Tickets   []Ticket
+}
+
+// LoadCache loads the state of the user's credentials cache.
+func LoadCache() (*Cache, error) {
+	path := CCachePath()
+	cc, err := credentials.LoadCCache(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		Path:      path,
+		Principal: cc.DefaultPrincipal.PrincipalName.PrincipalNameString() + "@" + cc.DefaultPrincipal.Realm,
+	}
+	for _, cred := range cc.GetEntries() {
+		c.Tickets = append(c.Tickets, Ticket{
+			Server:    cred.Server.PrincipalName.PrincipalNameString() + "@" + cred.Server.Realm,
+			StartTime: cred.StartTime,
+			EndTime:   cred.EndTime,
+			RenewTill: cred.RenewTill,
+		})
+	}
+	return c, nil
+}
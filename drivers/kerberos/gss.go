@@ -0,0 +1,73 @@
+package kerberos
+
+import (
+	"fmt"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// GSS is a GSSAPI provider using the user's credentials cache, compatible
+// with the GSS interfaces of lib/pq and pgx.
+type GSS struct {
+	cl *client.Client
+}
+
+// NewGSS creates a GSSAPI provider, logging in with the credentials found in
+// the user's credentials cache (see kinit).
+func NewGSS() (*GSS, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kerberos config %s: %w", ConfigPath(), err)
+	}
+	cc, err := credentials.LoadCCache(CCachePath())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kerberos credentials cache %s: %w", CCachePath(), err)
+	}
+	cl, err := client.NewFromCCache(cc, cfg, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.Login(); err != nil {
+		return nil, err
+	}
+	return &GSS{cl: cl}, nil
+}
+
+// GetInitToken returns the initial token for the service on host.
+func (g *GSS) GetInitToken(host, service string) ([]byte, error) {
+	return g.GetInitTokenFromSpn(service + "/" + host)
+}
+
+// GetInitTokenFromSpn returns the initial token for the service principal
+// name.
+func (g *GSS) GetInitTokenFromSpn(spn string) ([]byte, error) {
+	tok, err := spnego.SPNEGOClient(g.cl, spn).InitSecContext()
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: unable to init security context: %w", err)
+	}
+	buf, err := tok.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: unable to marshal token: %w", err)
+	}
+	return buf, nil
+}
+
+// GetInitTokenFromSPN is the same as GetInitTokenFromSpn, satisfying pgx's
+// GSS interface.
+func (g *GSS) GetInitTokenFromSPN(spn string) ([]byte, error) {
+	return g.GetInitTokenFromSpn(spn)
+}
+
+// Continue handles the server's response token.
+func (g *GSS) Continue(inToken []byte) (bool, []byte, error) {
+	var tok spnego.SPNEGOToken
+	if err := tok.Unmarshal(inToken); err != nil {
+		return true, nil, fmt.Errorf("kerberos: unable to unmarshal token: %w", err)
+	}
+	if state := tok.NegTokenResp.State(); state != spnego.NegStateAcceptCompleted {
+		return true, nil, fmt.Errorf("kerberos: expected state completed, got %d", state)
+	}
+	return true, nil, nil
+}
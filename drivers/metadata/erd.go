@@ -0,0 +1,207 @@
+package metadata
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ildus/usql/text"
+)
+
+// erdTable is a table in an entity relationship diagram.
+type erdTable struct {
+	name    string
+	columns []*Column
+	// stub is set for tables outside of the diagram's schema, referenced by
+	// foreign keys, which are drawn without their columns.
+	stub bool
+}
+
+// erdRelation is a foreign key relationship in an entity relationship
+// diagram.
+type erdRelation struct {
+	table, foreignTable string
+	columns             string
+}
+
+// WriteERD writes an entity relationship diagram of the tables and foreign
+// key relationships in schema (or all schemas when empty), as read from r, to
+// w. Format is either dot (Graphviz) or mermaid. Tables in other schemas
+// referenced by foreign keys are drawn as stubs, qualified with their schema.
+func WriteERD(w io.Writer, r Reader, schema, format string) error {
+	tr, isTR := r.(TableReader)
+	cr, isCR := r.(ConstraintReader)
+	if !isTR || !isCR {
+		return text.ErrNotSupported
+	}
+	tables, err := erdTables(r, tr, schema)
+	if err != nil {
+		return err
+	}
+	res, err := cr.Constraints(Filter{Schema: schema})
+	if err != nil && err != text.ErrNotSupported {
+		return fmt.Errorf("failed to list constraints: %w", err)
+	}
+	var relations []erdRelation
+	if res != nil {
+		defer res.Close()
+		for res.Next() {
+			c := res.Get()
+			if c.Type != "FOREIGN KEY" {
+				continue
+			}
+			columns, err := erdConstraintColumns(r, c)
+			if err != nil {
+				return err
+			}
+			relations = append(relations, erdRelation{
+				table:        qualifiedName(schema, c.Schema, c.Table),
+				foreignTable: qualifiedName(schema, c.ForeignSchema, c.ForeignTable),
+				columns:      columns,
+			})
+		}
+	}
+	// add stubs for the tables referenced by relations, but not drawn
+	drawn := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		drawn[t.name] = true
+	}
+	for _, r := range relations {
+		for _, name := range []string{r.table, r.foreignTable} {
+			if !drawn[name] {
+				drawn[name] = true
+				tables = append(tables, &erdTable{name: name, stub: true})
+			}
+		}
+	}
+	switch format {
+	case "", "dot":
+		return writeDot(w, tables, relations)
+	case "mermaid":
+		return writeMermaid(w, tables, relations)
+	}
+	return fmt.Errorf(text.ERDInvalidFormat, format)
+}
+
+// erdTables returns the tables in schema, along with their columns when r is
+// a ColumnReader.
+func erdTables(r Reader, tr TableReader, schema string) ([]*erdTable, error) {
+	res, err := tr.Tables(Filter{Schema: schema, Types: []string{"BASE TABLE", "TABLE"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer res.Close()
+	var tables []*erdTable
+	m := make(map[string]*erdTable)
+	for res.Next() {
+		t := res.Get()
+		table := &erdTable{name: qualifiedName(schema, t.Schema, t.Name)}
+		tables, m[table.name] = append(tables, table), table
+	}
+	cr, ok := r.(ColumnReader)
+	if !ok {
+		return tables, nil
+	}
+	cols, err := cr.Columns(Filter{Schema: schema})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+	defer cols.Close()
+	for cols.Next() {
+		c := cols.Get()
+		if table, ok := m[qualifiedName(schema, c.Schema, c.Table)]; ok {
+			table.columns = append(table.columns, c)
+		}
+	}
+	return tables, nil
+}
+
+// erdConstraintColumns returns the columns of the foreign key constraint, when
+// r is a ConstraintColumnReader.
+func erdConstraintColumns(r Reader, c *Constraint) (string, error) {
+	ccr, ok := r.(ConstraintColumnReader)
+	if !ok {
+		return "", nil
+	}
+	res, err := ccr.ConstraintColumns(Filter{Catalog: c.Catalog, Schema: c.Schema, Parent: c.Table, Name: c.Name})
+	if err != nil {
+		return "", fmt.Errorf("failed to get columns of constraint %s: %w", c.Name, err)
+	}
+	defer res.Close()
+	var columns []string
+	for res.Next() {
+		columns = append(columns, res.Get().Name)
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// qualifiedName returns the name of a table, qualified with its schema unless
+// it is the schema filtered by.
+func qualifiedName(filter, schema, name string) string {
+	if schema == "" || schema == filter {
+		return name
+	}
+	return schema + "." + name
+}
+
+// writeDot writes the tables and relations as a Graphviz DOT digraph.
+func writeDot(w io.Writer, tables []*erdTable, relations []erdRelation) error {
+	var sb strings.Builder
+	sb.WriteString("digraph erd {\n  rankdir=LR;\n  node [shape=plaintext];\n")
+	for _, t := range tables {
+		fmt.Fprintf(&sb, `  %s [label=<<table border="0" cellborder="1" cellspacing="0">`, dotQuote(t.name))
+		if t.stub {
+			fmt.Fprintf(&sb, `<tr><td><i>%s</i></td></tr>`, html.EscapeString(t.name))
+		} else {
+			fmt.Fprintf(&sb, `<tr><td bgcolor="lightgrey"><b>%s</b></td></tr>`, html.EscapeString(t.name))
+		}
+		for _, c := range t.columns {
+			fmt.Fprintf(&sb, `<tr><td align="left">%s %s</td></tr>`, html.EscapeString(c.Name), html.EscapeString(c.DataType))
+		}
+		sb.WriteString("</table>>];\n")
+	}
+	for _, r := range relations {
+		fmt.Fprintf(&sb, "  %s -> %s [label=%s];\n", dotQuote(r.table), dotQuote(r.foreignTable), dotQuote(r.columns))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// writeMermaid writes the tables and relations as a Mermaid entity
+// relationship diagram.
+func writeMermaid(w io.Writer, tables []*erdTable, relations []erdRelation) error {
+	var sb strings.Builder
+	sb.WriteString("erDiagram\n")
+	for _, t := range tables {
+		fmt.Fprintf(&sb, "  %s {\n", mermaidName(t.name))
+		for _, c := range t.columns {
+			fmt.Fprintf(&sb, "    %s %s\n", mermaidName(c.DataType), mermaidName(c.Name))
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, r := range relations {
+		fmt.Fprintf(&sb, "  %s }o--|| %s : %q\n", mermaidName(r.table), mermaidName(r.foreignTable), strings.ReplaceAll(r.columns, `"`, ""))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaidRE matches runs of characters not allowed in Mermaid entity names,
+// attribute names, and attribute types.
+var mermaidRE = regexp.MustCompile(`[^A-Za-z0-9_\-()\[\]]+`)
+
+// mermaidName replaces the characters not allowed in Mermaid names in s.
+func mermaidName(s string) string {
+	if s = mermaidRE.ReplaceAllString(s, "_"); s == "" {
+		return "_"
+	}
+	return s
+}
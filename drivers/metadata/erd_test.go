@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+// erdReader is a reader returning fixed tables, columns, and constraints.
+type erdReader struct{}
+
+func (erdReader) Tables(Filter) (*TableSet, error) {
+	return NewTableSet([]Table{
+		{Schema: "public", Name: "authors", Type: "BASE TABLE"},
+		{Schema: "public", Name: "books", Type: "BASE TABLE"},
+	}), nil
+}
+
+func (erdReader) Columns(Filter) (*ColumnSet, error) {
+	return NewColumnSet([]Column{
+		{Schema: "public", Table: "authors", Name: "author_id", DataType: "integer"},
+		{Schema: "public", Table: "authors", Name: "name", DataType: "character varying(255)"},
+		{Schema: "public", Table: "books", Name: "book_id", DataType: "integer"},
+		{Schema: "public", Table: "books", Name: "author_id", DataType: "integer"},
+	}), nil
+}
+
+func (erdReader) Constraints(Filter) (*ConstraintSet, error) {
+	return NewConstraintSet([]Constraint{
+		{Schema: "public", Table: "authors", Name: "authors_pkey", Type: "PRIMARY KEY"},
+		{Schema: "public", Table: "books", Name: "books_author_id_fkey", Type: "FOREIGN KEY", ForeignSchema: "public", ForeignTable: "authors"},
+		{Schema: "public", Table: "books", Name: "books_editor_id_fkey", Type: "FOREIGN KEY", ForeignSchema: "audit", ForeignTable: "users"},
+	}), nil
+}
+
+func (erdReader) ConstraintColumns(f Filter) (*ConstraintColumnSet, error) {
+	return NewConstraintColumnSet([]ConstraintColumn{
+		{Schema: "public", Table: f.Parent, Constraint: f.Name, Name: "author_id", ForeignName: "author_id"},
+	}), nil
+}
+
+func TestWriteERD(t *testing.T) {
+	tests := []struct {
+		schema string
+		format string
+		exp    string
+	}{
+		{"public", "", `digraph erd {
+  rankdir=LR;
+  node [shape=plaintext];
+  "authors" [label=<<table border="0" cellborder="1" cellspacing="0"><tr><td bgcolor="lightgrey"><b>authors</b></td></tr><tr><td align="left">author_id integer</td></tr><tr><td align="left">name character varying(255)</td></tr></table>>];
+  "books" [label=<<table border="0" cellborder="1" cellspacing="0"><tr><td bgcolor="lightgrey"><b>books</b></td></tr><tr><td align="left">book_id integer</td></tr><tr><td align="left">author_id integer</td></tr></table>>];
+  "audit.users" [label=<<table border="0" cellborder="1" cellspacing="0"><tr><td><i>audit.users</i></td></tr></table>>];
+  "books" -> "authors" [label="author_id"];
+  "books" -> "audit.users" [label="author_id"];
+}
+`},
+		{"", "mermaid", `erDiagram
+  public_authors {
+    integer author_id
+    character_varying(255) name
+  }
+  public_books {
+    integer book_id
+    integer author_id
+  }
+  audit_users {
+  }
+  public_books }o--|| public_authors : "author_id"
+  public_books }o--|| audit_users : "author_id"
+`},
+	}
+	for i, test := range tests {
+		var sb strings.Builder
+		if err := WriteERD(&sb, erdReader{}, test.schema, test.format); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := sb.String(); s != test.exp {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, test.exp, s)
+		}
+	}
+	if err := WriteERD(new(strings.Builder), erdReader{}, "", "svg"); err == nil {
+		t.Errorf("expected error for invalid format")
+	}
+}
@@ -19,6 +19,7 @@ type InformationSchema struct {
 	pf                  func(int) string
 	hasFunctions        bool
 	hasSequences        bool
+	hasMatViews         bool
 	hasIndexes          bool
 	hasConstraints      bool
 	hasCheckConstraints bool
@@ -32,7 +33,10 @@ type InformationSchema struct {
 	dataTypeFormatter   func(metadata.Column) string
 }
 
-var _ metadata.BasicReader = &InformationSchema{}
+var (
+	_ metadata.BasicReader            = &InformationSchema{}
+	_ metadata.MaterializedViewReader = &InformationSchema{}
+)
 
 type Logger interface {
 	Println(...interface{})
@@ -46,6 +50,9 @@ const (
 	ColumnsNumericScale     = ClauseName("columns.numeric_scale")
 	ColumnsNumericPrecRadix = ClauseName("columns.numeric_precision_radix")
 	ColumnsCharOctetLength  = ClauseName("columns.character_octet_length")
+	ColumnsComment          = ClauseName("columns.comment")
+
+	TablesComment = ClauseName("tables.comment")
 
 	FunctionColumnsColumnSize       = ClauseName("function_columns.column_size")
 	FunctionColumnsNumericScale     = ClauseName("function_columns.numeric_scale")
@@ -69,6 +76,7 @@ func New(opts ...metadata.ReaderOption) func(drivers.DB, ...metadata.ReaderOptio
 		pf:                  func(n int) string { return fmt.Sprintf("$%d", n) },
 		hasFunctions:        true,
 		hasSequences:        true,
+		hasMatViews:         true,
 		hasIndexes:          true,
 		hasConstraints:      true,
 		hasCheckConstraints: true,
@@ -81,6 +89,8 @@ func New(opts ...metadata.ReaderOption) func(drivers.DB, ...metadata.ReaderOptio
 			ColumnsNumericScale:             "COALESCE(numeric_scale, 0)",
 			ColumnsNumericPrecRadix:         "COALESCE(numeric_precision_radix, 10)",
 			ColumnsCharOctetLength:          "COALESCE(character_octet_length, 0)",
+			ColumnsComment:                  "''",
+			TablesComment:                   "''",
 			FunctionColumnsColumnSize:       "COALESCE(character_maximum_length, numeric_precision, datetime_precision, 0)",
 			FunctionColumnsNumericScale:     "COALESCE(numeric_scale, 0)",
 			FunctionColumnsNumericPrecRadix: "COALESCE(numeric_precision_radix, 10)",
@@ -157,6 +167,14 @@ func WithSequences(seq bool) metadata.ReaderOption {
 	}
 }
 
+// WithMaterializedViews when the `tables` table reports materialized views
+// with the `MATERIALIZED VIEW` table type
+func WithMaterializedViews(mv bool) metadata.ReaderOption {
+	return func(r metadata.Reader) {
+		r.(*InformationSchema).hasMatViews = mv
+	}
+}
+
 // WithTablePrivileges when the `table_privileges` table exists
 func WithTablePrivileges(t bool) metadata.ReaderOption {
 	return func(r metadata.Reader) {
@@ -219,6 +237,7 @@ func (s InformationSchema) Columns(f metadata.Filter) (*metadata.ColumnSet, erro
 		s.clauses[ColumnsNumericScale],
 		s.clauses[ColumnsNumericPrecRadix],
 		s.clauses[ColumnsCharOctetLength],
+		s.clauses[ColumnsComment],
 	}
 
 	qstr := "SELECT\n  " + strings.Join(columns, ",\n  ") + " FROM information_schema.columns\n"
@@ -253,6 +272,7 @@ func (s InformationSchema) Columns(f metadata.Filter) (*metadata.ColumnSet, erro
 			&rec.DecimalDigits,
 			&rec.NumPrecRadix,
 			&rec.CharOctetLength,
+			&rec.Comment,
 		)
 		if err != nil {
 			return nil, err
@@ -272,7 +292,8 @@ func (s InformationSchema) Tables(f metadata.Filter) (*metadata.TableSet, error)
   table_catalog,
   table_schema,
   table_name,
-  table_type
+  table_type,
+  ` + s.clauses[TablesComment] + ` AS table_comment
 FROM information_schema.tables
 `
 	conds, vals := s.conditions(1, f, formats{
@@ -298,7 +319,8 @@ SELECT
   sequence_catalog AS table_catalog,
   sequence_schema AS table_schema,
   sequence_name AS table_name,
-  'SEQUENCE' AS table_type
+  'SEQUENCE' AS table_type,
+  '' AS table_comment
 FROM information_schema.sequences
 `
 		conds, seqVals := s.conditions(len(vals)+1, f, formats{
@@ -324,7 +346,7 @@ FROM information_schema.sequences
 	results := []metadata.Table{}
 	for rows.Next() {
 		rec := metadata.Table{}
-		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type)
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.Comment)
 		if err != nil {
 			return nil, err
 		}
@@ -336,6 +358,50 @@ FROM information_schema.sequences
 	return metadata.NewTableSet(results), nil
 }
 
+// MaterializedViews from selected catalog (or all, if empty), matching schemas and names
+func (s InformationSchema) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	if !s.hasMatViews {
+		return nil, text.ErrNotSupported
+	}
+	qstr := `SELECT
+  table_catalog,
+  table_schema,
+  table_name,
+  ` + s.clauses[TablesComment] + ` AS table_comment
+FROM information_schema.tables
+`
+	f.Types = []string{"MATERIALIZED VIEW"}
+	conds, vals := s.conditions(1, f, formats{
+		catalog:    "table_catalog LIKE %s",
+		schema:     "table_schema LIKE %s",
+		notSchemas: "table_schema NOT IN (%s)",
+		name:       "table_name LIKE %s",
+		types:      "table_type IN (%s)",
+	})
+	rows, closeRows, err := s.query(qstr, conds, "table_catalog, table_schema, table_name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
 // Schemas from selected catalog (or all, if empty), matching schemas and tables
 func (s InformationSchema) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
 	qstr := `SELECT
@@ -0,0 +1,98 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ildus/usql/text"
+)
+
+// WriteJoins writes ready to paste JOIN clauses for every foreign key of the
+// table, both referencing other tables and referenced by other tables, as read
+// from r, to w. Identifiers are quoted using quote. Table names are qualified
+// with their schema when schema is not empty.
+func WriteJoins(w io.Writer, r Reader, schema, table string, quote func(string) string) error {
+	cr, isCR := r.(ConstraintReader)
+	_, isCCR := r.(ConstraintColumnReader)
+	if !isCR || !isCCR {
+		return text.ErrNotSupported
+	}
+	name := func(s, t string) string {
+		if schema == "" || s == "" {
+			return quote(t)
+		}
+		return quote(s) + "." + quote(t)
+	}
+	var found int
+	for _, f := range []Filter{
+		{Schema: schema, Parent: table},
+		{Schema: schema, Reference: table},
+	} {
+		res, err := cr.Constraints(f)
+		if err != nil {
+			return fmt.Errorf("failed to list constraints: %w", err)
+		}
+		defer res.Close()
+		for res.Next() {
+			c := res.Get()
+			if c.Type != "FOREIGN KEY" {
+				continue
+			}
+			// skip self references found when listing referencing tables
+			if f.Reference != "" && c.Table == c.ForeignTable {
+				continue
+			}
+			columns, err := joinConstraintColumns(r, c)
+			if err != nil {
+				return err
+			}
+			from, to := name(c.Schema, c.Table), name(c.ForeignSchema, c.ForeignTable)
+			// alias the joined table of self references
+			joined, fromAlias, toAlias := to, from, to
+			if f.Reference != "" {
+				joined = from
+			}
+			if c.Table == c.ForeignTable {
+				joined, toAlias = to+" "+quote(c.ForeignTable+"2"), quote(c.ForeignTable+"2")
+			}
+			conds := make([]string, len(columns))
+			for i, col := range columns {
+				conds[i] = fmt.Sprintf("%s.%s = %s.%s", fromAlias, quote(col[0]), toAlias, quote(col[1]))
+			}
+			fmt.Fprintf(w, "-- %s: %s(%s) -> %s(%s)\n", c.Name, c.Table, joinNames(columns, 0), c.ForeignTable, joinNames(columns, 1))
+			fmt.Fprintf(w, "JOIN %s ON %s\n", joined, strings.Join(conds, " AND "))
+			found++
+		}
+	}
+	if found == 0 {
+		fmt.Fprintf(w, text.JoinNotFound, table)
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// joinConstraintColumns returns the column and foreign column pairs of the
+// foreign key constraint.
+func joinConstraintColumns(r Reader, c *Constraint) ([][2]string, error) {
+	res, err := r.(ConstraintColumnReader).ConstraintColumns(Filter{Catalog: c.Catalog, Schema: c.Schema, Parent: c.Table, Name: c.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of constraint %s: %w", c.Name, err)
+	}
+	defer res.Close()
+	var columns [][2]string
+	for res.Next() {
+		col := res.Get()
+		columns = append(columns, [2]string{col.Name, col.ForeignName})
+	}
+	return columns, nil
+}
+
+// joinNames joins the i-th names of the column pairs.
+func joinNames(columns [][2]string, i int) string {
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = col[i]
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+// joinReader is a reader returning fixed foreign key constraints.
+type joinReader struct{}
+
+func (joinReader) Constraints(f Filter) (*ConstraintSet, error) {
+	var res []Constraint
+	for _, c := range []Constraint{
+		{Schema: "public", Table: "books", Name: "books_author_id_fkey", Type: "FOREIGN KEY", ForeignSchema: "public", ForeignTable: "authors"},
+		{Schema: "public", Table: "books", Name: "books_pkey", Type: "PRIMARY KEY"},
+		{Schema: "public", Table: "reviews", Name: "reviews_book_id_fkey", Type: "FOREIGN KEY", ForeignSchema: "public", ForeignTable: "books"},
+		{Schema: "public", Table: "books", Name: "books_sequel_of_fkey", Type: "FOREIGN KEY", ForeignSchema: "public", ForeignTable: "books"},
+	} {
+		if f.Parent != "" && c.Table == f.Parent || f.Reference != "" && c.ForeignTable == f.Reference {
+			res = append(res, c)
+		}
+	}
+	return NewConstraintSet(res), nil
+}
+
+func (joinReader) ConstraintColumns(f Filter) (*ConstraintColumnSet, error) {
+	cols := map[string]ConstraintColumn{
+		"books_author_id_fkey": {Name: "author_id", ForeignName: "author_id"},
+		"reviews_book_id_fkey": {Name: "book_id", ForeignName: "book_id"},
+		"books_sequel_of_fkey": {Name: "sequel_of", ForeignName: "book_id"},
+	}
+	return NewConstraintColumnSet([]ConstraintColumn{cols[f.Name]}), nil
+}
+
+func TestWriteJoins(t *testing.T) {
+	quote := func(s string) string { return `"` + s + `"` }
+	tests := []struct {
+		schema string
+		table  string
+		exp    string
+	}{
+		{"", "books", `-- books_author_id_fkey: books(author_id) -> authors(author_id)
+JOIN "authors" ON "books"."author_id" = "authors"."author_id"
+-- books_sequel_of_fkey: books(sequel_of) -> books(book_id)
+JOIN "books" "books2" ON "books"."sequel_of" = "books2"."book_id"
+-- reviews_book_id_fkey: reviews(book_id) -> books(book_id)
+JOIN "reviews" ON "reviews"."book_id" = "books"."book_id"
+`},
+		{"public", "authors", `-- books_author_id_fkey: books(author_id) -> authors(author_id)
+JOIN "public"."books" ON "public"."books"."author_id" = "public"."authors"."author_id"
+`},
+		{"", "publishers", `Did not find any foreign keys for table "publishers".
+`},
+	}
+	for i, test := range tests {
+		var sb strings.Builder
+		if err := WriteJoins(&sb, joinReader{}, test.schema, test.table, quote); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := sb.String(); s != test.exp {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, test.exp, s)
+		}
+	}
+}
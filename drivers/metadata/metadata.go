@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ildus/usql/dburl"
@@ -23,6 +25,22 @@ type ExtendedReader interface {
 	FunctionColumnReader
 	SequenceReader
 	PrivilegeSummaryReader
+	MaterializedViewReader
+	SpatialColumnReader
+	ServerConfigReader
+	ActivityReader
+	LockReader
+	SizeReader
+	ProjectionReader
+	ForeignTableReader
+	PartitionReader
+	RoleReader
+	CurrentSchemaReader
+	TypeReader
+	DictionaryReader
+	IndexStatReader
+	QueryLogReader
+	DependencyReader
 }
 
 // BasicReader of common database metadata like schemas, tables and columns.
@@ -116,6 +134,112 @@ type PrivilegeSummaryReader interface {
 	PrivilegeSummaries(Filter) (*PrivilegeSummarySet, error)
 }
 
+// MaterializedViewReader lists materialized views.
+type MaterializedViewReader interface {
+	Reader
+	MaterializedViews(Filter) (*MaterializedViewSet, error)
+}
+
+// SpatialColumnReader lists spatial (geometry and geography) columns.
+type SpatialColumnReader interface {
+	Reader
+	SpatialColumns(Filter) (*SpatialColumnSet, error)
+}
+
+// ServerConfigReader lists server configuration parameters.
+type ServerConfigReader interface {
+	Reader
+	ServerConfig(Filter) (*ServerConfigSet, error)
+}
+
+// ActivityReader lists the sessions connected to the server and their
+// current queries.
+type ActivityReader interface {
+	Reader
+	Activity(Filter) (*ActivitySet, error)
+}
+
+// LockReader lists the locks held and awaited by sessions.
+type LockReader interface {
+	Reader
+	Locks(Filter) (*LockSet, error)
+}
+
+// SizeReader lists the on-disk sizes of tables and indexes.
+type SizeReader interface {
+	Reader
+	Sizes(Filter) (*SizeSet, error)
+}
+
+// ProjectionReader lists table projections.
+type ProjectionReader interface {
+	Reader
+	Projections(Filter) (*ProjectionSet, error)
+}
+
+// PartitionReader lists the partitions of partitioned tables.
+type PartitionReader interface {
+	Reader
+	Partitions(Filter) (*PartitionSet, error)
+}
+
+// RoleReader lists database roles and users.
+type RoleReader interface {
+	Reader
+	Roles(Filter) (*RoleSet, error)
+}
+
+// CurrentSchemaReader returns the schemas searched by the session for objects
+// referenced by unqualified names, in the order the server resolves them.
+type CurrentSchemaReader interface {
+	Reader
+	CurrentSchema() ([]string, error)
+}
+
+// ForeignTableReader lists foreign and external tables, whose data is stored
+// outside of the database.
+type ForeignTableReader interface {
+	Reader
+	ForeignTables(Filter) (*ForeignTableSet, error)
+}
+
+// TypeReader lists user-defined types, such as enums, domains, and
+// composite, object, or table types.
+type TypeReader interface {
+	Reader
+	Types(Filter) (*TypeSet, error)
+}
+
+// DictionaryReader lists dictionaries, the in-memory key-value lookups loaded
+// from external sources, such as ClickHouse dictionaries.
+type DictionaryReader interface {
+	Reader
+	Dictionaries(Filter) (*DictionarySet, error)
+}
+
+// IndexStatReader lists the usage statistics of indexes, such as the number of
+// index scans, to find unused indexes.
+type IndexStatReader interface {
+	Reader
+	IndexStats(Filter) (*IndexStatSet, error)
+}
+
+// QueryLogReader lists the most recent queries logged by the server, with
+// their duration and resource usage.
+type QueryLogReader interface {
+	Reader
+	QueryLog(Filter, int) (*LoggedQuerySet, error)
+}
+
+// DependencyReader lists the dependencies between database objects, such as
+// the tables and functions referenced by views. Dependencies are filtered by
+// the schema and name of the dependent object, or by the schema and name of
+// the referenced object when the filter's Reference is set.
+type DependencyReader interface {
+	Reader
+	Dependencies(Filter) (*DependencySet, error)
+}
+
 // Reader of any database metadata in a structured format.
 type Reader interface{}
 
@@ -160,6 +284,32 @@ type Writer interface {
 	ShowStats(*dburl.URL, string, string, bool, int) error
 	// ListPrivilegeSummaries \dp
 	ListPrivilegeSummaries(*dburl.URL, string, bool) error
+	// ListSpatialColumns \dgs
+	ListSpatialColumns(*dburl.URL, string, bool, bool) error
+	// ListServerConfig \dconfig
+	ListServerConfig(*dburl.URL, string, bool) error
+	// ListActivity \dactivity
+	ListActivity(*dburl.URL, string, bool) error
+	// ListLocks \dlocks
+	ListLocks(*dburl.URL, string, bool) error
+	// ListSizes \dsize
+	ListSizes(*dburl.URL, string, bool, bool) error
+	// ListForeignTables \det
+	ListForeignTables(*dburl.URL, string, bool, bool) error
+	// ListRoles \du, \dg
+	ListRoles(*dburl.URL, string, bool, bool) error
+	// ListTypes \dT
+	ListTypes(*dburl.URL, string, bool, bool) error
+	// ListDictionaries \ddict
+	ListDictionaries(*dburl.URL, string, bool) error
+	// ListIndexStats \dxstat
+	ListIndexStats(*dburl.URL, string, bool, bool) error
+	// ListQueryLog \qlog
+	ListQueryLog(*dburl.URL, string, int, bool) error
+	// DescribeDependencies \ddep
+	DescribeDependencies(*dburl.URL, string, bool) error
+	// ShowFunctionSource \sf
+	ShowFunctionSource(*dburl.URL, string, bool) error
 }
 
 type CatalogSet struct {
@@ -323,6 +473,7 @@ func NewColumnSet(v []Column) *ColumnSet {
 				"Decimal Digits",
 				"Precision Radix",
 				"Octet Length",
+				"Comment",
 			},
 		},
 	}
@@ -346,6 +497,7 @@ type Column struct {
 	NumPrecRadix    int
 	CharOctetLength int
 	IsNullable      Bool
+	Comment         string
 }
 
 type Bool string
@@ -369,6 +521,7 @@ func (c Column) Values() []interface{} {
 		c.DecimalDigits,
 		c.NumPrecRadix,
 		c.CharOctetLength,
+		c.Comment,
 	}
 }
 
@@ -530,6 +683,12 @@ type IndexColumn struct {
 	Name            string
 	DataType        string
 	OrdinalPosition int
+	// KeyPosition is the position of the column in the index key, or 0 when
+	// the column is not part of the key.
+	KeyPosition int
+	// SortOrder is the sort direction of the column in the index key (ASC or
+	// DESC), or empty when the index is unordered.
+	SortOrder string
 }
 
 func (c IndexColumn) Values() []interface{} {
@@ -865,204 +1024,767 @@ func (s Sequence) Values() []interface{} {
 	}
 }
 
-type PrivilegeSummarySet struct {
+type MaterializedViewSet struct {
 	resultSet
 }
 
-func NewPrivilegeSummarySet(v []PrivilegeSummary) *PrivilegeSummarySet {
+func NewMaterializedViewSet(v []MaterializedView) *MaterializedViewSet {
 	r := make([]Result, len(v))
 	for i := range v {
 		r[i] = &v[i]
 	}
-	return &PrivilegeSummarySet{
+	return &MaterializedViewSet{
 		resultSet: resultSet{
 			results: r,
 			columns: []string{
+				"Catalog",
 				"Schema",
 				"Name",
-				"Type",
-				"Access privileges",
-				"Column privileges",
+				"Owner",
+				"Populated?",
+				"Last refresh",
+				"Refresh mode",
+				"Size",
+				"Comment",
 			},
 		},
 	}
 }
 
-func (s PrivilegeSummarySet) Get() *PrivilegeSummary {
-	return s.results[s.current-1].(*PrivilegeSummary)
+func (s MaterializedViewSet) Get() *MaterializedView {
+	return s.results[s.current-1].(*MaterializedView)
 }
 
-// PrivilegeSummary summarizes the privileges granted on a database object
-type PrivilegeSummary struct {
-	Catalog          string
-	Schema           string
-	Name             string
-	ObjectType       string
-	ObjectPrivileges ObjectPrivileges
-	ColumnPrivileges ColumnPrivileges
+// MaterializedView describes a materialized view, including its refresh state
+// when the database keeps track of it.
+type MaterializedView struct {
+	Catalog     string
+	Schema      string
+	Name        string
+	Owner       string
+	IsPopulated Bool
+	LastRefresh string
+	RefreshMode string
+	Size        string
+	Comment     string
+	Definition  string
 }
 
-func (s PrivilegeSummary) Values() []interface{} {
+func (m MaterializedView) Values() []interface{} {
 	return []interface{}{
-		s.Catalog,
-		s.Schema,
-		s.Name,
-		s.ObjectType,
-		s.ObjectPrivileges,
-		s.ColumnPrivileges,
+		m.Catalog,
+		m.Schema,
+		m.Name,
+		m.Owner,
+		m.IsPopulated,
+		m.LastRefresh,
+		m.RefreshMode,
+		m.Size,
+		m.Comment,
 	}
 }
 
-// ObjectPrivilege represents a privilege granted on a database object.
-type ObjectPrivilege struct {
-	Grantee       string
-	Grantor       string
-	PrivilegeType string
-	IsGrantable   bool
+type SpatialColumnSet struct {
+	resultSet
 }
 
-// ColumnPrivilege represents a privilege granted on a column.
-type ColumnPrivilege struct {
-	Column        string
-	Grantee       string
-	Grantor       string
-	PrivilegeType string
-	IsGrantable   bool
+func NewSpatialColumnSet(v []SpatialColumn) *SpatialColumnSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &SpatialColumnSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Table",
+				"Name",
+				"Type",
+				"SRID",
+				"Dimensions",
+				"Spatial index",
+			},
+		},
+	}
 }
 
-// ObjectPrivileges represents privileges granted on a database object.
-// The privileges are assumed to be sorted. Otherwise the
-// String() method will fail.
-type ObjectPrivileges []ObjectPrivilege
-
-// ColumnPrivileges represents privileges granted on a column.
-// The privileges are assumed to be sorted. Otherwise the
-// String() method will fail.
-type ColumnPrivileges []ColumnPrivilege
+func (s SpatialColumnSet) Get() *SpatialColumn {
+	return s.results[s.current-1].(*SpatialColumn)
+}
 
-func (p ObjectPrivileges) Len() int      { return len(p) }
-func (p ObjectPrivileges) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
-func (p ObjectPrivileges) Less(i, j int) bool {
-	switch {
-	case p[i].Grantee != p[j].Grantee:
-		return p[i].Grantee < p[j].Grantee
-	case p[i].Grantor != p[j].Grantor:
-		return p[i].Grantor < p[j].Grantor
-	}
-	return p[i].PrivilegeType < p[j].PrivilegeType
+// SpatialColumn describes a spatial column, including its spatial reference
+// system identifier and the spatial indexes covering it.
+type SpatialColumn struct {
+	Catalog    string
+	Schema     string
+	Table      string
+	Name       string
+	Type       string
+	SRID       string
+	Dimensions string
+	Indexes    string
 }
 
-// String returns a string representation of ObjectPrivileges.
-// Assumes the ObjectPrivileges to be sorted.
-func (p ObjectPrivileges) String() string {
-	if len(p) == 0 {
-		return ""
+func (c SpatialColumn) Values() []interface{} {
+	return []interface{}{
+		c.Catalog,
+		c.Schema,
+		c.Table,
+		c.Name,
+		c.Type,
+		c.SRID,
+		c.Dimensions,
+		c.Indexes,
 	}
+}
 
-	lines := []string{}
-	types := []string{}
-	for i := range p {
-		switch {
-		// Is last privilege or next privilege has new grantee or grantor; finalize line
-		case i == len(p)-1 || p[i].Grantee != p[i+1].Grantee || p[i].Grantor != p[i+1].Grantor:
-			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
-			lines = append(lines, lineStr(p[i].Grantee, p[i].Grantor, types))
-			types = types[:0]
-		default:
-			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
-		}
-	}
-	return strings.Join(lines, "\n")
+type ServerConfigSet struct {
+	resultSet
 }
 
-func (p ColumnPrivileges) Len() int      { return len(p) }
-func (p ColumnPrivileges) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
-func (p ColumnPrivileges) Less(i, j int) bool {
-	switch {
-	case p[i].Column != p[j].Column:
-		return p[i].Column < p[j].Column
-	case p[i].Grantee != p[j].Grantee:
-		return p[i].Grantee < p[j].Grantee
-	case p[i].Grantor != p[j].Grantor:
-		return p[i].Grantor < p[j].Grantor
+func NewServerConfigSet(v []ServerConfig) *ServerConfigSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ServerConfigSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Name",
+				"Value",
+				"Unit",
+				"Default",
+				"Changed",
+				"Source",
+				"Description",
+			},
+		},
 	}
-	return p[i].PrivilegeType < p[j].PrivilegeType
 }
 
-// String returns a string representation of ColumnPrivileges.
-// Assumes the ColumnPrivileges to be sorted.
-func (p ColumnPrivileges) String() string {
-	if len(p) == 0 {
-		return ""
-	}
+func (s ServerConfigSet) Get() *ServerConfig {
+	return s.results[s.current-1].(*ServerConfig)
+}
 
-	colBlocks := []string{}
-	lines := []string{}
-	types := []string{}
-	for i := range p {
-		switch {
-		// Is last privilege or next privilege has new column; finalize column block
-		case i == len(p)-1 || p[i].Column != p[i+1].Column:
-			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
-			lines = append(lines, "  "+lineStr(p[i].Grantee, p[i].Grantor, types))
-			colBlocks = append(colBlocks, p[i].Column+":\n"+strings.Join(lines, "\n"))
-			lines = lines[:0]
-			types = types[:0]
-		// Next privilege has new grantee or grantor; finalize line
-		case p[i].Grantee != p[i+1].Grantee || p[i].Grantor != p[i+1].Grantor:
-			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
-			lines = append(lines, "  "+lineStr(p[i].Grantee, p[i].Grantor, types))
-			types = types[:0]
-		default:
-			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
-		}
-	}
-	return strings.Join(colBlocks, "\n")
+// ServerConfig describes a server configuration parameter, and whether it was
+// changed from its default value.
+type ServerConfig struct {
+	Name        string
+	Value       string
+	Unit        string
+	Default     string
+	Changed     Bool
+	Source      string
+	Description string
 }
 
-// typeStr appends an asterisk suffix to grantable privileges
-func typeStr(privilege string, grantable bool) string {
-	if grantable {
-		return privilege + "*"
-	} else {
-		return privilege
+func (c ServerConfig) Values() []interface{} {
+	return []interface{}{
+		c.Name,
+		c.Value,
+		c.Unit,
+		c.Default,
+		c.Changed,
+		c.Source,
+		c.Description,
 	}
 }
 
-// lineStr compiles grantee, grantor and privilege types into a line of output
-func lineStr(grantee, grantor string, types []string) string {
-	if grantor != "" {
-		return grantee + "=" + strings.Join(types, ",") + "/" + grantor
-	} else {
-		return grantee + "=" + strings.Join(types, ",")
-	}
+type ActivitySet struct {
+	resultSet
 }
 
-type resultSet struct {
-	results    []Result
-	columns    []string
-	current    int
-	filter     func(Result) bool
-	scanValues func(Result) []interface{}
+func NewActivitySet(v []Activity) *ActivitySet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ActivitySet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"ID",
+				"User",
+				"Database",
+				"Client",
+				"State",
+				"Started",
+				"Duration",
+				"Query",
+			},
+		},
+	}
 }
 
-type Result interface {
-	Values() []interface{}
+func (s ActivitySet) Get() *Activity {
+	return s.results[s.current-1].(*Activity)
 }
 
-func (r *resultSet) SetFilter(f func(Result) bool) {
-	r.filter = f
+// Activity describes a session connected to the server, and the query it is
+// currently (or was last) executing. The ID identifies the session to kill.
+type Activity struct {
+	ID       string
+	User     string
+	Database string
+	Client   string
+	State    string
+	Started  string
+	Duration string
+	Query    string
 }
 
-func (r *resultSet) SetColumns(c []string) {
-	r.columns = c
+func (a Activity) Values() []interface{} {
+	return []interface{}{
+		a.ID,
+		a.User,
+		a.Database,
+		a.Client,
+		a.State,
+		a.Started,
+		a.Duration,
+		a.Query,
+	}
 }
 
-func (r *resultSet) SetScanValues(s func(Result) []interface{}) {
-	r.scanValues = s
+type LockSet struct {
+	resultSet
 }
 
-func (r *resultSet) Len() int {
+func NewLockSet(v []Lock) *LockSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &LockSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Session",
+				"User",
+				"Type",
+				"Schema",
+				"Object",
+				"Mode",
+				"Granted",
+				"Blocked by",
+				"Query",
+			},
+		},
+	}
+}
+
+func (s LockSet) Get() *Lock {
+	return s.results[s.current-1].(*Lock)
+}
+
+// Lock describes a lock held (or awaited, when not granted) by a session.
+// BlockedBy is a list, separated by ", ", of the sessions holding the locks
+// the session is waiting on.
+type Lock struct {
+	Session   string
+	User      string
+	Type      string
+	Schema    string
+	Object    string
+	Mode      string
+	Granted   Bool
+	BlockedBy string
+	Query     string
+}
+
+func (l Lock) Values() []interface{} {
+	return []interface{}{
+		l.Session,
+		l.User,
+		l.Type,
+		l.Schema,
+		l.Object,
+		l.Mode,
+		l.Granted,
+		l.BlockedBy,
+		l.Query,
+	}
+}
+
+type SizeSet struct {
+	resultSet
+}
+
+func NewSizeSet(v []Size) *SizeSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &SizeSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Name",
+				"Type",
+				"Table",
+				"Rows",
+				"Size",
+				"Index size",
+				"Total size",
+				"Bloat",
+			},
+		},
+	}
+}
+
+func (s SizeSet) Get() *Size {
+	return s.results[s.current-1].(*Size)
+}
+
+// Size describes the on-disk size of a table (including the size of its
+// indexes), or of an index of Table. Rows is an estimate, and Bloat an
+// estimate of the space wasted by dead rows or fragmentation, if available.
+type Size struct {
+	Catalog   string
+	Schema    string
+	Name      string
+	Type      string
+	Table     string
+	Rows      string
+	Size      string
+	IndexSize string
+	TotalSize string
+	Bloat     string
+}
+
+func (s Size) Values() []interface{} {
+	return []interface{}{
+		s.Catalog,
+		s.Schema,
+		s.Name,
+		s.Type,
+		s.Table,
+		s.Rows,
+		s.Size,
+		s.IndexSize,
+		s.TotalSize,
+		s.Bloat,
+	}
+}
+
+type ProjectionSet struct {
+	resultSet
+}
+
+func NewProjectionSet(v []Projection) *ProjectionSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ProjectionSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Table",
+				"Name",
+				"Type",
+				"Definition",
+			},
+		},
+	}
+}
+
+func (s ProjectionSet) Get() *Projection {
+	return s.results[s.current-1].(*Projection)
+}
+
+// Projection describes a table projection, a copy of the table's data stored
+// with a different sort order or pre-aggregated, that is used in place of the
+// table by matching queries.
+type Projection struct {
+	Catalog    string
+	Schema     string
+	Table      string
+	Name       string
+	Type       string
+	Definition string
+}
+
+func (p Projection) Values() []interface{} {
+	return []interface{}{
+		p.Catalog,
+		p.Schema,
+		p.Table,
+		p.Name,
+		p.Type,
+		p.Definition,
+	}
+}
+
+type PartitionSet struct {
+	resultSet
+}
+
+func NewPartitionSet(v []Partition) *PartitionSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &PartitionSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Table",
+				"Name",
+				"Method",
+				"Key",
+				"Bound",
+				"Rows",
+				"Size",
+				"Parts",
+			},
+		},
+	}
+}
+
+func (s PartitionSet) Get() *Partition {
+	return s.results[s.current-1].(*Partition)
+}
+
+// Partition describes a partition of a partitioned table, with the
+// partitioning method and key of the table it belongs to. A partitioned table
+// without any partitions is described by a partition without a name.
+type Partition struct {
+	Catalog string
+	Schema  string
+	Table   string
+	Name    string
+	Method  string
+	Key     string
+	Bound   string
+	Rows    string
+	Size    string
+	// Parts is the number of data parts of the partition, for databases
+	// storing a partition as multiple parts.
+	Parts string
+}
+
+func (p Partition) Values() []interface{} {
+	return []interface{}{
+		p.Catalog,
+		p.Schema,
+		p.Table,
+		p.Name,
+		p.Method,
+		p.Key,
+		p.Bound,
+		p.Rows,
+		p.Size,
+		p.Parts,
+	}
+}
+
+type RoleSet struct {
+	resultSet
+}
+
+func NewRoleSet(v []Role) *RoleSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &RoleSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Role name",
+				"Login",
+				"Superuser",
+				"Attributes",
+				"Member of",
+				"Valid until",
+				"Description",
+			},
+		},
+	}
+}
+
+func (s RoleSet) Get() *Role {
+	return s.results[s.current-1].(*Role)
+}
+
+// Role describes a database role or user, whether it can log in or has all
+// privileges, and the roles it is a member of. Membership granted with the
+// admin option (ie, allowing to grant the role to others) is suffixed with
+// "(admin)".
+type Role struct {
+	Name       string
+	Login      Bool
+	Superuser  Bool
+	Attributes string
+	MemberOf   string
+	ValidUntil string
+	Comment    string
+}
+
+func (r Role) Values() []interface{} {
+	return []interface{}{
+		r.Name,
+		r.Login,
+		r.Superuser,
+		r.Attributes,
+		r.MemberOf,
+		r.ValidUntil,
+		r.Comment,
+	}
+}
+
+// FormatSize formats a size in bytes in human readable units, like
+// PostgreSQL's pg_size_pretty.
+func FormatSize(n int64) string {
+	units := []string{"bytes", "kB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for ; i < len(units)-1 && (n >= 10*1024 || n <= -10*1024); i++ {
+		n = (n + 512) / 1024
+	}
+	return fmt.Sprintf("%d %s", n, units[i])
+}
+
+type PrivilegeSummarySet struct {
+	resultSet
+}
+
+func NewPrivilegeSummarySet(v []PrivilegeSummary) *PrivilegeSummarySet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &PrivilegeSummarySet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Name",
+				"Type",
+				"Access privileges",
+				"Column privileges",
+			},
+		},
+	}
+}
+
+func (s PrivilegeSummarySet) Get() *PrivilegeSummary {
+	return s.results[s.current-1].(*PrivilegeSummary)
+}
+
+// PrivilegeSummary summarizes the privileges granted on a database object
+type PrivilegeSummary struct {
+	Catalog          string
+	Schema           string
+	Name             string
+	ObjectType       string
+	ObjectPrivileges ObjectPrivileges
+	ColumnPrivileges ColumnPrivileges
+}
+
+func (s PrivilegeSummary) Values() []interface{} {
+	return []interface{}{
+		s.Catalog,
+		s.Schema,
+		s.Name,
+		s.ObjectType,
+		s.ObjectPrivileges,
+		s.ColumnPrivileges,
+	}
+}
+
+// ObjectPrivilege represents a privilege granted on a database object.
+type ObjectPrivilege struct {
+	Grantee       string
+	Grantor       string
+	PrivilegeType string
+	IsGrantable   bool
+}
+
+// ColumnPrivilege represents a privilege granted on a column.
+type ColumnPrivilege struct {
+	Column        string
+	Grantee       string
+	Grantor       string
+	PrivilegeType string
+	IsGrantable   bool
+}
+
+// ObjectPrivileges represents privileges granted on a database object.
+// The privileges are assumed to be sorted. Otherwise the
+// String() method will fail.
+type ObjectPrivileges []ObjectPrivilege
+
+// ColumnPrivileges represents privileges granted on a column.
+// The privileges are assumed to be sorted. Otherwise the
+// String() method will fail.
+type ColumnPrivileges []ColumnPrivilege
+
+func (p ObjectPrivileges) Len() int      { return len(p) }
+func (p ObjectPrivileges) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p ObjectPrivileges) Less(i, j int) bool {
+	switch {
+	case p[i].Grantee != p[j].Grantee:
+		return p[i].Grantee < p[j].Grantee
+	case p[i].Grantor != p[j].Grantor:
+		return p[i].Grantor < p[j].Grantor
+	}
+	return p[i].PrivilegeType < p[j].PrivilegeType
+}
+
+// String returns a string representation of ObjectPrivileges.
+// Assumes the ObjectPrivileges to be sorted.
+func (p ObjectPrivileges) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	lines := []string{}
+	types := []string{}
+	for i := range p {
+		switch {
+		// Is last privilege or next privilege has new grantee or grantor; finalize line
+		case i == len(p)-1 || p[i].Grantee != p[i+1].Grantee || p[i].Grantor != p[i+1].Grantor:
+			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
+			lines = append(lines, lineStr(p[i].Grantee, p[i].Grantor, types))
+			types = types[:0]
+		default:
+			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p ColumnPrivileges) Len() int      { return len(p) }
+func (p ColumnPrivileges) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p ColumnPrivileges) Less(i, j int) bool {
+	switch {
+	case p[i].Column != p[j].Column:
+		return p[i].Column < p[j].Column
+	case p[i].Grantee != p[j].Grantee:
+		return p[i].Grantee < p[j].Grantee
+	case p[i].Grantor != p[j].Grantor:
+		return p[i].Grantor < p[j].Grantor
+	}
+	return p[i].PrivilegeType < p[j].PrivilegeType
+}
+
+// String returns a string representation of ColumnPrivileges.
+// Assumes the ColumnPrivileges to be sorted.
+func (p ColumnPrivileges) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	colBlocks := []string{}
+	lines := []string{}
+	types := []string{}
+	for i := range p {
+		switch {
+		// Is last privilege or next privilege has new column; finalize column block
+		case i == len(p)-1 || p[i].Column != p[i+1].Column:
+			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
+			lines = append(lines, "  "+lineStr(p[i].Grantee, p[i].Grantor, types))
+			colBlocks = append(colBlocks, p[i].Column+":\n"+strings.Join(lines, "\n"))
+			lines = lines[:0]
+			types = types[:0]
+		// Next privilege has new grantee or grantor; finalize line
+		case p[i].Grantee != p[i+1].Grantee || p[i].Grantor != p[i+1].Grantor:
+			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
+			lines = append(lines, "  "+lineStr(p[i].Grantee, p[i].Grantor, types))
+			types = types[:0]
+		default:
+			types = append(types, typeStr(p[i].PrivilegeType, p[i].IsGrantable))
+		}
+	}
+	return strings.Join(colBlocks, "\n")
+}
+
+// typeStr appends an asterisk suffix to grantable privileges
+func typeStr(privilege string, grantable bool) string {
+	if grantable {
+		return privilege + "*"
+	} else {
+		return privilege
+	}
+}
+
+// lineStr compiles grantee, grantor and privilege types into a line of output
+func lineStr(grantee, grantor string, types []string) string {
+	if grantor != "" {
+		return grantee + "=" + strings.Join(types, ",") + "/" + grantor
+	} else {
+		return grantee + "=" + strings.Join(types, ",")
+	}
+}
+
+type resultSet struct {
+	results    []Result
+	columns    []string
+	current    int
+	filter     func(Result) bool
+	scanValues func(Result) []interface{}
+}
+
+type Result interface {
+	Values() []interface{}
+}
+
+func (r *resultSet) SetFilter(f func(Result) bool) {
+	r.filter = f
+}
+
+func (r *resultSet) SetColumns(c []string) {
+	r.columns = c
+}
+
+func (r *resultSet) SetScanValues(s func(Result) []interface{}) {
+	r.scanValues = s
+}
+
+// SetSearchPath restricts the results to those in the schemas of path, ordered
+// by the position of their schema in path. When shadow is true, results having
+// the same name as a result in an earlier schema are also excluded, as they
+// cannot be referenced by their name alone.
+func (r *resultSet) SetSearchPath(path []string, schema, name func(Result) string, shadow bool) {
+	pos := make(map[string]int, len(path))
+	for i, s := range path {
+		if _, ok := pos[s]; !ok {
+			pos[s] = i
+		}
+	}
+	var results []Result
+	for _, rec := range r.results {
+		if _, ok := pos[schema(rec)]; ok {
+			results = append(results, rec)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return pos[schema(results[i])] < pos[schema(results[j])]
+	})
+	if shadow {
+		seen, i := make(map[string]bool), 0
+		for _, rec := range results {
+			if n := name(rec); !seen[n] {
+				seen[n], results[i] = true, rec
+				i++
+			}
+		}
+		results = results[:i]
+	}
+	r.results = results
+}
+
+func (r *resultSet) Len() int {
 	if r.filter == nil {
 		return len(r.results)
 	}
@@ -1166,3 +1888,392 @@ func NewTriggerSet(t []Trigger) *TriggerSet {
 func (t TriggerSet) Get() *Trigger {
 	return t.results[t.current-1].(*Trigger)
 }
+
+type ForeignTableSet struct {
+	resultSet
+}
+
+func NewForeignTableSet(v []ForeignTable) *ForeignTableSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ForeignTableSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Name",
+				"Type",
+				"Server",
+				"Wrapper",
+				"Options",
+				"Owner",
+				"Comment",
+			},
+		},
+	}
+}
+
+func (s ForeignTableSet) Get() *ForeignTable {
+	return s.results[s.current-1].(*ForeignTable)
+}
+
+// ForeignTable describes a foreign or external table, such as a PostgreSQL
+// foreign table, a Hive external table, or a ClickHouse dictionary. Server is
+// the server or location the data is read from, Wrapper the foreign data
+// wrapper, storage handler, or engine used to read it, and Options its
+// options, if any.
+type ForeignTable struct {
+	Catalog string
+	Schema  string
+	Name    string
+	Type    string
+	Server  string
+	Wrapper string
+	Options string
+	Owner   string
+	Comment string
+}
+
+func (t ForeignTable) Values() []interface{} {
+	return []interface{}{
+		t.Catalog,
+		t.Schema,
+		t.Name,
+		t.Type,
+		t.Server,
+		t.Wrapper,
+		t.Options,
+		t.Owner,
+		t.Comment,
+	}
+}
+
+type TypeSet struct {
+	resultSet
+}
+
+func NewTypeSet(v []Type) *TypeSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &TypeSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Name",
+				"Kind",
+				"Definition",
+				"Elements",
+				"Owner",
+				"Comment",
+			},
+		},
+	}
+}
+
+func (s TypeSet) Get() *Type {
+	return s.results[s.current-1].(*Type)
+}
+
+// Type describes a user-defined type. Kind is the kind of type, such as enum,
+// domain, composite, object, collection, or table. Definition is the
+// underlying type of domains, aliases, and collections, or the attributes of
+// composite, object, and table types, and Elements the labels of enums.
+type Type struct {
+	Catalog    string
+	Schema     string
+	Name       string
+	Kind       string
+	Definition string
+	Elements   string
+	Owner      string
+	Comment    string
+}
+
+func (t Type) Values() []interface{} {
+	return []interface{}{
+		t.Catalog,
+		t.Schema,
+		t.Name,
+		t.Kind,
+		t.Definition,
+		t.Elements,
+		t.Owner,
+		t.Comment,
+	}
+}
+
+type DictionarySet struct {
+	resultSet
+}
+
+func NewDictionarySet(v []Dictionary) *DictionarySet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &DictionarySet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Name",
+				"Status",
+				"Layout",
+				"Source",
+				"Lifetime",
+				"Hit rate",
+				"Elements",
+				"Size",
+				"Last update",
+				"Last error",
+				"Definition",
+				"Comment",
+			},
+		},
+	}
+}
+
+func (s DictionarySet) Get() *Dictionary {
+	return s.results[s.current-1].(*Dictionary)
+}
+
+// Dictionary describes a dictionary, its loading status, the layout it is
+// stored in memory with, the source it is loaded from, and how often it is
+// reloaded. HitRate is the percentage of lookups found in the cache of cached
+// layouts, and Definition is the statement creating the dictionary, if it was
+// not created from the server configuration.
+type Dictionary struct {
+	Schema     string
+	Name       string
+	Status     string
+	Layout     string
+	Source     string
+	Lifetime   string
+	HitRate    string
+	Elements   int64
+	Size       string
+	LastUpdate string
+	LastError  string
+	Definition string
+	Comment    string
+}
+
+func (d Dictionary) Values() []interface{} {
+	return []interface{}{
+		d.Schema,
+		d.Name,
+		d.Status,
+		d.Layout,
+		d.Source,
+		d.Lifetime,
+		d.HitRate,
+		d.Elements,
+		d.Size,
+		d.LastUpdate,
+		d.LastError,
+		d.Definition,
+		d.Comment,
+	}
+}
+
+type IndexStatSet struct {
+	resultSet
+}
+
+func NewIndexStatSet(v []IndexStat) *IndexStatSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &IndexStatSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Table",
+				"Name",
+				"Scans",
+				"Rows read",
+				"Size",
+				"Last used",
+				"Required",
+				"Definition",
+			},
+		},
+	}
+}
+
+func (s IndexStatSet) Get() *IndexStat {
+	return s.results[s.current-1].(*IndexStat)
+}
+
+// IndexStat describes the usage of an index since the server statistics were
+// last reset. Scans is the number of index scans (or reads, when scans are not
+// counted), and RowsRead and LastUsed are empty when not available. Required
+// is true for indexes that cannot be dropped without changing their table,
+// such as the indexes enforcing primary key and unique constraints, or
+// clustered indexes.
+type IndexStat struct {
+	Catalog    string
+	Schema     string
+	Table      string
+	Name       string
+	Scans      int64
+	RowsRead   string
+	Size       string
+	LastUsed   string
+	Required   bool
+	Definition string
+}
+
+// Unused returns true when the index was never used, and is not required,
+// making it a likely candidate for removal.
+func (s IndexStat) Unused() bool {
+	return s.Scans == 0 && !s.Required
+}
+
+func (s IndexStat) Values() []interface{} {
+	return []interface{}{
+		s.Catalog,
+		s.Schema,
+		s.Table,
+		s.Name,
+		s.Scans,
+		s.RowsRead,
+		s.Size,
+		s.LastUsed,
+		s.Required,
+		s.Definition,
+	}
+}
+
+type LoggedQuerySet struct {
+	resultSet
+}
+
+func NewLoggedQuerySet(v []LoggedQuery) *LoggedQuerySet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &LoggedQuerySet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"ID",
+				"User",
+				"Database",
+				"Type",
+				"Started",
+				"Duration",
+				"Read rows",
+				"Read bytes",
+				"Written rows",
+				"Result rows",
+				"Memory",
+				"Error",
+				"Query",
+			},
+		},
+	}
+}
+
+func (s LoggedQuerySet) Get() *LoggedQuery {
+	return s.results[s.current-1].(*LoggedQuery)
+}
+
+// LoggedQuery describes a query logged by the server once finished, or when
+// failed, in which case Error is the error of the query.
+type LoggedQuery struct {
+	ID          string
+	User        string
+	Database    string
+	Type        string
+	Started     string
+	Duration    string
+	ReadRows    int64
+	ReadBytes   string
+	WrittenRows int64
+	ResultRows  int64
+	Memory      string
+	Error       string
+	Query       string
+}
+
+func (q LoggedQuery) Values() []interface{} {
+	return []interface{}{
+		q.ID,
+		q.User,
+		q.Database,
+		q.Type,
+		q.Started,
+		q.Duration,
+		q.ReadRows,
+		q.ReadBytes,
+		q.WrittenRows,
+		q.ResultRows,
+		q.Memory,
+		q.Error,
+		q.Query,
+	}
+}
+
+type DependencySet struct {
+	resultSet
+}
+
+func NewDependencySet(v []Dependency) *DependencySet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &DependencySet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Catalog",
+				"Schema",
+				"Name",
+				"Type",
+				"Referenced schema",
+				"Referenced name",
+				"Referenced type",
+			},
+		},
+	}
+}
+
+func (s DependencySet) Get() *Dependency {
+	return s.results[s.current-1].(*Dependency)
+}
+
+// Dependency describes an object depending on (referencing) another object.
+type Dependency struct {
+	Catalog   string
+	Schema    string
+	Name      string
+	Type      string
+	RefSchema string
+	RefName   string
+	RefType   string
+}
+
+func (d Dependency) Values() []interface{} {
+	return []interface{}{
+		d.Catalog,
+		d.Schema,
+		d.Name,
+		d.Type,
+		d.RefSchema,
+		d.RefName,
+		d.RefType,
+	}
+}
@@ -173,3 +173,51 @@ func TestColumnPrivileges_String(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n   int64
+		exp string
+	}{
+		{0, "0 bytes"},
+		{10239, "10239 bytes"},
+		{10240, "10 kB"},
+		{1536 * 1024, "1536 kB"},
+		{10 * 1024 * 1024, "10 MB"},
+		{3 * 1024 * 1024 * 1024 * 1024, "3072 GB"},
+	}
+	for i, test := range tests {
+		if s := FormatSize(test.n); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
+
+func TestSetSearchPath(t *testing.T) {
+	tables := []Table{
+		{Schema: "public", Name: "books"},
+		{Schema: "archive", Name: "books"},
+		{Schema: "archive", Name: "authors"},
+		{Schema: "other", Name: "authors"},
+		{Schema: "public", Name: "sales"},
+	}
+	tests := []struct {
+		shadow bool
+		exp    []string
+	}{
+		{false, []string{"archive.books", "archive.authors", "public.books", "public.sales"}},
+		{true, []string{"archive.books", "archive.authors", "public.sales"}},
+	}
+	for i, test := range tests {
+		res := NewTableSet(tables)
+		res.SetSearchPath([]string{"archive", "public"}, tableSchema, tableName, test.shadow)
+		var names []string
+		for res.Next() {
+			t := res.Get()
+			names = append(names, t.Schema+"."+t.Name)
+		}
+		if diff := cmp.Diff(test.exp, names); diff != "" {
+			t.Errorf("test %d unexpected results (-expected, +got):\n%s", i, diff)
+		}
+	}
+}
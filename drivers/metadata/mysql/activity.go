@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/text"
+)
+
+// activityReader reads the sessions of MySQL and MariaDB databases.
+type activityReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.ActivityReader = &activityReader{}
+
+func (r activityReader) Activity(f metadata.Filter) (*metadata.ActivitySet, error) {
+	qstr := `SELECT
+  CAST(id AS CHAR),
+  COALESCE(user, ''),
+  COALESCE(db, ''),
+  COALESCE(host, ''),
+  CONCAT_WS(': ', command, NULLIF(state, '')),
+  DATE_FORMAT(NOW() - INTERVAL time SECOND, '%Y-%m-%d %H:%i:%s'),
+  CAST(SEC_TO_TIME(time) AS CHAR),
+  COALESCE(info, '')
+FROM information_schema.processlist`
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		qstr += "\nWHERE user LIKE ?"
+	}
+	qstr += "\nORDER BY time DESC, id"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewActivitySet([]metadata.Activity{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Activity{}
+	for rows.Next() {
+		rec := metadata.Activity{}
+		err = rows.Scan(&rec.ID, &rec.User, &rec.Database, &rec.Client, &rec.State, &rec.Started, &rec.Duration, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewActivitySet(results), nil
+}
+
+// Kill kills the connection with id.
+func Kill(ctx context.Context, db drivers.DB, id string) error {
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return text.ErrNoSuchSession
+	}
+	_, err := db.ExecContext(ctx, `KILL `+id)
+	return err
+}
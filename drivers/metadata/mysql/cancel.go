@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// connIDs are the connection ids retrieved by QueryID, by driver connection.
+var connIDs = struct {
+	sync.Mutex
+	m map[driver.Conn]string
+}{m: make(map[driver.Conn]string)}
+
+// QueryID returns the connection id of conn, used to cancel the queries
+// executed on the connection. The id is retrieved once per driver connection,
+// and cached while the connection is valid, except for transactions, whose
+// driver connection is not accessible.
+func QueryID(ctx context.Context, conn drivers.Conn) (context.Context, string, error) {
+	var dc driver.Conn
+	if c, ok := conn.(*sql.Conn); ok {
+		if err := c.Raw(func(v interface{}) error {
+			dc, _ = v.(driver.Conn)
+			return nil
+		}); err != nil {
+			return nil, "", err
+		}
+	}
+	if dc != nil {
+		connIDs.Lock()
+		id, ok := connIDs.m[dc]
+		connIDs.Unlock()
+		if ok {
+			return ctx, id, nil
+		}
+	}
+	var id string
+	if err := conn.QueryRowContext(ctx, `SELECT CONNECTION_ID()`).Scan(&id); err != nil {
+		return nil, "", err
+	}
+	if dc != nil {
+		connIDs.Lock()
+		defer connIDs.Unlock()
+		// remove the closed connections, when the driver reports them
+		for c := range connIDs.m {
+			if v, ok := c.(driver.Validator); ok && !v.IsValid() {
+				delete(connIDs.m, c)
+			}
+		}
+		connIDs.m[dc] = id
+	}
+	return ctx, id, nil
+}
+
+// Cancel kills the query running on the connection with id.
+func Cancel(ctx context.Context, db drivers.DB, id string) error {
+	_, err := db.ExecContext(ctx, `KILL QUERY `+id)
+	return err
+}
+
+// StatementTimeout sets the max_execution_time of the session (applying to
+// SELECT statements), or the max_statement_time of the session with MariaDB.
+// Resets the session value to the global default when d is 0.
+func StatementTimeout(ctx context.Context, conn drivers.Conn, d time.Duration) (context.Context, error) {
+	ms, secs := "DEFAULT", "DEFAULT"
+	if d != 0 {
+		ms, secs = strconv.FormatInt(d.Milliseconds(), 10), strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	}
+	if _, err := conn.ExecContext(ctx, `SET SESSION max_execution_time = `+ms); err == nil {
+		return ctx, nil
+	}
+	_, err := conn.ExecContext(ctx, `SET SESSION max_statement_time = `+secs)
+	return ctx, err
+}
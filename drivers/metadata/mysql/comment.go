@@ -0,0 +1,13 @@
+package mysql
+
+import "errors"
+
+// CommentOn returns the statement setting the comment of a table. Column
+// comments are part of the column definition, and can only be set with ALTER
+// TABLE ... MODIFY COLUMN.
+func CommentOn(typ, name, comment string) (string, error) {
+	if typ != "TABLE" {
+		return "", errors.New("column comments can only be set with ALTER TABLE ... MODIFY COLUMN")
+	}
+	return "ALTER TABLE " + name + " COMMENT = " + QuoteLiteral(comment), nil
+}
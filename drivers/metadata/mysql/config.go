@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// configReader reads the server configuration of MySQL and MariaDB databases.
+type configReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.ServerConfigReader = &configReader{}
+
+// configQueries are the queries listing system variables, from the most to
+// the least detailed. MariaDB has the default values and the origin of the
+// values in information_schema.system_variables, while MySQL 8.0 and newer
+// only has the source of the values in performance_schema.variables_info.
+var configQueries = []string{
+	`SELECT
+  variable_name,
+  COALESCE(global_value, ''),
+  '',
+  COALESCE(default_value, ''),
+  CASE WHEN global_value_origin = 'COMPILE-TIME' THEN 'NO' ELSE 'YES' END,
+  global_value_origin,
+  COALESCE(variable_comment, '')
+FROM information_schema.system_variables
+WHERE variable_name LIKE ?
+ORDER BY variable_name`,
+	`SELECT
+  v.variable_name,
+  COALESCE(v.variable_value, ''),
+  '',
+  '',
+  CASE WHEN i.variable_source = 'COMPILED' THEN 'NO' ELSE 'YES' END,
+  i.variable_source,
+  ''
+FROM performance_schema.global_variables v
+JOIN performance_schema.variables_info i ON i.variable_name = v.variable_name
+WHERE v.variable_name LIKE ?
+ORDER BY v.variable_name`,
+	`SELECT
+  variable_name,
+  COALESCE(variable_value, ''),
+  '',
+  '',
+  '',
+  '',
+  ''
+FROM performance_schema.global_variables
+WHERE variable_name LIKE ?
+ORDER BY variable_name`,
+}
+
+func (r configReader) ServerConfig(f metadata.Filter) (*metadata.ServerConfigSet, error) {
+	name := f.Name
+	if name == "" {
+		name = "%"
+	}
+	var rows *sql.Rows
+	var closeRows metadata.CloseFunc
+	var err error
+	for _, qstr := range configQueries {
+		if rows, closeRows, err = r.Query(qstr, name); err == nil || err == sql.ErrNoRows {
+			break
+		}
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewServerConfigSet([]metadata.ServerConfig{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ServerConfig{}
+	for rows.Next() {
+		rec := metadata.ServerConfig{}
+		err = rows.Scan(&rec.Name, &rec.Value, &rec.Unit, &rec.Default, &rec.Changed, &rec.Source, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewServerConfigSet(results), nil
+}
@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// Explain retrieves the query plan for a query using EXPLAIN FORMAT=JSON, or
+// EXPLAIN ANALYZE when analyze is true.
+func Explain(ctx context.Context, db drivers.DB, query string, analyze bool) (*drivers.ExplainNode, error) {
+	if analyze {
+		rows, err := db.QueryContext(ctx, "EXPLAIN ANALYZE "+query)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var lines []string
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				return nil, err
+			}
+			lines = append(lines, s)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return drivers.ParseIndentedPlan("QUERY PLAN", lines), nil
+	}
+	var buf []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query).Scan(&buf); err != nil {
+		return nil, err
+	}
+	root := &drivers.ExplainNode{Name: "QUERY PLAN"}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := decodePlan(dec, root); err != nil {
+		return nil, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	return root, nil
+}
+
+// estimateRowsRE matches the estimated rows of an operation in a tree
+// formatted plan.
+var estimateRowsRE = regexp.MustCompile(`\brows=([0-9.e+]+)`)
+
+// EstimateRows retrieves the optimizer's estimated number of rows returned by
+// a query from the top-most operation of EXPLAIN FORMAT=TREE, without
+// executing the query. Requires MySQL 8.0.16 or newer.
+func EstimateRows(ctx context.Context, db drivers.Conn, query string) (int64, error) {
+	var plan string
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=TREE "+query).Scan(&plan); err != nil {
+		return 0, err
+	}
+	line, _, _ := strings.Cut(plan, "\n")
+	m := estimateRowsRE.FindStringSubmatch(line)
+	if m == nil {
+		return 0, fmt.Errorf("no row estimate in query plan")
+	}
+	f, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(f)), nil
+}
+
+// decodePlan decodes the next JSON object from dec into node, preserving the
+// order of the keys.
+func decodePlan(dec *json.Decoder, node *drivers.ExplainNode) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+	return decodeObject(dec, node)
+}
+
+// decodeObject decodes the remainder of an already opened JSON object. Scalar
+// values are added as details of node, while nested objects and arrays of
+// objects are added as children.
+func decodeObject(dec *json.Decoder, node *drivers.ExplainNode) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{':
+				err = decodeObject(dec, node.Add(key))
+			case '[':
+				err = decodeArray(dec, node, key)
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			s := fmt.Sprintf("%v", v)
+			if key == "table_name" {
+				node.Name += " " + s
+				continue
+			}
+			node.Details = append(node.Details, key+"="+s)
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// decodeArray decodes the remainder of an already opened JSON array, adding
+// objects as children of node and joining scalar values as a single detail.
+func decodeArray(dec *json.Decoder, node *drivers.ExplainNode, key string) error {
+	var vals []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			if v != '{' {
+				return fmt.Errorf("unexpected %v in %q", v, key)
+			}
+			if err := decodeObject(dec, node.Add(key)); err != nil {
+				return err
+			}
+		default:
+			vals = append(vals, fmt.Sprintf("%v", v))
+		}
+	}
+	if len(vals) != 0 {
+		node.Details = append(node.Details, key+"=["+strings.Join(vals, ", ")+"]")
+	}
+	_, err := dec.Token()
+	return err
+}
@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// indexStatReader reads the index usage statistics of MySQL and MariaDB
+// databases.
+type indexStatReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.IndexStatReader = &indexStatReader{}
+
+// IndexStats lists the usage statistics of indexes collected by the
+// performance schema (table_io_waits_summary_by_index_usage), with the least
+// used indexes first. The performance schema does not count index scans, so
+// the rows read through the index are used instead, and the time the index
+// was last used is not available. Sizes are those estimated by InnoDB.
+func (r indexStatReader) IndexStats(f metadata.Filter) (*metadata.IndexStatSet, error) {
+	qstr := `SELECT
+  p.object_schema,
+  p.object_name,
+  p.index_name,
+  p.count_read,
+  COALESCE(st.stat_value * @@innodb_page_size, -1),
+  p.index_name = 'PRIMARY' OR COALESCE(x.non_unique, 1) = 0,
+  COALESCE(CONCAT(x.index_type, ' (', x.cols, ')'), '')
+FROM performance_schema.table_io_waits_summary_by_index_usage p
+LEFT JOIN mysql.innodb_index_stats st ON st.database_name = p.object_schema
+  AND st.table_name = p.object_name
+  AND st.index_name = p.index_name
+  AND st.stat_name = 'size'
+LEFT JOIN (
+  SELECT
+    table_schema,
+    table_name,
+    index_name,
+    MIN(non_unique) AS non_unique,
+    MIN(index_type) AS index_type,
+    GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ', ') AS cols
+  FROM information_schema.statistics
+  GROUP BY table_schema, table_name, index_name
+) x ON x.table_schema = p.object_schema
+  AND x.table_name = p.object_name
+  AND x.index_name = p.index_name`
+	conds := []string{"p.object_type = 'TABLE'", "p.index_name IS NOT NULL"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "p.object_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "p.object_schema LIKE ?")
+	} else {
+		conds = append(conds, "p.object_schema LIKE COALESCE(DATABASE(), '%')")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name, f.Name)
+		conds = append(conds, "(p.object_name LIKE ? OR p.index_name LIKE ?)")
+	}
+	qstr += "\nWHERE " + strings.Join(conds, " AND ") + "\nORDER BY p.count_read, 5 DESC, p.object_schema, p.object_name, p.index_name"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewIndexStatSet([]metadata.IndexStat{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.IndexStat{}
+	for rows.Next() {
+		rec := metadata.IndexStat{}
+		var size int64
+		err = rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &rec.Scans, &size, &rec.Required, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		if size >= 0 {
+			rec.Size = metadata.FormatSize(size)
+		}
+		rec.RowsRead = strconv.FormatInt(rec.Scans, 10)
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewIndexStatSet(results), nil
+}
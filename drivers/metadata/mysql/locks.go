@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// lockReader reads the InnoDB locks of MySQL and MariaDB databases.
+type lockReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.LockReader = &lockReader{}
+
+// lockQueries are the queries listing locks, along with the expressions of
+// the schema and the object of the locks. MySQL 8.0 and newer has all locks
+// in performance_schema.data_locks, while MySQL 5.7 and MariaDB only have
+// the locks blocking or waiting on other locks in
+// information_schema.innodb_locks.
+var lockQueries = []struct {
+	query  string
+	schema string
+	object string
+	order  string
+}{
+	{
+		`SELECT
+  COALESCE(CAST(t.processlist_id AS CHAR), ''),
+  COALESCE(t.processlist_user, ''),
+  l.lock_type,
+  COALESCE(l.object_schema, ''),
+  COALESCE(l.object_name, ''),
+  l.lock_mode,
+  CASE WHEN l.lock_status = 'GRANTED' THEN 'YES' ELSE 'NO' END,
+  COALESCE((
+    SELECT GROUP_CONCAT(DISTINCT bt.processlist_id ORDER BY bt.processlist_id SEPARATOR ', ')
+    FROM performance_schema.data_lock_waits w
+         JOIN performance_schema.threads bt ON bt.thread_id = w.blocking_thread_id
+    WHERE w.requesting_engine_lock_id = l.engine_lock_id
+  ), ''),
+  COALESCE(t.processlist_info, '')
+FROM performance_schema.data_locks l
+     JOIN performance_schema.threads t ON t.thread_id = l.thread_id`,
+		"l.object_schema",
+		"l.object_name",
+		"t.processlist_id, l.lock_status, l.object_schema, l.object_name",
+	},
+	{
+		`SELECT
+  CAST(x.trx_mysql_thread_id AS CHAR),
+  COALESCE(p.user, ''),
+  l.lock_type,
+  SUBSTRING_INDEX(REPLACE(l.lock_table, '` + "`" + `', ''), '.', 1),
+  SUBSTRING_INDEX(REPLACE(l.lock_table, '` + "`" + `', ''), '.', -1),
+  l.lock_mode,
+  CASE WHEN x.trx_requested_lock_id = l.lock_id THEN 'NO' ELSE 'YES' END,
+  COALESCE((
+    SELECT GROUP_CONCAT(DISTINCT bx.trx_mysql_thread_id ORDER BY bx.trx_mysql_thread_id SEPARATOR ', ')
+    FROM information_schema.innodb_lock_waits w
+         JOIN information_schema.innodb_trx bx ON bx.trx_id = w.blocking_trx_id
+    WHERE w.requested_lock_id = l.lock_id
+  ), ''),
+  COALESCE(x.trx_query, '')
+FROM information_schema.innodb_locks l
+     JOIN information_schema.innodb_trx x ON x.trx_id = l.lock_trx_id
+     LEFT JOIN information_schema.processlist p ON p.id = x.trx_mysql_thread_id`,
+		"SUBSTRING_INDEX(REPLACE(l.lock_table, '`', ''), '.', 1)",
+		"SUBSTRING_INDEX(REPLACE(l.lock_table, '`', ''), '.', -1)",
+		"1, 7 DESC, 4, 5",
+	},
+}
+
+func (r lockReader) Locks(f metadata.Filter) (*metadata.LockSet, error) {
+	var rows *sql.Rows
+	var closeRows metadata.CloseFunc
+	var err error
+	for _, q := range lockQueries {
+		conds := []string{}
+		vals := []interface{}{}
+		if f.Schema != "" {
+			vals = append(vals, f.Schema)
+			conds = append(conds, q.schema+" LIKE ?")
+		}
+		if f.Name != "" {
+			vals = append(vals, f.Name)
+			conds = append(conds, q.object+" LIKE ?")
+		}
+		qstr := q.query
+		if len(conds) != 0 {
+			qstr += "\nWHERE " + strings.Join(conds, " AND ")
+		}
+		qstr += "\nORDER BY " + q.order
+		if rows, closeRows, err = r.Query(qstr, vals...); err == nil || err == sql.ErrNoRows {
+			break
+		}
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewLockSet([]metadata.Lock{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Lock{}
+	for rows.Next() {
+		rec := metadata.Lock{}
+		err = rows.Scan(&rec.Session, &rec.User, &rec.Type, &rec.Schema, &rec.Object, &rec.Mode, &rec.Granted, &rec.BlockedBy, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewLockSet(results), nil
+}
@@ -12,13 +12,47 @@ import (
 
 var (
 	// NewReader for MySQL databases
-	NewReader = infos.New(
+	NewReader = func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+		return metadata.NewPluginReader(
+			newISReader(db, opts...),
+			&spatialReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&configReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&activityReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&lockReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&sizeReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&indexStatReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&partitionReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&roleReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+			&schemaReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+		)
+	}
+	newISReader = infos.New(
 		infos.WithPlaceholder(func(int) string { return "?" }),
 		infos.WithSequences(false),
 		infos.WithCheckConstraints(false),
 		infos.WithCustomClauses(map[infos.ClauseName]string{
 			infos.ColumnsDataType:                 "column_type",
 			infos.ColumnsNumericPrecRadix:         "10",
+			infos.ColumnsComment:                  "column_comment",
+			infos.TablesComment:                   "table_comment",
 			infos.FunctionColumnsNumericPrecRadix: "10",
 			infos.ConstraintIsDeferrable:          "''",
 			infos.ConstraintInitiallyDeferred:     "''",
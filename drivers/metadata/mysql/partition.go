@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// partitionReader reads the table partitions of MySQL and MariaDB databases.
+type partitionReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.PartitionReader = &partitionReader{}
+
+// Partitions of tables, with their sizes as estimated by the storage engine.
+// Subpartitions are listed individually, named after the partition they
+// belong to.
+func (r partitionReader) Partitions(f metadata.Filter) (*metadata.PartitionSet, error) {
+	qstr := `SELECT
+  table_catalog,
+  table_schema,
+  table_name,
+  COALESCE(CONCAT(partition_name, '.', subpartition_name), partition_name),
+  CASE WHEN subpartition_method IS NULL THEN partition_method
+    ELSE CONCAT(partition_method, ' SUBPARTITION BY ', subpartition_method) END,
+  CASE WHEN subpartition_expression IS NULL THEN COALESCE(partition_expression, '')
+    ELSE CONCAT(partition_expression, ') (', subpartition_expression) END,
+  CASE WHEN partition_method LIKE 'RANGE%' THEN CONCAT('VALUES LESS THAN (', partition_description, ')')
+    WHEN partition_method LIKE 'LIST%' THEN CONCAT('VALUES IN (', partition_description, ')')
+    ELSE '' END,
+  COALESCE(table_rows, -1),
+  COALESCE(data_length, 0) + COALESCE(index_length, 0)
+FROM information_schema.partitions`
+	conds := []string{"partition_name IS NOT NULL"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "table_schema LIKE ?")
+	} else {
+		conds = append(conds, "table_schema LIKE COALESCE(DATABASE(), '%')")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "table_name LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "partition_name LIKE ?")
+	}
+	qstr += "\nWHERE " + strings.Join(conds, " AND ") + "\nORDER BY table_schema, table_name, partition_ordinal_position, subpartition_ordinal_position"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewPartitionSet([]metadata.Partition{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Partition{}
+	for rows.Next() {
+		rec := metadata.Partition{}
+		var n, size int64
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Method, &rec.Key, &rec.Bound, &n, &size)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 0 {
+			rec.Rows = strconv.FormatInt(n, 10)
+		}
+		rec.Size = metadata.FormatSize(size)
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewPartitionSet(results), nil
+}
@@ -0,0 +1,19 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// ChangePassword changes the password for the user account. The user may be
+// specified as user@host, as returned by CURRENT_USER, otherwise the account
+// is matched by the user name only.
+func ChangePassword(db drivers.DB, user, newpw, _ string) error {
+	account := QuoteLiteral(user)
+	if i := strings.LastIndex(user, "@"); i != -1 {
+		account = QuoteLiteral(user[:i]) + "@" + QuoteLiteral(user[i+1:])
+	}
+	_, err := db.Exec(`ALTER USER ` + account + ` IDENTIFIED BY ` + QuoteLiteral(newpw))
+	return err
+}
@@ -0,0 +1,21 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// OutParams returns the query selecting the values of the user variables
+// passed to a stored procedure call, such as the variables receiving its OUT
+// and INOUT parameters.
+func OutParams(sqlstr string) (string, string) {
+	var names []string
+	for _, v := range drivers.Variables(sqlstr) {
+		names = append(names, "@"+v.Name)
+	}
+	if len(names) == 0 {
+		return sqlstr, ""
+	}
+	return sqlstr, "SELECT " + strings.Join(names, ", ")
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import "strings"
+
+// literalReplacer escapes the special characters of a string literal.
+var literalReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+// QuoteLiteral quotes s as a string literal, escaping backslashes and quotes
+// as the server treats backslashes as escape characters by default.
+func QuoteLiteral(s string) string {
+	return "'" + literalReplacer.Replace(s) + "'"
+}
+
+// QuoteIdentifier quotes s as an identifier using backticks.
+func QuoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// roleReader reads the accounts and roles of MySQL and MariaDB databases.
+type roleReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.RoleReader = &roleReader{}
+
+// Roles lists the accounts of mysql.user, with the roles granted to them.
+// Roles are granted from mysql.role_edges on MySQL, and from
+// mysql.roles_mapping on MariaDB. Accounts that are locked (including MySQL
+// roles) cannot log in.
+func (r roleReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	qstr := `SELECT
+  User,
+  Host,
+  account_locked,
+  Super_priv,
+  Grant_priv,
+  Create_user_priv,
+  password_expired,
+  COALESCE(CASE WHEN password_lifetime > 0
+    THEN CAST(DATE_ADD(password_last_changed, INTERVAL password_lifetime DAY) AS CHAR) END, '')
+FROM mysql.user`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "User NOT IN ('mysql.sys', 'mysql.session', 'mysql.infoschema', 'mariadb.sys')")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "User LIKE ?")
+	}
+	if len(conds) != 0 {
+		qstr += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += "\nORDER BY User, Host"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewRoleSet([]metadata.Role{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Role{}
+	for rows.Next() {
+		var user, host, locked, super, grant, createUser, expired string
+		rec := metadata.Role{Login: metadata.YES, Superuser: metadata.NO}
+		err = rows.Scan(&user, &host, &locked, &super, &grant, &createUser, &expired, &rec.ValidUntil)
+		if err != nil {
+			return nil, err
+		}
+		rec.Name = account(user, host)
+		if locked == "Y" {
+			rec.Login = metadata.NO
+		}
+		if super == "Y" {
+			rec.Superuser = metadata.YES
+		}
+		var attrs []string
+		if grant == "Y" {
+			attrs = append(attrs, "Grant option")
+		}
+		if createUser == "Y" {
+			attrs = append(attrs, "Create user")
+		}
+		if expired == "Y" {
+			attrs = append(attrs, "Password expired")
+		}
+		rec.Attributes = strings.Join(attrs, ", ")
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	members, err := r.memberships()
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].MemberOf = strings.Join(members[results[i].Name], ", ")
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// memberships returns the roles granted to each account. No roles are
+// returned when neither mysql.role_edges nor mysql.roles_mapping can be read,
+// as on servers without roles.
+func (r roleReader) memberships() (map[string][]string, error) {
+	rows, closeRows, err := r.Query(`SELECT TO_USER, TO_HOST, FROM_USER, FROM_HOST, WITH_ADMIN_OPTION
+FROM mysql.role_edges
+ORDER BY FROM_USER, FROM_HOST`)
+	if err != nil {
+		// MariaDB roles have no host
+		rows, closeRows, err = r.Query(`SELECT User, Host, Role, '', Admin_option
+FROM mysql.roles_mapping
+ORDER BY Role`)
+	}
+	if err != nil {
+		return nil, nil
+	}
+	defer closeRows()
+
+	members := map[string][]string{}
+	for rows.Next() {
+		var user, host, role, roleHost, admin string
+		if err := rows.Scan(&user, &host, &role, &roleHost, &admin); err != nil {
+			return nil, err
+		}
+		name := role
+		if roleHost != "" {
+			name = account(role, roleHost)
+		}
+		if admin == "Y" {
+			name += " (admin)"
+		}
+		members[account(user, host)] = append(members[account(user, host)], name)
+	}
+	return members, rows.Err()
+}
+
+// account returns the account name of user at host.
+func account(user, host string) string {
+	return QuoteLiteral(user) + "@" + QuoteLiteral(host)
+}
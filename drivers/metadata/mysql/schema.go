@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// schemaReader reads the current database of MySQL and MariaDB sessions.
+type schemaReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.CurrentSchemaReader = &schemaReader{}
+
+// CurrentSchema returns the current (default) database of the session, as
+// selected with USE, or nothing when no database was selected.
+func (r schemaReader) CurrentSchema() ([]string, error) {
+	rows, closeRows, err := r.Query(`SELECT DATABASE()`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var path []string
+	for rows.Next() {
+		var schema sql.NullString
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		if schema.Valid {
+			path = append(path, schema.String)
+		}
+	}
+	return path, rows.Err()
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// sizeReader reads the table sizes of MySQL and MariaDB databases.
+type sizeReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.SizeReader = &sizeReader{}
+
+// Sizes of tables, as estimated by the storage engine. The sizes of
+// individual indexes are not available, with the free space allocated to a
+// table (ie, after deleting rows) used as the bloat estimate.
+func (r sizeReader) Sizes(f metadata.Filter) (*metadata.SizeSet, error) {
+	qstr := `SELECT
+  table_catalog,
+  table_schema,
+  table_name,
+  COALESCE(table_rows, -1),
+  COALESCE(data_length, 0),
+  COALESCE(index_length, 0),
+  COALESCE(data_free, 0)
+FROM information_schema.tables`
+	conds := []string{"table_type = 'BASE TABLE'"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "table_schema LIKE ?")
+	} else {
+		conds = append(conds, "table_schema LIKE COALESCE(DATABASE(), '%')")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "table_name LIKE ?")
+	}
+	qstr += "\nWHERE " + strings.Join(conds, " AND ") + "\nORDER BY data_length + index_length DESC, table_schema, table_name"
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSizeSet([]metadata.Size{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Size{}
+	for rows.Next() {
+		rec := metadata.Size{Type: "TABLE"}
+		var n, data, index, free int64
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &n, &data, &index, &free)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 0 {
+			rec.Rows = strconv.FormatInt(n, 10)
+		}
+		rec.Size, rec.IndexSize, rec.TotalSize = metadata.FormatSize(data), metadata.FormatSize(index), metadata.FormatSize(data+index)
+		if total := data + index + free; free != 0 && total != 0 {
+			rec.Bloat = fmt.Sprintf("%.1f%% free", 100*float64(free)/float64(total))
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSizeSet(results), nil
+}
@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+// spatialReader reads the spatial columns of MySQL and MariaDB databases.
+type spatialReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.SpatialColumnReader = &spatialReader{}
+
+func (r spatialReader) SpatialColumns(f metadata.Filter) (*metadata.SpatialColumnSet, error) {
+	// SRS_ID is only available in MySQL 8.0 and newer
+	rows, closeRows, err := r.spatialColumns(f, "CAST(c.SRS_ID AS CHAR)")
+	if err != nil && err != sql.ErrNoRows {
+		rows, closeRows, err = r.spatialColumns(f, "NULL")
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSpatialColumnSet([]metadata.SpatialColumn{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.SpatialColumn{}
+	for rows.Next() {
+		rec := metadata.SpatialColumn{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Type, &rec.SRID, &rec.Dimensions, &rec.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSpatialColumnSet(results), nil
+}
+
+func (r spatialReader) spatialColumns(f metadata.Filter, srid string) (*sql.Rows, metadata.CloseFunc, error) {
+	qstr := `SELECT
+  c.table_catalog,
+  c.table_schema,
+  c.table_name,
+  c.column_name,
+  c.data_type,
+  COALESCE(` + srid + `, ''),
+  '2',
+  COALESCE((
+    SELECT GROUP_CONCAT(s.index_name ORDER BY s.index_name SEPARATOR ', ')
+    FROM information_schema.statistics s
+    WHERE s.table_schema = c.table_schema
+      AND s.table_name = c.table_name
+      AND s.column_name = c.column_name
+      AND s.index_type = 'SPATIAL'
+  ), '')
+FROM information_schema.columns c`
+	conds := []string{"c.data_type IN ('geometry', 'point', 'linestring', 'polygon', 'multipoint', 'multilinestring', 'multipolygon', 'geometrycollection', 'geomcollection')"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "c.table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, "c.table_schema LIKE ?")
+	} else {
+		conds = append(conds, "c.table_schema LIKE COALESCE(DATABASE(), '%')")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "c.table_name LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "c.column_name LIKE ?")
+	}
+	qstr += "\nWHERE " + strings.Join(conds, " AND ") + "\nORDER BY c.table_schema, c.table_name, c.ordinal_position"
+	return r.Query(qstr, vals...)
+}
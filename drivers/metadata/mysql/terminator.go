@@ -0,0 +1,24 @@
+package mysql
+
+import "github.com/ildus/usql/stmt"
+
+// Terminator handles the DELIMITER command of the mysql command-line client,
+// and BEGIN ... END blocks in stored program definitions.
+var Terminator = &stmt.Terminator{
+	Delimiter: true,
+	Blocks:    true,
+	BlockPrefixes: []string{
+		"CREATE PROCEDURE",
+		"CREATE FUNCTION",
+		"CREATE AGGREGATE FUNCTION",
+		"CREATE TRIGGER",
+		"CREATE EVENT",
+		"CREATE DEFINER",
+		"CREATE OR REPLACE PROCEDURE",
+		"CREATE OR REPLACE FUNCTION",
+		"CREATE OR REPLACE TRIGGER",
+		"CREATE OR REPLACE EVENT",
+		"ALTER EVENT",
+		"BEGIN NOT ATOMIC",
+	},
+}
@@ -0,0 +1,125 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/text"
+)
+
+var _ metadata.ActivityReader = &metaReader{}
+
+func (r metaReader) Activity(f metadata.Filter) (*metadata.ActivitySet, error) {
+	qstr := `SELECT
+  s.sid || ',' || s.serial#,
+  COALESCE(s.username, ''),
+  COALESCE(s.schemaname, ''),
+  COALESCE(s.machine, '') || CASE WHEN s.program IS NOT NULL THEN ' (' || s.program || ')' END,
+  s.status,
+  COALESCE(TO_CHAR(s.sql_exec_start, 'YYYY-MM-DD HH24:MI:SS'), ''),
+  TO_CHAR(NUMTODSINTERVAL(s.last_call_et, 'SECOND')),
+  COALESCE(q.sql_text, '')
+FROM v$session s
+LEFT JOIN v$sql q ON q.sql_id = s.sql_id AND q.child_number = s.sql_child_number
+`
+	conds, vals := r.conditions(f, formats{
+		name: "s.username LIKE :%d",
+	})
+	conds = append(conds, "s.type = 'USER'")
+	qstr += " WHERE " + strings.Join(conds, " AND ")
+	qstr += `
+ORDER BY s.last_call_et DESC, s.sid`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewActivitySet([]metadata.Activity{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Activity{}
+	for rows.Next() {
+		rec := metadata.Activity{}
+		err = rows.Scan(&rec.ID, &rec.User, &rec.Database, &rec.Client, &rec.State, &rec.Started, &rec.Duration, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewActivitySet(results), nil
+}
+
+// sessionIDRE matches session ids, as the sid and serial# of the session.
+var sessionIDRE = regexp.MustCompile(`^\d+,\d+$`)
+
+// Kill kills the session with id, as listed by \dactivity.
+func Kill(ctx context.Context, db drivers.DB, id string) error {
+	if !sessionIDRE.MatchString(id) {
+		return text.ErrNoSuchSession
+	}
+	_, err := db.ExecContext(ctx, `ALTER SYSTEM KILL SESSION '`+id+`' IMMEDIATE`)
+	return err
+}
+
+var _ metadata.LockReader = &metaReader{}
+
+func (r metaReader) Locks(f metadata.Filter) (*metadata.LockSet, error) {
+	qstr := `SELECT
+  s.sid || ',' || s.serial#,
+  COALESCE(s.username, ''),
+  l.type,
+  COALESCE(o.owner, ''),
+  COALESCE(o.object_name, ''),
+  DECODE(CASE WHEN l.request > 0 THEN l.request ELSE l.lmode END,
+    1, 'Null', 2, 'Row-S (SS)', 3, 'Row-X (SX)', 4, 'Share', 5, 'S/Row-X (SSX)', 6, 'Exclusive', 'None'),
+  CASE WHEN l.request > 0 THEN 'NO' ELSE 'YES' END,
+  CASE WHEN l.request > 0 THEN (
+    SELECT b.sid || ',' || b.serial# FROM v$session b WHERE b.sid = s.blocking_session
+  ) END,
+  COALESCE(q.sql_text, '')
+FROM v$lock l
+JOIN v$session s ON s.sid = l.sid
+LEFT JOIN all_objects o ON o.object_id = l.id1 AND l.type = 'TM'
+LEFT JOIN v$sql q ON q.sql_id = s.sql_id AND q.child_number = s.sql_child_number
+`
+	conds, vals := r.conditions(f, formats{
+		schema: "o.owner LIKE %s",
+		name:   "o.object_name LIKE :%d",
+	})
+	conds = append(conds, "s.type = 'USER'", "l.type IN ('TM', 'TX', 'UL')")
+	qstr += " WHERE " + strings.Join(conds, " AND ")
+	qstr += `
+ORDER BY s.sid, l.request, o.owner, o.object_name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewLockSet([]metadata.Lock{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Lock{}
+	for rows.Next() {
+		var blockedBy sql.NullString
+		rec := metadata.Lock{}
+		err = rows.Scan(&rec.Session, &rec.User, &rec.Type, &rec.Schema, &rec.Object, &rec.Mode, &rec.Granted, &blockedBy, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		rec.BlockedBy = blockedBy.String
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewLockSet(results), nil
+}
@@ -0,0 +1,90 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// Explain retrieves the query plan for a query using EXPLAIN PLAN, reading
+// the resulting operations back from the session's PLAN_TABLE.
+func Explain(ctx context.Context, db drivers.DB, query string, analyze bool) (*drivers.ExplainNode, error) {
+	if analyze {
+		return nil, text.ErrExplainAnalyzeNotSupported
+	}
+	// the plan table is session specific, so make sure to use a single
+	// connection when given a pool
+	var conn interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+		QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	} = db
+	if pool, ok := db.(*sql.DB); ok {
+		c, err := pool.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		conn = c
+	}
+	id := fmt.Sprintf("%s_%d", text.CommandLower(), time.Now().UnixNano())
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if _, err := conn.ExecContext(ctx, `EXPLAIN PLAN SET STATEMENT_ID = '`+id+`' FOR `+query); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(context.Background(), `DELETE FROM plan_table WHERE statement_id = :1`, id)
+	rows, err := conn.QueryContext(ctx, `SELECT
+  id,
+  COALESCE(parent_id, -1),
+  TRIM(operation || ' ' || options),
+  COALESCE(object_name, ''),
+  COALESCE(cost, -1),
+  COALESCE(cardinality, -1),
+  COALESCE(access_predicates, ''),
+  COALESCE(filter_predicates, '')
+FROM plan_table
+WHERE statement_id = :1
+ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	root := &drivers.ExplainNode{Name: "QUERY PLAN"}
+	nodes := map[int64]*drivers.ExplainNode{-1: root}
+	for rows.Next() {
+		var id, parentID, cost, cardinality int64
+		var operation, object, access, filter string
+		if err := rows.Scan(&id, &parentID, &operation, &object, &cost, &cardinality, &access, &filter); err != nil {
+			return nil, err
+		}
+		if object != "" {
+			operation += " " + object
+		}
+		var details []string
+		if cost != -1 {
+			details = append(details, fmt.Sprintf("cost=%d", cost))
+		}
+		if cardinality != -1 {
+			details = append(details, fmt.Sprintf("rows=%d", cardinality))
+		}
+		if access != "" {
+			details = append(details, "access: "+access)
+		}
+		if filter != "" {
+			details = append(details, "filter: "+filter)
+		}
+		parent, ok := nodes[parentID]
+		if !ok {
+			parent = root
+		}
+		nodes[id] = parent.Add(operation, details...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
@@ -18,6 +18,12 @@ type metaReader struct {
 var _ metadata.BasicReader = &metaReader{}
 var _ metadata.IndexReader = &metaReader{}
 var _ metadata.IndexColumnReader = &metaReader{}
+var _ metadata.MaterializedViewReader = &metaReader{}
+var _ metadata.ServerConfigReader = &metaReader{}
+var _ metadata.PartitionReader = &metaReader{}
+var _ metadata.CurrentSchemaReader = &metaReader{}
+var _ metadata.TypeReader = &metaReader{}
+var _ metadata.DependencyReader = &metaReader{}
 
 func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 	return func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
@@ -108,8 +114,10 @@ func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
 	qstr := `SELECT
 o.owner AS table_schem,
 o.object_name AS table_name,
-o.object_type AS table_type
+o.object_type AS table_type,
+tc.comments AS table_comment
 FROM all_objects o
+LEFT JOIN all_tab_comments tc ON tc.owner = o.owner AND tc.table_name = o.object_name
 `
 	conds, vals := r.conditions(f, formats{
 		schema:     "o.owner LIKE %s",
@@ -132,7 +140,8 @@ UNION ALL
 SELECT
   s.owner AS table_schem,
   s.synonym_name AS table_name,
-  'SYNONYM' AS table_type
+  'SYNONYM' AS table_type,
+  NULL AS table_comment
 FROM all_synonyms s
 `
 		conds, seqVals := r.conditions(f, formats{
@@ -159,10 +168,12 @@ ORDER BY table_schem, table_name, table_type`
 	results := []metadata.Table{}
 	for rows.Next() {
 		rec := metadata.Table{}
-		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Type)
+		var comment sql.NullString
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &comment)
 		if err != nil {
 			return nil, err
 		}
+		rec.Comment = comment.String
 		results = append(results, rec)
 	}
 	if rows.Err() != nil {
@@ -187,8 +198,10 @@ func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
            WHEN 'FLOAT'  THEN  2
            WHEN 'NUMBER' THEN 10
   ELSE  0  END AS num_prec_radix,
-  COALESCE(c.char_col_decl_length, 0) as char_octet_length
+  COALESCE(c.char_col_decl_length, 0) as char_octet_length,
+  cc.comments
 FROM all_tab_columns c
+LEFT JOIN all_col_comments cc ON cc.owner = c.owner AND cc.table_name = c.table_name AND cc.column_name = c.column_name
 `
 	conds, vals := r.conditions(f, formats{
 		schema:     "c.owner LIKE %s",
@@ -212,6 +225,7 @@ ORDER BY c.owner, c.table_name, c.column_id`
 	results := []metadata.Column{}
 	for rows.Next() {
 		rec := metadata.Column{}
+		var comment sql.NullString
 		targets := []interface{}{
 			&rec.Schema,
 			&rec.Table,
@@ -223,11 +237,13 @@ ORDER BY c.owner, c.table_name, c.column_id`
 			&rec.DecimalDigits,
 			&rec.NumPrecRadix,
 			&rec.CharOctetLength,
+			&comment,
 		}
 		err = rows.Scan(targets...)
 		if err != nil {
 			return nil, err
 		}
+		rec.Comment = comment.String
 		results = append(results, rec)
 	}
 	if rows.Err() != nil {
@@ -434,6 +450,289 @@ ORDER BY o.owner, o.table_name, o.index_name, b.column_position`
 	return metadata.NewIndexColumnSet(results), nil
 }
 
+func (r metaReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  o.owner,
+  o.mview_name,
+  o.owner,
+  CASE WHEN o.last_refresh_date IS NULL THEN 'NO' ELSE 'YES' END,
+  COALESCE(TO_CHAR(o.last_refresh_date, 'YYYY-MM-DD HH24:MI:SS'), ''),
+  o.refresh_mode || ' ' || o.refresh_method || ' (' || o.staleness || ')',
+  COALESCE(c.comments, ''),
+  COALESCE(o.query, '')
+FROM all_mviews o
+LEFT JOIN all_mview_comments c ON o.owner = c.owner AND o.mview_name = c.mview_name
+`
+	conds, vals := r.conditions(f, formats{
+		schema:     "o.owner LIKE %s",
+		notSchemas: "o.owner NOT IN (%s)",
+		name:       "o.mview_name LIKE :%d",
+	})
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += `
+ORDER BY o.owner, o.mview_name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Owner, &rec.IsPopulated, &rec.LastRefresh, &rec.RefreshMode, &rec.Comment, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
+// Partitions lists the partitions of tables, with their row counts and sizes
+// from the optimizer statistics. The bounds are read separately from the
+// partition values, as HIGH_VALUE is a LONG column.
+func (r metaReader) Partitions(f metadata.Filter) (*metadata.PartitionSet, error) {
+	qstr := `SELECT
+  o.owner,
+  o.table_name,
+  p.partition_name,
+  o.partitioning_type || CASE WHEN o.subpartitioning_type <> 'NONE' THEN ' SUBPARTITION BY ' || o.subpartitioning_type END,
+  (SELECT LISTAGG(k.column_name, ', ') WITHIN GROUP (ORDER BY k.column_position)
+    FROM all_part_key_columns k
+    WHERE k.owner = o.owner AND k.name = o.table_name AND k.object_type = 'TABLE'),
+  p.high_value,
+  TO_CHAR(p.num_rows),
+  COALESCE(p.blocks * t.block_size, -1)
+FROM all_part_tables o
+JOIN all_tab_partitions p ON p.table_owner = o.owner AND p.table_name = o.table_name
+LEFT JOIN user_tablespaces t ON t.tablespace_name = p.tablespace_name
+`
+	conds, vals := r.conditions(f, formats{
+		schema:     "o.owner LIKE %s",
+		notSchemas: "o.owner NOT IN (%s)",
+		parent:     "o.table_name LIKE :%d",
+		name:       "p.partition_name LIKE :%d",
+	})
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += `
+ORDER BY o.owner, o.table_name, p.partition_position`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewPartitionSet([]metadata.Partition{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Partition{}
+	for rows.Next() {
+		rec := metadata.Partition{}
+		var key, value, n sql.NullString
+		var size int64
+		err = rows.Scan(&rec.Schema, &rec.Table, &rec.Name, &rec.Method, &key, &value, &n, &size)
+		if err != nil {
+			return nil, err
+		}
+		rec.Key, rec.Rows = key.String, n.String
+		switch {
+		case value.String == "":
+		case strings.HasPrefix(rec.Method, "RANGE"):
+			rec.Bound = "VALUES LESS THAN (" + value.String + ")"
+		case strings.HasPrefix(rec.Method, "LIST"):
+			rec.Bound = "VALUES (" + value.String + ")"
+		}
+		if size >= 0 {
+			rec.Size = metadata.FormatSize(size)
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewPartitionSet(results), nil
+}
+
+// Dependencies lists the objects referenced by views, materialized views,
+// and PL/SQL objects, as recorded in all_dependencies.
+func (r metaReader) Dependencies(f metadata.Filter) (*metadata.DependencySet, error) {
+	qstr := `SELECT DISTINCT
+  d.owner,
+  d.name,
+  d.type,
+  d.referenced_owner,
+  d.referenced_name,
+  d.referenced_type
+FROM all_dependencies d
+`
+	// filter by the referenced object, when set
+	fmts := formats{
+		schema:     "d.owner LIKE %s",
+		notSchemas: "d.owner NOT IN (%[1]s) AND d.referenced_owner NOT IN (%[1]s, 'PUBLIC')",
+		name:       "d.name LIKE :%d",
+	}
+	if f.Reference != "" {
+		fmts.schema, fmts.name = "d.referenced_owner LIKE %s", "d.referenced_name LIKE :%d"
+		f.Name = f.Reference
+	}
+	conds, vals := r.conditions(f, fmts)
+	conds = append(conds, "d.referenced_type <> 'NON-EXISTENT'")
+	qstr += " WHERE " + strings.Join(conds, " AND ")
+	qstr += `
+ORDER BY d.owner, d.name, d.referenced_owner, d.referenced_name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewDependencySet([]metadata.Dependency{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Dependency{}
+	for rows.Next() {
+		rec := metadata.Dependency{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.RefSchema, &rec.RefName, &rec.RefType)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewDependencySet(results), nil
+}
+
+// Types lists the object and collection types, with the attributes of object
+// types, and the element type of collections.
+func (r metaReader) Types(f metadata.Filter) (*metadata.TypeSet, error) {
+	qstr := `SELECT
+  t.owner,
+  t.type_name,
+  LOWER(t.typecode),
+  CASE WHEN t.typecode = 'COLLECTION' THEN
+    (SELECT CASE WHEN c.coll_type = 'TABLE' THEN 'TABLE OF ' ELSE 'VARRAY(' || c.upper_bound || ') OF ' END || c.elem_type_name
+      FROM all_coll_types c
+      WHERE c.owner = t.owner AND c.type_name = t.type_name)
+  ELSE
+    (SELECT LISTAGG(a.attr_name || ' ' || a.attr_type_name, ', ') WITHIN GROUP (ORDER BY a.attr_no)
+      FROM all_type_attrs a
+      WHERE a.owner = t.owner AND a.type_name = t.type_name)
+  END,
+  t.owner
+FROM all_types t
+`
+	conds, vals := r.conditions(f, formats{
+		schema:     "t.owner LIKE %s",
+		notSchemas: "t.owner NOT IN (%s)",
+		name:       "t.type_name LIKE :%d",
+	})
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += `
+ORDER BY t.owner, t.type_name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTypeSet([]metadata.Type{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Type{}
+	for rows.Next() {
+		rec := metadata.Type{}
+		var def sql.NullString
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Kind, &def, &rec.Owner)
+		if err != nil {
+			return nil, err
+		}
+		rec.Definition = def.String
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTypeSet(results), nil
+}
+
+func (r metaReader) ServerConfig(f metadata.Filter) (*metadata.ServerConfigSet, error) {
+	qstr := `SELECT
+  o.name,
+  COALESCE(o.display_value, ''),
+  '',
+  CASE WHEN o.isdefault = 'TRUE' THEN 'NO' ELSE 'YES' END,
+  CASE WHEN o.ismodified <> 'FALSE' THEN 'SESSION' WHEN o.isdefault = 'TRUE' THEN 'DEFAULT' ELSE 'CONFIG' END,
+  COALESCE(o.description, '')
+FROM v$parameter o
+`
+	conds, vals := r.conditions(f, formats{
+		name: "UPPER(o.name) LIKE :%d",
+	})
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += `
+ORDER BY o.name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewServerConfigSet([]metadata.ServerConfig{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ServerConfig{}
+	for rows.Next() {
+		rec := metadata.ServerConfig{}
+		err = rows.Scan(&rec.Name, &rec.Value, &rec.Unit, &rec.Changed, &rec.Source, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewServerConfigSet(results), nil
+}
+
+// CurrentSchema returns the current schema of the session, as set by ALTER
+// SESSION SET CURRENT_SCHEMA.
+func (r metaReader) CurrentSchema() ([]string, error) {
+	rows, closeRows, err := r.Query(`SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM dual`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var path []string
+	for rows.Next() {
+		var schema sql.NullString
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		if schema.Valid {
+			path = append(path, schema.String)
+		}
+	}
+	return path, rows.Err()
+}
+
 func (r metaReader) conditions(filter metadata.Filter, formats formats) ([]string, []interface{}) {
 	baseParam := 1
 	conds := []string{}
@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a catalog.schema.name search pattern of the listing commands, as
+// passed to the readers in a Filter. As with LIKE, % (or *) matches any
+// characters, and _ matches a single character.
+type Pattern struct {
+	Catalog string
+	Schema  string
+	Name    string
+	// Exclude is true for patterns prefixed with -, excluding the objects
+	// they match.
+	Exclude bool
+	// re matches the catalog, schema, and name of the pattern, when not
+	// empty.
+	re [3]*regexp.Regexp
+}
+
+// ParsePatterns parses a comma separated list of search patterns, such as
+// app*.*,-*_archive, listing the objects matching any of the patterns not
+// prefixed with -, and not matching any of the patterns prefixed with -. A
+// list without include patterns includes the objects matched by an empty
+// pattern.
+func ParsePatterns(s string) ([]Pattern, error) {
+	var patterns []Pattern
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		exclude := strings.HasPrefix(v, "-")
+		if exclude {
+			if v = strings.TrimPrefix(v, "-"); v == "" {
+				return nil, fmt.Errorf("empty exclude pattern")
+			}
+		}
+		cp, sp, tp, err := parsePattern(v)
+		if err != nil {
+			return nil, err
+		}
+		p := Pattern{Catalog: cp, Schema: sp, Name: tp, Exclude: exclude}
+		for i, part := range []string{cp, sp, tp} {
+			if part != "" {
+				p.re[i] = likeRegexp(part)
+			}
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Match returns true when the catalog, schema, and name match the pattern.
+// Empty parts of the pattern match any value.
+func (p Pattern) Match(catalog, schema, name string) bool {
+	for i, v := range []string{catalog, schema, name} {
+		if p.re[i] != nil && !p.re[i].MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// likeRegexp compiles a LIKE pattern to a regexp.
+func likeRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteByte('.')
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteByte('$')
+	return regexp.MustCompile(sb.String())
+}
+
+// patternSet is a result set read for each of the include patterns of a list
+// of search patterns.
+type patternSet interface {
+	base() *resultSet
+}
+
+func (r *resultSet) base() *resultSet {
+	return r
+}
+
+// readPatterns reads the results matching any of the include patterns, and
+// not matching any of the exclude patterns. As readers only support a single
+// pattern, the results are read for each include pattern, in order, removing
+// the duplicates. names returns the catalog, schema, and name of a result
+// matched by the exclude patterns.
+func readPatterns[S patternSet](patterns []Pattern, read func(Pattern) (S, error), names func(Result) (string, string, string)) (S, error) {
+	var includes, excludes []Pattern
+	for _, p := range patterns {
+		if p.Exclude {
+			excludes = append(excludes, p)
+		} else {
+			includes = append(includes, p)
+		}
+	}
+	if len(includes) == 0 {
+		includes = []Pattern{{}}
+	}
+	var res S
+	var results []Result
+	seen := make(map[string]bool)
+	for i, p := range includes {
+		s, err := read(p)
+		if err != nil {
+			return res, err
+		}
+		if i == 0 {
+			res = s
+		}
+	next:
+		for _, rec := range s.base().results {
+			for _, e := range excludes {
+				if e.Match(names(rec)) {
+					continue next
+				}
+			}
+			if len(includes) > 1 {
+				key := fmt.Sprintf("%q", rec.Values())
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			results = append(results, rec)
+		}
+	}
+	res.base().results = results
+	return res, nil
+}
+
+// patternSchema returns the schema of the first include pattern with a
+// schema.
+func patternSchema(patterns []Pattern) string {
+	for _, p := range patterns {
+		if !p.Exclude && p.Schema != "" {
+			return p.Schema
+		}
+	}
+	return ""
+}
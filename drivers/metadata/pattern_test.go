@@ -0,0 +1,61 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadPatterns(t *testing.T) {
+	tables := []Table{
+		{Schema: "app", Name: "books"},
+		{Schema: "app", Name: "books_archive"},
+		{Schema: "app2", Name: "authors"},
+		{Schema: "public", Name: "books"},
+		{Schema: "public", Name: "reviews_archive"},
+	}
+	// read matches the schema and name patterns of the filter, as readers do
+	read := func(p Pattern) (*TableSet, error) {
+		var res []Table
+		for _, table := range tables {
+			if p.Match("", table.Schema, table.Name) && (p.Schema != "" || table.Schema == "public") {
+				res = append(res, table)
+			}
+		}
+		return NewTableSet(res), nil
+	}
+	tests := []struct {
+		pattern string
+		exp     []string
+	}{
+		{"", []string{"public.books", "public.reviews_archive"}},
+		{"books", []string{"public.books"}},
+		{"app*.*", []string{"app.books", "app.books_archive", "app2.authors"}},
+		{"app*.*,-*_archive", []string{"app.books", "app2.authors"}},
+		{"-*_archive", []string{"public.books"}},
+		{"app.*, *.books", []string{"app.books", "app.books_archive", "public.books"}},
+		{"*.*,-app2.*,-public.*", []string{"app.books", "app.books_archive"}},
+		{"app.book_", []string{"app.books"}},
+		{"app.books_", nil},
+	}
+	for i, test := range tests {
+		patterns, err := ParsePatterns(test.pattern)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		res, err := readPatterns(patterns, read, tableNames)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var names []string
+		for res.Next() {
+			table := res.Get()
+			names = append(names, table.Schema+"."+table.Name)
+		}
+		if !reflect.DeepEqual(names, test.exp) {
+			t.Errorf("test %d %q expected %v, got: %v", i, test.pattern, test.exp, names)
+		}
+	}
+	if _, err := ParsePatterns("books,-"); err == nil {
+		t.Errorf("expected error for empty exclude pattern")
+	}
+}
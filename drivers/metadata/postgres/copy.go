@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// ParseCopyIn parses a COPY ... FROM STDIN statement, returning the table
+// (with optional column list) and the options of the copy. The statement is
+// kept as is, for drivers passing it to the server, and the names of the
+// options other than the format and header are returned, for drivers that
+// cannot honour them.
+func ParseCopyIn(sqlstr string) (string, drivers.CopyInOptions, bool) {
+	m := copyInRE.FindStringSubmatch(sqlstr)
+	if m == nil {
+		return "", drivers.CopyInOptions{}, false
+	}
+	opts := drivers.CopyInOptions{
+		Format:    "text",
+		Statement: strings.TrimRight(strings.TrimSpace(sqlstr), "; \t\r\n"),
+	}
+	words := copyWords(m[2])
+	for i := 0; i < len(words); i++ {
+		switch w := strings.ToUpper(words[i]); w {
+		case "WITH":
+		case "CSV", "TEXT", "BINARY":
+			opts.Format = strings.ToLower(w)
+		case "FORMAT":
+			if i+1 < len(words) {
+				opts.Format = strings.ToLower(strings.Trim(words[i+1], `'"`))
+				i++
+			}
+		case "HEADER":
+			opts.Header = true
+			if i+1 < len(words) {
+				switch strings.ToLower(strings.Trim(words[i+1], `'`)) {
+				case "true", "on", "1", "match":
+					i++
+				case "false", "off", "0":
+					opts.Header = false
+					i++
+				}
+			}
+		case "WHERE":
+			// the rest of the statement is the condition
+			opts.Other = append(opts.Other, w)
+			return strings.TrimSpace(m[1]), opts, true
+		default:
+			if copyOptions[w] {
+				opts.Other = append(opts.Other, w)
+			}
+		}
+	}
+	return strings.TrimSpace(m[1]), opts, true
+}
+
+// copyWords splits the options of a COPY statement into words and quoted
+// strings, skipping commas and parentheses.
+func copyWords(s string) []string {
+	r := []rune(s)
+	var words []string
+	for i := 0; i < len(r); {
+		switch c := r[i]; {
+		case unicode.IsSpace(c) || c == ',' || c == '(' || c == ')':
+			i++
+		case c == '\'' || c == '"':
+			start := i
+			for i++; i < len(r); i++ {
+				if r[i] == c {
+					if i+1 < len(r) && r[i+1] == c {
+						i++
+						continue
+					}
+					i++
+					break
+				}
+			}
+			words = append(words, string(r[start:i]))
+		default:
+			start := i
+			for i < len(r) && !unicode.IsSpace(r[i]) && !strings.ContainsRune(",()'\"", r[i]) {
+				i++
+			}
+			words = append(words, string(r[start:i]))
+		}
+	}
+	return words
+}
+
+var (
+	// copyInRE matches a COPY ... FROM STDIN statement.
+	copyInRE = regexp.MustCompile(`(?is)^\s*copy\s+(.+?)\s+from\s+stdin\b(.*?);?\s*$`)
+	// copyOptions are the names of the options of a COPY statement other than
+	// the format and header, in both the current and pre-9.0 syntax.
+	copyOptions = map[string]bool{
+		"DEFAULT":        true,
+		"DELIMITER":      true,
+		"ENCODING":       true,
+		"ESCAPE":         true,
+		"FORCE":          true,
+		"FORCE_NOT_NULL": true,
+		"FORCE_NULL":     true,
+		"FORCE_QUOTE":    true,
+		"FREEZE":         true,
+		"LOG_VERBOSITY":  true,
+		"NULL":           true,
+		"OIDS":           true,
+		"ON_ERROR":       true,
+		"QUOTE":          true,
+	}
+)
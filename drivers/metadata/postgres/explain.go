@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// Explain retrieves the query plan for a query using EXPLAIN (FORMAT JSON),
+// executing the query when analyze is true.
+func Explain(ctx context.Context, db drivers.DB, query string, analyze bool) (*drivers.ExplainNode, error) {
+	opts := "FORMAT JSON"
+	if analyze {
+		opts = "ANALYZE, " + opts
+	}
+	var buf []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN ("+opts+") "+query).Scan(&buf); err != nil {
+		return nil, err
+	}
+	return ParsePlan(buf)
+}
+
+// ParsePlan builds the query plan from a JSON formatted plan, as returned by
+// EXPLAIN (FORMAT JSON). For executed plans (ie, EXPLAIN ANALYZE), the time
+// spent in each operation is included, and operations spilling to disk or
+// with misestimated row counts are flagged.
+func ParsePlan(buf []byte) (*drivers.ExplainNode, error) {
+	var res []struct {
+		Plan          *planNode `json:"Plan"`
+		PlanningTime  *float64  `json:"Planning Time"`
+		ExecutionTime *float64  `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	if len(res) == 0 || res[0].Plan == nil {
+		return nil, fmt.Errorf("empty query plan")
+	}
+	root := &drivers.ExplainNode{Name: "QUERY PLAN"}
+	for _, r := range res {
+		if r.Plan == nil {
+			continue
+		}
+		r.Plan.add(root)
+		if r.PlanningTime != nil {
+			root.Details = append(root.Details, fmt.Sprintf("planning time=%.3f ms", *r.PlanningTime))
+		}
+		if r.ExecutionTime != nil {
+			root.Details = append(root.Details, fmt.Sprintf("execution time=%.3f ms", *r.ExecutionTime))
+		}
+	}
+	return root, nil
+}
+
+// planNode is a node of a JSON formatted Postgres query plan.
+type planNode struct {
+	NodeType          string     `json:"Node Type"`
+	JoinType          string     `json:"Join Type"`
+	RelationName      string     `json:"Relation Name"`
+	Alias             string     `json:"Alias"`
+	IndexName         string     `json:"Index Name"`
+	StartupCost       float64    `json:"Startup Cost"`
+	TotalCost         float64    `json:"Total Cost"`
+	PlanRows          int64      `json:"Plan Rows"`
+	ActualStartupTime *float64   `json:"Actual Startup Time"`
+	ActualTotalTime   *float64   `json:"Actual Total Time"`
+	ActualRows        *int64     `json:"Actual Rows"`
+	ActualLoops       *int64     `json:"Actual Loops"`
+	Filter            string     `json:"Filter"`
+	IndexCond         string     `json:"Index Cond"`
+	HashCond          string     `json:"Hash Cond"`
+	MergeCond         string     `json:"Merge Cond"`
+	JoinFilter        string     `json:"Join Filter"`
+	SortSpaceType     string     `json:"Sort Space Type"`
+	SortSpaceUsed     int64      `json:"Sort Space Used"`
+	HashBatches       int64      `json:"Hash Batches"`
+	HashAggBatches    int64      `json:"HashAgg Batches"`
+	DiskUsage         int64      `json:"Disk Usage"`
+	TempWrittenBlocks int64      `json:"Temp Written Blocks"`
+	Plans             []planNode `json:"Plans"`
+}
+
+// misestimateRatio is the ratio between the actual and estimated row counts
+// flagged as a misestimate.
+const misestimateRatio = 10
+
+// add adds the plan node and its children to parent.
+func (n planNode) add(parent *drivers.ExplainNode) {
+	name := n.NodeType
+	if n.JoinType != "" && n.JoinType != "Inner" {
+		name = n.NodeType + " " + n.JoinType
+	}
+	if n.IndexName != "" {
+		name += " using " + n.IndexName
+	}
+	if n.RelationName != "" {
+		name += " on " + n.RelationName
+		if n.Alias != "" && n.Alias != n.RelationName {
+			name += " " + n.Alias
+		}
+	}
+	details := []string{
+		fmt.Sprintf("cost=%.2f..%.2f", n.StartupCost, n.TotalCost),
+		fmt.Sprintf("rows=%d", n.PlanRows),
+	}
+	if n.ActualTotalTime != nil && n.ActualStartupTime != nil {
+		details = append(details, fmt.Sprintf("actual time=%.3f..%.3f", *n.ActualStartupTime, *n.ActualTotalTime))
+	}
+	if n.ActualRows != nil {
+		details = append(details, fmt.Sprintf("actual rows=%d", *n.ActualRows))
+	}
+	if n.ActualLoops != nil {
+		details = append(details, fmt.Sprintf("loops=%d", *n.ActualLoops))
+	}
+	for _, c := range []struct{ label, cond string }{
+		{"index cond", n.IndexCond},
+		{"hash cond", n.HashCond},
+		{"merge cond", n.MergeCond},
+		{"join filter", n.JoinFilter},
+		{"filter", n.Filter},
+	} {
+		if c.cond != "" {
+			details = append(details, c.label+": "+c.cond)
+		}
+	}
+	node := parent.Add(name, details...)
+	node.Time, node.Flags = n.selfTime(), n.flags()
+	for _, c := range n.Plans {
+		c.add(node)
+	}
+}
+
+// totalTime returns the time spent in the operation and its children over all
+// loops, in milliseconds.
+func (n planNode) totalTime() float64 {
+	if n.ActualTotalTime == nil || n.ActualLoops == nil {
+		return 0
+	}
+	return *n.ActualTotalTime * float64(*n.ActualLoops)
+}
+
+// selfTime returns the time spent in the operation, excluding its children,
+// in milliseconds. The time of parallel workers may exceed the time of their
+// parent, so it is never negative.
+func (n planNode) selfTime() float64 {
+	t := n.totalTime()
+	for _, c := range n.Plans {
+		t -= c.totalTime()
+	}
+	return max(t, 0)
+}
+
+// flags returns the warnings for the operation, when executed.
+func (n planNode) flags() []string {
+	var flags []string
+	switch {
+	case n.SortSpaceType == "Disk":
+		flags = append(flags, fmt.Sprintf("sort spilled to disk (%d kB)", n.SortSpaceUsed))
+	case n.HashBatches > 1:
+		flags = append(flags, fmt.Sprintf("hash spilled to disk (%d batches)", n.HashBatches))
+	case n.HashAggBatches > 1:
+		flags = append(flags, fmt.Sprintf("aggregate spilled to disk (%d kB)", n.DiskUsage))
+	case n.TempWrittenBlocks != 0:
+		flags = append(flags, fmt.Sprintf("wrote %d temp blocks", n.TempWrittenBlocks))
+	}
+	if n.ActualRows != nil && n.ActualLoops != nil && *n.ActualLoops != 0 {
+		actual, plan := float64(max(*n.ActualRows, 1)), float64(max(n.PlanRows, 1))
+		switch {
+		case actual >= plan*misestimateRatio:
+			flags = append(flags, fmt.Sprintf("rows underestimated %.0fx", actual/plan))
+		case plan >= actual*misestimateRatio:
+			flags = append(flags, fmt.Sprintf("rows overestimated %.0fx", plan/actual))
+		}
+	}
+	return flags
+}
+
+// EstimateRows retrieves the planner's estimated number of rows returned by a
+// query using EXPLAIN (FORMAT JSON), without executing the query.
+func EstimateRows(ctx context.Context, db drivers.Conn, query string) (int64, error) {
+	var buf []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query).Scan(&buf); err != nil {
+		return 0, err
+	}
+	var res []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return 0, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	if len(res) == 0 {
+		return 0, fmt.Errorf("empty query plan")
+	}
+	return res[0].Plan.PlanRows, nil
+}
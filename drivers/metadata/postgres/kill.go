@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// Kill terminates the backend with the process id.
+func Kill(ctx context.Context, db drivers.DB, id string) error {
+	var ok bool
+	if err := db.QueryRowContext(ctx, `SELECT pg_catalog.pg_terminate_backend($1::integer)`, id).Scan(&ok); err != nil {
+		return err
+	}
+	if !ok {
+		return text.ErrNoSuchSession
+	}
+	return nil
+}
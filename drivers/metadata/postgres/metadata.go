@@ -23,6 +23,19 @@ var _ metadata.ColumnStatReader = &metaReader{}
 var _ metadata.IndexReader = &metaReader{}
 var _ metadata.IndexColumnReader = &metaReader{}
 var _ metadata.TriggerReader = &metaReader{}
+var _ metadata.MaterializedViewReader = &metaReader{}
+var _ metadata.SpatialColumnReader = &metaReader{}
+var _ metadata.ServerConfigReader = &metaReader{}
+var _ metadata.ActivityReader = &metaReader{}
+var _ metadata.LockReader = &metaReader{}
+var _ metadata.SizeReader = &metaReader{}
+var _ metadata.ForeignTableReader = &metaReader{}
+var _ metadata.TypeReader = &metaReader{}
+var _ metadata.IndexStatReader = &metaReader{}
+var _ metadata.PartitionReader = &metaReader{}
+var _ metadata.RoleReader = &metaReader{}
+var _ metadata.CurrentSchemaReader = &metaReader{}
+var _ metadata.DependencyReader = &metaReader{}
 
 func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 	return func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
@@ -31,6 +44,7 @@ func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 			infos.WithCustomClauses(map[infos.ClauseName]string{
 				infos.ColumnsColumnSize:         "COALESCE(character_maximum_length, numeric_precision, datetime_precision, interval_precision, 0)",
 				infos.FunctionColumnsColumnSize: "COALESCE(character_maximum_length, numeric_precision, datetime_precision, interval_precision, 0)",
+				infos.ColumnsComment:            "COALESCE(pg_catalog.col_description((quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass, ordinal_position::int), '')",
 			}),
 			infos.WithSystemSchemas([]string{"pg_catalog", "pg_toast", "information_schema"}),
 			infos.WithCurrentSchema("CURRENT_SCHEMA"),
@@ -452,6 +466,803 @@ FROM
 	return metadata.NewTriggerSet(results), nil
 }
 
+func (r metaReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  current_database(),
+  m.schemaname,
+  m.matviewname,
+  m.matviewowner,
+  CASE WHEN m.ispopulated THEN 'YES' ELSE 'NO' END,
+  pg_catalog.pg_size_pretty(pg_catalog.pg_table_size(c.oid)),
+  COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), ''),
+  COALESCE(m.definition, '')
+FROM pg_catalog.pg_matviews m
+     JOIN pg_catalog.pg_namespace n ON n.nspname = m.schemaname
+     JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = m.matviewname
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(c.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "m.schemaname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("m.schemaname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("m.matviewname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Owner, &rec.IsPopulated, &rec.Size, &rec.Comment, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
+func (r metaReader) SpatialColumns(f metadata.Filter) (*metadata.SpatialColumnSet, error) {
+	schema, err := r.postgisSchema()
+	if err != nil {
+		return nil, err
+	}
+	if schema == "" {
+		// PostGIS is not installed
+		return metadata.NewSpatialColumnSet([]metadata.SpatialColumn{}), nil
+	}
+	qstr := `SELECT
+  g.catalog,
+  g.schema,
+  g.tbl,
+  g.col,
+  g.typ,
+  g.srid,
+  g.dims,
+  COALESCE((
+    SELECT pg_catalog.string_agg(ic.relname, ', ' ORDER BY ic.relname)
+    FROM pg_catalog.pg_index i
+         JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+         JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+         JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+    WHERE i.indrelid = c.oid AND a.attname = g.col AND am.amname IN ('gist', 'spgist', 'brin')
+  ), '')
+FROM (
+  SELECT
+    f_table_catalog::text AS catalog,
+    f_table_schema::text AS schema,
+    f_table_name::text AS tbl,
+    f_geometry_column::text AS col,
+    'geometry(' || type || ')' AS typ,
+    srid::text AS srid,
+    coord_dimension::text AS dims
+  FROM ` + pq.QuoteIdentifier(schema) + `.geometry_columns
+  UNION ALL
+  SELECT
+    f_table_catalog::text,
+    f_table_schema::text,
+    f_table_name::text,
+    f_geography_column::text,
+    'geography(' || type || ')',
+    srid::text,
+    coord_dimension::text
+  FROM ` + pq.QuoteIdentifier(schema) + `.geography_columns
+) g
+     JOIN pg_catalog.pg_namespace n ON n.nspname = g.schema
+     JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = g.tbl
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(c.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "g.schema NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("g.schema LIKE $%d", len(vals)))
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, fmt.Sprintf("g.tbl LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("g.col LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3, 4", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSpatialColumnSet([]metadata.SpatialColumn{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.SpatialColumn{}
+	for rows.Next() {
+		rec := metadata.SpatialColumn{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Type, &rec.SRID, &rec.Dimensions, &rec.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSpatialColumnSet(results), nil
+}
+
+func (r metaReader) ServerConfig(f metadata.Filter) (*metadata.ServerConfigSet, error) {
+	qstr := `SELECT
+  name,
+  COALESCE(setting, ''),
+  COALESCE(unit, ''),
+  COALESCE(boot_val, ''),
+  CASE WHEN source NOT IN ('default', 'override') AND setting IS DISTINCT FROM boot_val THEN 'YES' ELSE 'NO' END,
+  source,
+  COALESCE(short_desc, '')
+FROM pg_catalog.pg_settings`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("name LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewServerConfigSet([]metadata.ServerConfig{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ServerConfig{}
+	for rows.Next() {
+		rec := metadata.ServerConfig{}
+		err = rows.Scan(&rec.Name, &rec.Value, &rec.Unit, &rec.Default, &rec.Changed, &rec.Source, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewServerConfigSet(results), nil
+}
+
+func (r metaReader) Activity(f metadata.Filter) (*metadata.ActivitySet, error) {
+	qstr := `SELECT
+  pid::text,
+  COALESCE(usename::text, ''),
+  COALESCE(datname::text, ''),
+  COALESCE(host(client_addr) || ':' || client_port, ''),
+  COALESCE(state, ''),
+  COALESCE(pg_catalog.to_char(query_start, 'YYYY-MM-DD HH24:MI:SS'), ''),
+  COALESCE(pg_catalog.date_trunc('second', CASE WHEN state = 'active' THEN now() - query_start ELSE state_change - query_start END)::text, ''),
+  COALESCE(query, '')
+FROM pg_catalog.pg_stat_activity`
+	conds := []string{"backend_type = 'client backend'"}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("usename LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "query_start NULLS LAST, pid", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewActivitySet([]metadata.Activity{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Activity{}
+	for rows.Next() {
+		rec := metadata.Activity{}
+		err = rows.Scan(&rec.ID, &rec.User, &rec.Database, &rec.Client, &rec.State, &rec.Started, &rec.Duration, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewActivitySet(results), nil
+}
+
+func (r metaReader) Locks(f metadata.Filter) (*metadata.LockSet, error) {
+	qstr := `SELECT
+  COALESCE(l.pid::text, ''),
+  COALESCE(a.usename::text, ''),
+  l.locktype,
+  COALESCE(n.nspname::text, ''),
+  COALESCE(c.relname::text, l.transactionid::text, l.virtualxid, ''),
+  l.mode,
+  CASE WHEN l.granted THEN 'YES' ELSE 'NO' END,
+  COALESCE(CASE WHEN NOT l.granted THEN pg_catalog.array_to_string(pg_catalog.pg_blocking_pids(l.pid), ', ') END, ''),
+  COALESCE(a.query, '')
+FROM pg_catalog.pg_locks l
+     LEFT JOIN pg_catalog.pg_stat_activity a ON a.pid = l.pid
+     LEFT JOIN pg_catalog.pg_class c ON c.oid = l.relation
+     LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace`
+	conds := []string{"l.pid IS DISTINCT FROM pg_catalog.pg_backend_pid()"}
+	vals := []interface{}{}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("c.relname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "l.pid, l.granted DESC, 4, 5", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewLockSet([]metadata.Lock{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Lock{}
+	for rows.Next() {
+		rec := metadata.Lock{}
+		err = rows.Scan(&rec.Session, &rec.User, &rec.Type, &rec.Schema, &rec.Object, &rec.Mode, &rec.Granted, &rec.BlockedBy, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewLockSet(results), nil
+}
+
+func (r metaReader) Sizes(f metadata.Filter) (*metadata.SizeSet, error) {
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  n.nspname,
+  c.relname,
+  CASE c.relkind WHEN 'i' THEN 'INDEX' WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'TABLE' END,
+  COALESCE(t.relname, ''),
+  CASE WHEN c.reltuples < 0 THEN '' ELSE c.reltuples::bigint::text END,
+  pg_catalog.pg_size_pretty(CASE WHEN c.relkind = 'i' THEN pg_catalog.pg_relation_size(c.oid) ELSE pg_catalog.pg_table_size(c.oid) END),
+  CASE WHEN c.relkind = 'i' THEN '' ELSE pg_catalog.pg_size_pretty(pg_catalog.pg_indexes_size(c.oid)) END,
+  CASE WHEN c.relkind = 'i' THEN '' ELSE pg_catalog.pg_size_pretty(pg_catalog.pg_total_relation_size(c.oid)) END,
+  COALESCE(CASE WHEN s.n_live_tup + s.n_dead_tup > 0 THEN pg_catalog.round(100.0 * s.n_dead_tup / (s.n_live_tup + s.n_dead_tup), 1)::text || '% dead' END, '')
+FROM pg_catalog.pg_class c
+     JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+     LEFT JOIN pg_catalog.pg_index i ON i.indexrelid = c.oid
+     LEFT JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+     LEFT JOIN pg_catalog.pg_stat_all_tables s ON s.relid = c.oid`
+	kinds := []string{"'r'", "'p'", "'m'"}
+	for _, typ := range f.Types {
+		if typ == "INDEX" {
+			kinds = append(kinds, "'i'")
+		}
+	}
+	conds := []string{"c.relkind IN (" + strings.Join(kinds, ", ") + ")"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'pg_toast', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	} else {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(COALESCE(t.oid, c.oid))")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("COALESCE(t.relname, c.relname) LIKE $%d", len(vals)))
+	}
+	order := "pg_catalog.pg_total_relation_size(COALESCE(t.oid, c.oid)) DESC, 2, COALESCE(t.relname, c.relname), c.relkind = 'i', 3"
+	rows, closeRows, err := r.query(qstr, conds, order, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSizeSet([]metadata.Size{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Size{}
+	for rows.Next() {
+		rec := metadata.Size{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.Table, &rec.Rows, &rec.Size, &rec.IndexSize, &rec.TotalSize, &rec.Bloat)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSizeSet(results), nil
+}
+
+// ForeignTables lists the foreign tables, with their server, foreign data
+// wrapper, and options.
+func (r metaReader) ForeignTables(f metadata.Filter) (*metadata.ForeignTableSet, error) {
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  n.nspname,
+  c.relname,
+  'FOREIGN TABLE',
+  s.srvname,
+  w.fdwname,
+  COALESCE(pg_catalog.array_to_string(ft.ftoptions, ', '), ''),
+  pg_catalog.pg_get_userbyid(c.relowner),
+  COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), '')
+FROM pg_catalog.pg_foreign_table ft
+     JOIN pg_catalog.pg_class c ON c.oid = ft.ftrelid
+     JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+     JOIN pg_catalog.pg_foreign_server s ON s.oid = ft.ftserver
+     JOIN pg_catalog.pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(c.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("c.relname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewForeignTableSet([]metadata.ForeignTable{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ForeignTable{}
+	for rows.Next() {
+		rec := metadata.ForeignTable{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.Server, &rec.Wrapper, &rec.Options, &rec.Owner, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewForeignTableSet(results), nil
+}
+
+// Partitions lists the partitions of declaratively partitioned tables, with
+// their bounds. Partitions that are partitioned themselves are marked as
+// PARTITIONED, as done by psql.
+func (r metaReader) Partitions(f metadata.Filter) (*metadata.PartitionSet, error) {
+	ok, err := r.hasPartitioning()
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return metadata.NewPartitionSet([]metadata.Partition{}), nil
+	}
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  n.nspname,
+  c.relname,
+  COALESCE(p.relname, ''),
+  CASE pt.partstrat WHEN 'r' THEN 'RANGE' WHEN 'l' THEN 'LIST' WHEN 'h' THEN 'HASH' ELSE '' END,
+  COALESCE(substring(pg_catalog.pg_get_partkeydef(c.oid) from '\((.*)\)$'), ''),
+  COALESCE(pg_catalog.pg_get_expr(p.relpartbound, p.oid), '') || CASE WHEN p.relkind = 'p' THEN ', PARTITIONED' ELSE '' END,
+  CASE WHEN p.oid IS NULL OR p.reltuples < 0 THEN '' ELSE p.reltuples::bigint::text END,
+  COALESCE(pg_catalog.pg_size_pretty(pg_catalog.pg_total_relation_size(p.oid)), '')
+FROM pg_catalog.pg_partitioned_table pt
+     JOIN pg_catalog.pg_class c ON c.oid = pt.partrelid
+     JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+     LEFT JOIN pg_catalog.pg_inherits i ON i.inhparent = c.oid
+     LEFT JOIN pg_catalog.pg_class p ON p.oid = i.inhrelid`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'pg_toast', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	} else {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(c.oid)")
+	}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, fmt.Sprintf("c.relname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("p.relname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3, pg_catalog.pg_get_expr(p.relpartbound, p.oid) = 'DEFAULT', 4", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewPartitionSet([]metadata.Partition{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Partition{}
+	for rows.Next() {
+		rec := metadata.Partition{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Method, &rec.Key, &rec.Bound, &rec.Rows, &rec.Size)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewPartitionSet(results), nil
+}
+
+// Dependencies lists the relations and functions referenced by views and
+// materialized views, as recorded in pg_depend for their rewrite rules.
+func (r metaReader) Dependencies(f metadata.Filter) (*metadata.DependencySet, error) {
+	qstr := `SELECT DISTINCT
+  pg_catalog.current_database(),
+  vn.nspname,
+  v.relname,
+  CASE v.relkind WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'VIEW' END,
+  COALESCE(tn.nspname, pn.nspname),
+  COALESCE(t.relname, p.proname),
+  CASE
+    WHEN p.oid IS NOT NULL THEN 'FUNCTION'
+    ELSE CASE t.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'f' THEN 'FOREIGN TABLE' WHEN 'S' THEN 'SEQUENCE' ELSE 'TABLE' END
+  END
+FROM pg_catalog.pg_depend d
+     JOIN pg_catalog.pg_rewrite rw ON rw.oid = d.objid
+     JOIN pg_catalog.pg_class v ON v.oid = rw.ev_class
+     JOIN pg_catalog.pg_namespace vn ON vn.oid = v.relnamespace
+     LEFT JOIN pg_catalog.pg_class t ON d.refclassid = 'pg_catalog.pg_class'::regclass AND t.oid = d.refobjid
+     LEFT JOIN pg_catalog.pg_namespace tn ON tn.oid = t.relnamespace
+     LEFT JOIN pg_catalog.pg_proc p ON d.refclassid = 'pg_catalog.pg_proc'::regclass AND p.oid = d.refobjid
+     LEFT JOIN pg_catalog.pg_namespace pn ON pn.oid = p.pronamespace`
+	conds := []string{
+		"d.classid = 'pg_catalog.pg_rewrite'::regclass",
+		"d.deptype = 'n'",
+		"d.refobjid <> v.oid",
+		"COALESCE(t.oid, p.oid) IS NOT NULL",
+	}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds,
+			"vn.nspname NOT IN ('pg_catalog', 'pg_toast', 'information_schema')",
+			"COALESCE(tn.nspname, pn.nspname) NOT IN ('pg_catalog', 'pg_toast', 'information_schema')",
+		)
+	}
+	// filter by the referenced object, when set
+	schema, name, visible := "vn.nspname", "v.relname", "pg_catalog.pg_table_is_visible(v.oid)"
+	if f.Reference != "" {
+		schema, name, visible = "COALESCE(tn.nspname, pn.nspname)", "COALESCE(t.relname, p.proname)", "COALESCE(pg_catalog.pg_table_is_visible(t.oid), pg_catalog.pg_function_is_visible(p.oid))"
+		f.Name = f.Reference
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("%s LIKE $%d", schema, len(vals)))
+	} else {
+		conds = append(conds, visible)
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("%s LIKE $%d", name, len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3, 5, 6", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewDependencySet([]metadata.Dependency{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Dependency{}
+	for rows.Next() {
+		rec := metadata.Dependency{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.RefSchema, &rec.RefName, &rec.RefType)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewDependencySet(results), nil
+}
+
+// Types lists the user-defined types, excluding array types and the row types
+// of tables, with the underlying type of domains, the attributes of composite
+// types, and the labels of enums.
+func (r metaReader) Types(f metadata.Filter) (*metadata.TypeSet, error) {
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  n.nspname,
+  t.typname,
+  CASE t.typtype
+    WHEN 'e' THEN 'enum'
+    WHEN 'd' THEN 'domain'
+    WHEN 'c' THEN 'composite'
+    WHEN 'r' THEN 'range'
+    WHEN 'm' THEN 'multirange'
+    WHEN 'p' THEN 'pseudo'
+    ELSE 'base'
+  END,
+  CASE t.typtype
+    WHEN 'd' THEN pg_catalog.format_type(t.typbasetype, t.typtypmod)
+    WHEN 'c' THEN COALESCE((
+      SELECT pg_catalog.string_agg(pg_catalog.quote_ident(a.attname) || ' ' || pg_catalog.format_type(a.atttypid, a.atttypmod), ', ' ORDER BY a.attnum)
+      FROM pg_catalog.pg_attribute a
+      WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped), '')
+    ELSE ''
+  END,
+  COALESCE((
+    SELECT pg_catalog.string_agg(e.enumlabel, ', ' ORDER BY e.enumsortorder)
+    FROM pg_catalog.pg_enum e
+    WHERE e.enumtypid = t.oid), ''),
+  pg_catalog.pg_get_userbyid(t.typowner),
+  COALESCE(pg_catalog.obj_description(t.oid, 'pg_type'), '')
+FROM pg_catalog.pg_type t
+     JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+`
+	conds := []string{
+		"(t.typrelid = 0 OR (SELECT c.relkind = 'c' FROM pg_catalog.pg_class c WHERE c.oid = t.typrelid))",
+		"NOT EXISTS (SELECT 1 FROM pg_catalog.pg_type el WHERE el.oid = t.typelem AND el.typarray = t.oid)",
+	}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_type_is_visible(t.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'information_schema')", "n.nspname !~ '^pg_toast'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("t.typname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTypeSet([]metadata.Type{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Type{}
+	for rows.Next() {
+		rec := metadata.Type{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Kind, &rec.Definition, &rec.Elements, &rec.Owner, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTypeSet(results), nil
+}
+
+// IndexStats lists the usage statistics of indexes (pg_stat_all_indexes),
+// with the least used indexes first. The time of the last index scan is only
+// available in PostgreSQL 16 and newer.
+func (r metaReader) IndexStats(f metadata.Filter) (*metadata.IndexStatSet, error) {
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  s.schemaname,
+  s.relname,
+  s.indexrelname,
+  s.idx_scan,
+  s.idx_tup_read::text,
+  pg_catalog.pg_relation_size(s.indexrelid),
+  COALESCE(pg_catalog.to_char((pg_catalog.to_jsonb(s) ->> 'last_idx_scan')::timestamptz, 'YYYY-MM-DD HH24:MI:SS'), ''),
+  i.indisunique OR i.indisprimary OR i.indisexclusion,
+  pg_catalog.pg_get_indexdef(s.indexrelid)
+FROM pg_catalog.pg_stat_all_indexes s
+     JOIN pg_catalog.pg_index i ON i.indexrelid = s.indexrelid`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "s.schemaname NOT IN ('pg_catalog', 'information_schema')", "s.schemaname !~ '^pg_toast'")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("s.schemaname LIKE $%d", len(vals)))
+	} else {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(s.relid)")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("(s.relname LIKE $%d OR s.indexrelname LIKE $%d)", len(vals), len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "s.idx_scan, pg_catalog.pg_relation_size(s.indexrelid) DESC, 2, 3, 4", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewIndexStatSet([]metadata.IndexStat{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.IndexStat{}
+	for rows.Next() {
+		rec := metadata.IndexStat{}
+		var size int64
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Scans, &rec.RowsRead, &size, &rec.LastUsed, &rec.Required, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		rec.Size = metadata.FormatSize(size)
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewIndexStatSet(results), nil
+}
+
+// postgisSchema returns the schema of the PostGIS extension, or an empty
+// string when it is not installed.
+func (r metaReader) postgisSchema() (string, error) {
+	rows, closeRows, err := r.Query(`SELECT n.nspname
+FROM pg_catalog.pg_extension e
+     JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+WHERE e.extname = 'postgis'`)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	defer closeRows()
+	var schema string
+	for rows.Next() {
+		if err := rows.Scan(&schema); err != nil {
+			return "", err
+		}
+	}
+	return schema, rows.Err()
+}
+
+// Roles lists the roles, with their attributes and the roles they are members
+// of. The predefined pg_* roles are only listed with system objects.
+func (r metaReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	qstr := `SELECT
+  r.rolname,
+  CASE WHEN r.rolcanlogin THEN 'YES' ELSE 'NO' END,
+  CASE WHEN r.rolsuper THEN 'YES' ELSE 'NO' END,
+  pg_catalog.concat_ws(', ',
+    CASE WHEN NOT r.rolinherit THEN 'No inheritance' END,
+    CASE WHEN r.rolcreaterole THEN 'Create role' END,
+    CASE WHEN r.rolcreatedb THEN 'Create DB' END,
+    CASE WHEN r.rolreplication THEN 'Replication' END,
+    CASE WHEN r.rolbypassrls THEN 'Bypass RLS' END,
+    CASE WHEN r.rolconnlimit >= 0 THEN r.rolconnlimit || ' connections' END),
+  COALESCE((SELECT pg_catalog.string_agg(b.rolname || CASE WHEN m.admin_option THEN ' (admin)' ELSE '' END, ', ' ORDER BY b.rolname)
+    FROM pg_catalog.pg_auth_members m
+         JOIN pg_catalog.pg_roles b ON b.oid = m.roleid
+    WHERE m.member = r.oid), ''),
+  COALESCE(r.rolvaliduntil::text, ''),
+  COALESCE(pg_catalog.shobj_description(r.oid, 'pg_authid'), '')
+FROM pg_catalog.pg_roles r`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "r.rolname !~ '^pg_'")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("r.rolname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewRoleSet([]metadata.Role{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Role{}
+	for rows.Next() {
+		rec := metadata.Role{}
+		err = rows.Scan(&rec.Name, &rec.Login, &rec.Superuser, &rec.Attributes, &rec.MemberOf, &rec.ValidUntil, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// CurrentSchema returns the existing schemas of the search_path, including the
+// implicitly searched temporary schema and pg_catalog.
+func (r metaReader) CurrentSchema() ([]string, error) {
+	rows, closeRows, err := r.Query(`SELECT s
+FROM pg_catalog.unnest(pg_catalog.current_schemas(true)) WITH ORDINALITY AS p(s, n)
+ORDER BY n`)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+	var path []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		path = append(path, schema)
+	}
+	return path, rows.Err()
+}
+
+// hasPartitioning returns true when the server supports declarative
+// partitioning (PostgreSQL 10 and later).
+func (r metaReader) hasPartitioning() (bool, error) {
+	rows, closeRows, err := r.Query(`SELECT c.relname
+FROM pg_catalog.pg_class c
+     JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = 'pg_catalog' AND c.relname = 'pg_partitioned_table'`)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	defer closeRows()
+	ok := rows.Next()
+	return ok, rows.Err()
+}
+
 func (r metaReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
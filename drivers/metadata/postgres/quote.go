@@ -0,0 +1,20 @@
+package postgres
+
+import "strings"
+
+// QuoteLiteral quotes s as a string literal. Like libpq's PQescapeLiteral,
+// literals containing backslashes are written as escape string constants
+// (E'...'), so that they are interpreted the same regardless of the
+// standard_conforming_strings setting.
+func QuoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if !strings.ContainsRune(s, '\\') {
+		return "'" + s + "'"
+	}
+	return "E'" + strings.ReplaceAll(s, `\`, `\\`) + "'"
+}
+
+// QuoteIdentifier quotes s as an identifier, doubling any double quotes.
+func QuoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// sampleMinRows is the minimum estimated number of rows of the tables sampled
+// with TABLESAMPLE, for which ordering all rows randomly would be slow.
+const sampleMinRows = 100000
+
+// Sample returns the query returning a random sample of n rows of the table.
+// Large tables (and materialized views) are sampled with TABLESAMPLE SYSTEM,
+// reading only a percentage of the table's pages large enough to contain n
+// rows, while other relations are ordered randomly.
+func Sample(ctx context.Context, db drivers.DB, table string, n int) (string, error) {
+	var kind string
+	var tuples float64
+	err := db.QueryRowContext(ctx, `SELECT relkind, reltuples FROM pg_catalog.pg_class WHERE oid = pg_catalog.to_regclass($1)`, table).Scan(&kind, &tuples)
+	if err != nil || (kind != "r" && kind != "m" && kind != "p") || tuples < sampleMinRows {
+		return drivers.SampleRandom("random()")(ctx, db, table, n)
+	}
+	// sample 10 times the number of rows, as pages are sampled
+	pct := min(100, 1000*float64(n)/tuples)
+	return "SELECT * FROM " + table + " TABLESAMPLE SYSTEM (" + strconv.FormatFloat(pct, 'f', -1, 64) + ") ORDER BY random() LIMIT " + strconv.Itoa(n), nil
+}
@@ -0,0 +1,16 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// QueryTag sets the application name of the session to the query tag.
+func QueryTag(ctx context.Context, conn drivers.Conn, tag string) (context.Context, error) {
+	// errors are ignored, as the statement can not be executed in an aborted
+	// transaction, which would prevent the rollback
+	_, _ = conn.ExecContext(ctx, `SET application_name = `+QuoteLiteral(text.CommandName+" "+tag))
+	return ctx, nil
+}
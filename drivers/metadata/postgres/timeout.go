@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+)
+
+// StatementTimeout sets the statement_timeout of the session, or resets it
+// when d is 0.
+func StatementTimeout(ctx context.Context, conn drivers.Conn, d time.Duration) (context.Context, error) {
+	sqlstr := `RESET statement_timeout`
+	if d != 0 {
+		sqlstr = `SET statement_timeout = ` + strconv.FormatInt(d.Milliseconds(), 10)
+	}
+	_, err := conn.ExecContext(ctx, sqlstr)
+	// no statement can be executed in an aborted transaction (SQLSTATE
+	// 25P02), which the executed statement reports, and the rollback undoes
+	if err != nil && strings.Contains(err.Error(), "current transaction is aborted") {
+		return ctx, nil
+	}
+	return ctx, err
+}
@@ -24,6 +24,22 @@ type PluginReader struct {
 	functionColumns    func(Filter) (*FunctionColumnSet, error)
 	sequences          func(Filter) (*SequenceSet, error)
 	privilegeSummaries func(Filter) (*PrivilegeSummarySet, error)
+	materializedViews  func(Filter) (*MaterializedViewSet, error)
+	spatialColumns     func(Filter) (*SpatialColumnSet, error)
+	serverConfig       func(Filter) (*ServerConfigSet, error)
+	activity           func(Filter) (*ActivitySet, error)
+	locks              func(Filter) (*LockSet, error)
+	sizes              func(Filter) (*SizeSet, error)
+	projections        func(Filter) (*ProjectionSet, error)
+	foreignTables      func(Filter) (*ForeignTableSet, error)
+	partitions         func(Filter) (*PartitionSet, error)
+	roles              func(Filter) (*RoleSet, error)
+	currentSchema      func() ([]string, error)
+	types              func(Filter) (*TypeSet, error)
+	dictionaries       func(Filter) (*DictionarySet, error)
+	indexStats         func(Filter) (*IndexStatSet, error)
+	queryLog           func(Filter, int) (*LoggedQuerySet, error)
+	dependencies       func(Filter) (*DependencySet, error)
 }
 
 var _ ExtendedReader = &PluginReader{}
@@ -74,6 +90,54 @@ func NewPluginReader(readers ...Reader) Reader {
 		if r, ok := i.(PrivilegeSummaryReader); ok {
 			p.privilegeSummaries = r.PrivilegeSummaries
 		}
+		if r, ok := i.(MaterializedViewReader); ok {
+			p.materializedViews = r.MaterializedViews
+		}
+		if r, ok := i.(SpatialColumnReader); ok {
+			p.spatialColumns = r.SpatialColumns
+		}
+		if r, ok := i.(ServerConfigReader); ok {
+			p.serverConfig = r.ServerConfig
+		}
+		if r, ok := i.(ActivityReader); ok {
+			p.activity = r.Activity
+		}
+		if r, ok := i.(LockReader); ok {
+			p.locks = r.Locks
+		}
+		if r, ok := i.(SizeReader); ok {
+			p.sizes = r.Sizes
+		}
+		if r, ok := i.(ProjectionReader); ok {
+			p.projections = r.Projections
+		}
+		if r, ok := i.(ForeignTableReader); ok {
+			p.foreignTables = r.ForeignTables
+		}
+		if r, ok := i.(PartitionReader); ok {
+			p.partitions = r.Partitions
+		}
+		if r, ok := i.(RoleReader); ok {
+			p.roles = r.Roles
+		}
+		if r, ok := i.(CurrentSchemaReader); ok {
+			p.currentSchema = r.CurrentSchema
+		}
+		if r, ok := i.(TypeReader); ok {
+			p.types = r.Types
+		}
+		if r, ok := i.(DictionaryReader); ok {
+			p.dictionaries = r.Dictionaries
+		}
+		if r, ok := i.(IndexStatReader); ok {
+			p.indexStats = r.IndexStats
+		}
+		if r, ok := i.(QueryLogReader); ok {
+			p.queryLog = r.QueryLog
+		}
+		if r, ok := i.(DependencyReader); ok {
+			p.dependencies = r.Dependencies
+		}
 	}
 	return &p
 }
@@ -176,6 +240,118 @@ func (p PluginReader) PrivilegeSummaries(f Filter) (*PrivilegeSummarySet, error)
 	return p.privilegeSummaries(f)
 }
 
+func (p PluginReader) MaterializedViews(f Filter) (*MaterializedViewSet, error) {
+	if p.materializedViews == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.materializedViews(f)
+}
+
+func (p PluginReader) SpatialColumns(f Filter) (*SpatialColumnSet, error) {
+	if p.spatialColumns == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.spatialColumns(f)
+}
+
+func (p PluginReader) ServerConfig(f Filter) (*ServerConfigSet, error) {
+	if p.serverConfig == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.serverConfig(f)
+}
+
+func (p PluginReader) Activity(f Filter) (*ActivitySet, error) {
+	if p.activity == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.activity(f)
+}
+
+func (p PluginReader) Locks(f Filter) (*LockSet, error) {
+	if p.locks == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.locks(f)
+}
+
+func (p PluginReader) Sizes(f Filter) (*SizeSet, error) {
+	if p.sizes == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.sizes(f)
+}
+
+func (p PluginReader) Projections(f Filter) (*ProjectionSet, error) {
+	if p.projections == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.projections(f)
+}
+
+func (p PluginReader) ForeignTables(f Filter) (*ForeignTableSet, error) {
+	if p.foreignTables == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.foreignTables(f)
+}
+
+func (p PluginReader) Partitions(f Filter) (*PartitionSet, error) {
+	if p.partitions == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.partitions(f)
+}
+
+func (p PluginReader) Roles(f Filter) (*RoleSet, error) {
+	if p.roles == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.roles(f)
+}
+
+func (p PluginReader) CurrentSchema() ([]string, error) {
+	if p.currentSchema == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.currentSchema()
+}
+
+func (p PluginReader) Types(f Filter) (*TypeSet, error) {
+	if p.types == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.types(f)
+}
+
+func (p PluginReader) Dictionaries(f Filter) (*DictionarySet, error) {
+	if p.dictionaries == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.dictionaries(f)
+}
+
+func (p PluginReader) IndexStats(f Filter) (*IndexStatSet, error) {
+	if p.indexStats == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.indexStats(f)
+}
+
+func (p PluginReader) QueryLog(f Filter, n int) (*LoggedQuerySet, error) {
+	if p.queryLog == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.queryLog(f, n)
+}
+
+func (p PluginReader) Dependencies(f Filter) (*DependencySet, error) {
+	if p.dependencies == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.dependencies(f)
+}
+
 type LoggingReader struct {
 	db      DB
 	logger  logger
@@ -101,15 +101,24 @@ func (w DefaultWriter) DescribeFunctions(u *dburl.URL, funcTypes, pattern string
 			types = append(types, v...)
 		}
 	}
-	sp, tp, err := parsePattern(pattern)
+	patterns, err := ParsePatterns(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
-	res, err := r.Functions(Filter{Schema: sp, Name: tp, Types: types, WithSystem: showSystem})
+	res, err := readPatterns(patterns, func(p Pattern) (*FunctionSet, error) {
+		return r.Functions(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, Types: types, WithSystem: showSystem})
+	}, functionNames)
 	if err != nil {
 		return fmt.Errorf("failed to list functions: %w", err)
 	}
 	defer res.Close()
+	path, err := w.searchPath(patternSchema(patterns))
+	if err != nil {
+		return err
+	}
+	if len(path) != 0 {
+		res.SetSearchPath(path, func(r Result) string { return r.(*Function).Schema }, func(r Result) string { return r.(*Function).Name }, false)
+	}
 
 	if !showSystem {
 		// in case the reader doesn't implement WithSystem
@@ -169,28 +178,36 @@ func (w DefaultWriter) getFunctionColumns(c, s, f string) (string, error) {
 		if name != "" {
 			name += " "
 		}
-		args = append(args, fmt.Sprintf("%s%s%s", typ, name, c.DataType))
+		args = append(args, strings.TrimSpace(fmt.Sprintf("%s%s%s", typ, name, c.DataType)))
 	}
 	return strings.Join(args, ", "), nil
 }
 
 // DescribeTableDetails matching pattern
 func (w DefaultWriter) DescribeTableDetails(u *dburl.URL, pattern string, verbose, showSystem bool) error {
-	sp, tp, err := parsePattern(pattern)
+	cp, sp, tp, err := parsePattern(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
 
+	path, err := w.searchPath(sp)
+	if err != nil {
+		return err
+	}
+
 	found := 0
 
 	tr, isTR := w.r.(TableReader)
 	_, isCR := w.r.(ColumnReader)
 	if isTR && isCR {
-		res, err := tr.Tables(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
+		res, err := tr.Tables(Filter{Catalog: cp, Schema: sp, Name: tp, WithSystem: showSystem})
 		if err != nil {
 			return fmt.Errorf("failed to list tables: %w", err)
 		}
 		defer res.Close()
+		if len(path) != 0 {
+			res.SetSearchPath(path, tableSchema, tableName, true)
+		}
 		if !showSystem {
 			// in case the reader doesn't implement WithSystem
 			res.SetFilter(func(r Result) bool {
@@ -200,7 +217,12 @@ func (w DefaultWriter) DescribeTableDetails(u *dburl.URL, pattern string, verbos
 		}
 		for res.Next() {
 			t := res.Get()
-			err = w.describeTableDetails(t.Type, t.Schema, t.Name, verbose, showSystem)
+			// only qualify the columns with the catalog when requested
+			var catalog string
+			if cp != "" {
+				catalog = t.Catalog
+			}
+			err = w.describeTableDetails(t.Type, catalog, t.Schema, t.Name, t.Comment, verbose, showSystem)
 			if err != nil {
 				return fmt.Errorf("failed to describe %s %s.%s: %w", t.Type, t.Schema, t.Name, err)
 			}
@@ -219,12 +241,15 @@ func (w DefaultWriter) DescribeTableDetails(u *dburl.URL, pattern string, verbos
 	ir, isIR := w.r.(IndexReader)
 	_, isICR := w.r.(IndexColumnReader)
 	if isIR && isICR {
-		res, err := ir.Indexes(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
+		res, err := ir.Indexes(Filter{Catalog: cp, Schema: sp, Name: tp, WithSystem: showSystem})
 		if err != nil && err != text.ErrNotSupported {
 			return fmt.Errorf("failed to list indexes for table %s: %w", tp, err)
 		}
 		if res != nil {
 			defer res.Close()
+			if len(path) != 0 {
+				res.SetSearchPath(path, indexSchema, indexName, true)
+			}
 			if !showSystem {
 				// in case the reader doesn't implement WithSystem
 				res.SetFilter(func(r Result) bool {
@@ -250,9 +275,9 @@ func (w DefaultWriter) DescribeTableDetails(u *dburl.URL, pattern string, verbos
 	return nil
 }
 
-func (w DefaultWriter) describeTableDetails(typ, sp, tp string, verbose, showSystem bool) error {
+func (w DefaultWriter) describeTableDetails(typ, cp, sp, tp, comment string, verbose, showSystem bool) error {
 	r := w.r.(ColumnReader)
-	res, err := r.Columns(Filter{Schema: sp, Parent: tp, WithSystem: showSystem})
+	res, err := r.Columns(Filter{Catalog: cp, Schema: sp, Parent: tp, WithSystem: showSystem})
 	if err != nil {
 		return fmt.Errorf("failed to list columns for table %s: %w", tp, err)
 	}
@@ -260,20 +285,24 @@ func (w DefaultWriter) describeTableDetails(typ, sp, tp string, verbose, showSys
 
 	columns := []string{"Name", "Type", "Nullable", "Default"}
 	if verbose {
-		columns = append(columns, "Size", "Decimal Digits", "Radix", "Octet Length")
+		columns = append(columns, "Size", "Decimal Digits", "Radix", "Octet Length", "Comment")
 	}
 	res.SetColumns(columns)
 	res.SetScanValues(func(r Result) []interface{} {
 		f := r.(*Column)
 		v := []interface{}{f.Name, f.DataType, f.IsNullable, f.Default}
 		if verbose {
-			v = append(v, f.ColumnSize, f.DecimalDigits, f.NumPrecRadix, f.CharOctetLength)
+			v = append(v, f.ColumnSize, f.DecimalDigits, f.NumPrecRadix, f.CharOctetLength, f.Comment)
 		}
 		return v
 	})
 	params := env.Pall()
-	params["title"] = fmt.Sprintf("%s %s\n", typ, qualifiedIdentifier(sp, tp))
-	return w.encodeWithSummary(res, params, w.tableDetailsSummary(sp, tp))
+	name := qualifiedIdentifier(sp, tp)
+	if cp != "" {
+		name = qualifiedIdentifier(cp+"."+sp, tp)
+	}
+	params["title"] = fmt.Sprintf("%s %s\n", typ, name)
+	return w.encodeWithSummary(res, params, w.tableDetailsSummary(typ, sp, tp, comment, verbose, showSystem))
 }
 
 func (w DefaultWriter) encodeWithSummary(res tblfmt.ResultSet, params map[string]string, summary func(io.Writer, int) (int, error)) error {
@@ -290,8 +319,11 @@ func (w DefaultWriter) encodeWithSummary(res tblfmt.ResultSet, params map[string
 	return enc.EncodeAll(w.w)
 }
 
-func (w DefaultWriter) tableDetailsSummary(sp, tp string) func(io.Writer, int) (int, error) {
+func (w DefaultWriter) tableDetailsSummary(typ, sp, tp, comment string, verbose, showSystem bool) func(io.Writer, int) (int, error) {
 	return func(out io.Writer, _ int) (int, error) {
+		if verbose && comment != "" {
+			fmt.Fprintf(out, "Comment: %s\n", comment)
+		}
 		err := w.describeTableIndexes(out, sp, tp)
 		if err != nil {
 			return 0, err
@@ -362,6 +394,20 @@ func (w DefaultWriter) tableDetailsSummary(sp, tp string) func(io.Writer, int) (
 		if err != nil {
 			return 0, err
 		}
+		if verbose {
+			if err = w.describeTablePartitions(out, sp, tp); err != nil {
+				return 0, err
+			}
+			if err = w.describeTableSpatialColumns(out, sp, tp); err != nil {
+				return 0, err
+			}
+			if strings.Contains(strings.ToUpper(typ), "VIEW") {
+				if err = w.describeViewDependencies(out, sp, tp, showSystem); err != nil {
+					return 0, err
+				}
+			}
+			err = w.describeTableProjections(out, sp, tp)
+		}
 		return 0, err
 	}
 }
@@ -391,6 +437,178 @@ func (w DefaultWriter) describeTableTriggers(out io.Writer, sp, tp string) error
 	return nil
 }
 
+func (w DefaultWriter) describeTableProjections(out io.Writer, sp, tp string) error {
+	r, ok := w.r.(ProjectionReader)
+	if !ok {
+		return nil
+	}
+	res, err := r.Projections(Filter{Schema: sp, Parent: tp})
+	if err != nil && err != text.ErrNotSupported {
+		return fmt.Errorf("failed to list projections for table %s: %w", tp, err)
+	}
+	if res == nil {
+		return nil
+	}
+	defer res.Close()
+
+	if res.Len() == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "Projections:")
+	for res.Next() {
+		p := res.Get()
+		fmt.Fprintf(out, "  \"%s\"", p.Name)
+		if p.Type != "" {
+			fmt.Fprintf(out, " %s", p.Type)
+		}
+		if p.Definition != "" {
+			fmt.Fprintf(out, " (%s)", p.Definition)
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func (w DefaultWriter) describeTablePartitions(out io.Writer, sp, tp string) error {
+	r, ok := w.r.(PartitionReader)
+	if !ok {
+		return nil
+	}
+	res, err := r.Partitions(Filter{Schema: sp, Parent: tp})
+	if err != nil && err != text.ErrNotSupported {
+		return fmt.Errorf("failed to list partitions for table %s: %w", tp, err)
+	}
+	if res == nil {
+		return nil
+	}
+	defer res.Close()
+
+	if res.Len() == 0 {
+		return nil
+	}
+	for i := 0; res.Next(); i++ {
+		p := res.Get()
+		if i == 0 {
+			fmt.Fprint(out, "Partitions:")
+			if p.Method != "" {
+				fmt.Fprintf(out, " %s", p.Method)
+			}
+			if p.Key != "" {
+				fmt.Fprintf(out, " (%s)", p.Key)
+			}
+			fmt.Fprintln(out)
+		}
+		if p.Name == "" {
+			continue
+		}
+		fmt.Fprintf(out, "  \"%s\"", p.Name)
+		if p.Bound != "" {
+			fmt.Fprintf(out, " %s", p.Bound)
+		}
+		var details []string
+		if p.Rows != "" {
+			details = append(details, p.Rows+" rows")
+		}
+		if p.Size != "" {
+			details = append(details, p.Size)
+		}
+		if p.Parts != "" {
+			details = append(details, p.Parts+" parts")
+		}
+		if len(details) != 0 {
+			fmt.Fprintf(out, " (%s)", strings.Join(details, ", "))
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func (w DefaultWriter) describeViewDependencies(out io.Writer, sp, tp string, showSystem bool) error {
+	r, ok := w.r.(DependencyReader)
+	if !ok {
+		return nil
+	}
+	for _, dependents := range []bool{false, true} {
+		deps, err := w.dependencies(r, sp, tp, dependents, showSystem)
+		if err != nil {
+			return err
+		}
+		if len(deps) == 0 {
+			continue
+		}
+		if dependents {
+			fmt.Fprintln(out, "Referenced by:")
+		} else {
+			fmt.Fprintln(out, "Depends on:")
+		}
+		for _, d := range deps {
+			fmt.Fprintf(out, "  %s %s\n", d.Type, qualifiedIdentifier(d.Schema, d.Name))
+		}
+	}
+	return nil
+}
+
+// dependencies returns the objects the object depends on, or the objects
+// depending on the object, as Dependency values with the other object's
+// schema, name, and type.
+func (w DefaultWriter) dependencies(r DependencyReader, sp, tp string, dependents, showSystem bool) ([]Dependency, error) {
+	f := Filter{Schema: sp, Name: tp, WithSystem: showSystem}
+	if dependents {
+		f = Filter{Schema: sp, Reference: tp, WithSystem: showSystem}
+	}
+	res, err := r.Dependencies(f)
+	if err != nil && err != text.ErrNotSupported {
+		return nil, fmt.Errorf("failed to list dependencies of %s: %w", tp, err)
+	}
+	if res == nil {
+		return nil, nil
+	}
+	defer res.Close()
+	var deps []Dependency
+	for res.Next() {
+		d := res.Get()
+		switch {
+		case dependents && d.RefName == tp && (sp == "" || d.RefSchema == sp):
+			deps = append(deps, Dependency{Schema: d.Schema, Name: d.Name, Type: d.Type})
+		case !dependents && d.Name == tp && (sp == "" || d.Schema == sp):
+			deps = append(deps, Dependency{Schema: d.RefSchema, Name: d.RefName, Type: d.RefType})
+		}
+	}
+	return deps, nil
+}
+
+func (w DefaultWriter) describeTableSpatialColumns(out io.Writer, sp, tp string) error {
+	r, ok := w.r.(SpatialColumnReader)
+	if !ok {
+		return nil
+	}
+	res, err := r.SpatialColumns(Filter{Schema: sp, Parent: tp})
+	if err != nil && err != text.ErrNotSupported {
+		return fmt.Errorf("failed to list spatial columns for table %s: %w", tp, err)
+	}
+	if res == nil {
+		return nil
+	}
+	defer res.Close()
+
+	if res.Len() == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "Spatial columns:")
+	for res.Next() {
+		c := res.Get()
+		fmt.Fprintf(out, "  \"%s\" %s", c.Name, c.Type)
+		if c.SRID != "" {
+			fmt.Fprintf(out, " SRID %s", c.SRID)
+		}
+		if c.Indexes != "" {
+			fmt.Fprintf(out, " INDEXED BY %s", c.Indexes)
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
 func (w DefaultWriter) describeTableIndexes(out io.Writer, sp, tp string) error {
 	r, ok := w.r.(IndexReader)
 	if !ok {
@@ -566,6 +784,12 @@ func (w DefaultWriter) ListAllDbs(u *dburl.URL, pattern string, verbose bool) er
 
 // ListTables matching pattern
 func (w DefaultWriter) ListTables(u *dburl.URL, tableTypes, pattern string, verbose, showSystem bool) error {
+	if strings.TrimPrefix(tableTypes, "d") == "m" {
+		err := w.listMaterializedViews(pattern, verbose, showSystem)
+		if err != text.ErrNotSupported {
+			return err
+		}
+	}
 	r, ok := w.r.(TableReader)
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\dt`, u.Driver)
@@ -576,15 +800,24 @@ func (w DefaultWriter) ListTables(u *dburl.URL, tableTypes, pattern string, verb
 			types = append(types, v...)
 		}
 	}
-	sp, tp, err := parsePattern(pattern)
+	patterns, err := ParsePatterns(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
-	res, err := r.Tables(Filter{Schema: sp, Name: tp, Types: types, WithSystem: showSystem})
+	res, err := readPatterns(patterns, func(p Pattern) (*TableSet, error) {
+		return r.Tables(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, Types: types, WithSystem: showSystem})
+	}, tableNames)
 	if err != nil {
 		return fmt.Errorf("failed to list tables: %w", err)
 	}
 	defer res.Close()
+	path, err := w.searchPath(patternSchema(patterns))
+	if err != nil {
+		return err
+	}
+	if len(path) != 0 {
+		res.SetSearchPath(path, tableSchema, tableName, true)
+	}
 	if !showSystem {
 		// in case the reader doesn't implement WithSystem
 		res.SetFilter(func(r Result) bool {
@@ -616,13 +849,78 @@ func (w DefaultWriter) ListTables(u *dburl.URL, tableTypes, pattern string, verb
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
+// listMaterializedViews matching pattern, including their refresh state;
+// returns text.ErrNotSupported when the reader cannot list them, so callers
+// can fall back to a plain relation list
+func (w DefaultWriter) listMaterializedViews(pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(MaterializedViewReader)
+	if !ok {
+		return text.ErrNotSupported
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*MaterializedViewSet, error) {
+		return r.MaterializedViews(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		v := r.(*MaterializedView)
+		return v.Catalog, v.Schema, v.Name
+	})
+	if err == text.ErrNotSupported {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list materialized views: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*MaterializedView).Schema]
+			return !ok
+		})
+	}
+	if res.Len() == 0 {
+		fmt.Fprintf(w.w, text.RelationNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+	columns := []string{"Schema", "Name", "Owner", "Populated?", "Last refresh"}
+	if verbose {
+		columns = append(columns, "Refresh mode", "Size", "Comment")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		f := r.(*MaterializedView)
+		v := []interface{}{f.Schema, f.Name, f.Owner, f.IsPopulated, f.LastRefresh}
+		if verbose {
+			v = append(v, f.RefreshMode, f.Size, f.Comment)
+		}
+		return v
+	})
+
+	params := env.Pall()
+	params["title"] = "List of materialized views"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
 // ListSchemas matching pattern
 func (w DefaultWriter) ListSchemas(u *dburl.URL, pattern string, verbose, showSystem bool) error {
 	r, ok := w.r.(SchemaReader)
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\d`, u.Driver)
 	}
-	res, err := r.Schemas(Filter{Name: pattern, WithSystem: showSystem})
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*SchemaSet, error) {
+		return r.Schemas(Filter{Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		s := r.(*Schema)
+		return s.Catalog, "", s.Schema
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list schemas: %w", err)
 	}
@@ -646,15 +944,27 @@ func (w DefaultWriter) ListIndexes(u *dburl.URL, pattern string, verbose, showSy
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\di`, u.Driver)
 	}
-	sp, tp, err := parsePattern(pattern)
+	patterns, err := ParsePatterns(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
-	res, err := r.Indexes(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
+	res, err := readPatterns(patterns, func(p Pattern) (*IndexSet, error) {
+		return r.Indexes(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		i := r.(*Index)
+		return i.Catalog, i.Schema, i.Name
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list indexes: %w", err)
 	}
 	defer res.Close()
+	path, err := w.searchPath(patternSchema(patterns))
+	if err != nil {
+		return err
+	}
+	if len(path) != 0 {
+		res.SetSearchPath(path, indexSchema, indexName, true)
+	}
 
 	if !showSystem {
 		// in case the reader doesn't implement WithSystem
@@ -694,7 +1004,7 @@ func (w DefaultWriter) ShowStats(u *dburl.URL, statTypes, pattern string, verbos
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\ss`, u.Driver)
 	}
-	sp, tp, err := parsePattern(pattern)
+	cp, sp, tp, err := parsePattern(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
@@ -702,7 +1012,7 @@ func (w DefaultWriter) ShowStats(u *dburl.URL, statTypes, pattern string, verbos
 	rows := int64(0)
 	tr, ok := w.r.(TableReader)
 	if ok {
-		tables, err := tr.Tables(Filter{Schema: sp, Name: tp})
+		tables, err := tr.Tables(Filter{Catalog: cp, Schema: sp, Name: tp})
 		if err != nil {
 			return fmt.Errorf("failed to get table entry: %w", err)
 		}
@@ -716,7 +1026,7 @@ func (w DefaultWriter) ShowStats(u *dburl.URL, statTypes, pattern string, verbos
 	if verbose {
 		types = append(types, "extended")
 	}
-	res, err := r.ColumnStats(Filter{Schema: sp, Parent: tp, Types: types})
+	res, err := r.ColumnStats(Filter{Catalog: cp, Schema: sp, Parent: tp, Types: types})
 	if err != nil {
 		return fmt.Errorf("failed to get column stats: %w", err)
 	}
@@ -778,7 +1088,7 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\dp`, u.Driver)
 	}
-	sp, tp, err := parsePattern(pattern)
+	patterns, err := ParsePatterns(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to parse search pattern: %w", err)
 	}
@@ -790,7 +1100,12 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 			types = append(types, v...)
 		}
 	}
-	res, err := r.PrivilegeSummaries(Filter{Schema: sp, Name: tp, WithSystem: showSystem, Types: types})
+	res, err := readPatterns(patterns, func(p Pattern) (*PrivilegeSummarySet, error) {
+		return r.PrivilegeSummaries(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem, Types: types})
+	}, func(r Result) (string, string, string) {
+		s := r.(*PrivilegeSummary)
+		return s.Catalog, s.Schema, s.Name
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list table privileges: %w", err)
 	}
@@ -821,13 +1136,814 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
-func parsePattern(pattern string) (string, string, error) {
-	// TODO do proper escaping, quoting etc
-	if strings.ContainsRune(pattern, '.') {
-		parts := strings.SplitN(pattern, ".", 2)
-		return strings.ReplaceAll(parts[0], "*", "%"), strings.ReplaceAll(parts[1], "*", "%"), nil
+// ListSpatialColumns matching pattern
+func (w DefaultWriter) ListSpatialColumns(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(SpatialColumnReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dgs`, u.Driver)
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*SpatialColumnSet, error) {
+		return r.SpatialColumns(Filter{Catalog: p.Catalog, Schema: p.Schema, Parent: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		c := r.(*SpatialColumn)
+		return c.Catalog, c.Schema, c.Table
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dgs`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list spatial columns: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*SpatialColumn).Schema]
+			return !ok
+		})
+	}
+
+	columns := []string{"Schema", "Table", "Name", "Type", "SRID"}
+	if verbose {
+		columns = append(columns, "Dimensions", "Spatial index")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		c := r.(*SpatialColumn)
+		v := []interface{}{c.Schema, c.Table, c.Name, c.Type, c.SRID}
+		if verbose {
+			v = append(v, c.Dimensions, c.Indexes)
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of spatial columns"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListServerConfig matching pattern
+func (w DefaultWriter) ListServerConfig(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ServerConfigReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dconfig`, u.Driver)
+	}
+	res, err := r.ServerConfig(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dconfig`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list server configuration: %w", err)
+	}
+	defer res.Close()
+
+	columns := []string{"Name", "Value", "Unit", "Changed"}
+	if verbose {
+		columns = append(columns, "Default", "Source", "Description")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		c := r.(*ServerConfig)
+		v := []interface{}{c.Name, c.Value, c.Unit, c.Changed}
+		if verbose {
+			v = append(v, c.Default, c.Source, c.Description)
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of configuration parameters"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListActivity of sessions of users matching pattern
+func (w DefaultWriter) ListActivity(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ActivityReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dactivity`, u.Driver)
+	}
+	res, err := r.Activity(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dactivity`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list activity: %w", err)
+	}
+	defer res.Close()
+
+	columns := []string{"ID", "User", "State", "Duration", "Query"}
+	if verbose {
+		columns = []string{"ID", "User", "Database", "Client", "State", "Started", "Duration", "Query"}
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		a := r.(*Activity)
+		if verbose {
+			return []interface{}{a.ID, a.User, a.Database, a.Client, a.State, a.Started, a.Duration, a.Query}
+		}
+		return []interface{}{a.ID, a.User, a.State, a.Duration, querySnippet(a.Query)}
+	})
+	params := env.Pall()
+	params["title"] = "Server activity"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListLocks on objects matching pattern, followed by the blocking tree of
+// the sessions waiting on locks
+func (w DefaultWriter) ListLocks(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(LockReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dlocks`, u.Driver)
+	}
+	cp, sp, tp, err := parsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.Locks(Filter{Catalog: cp, Schema: sp, Name: tp})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dlocks`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list locks: %w", err)
+	}
+	defer res.Close()
+	var locks []*Lock
+	for res.Next() {
+		locks = append(locks, res.Get())
+	}
+	res.Reset()
+
+	columns := []string{"Session", "User", "Type", "Schema", "Object", "Mode", "Granted", "Blocked by"}
+	if verbose {
+		columns = append(columns, "Query")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		l := r.(*Lock)
+		v := []interface{}{l.Session, l.User, l.Type, l.Schema, l.Object, l.Mode, l.Granted, l.BlockedBy}
+		if verbose {
+			v = append(v, querySnippet(l.Query))
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of locks"
+	if err := tblfmt.EncodeAll(w.w, res, params); err != nil {
+		return err
+	}
+	return writeBlockingTree(w.w, locks)
+}
+
+// ListSizes of tables matching pattern, and their indexes when verbose
+func (w DefaultWriter) ListSizes(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(SizeReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dsize`, u.Driver)
 	}
-	return "", strings.ReplaceAll(pattern, "*", "%"), nil
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	types := []string{"TABLE"}
+	if verbose {
+		types = append(types, "INDEX")
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*SizeSet, error) {
+		return r.Sizes(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, Types: types, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		s := r.(*Size)
+		if s.Table != "" {
+			return s.Catalog, s.Schema, s.Table
+		}
+		return s.Catalog, s.Schema, s.Name
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dsize`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list sizes: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*Size).Schema]
+			return !ok
+		})
+	}
+
+	columns := []string{"Schema", "Name", "Rows", "Size", "Index size", "Total size", "Bloat"}
+	if verbose {
+		columns = []string{"Schema", "Name", "Type", "Table", "Rows", "Size", "Index size", "Total size", "Bloat"}
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		s := r.(*Size)
+		if verbose {
+			return []interface{}{s.Schema, s.Name, s.Type, s.Table, s.Rows, s.Size, s.IndexSize, s.TotalSize, s.Bloat}
+		}
+		return []interface{}{s.Schema, s.Name, s.Rows, s.Size, s.IndexSize, s.TotalSize, s.Bloat}
+	})
+	params := env.Pall()
+	params["title"] = "List of relation sizes"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListForeignTables matching pattern, including their options when verbose
+func (w DefaultWriter) ListForeignTables(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(ForeignTableReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\det`, u.Driver)
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*ForeignTableSet, error) {
+		return r.ForeignTables(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		t := r.(*ForeignTable)
+		return t.Catalog, t.Schema, t.Name
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\det`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list foreign tables: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*ForeignTable).Schema]
+			return !ok
+		})
+	}
+	if res.Len() == 0 {
+		fmt.Fprintf(w.w, text.RelationNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+	columns := []string{"Schema", "Table", "Type", "Server"}
+	if verbose {
+		columns = append(columns, "Wrapper", "Options", "Owner", "Comment")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		t := r.(*ForeignTable)
+		v := []interface{}{t.Schema, t.Name, t.Type, t.Server}
+		if verbose {
+			v = append(v, t.Wrapper, t.Options, t.Owner, t.Comment)
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of foreign tables"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListRoles matching pattern, including their descriptions when verbose
+func (w DefaultWriter) ListRoles(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(RoleReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\du`, u.Driver)
+	}
+	res, err := r.Roles(Filter{Name: strings.ReplaceAll(pattern, "*", "%"), WithSystem: showSystem})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\du`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer res.Close()
+
+	columns := []string{"Role name", "Login", "Superuser", "Attributes", "Member of", "Valid until"}
+	if verbose {
+		columns = append(columns, "Description")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		role := r.(*Role)
+		v := []interface{}{role.Name, role.Login, role.Superuser, role.Attributes, role.MemberOf, role.ValidUntil}
+		if verbose {
+			v = append(v, role.Comment)
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of roles"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListTypes matching pattern, including their definitions and enum elements
+// when verbose
+func (w DefaultWriter) ListTypes(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(TypeReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dT`, u.Driver)
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*TypeSet, error) {
+		return r.Types(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		t := r.(*Type)
+		return t.Catalog, t.Schema, t.Name
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dT`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list types: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*Type).Schema]
+			return !ok
+		})
+	}
+	columns := []string{"Schema", "Name", "Kind"}
+	if verbose {
+		columns = append(columns, "Definition", "Elements", "Owner")
+	}
+	res.SetColumns(append(columns, "Description"))
+	res.SetScanValues(func(r Result) []interface{} {
+		t := r.(*Type)
+		v := []interface{}{t.Schema, t.Name, t.Kind}
+		if verbose {
+			v = append(v, t.Definition, t.Elements, t.Owner)
+		}
+		return append(v, t.Comment)
+	})
+	params := env.Pall()
+	params["title"] = "List of data types"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListDictionaries matching pattern, including their load times, errors, and
+// definitions when verbose
+func (w DefaultWriter) ListDictionaries(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(DictionaryReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\ddict`, u.Driver)
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*DictionarySet, error) {
+		return r.Dictionaries(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name})
+	}, func(r Result) (string, string, string) {
+		d := r.(*Dictionary)
+		return "", d.Schema, d.Name
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\ddict`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list dictionaries: %w", err)
+	}
+	defer res.Close()
+	columns := []string{"Schema", "Name", "Status", "Layout", "Source", "Hit rate", "Elements", "Size"}
+	if verbose {
+		columns = append(columns, "Lifetime", "Last update", "Last error", "Definition", "Comment")
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		d := r.(*Dictionary)
+		v := []interface{}{d.Schema, d.Name, d.Status, d.Layout, d.Source, d.HitRate, d.Elements, d.Size}
+		if verbose {
+			v = append(v, d.Lifetime, d.LastUpdate, d.LastError, d.Definition, d.Comment)
+		}
+		return v
+	})
+	params := env.Pall()
+	params["title"] = "List of dictionaries"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListIndexStats matching pattern, including the rows read and index
+// definitions when verbose. Indexes never used, and not required by their
+// table, are flagged as likely unused.
+func (w DefaultWriter) ListIndexStats(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(IndexStatReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dxstat`, u.Driver)
+	}
+	patterns, err := ParsePatterns(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := readPatterns(patterns, func(p Pattern) (*IndexStatSet, error) {
+		return r.IndexStats(Filter{Catalog: p.Catalog, Schema: p.Schema, Name: p.Name, WithSystem: showSystem})
+	}, func(r Result) (string, string, string) {
+		s := r.(*IndexStat)
+		return s.Catalog, s.Schema, s.Table
+	})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dxstat`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list index statistics: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*IndexStat).Schema]
+			return !ok
+		})
+	}
+	columns := []string{"Schema", "Table", "Name", "Scans", "Size", "Last used"}
+	if verbose {
+		columns = append(columns, "Rows read", "Definition")
+	}
+	res.SetColumns(append(columns, "Flag"))
+	res.SetScanValues(func(r Result) []interface{} {
+		s := r.(*IndexStat)
+		v := []interface{}{s.Schema, s.Table, s.Name, s.Scans, s.Size, s.LastUsed}
+		if verbose {
+			v = append(v, s.RowsRead, s.Definition)
+		}
+		var flag string
+		if s.Unused() {
+			flag = "likely unused"
+		}
+		return append(v, flag)
+	})
+	params := env.Pall()
+	params["title"] = "Index usage statistics"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListQueryLog of the n most recent queries, including the database, query
+// type, start time, rows written and returned, error, and full query when
+// verbose. Displays all the details of the query instead, when id is not
+// empty.
+func (w DefaultWriter) ListQueryLog(u *dburl.URL, id string, n int, verbose bool) error {
+	r, ok := w.r.(QueryLogReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\qlog`, u.Driver)
+	}
+	if id != "" {
+		n = 1
+	}
+	res, err := r.QueryLog(Filter{Name: id}, n)
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\qlog`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list query log: %w", err)
+	}
+	defer res.Close()
+	params := env.Pall()
+	if id != "" {
+		if res.Len() == 0 {
+			return fmt.Errorf(text.QueryLogNotFound, id)
+		}
+		params["title"] = "Query " + id
+		params["expanded"] = "on"
+		return tblfmt.EncodeAll(w.w, res, params)
+	}
+	columns := []string{"ID", "Started", "Duration", "Read rows", "Read bytes", "Memory", "Query"}
+	if verbose {
+		columns = []string{"ID", "User", "Database", "Type", "Started", "Duration", "Read rows", "Read bytes", "Written rows", "Result rows", "Memory", "Error", "Query"}
+	}
+	res.SetColumns(columns)
+	res.SetScanValues(func(r Result) []interface{} {
+		q := r.(*LoggedQuery)
+		if verbose {
+			return q.Values()
+		}
+		return []interface{}{q.ID, q.Started, q.Duration, q.ReadRows, q.ReadBytes, q.Memory, querySnippet(q.Query)}
+	})
+	params["title"] = "Query log"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// maxDependencyDepth is the maximum depth of the trees written by
+// DescribeDependencies.
+const maxDependencyDepth = 10
+
+// DescribeDependencies writes the trees of the objects the objects matching
+// pattern depend on, and of the objects depending on them, including the
+// objects in system schemas when showSystem.
+func (w DefaultWriter) DescribeDependencies(u *dburl.URL, pattern string, showSystem bool) error {
+	r, ok := w.r.(DependencyReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\ddep`, u.Driver)
+	}
+	_, sp, tp, err := parsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	// find the matching objects, in the order listed
+	var objects []Dependency
+	seen := make(map[string]bool)
+	add := func(d Dependency) {
+		if key := d.Schema + "." + d.Name; !seen[key] {
+			seen[key] = true
+			objects = append(objects, d)
+		}
+	}
+	for _, f := range []Filter{
+		{Schema: sp, Name: tp, WithSystem: showSystem},
+		{Schema: sp, Reference: tp, WithSystem: showSystem},
+	} {
+		res, err := r.Dependencies(f)
+		if err == text.ErrNotSupported {
+			return fmt.Errorf(text.NotSupportedByDriver, `\ddep`, u.Driver)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list dependencies: %w", err)
+		}
+		for res.Next() {
+			d := res.Get()
+			if f.Reference != "" {
+				add(Dependency{Schema: d.RefSchema, Name: d.RefName, Type: d.RefType})
+			} else {
+				add(Dependency{Schema: d.Schema, Name: d.Name, Type: d.Type})
+			}
+		}
+		res.Close()
+	}
+	if len(objects) == 0 {
+		fmt.Fprintf(w.w, text.DependencyNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+	for i, o := range objects {
+		if i != 0 {
+			fmt.Fprintln(w.w)
+		}
+		fmt.Fprintf(w.w, "%s %s\n", o.Type, qualifiedIdentifier(o.Schema, o.Name))
+		for _, dependents := range []bool{false, true} {
+			var sb strings.Builder
+			path := map[string]bool{o.Schema + "." + o.Name: true}
+			if err := w.writeDependencyTree(&sb, r, o, dependents, showSystem, 1, path); err != nil {
+				return err
+			}
+			if sb.Len() == 0 {
+				continue
+			}
+			if dependents {
+				fmt.Fprintln(w.w, "Referenced by:")
+			} else {
+				fmt.Fprintln(w.w, "Depends on:")
+			}
+			fmt.Fprint(w.w, sb.String())
+		}
+	}
+	return nil
+}
+
+// writeDependencyTree writes the objects the object depends on, or the
+// objects depending on it, indented by depth, followed by their own
+// dependencies. Objects already on the path from the root object are written
+// without their dependencies, breaking cycles.
+func (w DefaultWriter) writeDependencyTree(out io.Writer, r DependencyReader, o Dependency, dependents, showSystem bool, depth int, path map[string]bool) error {
+	deps, err := w.dependencies(r, o.Schema, o.Name, dependents, showSystem)
+	if err != nil {
+		return err
+	}
+	for _, d := range deps {
+		fmt.Fprintf(out, "%s%s %s\n", strings.Repeat("  ", depth), d.Type, qualifiedIdentifier(d.Schema, d.Name))
+		key := d.Schema + "." + d.Name
+		if path[key] || depth >= maxDependencyDepth {
+			continue
+		}
+		path[key] = true
+		if err := w.writeDependencyTree(out, r, d, dependents, showSystem, depth+1, path); err != nil {
+			return err
+		}
+		delete(path, key)
+	}
+	return nil
+}
+
+// ShowFunctionSource writes the source of the function, with line numbers
+// when numbered.
+func (w DefaultWriter) ShowFunctionSource(u *dburl.URL, name string, numbered bool) error {
+	r, ok := w.r.(FunctionReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\sf`, u.Driver)
+	}
+	// ignore argument types
+	if i := strings.IndexRune(name, '('); i != -1 {
+		name = strings.TrimSpace(name[:i])
+	}
+	cp, sp, tp, err := parsePattern(name)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.Functions(Filter{Catalog: cp, Schema: sp, Name: tp, WithSystem: true})
+	if err == text.ErrNotSupported {
+		return fmt.Errorf(text.NotSupportedByDriver, `\sf`, u.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list functions: %w", err)
+	}
+	defer res.Close()
+	path, err := w.searchPath(sp)
+	if err != nil {
+		return err
+	}
+	if len(path) != 0 {
+		res.SetSearchPath(path, func(r Result) string { return r.(*Function).Schema }, func(r Result) string { return r.(*Function).Name }, false)
+	}
+	var f *Function
+	for res.Next() {
+		switch g := res.Get(); {
+		case f == nil:
+			f = g
+		case g.Schema == f.Schema && g.Name == f.Name:
+			return fmt.Errorf(text.FunctionNotUnique, name)
+		}
+	}
+	switch {
+	case f == nil:
+		return fmt.Errorf(text.FunctionNotFound, name)
+	case f.Source == "":
+		return fmt.Errorf(text.FunctionNoSource, name)
+	}
+	return WriteSource(w.w, f.Source, numbered)
+}
+
+// WriteSource writes the source of a function or procedure, with line numbers
+// when numbered.
+func WriteSource(w io.Writer, src string, numbered bool) error {
+	lines := strings.Split(strings.TrimRight(src, "\r\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if numbered {
+			line = fmt.Sprintf("%-7d %s", i+1, line)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBlockingTree writes the hierarchy of sessions blocking other sessions,
+// starting with the sessions that are not blocked themselves.
+func writeBlockingTree(out io.Writer, locks []*Lock) error {
+	sessions := map[string]*Lock{}
+	blocked := map[string][]string{}
+	waiting := map[string]bool{}
+	var ids []string
+	for _, l := range locks {
+		if _, ok := sessions[l.Session]; !ok {
+			ids = append(ids, l.Session)
+		}
+		if s, ok := sessions[l.Session]; !ok || (l.Granted == NO && s.Granted != NO) {
+			sessions[l.Session] = l
+		}
+		if l.BlockedBy == "" {
+			continue
+		}
+		for _, id := range strings.Split(l.BlockedBy, ", ") {
+			id = strings.TrimSpace(id)
+			if id == "" || id == l.Session || contains(blocked[id], l.Session) {
+				continue
+			}
+			if _, ok := sessions[id]; !ok && !contains(ids, id) {
+				ids = append(ids, id)
+			}
+			blocked[id] = append(blocked[id], l.Session)
+			waiting[l.Session] = true
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "Blocking tree")
+	// seen are the sessions written, with path the sessions on the path to
+	// the session being written
+	seen, path := map[string]bool{}, map[string]bool{}
+	var write func(string, string, string)
+	write = func(id, prefix, branch string) {
+		line := id
+		if l, ok := sessions[id]; ok {
+			if l.User != "" {
+				line += " (" + l.User + ")"
+			}
+			if l.Granted == NO {
+				line += " waiting for " + l.Mode
+				if obj := strings.Trim(l.Schema+"."+l.Object, "."); obj != "" {
+					line += " on " + obj
+				}
+			}
+			if q := querySnippet(l.Query); q != "" {
+				line += ": " + q
+			}
+		}
+		if path[id] {
+			fmt.Fprintln(out, prefix+branch+line+" (deadlock)")
+			return
+		}
+		seen[id], path[id] = true, true
+		defer delete(path, id)
+		fmt.Fprintln(out, prefix+branch+line)
+		switch branch {
+		case "├─ ":
+			prefix += "│  "
+		case "└─ ":
+			prefix += "   "
+		}
+		for i, child := range blocked[id] {
+			b := "├─ "
+			if i == len(blocked[id])-1 {
+				b = "└─ "
+			}
+			write(child, prefix, b)
+		}
+	}
+	for _, id := range ids {
+		if len(blocked[id]) != 0 && !waiting[id] {
+			write(id, "  ", "")
+		}
+	}
+	// sessions blocking each other in a cycle (ie, a deadlock)
+	for _, id := range ids {
+		if len(blocked[id]) != 0 && !seen[id] {
+			write(id, "  ", "")
+		}
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// contains determines if v contains s.
+func contains(v []string, s string) bool {
+	for _, x := range v {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// querySnippet collapses the whitespace in a query, truncating it to the
+// first 60 characters.
+func querySnippet(query string) string {
+	s := []rune(strings.Join(strings.Fields(query), " "))
+	if len(s) > 60 {
+		return string(s[:59]) + "…"
+	}
+	return string(s)
+}
+
+// searchPath returns the schemas searched by the session for unqualified
+// names, when no schema (sp) was given and the reader supports it.
+func (w DefaultWriter) searchPath(sp string) ([]string, error) {
+	r, ok := w.r.(CurrentSchemaReader)
+	if sp != "" || !ok {
+		return nil, nil
+	}
+	path, err := r.CurrentSchema()
+	switch {
+	case err == text.ErrNotSupported:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get current schema: %w", err)
+	}
+	return path, nil
+}
+
+func tableNames(r Result) (string, string, string) {
+	t := r.(*Table)
+	return t.Catalog, t.Schema, t.Name
+}
+
+func functionNames(r Result) (string, string, string) {
+	f := r.(*Function)
+	return f.Catalog, f.Schema, f.Name
+}
+
+func tableSchema(r Result) string { return r.(*Table).Schema }
+func tableName(r Result) string   { return r.(*Table).Name }
+func indexSchema(r Result) string { return r.(*Index).Schema }
+func indexName(r Result) string   { return r.(*Index).Name }
+
+// parsePattern splits a pattern into catalog, schema, and name patterns, for
+// catalog.schema.name, schema.name, and name patterns.
+func parsePattern(pattern string) (string, string, string, error) {
+	// TODO do proper escaping, quoting etc
+	parts := strings.SplitN(strings.ReplaceAll(pattern, "*", "%"), ".", 3)
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	case 2:
+		return "", parts[0], parts[1], nil
+	}
+	return "", "", parts[0], nil
 }
 
 func qualifiedIdentifier(schema, name string) string {
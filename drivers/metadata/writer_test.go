@@ -0,0 +1,175 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteBlockingTree(t *testing.T) {
+	tests := []struct {
+		locks []*Lock
+		exp   string
+	}{
+		{
+			[]*Lock{
+				{Session: "1", User: "alice", Granted: YES, Query: "UPDATE t SET a = 1"},
+			},
+			"",
+		},
+		{
+			[]*Lock{
+				{Session: "1", User: "alice", Schema: "public", Object: "t", Mode: "RowExclusiveLock", Granted: YES, Query: "UPDATE t\n  SET a = 1"},
+				{Session: "2", User: "bob", Schema: "public", Object: "t", Mode: "AccessExclusiveLock", Granted: NO, BlockedBy: "1", Query: "ALTER TABLE t ADD b int"},
+				{Session: "3", User: "carol", Schema: "public", Object: "t", Mode: "AccessShareLock", Granted: NO, BlockedBy: "1, 2", Query: "SELECT * FROM t"},
+			},
+			"Blocking tree\n" +
+				"  1 (alice): UPDATE t SET a = 1\n" +
+				"  ├─ 2 (bob) waiting for AccessExclusiveLock on public.t: ALTER TABLE t ADD b int\n" +
+				"  │  └─ 3 (carol) waiting for AccessShareLock on public.t: SELECT * FROM t\n" +
+				"  └─ 3 (carol) waiting for AccessShareLock on public.t: SELECT * FROM t\n\n",
+		},
+		{
+			[]*Lock{
+				{Session: "1", Mode: "X", Object: "a", Granted: NO, BlockedBy: "2"},
+				{Session: "2", Mode: "X", Object: "b", Granted: NO, BlockedBy: "1"},
+			},
+			"Blocking tree\n" +
+				"  1 waiting for X on a\n" +
+				"  └─ 2 waiting for X on b\n" +
+				"     └─ 1 waiting for X on a (deadlock)\n\n",
+		},
+	}
+	for i, test := range tests {
+		var sb strings.Builder
+		if err := writeBlockingTree(&sb, test.locks); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := sb.String(); s != test.exp {
+			t.Errorf("test %d expected:\n%q\ngot:\n%q", i, test.exp, s)
+		}
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		s               string
+		catalog, schema string
+		name            string
+	}{
+		{"", "", "", ""},
+		{"t*", "", "", "t%"},
+		{"s.t", "", "s", "t"},
+		{"c.s*.*", "c", "s%", "%"},
+		{"c.s.t.u", "c", "s", "t.u"},
+	}
+	for i, test := range tests {
+		catalog, schema, name, err := parsePattern(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if catalog != test.catalog || schema != test.schema || name != test.name {
+			t.Errorf("test %d expected %q, %q, %q, got: %q, %q, %q", i, test.catalog, test.schema, test.name, catalog, schema, name)
+		}
+	}
+}
+
+// dependencyReader is a reader returning fixed dependencies.
+type dependencyReader []Dependency
+
+func (r dependencyReader) Dependencies(f Filter) (*DependencySet, error) {
+	var res []Dependency
+	for _, d := range r {
+		if f.Name != "" && d.Name == f.Name || f.Reference != "" && d.RefName == f.Reference {
+			res = append(res, d)
+		}
+	}
+	return NewDependencySet(res), nil
+}
+
+func TestDescribeDependencies(t *testing.T) {
+	r := dependencyReader{
+		{Schema: "public", Name: "v", Type: "VIEW", RefSchema: "public", RefName: "t", RefType: "TABLE"},
+		{Schema: "public", Name: "v", Type: "VIEW", RefSchema: "public", RefName: "f", RefType: "FUNCTION"},
+		{Schema: "public", Name: "w", Type: "VIEW", RefSchema: "public", RefName: "v", RefType: "VIEW"},
+		{Schema: "public", Name: "f", Type: "FUNCTION", RefSchema: "public", RefName: "w", RefType: "VIEW"},
+	}
+	tests := []struct {
+		pattern string
+		exp     string
+	}{
+		{"v", `VIEW "public.v"
+Depends on:
+  TABLE "public.t"
+  FUNCTION "public.f"
+    VIEW "public.w"
+      VIEW "public.v"
+Referenced by:
+  VIEW "public.w"
+    FUNCTION "public.f"
+      VIEW "public.v"
+`},
+		{"t", `TABLE "public.t"
+Referenced by:
+  VIEW "public.v"
+    VIEW "public.w"
+      FUNCTION "public.f"
+        VIEW "public.v"
+`},
+		{"x", `Did not find any dependencies of an object named "x".
+`},
+	}
+	for i, test := range tests {
+		var sb strings.Builder
+		w := NewDefaultWriter(r)(nil, &sb)
+		if err := w.DescribeDependencies(nil, test.pattern, false); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := sb.String(); s != test.exp {
+			t.Errorf("test %d expected:\n%s\ngot:\n%s", i, test.exp, s)
+		}
+	}
+}
+
+// matViewReader is a reader returning fixed tables and materialized views.
+type matViewReader struct {
+	tables   []Table
+	matViews []MaterializedView
+}
+
+func (r matViewReader) Tables(Filter) (*TableSet, error) {
+	return NewTableSet(r.tables), nil
+}
+
+func (r matViewReader) MaterializedViews(Filter) (*MaterializedViewSet, error) {
+	return NewMaterializedViewSet(r.matViews), nil
+}
+
+func TestListMaterializedViews(t *testing.T) {
+	r := matViewReader{
+		tables: []Table{
+			{Schema: "public", Name: "t", Type: "TABLE"},
+		},
+		matViews: []MaterializedView{
+			{Schema: "public", Name: "mv", Owner: "alice", IsPopulated: YES},
+		},
+	}
+	tests := []struct {
+		tableTypes string
+		exp        string
+	}{
+		{"dm", "List of materialized views"},
+		{"m", "List of materialized views"},
+		{"dt", "List of relations"},
+		{"dtm", "List of relations"},
+	}
+	for i, test := range tests {
+		var sb strings.Builder
+		w := NewDefaultWriter(r)(nil, &sb)
+		if err := w.ListTables(nil, test.tableTypes, "", false, false); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := sb.String(); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected title %q, got:\n%s", i, test.exp, s)
+		}
+	}
+}
@@ -41,5 +41,8 @@ func init() {
 		ConvertBytes:      sqshared.ConvertBytes,
 		NewMetadataReader: sqshared.NewMetadataReader,
 		Copy:              drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:            drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder:       func(int) string { return "?" },
+		Sample:            drivers.SampleRandom("RANDOM()"),
 	})
 }
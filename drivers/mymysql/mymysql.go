@@ -19,6 +19,7 @@ func init() {
 		AllowMultilineComments: true,
 		AllowHashComments:      true,
 		LexerName:              "mysql",
+		Terminator:             mymeta.Terminator,
 		UseColumnTypes:         true,
 		Err: func(err error) (string, string) {
 			if e, ok := err.(*mysql.Error); ok {
@@ -32,11 +33,28 @@ func init() {
 			}
 			return false
 		},
+		IsSerializationErr: func(err error) bool {
+			if e, ok := err.(*mysql.Error); ok {
+				return e.Code == mysql.ER_LOCK_DEADLOCK
+			}
+			return false
+		},
+		ChangePassword:    mymeta.ChangePassword,
 		NewMetadataReader: mymeta.NewReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(mymeta.NewReader(db, opts...))(db, w)
 		},
-		Copy:         drivers.CopyWithInsert(func(int) string { return "?" }),
-		NewCompleter: mymeta.NewCompleter,
+		Copy:            drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:          drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder:     func(int) string { return "?" },
+		QueryID:         mymeta.QueryID,
+		Cancel:          mymeta.Cancel,
+		Kill:            mymeta.Kill,
+		NewCompleter:    mymeta.NewCompleter,
+		Explain:         mymeta.Explain,
+		EstimateRows:    mymeta.EstimateRows,
+		Sample:          drivers.SampleRandom("RAND()"),
+		QuoteLiteral:    mymeta.QuoteLiteral,
+		QuoteIdentifier: mymeta.QuoteIdentifier,
 	})
 }
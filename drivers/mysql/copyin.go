@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/ildus/usql/drivers"
+)
+
+// copyInSeq is the sequence of the reader handlers registered for LOAD DATA.
+var copyInSeq uint64
+
+// copyIn streams the data read from r into the table using LOAD DATA LOCAL
+// INFILE, which requires local_infile to be enabled on the server.
+func copyIn(ctx context.Context, db *sql.DB, r io.Reader, table string, opts drivers.CopyInOptions) (int64, error) {
+	name := fmt.Sprintf("usql-copyin-%d", atomic.AddUint64(&copyInSeq, 1))
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		return r
+	})
+	defer mysql.DeregisterReaderHandler(name)
+	var columns string
+	if i := strings.IndexRune(table, '('); i != -1 {
+		table, columns = strings.TrimSpace(table[:i]), table[i:]
+	}
+	query := "LOAD DATA LOCAL INFILE 'Reader::" + name + "' INTO TABLE " + table
+	// the text format is the default of LOAD DATA
+	if opts.Format == "csv" {
+		query += ` FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY ''`
+	}
+	if opts.Header {
+		query += " IGNORE 1 LINES"
+	}
+	query += " " + columns
+	res, err := db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load data: %w", err)
+	}
+	return res.RowsAffected()
+}
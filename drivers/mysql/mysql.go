@@ -9,6 +9,7 @@
 package mysql
 
 import (
+	"errors"
 	"io"
 	"strconv"
 
@@ -23,7 +24,9 @@ func init() {
 		AllowMultilineComments: true,
 		AllowHashComments:      true,
 		LexerName:              "mysql",
+		Terminator:             mymeta.Terminator,
 		UseColumnTypes:         true,
+		ProcedureResults:       true,
 		ForceParams: drivers.ForceQueryParameters([]string{
 			"parseTime", "true",
 			"loc", "Local",
@@ -41,11 +44,48 @@ func init() {
 			}
 			return false
 		},
+		IsSerializationErr: func(err error) bool {
+			if e, ok := err.(*mysql.MySQLError); ok {
+				return e.Number == 1213
+			}
+			return false
+		},
+		IsConnErr: func(err error) bool {
+			if e, ok := err.(*mysql.MySQLError); ok {
+				// ER_CONNECTION_KILLED, ER_SERVER_SHUTDOWN
+				return e.Number == 1927 || e.Number == 1053
+			}
+			return errors.Is(err, mysql.ErrInvalidConn)
+		},
+		ChangePassword:    mymeta.ChangePassword,
 		NewMetadataReader: mymeta.NewReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(mymeta.NewReader(db, opts...))(db, w)
 		},
-		Copy:         drivers.CopyWithInsert(func(int) string { return "?" }),
-		NewCompleter: mymeta.NewCompleter,
+		Copy:             drivers.CopyWithInsert(func(int) string { return "?" }),
+		Placeholder:      func(int) string { return "?" },
+		CopyIn:           copyIn,
+		QueryID:          mymeta.QueryID,
+		Cancel:           mymeta.Cancel,
+		StatementTimeout: mymeta.StatementTimeout,
+		Kill:             mymeta.Kill,
+		NewCompleter:     mymeta.NewCompleter,
+		Explain:          mymeta.Explain,
+		EstimateRows:     mymeta.EstimateRows,
+		Sample:           drivers.SampleRandom("RAND()"),
+		QuoteLiteral:     mymeta.QuoteLiteral,
+		QuoteIdentifier:  mymeta.QuoteIdentifier,
+		OutParams:        mymeta.OutParams,
+		CommentOn:        mymeta.CommentOn,
+		ColumnType: func(kind drivers.ColumnKind) string {
+			switch kind {
+			case drivers.KindFloat:
+				return "DOUBLE"
+			case drivers.KindTimestamp:
+				// TIMESTAMP columns are limited to 1970-2038
+				return "DATETIME(6)"
+			}
+			return ""
+		},
 	}, "memsql", "vitess", "tidb")
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/ildus/usql/dburl"
@@ -14,15 +15,30 @@ import (
 	"github.com/ildus/usql/drivers/metadata"
 	orameta "github.com/ildus/usql/drivers/metadata/oracle"
 	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
 )
 
+// simpleIdentRE matches unquoted identifiers, which are stored in upper
+// case.
+var simpleIdentRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]*$`)
+
 // Register registers an oracle driver.
 func Register(name string, err func(error) (string, string), isPasswordErr func(error) bool) {
 	endRE := regexp.MustCompile(`;?\s*$`)
-	endAnchorRE := regexp.MustCompile(`(?i)\send\s*;\s*$`)
+	endAnchorRE := regexp.MustCompile(`(?i)\send(\s+\w+)?\s*;\s*$`)
 	drivers.Register(name, drivers.Driver{
 		AllowMultilineComments: true,
 		LowerColumnNames:       true,
+		Terminator: &stmt.Terminator{
+			Batch: "/",
+			BatchPrefixes: []string{
+				"CREATE PROCEDURE", "CREATE FUNCTION", "CREATE PACKAGE", "CREATE TRIGGER", "CREATE TYPE",
+				"CREATE OR REPLACE PROCEDURE", "CREATE OR REPLACE FUNCTION", "CREATE OR REPLACE PACKAGE",
+				"CREATE OR REPLACE TRIGGER", "CREATE OR REPLACE TYPE",
+				"DECLARE", "BEGIN",
+			},
+		},
 		ForceParams: func(u *dburl.URL) {
 			// if the service name is not specified, use the environment
 			// variable if present
@@ -50,11 +66,23 @@ func Register(name string, err func(error) (string, string), isPasswordErr func(
 			return user, nil
 		},
 		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
-			_, err := db.Exec(`ALTER USER ` + user + ` IDENTIFIED BY ` + newpw)
+			// passwords are quoted identifiers, and cannot contain quotes
+			if strings.ContainsRune(newpw, '"') {
+				return text.ErrPasswordInvalidCharacter
+			}
+			if simpleIdentRE.MatchString(user) {
+				user = strings.ToUpper(user)
+			}
+			_, err := db.Exec(`ALTER USER ` + quoteIdentifier(user) + ` IDENTIFIED BY ` + quoteIdentifier(newpw))
 			return err
 		},
 		Err:           err,
 		IsPasswordErr: isPasswordErr,
+		IsSerializationErr: func(e error) bool {
+			// can't serialize access, deadlock detected
+			code, _ := err(e)
+			return code == "ORA-08177" || code == "ORA-00060"
+		},
 		Process: func(prefix string, sqlstr string) (string, string, bool, error) {
 			if !endAnchorRE.MatchString(sqlstr) {
 				// trim last ; but only when not END;
@@ -70,5 +98,24 @@ func Register(name string, err func(error) (string, string), isPasswordErr func(
 		Copy: drivers.CopyWithInsert(func(n int) string {
 			return fmt.Sprintf(":%d", n)
 		}),
+		Explain: orameta.Explain,
+		Sample: func(_ context.Context, _ drivers.DB, table string, n int) (string, error) {
+			return `SELECT * FROM ` + table + ` ORDER BY DBMS_RANDOM.VALUE FETCH FIRST ` + strconv.Itoa(n) + ` ROWS ONLY`, nil
+		},
+		Limit:          drivers.LimitClause("FETCH FIRST %d ROWS ONLY"),
+		InlineRows:     drivers.InlineSelect("DUAL"),
+		Kill:           orameta.Kill,
+		FoldIdentifier: strings.ToUpper,
+		Savepoint: func(name string) (string, string, string) {
+			return "SAVEPOINT " + name, "", "ROLLBACK TO SAVEPOINT " + name
+		},
+		Placeholder: func(n int) string {
+			return fmt.Sprintf(":%d", n)
+		},
 	})
 }
+
+// quoteIdentifier quotes s as an identifier, doubling any double quotes.
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -4,19 +4,25 @@
 package pgx
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	pgxconn "github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/stdlib" // DRIVER
+	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/kerberos"
 	"github.com/ildus/usql/drivers/metadata"
 	pgmeta "github.com/ildus/usql/drivers/metadata/postgres"
+	"github.com/ildus/usql/text"
 )
 
 func init() {
@@ -24,6 +30,12 @@ func init() {
 		AllowDollar:            true,
 		AllowMultilineComments: true,
 		LexerName:              "postgres",
+		GSSAPI: func(u *dburl.URL) {
+			pgxconn.RegisterGSSProvider(func() (pgxconn.GSS, error) {
+				return kerberos.NewGSS()
+			})
+			drivers.ReplaceQueryParameters([]string{"auth"}, nil)(u)
+		},
 		Version: func(ctx context.Context, db drivers.DB) (string, error) {
 			var ver string
 			err := db.QueryRowContext(ctx, `SHOW server_version`).Scan(&ver)
@@ -33,7 +45,7 @@ func init() {
 			return "PostgreSQL " + ver, nil
 		},
 		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
-			_, err := db.Exec(`ALTER USER ` + user + ` PASSWORD '` + newpw + `'`)
+			_, err := db.Exec(`ALTER USER ` + pgmeta.QuoteIdentifier(user) + ` PASSWORD ` + pgmeta.QuoteLiteral(newpw))
 			return err
 		},
 		Err: func(err error) (string, string) {
@@ -50,10 +62,36 @@ func init() {
 			}
 			return false
 		},
+		IsSerializationErr: func(err error) bool {
+			var e *pgconn.PgError
+			if errors.As(err, &e) {
+				return e.Code == "40001" || e.Code == "40P01"
+			}
+			return false
+		},
+		IsConnErr: func(err error) bool {
+			var e *pgxconn.PgError
+			if errors.As(err, &e) {
+				return strings.HasPrefix(e.Code, "08") || e.Code == "57P01" || e.Code == "57P02" || e.Code == "57P03"
+			}
+			return false
+		},
 		NewMetadataReader: pgmeta.NewReader(),
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(pgmeta.NewReader()(db, opts...))(db, w)
 		},
+		Explain:          pgmeta.Explain,
+		ParsePlan:        pgmeta.ParsePlan,
+		EstimateRows:     pgmeta.EstimateRows,
+		Sample:           pgmeta.Sample,
+		InlineRows:       drivers.InlineValues,
+		Describe:         describe,
+		Kill:             pgmeta.Kill,
+		QueryTag:         pgmeta.QueryTag,
+		StatementTimeout: pgmeta.StatementTimeout,
+		QuoteLiteral:     pgmeta.QuoteLiteral,
+		QuoteIdentifier:  pgmeta.QuoteIdentifier,
+		FoldIdentifier:   strings.ToLower,
 		Copy: func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error) {
 			conn, err := db.Conn(context.Background())
 			if err != nil {
@@ -98,7 +136,70 @@ func init() {
 			})
 			return n, err
 		},
+		CopyIn: func(ctx context.Context, db *sql.DB, r io.Reader, table string, opts drivers.CopyInOptions) (int64, error) {
+			query := "COPY " + table + " FROM STDIN"
+			switch {
+			case opts.Statement != "":
+				// pass the statement with all of its options to the server
+				query = opts.Statement
+			case opts.Format == "csv":
+				query += " (FORMAT csv, HEADER " + strconv.FormatBool(opts.Header) + ")"
+			case opts.Header:
+				// the header option of the text format requires PostgreSQL 15
+				br := bufio.NewReader(r)
+				if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+					return 0, fmt.Errorf("failed to read header: %w", err)
+				}
+				r = br
+			}
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get a connection from pool: %w", err)
+			}
+			defer conn.Close()
+			// stream the data as is to the server
+			var n int64
+			err = conn.Raw(func(driverConn interface{}) error {
+				tag, err := driverConn.(*stdlib.Conn).Conn().PgConn().CopyFrom(ctx, r, query)
+				n = tag.RowsAffected()
+				return err
+			})
+			return n, err
+		},
+		ParseCopyIn: pgmeta.ParseCopyIn,
+	})
+}
+
+// describe prepares the query on a connection from the pool, returning the
+// result columns of the prepared statement. Not supported inside a
+// transaction, as the connection of the transaction is not available.
+func describe(ctx context.Context, db drivers.DB, query string) ([]drivers.ColumnDesc, error) {
+	sqldb, ok := db.(*sql.DB)
+	if !ok {
+		return nil, text.ErrNotSupported
+	}
+	conn, err := sqldb.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection from pool: %w", err)
+	}
+	defer conn.Close()
+	var cols []drivers.ColumnDesc
+	err = conn.Raw(func(driverConn interface{}) error {
+		c := driverConn.(*stdlib.Conn).Conn()
+		desc, err := c.PgConn().Prepare(ctx, "", query, nil)
+		if err != nil {
+			return err
+		}
+		for _, f := range desc.Fields {
+			typ := strconv.FormatUint(uint64(f.DataTypeOID), 10)
+			if t, ok := c.TypeMap().TypeForOID(f.DataTypeOID); ok {
+				typ = t.Name
+			}
+			cols = append(cols, drivers.ColumnDesc{Name: f.Name, Type: typ})
+		}
+		return nil
 	})
+	return cols, err
 }
 
 type copyRows struct {
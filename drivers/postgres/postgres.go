@@ -18,6 +18,7 @@ import (
 	"github.com/lib/pq" // DRIVER
 	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/kerberos"
 	"github.com/ildus/usql/drivers/metadata"
 	pgmeta "github.com/ildus/usql/drivers/metadata/postgres"
 	"github.com/ildus/usql/env"
@@ -51,6 +52,12 @@ func init() {
 		AllowDollar:            true,
 		AllowMultilineComments: true,
 		LexerName:              "postgres",
+		GSSAPI: func(u *dburl.URL) {
+			pq.RegisterGSSProvider(func() (pq.GSS, error) {
+				return kerberos.NewGSS()
+			})
+			drivers.ReplaceQueryParameters([]string{"auth"}, nil)(u)
+		},
 		ForceParams: func(u *dburl.URL) {
 			if u.Scheme == "cockroachdb" {
 				drivers.ForceQueryParameters([]string{"sslmode", "disable"})(u)
@@ -90,7 +97,7 @@ func init() {
 			return "PostgreSQL " + ver, nil
 		},
 		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
-			_, err := db.Exec(`ALTER USER ` + user + ` PASSWORD '` + newpw + `'`)
+			_, err := db.Exec(`ALTER USER ` + pgmeta.QuoteIdentifier(user) + ` PASSWORD ` + pgmeta.QuoteLiteral(newpw))
 			return err
 		},
 		Err: func(err error) (string, string) {
@@ -105,10 +112,33 @@ func init() {
 			}
 			return false
 		},
+		IsSerializationErr: func(err error) bool {
+			if e, ok := err.(*pq.Error); ok {
+				return e.Code == "40001" || e.Code == "40P01"
+			}
+			return false
+		},
+		IsConnErr: func(err error) bool {
+			if e, ok := err.(*pq.Error); ok {
+				return e.Code.Class() == "08" || e.Code == "57P01" || e.Code == "57P02" || e.Code == "57P03"
+			}
+			return false
+		},
 		NewMetadataReader: pgmeta.NewReader(),
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(pgmeta.NewReader()(db, opts...))(db, w)
 		},
+		Explain:          pgmeta.Explain,
+		ParsePlan:        pgmeta.ParsePlan,
+		EstimateRows:     pgmeta.EstimateRows,
+		Sample:           pgmeta.Sample,
+		InlineRows:       drivers.InlineValues,
+		Kill:             pgmeta.Kill,
+		QueryTag:         pgmeta.QueryTag,
+		StatementTimeout: pgmeta.StatementTimeout,
+		QuoteLiteral:     pgmeta.QuoteLiteral,
+		QuoteIdentifier:  pgmeta.QuoteIdentifier,
+		FoldIdentifier:   strings.ToLower,
 		Copy: func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error) {
 			columns, err := rows.Columns()
 			if err != nil {
@@ -182,5 +212,52 @@ func init() {
 
 			return n, rows.Err()
 		},
+		CopyIn: func(ctx context.Context, db *sql.DB, r io.Reader, table string, opts drivers.CopyInOptions) (int64, error) {
+			// lib/pq encodes the records itself, so only the format and header
+			// options of the statement can be honoured
+			switch {
+			case len(opts.Other) != 0:
+				return 0, fmt.Errorf(text.NotSupportedByDriver, "COPY option "+strings.Join(opts.Other, ", "), "postgres")
+			case opts.Format != "csv" && opts.Format != "text":
+				return 0, fmt.Errorf(text.NotSupportedByDriver, "COPY format "+opts.Format, "postgres")
+			}
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				return 0, fmt.Errorf("failed to begin transaction: %w", err)
+			}
+			defer tx.Rollback()
+			// lib/pq sends the values of each exec as a row of the copy
+			stmt, err := tx.PrepareContext(ctx, "COPY "+table+" FROM STDIN")
+			if err != nil {
+				return 0, fmt.Errorf("failed to prepare copy query: %w", err)
+			}
+			defer stmt.Close()
+			next := drivers.NewRecordReader(r, opts)
+			for i := 1; ; i++ {
+				values, err := next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return 0, fmt.Errorf("failed to read record %d: %w", i, err)
+				}
+				if _, err := stmt.ExecContext(ctx, values...); err != nil {
+					return 0, fmt.Errorf("failed to exec copy: %w", err)
+				}
+			}
+			res, err := stmt.ExecContext(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("failed to final exec copy: %w", err)
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return 0, fmt.Errorf("failed to check rows affected: %w", err)
+			}
+			if err := tx.Commit(); err != nil {
+				return 0, fmt.Errorf("failed to commit transaction: %w", err)
+			}
+			return n, nil
+		},
+		ParseCopyIn: pgmeta.ParseCopyIn,
 	}, "cockroachdb", "redshift")
 }
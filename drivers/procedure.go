@@ -0,0 +1,80 @@
+package drivers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Variable is a variable (such as @name) of a statement.
+type Variable struct {
+	// Name is the name of the variable, without the @ prefix.
+	Name string
+	// Output indicates the variable is followed by the OUTPUT (or OUT)
+	// keyword, as with the output parameters of a SQL Server stored procedure
+	// call.
+	Output bool
+}
+
+// Variables returns the distinct @ prefixed variables of the statement,
+// skipping system variables (such as @@ROWCOUNT), quoted strings and
+// identifiers, and comments.
+func Variables(sqlstr string) []Variable {
+	r := []rune(sqlstr)
+	var vars []Variable
+	seen := make(map[string]int)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			i += 2
+			for i < len(r) && !(r[i-1] == '*' && r[i] == '/') {
+				i++
+			}
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(r, i, c)
+		case c == '[':
+			i = skipQuoted(r, i, ']')
+		case c == '@' && i+1 < len(r) && r[i+1] == '@':
+			for i += 2; i < len(r) && isNameRune(r[i]); i++ {
+			}
+		case c == '@':
+			start := i + 1
+			for i = start; i < len(r) && isNameRune(r[i]) && r[i] != '@'; i++ {
+			}
+			if i == start {
+				continue
+			}
+			name := string(r[start:i])
+			// following keyword
+			j := i
+			for j < len(r) && unicode.IsSpace(r[j]) {
+				j++
+			}
+			k := j
+			for k < len(r) && isNameRune(r[k]) {
+				k++
+			}
+			keyword := strings.ToUpper(string(r[j:k]))
+			output := keyword == "OUTPUT" || keyword == "OUT"
+			if n, ok := seen[strings.ToLower(name)]; ok {
+				vars[n].Output = vars[n].Output || output
+				continue
+			}
+			seen[strings.ToLower(name)] = len(vars)
+			vars = append(vars, Variable{Name: name, Output: output})
+		case isNameRune(c):
+			// skip names containing @, such as an email address in a name
+			for i < len(r) && isNameRune(r[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return vars
+}
@@ -0,0 +1,83 @@
+package drivers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// readOnlyTypes are the statement types that only read data.
+var readOnlyTypes = map[string]bool{
+	"DESC":     true,
+	"DESCRIBE": true,
+	"EXPLAIN":  true,
+	"SELECT":   true,
+	"SHOW":     true,
+	"TABLE":    true,
+	"VALUES":   true,
+	"WITH":     true,
+}
+
+// writeKeywords are the keywords of statements of a read only type that
+// modify data or lock rows, such as SELECT ... INTO, SELECT ... FOR UPDATE, or
+// data modifying common table expressions.
+var writeKeywords = map[string]bool{
+	"DELETE": true,
+	"INSERT": true,
+	"INTO":   true,
+	"LOCK":   true,
+	"MERGE":  true,
+	"SHARE":  true,
+	"UPDATE": true,
+}
+
+// ReadOnly returns true when the statement of the type (as returned by
+// Process) only reads data, so that it can be routed to a read replica.
+// Functions with side effects called by a statement are not detected.
+func ReadOnly(typ, sqlstr string) bool {
+	t, _, _ := strings.Cut(typ, " ")
+	if !readOnlyTypes[strings.ToUpper(t)] {
+		return false
+	}
+	for _, w := range sqlWords(sqlstr) {
+		if writeKeywords[strings.ToUpper(w)] {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlWords returns the unquoted words of the statement, including the words of
+// parenthesized expressions. String literals, quoted identifiers, and comments
+// are skipped.
+func sqlWords(sqlstr string) []string {
+	r := []rune(sqlstr)
+	var words []string
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			i += 2
+			for i < len(r) && !(r[i-1] == '*' && r[i] == '/') {
+				i++
+			}
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(r, i, c)
+		case c == '[':
+			i = skipQuoted(r, i, ']')
+		case isNameRune(c) && !unicode.IsDigit(c):
+			start := i
+			for i < len(r) && isNameRune(r[i]) {
+				i++
+			}
+			words = append(words, string(r[start:i]))
+		default:
+			i++
+		}
+	}
+	return words
+}
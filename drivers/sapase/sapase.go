@@ -20,6 +20,7 @@ func init() {
 		AllowMultilineComments:  true,
 		RequirePreviousPassword: true,
 		LexerName:               "tsql",
+		Limit:                   drivers.LimitSelect("TOP %d", true),
 		Version: func(ctx context.Context, db drivers.DB) (string, error) {
 			var ver string
 			err := db.QueryRowContext(ctx, `SELECT @@version`).Scan(&ver)
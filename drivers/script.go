@@ -0,0 +1,68 @@
+package drivers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/text"
+)
+
+// Statements reads the statements from r, using the statement parsing rules
+// of a driver. The statements of a batch (such as a Cassandra BEGIN BATCH ...
+// APPLY BATCH) are returned as a single statement. Variables are interpolated
+// using unquote, and left as is when unquote is nil. Backslash commands are
+// not supported.
+func Statements(u *dburl.URL, r io.Reader, unquote func(string, bool) (bool, string, error)) ([]string, error) {
+	if unquote == nil {
+		unquote = func(string, bool) (bool, string, error) {
+			return false, "", nil
+		}
+	}
+	br := bufio.NewReader(r)
+	buf := NewStmt(u, func() ([]rune, error) {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return []rune(strings.TrimRight(line, "\r\n")), err
+	})
+	var stmts []string
+	var batch []string
+	var batchEnd string
+	for {
+		cmd, _, err := buf.Next(unquote)
+		switch {
+		case err == io.EOF:
+			if buf.Len != 0 {
+				batch = append(batch, buf.String())
+			}
+			if len(batch) != 0 {
+				stmts = append(stmts, strings.Join(batch, "\n"))
+			}
+			return stmts, nil
+		case err != nil:
+			return nil, err
+		case cmd != "":
+			return nil, fmt.Errorf("%s: %w", cmd, text.ErrBackslashCommandsNotSupported)
+		case !buf.Ready():
+			continue
+		}
+		// accumulate batch queries as a single statement
+		typ, end, isBatch := IsBatchQueryPrefix(u, buf.Prefix)
+		s := buf.String()
+		buf.Reset(nil)
+		switch {
+		case batchEnd == "" && isBatch:
+			batch, batchEnd = []string{s}, end
+		case batchEnd != "" && typ != batchEnd:
+			batch = append(batch, s)
+		case batchEnd != "":
+			stmts, batch, batchEnd = append(stmts, strings.Join(append(batch, s), "\n")), nil, ""
+		case s != ";":
+			stmts = append(stmts, s)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/text"
+)
+
+// SeedOptions are the options for generating synthetic data.
+type SeedOptions struct {
+	// Rows is the number of rows to generate.
+	Rows int
+	// Seed is the seed of the random number generator.
+	Seed int64
+	// BatchSize is the number of rows inserted by each INSERT statement.
+	BatchSize int
+	// DryRun writes the INSERT statements instead of executing them.
+	DryRun bool
+	// Specs are the generator specs of the columns, by column name (see
+	// NewSeedGenerator).
+	Specs map[string]string
+}
+
+// Seed generates rows of synthetic data for the table, based on the types and
+// names of the table's columns, inserting the rows in batches with multi-row
+// INSERT statements, or writing the statements to w for a dry run. Columns
+// with a default value, and integer id columns, are left to the database,
+// unless a generator spec is provided for the column.
+func Seed(ctx context.Context, u *dburl.URL, db DB, w io.Writer, table string, opts SeedOptions) (int64, error) {
+	r, err := NewMetadataReader(ctx, u, db, w)
+	if err != nil {
+		return 0, err
+	}
+	cr, ok := r.(metadata.ColumnReader)
+	if !ok {
+		return 0, fmt.Errorf(text.NotSupportedByDriver, `\seed`, u.Driver)
+	}
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		schema, name = "", table
+	}
+	res, err := cr.Columns(metadata.Filter{Schema: schema, Parent: name})
+	if err != nil {
+		return 0, err
+	}
+	defer res.Close()
+	specs := make(map[string]string, len(opts.Specs))
+	for k, v := range opts.Specs {
+		specs[k] = v
+	}
+	var cols []string
+	var gens []SeedGenerator
+	for res.Next() {
+		c := res.Get()
+		// only use the columns of the first matching table
+		if len(cols) != 0 && (c.Schema != schema || c.Table != name) {
+			continue
+		}
+		schema, name = c.Schema, c.Table
+		spec, ok := specs[c.Name]
+		delete(specs, c.Name)
+		if !ok && (c.Default != "" || isSeedID(c)) {
+			continue
+		}
+		gen, err := NewSeedGenerator(c, spec)
+		switch {
+		case err != nil:
+			return 0, err
+		case gen != nil:
+			cols, gens = append(cols, QuoteIdentifier(u, c.Name)), append(gens, gen)
+		}
+	}
+	if err := res.Err(); err != nil {
+		return 0, err
+	}
+	for k := range specs {
+		return 0, fmt.Errorf(text.SeedUnknownColumn, k)
+	}
+	if len(cols) == 0 {
+		return 0, fmt.Errorf(text.RelationNotFound, table)
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	prefix := "INSERT INTO " + table + " (" + strings.Join(cols, ", ") + ") VALUES "
+	var n int64
+	for i := 0; i < opts.Rows; i += batchSize {
+		rows := make([]string, 0, min(batchSize, opts.Rows-i))
+		for j := i; j < opts.Rows && j < i+batchSize; j++ {
+			values := make([]string, len(gens))
+			for k, gen := range gens {
+				values[k] = gen(u, rnd, j)
+			}
+			rows = append(rows, "("+strings.Join(values, ", ")+")")
+		}
+		sqlstr := prefix + strings.Join(rows, ", ")
+		if opts.DryRun {
+			fmt.Fprintln(w, sqlstr+";")
+			n += int64(len(rows))
+			continue
+		}
+		if _, err := db.ExecContext(ctx, sqlstr); err != nil {
+			return n, err
+		}
+		n += int64(len(rows))
+	}
+	return n, nil
+}
+
+// isSeedID returns true when c is an integer id column, which are usually
+// generated by the database.
+func isSeedID(c *metadata.Column) bool {
+	return strings.EqualFold(c.Name, "id") && strings.Contains(strings.ToLower(c.DataType), "int")
+}
+
+// SeedGenerator generates the SQL literal of a column's value for the i-th
+// generated row.
+type SeedGenerator func(u *dburl.URL, rnd *rand.Rand, i int) string
+
+// NewSeedGenerator creates a generator for the column from the spec, or from
+// the column's type and name when spec is empty. Returns nil when the column
+// should be skipped. Columns of user-defined types (such as PostgreSQL enums)
+// that are not nullable require a spec.
+//
+// Specs have the form NAME[:ARG]..., and are one of:
+//
+//	int[:MIN:MAX]         random integer (default 1 to 1000)
+//	float[:MIN:MAX]       random number with 2 decimal digits (default 0 to 1000)
+//	seq[:START]           sequential integer (default 1)
+//	bool                  random boolean
+//	date[:FROM:TO]        random date (default the last year)
+//	timestamp[:FROM:TO]   random timestamp (default the last year)
+//	enum:A,B,...          random value from a list
+//	text[:WORDS]          random words (default 3)
+//	name                  random full name
+//	first_name            random first name
+//	last_name             random last name
+//	email                 random email address
+//	city                  random city
+//	uuid                  random UUID
+//	const:VALUE           constant value
+//	null                  null
+//	skip                  skips the column, leaving it to the database
+func NewSeedGenerator(c *metadata.Column, spec string) (SeedGenerator, error) {
+	if spec == "" {
+		if spec = seedSpec(c); spec == "" {
+			return nil, fmt.Errorf(text.SeedUserDefined, c.Name, c.Name)
+		}
+	}
+	name, arg, _ := strings.Cut(spec, ":")
+	args := strings.Split(arg, ":")
+	invalid := func() (SeedGenerator, error) {
+		return nil, fmt.Errorf(text.SeedInvalidSpec, spec, c.Name)
+	}
+	switch name {
+	case "skip":
+		return nil, nil
+	case "null":
+		return func(*dburl.URL, *rand.Rand, int) string {
+			return "NULL"
+		}, nil
+	case "const":
+		return func(u *dburl.URL, _ *rand.Rand, _ int) string {
+			return QuoteLiteral(u, arg)
+		}, nil
+	case "int":
+		lo, hi := int64(1), int64(1000)
+		if arg != "" {
+			var err error
+			if len(args) != 2 {
+				return invalid()
+			}
+			if lo, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+				return invalid()
+			}
+			// the range must not overflow
+			if hi, err = strconv.ParseInt(args[1], 10, 64); err != nil || hi < lo || hi-lo < 0 || hi-lo == math.MaxInt64 {
+				return invalid()
+			}
+		}
+		return func(_ *dburl.URL, rnd *rand.Rand, _ int) string {
+			return strconv.FormatInt(lo+rnd.Int63n(hi-lo+1), 10)
+		}, nil
+	case "float":
+		lo, hi := 0.0, 1000.0
+		if arg != "" {
+			var err error
+			if len(args) != 2 {
+				return invalid()
+			}
+			if lo, err = strconv.ParseFloat(args[0], 64); err != nil {
+				return invalid()
+			}
+			if hi, err = strconv.ParseFloat(args[1], 64); err != nil || hi < lo {
+				return invalid()
+			}
+		}
+		return func(_ *dburl.URL, rnd *rand.Rand, _ int) string {
+			return strconv.FormatFloat(lo+rnd.Float64()*(hi-lo), 'f', 2, 64)
+		}, nil
+	case "seq":
+		start := int64(1)
+		if arg != "" {
+			var err error
+			if start, err = strconv.ParseInt(arg, 10, 64); err != nil {
+				return invalid()
+			}
+		}
+		return func(_ *dburl.URL, _ *rand.Rand, i int) string {
+			return strconv.FormatInt(start+int64(i), 10)
+		}, nil
+	case "bool":
+		return func(u *dburl.URL, rnd *rand.Rand, _ int) string {
+			// '1' and '0' are accepted as boolean literals by most databases
+			return QuoteLiteral(u, strconv.Itoa(rnd.Intn(2)))
+		}, nil
+	case "date", "timestamp":
+		layout := time.DateOnly
+		if name == "timestamp" {
+			layout = time.DateTime
+		}
+		to := time.Now().Truncate(time.Second)
+		from := to.AddDate(-1, 0, 0)
+		if arg != "" {
+			var err error
+			if len(args) != 2 {
+				return invalid()
+			}
+			if from, err = parseSeedTime(args[0]); err != nil {
+				return invalid()
+			}
+			if to, err = parseSeedTime(args[1]); err != nil || to.Before(from) {
+				return invalid()
+			}
+		}
+		d := int64(to.Sub(from)/time.Second) + 1
+		return func(u *dburl.URL, rnd *rand.Rand, _ int) string {
+			return QuoteLiteral(u, from.Add(time.Duration(rnd.Int63n(d))*time.Second).Format(layout))
+		}, nil
+	case "enum":
+		values := strings.Split(arg, ",")
+		if arg == "" {
+			return invalid()
+		}
+		return func(u *dburl.URL, rnd *rand.Rand, _ int) string {
+			return QuoteLiteral(u, values[rnd.Intn(len(values))])
+		}, nil
+	case "text":
+		words := 3
+		if arg != "" {
+			var err error
+			if words, err = strconv.Atoi(arg); err != nil || words < 1 {
+				return invalid()
+			}
+		}
+		size := c.ColumnSize
+		return func(u *dburl.URL, rnd *rand.Rand, _ int) string {
+			v := make([]string, words)
+			for i := range v {
+				v[i] = seedWords[rnd.Intn(len(seedWords))]
+			}
+			s := strings.Join(v, " ")
+			if size > 0 && len(s) > size {
+				s = s[:size]
+			}
+			return QuoteLiteral(u, s)
+		}, nil
+	case "name", "first_name", "last_name", "email", "city":
+		return func(u *dburl.URL, rnd *rand.Rand, i int) string {
+			first := seedFirstNames[rnd.Intn(len(seedFirstNames))]
+			last := seedLastNames[rnd.Intn(len(seedLastNames))]
+			var s string
+			switch name {
+			case "name":
+				s = first + " " + last
+			case "first_name":
+				s = first
+			case "last_name":
+				s = last
+			case "email":
+				s = fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1)
+			case "city":
+				s = seedCities[rnd.Intn(len(seedCities))]
+			}
+			return QuoteLiteral(u, s)
+		}, nil
+	case "uuid":
+		return func(u *dburl.URL, rnd *rand.Rand, _ int) string {
+			b := make([]byte, 16)
+			rnd.Read(b)
+			b[6], b[8] = b[6]&0x0f|0x40, b[8]&0x3f|0x80
+			return QuoteLiteral(u, fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+		}, nil
+	}
+	return invalid()
+}
+
+// seedSpec returns the generator spec for the column's type and name, or
+// empty for columns of user-defined types that are not nullable.
+func seedSpec(c *metadata.Column) string {
+	typ, name := strings.ToLower(c.DataType), strings.ToLower(c.Name)
+	if m := seedEnumRE.FindStringSubmatch(c.DataType); m != nil {
+		var values []string
+		for _, v := range seedEnumValueRE.FindAllStringSubmatch(m[1], -1) {
+			values = append(values, strings.ReplaceAll(v[1], "''", "'"))
+		}
+		return "enum:" + strings.Join(values, ",")
+	}
+	switch {
+	case typ == "user-defined" && c.IsNullable != metadata.YES:
+		// the values of enums, composite, and other types are unknown
+		return ""
+	case strings.Contains(typ, "bool"), typ == "bit", strings.HasPrefix(typ, "tinyint(1)"):
+		return "bool"
+	case strings.Contains(typ, "timestamp"), strings.Contains(typ, "datetime"):
+		return "timestamp"
+	case strings.Contains(typ, "date"):
+		return "date"
+	case strings.Contains(typ, "uuid"), strings.Contains(typ, "uniqueidentifier"):
+		return "uuid"
+	case strings.Contains(typ, "smallint"), strings.Contains(typ, "tinyint"):
+		return "int:1:100"
+	case strings.Contains(typ, "int"), typ == "number" && c.DecimalDigits == 0:
+		return "int"
+	case strings.Contains(typ, "dec"), strings.Contains(typ, "num"), strings.Contains(typ, "real"),
+		strings.Contains(typ, "float"), strings.Contains(typ, "double"), strings.Contains(typ, "money"):
+		return "float"
+	case strings.Contains(typ, "char"), strings.Contains(typ, "text"), strings.Contains(typ, "string"),
+		strings.Contains(typ, "clob"), typ == "":
+		switch {
+		case strings.Contains(name, "email"):
+			return "email"
+		case strings.Contains(name, "first") && strings.Contains(name, "name"):
+			return "first_name"
+		case (strings.Contains(name, "last") && strings.Contains(name, "name")) || strings.Contains(name, "surname"):
+			return "last_name"
+		case strings.Contains(name, "city"):
+			return "city"
+		case strings.Contains(name, "name"):
+			return "name"
+		}
+		return "text"
+	}
+	if c.IsNullable == metadata.YES {
+		return "null"
+	}
+	return "text"
+}
+
+// parseSeedTime parses a date or timestamp.
+func parseSeedTime(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+// seedEnumRE and seedEnumValueRE match enum column types and their values.
+var (
+	seedEnumRE      = regexp.MustCompile(`(?i)^enum\s*\((.*)\)$`)
+	seedEnumValueRE = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// Synthetic data.
+var (
+	seedFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Irene", "Jack", "Karen", "Liam", "Maria", "Noah", "Olivia", "Paul", "Quinn", "Rosa", "Sam", "Tara"}
+	seedLastNames  = []string{"Anderson", "Brown", "Clark", "Davis", "Evans", "Garcia", "Harris", "Jones", "King", "Lee", "Martin", "Miller", "Nguyen", "Patel", "Smith", "Taylor", "Walker", "White", "Wilson", "Young"}
+	seedCities     = []string{"Amsterdam", "Berlin", "Chicago", "Dublin", "Lisbon", "London", "Madrid", "Oslo", "Paris", "Prague", "Rome", "Seoul", "Sydney", "Tokyo", "Toronto", "Vienna"}
+	seedWords      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim"}
+)
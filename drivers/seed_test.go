@@ -0,0 +1,94 @@
+package drivers_test
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+)
+
+func TestNewSeedGenerator(t *testing.T) {
+	tests := []struct {
+		col  metadata.Column
+		spec string
+		exp  string
+		err  bool
+	}{
+		// specs
+		{metadata.Column{Name: "a"}, "int:5:7", `^[5-7]$`, false},
+		{metadata.Column{Name: "a"}, "int:0:9223372036854775806", `^\d+$`, false},
+		{metadata.Column{Name: "a"}, "float:1:2", `^(1\.\d\d|2\.00)$`, false},
+		{metadata.Column{Name: "a"}, "seq:10", `^1[0-9]$`, false},
+		{metadata.Column{Name: "a"}, "bool", `^'[01]'$`, false},
+		{metadata.Column{Name: "a"}, "date:2024-01-01:2024-01-31", `^'2024-01-\d\d'$`, false},
+		{metadata.Column{Name: "a"}, "timestamp:2024-01-01:2024-01-02", `^'2024-01-0[12] \d\d:\d\d:\d\d'$`, false},
+		{metadata.Column{Name: "a"}, "enum:x,it's", `^('x'|'it''s')$`, false},
+		{metadata.Column{Name: "a"}, "text:2", `^'[a-z]+ [a-z]+'$`, false},
+		{metadata.Column{Name: "a"}, "email", `^'[a-z]+\.[a-z]+\d+@example\.com'$`, false},
+		{metadata.Column{Name: "a"}, "uuid", `^'[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}'$`, false},
+		{metadata.Column{Name: "a"}, "const:o'k", `^'o''k'$`, false},
+		{metadata.Column{Name: "a"}, "null", `^NULL$`, false},
+		{metadata.Column{Name: "a"}, "skip", ``, false},
+		// invalid specs
+		{metadata.Column{Name: "a"}, "int:7:5", ``, true},
+		{metadata.Column{Name: "a"}, "int:1", ``, true},
+		{metadata.Column{Name: "a"}, "int:1.5:2", ``, true},
+		{metadata.Column{Name: "a"}, "int:-9223372036854775808:9223372036854775807", ``, true},
+		{metadata.Column{Name: "a"}, "int:-1:9223372036854775807", ``, true},
+		{metadata.Column{Name: "a"}, "int:0:9223372036854775808", ``, true},
+		{metadata.Column{Name: "a"}, "float:2:1", ``, true},
+		{metadata.Column{Name: "a"}, "date:2024-02-01:2024-01-01", ``, true},
+		{metadata.Column{Name: "a"}, "enum", ``, true},
+		{metadata.Column{Name: "a"}, "text:0", ``, true},
+		{metadata.Column{Name: "a"}, "unknown", ``, true},
+		// column types and names
+		{metadata.Column{Name: "a", DataType: "integer"}, "", `^\d+$`, false},
+		{metadata.Column{Name: "a", DataType: "smallint"}, "", `^\d+$`, false},
+		{metadata.Column{Name: "a", DataType: "NUMBER"}, "", `^\d+$`, false},
+		{metadata.Column{Name: "a", DataType: "numeric(10,2)", DecimalDigits: 2}, "", `^\d+\.\d\d$`, false},
+		{metadata.Column{Name: "a", DataType: "boolean"}, "", `^'[01]'$`, false},
+		{metadata.Column{Name: "a", DataType: "tinyint(1)"}, "", `^'[01]'$`, false},
+		{metadata.Column{Name: "a", DataType: "date"}, "", `^'\d{4}-\d\d-\d\d'$`, false},
+		{metadata.Column{Name: "a", DataType: "timestamp with time zone"}, "", `^'\d{4}-\d\d-\d\d \d\d:\d\d:\d\d'$`, false},
+		{metadata.Column{Name: "a", DataType: "uuid"}, "", `^'[0-9a-f-]{36}'$`, false},
+		{metadata.Column{Name: "a", DataType: "enum('x','it''s')"}, "", `^('x'|'it''s')$`, false},
+		{metadata.Column{Name: "email", DataType: "varchar(100)"}, "", `@example\.com'$`, false},
+		{metadata.Column{Name: "a", DataType: "varchar(5)", ColumnSize: 5}, "", `^'.{1,5}'$`, false},
+		{metadata.Column{Name: "a", DataType: "xml", IsNullable: metadata.YES}, "", `^NULL$`, false},
+		// user-defined types, such as PostgreSQL enums
+		{metadata.Column{Name: "a", DataType: "USER-DEFINED", IsNullable: metadata.YES}, "", `^NULL$`, false},
+		{metadata.Column{Name: "a", DataType: "USER-DEFINED", IsNullable: metadata.NO}, "", ``, true},
+		{metadata.Column{Name: "a", DataType: "USER-DEFINED", IsNullable: metadata.NO}, "enum:x", `^'x'$`, false},
+	}
+	u, err := dburl.Parse("pg://")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range tests {
+		gen, err := drivers.NewSeedGenerator(&test.col, test.spec)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d expected error, got nil", i)
+			continue
+		case !test.err && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+			continue
+		case test.err:
+			continue
+		case test.exp == "":
+			if gen != nil {
+				t.Errorf("test %d expected column to be skipped", i)
+			}
+			continue
+		}
+		re, rnd := regexp.MustCompile(test.exp), rand.New(rand.NewSource(1))
+		for j := 0; j < 10; j++ {
+			if s := gen(u, rnd, j); !re.MatchString(s) {
+				t.Errorf("test %d expected %s to match %s", i, s, test.exp)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/drivers/sidecar/wire"
+)
+
+// MetadataReader reads the metadata of a database with the metadata requests
+// of the sidecar protocol.
+type MetadataReader struct {
+	metadata.LoggingReader
+	db drivers.DB
+}
+
+// NewMetadataReader creates the metadata reader for sidecar databases.
+func NewMetadataReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return &MetadataReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+		db:            db,
+	}
+}
+
+var (
+	_ metadata.CatalogReader = &MetadataReader{}
+	_ metadata.BasicReader   = &MetadataReader{}
+)
+
+// Catalogs satisfies the metadata.CatalogReader interface.
+func (r MetadataReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	var res []metadata.Catalog
+	if err := r.read(wire.KindCatalogs, f, &res); err != nil {
+		return nil, err
+	}
+	return metadata.NewCatalogSet(res), nil
+}
+
+// Schemas satisfies the metadata.SchemaReader interface.
+func (r MetadataReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	var res []metadata.Schema
+	if err := r.read(wire.KindSchemas, f, &res); err != nil {
+		return nil, err
+	}
+	return metadata.NewSchemaSet(res), nil
+}
+
+// Tables satisfies the metadata.TableReader interface.
+func (r MetadataReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	var res []metadata.Table
+	if err := r.read(wire.KindTables, f, &res); err != nil {
+		return nil, err
+	}
+	return metadata.NewTableSet(res), nil
+}
+
+// Columns satisfies the metadata.ColumnReader interface.
+func (r MetadataReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	var res []metadata.Column
+	if err := r.read(wire.KindColumns, f, &res); err != nil {
+		return nil, err
+	}
+	return metadata.NewColumnSet(res), nil
+}
+
+// read reads the metadata of the kind matching the filter to v, a pointer
+// to a slice of the metadata type. The fields of the returned objects are
+// matched with the fields of the type ignoring case.
+func (r MetadataReader) read(kind string, f metadata.Filter, v interface{}) error {
+	return raw(context.Background(), r.db, func(c *wire.Conn) error {
+		rows, err := c.Metadata(kind, wire.Filter{
+			Catalog: f.Catalog,
+			Schema:  f.Schema,
+			Name:    f.Name,
+			Parent:  f.Parent,
+			Types:   f.Types,
+		})
+		if err != nil {
+			return err
+		}
+		buf, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(buf, v)
+	})
+}
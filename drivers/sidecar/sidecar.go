@@ -0,0 +1,57 @@
+// Package sidecar defines and registers usql's Sidecar driver.
+//
+// The Sidecar driver executes statements with a sidecar process implementing
+// usql's sidecar protocol (see the wire package), allowing drivers for
+// databases without a Go driver to be written in any language.
+//
+// See: https://github.com/ildus/usql/tree/master/drivers/sidecar/wire
+package sidecar
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/sidecar/wire" // DRIVER
+	"github.com/ildus/usql/text"
+)
+
+func init() {
+	drivers.Register("sidecar", drivers.Driver{
+		AllowMultilineComments: true,
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			var ver string
+			err := raw(ctx, db, func(c *wire.Conn) error {
+				ver = c.Version()
+				return nil
+			})
+			if err != nil || ver == "" {
+				ver = "<unknown>"
+			}
+			return ver, nil
+		},
+		NewMetadataReader: NewMetadataReader,
+		Placeholder:       func(int) string { return "?" },
+	})
+}
+
+// raw calls f with the sidecar connection of db.
+func raw(ctx context.Context, db drivers.DB, f func(*wire.Conn) error) error {
+	sqldb, ok := db.(*sql.DB)
+	if !ok {
+		return text.ErrNotSupported
+	}
+	conn, err := sqldb.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc interface{}) error {
+		c, ok := dc.(*wire.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected sidecar connection %T", dc)
+		}
+		return f(c)
+	})
+}
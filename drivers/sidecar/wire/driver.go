@@ -0,0 +1,410 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	sql.Register("sidecar", Driver{})
+}
+
+// fetchSize is the number of rows read with each next request.
+const fetchSize = 100
+
+// Driver is a database/sql driver executing statements with a sidecar
+// process.
+type Driver struct{}
+
+// Open satisfies the driver.Driver interface, starting the sidecar for the
+// DSN and connecting it to the database.
+func (Driver) Open(dsn string) (driver.Conn, error) {
+	name, _, ok := strings.Cut(dsn, ":")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid sidecar dsn %q: must be <name>:<dsn>", dsn)
+	}
+	cmd := exec.Command("usql-sidecar-" + name)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := &Conn{
+		cmd:   cmd,
+		stdin: stdin,
+		enc:   json.NewEncoder(stdin),
+		dec:   json.NewDecoder(bufio.NewReader(stdout)),
+	}
+	c.dec.UseNumber()
+	var res ConnectResult
+	if err := c.call(context.Background(), MethodConnect, ConnectParams{DSN: dsn}, &res); err != nil {
+		c.kill()
+		return nil, err
+	}
+	c.version = res.Version
+	return c, nil
+}
+
+// Error is a sidecar error response.
+type Error struct {
+	Method string
+	Msg    string
+}
+
+// Error satisfies the error interface.
+func (err *Error) Error() string {
+	return err.Msg
+}
+
+// Conn is a connection to a sidecar process.
+type Conn struct {
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	enc     *json.Encoder
+	dec     *json.Decoder
+	version string
+	// rows are the open rows, closed before executing another statement.
+	rows *Rows
+	// broken is the error of a failed or canceled exchange, after which the
+	// protocol stream is out of sync.
+	broken error
+}
+
+// call sends a request to the sidecar, decoding the result of its response to
+// v, when not nil. When ctx is done before the response is read, the sidecar
+// is killed, as the protocol stream is out of sync, and driver.ErrBadConn is
+// returned.
+func (c *Conn) call(ctx context.Context, method string, params, v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broken != nil {
+		return driver.ErrBadConn
+	}
+	var res Response
+	var err error
+	if ctx.Done() == nil {
+		err = c.exchange(method, params, &res)
+	} else {
+		done := make(chan error, 1)
+		go func() {
+			done <- c.exchange(method, params, &res)
+		}()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			_ = c.cmd.Process.Kill()
+			<-done
+			c.broken = ctx.Err()
+			return driver.ErrBadConn
+		}
+	}
+	if err != nil {
+		c.broken = err
+		return err
+	}
+	if res.Error != "" {
+		return &Error{Method: method, Msg: res.Error}
+	}
+	if v == nil || len(res.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Result, v)
+}
+
+// exchange writes the request to the sidecar, and reads its response.
+func (c *Conn) exchange(method string, params interface{}, res *Response) error {
+	if err := c.enc.Encode(Request{Method: method, Params: params}); err != nil {
+		return err
+	}
+	if err := c.dec.Decode(res); err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("sidecar exited during %s: %w", method, io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+	return nil
+}
+
+// Version returns the database version reported by the sidecar on connect.
+func (c *Conn) Version() string {
+	return c.version
+}
+
+// Metadata returns the catalogs, schemas, tables, or columns (see the Kind
+// constants) matching the filter.
+func (c *Conn) Metadata(kind string, f Filter) ([]json.RawMessage, error) {
+	if err := c.closeRows(); err != nil {
+		return nil, err
+	}
+	var res MetadataResult
+	if err := c.call(context.Background(), MethodMetadata, MetadataParams{Kind: kind, Filter: f}, &res); err != nil {
+		return nil, err
+	}
+	return res.Rows, nil
+}
+
+// Prepare satisfies the driver.Conn interface.
+func (c *Conn) Prepare(query string) (driver.Stmt, error) {
+	return &Stmt{c: c, query: query}, nil
+}
+
+// Close satisfies the driver.Conn interface, stopping the sidecar.
+func (c *Conn) Close() error {
+	var err error
+	if c.broken == nil {
+		err = c.call(context.Background(), MethodClose, nil, nil)
+	}
+	c.stdin.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.cmd.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.kill()
+	}
+	return err
+}
+
+// IsValid satisfies the driver.Validator interface, discarding connections
+// whose sidecar was killed or whose protocol stream is out of sync.
+func (c *Conn) IsValid() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.broken == nil
+}
+
+// kill kills the sidecar.
+func (c *Conn) kill() {
+	_ = c.cmd.Process.Kill()
+	_ = c.cmd.Wait()
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c *Conn) Begin() (driver.Tx, error) {
+	if err := c.closeRows(); err != nil {
+		return nil, err
+	}
+	if err := c.call(context.Background(), MethodBegin, nil, nil); err != nil {
+		return nil, err
+	}
+	return &Tx{c: c}, nil
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.closeRows(); err != nil {
+		return nil, err
+	}
+	params, err := statementParams(query, args)
+	if err != nil {
+		return nil, err
+	}
+	var res QueryResult
+	if err := c.call(ctx, MethodQuery, params, &res); err != nil {
+		return nil, err
+	}
+	c.rows = &Rows{c: c, cols: res.Columns}
+	return c.rows, nil
+}
+
+// ExecContext satisfies the driver.ExecerContext interface.
+func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.closeRows(); err != nil {
+		return nil, err
+	}
+	params, err := statementParams(query, args)
+	if err != nil {
+		return nil, err
+	}
+	var res ExecResult
+	if err := c.call(ctx, MethodExec, params, &res); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.RowsAffected), nil
+}
+
+// closeRows closes the open rows, if any.
+func (c *Conn) closeRows() error {
+	if c.rows == nil {
+		return nil
+	}
+	return c.rows.Close()
+}
+
+// statementParams returns the params of a query or exec request.
+func statementParams(query string, args []driver.NamedValue) (StatementParams, error) {
+	params := StatementParams{SQL: query, Args: make([]interface{}, len(args))}
+	for i, arg := range args {
+		if arg.Name != "" {
+			return params, errors.New("sidecar does not support named parameters")
+		}
+		switch v := arg.Value.(type) {
+		case []byte:
+			params.Args[i] = string(v)
+		case time.Time:
+			params.Args[i] = v.Format(time.RFC3339Nano)
+		default:
+			params.Args[i] = v
+		}
+	}
+	return params, nil
+}
+
+// Stmt is a prepared statement, sent to the sidecar when executed.
+type Stmt struct {
+	c     *Conn
+	query string
+}
+
+// Close satisfies the driver.Stmt interface.
+func (s *Stmt) Close() error {
+	return nil
+}
+
+// NumInput satisfies the driver.Stmt interface.
+func (s *Stmt) NumInput() int {
+	return -1
+}
+
+// Exec satisfies the driver.Stmt interface.
+func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+// Query satisfies the driver.Stmt interface.
+func (s *Stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues converts args to named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	v := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		v[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return v
+}
+
+// Tx is a sidecar transaction.
+type Tx struct {
+	c *Conn
+}
+
+// Commit satisfies the driver.Tx interface.
+func (tx *Tx) Commit() error {
+	if err := tx.c.closeRows(); err != nil {
+		return err
+	}
+	return tx.c.call(context.Background(), MethodCommit, nil, nil)
+}
+
+// Rollback satisfies the driver.Tx interface.
+func (tx *Tx) Rollback() error {
+	if err := tx.c.closeRows(); err != nil {
+		return err
+	}
+	return tx.c.call(context.Background(), MethodRollback, nil, nil)
+}
+
+// Rows are the rows of a query, read from the sidecar in batches.
+type Rows struct {
+	c    *Conn
+	cols []Column
+	buf  [][]interface{}
+	done bool
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *Rows) Columns() []string {
+	names := make([]string, len(r.cols))
+	for i, col := range r.cols {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *Rows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.cols[i].Type
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *Rows) Next(dest []driver.Value) error {
+	for len(r.buf) == 0 {
+		if r.done {
+			return io.EOF
+		}
+		var res NextResult
+		if err := r.c.call(context.Background(), MethodNext, NextParams{Size: fetchSize}, &res); err != nil {
+			return err
+		}
+		r.buf, r.done = res.Rows, res.Done || len(res.Rows) == 0
+	}
+	row := r.buf[0]
+	r.buf = r.buf[1:]
+	for i := range dest {
+		if i < len(row) {
+			dest[i] = value(row[i])
+		} else {
+			dest[i] = nil
+		}
+	}
+	return nil
+}
+
+// Close satisfies the driver.Rows interface, closing the cursor when not
+// all rows were read.
+func (r *Rows) Close() error {
+	if r.c.rows != r {
+		return nil
+	}
+	r.c.rows = nil
+	if r.done {
+		return nil
+	}
+	r.done, r.buf = true, nil
+	return r.c.call(context.Background(), MethodCloseRows, nil, nil)
+}
+
+// value converts a decoded JSON value to a driver value.
+func value(v interface{}) driver.Value {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+		return x.String()
+	case nil, bool, string:
+		return x
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(buf)
+}
@@ -0,0 +1,163 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("USQL_TEST_SIDECAR") == "1" {
+		testSidecar()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testSidecar is a sidecar serving a table of 3 rows.
+func testSidecar() {
+	dec := json.NewDecoder(bufio.NewReader(os.Stdin))
+	enc := json.NewEncoder(os.Stdout)
+	rows := [][]interface{}{{1, "a"}, {2, nil}, {3, "c"}}
+	var cursor [][]interface{}
+	for {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		var res interface{}
+		switch req.Method {
+		case MethodConnect:
+			res = ConnectResult{Version: "Test 1.0"}
+		case MethodQuery:
+			var params StatementParams
+			_ = json.Unmarshal(req.Params, &params)
+			switch params.SQL {
+			case "SELECT missing":
+				_ = enc.Encode(Response{Error: "no such column: missing"})
+				continue
+			case "SELECT sleep":
+				time.Sleep(time.Minute)
+			}
+			cursor = rows
+			res = QueryResult{Columns: []Column{{"a", "INTEGER"}, {"b", "TEXT"}}}
+		case MethodNext:
+			var params NextParams
+			_ = json.Unmarshal(req.Params, &params)
+			n := min(params.Size, 2, len(cursor))
+			res = NextResult{Rows: cursor[:n], Done: n == len(cursor)}
+			cursor = cursor[n:]
+		case MethodExec:
+			res = ExecResult{RowsAffected: 3}
+		case MethodMetadata:
+			res = json.RawMessage(`{"rows": [{"schema": "main", "name": "t", "type": "TABLE"}]}`)
+		case MethodClose:
+			_ = enc.Encode(Response{})
+			return
+		}
+		buf, _ := json.Marshal(res)
+		_ = enc.Encode(Response{Result: buf})
+	}
+}
+
+func TestDriver(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nUSQL_TEST_SIDECAR=1 exec " + exe + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "usql-sidecar-test"), []byte(script), 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	db, err := sql.Open("sidecar", "test:db")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	rows, err := db.Query("SELECT a, b FROM t WHERE a > ?", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var res [][]interface{}
+	for rows.Next() {
+		var a int64
+		var b sql.NullString
+		if err := rows.Scan(&a, &b); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		res = append(res, []interface{}{a, b.String})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := [][]interface{}{{int64(1), "a"}, {int64(2), ""}, {int64(3), "c"}}; !reflect.DeepEqual(res, exp) {
+		t.Errorf("expected %v, got: %v", exp, res)
+	}
+	// unread rows are closed before the next statement
+	rows, err = db.Query("SELECT a, b FROM t")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !rows.Next() {
+		t.Fatalf("expected a row")
+	}
+	rows.Close()
+	r, err := db.Exec("DELETE FROM t")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n, _ := r.RowsAffected(); n != 3 {
+		t.Errorf("expected 3 rows affected, got: %d", n)
+	}
+	if _, err := db.Query("SELECT missing"); err == nil || err.Error() != "no such column: missing" {
+		t.Errorf("expected no such column error, got: %v", err)
+	}
+	// canceled statements kill the sidecar, and the connection is replaced
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := db.QueryContext(ctx, "SELECT sleep"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got: %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("expected statement to be canceled, took: %v", d)
+	}
+	if _, err := db.Exec("DELETE FROM t"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer conn.Close()
+	err = conn.Raw(func(dc interface{}) error {
+		c := dc.(*Conn)
+		if ver := c.Version(); ver != "Test 1.0" {
+			t.Errorf("expected version Test 1.0, got: %s", ver)
+		}
+		tables, err := c.Metadata(KindTables, Filter{Name: "t%"})
+		if err != nil {
+			return err
+		}
+		if len(tables) != 1 {
+			t.Errorf("expected 1 table, got: %d", len(tables))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
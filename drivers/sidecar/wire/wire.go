@@ -0,0 +1,150 @@
+// Package wire implements usql's sidecar protocol, and a database/sql driver
+// executing statements with a sidecar process, allowing drivers for databases
+// without a Go driver to be written in any language (such as a JDBC bridge).
+//
+// A sidecar is an executable named usql-sidecar-<name> on the PATH, started
+// for each database connection with a DSN of the form <name>:<rest>. Requests
+// are written to the sidecar's standard input, and responses read from its
+// standard output, as newline delimited JSON objects, with exactly one
+// response for every request, in order. The sidecar's standard error is
+// passed through.
+//
+// Requests have a method and its params:
+//
+//	{"method": "connect", "params": {"dsn": "jdbc:postgresql://localhost/db"}}
+//	{"method": "query", "params": {"sql": "SELECT * FROM t WHERE a > ?", "args": [1]}}
+//	{"method": "next", "params": {"size": 100}}
+//	{"method": "close_rows"}
+//	{"method": "exec", "params": {"sql": "DELETE FROM t", "args": []}}
+//	{"method": "begin"}
+//	{"method": "commit"}
+//	{"method": "rollback"}
+//	{"method": "metadata", "params": {"kind": "tables", "filter": {"schema": "public", "name": "a%"}}}
+//	{"method": "close"}
+//
+// Responses have the result of the method, or an error:
+//
+//	{"result": {"version": "PostgreSQL 16.2"}}
+//	{"result": {"columns": [{"name": "a", "type": "INTEGER"}, {"name": "b", "type": "TEXT"}]}}
+//	{"result": {"rows": [[2, "x"], [3, null]], "done": false}}
+//	{"result": {"rows_affected": 1}}
+//	{"error": "relation \"t\" does not exist"}
+//
+// A query opens a cursor, whose rows are read with next (at most size rows
+// per response) until done, which closes the cursor. Cursors not read until
+// done are closed with close_rows. Only one cursor is open at a time. Values
+// are JSON strings, numbers, booleans, or null; binary values are sent as
+// strings, and times as RFC 3339 strings.
+//
+// The metadata method lists the catalogs, schemas, tables, or columns
+// matching the filter, as JSON objects with the fields of the metadata
+// package's Catalog, Schema, Table, and Column types (for example, {"schema":
+// "public", "name": "books", "type": "TABLE"}). The filter's catalog,
+// schema, name, and parent (the table of columns) are LIKE patterns, and are
+// omitted when empty. Sidecars not supporting a kind respond with an error.
+//
+// A query or exec request canceled before its response is read (such as with
+// STATEMENT_TIMEOUT or Ctrl+C) kills the sidecar, and its connection is
+// discarded.
+package wire
+
+import (
+	"encoding/json"
+)
+
+// Request is a sidecar request.
+type Request struct {
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// Response is a sidecar response.
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Methods.
+const (
+	MethodConnect   = "connect"
+	MethodQuery     = "query"
+	MethodNext      = "next"
+	MethodCloseRows = "close_rows"
+	MethodExec      = "exec"
+	MethodBegin     = "begin"
+	MethodCommit    = "commit"
+	MethodRollback  = "rollback"
+	MethodMetadata  = "metadata"
+	MethodClose     = "close"
+)
+
+// ConnectParams are the params of a connect request.
+type ConnectParams struct {
+	DSN string `json:"dsn"`
+}
+
+// ConnectResult is the result of a connect request.
+type ConnectResult struct {
+	Version string `json:"version,omitempty"`
+}
+
+// StatementParams are the params of a query or exec request.
+type StatementParams struct {
+	SQL  string        `json:"sql"`
+	Args []interface{} `json:"args"`
+}
+
+// Column is a result column.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// QueryResult is the result of a query request.
+type QueryResult struct {
+	Columns []Column `json:"columns"`
+}
+
+// NextParams are the params of a next request.
+type NextParams struct {
+	Size int `json:"size"`
+}
+
+// NextResult is the result of a next request.
+type NextResult struct {
+	Rows [][]interface{} `json:"rows"`
+	Done bool            `json:"done"`
+}
+
+// ExecResult is the result of an exec request.
+type ExecResult struct {
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+// Metadata kinds.
+const (
+	KindCatalogs = "catalogs"
+	KindSchemas  = "schemas"
+	KindTables   = "tables"
+	KindColumns  = "columns"
+)
+
+// Filter is the filter of a metadata request.
+type Filter struct {
+	Catalog string   `json:"catalog,omitempty"`
+	Schema  string   `json:"schema,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Parent  string   `json:"parent,omitempty"`
+	Types   []string `json:"types,omitempty"`
+}
+
+// MetadataParams are the params of a metadata request.
+type MetadataParams struct {
+	Kind   string `json:"kind"`
+	Filter Filter `json:"filter"`
+}
+
+// MetadataResult is the result of a metadata request.
+type MetadataResult struct {
+	Rows []json.RawMessage `json:"rows"`
+}
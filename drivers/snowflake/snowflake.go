@@ -4,8 +4,13 @@
 package snowflake
 
 import (
+	"context"
+	"database/sql"
 	"io"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/snowflakedb/gosnowflake" // DRIVER
@@ -39,6 +44,35 @@ func init() {
 			}
 			return "", err.Error()
 		},
+		ResultID: func(ctx context.Context) (context.Context, func() string) {
+			ch, id := make(chan string, 1), ""
+			return gosnowflake.WithQueryIDChan(ctx, ch), func() string {
+				select {
+				case id = <-ch:
+				default:
+				}
+				return id
+			}
+		},
+		QueryResult: func(ctx context.Context, db drivers.DB, id string) (*sql.Rows, error) {
+			return db.QueryContext(gosnowflake.WithFetchResultByID(ctx, id), "")
+		},
+		QueryTag: func(ctx context.Context, conn drivers.Conn, tag string) (context.Context, error) {
+			// the context may carry the query id channel of the statement
+			_, err := conn.ExecContext(context.Background(), `ALTER SESSION SET QUERY_TAG = `+quoteLiteral(tag))
+			return ctx, err
+		},
+		StatementTimeout: func(ctx context.Context, conn drivers.Conn, d time.Duration) (context.Context, error) {
+			sqlstr := `ALTER SESSION UNSET STATEMENT_TIMEOUT_IN_SECONDS`
+			if d != 0 {
+				sqlstr = `ALTER SESSION SET STATEMENT_TIMEOUT_IN_SECONDS = ` + strconv.Itoa(int(math.Ceil(d.Seconds())))
+			}
+			_, err := conn.ExecContext(context.Background(), sqlstr)
+			return ctx, err
+		},
+		Sample: func(_ context.Context, _ drivers.DB, table string, n int) (string, error) {
+			return `SELECT * FROM ` + table + ` SAMPLE (` + strconv.Itoa(n) + ` ROWS)`, nil
+		},
 		NewMetadataReader: newReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			writerOpts := []metadata.WriterOption{
@@ -51,6 +85,11 @@ func init() {
 	})
 }
 
+// quoteLiteral returns a quoted string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\\`, `\\\\`, "'", "''").Replace(s) + "'"
+}
+
 // logger is an empty logger.
 type logger struct {
 	*logrus.Logger
@@ -27,6 +27,7 @@ var (
 	_ metadata.FunctionColumnReader = &MetadataReader{}
 	_ metadata.IndexReader          = &MetadataReader{}
 	_ metadata.IndexColumnReader    = &MetadataReader{}
+	_ metadata.SpatialColumnReader  = &MetadataReader{}
 )
 
 func (r *MetadataReader) SetLimit(l int) {
@@ -317,6 +318,87 @@ JOIN pragma_index_xinfo(i.name) ic`
 	return metadata.NewIndexColumnSet(results), nil
 }
 
+// SpatialColumns from the SpatiaLite geometry_columns table, when the
+// database has been initialized with SpatiaLite metadata (version 4 or newer).
+func (r MetadataReader) SpatialColumns(f metadata.Filter) (*metadata.SpatialColumnSet, error) {
+	ok, err := r.hasTable("geometry_columns")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return metadata.NewSpatialColumnSet([]metadata.SpatialColumn{}), nil
+	}
+	qstr := `SELECT
+  f_table_name,
+  f_geometry_column,
+  CASE geometry_type % 1000
+    WHEN 1 THEN 'POINT'
+    WHEN 2 THEN 'LINESTRING'
+    WHEN 3 THEN 'POLYGON'
+    WHEN 4 THEN 'MULTIPOINT'
+    WHEN 5 THEN 'MULTILINESTRING'
+    WHEN 6 THEN 'MULTIPOLYGON'
+    WHEN 7 THEN 'GEOMETRYCOLLECTION'
+    ELSE 'GEOMETRY'
+  END || CASE geometry_type / 1000
+    WHEN 1 THEN ' Z'
+    WHEN 2 THEN ' M'
+    WHEN 3 THEN ' ZM'
+    ELSE ''
+  END,
+  CAST(srid AS TEXT),
+  CAST(coord_dimension AS TEXT),
+  CASE spatial_index_enabled
+    WHEN 1 THEN 'idx_' || f_table_name || '_' || f_geometry_column
+    WHEN 2 THEN 'cache_' || f_table_name || '_' || f_geometry_column
+    ELSE ''
+  END
+FROM geometry_columns`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, "f_table_name LIKE ?")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "f_geometry_column LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "f_table_name, f_geometry_column", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.SpatialColumn{}
+	for rows.Next() {
+		rec := metadata.SpatialColumn{}
+		err = rows.Scan(&rec.Table, &rec.Name, &rec.Type, &rec.SRID, &rec.Dimensions, &rec.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSpatialColumnSet(results), nil
+}
+
+// hasTable returns true when the database has the table.
+func (r MetadataReader) hasTable(name string) (bool, error) {
+	rows, closeRows, err := r.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, name)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	defer closeRows()
+	ok := rows.Next()
+	return ok, rows.Err()
+}
+
 func (r MetadataReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
@@ -3,6 +3,7 @@ package sqlserver
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ildus/usql/drivers"
@@ -18,6 +19,13 @@ type metaReader struct {
 var _ metadata.CatalogReader = &metaReader{}
 var _ metadata.IndexReader = &metaReader{}
 var _ metadata.IndexColumnReader = &metaReader{}
+var _ metadata.LockReader = &metaReader{}
+var _ metadata.SizeReader = &metaReader{}
+var _ metadata.RoleReader = &metaReader{}
+var _ metadata.CurrentSchemaReader = &metaReader{}
+var _ metadata.TypeReader = &metaReader{}
+var _ metadata.IndexStatReader = &metaReader{}
+var _ metadata.DependencyReader = &metaReader{}
 
 func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
 	ir := infos.New(
@@ -27,6 +35,8 @@ func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
 		infos.WithConstraints(false),
 		infos.WithCustomClauses(map[infos.ClauseName]string{
 			infos.FunctionsSecurityType: "''",
+			infos.ColumnsComment:        "COALESCE(CAST((SELECT ep.value FROM sys.extended_properties ep WHERE ep.class = 1 AND ep.major_id = OBJECT_ID(QUOTENAME(table_schema) + '.' + QUOTENAME(table_name)) AND ep.minor_id = COLUMNPROPERTY(OBJECT_ID(QUOTENAME(table_schema) + '.' + QUOTENAME(table_name)), column_name, 'ColumnId') AND ep.name = 'MS_Description') AS nvarchar(4000)), '')",
+			infos.TablesComment:         "COALESCE(CAST((SELECT ep.value FROM sys.extended_properties ep WHERE ep.class = 1 AND ep.major_id = OBJECT_ID(QUOTENAME(table_schema) + '.' + QUOTENAME(table_name)) AND ep.minor_id = 0 AND ep.name = 'MS_Description') AS nvarchar(4000)), '')",
 		}),
 		infos.WithSystemSchemas([]string{
 			"db_accessadmin",
@@ -225,6 +235,455 @@ JOIN sys.types ty ON ty.user_type_id = c.user_type_id
 	return metadata.NewIndexColumnSet(results), nil
 }
 
+func (r metaReader) Locks(f metadata.Filter) (*metadata.LockSet, error) {
+	qstr := `
+SELECT * FROM (
+  SELECT
+    CAST(l.request_session_id AS varchar(20)) AS session_id,
+    COALESCE(s.login_name, '') AS login_name,
+    l.resource_type,
+    COALESCE(CASE
+      WHEN l.resource_type = 'OBJECT' THEN OBJECT_SCHEMA_NAME(l.resource_associated_entity_id, l.resource_database_id)
+      ELSE OBJECT_SCHEMA_NAME(p.object_id, l.resource_database_id)
+    END, '') AS schema_name,
+    COALESCE(CASE
+      WHEN l.resource_type = 'OBJECT' THEN OBJECT_NAME(l.resource_associated_entity_id, l.resource_database_id)
+      WHEN p.object_id IS NOT NULL THEN OBJECT_NAME(p.object_id, l.resource_database_id)
+      ELSE DB_NAME(l.resource_database_id)
+    END, '') AS object_name,
+    l.request_mode,
+    CASE WHEN l.request_status = 'GRANT' THEN 'YES' ELSE 'NO' END AS granted,
+    CASE WHEN l.request_status = 'GRANT' THEN '' ELSE COALESCE(CAST(NULLIF(q.blocking_session_id, 0) AS varchar(20)), '') END AS blocked_by,
+    COALESCE(t.text, '') AS query
+  FROM sys.dm_tran_locks l
+  LEFT JOIN sys.partitions p ON p.hobt_id = l.resource_associated_entity_id AND l.resource_type IN ('PAGE', 'KEY', 'RID', 'HOBT')
+  LEFT JOIN sys.dm_exec_sessions s ON s.session_id = l.request_session_id
+  LEFT JOIN sys.dm_exec_requests q ON q.session_id = l.request_session_id
+  LEFT JOIN sys.dm_exec_connections c ON c.session_id = l.request_session_id
+  OUTER APPLY sys.dm_exec_sql_text(c.most_recent_sql_handle) t
+  WHERE l.request_session_id <> @@SPID
+) x
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("x.schema_name LIKE @p%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("x.object_name LIKE @p%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "CAST(x.session_id AS int), x.granted DESC, x.schema_name, x.object_name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Lock{}
+	for rows.Next() {
+		rec := metadata.Lock{}
+		err = rows.Scan(&rec.Session, &rec.User, &rec.Type, &rec.Schema, &rec.Object, &rec.Mode, &rec.Granted, &rec.BlockedBy, &rec.Query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewLockSet(results), nil
+}
+
+// Sizes of tables and indexes, from their partition stats, with the
+// fragmentation of the heap or index (in the fast LIMITED mode) as the bloat
+// estimate.
+func (r metaReader) Sizes(f metadata.Filter) (*metadata.SizeSet, error) {
+	qstr := `
+SELECT
+  db_name(),
+  s.name,
+  CASE WHEN i.index_id < 2 THEN t.name ELSE i.name END,
+  CASE WHEN i.index_id < 2 THEN 'TABLE' ELSE 'INDEX' END,
+  CASE WHEN i.index_id < 2 THEN '' ELSE t.name END,
+  ps.row_count,
+  ps.reserved_page_count * 8192,
+  CASE WHEN i.index_id < 2 THEN (
+    SELECT SUM(x.reserved_page_count) FROM sys.dm_db_partition_stats x WHERE x.object_id = t.object_id AND x.index_id > 1
+  ) * 8192 END,
+  COALESCE(pf.avg_fragmentation_in_percent, -1)
+FROM sys.tables t
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+JOIN sys.indexes i ON i.object_id = t.object_id
+CROSS APPLY (
+  SELECT SUM(p.row_count) AS row_count, SUM(p.reserved_page_count) AS reserved_page_count
+  FROM sys.dm_db_partition_stats p
+  WHERE p.object_id = i.object_id AND p.index_id = i.index_id
+) ps
+OUTER APPLY (
+  SELECT MAX(avg_fragmentation_in_percent) AS avg_fragmentation_in_percent
+  FROM sys.dm_db_index_physical_stats(DB_ID(), i.object_id, i.index_id, NULL, 'LIMITED')
+) pf
+`
+	conds := []string{}
+	vals := []interface{}{}
+	index := false
+	for _, typ := range f.Types {
+		index = index || typ == "INDEX"
+	}
+	if !index {
+		conds = append(conds, "i.index_id < 2")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "t.is_ms_shipped = 0")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("s.name LIKE @p%d", len(vals)))
+	} else {
+		conds = append(conds, "s.name = schema_name()")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("t.name LIKE @p%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "s.name, t.name, i.index_id", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Size{}
+	for rows.Next() {
+		rec := metadata.Size{}
+		var n, size int64
+		var indexSize sql.NullInt64
+		var frag float64
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.Table, &n, &size, &indexSize, &frag)
+		if err != nil {
+			return nil, err
+		}
+		rec.Rows, rec.Size = strconv.FormatInt(n, 10), metadata.FormatSize(size)
+		if rec.Type == "TABLE" {
+			rec.IndexSize = metadata.FormatSize(indexSize.Int64)
+			rec.TotalSize = metadata.FormatSize(size + indexSize.Int64)
+		}
+		if frag >= 0 {
+			rec.Bloat = fmt.Sprintf("%.1f%% fragmented", frag)
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSizeSet(results), nil
+}
+
+// IndexStats lists the usage statistics of indexes (dm_db_index_usage_stats),
+// with the least used indexes first. Scans are the seeks, scans, and lookups
+// of user queries. Clustered indexes, storing the rows of their table, are
+// required.
+func (r metaReader) IndexStats(f metadata.Filter) (*metadata.IndexStatSet, error) {
+	qstr := `
+SELECT
+  db_name(),
+  s.name,
+  t.name,
+  i.name,
+  COALESCE(u.user_seeks + u.user_scans + u.user_lookups, 0),
+  COALESCE(ps.used_page_count, 0) * 8192,
+  COALESCE(CONVERT(varchar(19), (
+    SELECT MAX(x.v) FROM (VALUES (u.last_user_seek), (u.last_user_scan), (u.last_user_lookup)) x(v)
+  ), 120), ''),
+  CASE WHEN i.is_primary_key = 1 OR i.is_unique_constraint = 1 OR i.is_unique = 1 OR i.index_id = 1 THEN 1 ELSE 0 END,
+  LOWER(i.type_desc) + COALESCE(' (' + STUFF((
+    SELECT ', ' + c.name
+    FROM sys.index_columns ic
+    JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+    WHERE ic.object_id = i.object_id AND ic.index_id = i.index_id AND ic.is_included_column = 0
+    ORDER BY ic.key_ordinal
+    FOR XML PATH('')
+  ), 1, 2, '') + ')', '')
+FROM sys.tables t
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+JOIN sys.indexes i ON i.object_id = t.object_id
+LEFT JOIN sys.dm_db_index_usage_stats u ON u.database_id = DB_ID() AND u.object_id = i.object_id AND u.index_id = i.index_id
+OUTER APPLY (
+  SELECT SUM(p.used_page_count) AS used_page_count
+  FROM sys.dm_db_partition_stats p
+  WHERE p.object_id = i.object_id AND p.index_id = i.index_id
+) ps
+`
+	conds := []string{"i.index_id > 0", "i.is_hypothetical = 0"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "t.is_ms_shipped = 0")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("s.name LIKE @p%d", len(vals)))
+	} else {
+		conds = append(conds, "s.name = schema_name()")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("(t.name LIKE @p%d OR i.name LIKE @p%d)", len(vals), len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "5, 6 DESC, s.name, t.name, i.name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.IndexStat{}
+	for rows.Next() {
+		rec := metadata.IndexStat{}
+		var size int64
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.Scans, &size, &rec.LastUsed, &rec.Required, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		rec.Size = metadata.FormatSize(size)
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewIndexStatSet(results), nil
+}
+
+// Dependencies lists the objects referenced by views, functions, procedures,
+// and triggers, as recorded in sys.sql_expression_dependencies. Objects
+// referenced by name only (such as in other databases) have an empty type.
+func (r metaReader) Dependencies(f metadata.Filter) (*metadata.DependencySet, error) {
+	qstr := `
+SELECT DISTINCT
+  db_name(),
+  s.name,
+  o.name,
+  ` + objectType("o") + `,
+  COALESCE(d.referenced_schema_name, rs.name, ''),
+  d.referenced_entity_name,
+  COALESCE(` + objectType("ro") + `, '')
+FROM sys.sql_expression_dependencies d
+JOIN sys.objects o ON o.object_id = d.referencing_id
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+LEFT JOIN sys.objects ro ON ro.object_id = d.referenced_id
+LEFT JOIN sys.schemas rs ON rs.schema_id = ro.schema_id
+`
+	conds := []string{"d.referencing_minor_id = 0", "d.referenced_entity_name IS NOT NULL"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "o.is_ms_shipped = 0", "COALESCE(ro.is_ms_shipped, 0) = 0")
+	}
+	// filter by the referenced object, when set
+	schema, name := "s.name", "o.name"
+	if f.Reference != "" {
+		schema, name = "COALESCE(d.referenced_schema_name, rs.name, schema_name())", "d.referenced_entity_name"
+		f.Name = f.Reference
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("%s LIKE @p%d", schema, len(vals)))
+	} else {
+		conds = append(conds, schema+" = schema_name()")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("%s LIKE @p%d", name, len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "2, 3, 5, 6", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Dependency{}
+	for rows.Next() {
+		rec := metadata.Dependency{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.RefSchema, &rec.RefName, &rec.RefType)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewDependencySet(results), nil
+}
+
+// objectType returns the expression of the type of the sys.objects row
+// aliased as alias.
+func objectType(alias string) string {
+	return `CASE
+    WHEN ` + alias + `.type IN ('U', 'IT', 'S') THEN 'TABLE'
+    WHEN ` + alias + `.type = 'V' THEN 'VIEW'
+    WHEN ` + alias + `.type IN ('P', 'PC', 'X') THEN 'PROCEDURE'
+    WHEN ` + alias + `.type IN ('FN', 'IF', 'TF', 'FS', 'FT', 'AF') THEN 'FUNCTION'
+    WHEN ` + alias + `.type IN ('TR', 'TA') THEN 'TRIGGER'
+    WHEN ` + alias + `.type = 'SN' THEN 'SYNONYM'
+    WHEN ` + alias + `.type = 'SO' THEN 'SEQUENCE'
+    ELSE ` + alias + `.type_desc
+  END`
+}
+
+// Roles lists the server logins and roles, with the server roles they are
+// members of. Logins are superusers when they are members of sysadmin. The
+// fixed server roles, and the internal (##) and NT logins are only listed with
+// system objects.
+func (r metaReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	qstr := `
+SELECT
+  p.name,
+  CASE WHEN p.type <> 'R' AND p.is_disabled = 0 THEN 'YES' ELSE 'NO' END,
+  CASE WHEN p.name = 'sysadmin' OR IS_SRVROLEMEMBER('sysadmin', p.name) = 1 THEN 'YES' ELSE 'NO' END,
+  p.type_desc
+    + CASE WHEN p.is_disabled = 1 THEN ', DISABLED' ELSE '' END
+    + CASE WHEN l.is_policy_checked = 1 THEN ', PASSWORD POLICY' ELSE '' END
+    + CASE WHEN LOGINPROPERTY(p.name, 'IsLocked') = 1 THEN ', LOCKED' ELSE '' END
+    + CASE WHEN LOGINPROPERTY(p.name, 'IsExpired') = 1 THEN ', PASSWORD EXPIRED' ELSE '' END,
+  COALESCE(STUFF((
+    SELECT ', ' + r.name
+    FROM sys.server_role_members m
+    JOIN sys.server_principals r ON r.principal_id = m.role_principal_id
+    WHERE m.member_principal_id = p.principal_id
+    ORDER BY r.name
+    FOR XML PATH('')), 1, 2, ''), ''),
+  COALESCE(CASE WHEN l.is_expiration_checked = 1
+    THEN CONVERT(varchar(19), DATEADD(day, CAST(LOGINPROPERTY(p.name, 'DaysUntilExpiration') AS int), GETDATE()), 120) END, ''),
+  ''
+FROM sys.server_principals p
+LEFT JOIN sys.sql_logins l ON l.principal_id = p.principal_id`
+	conds := []string{"p.type IN ('S', 'U', 'G', 'R', 'E', 'X', 'C', 'K')"}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "p.is_fixed_role = 0", "p.name <> 'public'", "p.name NOT LIKE '##%'", "p.name NOT LIKE 'NT %\\%'")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("p.name LIKE @p%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "p.name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Role{}
+	for rows.Next() {
+		rec := metadata.Role{}
+		err = rows.Scan(&rec.Name, &rec.Login, &rec.Superuser, &rec.Attributes, &rec.MemberOf, &rec.ValidUntil, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// Types lists the user-defined alias, table, and CLR types, with the base type
+// of alias types, and the columns of table types. The system types are only
+// listed with system objects.
+func (r metaReader) Types(f metadata.Filter) (*metadata.TypeSet, error) {
+	qstr := `
+SELECT
+  DB_NAME(),
+  s.name,
+  t.name,
+  CASE
+    WHEN t.is_table_type = 1 THEN 'table'
+    WHEN t.is_assembly_type = 1 THEN 'clr'
+    WHEN t.is_user_defined = 1 THEN 'alias'
+    ELSE 'base'
+  END,
+  CASE
+    WHEN t.is_table_type = 1 THEN COALESCE(STUFF((
+      SELECT ', ' + c.name + ' ' + TYPE_NAME(c.user_type_id)
+      FROM sys.table_types tt
+      JOIN sys.columns c ON c.object_id = tt.type_table_object_id
+      WHERE tt.user_type_id = t.user_type_id
+      ORDER BY c.column_id
+      FOR XML PATH('')), 1, 2, ''), '')
+    WHEN t.is_user_defined = 0 OR t.is_assembly_type = 1 THEN ''
+    ELSE TYPE_NAME(t.system_type_id)
+      + CASE
+        WHEN TYPE_NAME(t.system_type_id) IN ('varchar', 'char', 'varbinary', 'binary')
+          THEN '(' + CASE WHEN t.max_length = -1 THEN 'max' ELSE CAST(t.max_length AS varchar(10)) END + ')'
+        WHEN TYPE_NAME(t.system_type_id) IN ('nvarchar', 'nchar')
+          THEN '(' + CASE WHEN t.max_length = -1 THEN 'max' ELSE CAST(t.max_length / 2 AS varchar(10)) END + ')'
+        WHEN TYPE_NAME(t.system_type_id) IN ('decimal', 'numeric')
+          THEN '(' + CAST(t.precision AS varchar(10)) + ', ' + CAST(t.scale AS varchar(10)) + ')'
+        ELSE ''
+      END
+      + CASE WHEN t.is_nullable = 0 THEN ' NOT NULL' ELSE '' END
+  END,
+  '',
+  COALESCE(USER_NAME(COALESCE(t.principal_id, s.principal_id)), ''),
+  COALESCE(CAST(ep.value AS nvarchar(4000)), '')
+FROM sys.types t
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+LEFT JOIN sys.extended_properties ep ON ep.class = 6 AND ep.major_id = t.user_type_id AND ep.minor_id = 0 AND ep.name = 'MS_Description'`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "t.is_user_defined = 1")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("s.name LIKE @p%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("t.name LIKE @p%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "s.name, t.name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Type{}
+	for rows.Next() {
+		rec := metadata.Type{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Kind, &rec.Definition, &rec.Elements, &rec.Owner, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTypeSet(results), nil
+}
+
+// CurrentSchema returns the default schema of the user, followed by dbo and
+// sys, as unqualified names are resolved in the default schema before dbo, and
+// system views are resolved in sys.
+func (r metaReader) CurrentSchema() ([]string, error) {
+	rows, closeRows, err := r.Query(`SELECT COALESCE(SCHEMA_NAME(), 'dbo')`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var path []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		path = append(path, schema)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return append(path, "dbo", "sys"), nil
+}
+
 func (r metaReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
@@ -6,14 +6,21 @@ package sqlserver
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	sqlserver "github.com/microsoft/go-mssqldb" // DRIVER
+	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
+	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/kerberos"
 	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/stmt"
 )
 
 func init() {
@@ -21,6 +28,24 @@ func init() {
 		AllowMultilineComments:  true,
 		RequirePreviousPassword: true,
 		LexerName:               "tsql",
+		ProcedureResults:        true,
+		Terminator: &stmt.Terminator{
+			Batch: "GO",
+			BatchPrefixes: []string{
+				"CREATE PROCEDURE", "CREATE PROC", "CREATE FUNCTION", "CREATE TRIGGER",
+				"ALTER PROCEDURE", "ALTER PROC", "ALTER FUNCTION", "ALTER TRIGGER",
+				"CREATE OR ALTER PROCEDURE", "CREATE OR ALTER PROC",
+				"CREATE OR ALTER FUNCTION", "CREATE OR ALTER TRIGGER",
+			},
+			Blocks: true,
+		},
+		GSSAPI: func(u *dburl.URL) {
+			drivers.ReplaceQueryParameters([]string{"auth"}, []string{
+				"authenticator", "krb5",
+				"krb5-configfile", kerberos.ConfigPath(),
+				"krb5-credcachefile", kerberos.CCachePath(),
+			})(u)
+		},
 		Version: func(ctx context.Context, db drivers.DB) (string, error) {
 			var ver, level, edition string
 			err := db.QueryRowContext(
@@ -33,7 +58,11 @@ func init() {
 			return "Microsoft SQL Server " + ver + ", " + level + ", " + edition, nil
 		},
 		ChangePassword: func(db drivers.DB, user, newpw, oldpw string) error {
-			_, err := db.Exec(`ALTER LOGIN ` + user + ` WITH password = '` + newpw + `' old_password = '` + oldpw + `'`)
+			sqlstr := `ALTER LOGIN ` + quoteIdentifier(user) + ` WITH PASSWORD = ` + quoteLiteral(newpw)
+			if oldpw != "" {
+				sqlstr += ` OLD_PASSWORD = ` + quoteLiteral(oldpw)
+			}
+			_, err := db.Exec(sqlstr)
 			return err
 		},
 		Err: func(err error) (string, string) {
@@ -46,15 +75,136 @@ func init() {
 			}
 			return "", msg
 		},
+		Open: func(context.Context, *dburl.URL, func() io.Writer, func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			return openAccessToken, nil
+		},
 		IsPasswordErr: func(err error) bool {
 			return strings.Contains(err.Error(), "Login failed for")
 		},
+		IsSerializationErr: func(err error) bool {
+			if e, ok := err.(sqlserver.Error); ok {
+				// deadlock victim, snapshot isolation update conflict
+				return e.Number == 1205 || e.Number == 3960
+			}
+			return false
+		},
 		NewMetadataReader: NewReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(NewReader(db, opts...))(db, w)
 		},
-		Copy: drivers.CopyWithInsert(placeholder),
+		Copy:            drivers.CopyWithInsert(placeholder),
+		CopyIn:          drivers.CopyInWithInsert(placeholder),
+		Placeholder:     placeholder,
+		QuoteLiteral:    quoteLiteral,
+		QuoteIdentifier: quoteIdentifier,
+		Savepoint: func(name string) (string, string, string) {
+			return "SAVE TRANSACTION " + name, "", "ROLLBACK TRANSACTION " + name
+		},
+		OutParams: outParams,
+		Limit:     drivers.LimitSelect("TOP (%d)", true),
+		CommentOn: commentOn,
+		Sample: func(_ context.Context, _ drivers.DB, table string, n int) (string, error) {
+			return `SELECT TOP (` + strconv.Itoa(n) + `) * FROM ` + table + ` ORDER BY NEWID()`, nil
+		},
+		ColumnType: func(kind drivers.ColumnKind) string {
+			switch kind {
+			case drivers.KindFloat:
+				return "FLOAT"
+			case drivers.KindBool:
+				return "BIT"
+			case drivers.KindTimestamp:
+				return "DATETIME2"
+			case drivers.KindText:
+				return "NVARCHAR(MAX)"
+			}
+			return ""
+		},
+	})
+}
+
+// quoteLiteral quotes s as a unicode string literal.
+func quoteLiteral(s string) string {
+	return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdentifier quotes s as a bracketed identifier.
+func quoteIdentifier(s string) string {
+	return "[" + strings.ReplaceAll(s, "]", "]]") + "]"
+}
+
+// declareRE matches a DECLARE statement.
+var declareRE = regexp.MustCompile(`(?i)\bDECLARE\b`)
+
+// outParams rewrites a stored procedure call with OUTPUT parameters to select
+// the values of the output parameters as the final result set of the batch,
+// declaring the output variables (as sql_variant) when the batch does not
+// declare its variables.
+func outParams(sqlstr string) (string, string) {
+	var decls, cols []string
+	for _, v := range drivers.Variables(sqlstr) {
+		if v.Output {
+			decls = append(decls, "@"+v.Name+" sql_variant")
+			cols = append(cols, "@"+v.Name+" AS "+quoteIdentifier(v.Name))
+		}
+	}
+	if len(cols) == 0 {
+		return sqlstr, ""
+	}
+	sqlstr = strings.TrimRight(strings.TrimSpace(sqlstr), ";")
+	if !declareRE.MatchString(sqlstr) {
+		sqlstr = "DECLARE " + strings.Join(decls, ", ") + ";\n" + sqlstr
+	}
+	return sqlstr + ";\nSELECT " + strings.Join(cols, ", "), ""
+}
+
+// commentOn returns the batch setting the comment of a table or column as its
+// MS_Description extended property, adding, updating, or dropping the property
+// as needed.
+func commentOn(typ, name, comment string) (string, error) {
+	// split the name with PARSENAME, with the schema defaulting to the
+	// current schema
+	n, level, minor := quoteLiteral(name), 1, "0"
+	decls := []string{}
+	args := ", @level0type = N'SCHEMA', @level0name = @schema, @level1type = N'TABLE', @level1name = @table"
+	if typ == "COLUMN" {
+		decls = append(decls, "@column sysname = PARSENAME("+n+", 1)")
+		level, minor = 2, "COLUMNPROPERTY(OBJECT_ID(QUOTENAME(@schema) + '.' + QUOTENAME(@table)), @column, 'ColumnId')"
+		args += ", @level2type = N'COLUMN', @level2name = @column"
+	}
+	decls = append([]string{
+		"@schema sysname = COALESCE(PARSENAME(" + n + ", " + strconv.Itoa(level+1) + "), SCHEMA_NAME())",
+		"@table sysname = PARSENAME(" + n + ", " + strconv.Itoa(level) + ")",
+	}, decls...)
+	exists := "IF EXISTS (SELECT 1 FROM sys.extended_properties WHERE class = 1 AND major_id = OBJECT_ID(QUOTENAME(@schema) + '.' + QUOTENAME(@table)) AND minor_id = " + minor + " AND name = N'MS_Description')"
+	sqlstr := "DECLARE " + strings.Join(decls, ", ") + ";\n" + exists + "\n"
+	if comment == "" {
+		return sqlstr + "  EXEC sp_dropextendedproperty @name = N'MS_Description'" + args + ";", nil
+	}
+	value := ", @value = " + quoteLiteral(comment)
+	return sqlstr +
+		"  EXEC sp_updateextendedproperty @name = N'MS_Description'" + value + args + ";\n" +
+		"ELSE\n" +
+		"  EXEC sp_addextendedproperty @name = N'MS_Description'" + value + args + ";", nil
+}
+
+// openAccessToken opens a database, authenticating with the Microsoft Entra ID
+// access token in the accesstoken query parameter of the DSN, when present.
+func openAccessToken(driver, dsn string) (*sql.DB, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || !u.Query().Has("accesstoken") {
+		return sql.Open(driver, dsn)
+	}
+	q := u.Query()
+	token := q.Get("accesstoken")
+	q.Del("accesstoken")
+	u.RawQuery = q.Encode()
+	conn, err := sqlserver.NewAccessTokenConnector(u.String(), func() (string, error) {
+		return token, nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(conn), nil
 }
 
 func placeholder(n int) string {
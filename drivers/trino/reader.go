@@ -13,11 +13,19 @@ type metaReader struct {
 }
 
 var _ metadata.CatalogReader = &metaReader{}
+var _ metadata.SchemaReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
 var _ metadata.ColumnStatReader = &metaReader{}
 
-func (r metaReader) Catalogs(metadata.Filter) (*metadata.CatalogSet, error) {
+func (r metaReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
 	qstr := `SHOW catalogs`
-	rows, closeRows, err := r.Query(qstr)
+	var vals []interface{}
+	if f.Name != "" {
+		qstr = `SELECT catalog_name FROM system.metadata.catalogs WHERE catalog_name LIKE ? ORDER BY catalog_name`
+		vals = append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr, vals...)
 	if err != nil {
 		return nil, err
 	}
@@ -102,3 +110,168 @@ func (r metaReader) ColumnStats(f metadata.Filter) (*metadata.ColumnStatSet, err
 
 	return metadata.NewColumnStatSet(results), nil
 }
+
+// Schemas lists the schemas of the catalogs matching the filter, or of the
+// current catalog.
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	conds, vals := conditions(f, "schema_name", "schema_name")
+	results := []metadata.Schema{}
+	err := r.eachCatalog(f.Catalog, func(catalog string) error {
+		rows, closeRows, err := r.Query(`SELECT schema_name, catalog_name FROM `+infoSchema(catalog, "schemata")+where(conds)+` ORDER BY schema_name`, vals...)
+		if err != nil {
+			return err
+		}
+		defer closeRows()
+		for rows.Next() {
+			rec := metadata.Schema{}
+			if err := rows.Scan(&rec.Schema, &rec.Catalog); err != nil {
+				return err
+			}
+			results = append(results, rec)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// Tables lists the tables of the catalogs matching the filter, or of the
+// current catalog.
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	conds, vals := conditions(f, "table_schema", "table_name")
+	if len(f.Types) != 0 {
+		conds = append(conds, "table_type IN ("+strings.Repeat("?, ", len(f.Types)-1)+"?)")
+		for _, typ := range f.Types {
+			vals = append(vals, typ)
+		}
+	}
+	results := []metadata.Table{}
+	err := r.eachCatalog(f.Catalog, func(catalog string) error {
+		rows, closeRows, err := r.Query(`SELECT table_catalog, table_schema, table_name, table_type FROM `+infoSchema(catalog, "tables")+where(conds)+` ORDER BY table_schema, table_name`, vals...)
+		if err != nil {
+			return err
+		}
+		defer closeRows()
+		for rows.Next() {
+			rec := metadata.Table{}
+			if err := rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type); err != nil {
+				return err
+			}
+			results = append(results, rec)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns lists the columns of the tables of the catalogs matching the
+// filter, or of the current catalog.
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	conds, vals := conditions(f, "table_schema", "column_name")
+	if f.Parent != "" {
+		conds = append(conds, "table_name LIKE ?")
+		vals = append(vals, f.Parent)
+	}
+	results := []metadata.Column{}
+	err := r.eachCatalog(f.Catalog, func(catalog string) error {
+		rows, closeRows, err := r.Query(`SELECT
+  table_catalog,
+  table_schema,
+  table_name,
+  column_name,
+  ordinal_position,
+  data_type,
+  COALESCE(column_default, ''),
+  COALESCE(is_nullable, '')
+FROM `+infoSchema(catalog, "columns")+where(conds)+`
+ORDER BY table_schema, table_name, ordinal_position`, vals...)
+		if err != nil {
+			return err
+		}
+		defer closeRows()
+		for rows.Next() {
+			rec := metadata.Column{}
+			err := rows.Scan(
+				&rec.Catalog,
+				&rec.Schema,
+				&rec.Table,
+				&rec.Name,
+				&rec.OrdinalPosition,
+				&rec.DataType,
+				&rec.Default,
+				&rec.IsNullable,
+			)
+			if err != nil {
+				return err
+			}
+			results = append(results, rec)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// eachCatalog calls f for each catalog matching the LIKE pattern, or for the
+// current catalog (an empty string) when the pattern is empty.
+func (r metaReader) eachCatalog(pattern string, f func(string) error) error {
+	catalogs := []string{""}
+	if pattern != "" {
+		res, err := r.Catalogs(metadata.Filter{Name: pattern})
+		if err != nil {
+			return err
+		}
+		defer res.Close()
+		for catalogs = nil; res.Next(); {
+			catalogs = append(catalogs, res.Get().Catalog)
+		}
+	}
+	for _, catalog := range catalogs {
+		if err := f(catalog); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	return nil
+}
+
+// infoSchema returns the information_schema view of the catalog, or of the
+// current catalog when empty.
+func infoSchema(catalog, view string) string {
+	if catalog == "" {
+		return "information_schema." + view
+	}
+	return `"` + strings.ReplaceAll(catalog, `"`, `""`) + `".information_schema.` + view
+}
+
+// conditions returns the conditions and values matching the schema and
+// name patterns of the filter, excluding information_schema unless requested.
+func conditions(f metadata.Filter, schemaCol, nameCol string) ([]string, []interface{}) {
+	var conds []string
+	var vals []interface{}
+	if f.Schema != "" {
+		conds = append(conds, schemaCol+" LIKE ?")
+		vals = append(vals, f.Schema)
+	} else if !f.WithSystem {
+		conds = append(conds, schemaCol+" <> 'information_schema'")
+	}
+	if f.Name != "" {
+		conds = append(conds, nameCol+" LIKE ?")
+		vals = append(vals, f.Name)
+	}
+	return conds, vals
+}
+
+// where returns the WHERE clause of conds.
+func where(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
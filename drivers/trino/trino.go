@@ -57,8 +57,15 @@ func init() {
 		},
 		NewMetadataReader: newReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
-			return metadata.NewDefaultWriter(newReader(db, opts...))(db, w)
+			r := newReader(db, opts...)
+			return metaWriter{
+				Writer: metadata.NewDefaultWriter(r)(db, w),
+				r:      r.(metadata.SchemaReader),
+				w:      w,
+			}
 		},
-		Copy: drivers.CopyWithInsert(func(int) string { return "?" }),
+		Copy:        drivers.CopyWithInsert(func(int) string { return "?" }),
+		CopyIn:      drivers.CopyInWithInsert(func(int) string { return "?" }),
+		Placeholder: func(int) string { return "?" },
 	})
 }
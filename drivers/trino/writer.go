@@ -0,0 +1,36 @@
+package trino
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/env"
+	"github.com/xo/tblfmt"
+)
+
+// metaWriter is a metadata writer aware of the catalog.schema.table hierarchy
+// of Trino. Catalogs are listed by \l, and table patterns can be qualified by
+// a catalog pattern (\dt catalog.schema.*).
+type metaWriter struct {
+	metadata.Writer
+	r metadata.SchemaReader
+	w io.Writer
+}
+
+// ListSchemas lists the schemas of the catalogs matching the catalog pattern,
+// as \dn catalog, or \dn catalog.schema, or the schemas of the current catalog
+// when the pattern is empty.
+func (w metaWriter) ListSchemas(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	catalog, schema, _ := strings.Cut(strings.ReplaceAll(pattern, "*", "%"), ".")
+	res, err := w.r.Schemas(metadata.Filter{Catalog: catalog, Name: schema, WithSystem: showSystem})
+	if err != nil {
+		return fmt.Errorf("failed to list schemas: %w", err)
+	}
+	defer res.Close()
+	params := env.Pall()
+	params["title"] = "List of schemas"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"github.com/xo/tblfmt"
+)
+
+// changedColor is the color of changed values.
+const changedColor = "\x1b[1;33m"
+
+// Changed is a value changed since the previous execution of a watched query
+// (\watch --diff), highlighted when displayed.
+type Changed struct {
+	V interface{}
+}
+
+// changedRows wraps a result set, unwrapping the changed values, and
+// recording the columns of the current row with changed values.
+type changedRows struct {
+	tblfmt.ResultSet
+	changed []bool
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *changedRows) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		return err
+	}
+	r.changed = r.changed[:0]
+	for _, z := range v {
+		var changed bool
+		if p, ok := z.(*interface{}); ok {
+			if c, ok := (*p).(Changed); ok {
+				*p, changed = c.V, true
+			}
+		}
+		r.changed = append(r.changed, changed)
+	}
+	return nil
+}
+
+// highlight highlights the formatted value of column i of the current row,
+// when changed. Values spanning multiple lines or containing tabs are not
+// highlighted, as the encoders split them using their byte offsets.
+func (r *changedRows) highlight(i int, v *tblfmt.Value) {
+	switch {
+	case v == nil, i >= len(r.changed), !r.changed[i], len(v.Newlines) != 0:
+		return
+	}
+	for _, tabs := range v.Tabs {
+		if len(tabs) != 0 {
+			return
+		}
+	}
+	buf := make([]byte, 0, len(changedColor)+len(v.Buf)+4)
+	buf = append(append(append(buf, changedColor...), v.Buf...), "\x1b[0m"...)
+	v.Buf = buf
+}
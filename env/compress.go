@@ -0,0 +1,80 @@
+package env
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildus/usql/text"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressBufferSize is the buffer size of compressed output files, sized
+// for large exports.
+const compressBufferSize = 1 << 20
+
+// Compression returns the compression method (gzip or zstd) for the output
+// file name, using the compress display setting, or when set to auto, the
+// file's extension (.gz, .zst). Returns an empty string when the output is
+// not compressed.
+func Compression(name string) string {
+	switch s := pvars["compress"]; s {
+	case "gzip", "zstd":
+		return s
+	case "none":
+		return ""
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".zst", ".zstd":
+		return "zstd"
+	}
+	return ""
+}
+
+// Compress wraps w, compressing writes using the compression method (gzip or
+// zstd) through a buffered writer. Closing the returned writer flushes the
+// compressed stream and closes w.
+func Compress(w io.WriteCloser, method string) (io.WriteCloser, error) {
+	buf := bufio.NewWriterSize(w, compressBufferSize)
+	var enc io.WriteCloser
+	switch method {
+	case "gzip":
+		enc = gzip.NewWriter(buf)
+	case "zstd":
+		var err error
+		if enc, err = zstd.NewWriter(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf(text.FormatFieldInvalidValue, method, "compress", "gzip or zstd")
+	}
+	return &compressWriter{
+		WriteCloser: enc,
+		buf:         buf,
+		w:           w,
+	}, nil
+}
+
+// compressWriter is a compressing writer.
+type compressWriter struct {
+	io.WriteCloser
+	buf *bufio.Writer
+	w   io.WriteCloser
+}
+
+// Close satisfies the io.Closer interface.
+func (w *compressWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if err == nil {
+		err = w.buf.Flush()
+	}
+	if cerr := w.w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
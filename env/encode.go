@@ -0,0 +1,269 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ildus/usql/text"
+	"github.com/mattn/go-runewidth"
+	"github.com/xo/tblfmt"
+)
+
+// nullSymbol is the null value displayed by the symbol null style.
+const nullSymbol = "∅"
+
+// EncodeAll encodes all result sets to w using the output parameters,
+// applying the display settings that are not handled by tblfmt: the numeric
+// settings (numericlocale, thousands_sep, and float_precision), maxcolwidth,
+// and nullstyle. Values are left raw for the csv and json formats, which are
+// preceded by the column metadata of each result set when schema_header is on.
+// The values of the columns matching mask_columns are masked in all formats
+// when mask is on. Expanded output is displayed with the field name column
+// width (expanded_fieldwidth) and record headers (expanded_header and
+// expanded_recordsep) settings. Changed values of watched queries are
+// highlighted when watch_diff is on.
+func EncodeAll(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	var changed *changedRows
+	if params["watch_diff"] == "on" {
+		changed = &changedRows{ResultSet: resultSet}
+		resultSet = changed
+	}
+	if masked := Masked(params); masked != nil {
+		resultSet = &maskRows{ResultSet: resultSet, masked: masked}
+	}
+	if loc := Location(params); loc != nil {
+		resultSet = &zoneRows{ResultSet: resultSet, loc: loc}
+	}
+	if expanded(params) {
+		if n := fieldWidth(params); n > 0 {
+			resultSet = &fieldRows{ResultSet: resultSet, width: n}
+		}
+		if params["expanded_header"] == "off" {
+			rw := &recordWriter{w: w, sep: params["expanded_recordsep"]}
+			if err := encodeAll(rw, resultSet, params, changed); err != nil {
+				return err
+			}
+			return rw.flush()
+		}
+	}
+	return encodeAll(w, resultSet, params, changed)
+}
+
+// encodeAll encodes all result sets to w using the output parameters,
+// highlighting the changed values recorded by changed, if any.
+func encodeAll(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string, changed *changedRows) error {
+	switch params["format"] {
+	case "csv", "json":
+		params["numericlocale"] = "off"
+		if params["schema_header"] == "on" {
+			return encodeSchema(w, resultSet, params)
+		}
+		return tblfmt.EncodeAll(w, resultSet, params)
+	}
+	f := newNumericFormat(params)
+	maxWidth, _ := strconv.Atoi(params["maxcolwidth"])
+	null, nullColor := params["null"], false
+	colors := false
+	switch params["format"] {
+	case "aligned", "wrapped", "vertical":
+		colors = true
+	}
+	switch params["nullstyle"] {
+	case "symbol":
+		null = nullSymbol
+		params["null"] = null
+	case "color":
+		if colors {
+			if null == "" {
+				null = "NULL"
+			}
+			nullColor = true
+		}
+	}
+	if !colors {
+		changed = nil
+	}
+	if f == nil && maxWidth <= 0 && !nullColor && changed == nil {
+		return tblfmt.EncodeAll(w, resultSet, params)
+	}
+	if f != nil {
+		resultSet = &numericRows{ResultSet: resultSet, f: f}
+	}
+	var trunc *truncRows
+	if maxWidth > 0 {
+		trunc = &truncRows{ResultSet: resultSet, max: maxWidth}
+		resultSet = trunc
+	}
+	// wrap the encoder's formatter to right align the formatted numbers, and
+	// color null and changed values
+	formatter := &displayFormatter{null: null, nullColor: nullColor, changed: changed}
+	builder, opts := tblfmt.FromMap(params)
+	opts = append(
+		opts,
+		tblfmt.WithFormatterOptions(func(escaper *tblfmt.EscapeFormatter) {
+			formatter.Formatter = escaper
+		}),
+		tblfmt.WithFormatter(formatter),
+	)
+	enc, err := builder(resultSet, opts...)
+	if err != nil {
+		return err
+	}
+	if err := enc.EncodeAll(w); err != nil {
+		return err
+	}
+	if trunc != nil && params["footer"] != "off" {
+		trunc.footnote(w)
+	}
+	return nil
+}
+
+// displayFormatter wraps a formatter, right aligning formatted numbers and
+// coloring null and changed values.
+type displayFormatter struct {
+	tblfmt.Formatter
+	null      string
+	nullColor bool
+	changed   *changedRows
+}
+
+// Format satisfies the tblfmt.Formatter interface.
+func (f *displayFormatter) Format(vals []interface{}) ([]*tblfmt.Value, error) {
+	res, err := f.Formatter.Format(vals)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		p, ok := v.(*interface{})
+		if !ok {
+			continue
+		}
+		switch (*p).(type) {
+		case Number:
+			if res[i] != nil {
+				res[i].Align = tblfmt.AlignRight
+			}
+		case nil:
+			if f.nullColor {
+				res[i] = &tblfmt.Value{
+					Buf:   []byte("\x1b[2m" + f.null + "\x1b[0m"),
+					Width: runewidth.StringWidth(f.null),
+					Tabs:  make([][][2]int, 1),
+				}
+			}
+		}
+		if f.changed != nil {
+			f.changed.highlight(i, res[i])
+		}
+	}
+	return res, nil
+}
+
+// truncRows wraps a result set, truncating text and binary values wider than
+// the maximum width with an ellipsis.
+type truncRows struct {
+	tblfmt.ResultSet
+	max int
+	// cols are the column names of the current result set.
+	cols []string
+	// truncated are the names of the truncated columns, in order.
+	truncated []string
+	// longest is the length in bytes of the longest truncated value of each
+	// truncated column.
+	longest map[string]int
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *truncRows) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err == nil {
+		r.cols = cols
+	}
+	return cols, err
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *truncRows) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		return err
+	}
+	for i, z := range v {
+		p, ok := z.(*interface{})
+		if !ok {
+			continue
+		}
+		var n int
+		switch x := (*p).(type) {
+		case string:
+			if s, ok := truncate(x, r.max); ok {
+				*p, n = s, len(x)
+			}
+		case []byte:
+			if s, ok := truncate(string(x), r.max); ok {
+				*p, n = s, len(x)
+			}
+		}
+		if n != 0 {
+			r.record(i, n)
+		}
+	}
+	return nil
+}
+
+// record records the truncation of a value of column i of length n.
+func (r *truncRows) record(i, n int) {
+	name := strconv.Itoa(i + 1)
+	if i < len(r.cols) {
+		name = r.cols[i]
+	}
+	if r.longest == nil {
+		r.longest = make(map[string]int)
+	}
+	prev, ok := r.longest[name]
+	if !ok {
+		r.truncated = append(r.truncated, name)
+	}
+	if n > prev {
+		r.longest[name] = n
+	}
+}
+
+// footnote writes the full length of the truncated columns.
+func (r *truncRows) footnote(w io.Writer) {
+	if len(r.truncated) == 0 {
+		return
+	}
+	cols := make([]string, len(r.truncated))
+	for i, name := range r.truncated {
+		cols[i] = fmt.Sprintf(text.TruncatedColumn, name, r.longest[name])
+	}
+	fmt.Fprintf(w, text.TruncatedDesc, r.max, strings.Join(cols, ", "))
+	fmt.Fprintln(w)
+}
+
+// truncate truncates s to max characters, replacing the last character with
+// an ellipsis, returning false when s is not wider than max.
+func truncate(s string, max int) (string, bool) {
+	if !utf8.ValidString(s) {
+		// binary data, where non printable bytes are displayed escaped as \xNN
+		var width int
+		for i := 0; i < len(s); i++ {
+			w := 4
+			if 0x20 <= s[i] && s[i] < 0x7f {
+				w = 1
+			}
+			if width+w > max-1 && (i < len(s)-1 || width+w > max) {
+				return s[:i] + "…", true
+			}
+			width += w
+		}
+		return s, false
+	}
+	if runewidth.StringWidth(s) <= max {
+		return s, false
+	}
+	return runewidth.Truncate(s, max, "…"), true
+}
@@ -151,6 +151,19 @@ func RCFile(u *user.User) string {
 	return passfile.Expand(u.HomeDir, path)
 }
 
+// PsetFile returns the path to the display settings defaults file.
+//
+// Defaults to ~/.<command name>pset, overridden by environment variable
+// <COMMAND NAME>PSET (ie, ~/.usqlpset and USQLPSET).
+func PsetFile(u *user.User) string {
+	n := text.CommandUpper() + "PSET"
+	path := "~/." + strings.ToLower(n)
+	if s, ok := Getenv(n); ok {
+		path = s
+	}
+	return passfile.Expand(u.HomeDir, path)
+}
+
 // Getshell returns the user's defined SHELL, or system default (if found on
 // path) and the appropriate command-line argument for the returned shell.
 //
@@ -272,7 +285,9 @@ func Dequote(s string, quote byte) (string, error) {
 	return string(buf), nil
 }
 
-// Getvar retrieves an environment variable.
+// Getvar retrieves an environment variable. When s is quoted (ie, 'NAME' or
+// "NAME"), the value is quoted with the same quote, doubling any embedded
+// quotes.
 func Getvar(s string, v Vars) (bool, string, error) {
 	q, n := "", s
 	if c := s[0]; c == '\'' || c == '"' {
@@ -283,6 +298,9 @@ func Getvar(s string, v Vars) (bool, string, error) {
 		q = string(c)
 	}
 	if val, ok := v[n]; ok {
+		if q != "" {
+			val = strings.ReplaceAll(val, q, q+q)
+		}
 		return true, q + val + q, nil
 	}
 	return false, s, nil
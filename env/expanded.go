@@ -0,0 +1,102 @@
+package env
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xo/tblfmt"
+)
+
+// expanded returns true when results are displayed in expanded output, either
+// always (on), or when wider than the terminal (auto).
+func expanded(params map[string]string) bool {
+	return params["format"] == "aligned" && (params["expanded"] == "on" || params["expanded"] == "auto")
+}
+
+// fieldRows wraps a result set, truncating the column names wider than the
+// maximum width of the field name column of expanded output.
+type fieldRows struct {
+	tblfmt.ResultSet
+	width int
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *fieldRows) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col
+		if s, ok := truncate(col, r.width); ok {
+			names[i] = s
+		}
+	}
+	return names, nil
+}
+
+// recordHeaderRE matches the record header lines of expanded output, for all
+// border and line styles, capturing the record number, and the border
+// preceding and following the record label.
+var recordHeaderRE = regexp.MustCompile(`^(?:\* Record (\d+)|([^\s\[]{0,2})(\[ RECORD (\d+) \])(.*))$`)
+
+// recordWriter wraps a writer, replacing the record header lines of expanded
+// output with the record separator. The header of the first record of each
+// result set is removed. With border 2, the record headers are also the
+// borders of the table, and are replaced with plain borders instead.
+type recordWriter struct {
+	w   io.Writer
+	sep string
+	buf []byte
+}
+
+// Write satisfies the io.Writer interface.
+func (rw *recordWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	for {
+		i := bytes.IndexByte(rw.buf, '\n')
+		if i == -1 {
+			return len(p), nil
+		}
+		line := rw.buf[:i+1]
+		if m := recordHeaderRE.FindSubmatch(bytes.TrimRight(line, " \r\n")); m != nil {
+			switch prefix := []rune(string(m[2])); {
+			case len(prefix) == 2:
+				fill := strings.Repeat(string(prefix[1]), len(m[3]))
+				line = []byte(string(m[2]) + fill + string(m[5]) + "\n")
+			case string(m[1])+string(m[4]) == "1":
+				line = nil
+			default:
+				line = []byte(strings.TrimRight(rw.sep, "\n") + "\n")
+			}
+		}
+		if _, err := rw.w.Write(line); err != nil {
+			return 0, err
+		}
+		rw.buf = rw.buf[i+1:]
+	}
+}
+
+// flush writes the remaining, incomplete, line.
+func (rw *recordWriter) flush() error {
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	_, err := rw.w.Write(rw.buf)
+	rw.buf = nil
+	return err
+}
+
+// fieldWidth returns the width of the field name column of expanded output,
+// or 0 when automatic.
+func fieldWidth(params map[string]string) int {
+	if params["expanded"] != "on" {
+		return 0
+	}
+	n, _ := strconv.Atoi(params["expanded_fieldwidth"])
+	return max(n, 0)
+}
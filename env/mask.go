@@ -0,0 +1,92 @@
+package env
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/xo/tblfmt"
+)
+
+// MaskValue is the value displayed in place of the non-null values of masked
+// columns.
+const MaskValue = "********"
+
+// defaultMaskColumns is the default mask_columns setting, matching the names
+// of columns commonly containing sensitive data.
+const defaultMaskColumns = `ssn,social_security_number,.*e_?mail.*,card_number,credit_card(_number)?,cc_number,cvv,iban,.*password.*,.*passwd.*,.*secret.*`
+
+// maskColumnsRE returns the regexp matching the names of the masked columns
+// of a mask_columns setting, a comma separated list of column names or
+// regular expressions matching the whole column name, case insensitively.
+func maskColumnsRE(s string) (*regexp.Regexp, error) {
+	var v []string
+	for _, z := range strings.Split(s, ",") {
+		if z = strings.TrimSpace(z); z != "" {
+			v = append(v, "(?:"+z+")")
+		}
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(`(?i)^(?:` + strings.Join(v, "|") + `)$`)
+}
+
+// Masked returns a func determining if a column is masked by the mask and
+// mask_columns settings of the output parameters, or nil when no columns are
+// masked.
+func Masked(params map[string]string) func(string) bool {
+	if params["mask"] != "on" {
+		return nil
+	}
+	re, err := maskColumnsRE(params["mask_columns"])
+	if err != nil || re == nil {
+		return nil
+	}
+	return re.MatchString
+}
+
+// maskRows wraps a result set, replacing the non-null values of masked
+// columns.
+type maskRows struct {
+	tblfmt.ResultSet
+	masked func(string) bool
+	// mask are the masked columns of the current result set.
+	mask []bool
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *maskRows) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	r.mask = make([]bool, len(cols))
+	for i, c := range cols {
+		r.mask[i] = r.masked(c)
+	}
+	return cols, nil
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *maskRows) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		return err
+	}
+	for i, z := range v {
+		if p, ok := z.(*interface{}); ok && i < len(r.mask) && r.mask[i] && *p != nil {
+			*p = MaskValue
+		}
+	}
+	return nil
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *maskRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
@@ -0,0 +1,185 @@
+package env
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xo/tblfmt"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Number is a numeric value formatted for display.
+type Number string
+
+// String satisfies the fmt.Stringer interface.
+func (n Number) String() string {
+	return string(n)
+}
+
+// numericFormat formats numeric values according to the numeric display
+// settings.
+type numericFormat struct {
+	// sep is the digit group separator, empty when digits are not grouped.
+	sep string
+	// point is the decimal point.
+	point string
+	// prec is the number of decimal digits of floating point values, or -1
+	// for the shortest representation.
+	prec int
+}
+
+// newNumericFormat creates the numeric format for the output parameters,
+// returning nil when numeric values are displayed as is.
+func newNumericFormat(params map[string]string) *numericFormat {
+	f := &numericFormat{point: ".", prec: -1}
+	if params["numericlocale"] == "on" || params["numericlocale"] == "true" {
+		f.sep, f.point = localeSeparators(params["locale"])
+	}
+	if s := params["thousands_sep"]; s != "" {
+		f.sep = s
+	}
+	if i, err := strconv.Atoi(params["float_precision"]); err == nil && i >= 0 {
+		f.prec = i
+	}
+	if f.sep == "" && f.point == "." && f.prec == -1 {
+		return nil
+	}
+	return f
+}
+
+// localeSeparators returns the digit group separator and decimal point for
+// the locale.
+func localeSeparators(locale string) (string, string) {
+	tag := language.English
+	if t, err := language.Parse(locale); err == nil {
+		tag = t
+	}
+	// extract the separators from a formatted 1<sep>234<point>5
+	s := message.NewPrinter(tag).Sprint(number.Decimal(1234.5, number.MinFractionDigits(1)))
+	i, j := strings.IndexByte(s, '2'), strings.IndexByte(s, '4')
+	if !strings.HasPrefix(s, "1") || !strings.HasSuffix(s, "5") || i == -1 || j < i {
+		return ",", "."
+	}
+	return s[1:i], s[j+1 : len(s)-1]
+}
+
+// decimalRE matches a decimal value.
+var decimalRE = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+
+// format formats v when it is a numeric value. String values are only
+// formatted for decimal columns.
+func (f *numericFormat) format(v interface{}, decimal bool) (Number, bool) {
+	var s string
+	switch x := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s = fmt.Sprintf("%d", x)
+	case float32:
+		s = strconv.FormatFloat(float64(x), 'f', f.prec, 32)
+	case float64:
+		s = strconv.FormatFloat(x, 'f', f.prec, 64)
+	case []byte:
+		if !decimal || !decimalRE.Match(x) {
+			return "", false
+		}
+		s = string(x)
+	case string:
+		if !decimal || !decimalRE.MatchString(x) {
+			return "", false
+		}
+		s = x
+	default:
+		return "", false
+	}
+	return Number(f.group(s)), true
+}
+
+// group groups the digits of the integer part of s, and replaces the decimal
+// point.
+func (f *numericFormat) group(s string) string {
+	var sign string
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	i, frac, ok := strings.Cut(s, ".")
+	if strings.TrimLeft(i, "0123456789") != "" {
+		// NaN, Inf
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for j := 0; j < len(i); j++ {
+		if f.sep != "" && j != 0 && (len(i)-j)%3 == 0 {
+			b.WriteString(f.sep)
+		}
+		b.WriteByte(i[j])
+	}
+	if ok {
+		b.WriteString(f.point)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// numericRows wraps a result set, replacing scanned numeric values with their
+// formatted Number.
+type numericRows struct {
+	tblfmt.ResultSet
+	f *numericFormat
+	// decimal indicates the decimal columns of the current result set.
+	decimal []bool
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *numericRows) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		return err
+	}
+	if r.decimal == nil {
+		r.decimal = r.decimals(len(v))
+	}
+	for i, z := range v {
+		if p, ok := z.(*interface{}); ok {
+			if n, ok := r.f.format(*p, r.decimal[i]); ok {
+				*p = n
+			}
+		}
+	}
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *numericRows) NextResultSet() bool {
+	r.decimal = nil
+	return r.ResultSet.NextResultSet()
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *numericRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// decimals determines the decimal columns of the current result set.
+func (r *numericRows) decimals(n int) []bool {
+	decimal := make([]bool, n)
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return decimal
+	}
+	for i := 0; i < n && i < len(types); i++ {
+		switch strings.ToUpper(types[i].DatabaseTypeName()) {
+		case "DEC", "DECIMAL", "NUMBER", "NUMERIC":
+			decimal[i] = true
+		}
+	}
+	return decimal
+}
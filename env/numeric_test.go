@@ -0,0 +1,117 @@
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNumericFormat(t *testing.T) {
+	tests := []struct {
+		params  map[string]string
+		v       interface{}
+		decimal bool
+		exp     string
+		ok      bool
+	}{
+		{map[string]string{"thousands_sep": ","}, int64(1234567), false, "1,234,567", true},
+		{map[string]string{"thousands_sep": ","}, int64(-1234), false, "-1,234", true},
+		{map[string]string{"thousands_sep": ","}, int64(123), false, "123", true},
+		{map[string]string{"thousands_sep": " "}, 1234567.125, false, "1 234 567.125", true},
+		{map[string]string{"thousands_sep": "'"}, uint32(1000), false, "1'000", true},
+		{map[string]string{"float_precision": "2"}, 1234.5678, false, "1234.57", true},
+		{map[string]string{"float_precision": "0"}, 2.5, false, "2", true},
+		{map[string]string{"float_precision": "2"}, float32(0.5), false, "0.50", true},
+		{map[string]string{"float_precision": "2"}, int64(12), false, "12", true},
+		{map[string]string{"float_precision": "1", "thousands_sep": ","}, -9876543.21, false, "-9,876,543.2", true},
+		// decimal values are only formatted for decimal columns
+		{map[string]string{"thousands_sep": ","}, "1234567.891", true, "1,234,567.891", true},
+		{map[string]string{"thousands_sep": ","}, []byte("-1234"), true, "-1,234", true},
+		{map[string]string{"thousands_sep": ","}, "1234567", false, "", false},
+		{map[string]string{"thousands_sep": ","}, "12a4", true, "", false},
+		{map[string]string{"thousands_sep": ","}, true, false, "", false},
+		{map[string]string{"thousands_sep": ","}, nil, false, "", false},
+		// locale separators
+		{map[string]string{"numericlocale": "on", "locale": "en-US"}, 1234.5, false, "1,234.5", true},
+		{map[string]string{"numericlocale": "on", "locale": "de-DE"}, 1234.5, false, "1.234,5", true},
+		{map[string]string{"numericlocale": "on", "locale": "de-DE", "thousands_sep": " "}, int64(1234), false, "1 234", true},
+	}
+	for i, test := range tests {
+		f := newNumericFormat(test.params)
+		if f == nil {
+			t.Fatalf("test %d expected numeric format", i)
+		}
+		n, ok := f.format(test.v, test.decimal)
+		if ok != test.ok {
+			t.Errorf("test %d expected ok %t, got: %t", i, test.ok, ok)
+		}
+		if string(n) != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, n)
+		}
+	}
+	// values are displayed as is without numeric settings
+	for _, params := range []map[string]string{{}, {"float_precision": "-1"}, {"float_precision": "x"}, {"numericlocale": "off", "locale": "de-DE"}} {
+		if f := newNumericFormat(params); f != nil {
+			t.Errorf("expected no numeric format for %v, got: %+v", params, f)
+		}
+	}
+}
+
+// numericTestRows is a result set of a single row of values.
+type numericTestRows struct {
+	cols []string
+	row  []interface{}
+	done bool
+}
+
+func (r *numericTestRows) Next() bool {
+	if r.done {
+		return false
+	}
+	r.done = true
+	return true
+}
+
+func (r *numericTestRows) Scan(v ...interface{}) error {
+	for i := range v {
+		*v[i].(*interface{}) = r.row[i]
+	}
+	return nil
+}
+
+func (r *numericTestRows) Columns() ([]string, error) { return r.cols, nil }
+func (r *numericTestRows) Close() error               { return nil }
+func (r *numericTestRows) Err() error                 { return nil }
+func (r *numericTestRows) NextResultSet() bool        { return false }
+
+func TestEncodeAllNumeric(t *testing.T) {
+	tests := []struct {
+		format string
+		exp    string
+	}{
+		{"aligned", "1 234 567 | 1 234,57"},
+		{"unaligned", "1 234 567|1 234,57"},
+		// csv and json values are left raw
+		{"csv", "1234567,1234.5678"},
+		{"json", `[{"a":1234567,"b":1234.5678}]`},
+	}
+	for i, test := range tests {
+		params := map[string]string{
+			"format":          test.format,
+			"thousands_sep":   " ",
+			"float_precision": "2",
+			"numericlocale":   "on",
+			"locale":          "de-DE",
+			"tuples_only":     "on",
+			"footer":          "off",
+		}
+		var buf bytes.Buffer
+		rows := &numericTestRows{cols: []string{"a", "b"}, row: []interface{}{int64(1234567), 1234.5678}}
+		if err := EncodeAll(&buf, rows, params); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := strings.TrimSpace(buf.String()); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected output to contain %q, got: %q", i, test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/text"
+)
+
+// PsetEntry is a display settings defaults file entry.
+//
+// Corresponds to a non-empty line in the file, consisting of a
+// protocol:host:port:dbname connection pattern, followed by the name and value
+// of a display setting. Trailing fields of the pattern may be omitted, and any
+// field may be a * wildcard, so that an entry can apply to all connections of
+// a driver (clickhouse), or only to specific connections
+// (postgres:db.example.com:*:reports).
+type PsetEntry struct {
+	Protocol, Host, Port, DBName string
+	Name, Value                  string
+}
+
+// ParsePset parses display settings defaults entries from the reader.
+func ParsePset(r io.Reader) ([]PsetEntry, error) {
+	var entries []PsetEntry
+	i, s := 0, bufio.NewScanner(r)
+	for s.Scan() {
+		i++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v := strings.Fields(line)
+		p := strings.Split(v[0], ":")
+		if len(v) < 2 || len(p) > 4 {
+			return nil, fmt.Errorf(text.PsetFileInvalidEntry, i)
+		}
+		p = append(p, "*", "*", "*")
+		entries = append(entries, PsetEntry{
+			Protocol: p[0],
+			Host:     p[1],
+			Port:     p[2],
+			DBName:   p[3],
+			Name:     v[1],
+			Value:    strings.Join(v[2:], " "),
+		})
+	}
+	return entries, s.Err()
+}
+
+// ParsePsetFile parses the display settings defaults entries contained in
+// file. Returns no entries when the file does not exist.
+func ParsePsetFile(file string) ([]PsetEntry, error) {
+	f, err := os.Open(file)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := ParsePset(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return entries, nil
+}
+
+// Match returns true when the entry applies to the database URL.
+func (entry PsetEntry) Match(u *dburl.URL) bool {
+	n := strings.Split(u.Normalize(":", "", 0), ":")
+	if len(n) < 4 {
+		return false
+	}
+	protocols := append([]string{u.Driver}, dburl.Protocols(u.Unaliased)...)
+	return (entry.Protocol == "*" || contains(protocols, entry.Protocol)) &&
+		(entry.Host == "*" || entry.Host == n[1]) &&
+		(entry.Port == "*" || entry.Port == n[2]) &&
+		(entry.DBName == "*" || entry.DBName == n[3])
+}
+
+// contains determines if v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package env
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/xo/tblfmt"
+)
+
+// Column is the metadata of a result column.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Nullable *bool  `json:"nullable,omitempty"`
+}
+
+// Columns returns the metadata of the result columns from the column names
+// and the column types reported by the driver, if any.
+func Columns(names []string, types []*sql.ColumnType) []Column {
+	cols := make([]Column, len(names))
+	for i, name := range names {
+		cols[i].Name = name
+		if i < len(types) && types[i] != nil {
+			cols[i].Type = types[i].DatabaseTypeName()
+			if nullable, ok := types[i].Nullable(); ok {
+				cols[i].Nullable = &nullable
+			}
+		}
+	}
+	return cols
+}
+
+// encodeSchema encodes all result sets to w, preceding each result set with
+// the metadata of its columns (schema_header). For the csv format, the
+// metadata is written as a JSON array on a comment line (prefixed with "#")
+// before the header, and for the json format, each result set is written as
+// an object containing the metadata (schema) and the rows.
+func encodeSchema(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	f, opts := tblfmt.FromMap(params)
+	enc, err := f(resultSet, opts...)
+	if err != nil {
+		return err
+	}
+	isJSON := params["format"] == "json"
+	for i := 0; ; i++ {
+		names, err := resultSet.Columns()
+		if err != nil {
+			return err
+		}
+		if params["lower_column_names"] == "true" {
+			for j := range names {
+				names[j] = strings.ToLower(names[j])
+			}
+		}
+		var types []*sql.ColumnType
+		if z, ok := resultSet.(interface {
+			ColumnTypes() ([]*sql.ColumnType, error)
+		}); ok {
+			types, _ = z.ColumnTypes()
+		}
+		buf, err := json.Marshal(Columns(names, types))
+		if err != nil {
+			return err
+		}
+		var preamble string
+		switch {
+		case isJSON:
+			preamble = `{"schema":` + string(buf) + `,"rows":`
+		case i != 0:
+			// separate result sets as tblfmt does
+			preamble = "\n# " + string(buf) + "\n"
+		default:
+			preamble = "# " + string(buf) + "\n"
+		}
+		if _, err := io.WriteString(w, preamble); err != nil {
+			return err
+		}
+		if err := enc.Encode(w); err != nil {
+			return err
+		}
+		if isJSON {
+			if _, err := io.WriteString(w, "}\n"); err != nil {
+				return err
+			}
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
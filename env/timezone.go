@@ -0,0 +1,114 @@
+package env
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/xo/tblfmt"
+)
+
+// Location returns the location of the timezone setting of the output
+// parameters, or nil when time/date values are displayed as returned by the
+// database.
+func Location(params map[string]string) *time.Location {
+	if params["timezone"] == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(params["timezone"])
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// localTypes are the database type names of time/date columns without a time
+// zone, whose values are not converted to the display time zone.
+var localTypes = map[string]bool{
+	"DATE":                        true,
+	"DATETIME":                    true,
+	"DATETIME2":                   true,
+	"SMALLDATETIME":               true,
+	"TIME":                        true,
+	"TIME WITHOUT TIME ZONE":      true,
+	"TIMESTAMP":                   true,
+	"TIMESTAMP WITHOUT TIME ZONE": true,
+}
+
+// ZonedColumns returns which of the columns have values that are converted to
+// the display time zone, which are all columns except the time/date columns
+// without a time zone (determined by the database type names of the column
+// types, when available).
+func ZonedColumns(types []*sql.ColumnType, n int) []bool {
+	zoned := make([]bool, n)
+	for i := range zoned {
+		zoned[i] = i >= len(types) || !localTypes[strings.ToUpper(types[i].DatabaseTypeName())]
+	}
+	return zoned
+}
+
+// InZone returns the time value converted to the location, or the value
+// unchanged when it is not a time value.
+func InZone(v interface{}, loc *time.Location) interface{} {
+	switch x := v.(type) {
+	case time.Time:
+		return x.In(loc)
+	case *time.Time:
+		if x != nil {
+			*x = x.In(loc)
+		}
+	case *sql.NullTime:
+		if x != nil && x.Valid {
+			x.Time = x.Time.In(loc)
+		}
+	}
+	return v
+}
+
+// zoneRows wraps a result set, converting time values to the display time
+// zone.
+type zoneRows struct {
+	tblfmt.ResultSet
+	loc *time.Location
+	// zoned are the converted columns of the current result set.
+	zoned []bool
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *zoneRows) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, _ := r.ColumnTypes()
+	r.zoned = ZonedColumns(types, len(cols))
+	return cols, nil
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *zoneRows) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		return err
+	}
+	for i, z := range v {
+		if i >= len(r.zoned) || !r.zoned[i] {
+			continue
+		}
+		if p, ok := z.(*interface{}); ok {
+			*p = InZone(*p, r.loc)
+		} else {
+			InZone(z, r.loc)
+		}
+	}
+	return nil
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *zoneRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
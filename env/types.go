@@ -11,9 +11,9 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/ildus/usql/text"
 	syslocale "github.com/jeandeaual/go-locale"
 	"github.com/xo/terminfo"
-	"github.com/ildus/usql/text"
 )
 
 type varName struct {
@@ -26,26 +26,82 @@ func (v varName) String() string {
 }
 
 var varNames = []varName{
+	{
+		"AUDIT_LOG",
+		"if set, record executed statements to the file as JSON lines, or to the system log when set to \"syslog\"",
+	},
+	{
+		"COMP_KEYWORD_CASE",
+		"case of completed keywords [upper, lower, preserve-upper, preserve-lower]",
+	},
+	{
+		"CONFIRM_DESTRUCTIVE",
+		"if set to \"on\", show the objects affected by DROP, TRUNCATE, and DELETE or UPDATE without WHERE, and require typed confirmation",
+	},
 	{
 		"ECHO_HIDDEN",
 		"if set, display internal queries executed by backslash commands; if set to \"noexec\", just show them without execution",
 	},
+	{
+		"FORMAT_INDENT",
+		"number of spaces (or tab) to indent with when formatting statements with \\format",
+	},
+	{
+		"FORMAT_KEYWORD_CASE",
+		"case of reserved words when formatting statements [upper, lower, preserve]",
+	},
+	{
+		"FORMAT_ON_PRINT",
+		"if set to \"on\", format the query buffer shown by \\p",
+	},
+	{
+		"IMPLICIT_LIMIT",
+		"if set, limit the rows of interactive SELECT statements without a limit to the number",
+	},
+	{
+		"KEYCHAIN",
+		"if set to \"on\", use the matching OS keychain entry when no passfile entry matches the connection",
+	},
 	{
 		"ON_ERROR_STOP",
 		"stop batch execution after error",
 	},
+	{
+		"PROGRESS",
+		"if set, display a progress line on standard error when query results are sent to a file or pipe, or when copying data",
+	},
 	{
 		"PROMPT1",
 		"specifies the standard " + text.CommandName + " prompt",
 	},
+	{
+		"QUERY_TAG",
+		"if set, tag the executed statements with the value, using the driver's native query tag or a comment",
+	},
 	{
 		"QUIET",
 		"run quietly (same as -q option)",
 	},
+	{
+		"RECONNECT",
+		"if set to \"on\", reconnect when the connection to the server is lost, restoring the session settings",
+	},
+	{
+		"RECONNECT_ATTEMPTS",
+		"number of attempts to reconnect, when RECONNECT is set",
+	},
+	{
+		"RECONNECT_BACKOFF",
+		"delay before the first reconnect attempt, doubled after every failed attempt",
+	},
 	{
 		"ROW_COUNT",
 		"number of rows returned or affected by last query, or 0",
 	},
+	{
+		"STATEMENT_TIMEOUT",
+		"if set, cancel statements running longer than the duration (such as 30s), also setting the server's statement timeout where supported",
+	},
 }
 
 var pvarNames = []varName{
@@ -53,10 +109,18 @@ var pvarNames = []varName{
 		"border",
 		"border style (number)",
 	},
+	{
+		"chart_type",
+		"set the chart type for chart format [bar, line, sparkline]",
+	},
 	{
 		"columns",
 		"target width for the wrapped format",
 	},
+	{
+		"compress",
+		"compression of output files [auto, none, gzip, zstd]",
+	},
 	{
 		"csv_fieldsep",
 		`field separator for CSV output (default ",")`,
@@ -65,6 +129,18 @@ var pvarNames = []varName{
 		"expanded",
 		"expanded output [on, off, auto]",
 	},
+	{
+		"expanded_fieldwidth",
+		"width of the field name column of expanded output, longer names are truncated (0 for automatic)",
+	},
+	{
+		"expanded_header",
+		"display the record headers of expanded output [on, off]",
+	},
+	{
+		"expanded_recordsep",
+		"record separator of expanded output, displayed in place of the record headers when expanded_header is off",
+	},
 	{
 		"fieldsep",
 		`field separator for unaligned output (default "|")`,
@@ -73,22 +149,42 @@ var pvarNames = []varName{
 		"fieldsep_zero",
 		"set field separator for unaligned output to a zero byte",
 	},
+	{
+		"float_precision",
+		"number of decimal digits to display for floating point values (unset for shortest)",
+	},
 	{
 		"footer",
 		"enable or disable display of the table footer [on, off]",
 	},
 	{
 		"format",
-		"set output format [unaligned, aligned, wrapped, vertical, html, asciidoc, csv, json, ...]",
+		"set output format [unaligned, aligned, wrapped, vertical, html, asciidoc, csv, json, chart, interactive, count, ...]",
 	},
 	{
 		"linestyle",
 		"set the border line drawing style [ascii, old-ascii, unicode]",
 	},
+	{
+		"mask",
+		"mask the values of columns matching mask_columns [on, off]",
+	},
+	{
+		"mask_columns",
+		"comma separated column names or regular expressions of columns to mask",
+	},
+	{
+		"maxcolwidth",
+		"maximum width of text and binary values, longer values are truncated (0 to disable)",
+	},
 	{
 		"null",
 		"set the string to be printed in place of a null value",
 	},
+	{
+		"nullstyle",
+		"set how null values are displayed [text, color, symbol]",
+	},
 	{
 		"numericlocale",
 		"enable display of a locale-specific character to separate groups of digits",
@@ -101,6 +197,10 @@ var pvarNames = []varName{
 		"pager",
 		"control when an external pager is used [on, off, always]",
 	},
+	{
+		"pager_format",
+		"set the format results are sent to the pager in, along with column metadata [text, csv, tsv, json]",
+	},
 	{
 		"recordsep",
 		"record (line) separator for unaligned output",
@@ -109,13 +209,29 @@ var pvarNames = []varName{
 		"recordsep_zero",
 		"set record separator for unaligned output to a zero byte",
 	},
+	{
+		"rowcount_estimate",
+		"display the planner's estimated row count of queries in the table footer",
+	},
+	{
+		"schema_header",
+		"precede csv and json output with the column names, types, and nullability",
+	},
 	{
 		"tableattr",
 		"specify attributes for table tag in html format, or proportional column widths for left-aligned data types in latex-longtable format",
 	},
+	{
+		"thousands_sep",
+		"override the locale-specific character used to separate groups of digits",
+	},
 	{
 		"time",
-		`format used to display time/date column values (default "RFC3339Nano")`,
+		`format used to display time/date column values, also set by timefmt (default "RFC3339Nano")`,
+	},
+	{
+		"timezone",
+		`time zone to convert time/date column values with a time zone to, such as "UTC", "Local", or "Europe/Berlin" (default unset)`,
 	},
 	{
 		"title",
@@ -140,6 +256,10 @@ var pvarNames = []varName{
 }
 
 var envVarNames = []varName{
+	{
+		text.CommandUpper() + "_AUDIT_LOG",
+		"initial value of the AUDIT_LOG variable",
+	},
 	{
 		text.CommandUpper() + "_EDITOR, EDITOR, VISUAL",
 		"editor used by the \\e, \\ef, and \\ev commands",
@@ -160,6 +280,10 @@ var envVarNames = []varName{
 		text.CommandUpper() + "_SHOW_HOST_INFORMATION",
 		"display host information when connecting to a database",
 	},
+	{
+		text.CommandUpper() + "PSET",
+		"alternative location for the user's .usqlpset display settings defaults file",
+	},
 	{
 		text.CommandUpper() + "RC",
 		"alternative location for the user's .usqlrc file",
@@ -244,6 +368,15 @@ func init() {
 	}
 	// editor
 	editorCmd, _ := Getenv(cmdNameUpper+"_EDITOR", "EDITOR", "VISUAL")
+	// audit log
+	auditLog, _ := Getenv(cmdNameUpper + "_AUDIT_LOG")
+	// keychain
+	keychain := "off"
+	if s, ok := Getenv(cmdNameUpper + "_KEYCHAIN"); ok {
+		if v, err := ParseBool(s, cmdNameUpper+"_KEYCHAIN"); err == nil {
+			keychain = v
+		}
+	}
 	// sslmode
 	sslmode, ok := Getenv(cmdNameUpper+"_SSLMODE", "SSLMODE")
 	if !ok {
@@ -254,7 +387,18 @@ func init() {
 		"SHOW_HOST_INFORMATION": enableHostInformation,
 		"PAGER":                 pagerCmd,
 		"EDITOR":                editorCmd,
+		"COMP_KEYWORD_CASE":     "preserve-upper",
+		"CONFIRM_DESTRUCTIVE":   "off",
 		"ON_ERROR_STOP":         "off",
+		"PROGRESS":              "off",
+		"RECONNECT":             "off",
+		"RECONNECT_ATTEMPTS":    "3",
+		"RECONNECT_BACKOFF":     "1s",
+		"FORMAT_INDENT":         "2",
+		"FORMAT_KEYWORD_CASE":   "preserve",
+		"FORMAT_ON_PRINT":       "off",
+		"AUDIT_LOG":             auditLog,
+		"KEYCHAIN":              keychain,
 		// prompts
 		"PROMPT1": "%S%N%m%/%R%# ",
 		// syntax highlighting variables
@@ -271,23 +415,38 @@ func init() {
 	}
 	pvars = Vars{
 		"border":                   "1",
+		"chart_type":               "bar",
 		"columns":                  "0",
+		"compress":                 "auto",
 		"csv_fieldsep":             ",",
 		"expanded":                 "off",
+		"expanded_fieldwidth":      "0",
+		"expanded_header":          "on",
+		"expanded_recordsep":       "",
 		"fieldsep":                 "|",
 		"fieldsep_zero":            "off",
+		"float_precision":          "",
 		"footer":                   "on",
 		"format":                   "aligned",
 		"linestyle":                "ascii",
 		"locale":                   locale,
+		"mask":                     "on",
+		"mask_columns":             defaultMaskColumns,
+		"maxcolwidth":              "0",
 		"null":                     "",
+		"nullstyle":                "text",
 		"numericlocale":            "off",
 		"pager_min_lines":          "0",
 		"pager":                    pager,
+		"pager_format":             "text",
 		"recordsep":                "\n",
 		"recordsep_zero":           "off",
+		"rowcount_estimate":        "off",
+		"schema_header":            "off",
 		"tableattr":                "",
+		"thousands_sep":            "",
 		"time":                     "RFC3339Nano",
+		"timezone":                 "",
 		"title":                    "",
 		"tuples_only":              "off",
 		"unicode_border_linestyle": "single",
@@ -316,7 +475,7 @@ func Set(name, value string) error {
 	if err := ValidIdentifier(name); err != nil {
 		return err
 	}
-	if name == "ON_ERROR_STOP" || name == "QUIET" {
+	if name == "CONFIRM_DESTRUCTIVE" || name == "KEYCHAIN" || name == "ON_ERROR_STOP" || name == "PROGRESS" || name == "QUIET" || name == "RECONNECT" {
 		if value == "" {
 			value = "on"
 		} else {
@@ -326,10 +485,59 @@ func Set(name, value string) error {
 			}
 		}
 	}
+	if name == "COMP_KEYWORD_CASE" {
+		switch value {
+		case "upper", "lower", "preserve-upper", "preserve-lower":
+		default:
+			return fmt.Errorf(text.FormatFieldInvalidValue, value, name, "upper, lower, preserve-upper, or preserve-lower")
+		}
+	}
+	if name == "STATEMENT_TIMEOUT" {
+		if _, err := parseTimeout(value); err != nil {
+			return fmt.Errorf(text.FormatFieldInvalidValue, value, name, "duration or number of seconds")
+		}
+	}
+	if name == "IMPLICIT_LIMIT" && value != "" {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf(text.FormatFieldInvalidValue, value, name, "non-negative integer")
+		}
+	}
 	vars.Set(name, value)
 	return nil
 }
 
+// ImplicitLimit returns the IMPLICIT_LIMIT variable, or 0 when unset.
+func ImplicitLimit() int {
+	n, _ := strconv.Atoi(vars["IMPLICIT_LIMIT"])
+	return n
+}
+
+// StatementTimeout returns the STATEMENT_TIMEOUT variable, or 0 when unset.
+func StatementTimeout() time.Duration {
+	d, _ := parseTimeout(vars["STATEMENT_TIMEOUT"])
+	return d
+}
+
+// parseTimeout parses a timeout as a duration (such as 30s or 500ms), or as a
+// number of seconds, with an empty string or 0 disabling the timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return 0, err
+		}
+		d = time.Duration(f * float64(time.Second))
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative timeout %s", s)
+	}
+	return d, nil
+}
+
 // Unset unsets a variable.
 func Unset(name string) error {
 	if err := ValidIdentifier(name); err != nil {
@@ -382,9 +590,13 @@ func Pwrite(w io.Writer) error {
 }
 
 var (
-	formatRE    = regexp.MustCompile(`^(unaligned|aligned|wrapped|html|asciidoc|latex|latex-longtable|troff-ms|csv|json|vertical)$`)
+	formatRE    = regexp.MustCompile(`^(unaligned|aligned|wrapped|html|asciidoc|latex|latex-longtable|troff-ms|csv|json|vertical|chart|interactive|count)$`)
 	linestlyeRE = regexp.MustCompile(`^(ascii|old-ascii|unicode)$`)
 	borderRE    = regexp.MustCompile(`^(single|double)$`)
+	chartTypeRE = regexp.MustCompile(`^(bar|line|sparkline)$`)
+	nullStyleRE = regexp.MustCompile(`^(text|color|symbol)$`)
+	compressRE  = regexp.MustCompile(`^(auto|none|gzip|zstd)$`)
+	pagerFmtRE  = regexp.MustCompile(`^(text|csv|tsv|json)$`)
 )
 
 func ParseBool(value, name string) (string, error) {
@@ -432,7 +644,7 @@ func Ptoggle(name, extra string) (string, error) {
 		return "", fmt.Errorf(text.UnknownFormatFieldName, name)
 	}
 	switch name {
-	case "border", "columns", "pager_min_lines":
+	case "border", "columns", "expanded_fieldwidth", "maxcolwidth", "pager_min_lines":
 	case "pager":
 		switch pvars[name] {
 		case "on", "always":
@@ -451,7 +663,7 @@ func Ptoggle(name, extra string) (string, error) {
 		default:
 			panic(fmt.Sprintf("invalid state for field %s", name))
 		}
-	case "fieldsep_zero", "footer", "numericlocale", "recordsep_zero", "tuples_only":
+	case "expanded_header", "fieldsep_zero", "footer", "mask", "numericlocale", "recordsep_zero", "rowcount_estimate", "schema_header", "tuples_only":
 		switch pvars[name] {
 		case "on":
 			pvars[name] = "off"
@@ -469,9 +681,10 @@ func Ptoggle(name, extra string) (string, error) {
 		default:
 			pvars[name] = "aligned"
 		}
-	case "linestyle":
+	case "chart_type", "compress", "linestyle", "nullstyle", "pager_format":
 	case "csv_fieldsep", "fieldsep", "null", "recordsep", "time", "locale":
-	case "tableattr", "title":
+	case "mask_columns":
+	case "expanded_recordsep", "float_precision", "tableattr", "thousands_sep", "timezone", "title":
 		pvars[name] = ""
 	case "unicode_border_linestyle", "unicode_column_linestyle", "unicode_header_linestyle":
 	default:
@@ -487,7 +700,7 @@ func Pset(name, value string) (string, error) {
 		return "", fmt.Errorf(text.UnknownFormatFieldName, name)
 	}
 	switch name {
-	case "border", "columns", "pager_min_lines":
+	case "border", "columns", "expanded_fieldwidth", "maxcolwidth", "pager_min_lines":
 		i, _ := strconv.Atoi(value)
 		pvars[name] = fmt.Sprintf("%d", i)
 	case "pager":
@@ -502,7 +715,7 @@ func Pset(name, value string) (string, error) {
 			return "", text.ErrInvalidFormatExpandedType
 		}
 		pvars[name] = s
-	case "fieldsep_zero", "footer", "numericlocale", "recordsep_zero", "tuples_only":
+	case "expanded_header", "fieldsep_zero", "footer", "mask", "numericlocale", "recordsep_zero", "rowcount_estimate", "schema_header", "tuples_only":
 		s, err := ParseBool(value, name)
 		if err != nil {
 			return "", err
@@ -513,12 +726,47 @@ func Pset(name, value string) (string, error) {
 			return "", text.ErrInvalidFormatType
 		}
 		pvars[name] = value
+	case "chart_type":
+		if !chartTypeRE.MatchString(value) {
+			return "", fmt.Errorf(text.ChartInvalidType, value)
+		}
+		pvars[name] = value
 	case "linestyle":
 		if !linestlyeRE.MatchString(value) {
 			return "", text.ErrInvalidFormatLineStyle
 		}
 		pvars[name] = value
-	case "csv_fieldsep", "fieldsep", "null", "recordsep", "tableattr", "time", "title", "locale":
+	case "nullstyle":
+		if !nullStyleRE.MatchString(value) {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "text, color, or symbol")
+		}
+		pvars[name] = value
+	case "compress":
+		if !compressRE.MatchString(value) {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "auto, none, gzip, or zstd")
+		}
+		pvars[name] = value
+	case "pager_format":
+		if !pagerFmtRE.MatchString(value) {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "text, csv, tsv, or json")
+		}
+		pvars[name] = value
+	case "csv_fieldsep", "expanded_recordsep", "fieldsep", "null", "recordsep", "tableattr", "thousands_sep", "time", "title", "locale":
+		pvars[name] = value
+	case "mask_columns":
+		if _, err := maskColumnsRE(value); err != nil {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "comma separated column names or regular expressions")
+		}
+		pvars[name] = value
+	case "timezone":
+		if _, err := time.LoadLocation(value); err != nil {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "time zone name")
+		}
+		pvars[name] = value
+	case "float_precision":
+		if i, err := strconv.Atoi(value); value != "" && (err != nil || i < 0) {
+			return "", fmt.Errorf(text.FormatFieldInvalidValue, value, name, "non-negative integer")
+		}
 		pvars[name] = value
 	case "unicode_border_linestyle", "unicode_column_linestyle", "unicode_header_linestyle":
 		if !borderRE.MatchString(value) {
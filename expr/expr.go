@@ -0,0 +1,591 @@
+// Package expr provides the expression language used by the \filter and \map
+// commands to post-process query results.
+//
+// Expressions use SQL-like syntax: names refer to the columns of a result
+// (double quoted when not a plain identifier), strings are single quoted, and
+// the usual arithmetic (+, -, *, /, %), comparison (=, ==, !=, <>, <, <=, >,
+// >=), and boolean (and, or, not, &&, ||, !) operators are supported, along
+// with LIKE, IN, IS [NOT] NULL, and functions such as lower, substr, round,
+// coalesce, and if.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Program is a compiled expression.
+type Program struct {
+	// Source is the source of the expression.
+	Source string
+	// Column is the index of the column when the expression is a single
+	// column name, or -1.
+	Column int
+	eval   evalFunc
+}
+
+// evalFunc evaluates an expression for the values of a row.
+type evalFunc func(row []interface{}) (interface{}, error)
+
+// Compile compiles the expression, resolving names to the columns.
+func Compile(s string, cols []string) (*Program, error) {
+	progs, _, err := compile(s, cols, false)
+	if err != nil {
+		return nil, err
+	}
+	return progs[0], nil
+}
+
+// CompileList compiles a comma separated list of expressions, each optionally
+// followed by AS and a name, resolving names to the columns. Returns the
+// compiled expressions and their names, which default to the column name for
+// single column expressions, and otherwise the source of the expression.
+func CompileList(s string, cols []string) ([]*Program, []string, error) {
+	return compile(s, cols, true)
+}
+
+// Eval evaluates the expression for the values of a row.
+func (p *Program) Eval(row []interface{}) (interface{}, error) {
+	return p.eval(row)
+}
+
+// compile compiles an expression, or a list of expressions.
+func compile(s string, cols []string, list bool) ([]*Program, []string, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{tokens: tokens, cols: cols}
+	var progs []*Program
+	var names []string
+	for {
+		start := p.peek().pos
+		prog := &Program{Column: -1}
+		if prog.eval, err = p.parseOr(); err != nil {
+			return nil, nil, err
+		}
+		prog.Source = strings.TrimSpace(s[start:p.peek().pos])
+		if p.column != -1 && p.last == 1 {
+			prog.Column = p.column
+		}
+		name := prog.Source
+		if prog.Column != -1 {
+			name = cols[prog.Column]
+		}
+		if list && p.keyword("AS") {
+			t := p.next()
+			if t.typ != tokenName {
+				return nil, nil, p.errorf(t, "expected name after AS")
+			}
+			name = t.val
+		}
+		progs, names = append(progs, prog), append(names, name)
+		if !list || !p.accept(",") {
+			break
+		}
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, nil, p.errorf(t, "unexpected %q", t.val)
+	}
+	return progs, names, nil
+}
+
+// Token types.
+const (
+	tokenEOF = iota
+	tokenName
+	tokenNumber
+	tokenString
+	tokenOp
+)
+
+// token is a lexical token.
+type token struct {
+	typ int
+	val string
+	// quoted indicates a quoted name.
+	quoted bool
+	pos    int
+}
+
+// ops are the operators, longest first.
+var ops = []string{"==", "!=", "<>", "<=", ">=", "&&", "||", "=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ","}
+
+// lex splits the expression into tokens.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	r := []rune(s)
+	// pos is the byte position of the rune at i
+	pos := func(i int) int {
+		return len(string(r[:i]))
+	}
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			start := i
+			var b strings.Builder
+			for i++; ; i++ {
+				if i >= len(r) {
+					return nil, fmt.Errorf("unterminated quoted string at position %d", pos(start)+1)
+				}
+				if r[i] == c {
+					// doubled quotes
+					if i+1 < len(r) && r[i+1] == c {
+						i++
+					} else {
+						i++
+						break
+					}
+				}
+				b.WriteRune(r[i])
+			}
+			typ := tokenString
+			if c == '"' {
+				typ = tokenName
+			}
+			tokens = append(tokens, token{typ: typ, val: b.String(), quoted: c == '"', pos: pos(start)})
+		case unicode.IsDigit(c) || c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1]):
+			start := i
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(r) && (r[i] == 'e' || r[i] == 'E') {
+				j := i + 1
+				if j < len(r) && (r[j] == '+' || r[j] == '-') {
+					j++
+				}
+				if j < len(r) && unicode.IsDigit(r[j]) {
+					for i = j; i < len(r) && unicode.IsDigit(r[i]); i++ {
+					}
+				}
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(r[start:i]), pos: pos(start)})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(r) && (r[i] == '_' || r[i] == '$' || unicode.IsLetter(r[i]) || unicode.IsDigit(r[i])) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenName, val: string(r[start:i]), pos: pos(start)})
+		default:
+			var op string
+			for _, o := range ops {
+				if strings.HasPrefix(string(r[i:min(i+2, len(r))]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, pos(i)+1)
+			}
+			tokens = append(tokens, token{typ: tokenOp, val: op, pos: pos(i)})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{typ: tokenEOF, pos: len(s)}), nil
+}
+
+// parser is an expression parser.
+type parser struct {
+	tokens []token
+	i      int
+	cols   []string
+	// column is the index of the last parsed column name, and last is the
+	// number of tokens of the last parsed expression.
+	column int
+	last   int
+}
+
+// peek returns the next token.
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+// next returns and consumes the next token.
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.typ != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token when it is one of the operators.
+func (p *parser) accept(ops ...string) bool {
+	t := p.peek()
+	if t.typ != tokenOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.val == op {
+			p.i++
+			return true
+		}
+	}
+	return false
+}
+
+// keyword consumes the next token when it is the (unquoted) keyword.
+func (p *parser) keyword(kw string) bool {
+	if p.isKeyword(0, kw) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// isKeyword returns true when the token at offset n is the keyword.
+func (p *parser) isKeyword(n int, kw string) bool {
+	if p.i+n >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.i+n]
+	return t.typ == tokenName && !t.quoted && strings.EqualFold(t.val, kw)
+}
+
+// errorf returns an error at the token.
+func (p *parser) errorf(t token, format string, v ...interface{}) error {
+	if t.typ == tokenEOF {
+		return fmt.Errorf(format+" at end of expression", v...)
+	}
+	return fmt.Errorf(format+" at position %d", append(v, t.pos+1)...)
+}
+
+// parseOr parses an or expression.
+func (p *parser) parseOr() (evalFunc, error) {
+	start := p.i
+	p.column = -1
+	a, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") || p.keyword("OR") {
+		b, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		a = logical(a, b, true)
+	}
+	p.last = p.i - start
+	return a, nil
+}
+
+// parseAnd parses an and expression.
+func (p *parser) parseAnd() (evalFunc, error) {
+	a, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") || p.keyword("AND") {
+		b, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		a = logical(a, b, false)
+	}
+	return a, nil
+}
+
+// parseNot parses a not expression.
+func (p *parser) parseNot() (evalFunc, error) {
+	if p.accept("!") || p.keyword("NOT") {
+		a, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(row []interface{}) (interface{}, error) {
+			v, err := a(row)
+			if err != nil || v == nil {
+				return nil, err
+			}
+			return !Truthy(v), nil
+		}, nil
+	}
+	return p.parseCompare()
+}
+
+// parseCompare parses a comparison.
+func (p *parser) parseCompare() (evalFunc, error) {
+	a, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case p.accept("=", "==", "!=", "<>", "<", "<=", ">", ">="):
+		b, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		return comparison(t.val, a, b), nil
+	case p.keyword("IS"):
+		not := p.keyword("NOT")
+		if !p.keyword("NULL") {
+			return nil, p.errorf(p.peek(), "expected NULL")
+		}
+		return func(row []interface{}) (interface{}, error) {
+			v, err := a(row)
+			if err != nil {
+				return nil, err
+			}
+			return (v == nil) != not, nil
+		}, nil
+	}
+	not := p.isKeyword(0, "NOT") && (p.isKeyword(1, "LIKE") || p.isKeyword(1, "IN"))
+	if not {
+		p.next()
+	}
+	switch {
+	case p.keyword("LIKE"):
+		b, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		return negate(like(a, b), not), nil
+	case p.keyword("IN"):
+		if !p.accept("(") {
+			return nil, p.errorf(p.peek(), "expected (")
+		}
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		return negate(in(a, args), not), nil
+	}
+	return a, nil
+}
+
+// parseAdd parses an additive expression.
+func (p *parser) parseAdd() (evalFunc, error) {
+	a, err := p.parseMul()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !p.accept("+", "-") {
+			return a, nil
+		}
+		b, err := p.parseMul()
+		if err != nil {
+			return nil, err
+		}
+		a = arithmetic(t.val, a, b)
+	}
+}
+
+// parseMul parses a multiplicative expression.
+func (p *parser) parseMul() (evalFunc, error) {
+	a, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !p.accept("*", "/", "%") {
+			return a, nil
+		}
+		b, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		a = arithmetic(t.val, a, b)
+	}
+}
+
+// parseUnary parses a unary minus expression.
+func (p *parser) parseUnary() (evalFunc, error) {
+	if p.accept("-") {
+		a, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		zero := func([]interface{}) (interface{}, error) { return int64(0), nil }
+		return arithmetic("-", zero, a), nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a literal, name, function call, or parenthesized
+// expression.
+func (p *parser) parsePrimary() (evalFunc, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenNumber:
+		var v interface{}
+		if i, err := strconv.ParseInt(t.val, 10, 64); err == nil {
+			v = i
+		} else if f, err := strconv.ParseFloat(t.val, 64); err == nil {
+			v = f
+		} else {
+			return nil, p.errorf(t, "invalid number %q", t.val)
+		}
+		return constant(v), nil
+	case tokenString:
+		return constant(t.val), nil
+	case tokenName:
+		if !t.quoted {
+			switch strings.ToUpper(t.val) {
+			case "NULL":
+				return constant(nil), nil
+			case "TRUE":
+				return constant(true), nil
+			case "FALSE":
+				return constant(false), nil
+			}
+			if p.accept("(") {
+				return p.parseCall(t)
+			}
+		}
+		i, err := p.resolve(t)
+		if err != nil {
+			return nil, err
+		}
+		p.column = i
+		return func(row []interface{}) (interface{}, error) {
+			if i < len(row) {
+				return row[i], nil
+			}
+			return nil, nil
+		}, nil
+	case tokenOp:
+		if t.val == "(" {
+			a, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, p.errorf(p.peek(), "expected )")
+			}
+			return a, nil
+		}
+	case tokenEOF:
+		return nil, p.errorf(t, "unexpected end")
+	}
+	return nil, p.errorf(t, "unexpected %q", t.val)
+}
+
+// parseCall parses the arguments of a function call.
+func (p *parser) parseCall(t token) (evalFunc, error) {
+	f, ok := funcs[strings.ToLower(t.val)]
+	if !ok {
+		return nil, p.errorf(t, "unknown function %s", t.val)
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < f.Min || f.Max != -1 && len(args) > f.Max {
+		return nil, p.errorf(t, "wrong number of arguments to %s", t.val)
+	}
+	name := strings.ToLower(t.val)
+	return func(row []interface{}) (interface{}, error) {
+		// conditional functions evaluate their arguments lazily
+		if f.Lazy != nil {
+			return f.Lazy(row, args)
+		}
+		v := make([]interface{}, len(args))
+		for i, arg := range args {
+			var err error
+			if v[i], err = arg(row); err != nil {
+				return nil, err
+			}
+		}
+		res, err := f.Func(v...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return res, nil
+	}, nil
+}
+
+// parseArgs parses a parenthesized argument list, after the opening
+// parenthesis.
+func (p *parser) parseArgs() ([]evalFunc, error) {
+	var args []evalFunc
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		a, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.accept(")") {
+			return args, nil
+		}
+		if !p.accept(",") {
+			return nil, p.errorf(p.peek(), "expected , or )")
+		}
+	}
+}
+
+// resolve resolves the name to a column, matching the exact name, or a
+// unique case insensitive name.
+func (p *parser) resolve(t token) (int, error) {
+	for i, c := range p.cols {
+		if c == t.val {
+			return i, nil
+		}
+	}
+	if !t.quoted {
+		found := -1
+		for i, c := range p.cols {
+			if strings.EqualFold(c, t.val) {
+				if found != -1 {
+					return 0, p.errorf(t, "ambiguous column %s", t.val)
+				}
+				found = i
+			}
+		}
+		if found != -1 {
+			return found, nil
+		}
+	}
+	return 0, p.errorf(t, "unknown column %s", t.val)
+}
+
+// constant returns an expression evaluating to the value.
+func constant(v interface{}) evalFunc {
+	return func([]interface{}) (interface{}, error) {
+		return v, nil
+	}
+}
+
+// negate negates a boolean expression when not is true.
+func negate(a evalFunc, not bool) evalFunc {
+	if !not {
+		return a
+	}
+	return func(row []interface{}) (interface{}, error) {
+		v, err := a(row)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return !Truthy(v), nil
+	}
+}
+
+// logical returns an and or or expression, evaluating b only when needed.
+func logical(a, b evalFunc, or bool) evalFunc {
+	return func(row []interface{}) (interface{}, error) {
+		x, err := a(row)
+		if err != nil {
+			return nil, err
+		}
+		if Truthy(x) == or {
+			return or, nil
+		}
+		y, err := b(row)
+		if err != nil {
+			return nil, err
+		}
+		return Truthy(y), nil
+	}
+}
@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	cols := []string{"id", "Name", "price", "note", "qty"}
+	row := []interface{}{int64(7), []byte("Widget"), "12.50", nil, int32(3)}
+	tests := []struct {
+		s   string
+		exp interface{}
+	}{
+		{"id", int64(7)},
+		{"name", []byte("Widget")},
+		{`"Name" = 'Widget'`, true},
+		{"price > 10 and qty >= 3", true},
+		{"price * qty", 37.5},
+		{"id / 2", 3.5},
+		{"id + qty * 2", int64(13)},
+		{"-(id - 10) % 2", int64(1)},
+		{"name + '-' + id", "Widget-7"},
+		{"note is null", true},
+		{"note is not null", false},
+		{"note = 'x'", nil},
+		{"not note = 'x'", nil},
+		{"coalesce(note, 'none')", "none"},
+		{"name like 'W%t'", true},
+		{"name not like '_idget'", false},
+		{"id in (1, 7, 9)", true},
+		{"id not in (1, 2)", true},
+		{"upper(substr(name, 2, 3))", "IDG"},
+		{"round(price)", int64(13)},
+		{"round(price * 1.01, 2)", 12.63},
+		{"length(name) = 6 || false", true},
+		{"if(qty > 5, 'many', 'few')", "few"},
+		{"matches(name, '^W[a-z]+$')", true},
+		{"int('42') + float(1)", 43.0},
+		{"concat(name, note, 1.5)", "Widget1.5"},
+	}
+	for i, test := range tests {
+		p, err := Compile(test.s, cols)
+		if err != nil {
+			t.Errorf("test %d %q expected no error, got: %v", i, test.s, err)
+			continue
+		}
+		v, err := p.Eval(row)
+		if err != nil {
+			t.Errorf("test %d %q expected no error, got: %v", i, test.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.exp) {
+			t.Errorf("test %d %q expected %#v, got: %#v", i, test.s, test.exp, v)
+		}
+	}
+}
+
+func TestCompileList(t *testing.T) {
+	cols := []string{"id", "name"}
+	progs, names, err := CompileList(`name, id * 2 as double, lower(name)`, cols)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := []string{"name", "double", "lower(name)"}; !reflect.DeepEqual(names, exp) {
+		t.Errorf("expected names %v, got: %v", exp, names)
+	}
+	if progs[0].Column != 1 || progs[1].Column != -1 {
+		t.Errorf("expected columns 1 and -1, got: %d and %d", progs[0].Column, progs[1].Column)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cols := []string{"id", "ID", "name"}
+	tests := []string{
+		"",
+		"missing = 1",
+		"Id = 1",
+		"name = 'unterminated",
+		"nosuch(name)",
+		"lower(name, id)",
+		"id = ",
+		"(id",
+		"id id",
+		"id ? 1",
+	}
+	for i, s := range tests {
+		if _, err := Compile(s, cols); err == nil {
+			t.Errorf("test %d %q expected error", i, s)
+		}
+	}
+	p, err := Compile("name / 0", cols)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := p.Eval([]interface{}{1, 1, 5}); err == nil {
+		t.Errorf("expected division by zero error")
+	}
+}
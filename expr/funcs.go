@@ -0,0 +1,254 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// function is an expression function.
+type function struct {
+	// Min and Max are the minimum and maximum number of arguments, with -1
+	// for any number of arguments.
+	Min, Max int
+	// Desc is the description of the function.
+	Desc string
+	// Func is the function, called with the values of the arguments.
+	Func func(...interface{}) (interface{}, error)
+	// Lazy is the function, called with the unevaluated arguments, for
+	// conditional functions.
+	Lazy func([]interface{}, []evalFunc) (interface{}, error)
+}
+
+// funcs are the expression functions.
+var funcs map[string]function
+
+func init() {
+	funcs = map[string]function{
+		"abs": {1, 1, "absolute value of a number", numeric(math.Abs, func(i int64) int64 {
+			if i < 0 {
+				return -i
+			}
+			return i
+		}), nil},
+		"ceil": {1, 1, "smallest integer greater than or equal to a number", numeric(math.Ceil, nil), nil},
+		"coalesce": {1, -1, "first non-null argument", nil, func(row []interface{}, args []evalFunc) (interface{}, error) {
+			for _, arg := range args {
+				v, err := arg(row)
+				if err != nil || v != nil {
+					return v, err
+				}
+			}
+			return nil, nil
+		}},
+		"concat":     {0, -1, "concatenation of the arguments, skipping nulls", concat, nil},
+		"contains":   {2, 2, "whether a string contains a substring", stringPredicate(strings.Contains), nil},
+		"endswith":   {2, 2, "whether a string ends with a suffix", stringPredicate(strings.HasSuffix), nil},
+		"float":      {1, 1, "value converted to a floating point number", toFloatFunc, nil},
+		"floor":      {1, 1, "largest integer less than or equal to a number", numeric(math.Floor, nil), nil},
+		"if":         {3, 3, "second argument when the first is true, otherwise the third", nil, ifFunc},
+		"int":        {1, 1, "value converted to an integer, truncating", toIntFunc, nil},
+		"length":     {1, 1, "number of characters of a string", length, nil},
+		"lower":      {1, 1, "string converted to lower case", stringFunc(strings.ToLower), nil},
+		"matches":    {2, 2, "whether a string matches a regular expression", matches(), nil},
+		"replace":    {3, 3, "string with all occurrences of a substring replaced", replace, nil},
+		"round":      {1, 2, "number rounded to a number of decimal digits (default 0)", round, nil},
+		"startswith": {2, 2, "whether a string starts with a prefix", stringPredicate(strings.HasPrefix), nil},
+		"str":        {1, 1, "value converted to a string", str, nil},
+		"substr":     {2, 3, "substring from a (1-based) position, with an optional length", substr, nil},
+		"trim":       {1, 1, "string with leading and trailing white space removed", stringFunc(strings.TrimSpace), nil},
+		"upper":      {1, 1, "string converted to upper case", stringFunc(strings.ToUpper), nil},
+	}
+}
+
+// errNotNumber is the not a number error.
+var errNotNumber = errors.New("not a number")
+
+// numeric returns a numeric function of a number, applying f to floats, and
+// i to integers (or returning integers as-is when i is nil).
+func numeric(f func(float64) float64, i func(int64) int64) func(...interface{}) (interface{}, error) {
+	return func(v ...interface{}) (interface{}, error) {
+		if v[0] == nil {
+			return nil, nil
+		}
+		n, ok := number(v[0])
+		if !ok {
+			return nil, errNotNumber
+		}
+		if x, ok := n.(int64); ok {
+			if i == nil {
+				return x, nil
+			}
+			return i(x), nil
+		}
+		return f(n.(float64)), nil
+	}
+}
+
+// stringFunc returns a function of a string.
+func stringFunc(f func(string) string) func(...interface{}) (interface{}, error) {
+	return func(v ...interface{}) (interface{}, error) {
+		if v[0] == nil {
+			return nil, nil
+		}
+		return f(toString(v[0])), nil
+	}
+}
+
+// stringPredicate returns a predicate function of two strings.
+func stringPredicate(f func(string, string) bool) func(...interface{}) (interface{}, error) {
+	return func(v ...interface{}) (interface{}, error) {
+		if v[0] == nil || v[1] == nil {
+			return nil, nil
+		}
+		return f(toString(v[0]), toString(v[1])), nil
+	}
+}
+
+// matches returns the regular expression match function, caching the
+// compiled regular expressions.
+func matches() func(...interface{}) (interface{}, error) {
+	cache := make(map[string]*regexp.Regexp)
+	return func(v ...interface{}) (interface{}, error) {
+		if v[0] == nil || v[1] == nil {
+			return nil, nil
+		}
+		pattern := toString(v[1])
+		re, ok := cache[pattern]
+		if !ok {
+			var err error
+			if re, err = regexp.Compile(pattern); err != nil {
+				return nil, err
+			}
+			cache[pattern] = re
+		}
+		return re.MatchString(toString(v[0])), nil
+	}
+}
+
+// ifFunc is the if function.
+func ifFunc(row []interface{}, args []evalFunc) (interface{}, error) {
+	v, err := args[0](row)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(v) {
+		return args[1](row)
+	}
+	return args[2](row)
+}
+
+// concat is the concat function.
+func concat(v ...interface{}) (interface{}, error) {
+	var s strings.Builder
+	for _, x := range v {
+		s.WriteString(toString(x))
+	}
+	return s.String(), nil
+}
+
+// length is the length function.
+func length(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	return int64(utf8.RuneCountInString(toString(v[0]))), nil
+}
+
+// replace is the replace function.
+func replace(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	return strings.ReplaceAll(toString(v[0]), toString(v[1]), toString(v[2])), nil
+}
+
+// round is the round function.
+func round(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	n, ok := number(v[0])
+	if !ok {
+		return nil, errNotNumber
+	}
+	var digits int64
+	if len(v) > 1 {
+		d, ok := number(v[1])
+		if !ok {
+			return nil, errNotNumber
+		}
+		digits = int64(toFloat(d))
+	}
+	if i, ok := n.(int64); ok && digits >= 0 {
+		return i, nil
+	}
+	p := math.Pow(10, float64(digits))
+	f := math.Round(toFloat(n)*p) / p
+	if digits <= 0 {
+		return int64(f), nil
+	}
+	return f, nil
+}
+
+// substr is the substr function.
+func substr(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	r := []rune(toString(v[0]))
+	start, ok := number(v[1])
+	if !ok {
+		return nil, errNotNumber
+	}
+	i := max(int(toFloat(start))-1, 0)
+	if i > len(r) {
+		return "", nil
+	}
+	end := len(r)
+	if len(v) > 2 {
+		n, ok := number(v[2])
+		if !ok {
+			return nil, errNotNumber
+		}
+		end = min(max(i+int(toFloat(n)), i), len(r))
+	}
+	return string(r[i:end]), nil
+}
+
+// str is the str function.
+func str(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	return toString(v[0]), nil
+}
+
+// toIntFunc is the int function.
+func toIntFunc(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	n, ok := number(v[0])
+	if !ok {
+		return nil, errNotNumber
+	}
+	if i, ok := n.(int64); ok {
+		return i, nil
+	}
+	return int64(n.(float64)), nil
+}
+
+// toFloatFunc is the float function.
+func toFloatFunc(v ...interface{}) (interface{}, error) {
+	if v[0] == nil {
+		return nil, nil
+	}
+	n, ok := number(v[0])
+	if !ok {
+		return nil, errNotNumber
+	}
+	return toFloat(n), nil
+}
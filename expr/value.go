@@ -0,0 +1,314 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// normalize normalizes a value of a result to nil, bool, int64, float64, or
+// string.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil, bool, int64, float64, string:
+		return x
+	case []byte:
+		return string(x)
+	case int:
+		return int64(x)
+	case int8:
+		return int64(x)
+	case int16:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case uint:
+		return int64(x)
+	case uint8:
+		return int64(x)
+	case uint16:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case uint64:
+		if x <= math.MaxInt64 {
+			return int64(x)
+		}
+		return float64(x)
+	case float32:
+		return float64(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// number returns the value as a number (int64 or float64), converting
+// numeric strings.
+func number(v interface{}) (interface{}, bool) {
+	switch x := normalize(v).(type) {
+	case int64, float64:
+		return x, true
+	case bool:
+		if x {
+			return int64(1), true
+		}
+		return int64(0), true
+	case string:
+		s := strings.TrimSpace(x)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// toFloat returns a number as a float64.
+func toFloat(v interface{}) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case float64:
+		return x
+	}
+	return 0
+}
+
+// toString returns the value as a string.
+func toString(v interface{}) string {
+	switch x := normalize(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// Truthy returns true when the value is true, a non-zero number, or a
+// non-empty string other than false (as parsed by strconv.ParseBool).
+func Truthy(v interface{}) bool {
+	switch x := normalize(v).(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case int64:
+		return x != 0
+	case float64:
+		return x != 0
+	case string:
+		if b, err := strconv.ParseBool(x); err == nil {
+			return b
+		}
+		return x != ""
+	}
+	return false
+}
+
+// compare compares a and b numerically when both are numbers (or numeric
+// strings), and otherwise as strings.
+func compare(a, b interface{}) int {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			if i, ok := x.(int64); ok {
+				if j, ok := y.(int64); ok {
+					switch {
+					case i < j:
+						return -1
+					case i > j:
+						return 1
+					}
+					return 0
+				}
+			}
+			f, g := toFloat(x), toFloat(y)
+			switch {
+			case f < g:
+				return -1
+			case f > g:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+// Compare compares a and b as the comparison operators of expressions do,
+// numerically when both are numbers (or numeric strings), and otherwise as
+// strings.
+func Compare(a, b interface{}) int {
+	return compare(a, b)
+}
+
+// comparison returns a comparison expression. Comparisons with null are null.
+func comparison(op string, a, b evalFunc) evalFunc {
+	return func(row []interface{}) (interface{}, error) {
+		x, err := a(row)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b(row)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || y == nil {
+			return nil, nil
+		}
+		c := compare(x, y)
+		switch op {
+		case "=", "==":
+			return c == 0, nil
+		case "!=", "<>":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	}
+}
+
+// arithmetic returns an arithmetic expression. The + operator concatenates
+// when either value is a non-numeric string. Arithmetic with null is null.
+func arithmetic(op string, a, b evalFunc) evalFunc {
+	return func(row []interface{}) (interface{}, error) {
+		x, err := a(row)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b(row)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || y == nil {
+			return nil, nil
+		}
+		m, xok := number(x)
+		n, yok := number(y)
+		if !xok || !yok {
+			if op == "+" {
+				return toString(x) + toString(y), nil
+			}
+			return nil, fmt.Errorf("invalid operands %q %s %q", toString(x), op, toString(y))
+		}
+		if i, ok := m.(int64); ok {
+			if j, ok := n.(int64); ok {
+				switch op {
+				case "+":
+					return i + j, nil
+				case "-":
+					return i - j, nil
+				case "*":
+					return i * j, nil
+				case "%":
+					if j == 0 {
+						return nil, errDivisionByZero
+					}
+					return i % j, nil
+				case "/":
+					if j == 0 {
+						return nil, errDivisionByZero
+					}
+					if i%j == 0 {
+						return i / j, nil
+					}
+				}
+			}
+		}
+		f, g := toFloat(m), toFloat(n)
+		switch op {
+		case "+":
+			return f + g, nil
+		case "-":
+			return f - g, nil
+		case "*":
+			return f * g, nil
+		}
+		if g == 0 {
+			return nil, errDivisionByZero
+		}
+		if op == "%" {
+			return math.Mod(f, g), nil
+		}
+		return f / g, nil
+	}
+}
+
+// errDivisionByZero is the division by zero error.
+var errDivisionByZero = errors.New("division by zero")
+
+// like returns a LIKE expression, matching the string against a pattern
+// where % matches any text and _ matches any character.
+func like(a, b evalFunc) evalFunc {
+	cache := make(map[string]*regexp.Regexp)
+	return func(row []interface{}) (interface{}, error) {
+		x, err := a(row)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b(row)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || y == nil {
+			return nil, nil
+		}
+		pattern := toString(y)
+		re, ok := cache[pattern]
+		if !ok {
+			var s strings.Builder
+			s.WriteString("(?s)^")
+			for _, c := range pattern {
+				switch c {
+				case '%':
+					s.WriteString(".*")
+				case '_':
+					s.WriteString(".")
+				default:
+					s.WriteString(regexp.QuoteMeta(string(c)))
+				}
+			}
+			s.WriteString("$")
+			re = regexp.MustCompile(s.String())
+			cache[pattern] = re
+		}
+		return re.MatchString(toString(x)), nil
+	}
+}
+
+// in returns an IN expression.
+func in(a evalFunc, args []evalFunc) evalFunc {
+	return func(row []interface{}) (interface{}, error) {
+		x, err := a(row)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		for _, arg := range args {
+			y, err := arg(row)
+			if err != nil {
+				return nil, err
+			}
+			if y != nil && compare(x, y) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
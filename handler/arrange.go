@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/expr"
+	"github.com/ildus/usql/text"
+	"github.com/xo/tblfmt"
+)
+
+// arrangeResults reads the result sets, sorting their rows by the comma
+// separated sort columns (each optionally followed by ASC or DESC), and
+// selecting the comma separated columns, in order. Columns are referenced by
+// name, or by position starting at 1.
+func arrangeResults(resultSet tblfmt.ResultSet, sortSpec, colsSpec string) (tblfmt.ResultSet, error) {
+	sets, err := readResults(resultSet)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range sets {
+		if len(e.cols) == 0 {
+			continue
+		}
+		if err := sortResult(e, sortSpec); err != nil {
+			return nil, err
+		}
+		if err := selectColumns(e, colsSpec); err != nil {
+			return nil, err
+		}
+	}
+	return &cachedSets{sets: sets, cachedRows: cachedRows{e: sets[0]}}, nil
+}
+
+// sortResult sorts the rows of the result by the sort columns. Nulls sort
+// last in ascending order, and first in descending order.
+func sortResult(e *cacheEntry, spec string) error {
+	type key struct {
+		i    int
+		desc bool
+	}
+	var keys []key
+	for _, s := range splitList(spec) {
+		fields := strings.Fields(s)
+		k := key{}
+		switch {
+		case len(fields) == 2 && strings.EqualFold(fields[1], "desc"):
+			k.desc = true
+		case len(fields) == 2 && strings.EqualFold(fields[1], "asc"), len(fields) == 1:
+		default:
+			return fmt.Errorf(text.ResultInvalidSort, s)
+		}
+		var err error
+		if k.i, err = resolveColumn(e.cols, fields[0]); err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.SliceStable(e.rows, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := e.rows[i][k.i], e.rows[j][k.i]
+			var c int
+			switch {
+			case a == nil && b == nil:
+				continue
+			case a == nil:
+				c = 1
+			case b == nil:
+				c = -1
+			default:
+				c = expr.Compare(a, b)
+			}
+			if c != 0 {
+				return c < 0 != k.desc
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// selectColumns replaces the columns of the result with the selected
+// columns.
+func selectColumns(e *cacheEntry, spec string) error {
+	names := splitList(spec)
+	if len(names) == 0 {
+		return nil
+	}
+	idx := make([]int, len(names))
+	for i, name := range names {
+		var err error
+		if idx[i], err = resolveColumn(e.cols, name); err != nil {
+			return err
+		}
+	}
+	cols := make([]string, len(idx))
+	for i, j := range idx {
+		cols[i] = e.cols[j]
+	}
+	if e.types != nil {
+		types := make([]*sql.ColumnType, len(idx))
+		for i, j := range idx {
+			types[i] = e.types[j]
+		}
+		e.types = types
+	}
+	for n, row := range e.rows {
+		v := make([]interface{}, len(idx))
+		for i, j := range idx {
+			v[i] = row[j]
+		}
+		e.rows[n] = v
+	}
+	e.cols = cols
+	return nil
+}
+
+// resolveColumn returns the index of the named column, matching the exact
+// name, a unique case insensitive name, or a position starting at 1.
+func resolveColumn(cols []string, name string) (int, error) {
+	for i, col := range cols {
+		if col == name {
+			return i, nil
+		}
+	}
+	found := -1
+	for i, col := range cols {
+		if strings.EqualFold(col, name) {
+			if found != -1 {
+				return 0, fmt.Errorf(text.ResultAmbiguous, name)
+			}
+			found = i
+		}
+	}
+	if found != -1 {
+		return found, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && 0 < n && n <= len(cols) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf(text.ResultUnknownColumn, name)
+}
+
+// splitList splits the comma separated list, dropping empty entries.
+func splitList(s string) []string {
+	var v []string
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			v = append(v, x)
+		}
+	}
+	return v
+}
+
+// cachedSets is a tblfmt.ResultSet for multiple cached results.
+type cachedSets struct {
+	sets []*cacheEntry
+	cachedRows
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *cachedSets) NextResultSet() bool {
+	if len(r.sets) < 2 {
+		return false
+	}
+	r.sets = r.sets[1:]
+	r.cachedRows = cachedRows{e: r.sets[0]}
+	return true
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/ildus/usql/dburl/passfile"
+	"github.com/ildus/usql/env"
+)
+
+// auditEntry is the audit log record of an executed statement.
+type auditEntry struct {
+	Time       time.Time `json:"time"`
+	Connection string    `json:"connection"`
+	Driver     string    `json:"driver"`
+	User       string    `json:"user"`
+	DBUser     string    `json:"db_user,omitempty"`
+	Statement  string    `json:"statement"`
+	Duration   float64   `json:"duration_ms"`
+	Rows       int64     `json:"rows"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLog is the open audit log, and the AUDIT_LOG value it was opened for.
+type auditLog struct {
+	dest string
+	w    io.WriteCloser
+}
+
+// audit records the statement executed at start to the audit log set by the
+// AUDIT_LOG variable, if any. The duration excludes the time spent rendering
+// the results.
+func (h *Handler) audit(start time.Time, sqlstr string, err error) {
+	dest := env.Get("AUDIT_LOG")
+	if h.auditLog != nil && h.auditLog.dest != dest {
+		h.closeAuditLog()
+	}
+	if dest == "" || h.u == nil {
+		return
+	}
+	if h.auditLog == nil {
+		w, err := openAuditLog(h.user, dest)
+		if err != nil {
+			fmt.Fprintln(h.l.Stderr(), "error:", err)
+			return
+		}
+		h.auditLog = &auditLog{dest: dest, w: w}
+	}
+	entry := auditEntry{
+		Time:       start,
+		Connection: h.u.Short(),
+		Driver:     h.u.Driver,
+		Statement:  sqlstr,
+		Duration:   float64((time.Since(start) - h.timings[phaseRender]).Microseconds()) / 1000,
+		Rows:       h.rowCount,
+	}
+	if h.user != nil {
+		entry.User = h.user.Username
+	}
+	if h.u.User != nil {
+		entry.DBUser = h.u.User.Username()
+	}
+	if err != nil {
+		entry.Error, entry.Rows = err.Error(), 0
+	}
+	buf, err := json.Marshal(entry)
+	if err == nil {
+		_, err = h.auditLog.w.Write(append(buf, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintln(h.l.Stderr(), "error:", err)
+	}
+}
+
+// closeAuditLog closes the audit log, if open.
+func (h *Handler) closeAuditLog() {
+	if h.auditLog != nil {
+		h.auditLog.w.Close()
+		h.auditLog = nil
+	}
+}
+
+// openAuditLog opens the audit log destination, either syslog, or a file
+// that records are appended to as JSON lines.
+func openAuditLog(u *user.User, dest string) (io.WriteCloser, error) {
+	if dest == "syslog" {
+		return openSyslog()
+	}
+	var homeDir string
+	if u != nil {
+		homeDir = u.HomeDir
+	}
+	return os.OpenFile(passfile.Expand(homeDir, dest), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package handler
+
+import (
+	"io"
+
+	"github.com/ildus/usql/text"
+)
+
+// openSyslog returns an error, as syslog is not available.
+func openSyslog() (io.WriteCloser, error) {
+	return nil, text.ErrSyslogNotSupported
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package handler
+
+import (
+	"io"
+	"log/syslog"
+
+	"github.com/ildus/usql/text"
+)
+
+// openSyslog opens the system log for writing audit log records.
+func openSyslog() (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, text.CommandLower())
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+	"github.com/ildus/usql/tui"
+	"github.com/xo/tblfmt"
+)
+
+// browseMaxRows is the maximum number of rows of the last result recorded
+// for \browse, \filter, and \map.
+const browseMaxRows = 100000
+
+// Browse opens the last query result in the interactive result browser.
+func (h *Handler) Browse() error {
+	switch {
+	case !h.l.Interactive():
+		return text.ErrNotInteractive
+	case h.lastResult == nil || h.u == nil:
+		return text.ErrNoResultToBrowse
+	}
+	h.warnTruncated(h.lastResult)
+	return h.browse(h.lastResult, env.Pall())
+}
+
+// browse opens the result in the interactive result browser, exporting the
+// selection with the params.
+func (h *Handler) browse(e *cacheEntry, params map[string]string) error {
+	res := tui.Result{
+		Columns: e.cols,
+		Rows:    make([][]string, len(e.rows)),
+	}
+	mask := make([]bool, len(e.cols))
+	if masked := env.Masked(params); masked != nil {
+		for i, c := range e.cols {
+			mask[i] = masked(c)
+		}
+	}
+	loc, zoned := env.Location(params), env.ZonedColumns(e.types, len(e.cols))
+	tfmt := env.GoTime()
+	for i, row := range e.rows {
+		res.Rows[i] = make([]string, len(row))
+		for j, v := range row {
+			switch {
+			case v == nil:
+				res.Rows[i][j] = params["null"]
+				continue
+			case j < len(mask) && mask[j]:
+				res.Rows[i][j] = env.MaskValue
+				continue
+			case loc != nil && zoned[j]:
+				v = env.InZone(v, loc)
+			}
+			var err error
+			if res.Rows[i][j], err = h.convert(v, tfmt); err != nil {
+				return err
+			}
+		}
+	}
+	export := func(name string, cols, rows []int) error {
+		return exportResult(e, name, cols, rows, params)
+	}
+	return tui.New(res, tui.WithExport(export)).Run()
+}
+
+// exportResult writes the rows and columns of the result to the named file,
+// in the format of the file's extension (csv, tsv, json, or html), or as csv.
+func exportResult(e *cacheEntry, name string, cols, rows []int, params map[string]string) error {
+	sub := &cacheEntry{cols: make([]string, len(cols))}
+	for i, c := range cols {
+		sub.cols[i] = e.cols[c]
+	}
+	for _, r := range rows {
+		row := make([]interface{}, len(cols))
+		for i, c := range cols {
+			row[i] = e.rows[r][c]
+		}
+		sub.rows = append(sub.rows, row)
+	}
+	p := make(map[string]string, len(params))
+	for k, v := range params {
+		p[k] = v
+	}
+	p["format"], p["expanded"] = "csv", "off"
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".json", ".html":
+		p["format"] = ext[1:]
+	case ".tsv":
+		p["csv_fieldsep"] = "\t"
+	}
+	delete(p, "pager_cmd")
+	f, err := os.OpenFile(name, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := env.EncodeAll(f, &cachedRows{e: sub}, p); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// warnTruncated warns when rows of the result were not recorded.
+func (h *Handler) warnTruncated(e *cacheEntry) {
+	if e.truncated {
+		fmt.Fprintf(h.l.Stderr(), text.ResultTruncated+"\n", len(e.rows))
+	}
+}
+
+// lastRecorder wraps a result set, recording the scanned rows of its first
+// result set with columns as the last result for \browse, \filter, and \map.
+// Rows past browseMaxRows are dropped, marking the result as truncated.
+type lastRecorder struct {
+	tblfmt.ResultSet
+	e *cacheEntry
+	// next indicates the first result set has been read.
+	next bool
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *lastRecorder) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err == nil && r.e == nil && len(cols) != 0 {
+		r.e = &cacheEntry{cols: append([]string{}, cols...)}
+		r.e.types, _ = r.ColumnTypes()
+	}
+	return cols, err
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *lastRecorder) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *lastRecorder) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil || r.e == nil || r.next {
+		return err
+	}
+	if len(r.e.rows) >= browseMaxRows {
+		r.e.truncated = true
+		return nil
+	}
+	row := make([]interface{}, len(v))
+	for i, z := range v {
+		if p, ok := z.(*interface{}); ok {
+			row[i] = *p
+		}
+	}
+	r.e.rows = append(r.e.rows, row)
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *lastRecorder) NextResultSet() bool {
+	if !r.ResultSet.NextResultSet() {
+		return false
+	}
+	r.next = r.e != nil
+	return true
+}
@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ildus/usql/text"
+	"github.com/xo/tblfmt"
+)
+
+// Result cache limits.
+const (
+	// cacheMaxEntries is the maximum number of cached results.
+	cacheMaxEntries = 128
+	// cacheMaxRows is the maximum number of rows of a cached result.
+	cacheMaxRows = 10000
+	// cacheDefaultTTL is the default time to live of cached results.
+	cacheDefaultTTL = 5 * time.Minute
+)
+
+// cacheKey is the key of a cached result.
+type cacheKey struct {
+	conn  string
+	query string
+}
+
+// cacheEntry is a cached result.
+type cacheEntry struct {
+	cols    []string
+	types   []*sql.ColumnType
+	rows    [][]interface{}
+	expires time.Time
+	// truncated indicates rows of the result past the recorded rows were
+	// dropped.
+	truncated bool
+}
+
+// resultCache is a client-side cache of query results, keyed by connection
+// and normalized query.
+type resultCache struct {
+	enabled bool
+	ttl     time.Duration
+	entries map[cacheKey]*cacheEntry
+	hits    int
+	misses  int
+}
+
+// get returns the cached result for the key, if it exists and has not
+// expired.
+func (c *resultCache) get(key cacheKey) *cacheEntry {
+	e, ok := c.entries[key]
+	switch {
+	case !ok:
+		c.misses++
+		return nil
+	case time.Now().After(e.expires):
+		delete(c.entries, key)
+		c.misses++
+		return nil
+	}
+	c.hits++
+	return e
+}
+
+// put caches the recorded result for the key.
+func (c *resultCache) put(key cacheKey, r *cacheRecorder) {
+	if !r.ok {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]*cacheEntry)
+	}
+	now := time.Now()
+	// evict expired entries, and when full, the entry expiring first
+	var oldest *cacheKey
+	for k, e := range c.entries {
+		switch {
+		case now.After(e.expires):
+			delete(c.entries, k)
+		case oldest == nil || e.expires.Before(c.entries[*oldest].expires):
+			k := k
+			oldest = &k
+		}
+	}
+	if len(c.entries) >= cacheMaxEntries && oldest != nil {
+		delete(c.entries, *oldest)
+	}
+	c.entries[key] = &cacheEntry{
+		cols:    r.cols,
+		types:   r.types,
+		rows:    r.rows,
+		expires: now.Add(c.ttl),
+	}
+}
+
+// invalidate removes the cached results for the connection.
+func (c *resultCache) invalidate(conn string) {
+	for k := range c.entries {
+		if k.conn == conn {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// clear removes all cached results, and resets the statistics.
+func (c *resultCache) clear() {
+	c.entries, c.hits, c.misses = nil, 0, 0
+}
+
+// cacheableQuery returns true when the results of queries with the prefix
+// can be cached. Data modifying statements of these types, such as SELECT ...
+// INTO, are excluded separately by drivers.ReadOnly.
+func cacheableQuery(prefix string) bool {
+	typ, _, _ := strings.Cut(prefix, " ")
+	switch typ {
+	case "SELECT", "WITH", "VALUES", "TABLE", "SHOW":
+		return true
+	}
+	return false
+}
+
+// normalizeQuery normalizes the whitespace and trailing semicolons of a
+// query, leaving quoted strings and identifiers unchanged.
+func normalizeQuery(sqlstr string) string {
+	var b strings.Builder
+	var quote rune
+	var space bool
+	for _, c := range strings.TrimRight(strings.TrimSpace(sqlstr), "; \t\r\n") {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case unicode.IsSpace(c):
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// cacheRecorder wraps a result set, recording the scanned rows of its first
+// result set.
+type cacheRecorder struct {
+	tblfmt.ResultSet
+	cols  []string
+	types []*sql.ColumnType
+	rows  [][]interface{}
+	// ok indicates the recorded result can be cached.
+	ok bool
+}
+
+// newCacheRecorder creates a new cache recorder for the result set.
+func newCacheRecorder(resultSet tblfmt.ResultSet) *cacheRecorder {
+	return &cacheRecorder{
+		ResultSet: resultSet,
+		ok:        true,
+	}
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *cacheRecorder) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err == nil && r.cols == nil {
+		r.cols = append([]string{}, cols...)
+		r.types, _ = r.ColumnTypes()
+	}
+	return cols, err
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *cacheRecorder) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *cacheRecorder) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil {
+		r.ok = false
+		return err
+	}
+	if !r.ok {
+		return nil
+	}
+	if len(r.rows) >= cacheMaxRows {
+		r.ok, r.rows = false, nil
+		return nil
+	}
+	row := make([]interface{}, len(v))
+	for i, z := range v {
+		p, ok := z.(*interface{})
+		if !ok {
+			r.ok, r.rows = false, nil
+			return nil
+		}
+		row[i] = *p
+	}
+	r.rows = append(r.rows, row)
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *cacheRecorder) NextResultSet() bool {
+	if r.ResultSet.NextResultSet() {
+		// only single result sets are cached
+		r.ok, r.rows = false, nil
+		return true
+	}
+	return false
+}
+
+// Err satisfies the tblfmt.ResultSet interface.
+func (r *cacheRecorder) Err() error {
+	err := r.ResultSet.Err()
+	if err != nil {
+		r.ok = false
+	}
+	return err
+}
+
+// cachedRows is a result set for a cached result.
+type cachedRows struct {
+	e *cacheEntry
+	i int
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) Next() bool {
+	if r.i >= len(r.e.rows) {
+		return false
+	}
+	r.i++
+	return true
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) Scan(v ...interface{}) error {
+	row := r.e.rows[r.i-1]
+	if len(v) != len(row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(row), len(v))
+	}
+	for i, z := range v {
+		p, ok := z.(*interface{})
+		if !ok {
+			return fmt.Errorf("unsupported Scan destination %T", z)
+		}
+		*p = row[i]
+	}
+	return nil
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) Columns() ([]string, error) {
+	return append([]string{}, r.e.cols...), nil
+}
+
+// ColumnTypes returns the column types of the cached result.
+func (r *cachedRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	if r.e.types == nil {
+		return nil, tblfmt.ErrResultSetHasNoColumnTypes
+	}
+	return r.e.types, nil
+}
+
+// Close satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) Close() error {
+	return nil
+}
+
+// Err satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) Err() error {
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *cachedRows) NextResultSet() bool {
+	return false
+}
+
+// SetCache sets the result cache mode and time to live.
+func (h *Handler) SetCache(enabled bool, ttl time.Duration) {
+	h.cache.enabled = enabled
+	if ttl != 0 {
+		h.cache.ttl = ttl
+	}
+	if !enabled {
+		h.cache.entries = nil
+	}
+}
+
+// ClearCache removes all cached results.
+func (h *Handler) ClearCache() {
+	h.cache.clear()
+}
+
+// CacheStats writes the result cache statistics.
+func (h *Handler) CacheStats(w io.Writer) error {
+	state := "off"
+	if h.cache.enabled {
+		state = "on"
+	}
+	var rows int
+	for _, e := range h.cache.entries {
+		rows += len(e.rows)
+	}
+	fmt.Fprintf(w, text.CacheStatsDesc, state, h.cache.ttl, len(h.cache.entries), rows, h.cache.hits, h.cache.misses)
+	fmt.Fprintln(w)
+	return nil
+}
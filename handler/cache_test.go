@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{"select 1", "select 1"},
+		{"  select\n\t1 ;  ", "select 1"},
+		{"select 1;;\n", "select 1"},
+		{"select   a,\n  b\nfrom t", "select a, b from t"},
+		// quoted strings and identifiers are unchanged
+		{"select 'a  b'", "select 'a  b'"},
+		{"select \"a \n b\" from t", "select \"a \n b\" from t"},
+		{"select `a  b`  from t", "select `a  b` from t"},
+		{"select 'it''s  a'  ,  1", "select 'it''s  a' , 1"},
+		{"select ';'", "select ';'"},
+	}
+	for i, test := range tests {
+		if s := normalizeQuery(test.s); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+	// equivalent queries have the same key
+	if a, b := normalizeQuery("SELECT *\n  FROM t;"), normalizeQuery("SELECT * FROM t"); a != b {
+		t.Errorf("expected %q and %q to be equal", a, b)
+	}
+	if a, b := normalizeQuery("SELECT 'a  b'"), normalizeQuery("SELECT 'a b'"); a == b {
+		t.Errorf("expected %q and %q to differ", a, b)
+	}
+}
+
+func TestCacheableQuery(t *testing.T) {
+	tests := []struct {
+		prefix string
+		exp    bool
+	}{
+		{"SELECT", true},
+		{"SELECT FROM", true},
+		{"WITH", true},
+		{"VALUES", true},
+		{"TABLE", true},
+		{"SHOW TABLES", true},
+		{"INSERT INTO", false},
+		{"UPDATE", false},
+		{"EXPLAIN", false},
+		{"", false},
+	}
+	for i, test := range tests {
+		if b := cacheableQuery(test.prefix); b != test.exp {
+			t.Errorf("test %d expected %t for %q, got: %t", i, test.exp, test.prefix, b)
+		}
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	c := &resultCache{enabled: true, ttl: time.Hour}
+	rec := func(v interface{}) *cacheRecorder {
+		return &cacheRecorder{cols: []string{"a"}, rows: [][]interface{}{{v}}, ok: true}
+	}
+	key := func(conn string, i int) cacheKey {
+		return cacheKey{conn, fmt.Sprintf("select %d", i)}
+	}
+	// not cached
+	if e := c.get(key("a", 0)); e != nil {
+		t.Errorf("expected no entry, got: %v", e)
+	}
+	c.put(key("a", 0), &cacheRecorder{ok: false})
+	if e := c.get(key("a", 0)); e != nil {
+		t.Errorf("expected unrecorded result not to be cached, got: %v", e)
+	}
+	c.put(key("a", 0), rec(0))
+	if e := c.get(key("a", 0)); e == nil || e.rows[0][0] != 0 {
+		t.Errorf("expected cached entry, got: %v", e)
+	}
+	if c.hits != 1 || c.misses != 2 {
+		t.Errorf("expected 1 hit and 2 misses, got: %d, %d", c.hits, c.misses)
+	}
+	// expired entries are not returned
+	c.entries[key("a", 0)].expires = time.Now().Add(-time.Second)
+	if e := c.get(key("a", 0)); e != nil {
+		t.Errorf("expected expired entry not to be returned, got: %v", e)
+	}
+	if _, ok := c.entries[key("a", 0)]; ok {
+		t.Errorf("expected expired entry to be removed")
+	}
+	// when full, the entry expiring first is evicted
+	c.clear()
+	for i := 0; i < cacheMaxEntries+1; i++ {
+		c.put(key("a", i), rec(i))
+	}
+	if n := len(c.entries); n != cacheMaxEntries {
+		t.Errorf("expected %d entries, got: %d", cacheMaxEntries, n)
+	}
+	if _, ok := c.entries[key("a", 0)]; ok {
+		t.Errorf("expected first entry to be evicted")
+	}
+	if _, ok := c.entries[key("a", cacheMaxEntries)]; !ok {
+		t.Errorf("expected last entry to be cached")
+	}
+	// invalidation only removes the entries of the connection
+	c.put(key("b", 0), rec(0))
+	c.invalidate("a")
+	if n := len(c.entries); n != 1 {
+		t.Errorf("expected 1 entry, got: %d", n)
+	}
+	if _, ok := c.entries[key("b", 0)]; !ok {
+		t.Errorf("expected entry of other connection to be cached")
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	out := new(bytes.Buffer)
+	h := newTestHandler(t, out)
+	h.SetCache(true, time.Hour)
+	ctx := context.Background()
+	exec := func(sqlstr string) string {
+		out.Reset()
+		if err := h.Execute(ctx, out, metacmd.Option{}, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return out.String()
+	}
+	exec(`CREATE TABLE t (a INTEGER)`)
+	exec(`INSERT INTO t VALUES (1)`)
+	tests := []struct {
+		sqlstr string
+		exp    string
+		cached bool
+	}{
+		{`SELECT a FROM t`, "(1 row)", false},
+		{`SELECT a FROM t`, "(1 row)", true},
+		// equivalent queries are cached as the same query
+		{"SELECT  a\n  FROM t;", "(1 row)", true},
+		// statements that are not read only invalidate the cache
+		{`INSERT INTO t VALUES (2)`, "INSERT", false},
+		{`SELECT a FROM t`, "(2 rows)", false},
+		{`SELECT a FROM t`, "(2 rows)", true},
+		{`DELETE FROM t WHERE a = 2`, "DELETE", false},
+		{`SELECT a FROM t`, "(1 row)", false},
+	}
+	for i, test := range tests {
+		s := exec(test.sqlstr)
+		if !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected output to contain %q, got: %q", i, test.exp, s)
+		}
+		if cached := strings.Contains(s, text.CachedDesc); cached != test.cached {
+			t.Errorf("test %d expected cached %t, got: %q", i, test.cached, s)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+	"github.com/xo/tblfmt"
+)
+
+// chartTypes are the supported chart types.
+var chartTypes = []string{"bar", "line", "sparkline"}
+
+// chartData are the labels and values of a chart.
+type chartData struct {
+	x, y   string
+	labels []string
+	values []string
+	nums   []float64
+	min    float64
+	max    float64
+}
+
+// chart reads the x and y columns of the result set and writes them as a
+// chart to w, using the chart_type, chart_x, and chart_y params. When the
+// results are written to a file ending in .svg, the chart is written as an SVG
+// image.
+func (h *Handler) chart(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	typ := params["chart_type"]
+	if typ == "" {
+		typ = "bar"
+	}
+	if !contains(chartTypes, typ) {
+		return fmt.Errorf(text.ChartInvalidType, typ)
+	}
+	d, err := h.chartData(resultSet, params["chart_x"], params["chart_y"])
+	if err != nil {
+		return err
+	}
+	if len(d.nums) == 0 {
+		_, err := fmt.Fprintln(w, text.ChartNoRows)
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(params["pipe"]), ".svg") {
+		return d.svg(w, typ, params["title"])
+	}
+	width, _ := strconv.Atoi(params["columns"])
+	if width <= 0 {
+		width = 80
+	}
+	if title := params["title"]; title != "" {
+		fmt.Fprintln(w, title)
+	}
+	switch typ {
+	case "line":
+		return d.line(w, width)
+	case "sparkline":
+		return d.sparkline(w)
+	}
+	return d.bar(w, width)
+}
+
+// chartData reads the chart data from the result set. The x and y columns
+// default to the first and second columns.
+func (h *Handler) chartData(resultSet tblfmt.ResultSet, x, y string) (*chartData, error) {
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return nil, drivers.WrapErr(h.u.Driver, err)
+	}
+	if drivers.LowerColumnNames(h.u) {
+		for i, c := range cols {
+			if strings.ToUpper(c) == c {
+				cols[i] = strings.ToLower(c)
+			}
+		}
+	}
+	if len(cols) < 2 {
+		return nil, text.ErrChartTooFewColumns
+	}
+	xi, err := chartColumn(cols, x, 0)
+	if err != nil {
+		return nil, err
+	}
+	yi, err := chartColumn(cols, y, 1)
+	if err != nil {
+		return nil, err
+	}
+	d := &chartData{x: cols[xi], y: cols[yi]}
+	clen, tfmt := len(cols), env.GoTime()
+	for resultSet.Next() {
+		row, err := h.scan(resultSet, clen, tfmt)
+		if err != nil {
+			return nil, err
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(row[yi]), 64)
+		if err != nil {
+			return nil, fmt.Errorf(text.ChartInvalidValue, row[yi], d.y)
+		}
+		if len(d.nums) == 0 || f < d.min {
+			d.min = f
+		}
+		if len(d.nums) == 0 || f > d.max {
+			d.max = f
+		}
+		d.labels, d.values, d.nums = append(d.labels, row[xi]), append(d.values, row[yi]), append(d.nums, f)
+	}
+	return d, resultSet.Err()
+}
+
+// chartColumn returns the position of name in cols, or def when name is
+// empty.
+func chartColumn(cols []string, name string, def int) (int, error) {
+	if name == "" {
+		return def, nil
+	}
+	for i, c := range cols {
+		if strings.EqualFold(c, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf(text.ChartUnknownColumn, name)
+}
+
+// chartEighths are the partial block characters, in eighths.
+var chartEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// bar writes a horizontal bar chart, with one bar per row.
+func (d *chartData) bar(w io.Writer, width int) error {
+	lw, vw := 0, 0
+	for i := range d.labels {
+		lw = max(lw, utf8.RuneCountInString(d.labels[i]))
+		vw = max(vw, utf8.RuneCountInString(d.values[i]))
+	}
+	bw := max(width-lw-vw-3, 10)
+	for i, f := range d.nums {
+		var bar string
+		if d.max > 0 && f > 0 {
+			n := int(f / d.max * float64(bw) * 8)
+			bar = strings.Repeat("█", n/8) + chartEighths[n%8]
+		}
+		fmt.Fprintf(w, "%s%s │%s %s\n", d.labels[i], strings.Repeat(" ", lw-utf8.RuneCountInString(d.labels[i])), bar, d.values[i])
+	}
+	return nil
+}
+
+// chartHeight is the height of line charts.
+const chartHeight = 10
+
+// line writes a line chart, with one column per row, the y axis on the left,
+// and the first and last labels below the x axis.
+func (d *chartData) line(w io.Writer, width int) error {
+	top, bottom := chartNum(d.max), chartNum(d.min)
+	aw := max(utf8.RuneCountInString(top), utf8.RuneCountInString(bottom))
+	nums := chartSample(d.nums, max(width-aw-2, 10))
+	levels := make([]int, len(nums))
+	for i, f := range nums {
+		levels[i] = chartLevel(f, d.min, d.max, chartHeight)
+	}
+	for r := chartHeight - 1; r >= 0; r-- {
+		var axis string
+		switch r {
+		case chartHeight - 1:
+			axis = top
+		case 0:
+			axis = bottom
+		}
+		var sb strings.Builder
+		for i, l := range levels {
+			prev := l
+			if i > 0 {
+				prev = levels[i-1]
+			}
+			switch {
+			case l == r:
+				sb.WriteString("•")
+			case min(prev, l) < r && r < max(prev, l):
+				sb.WriteString("│")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		fmt.Fprintf(w, "%*s ┤%s\n", aw, axis, strings.TrimRight(sb.String(), " "))
+	}
+	fmt.Fprintf(w, "%*s └%s\n", aw, "", strings.Repeat("─", len(levels)))
+	first, last := d.labels[0], d.labels[len(d.labels)-1]
+	pad := max(len(levels)-utf8.RuneCountInString(first)-utf8.RuneCountInString(last), 1)
+	_, err := fmt.Fprintf(w, "%*s  %s%s%s\n", aw, "", first, strings.Repeat(" ", pad), last)
+	return err
+}
+
+// chartLevels are the sparkline block characters.
+var chartLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline writes a single line sparkline, followed by the value range.
+func (d *chartData) sparkline(w io.Writer) error {
+	var sb strings.Builder
+	for _, f := range d.nums {
+		sb.WriteRune(chartLevels[chartLevel(f, d.min, d.max, len(chartLevels))])
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s..%s\n", sb.String(), d.y, chartNum(d.min), chartNum(d.max))
+	return err
+}
+
+// SVG chart dimensions.
+const (
+	svgWidth, svgHeight = 640, 400
+	svgMargin           = 40
+)
+
+// svg writes the chart as an SVG image. Sparklines are written as line
+// charts.
+func (d *chartData) svg(w io.Writer, typ, title string) error {
+	pw, ph := float64(svgWidth-2*svgMargin), float64(svgHeight-2*svgMargin)
+	lo, hi := math.Min(d.min, 0), math.Max(d.max, 0)
+	if typ != "bar" {
+		lo, hi = d.min, d.max
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	y := func(f float64) float64 {
+		return svgMargin + ph - (f-lo)/(hi-lo)*ph
+	}
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", svgWidth, svgHeight, svgWidth, svgHeight)
+	if title != "" {
+		fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle" font-size="16">%s</text>`+"\n", svgWidth/2, svgMargin/2, html.EscapeString(title))
+	}
+	fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", svgMargin-4, svgMargin, chartNum(hi))
+	fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", svgMargin-4, svgMargin+int(ph), chartNum(lo))
+	fmt.Fprintf(w, `<path d="M%d %d V%d H%d" fill="none" stroke="black"/>`+"\n", svgMargin, svgMargin, svgMargin+int(ph), svgMargin+int(pw))
+	step := pw / float64(len(d.nums))
+	switch typ {
+	case "bar":
+		for i, f := range d.nums {
+			top, bottom := y(math.Max(f, 0)), y(math.Min(f, 0))
+			fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="steelblue"><title>%s: %s</title></rect>`+"\n", svgMargin+float64(i)*step+step*0.1, top, step*0.8, bottom-top, html.EscapeString(d.labels[i]), html.EscapeString(d.values[i]))
+		}
+	default:
+		points := make([]string, len(d.nums))
+		for i, f := range d.nums {
+			points[i] = fmt.Sprintf("%.1f,%.1f", svgMargin+(float64(i)+0.5)*step, y(f))
+		}
+		fmt.Fprintf(w, `<polyline points="%s" fill="none" stroke="steelblue" stroke-width="2"/>`+"\n", strings.Join(points, " "))
+	}
+	fmt.Fprintf(w, `<text x="%d" y="%d">%s</text>`+"\n", svgMargin, svgHeight-svgMargin/2, html.EscapeString(d.labels[0]))
+	fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", svgWidth-svgMargin, svgHeight-svgMargin/2, html.EscapeString(d.labels[len(d.labels)-1]))
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// chartLevel scales f between lo and hi to one of n levels.
+func chartLevel(f, lo, hi float64, n int) int {
+	if hi == lo {
+		return 0
+	}
+	return int(math.Round((f - lo) / (hi - lo) * float64(n-1)))
+}
+
+// chartSample reduces nums to at most n values, averaging adjacent values.
+func chartSample(nums []float64, n int) []float64 {
+	if len(nums) <= n {
+		return nums
+	}
+	v := make([]float64, n)
+	for i := range v {
+		start, end := i*len(nums)/n, (i+1)*len(nums)/n
+		var sum float64
+		for _, f := range nums[start:end] {
+			sum += f
+		}
+		v[i] = sum / float64(end-start)
+	}
+	return v
+}
+
+// chartNum formats f for display on a chart axis.
+func chartNum(f float64) string {
+	return strconv.FormatFloat(f, 'g', 6, 64)
+}
+
+// contains determines if v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
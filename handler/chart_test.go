@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+)
+
+func TestChart(t *testing.T) {
+	out := new(bytes.Buffer)
+	h := newTestHandler(t, out)
+	ctx := context.Background()
+	if _, err := h.db.Exec(`CREATE TABLE t (a TEXT, b INTEGER, c INTEGER); INSERT INTO t VALUES ('x', 1, 8), ('y', 3, 1), ('z', 2, 4)`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		params map[string]string
+		exp    string
+	}{
+		{map[string]string{}, "▁█▅ b 1..3\n"},
+		// sorted and selected client-side, as with the other formats
+		{map[string]string{"sort": "b DESC"}, "█▅▁ b 1..3\n"},
+		{map[string]string{"cols": "a,c"}, "█▁▄ c 1..8\n"},
+		{map[string]string{"sort": "c", "cols": "1,3"}, "▁▄█ c 1..8\n"},
+	}
+	for i, test := range tests {
+		out.Reset()
+		params := map[string]string{"format": "chart", "chart_type": "sparkline"}
+		for k, v := range test.params {
+			params[k] = v
+		}
+		sqlstr := `SELECT a, b, c FROM t ORDER BY a`
+		if err := h.Execute(ctx, out, metacmd.Option{Params: params}, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := out.String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if h.rowCount != 3 {
+			t.Errorf("test %d expected row count 3, got: %d", i, h.rowCount)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+// Comment sets the comment of the table or column (when typ is COLUMN), with
+// the driver's statement, removing the comment when empty.
+func (h *Handler) Comment(ctx context.Context, typ, name, comment string) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	sqlstr, err := drivers.CommentOn(h.u, typ, name, comment)
+	if err != nil {
+		return err
+	}
+	opt := metacmd.Option{Exec: metacmd.ExecOnly}
+	return h.Execute(ctx, h.GetOutput(), opt, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false)
+}
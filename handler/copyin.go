@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/rline"
+	"github.com/ildus/usql/text"
+)
+
+// CopyIn copies the data read from the input into the table, returning the
+// number of copied rows. Like psql, the data is read from the lines following
+// the command, up to a line containing only \. When there are no more lines
+// of input, such as when executing commands passed with -c, the data is
+// streamed from standard input instead.
+func (h *Handler) CopyIn(ctx context.Context, table string, opts drivers.CopyInOptions) (int64, error) {
+	if h.db == nil {
+		return 0, text.ErrNotConnected
+	}
+	if h.tx != nil {
+		return 0, text.ErrCopyInTransaction
+	}
+	if h.l.Interactive() {
+		fmt.Fprintln(h.l.Stdout(), text.CopyInPrompt)
+		h.l.Prompt(text.CopyInLinePrompt)
+	}
+	line, err := h.l.Next()
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	var r io.Reader = os.Stdin
+	if len(line) != 0 || err == nil {
+		r = &inputReader{l: h.l, eof: err == io.EOF}
+		r.(*inputReader).add(line)
+	}
+	// display progress unless the data is entered interactively
+	if p := h.copyProgress(progressRead); p != nil && !h.l.Interactive() {
+		defer p.done()
+		r = p.reader(r)
+	}
+	n, err := drivers.CopyIn(ctx, h.u, h.db, r, table, opts)
+	if err != nil {
+		return n, err
+	}
+	// the copied rows may have changed the results of cached queries
+	h.cache.invalidate(h.u.String())
+	return n, nil
+}
+
+// execCopyIn executes a statement copying data from standard input, such as
+// COPY ... FROM STDIN.
+func (h *Handler) execCopyIn(ctx context.Context, w io.Writer, typ, table string, opts drivers.CopyInOptions) error {
+	n, err := h.CopyIn(ctx, table, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, typ, n)
+	h.rowCount = n
+	return env.Set("ROW_COUNT", strconv.FormatInt(n, 10))
+}
+
+// inputReader reads the lines of an input, up to a line containing only \.
+type inputReader struct {
+	l   rline.IO
+	buf []byte
+	eof bool
+}
+
+// add adds a line to the buffer.
+func (r *inputReader) add(line []rune) {
+	switch s := string(line); {
+	case s == `\.`:
+		r.eof = true
+	case s != "" || !r.eof:
+		r.buf = append(append(r.buf, s...), '\n')
+	}
+}
+
+// Read satisfies the io.Reader interface.
+func (r *inputReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		line, err := r.l.Next()
+		switch {
+		case err == io.EOF:
+			r.eof = true
+		case err != nil:
+			return 0, err
+		}
+		r.add(line)
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// importSampleRows is the number of records sampled to infer the column types
+// of the tables created by \import.
+const importSampleRows = 1000
+
+// Import copies the data of the file into the table, returning the number of
+// copied rows. When create is true, the table is first created, with the
+// column types inferred from a sample of the data.
+func (h *Handler) Import(ctx context.Context, name, table string, create bool, opts drivers.CopyInOptions) (int64, error) {
+	if h.db == nil {
+		return 0, text.ErrNotConnected
+	}
+	if h.tx != nil {
+		return 0, text.ErrImportTransaction
+	}
+	if opts.Format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".tsv", ".tab", ".txt":
+			opts.Format = "text"
+		}
+	}
+	if create {
+		f, err := os.Open(name)
+		if err != nil {
+			return 0, err
+		}
+		names, kinds, err := drivers.InferColumns(f, opts, importSampleRows)
+		f.Close()
+		switch {
+		case err != nil:
+			return 0, err
+		case len(names) == 0:
+			return 0, text.ErrNoColumnsToImport
+		}
+		if _, err := h.db.ExecContext(ctx, drivers.CreateTable(h.u, table, names, kinds)); err != nil {
+			return 0, err
+		}
+		h.Print("CREATE TABLE")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if p := h.copyProgress(progressRead); p != nil {
+		defer p.done()
+		r = p.reader(r)
+	}
+	n, err := drivers.CopyIn(ctx, h.u, h.db, r, table, opts)
+	if err != nil {
+		return n, err
+	}
+	h.cache.invalidate(h.u.String())
+	return n, nil
+}
+
+// Copy copies the results of query on the source database to the table on
+// the destination database, returning the number of copied rows.
+func (h *Handler) Copy(ctx context.Context, srcURL, destURL *dburl.URL, query, table string) (int64, error) {
+	stdout, stderr := h.l.Stdout, h.l.Stderr
+	src, err := drivers.Open(ctx, srcURL, stdout, stderr)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	dest, err := drivers.Open(ctx, destURL, stdout, stderr)
+	if err != nil {
+		return 0, err
+	}
+	defer dest.Close()
+	// get the result set
+	rows, err := src.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	// the rows are consumed by the driver, so only the elapsed time is
+	// displayed until done
+	p := h.copyProgress(progressCopy)
+	var stop func()
+	if p != nil {
+		stop = p.ticker()
+	}
+	n, err := drivers.Copy(ctx, destURL, stdout, stderr, rows, table)
+	if p != nil {
+		stop()
+		p.rows = n
+		p.done()
+	}
+	return n, err
+}
+
+// copyProgress returns a progress of kind rendering to standard error when
+// the PROGRESS variable is enabled, or nil otherwise.
+func (h *Handler) copyProgress(kind progressKind) *progress {
+	if env.All()["PROGRESS"] != "on" {
+		return nil
+	}
+	return newProgress(h.l.Stderr(), kind)
+}
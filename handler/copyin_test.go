@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/drivers"
+)
+
+func TestImport(t *testing.T) {
+	out := new(bytes.Buffer)
+	h := newTestHandler(t, out)
+	name := filepath.Join(h.wd, "data.csv")
+	data := "id,price,day,at,note\n1,2,2024-01-02,2024-01-02,x\n2,2.5,,2024-01-02 03:04:05,\n,,2024-01-03,,z\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	n, err := h.Import(context.Background(), name, "t", true, drivers.CopyInOptions{Header: true})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 rows, got: %d", n)
+	}
+	if s := out.String(); !strings.Contains(s, "CREATE TABLE") {
+		t.Errorf("expected CREATE TABLE, got: %q", s)
+	}
+	rows, err := h.db.Query(`SELECT name, type FROM pragma_table_info('t') ORDER BY cid`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cols = append(cols, name+" "+typ)
+	}
+	if exp := "id BIGINT, price DOUBLE PRECISION, day DATE, at TIMESTAMP, note TEXT"; strings.Join(cols, ", ") != exp {
+		t.Errorf("expected columns %q, got: %q", exp, strings.Join(cols, ", "))
+	}
+	// empty cells are imported as nulls
+	var nulls int
+	if err := h.db.QueryRow(`SELECT COUNT(*) FROM t WHERE id IS NULL OR price IS NULL OR day IS NULL OR at IS NULL OR note IS NULL`).Scan(&nulls); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if nulls != 2 {
+		t.Errorf("expected 2 rows with nulls, got: %d", nulls)
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ildus/usql/text"
+	"github.com/xo/tblfmt"
+)
+
+// countResults writes the number of rows of each result set, streaming the
+// rows without scanning or buffering them.
+func countResults(w io.Writer, resultSet tblfmt.ResultSet) error {
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return err
+		}
+		var n int64
+		for resultSet.Next() {
+			n++
+		}
+		if err := resultSet.Err(); err != nil {
+			return err
+		}
+		if len(cols) != 0 {
+			fmt.Fprintf(w, text.CountDesc+"\n", n)
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+)
+
+// confirmDestructive displays the objects affected by a destructive
+// statement, and reads the typed confirmation of the statement, when
+// CONFIRM_DESTRUCTIVE is on. Destructive statements are never confirmed when
+// not interactive.
+func (h *Handler) confirmDestructive(ctx context.Context, sqlstr string) error {
+	if env.Get("CONFIRM_DESTRUCTIVE") != "on" {
+		return nil
+	}
+	d, ok := drivers.ParseDestructive(sqlstr)
+	switch {
+	case !ok:
+		return nil
+	case !h.l.Interactive():
+		return text.ErrStatementNotConfirmed
+	}
+	stdout := h.l.Stdout()
+	if d.Type == "DELETE" || d.Type == "UPDATE" {
+		fmt.Fprintf(stdout, text.DestructiveNoWhere, d.Type)
+	} else {
+		fmt.Fprintf(stdout, text.DestructiveDesc, d.Type)
+	}
+	fmt.Fprintln(stdout)
+	// read on the active transaction, if any
+	for _, obj := range drivers.DestructiveObjects(ctx, h.u, h.DB(), d) {
+		var desc []string
+		if obj.Type != "" {
+			desc = append(desc, strings.ToLower(obj.Type))
+		}
+		switch obj.Rows {
+		case -1:
+		case 1:
+			desc = append(desc, text.DestructiveRow)
+		default:
+			desc = append(desc, fmt.Sprintf(text.DestructiveRows, obj.Rows))
+		}
+		if len(desc) == 0 {
+			desc = append(desc, "unknown")
+		}
+		fmt.Fprintf(stdout, text.DestructiveObject, obj.Name, strings.Join(desc, ", "))
+		fmt.Fprintln(stdout)
+	}
+	h.l.Prompt(fmt.Sprintf(text.DestructiveConfirm, d.Keyword()))
+	r, err := h.l.Next()
+	if err != nil || strings.TrimSpace(string(r)) != d.Keyword() {
+		return text.ErrStatementNotConfirmed
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/expr"
+	"github.com/ildus/usql/text"
+)
+
+// FilterResult filters the rows of the last query result by the expression,
+// displaying the filtered result, and recording it as the last result.
+func (h *Handler) FilterResult(s string) error {
+	if h.lastResult == nil || h.u == nil {
+		return text.ErrNoLastResult
+	}
+	e := h.lastResult
+	prog, err := expr.Compile(s, e.cols)
+	if err != nil {
+		return fmt.Errorf(text.InvalidExpression, err)
+	}
+	h.warnTruncated(e)
+	res := &cacheEntry{cols: e.cols, types: e.types, truncated: e.truncated}
+	for _, row := range e.rows {
+		v, err := prog.Eval(row)
+		if err != nil {
+			return fmt.Errorf(text.ExpressionFailed, err)
+		}
+		if expr.Truthy(v) {
+			res.rows = append(res.rows, row)
+		}
+	}
+	return h.showResult(res)
+}
+
+// MapResult maps the rows of the last query result to the values of the
+// comma separated expressions, displaying the mapped result, and recording it
+// as the last result.
+func (h *Handler) MapResult(s string) error {
+	if h.lastResult == nil || h.u == nil {
+		return text.ErrNoLastResult
+	}
+	e := h.lastResult
+	progs, names, err := expr.CompileList(s, e.cols)
+	if err != nil {
+		return fmt.Errorf(text.InvalidExpression, err)
+	}
+	h.warnTruncated(e)
+	res := &cacheEntry{cols: names, rows: make([][]interface{}, len(e.rows)), truncated: e.truncated}
+	for i, row := range e.rows {
+		res.rows[i] = make([]interface{}, len(progs))
+		for j, prog := range progs {
+			if res.rows[i][j], err = prog.Eval(row); err != nil {
+				return fmt.Errorf(text.ExpressionFailed, err)
+			}
+		}
+	}
+	return h.showResult(res)
+}
+
+// showResult displays a post-processed result, recording it as the last
+// result.
+func (h *Handler) showResult(e *cacheEntry) error {
+	h.lastResult = e
+	params := env.Pall()
+	params["time"] = env.GoTime()
+	w := h.GetOutput()
+	if h.out == nil {
+		params["pager_cmd"] = env.All()["PAGER"]
+	}
+	switch params["format"] {
+	case "interactive":
+		if h.out == nil && h.l.Interactive() {
+			return h.browse(e, params)
+		}
+		params["format"] = "aligned"
+	case "chart":
+		params["format"] = "aligned"
+	case "count":
+		fmt.Fprintf(w, text.CountDesc+"\n", len(e.rows))
+		return nil
+	}
+	if err := env.EncodeAll(w, &cachedRows{e: e}, params); err != nil {
+		return err
+	}
+	if params["format"] == "aligned" {
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"io"
@@ -29,7 +30,10 @@ import (
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/ildus/usql/dburl"
+	_ "github.com/ildus/usql/dburl/cloud" // cloud provider url shorthands
+	"github.com/ildus/usql/dburl/credential"
 	"github.com/ildus/usql/dburl/passfile"
+	"github.com/ildus/usql/dburl/passfile/keychain"
 	"github.com/xo/tblfmt"
 	"github.com/ildus/usql/drivers"
 	"github.com/ildus/usql/drivers/completer"
@@ -37,6 +41,7 @@ import (
 	"github.com/ildus/usql/env"
 	"github.com/ildus/usql/metacmd"
 	"github.com/ildus/usql/rline"
+	"github.com/ildus/usql/sinks"
 	"github.com/ildus/usql/stmt"
 	ustyles "github.com/ildus/usql/styles"
 	"github.com/ildus/usql/text"
@@ -57,6 +62,14 @@ type Handler struct {
 	nopw bool
 	// timing of every command executed
 	timing bool
+	// timingVerbose displays the timing of each phase of a command
+	timingVerbose bool
+	// timings of the current command
+	timings timings
+	// stats are the timing statistics of the session
+	stats timingStats
+	// cache is the query result cache
+	cache resultCache
 	// singleLineMode is single line mode
 	singleLineMode bool
 	// query statement buffer
@@ -68,12 +81,57 @@ type Handler struct {
 	// batch
 	batch    bool
 	batchEnd string
+	// bindParams are the parameters bound to the next query by \bind
+	bindParams []string
+	// prepared are the statements prepared by \prepare on the connection
+	prepared map[string]*preparedStmt
+	// tee is the output query results are additionally written to
+	tee *tee
 	// connection
 	u  *dburl.URL
 	db *sql.DB
 	tx *sql.Tx
 	// out file or pipe
 	out io.WriteCloser
+	// psetApplied are the display settings applied from the display settings
+	// defaults file for the current connection, and psetSaved are the values
+	// they replaced
+	psetApplied map[string]string
+	psetSaved   map[string]string
+	// passphrase is the master passphrase of the encrypted passfile.
+	passphrase string
+	// rowCount is the number of rows returned or affected by the current
+	// statement.
+	rowCount int64
+	// auditLog is the audit log executed statements are recorded to.
+	auditLog *auditLog
+	// session are the statements that changed the session state of the
+	// connection, replayed on reconnect.
+	session []sessionStmt
+	// replayed are the driver connections the session statements were
+	// replayed on after reconnecting, or nil when not reconnected.
+	replayed map[driver.Conn]bool
+	// lastQueryID is the server query id of the last executed query.
+	lastQueryID string
+	// lastResult is the last query result, browsed by \browse, processed
+	// by \filter and \map, and stored by \store.
+	lastResult *cacheEntry
+	// stored are the results stored by \store, referenced as ::name in
+	// statements.
+	stored map[string]*cacheEntry
+	// replica is the read replica connection, and route is the routing of
+	// statements set by \route.
+	replica *replica
+	route   string
+	// lastRoute is the connection (primary or replica) the last statement
+	// was routed to.
+	lastRoute string
+	// watchDiff are the previous results of the query watched by
+	// \watch --diff.
+	watchDiff *watchDiff
+	// limit is the number of rows the executing statement was limited to by
+	// IMPLICIT_LIMIT, or 0.
+	limit int
 }
 
 // New creates a new input handler.
@@ -95,12 +153,14 @@ func New(l rline.IO, user *user.User, wd string, nopw bool) *Handler {
 		l:    l,
 		user: user,
 		wd:   wd,
-		nopw: nopw,
-		buf:  stmt.New(f),
+		nopw:  nopw,
+		buf:   stmt.New(f),
+		cache: resultCache{ttl: cacheDefaultTTL},
 	}
 	if iactive {
 		l.SetOutput(h.outputHighlighter)
 	}
+	passfile.Passphrase = h.passfilePassphrase
 	return h
 }
 
@@ -119,6 +179,50 @@ func (h *Handler) SetTiming(timing bool) {
 	h.timing = timing
 }
 
+// GetTimingVerbose gets the verbose timing toggle.
+func (h *Handler) GetTimingVerbose() bool {
+	return h.timingVerbose
+}
+
+// SetTimingVerbose sets the verbose timing toggle.
+func (h *Handler) SetTimingVerbose(timingVerbose bool) {
+	h.timingVerbose = timingVerbose
+}
+
+// TimingStats writes the timing statistics of the session to w.
+func (h *Handler) TimingStats(w io.Writer) error {
+	return h.stats.write(w)
+}
+
+// unquote returns a func that unquotes strings and variables for the
+// statement buffer. Quoted variables (ie, :'NAME' and :"NAME") are quoted as
+// a string literal or identifier using the connected driver's quoting rules.
+func (h *Handler) unquote() func(string, bool) (bool, string, error) {
+	vars := env.All()
+	f := env.Unquote(h.user, false, vars)
+	return func(s string, isvar bool) (bool, string, error) {
+		switch {
+		case isvar && !h.buf.Active():
+			// variables are not interpolated in inactive conditional branches
+			return false, s, nil
+		case !isvar || len(s) < 2 || (s[0] != '\'' && s[0] != '"'):
+			return f(s, isvar)
+		}
+		n, err := env.Dequote(s, s[0])
+		if err != nil {
+			return false, "", err
+		}
+		val, ok := vars[n]
+		switch {
+		case !ok:
+			return false, s, nil
+		case s[0] == '"':
+			return true, drivers.QuoteIdentifier(h.u, val), nil
+		}
+		return true, drivers.QuoteLiteral(h.u, val), nil
+	}
+}
+
 // outputHighlighter returns s as a highlighted string, based on the current
 // buffer and syntax highlighting settings.
 func (h *Handler) outputHighlighter(s string) string {
@@ -172,7 +276,7 @@ func (h *Handler) outputHighlighter(s string) string {
 	var cmd, final string
 loop:
 	for {
-		cmd, _, err = st.Next(env.Unquote(h.user, false, env.All()))
+		cmd, _, err = st.Next(h.unquote())
 		switch {
 		case err != nil && err != io.EOF:
 			return s + endl
@@ -237,7 +341,7 @@ func (h *Handler) Run() error {
 			h.l.Prompt(h.Prompt(env.Get("PROMPT1")))
 		}
 		// read next statement/command
-		cmd, paramstr, err := h.buf.Next(env.Unquote(h.user, false, env.All()))
+		cmd, paramstr, err := h.buf.Next(h.unquote())
 		switch {
 		case h.singleLineMode && err == nil:
 			execute = h.buf.Len != 0
@@ -246,13 +350,28 @@ func (h *Handler) Run() error {
 			continue
 		case err != nil:
 			if err == io.EOF {
+				if h.buf.CondDepth() != 0 {
+					err := text.ErrUnterminatedIf
+					if h.buf.InLoop() {
+						err = text.ErrUnterminatedWhile
+					}
+					fmt.Fprintln(stderr, "error:", err)
+					return err
+				}
 				return lastErr
 			}
 			return err
 		}
+		cmd = strings.TrimPrefix(cmd, `\`)
+		// skip statements and commands in inactive conditional branches
+		if !h.buf.Active() {
+			h.buf.Discard()
+			if cmd == "" || !metacmd.IsConditional(cmd) {
+				continue
+			}
+		}
 		var opt metacmd.Option
 		if cmd != "" {
-			cmd = strings.TrimPrefix(cmd, `\`)
 			params := stmt.DecodeParams(paramstr)
 			// decode
 			r, err := metacmd.Decode(cmd, params)
@@ -317,6 +436,8 @@ func (h *Handler) Run() error {
 			if h.out != nil {
 				h.out.Close()
 			}
+			h.SetTee(nil, "")
+			h.closeAuditLog()
 			return nil
 		}
 		// execute buf
@@ -401,16 +522,36 @@ func (h *Handler) Execute(ctx context.Context, w io.Writer, opt metacmd.Option,
 		return text.ErrNotConnected
 	}
 	// determine type and pre process string
+	start, raw := time.Now(), sqlstr
 	prefix, sqlstr, qtyp, err := drivers.Process(h.u, prefix, sqlstr)
 	if err != nil {
 		return drivers.WrapErr(h.u.Driver, err)
 	}
+	sqlstr = h.expandStored(sqlstr)
+	sqlstr, h.limit = h.implicitLimit(opt, prefix, sqlstr, qtyp)
+	defer func() { h.limit = 0 }()
+	h.timings, h.rowCount = timings{phaseParse: time.Since(start)}, 0
+	if err := h.confirmDestructive(ctx, sqlstr); err != nil {
+		return err
+	}
+	// use parameters bound by \bind
+	if h.bindParams != nil {
+		opt.Bind, h.bindParams = h.bindParams, nil
+	}
 	// start a transaction if forced
 	if forceTrans {
 		if err = h.BeginTx(ctx, nil); err != nil {
 			return err
 		}
 	}
+	// cancel statements running longer than the statement timeout, except
+	// for watched queries
+	timeout := env.StatementTimeout()
+	if timeout != 0 && opt.Exec != metacmd.ExecWatch {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	f := h.execSingle
 	switch opt.Exec {
 	case metacmd.ExecExec:
@@ -419,8 +560,31 @@ func (h *Handler) Execute(ctx context.Context, w io.Writer, opt metacmd.Option,
 		f = h.execSet
 	case metacmd.ExecWatch:
 		f = h.execWatch
+	case metacmd.ExecDesc:
+		f = h.execDesc
+	}
+	err = drivers.WrapErr(h.u.Driver, f(ctx, w, opt, prefix, sqlstr, qtyp))
+	// reconnect when the connection was lost, executing read only queries
+	// again, as other statements may have been executed before the
+	// connection was lost
+	if !forceTrans && h.canReconnect(err) {
+		switch rerr := h.reconnect(ctx); {
+		case rerr != nil:
+			err = fmt.Errorf("%w (reconnect failed: %v)", err, rerr)
+		case qtyp && drivers.ReadOnly(prefix, sqlstr):
+			err = drivers.WrapErr(h.u.Driver, f(ctx, w, opt, prefix, sqlstr, qtyp))
+		default:
+			err = fmt.Errorf("%w (%s)", err, text.ReconnectNotRetried)
+		}
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w (%v)", text.ErrStatementTimeout, timeout)
 	}
-	if err = drivers.WrapErr(h.u.Driver, f(ctx, w, opt, prefix, sqlstr, qtyp)); err != nil {
+	h.audit(start, sqlstr, err)
+	if err == nil {
+		h.trackSession(raw)
+	}
+	if err != nil {
 		if forceTrans {
 			defer h.tx.Rollback()
 			h.tx = nil
@@ -669,6 +833,12 @@ func (h *Handler) LastRaw() string {
 	return h.lastRaw
 }
 
+// LastQueryID returns the server query id of the last executed query, if
+// any.
+func (h *Handler) LastQueryID() string {
+	return h.lastQueryID
+}
+
 // Buf returns the current query statement buffer.
 func (h *Handler) Buf() *stmt.Stmt {
 	return h.buf
@@ -713,29 +883,37 @@ func (h *Handler) Open(ctx context.Context, params ...string) error {
 	if h.tx != nil {
 		return text.ErrPreviousTransactionExists
 	}
+	h.restorePset()
+	h.deallocateAll()
+	h.session, h.replayed = nil, nil
+	if err := h.CloseReplica(); err != nil {
+		return err
+	}
 	if len(params) < 2 {
-		urlstr := params[0]
+		// resolve url shorthands
+		urlstr, err := dburl.Resolve(ctx, params[0])
+		if err != nil {
+			return err
+		}
+		params = []string{urlstr}
 		// parse dsn
 		u, err := dburl.Parse(urlstr)
 		switch {
 		case err == dburl.ErrInvalidDatabaseScheme:
-			fi, err := os.Stat(urlstr)
-			switch {
-			case err != nil:
+			if urlstr, err = fileURL(urlstr); err != nil {
 				return err
-			case fi.IsDir():
-				return h.Open(ctx, "postgres+unix:"+urlstr)
-			case fi.Mode()&os.ModeSocket != 0:
-				return h.Open(ctx, "mysql+unix:"+urlstr)
 			}
-			// it is a file, so reattempt to open it with sqlite3
-			return h.Open(ctx, "sqlite3:"+urlstr)
+			return h.Open(ctx, urlstr)
 		case err != nil:
 			return err
 		}
 		h.u = u
 		// force parameters
 		h.forceParams(h.u)
+		// retrieve credential from provider
+		if h.u, err = credential.Resolve(ctx, h.u); err != nil {
+			return err
+		}
 	} else {
 		h.u = &dburl.URL{
 			Driver: params[0],
@@ -755,6 +933,7 @@ func (h *Handler) Open(ctx context.Context, params ...string) error {
 	if err == nil {
 		if err = drivers.Ping(ctx, h.u, h.db); err == nil {
 			h.l.Completer(drivers.NewCompleter(ctx, h.u, h.db, readerOpts(), completer.WithConnStrings(connStrings)))
+			h.applyPset()
 			return h.Version(ctx)
 		}
 	}
@@ -776,8 +955,63 @@ func (h *Handler) Open(ctx context.Context, params ...string) error {
 	return h.Open(ctx, dsn)
 }
 
+// fileURL returns the database URL for a path on disk: a postgres unix
+// domain socket directory, a mysql unix domain socket, or a sqlite3 database
+// file.
+func fileURL(path string) (string, error) {
+	fi, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return "", err
+	case fi.IsDir():
+		return "postgres+unix:" + path, nil
+	case fi.Mode()&os.ModeSocket != 0:
+		return "mysql+unix:" + path, nil
+	}
+	return "sqlite3:" + path, nil
+}
+
+// OpenDB opens a database connection separate from the current connection,
+// resolving urlstr the same way as Open: resolving URL shorthands and paths
+// on disk, forcing the driver parameters, using the matching passfile or
+// keychain entry, and resolving the credential from its provider.
+func (h *Handler) OpenDB(ctx context.Context, urlstr string) (*dburl.URL, *sql.DB, error) {
+	urlstr, err := dburl.Resolve(ctx, urlstr)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := dburl.Parse(urlstr)
+	if err == dburl.ErrInvalidDatabaseScheme {
+		if urlstr, err = fileURL(urlstr); err != nil {
+			return nil, nil, err
+		}
+		u, err = dburl.Parse(urlstr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	h.forceParams(u)
+	if u, err = credential.Resolve(ctx, u); err != nil {
+		return nil, nil, err
+	}
+	db, err := drivers.Open(ctx, u, h.GetOutput, h.IO().Stderr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := drivers.Ping(ctx, u, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return u, db, nil
+}
+
 func (h *Handler) connStrings() []string {
-	entries, err := passfile.Entries(h.user.HomeDir, text.PassfileName)
+	var entries []passfile.Entry
+	var err error
+	// don't prompt for the passphrase of an encrypted passfile
+	if file := passfile.Path(h.user.HomeDir, text.PassfileName); !h.passfileLocked(file) {
+		entries, err = passfile.ParseFile(file)
+	}
 	if err != nil {
 		// ignore the error as this is only used for completer
 		// and it'll be reported again when trying to force params before opening a conn
@@ -791,6 +1025,15 @@ func (h *Handler) connStrings() []string {
 		names = append(names, schema)
 		names = append(names, aliases...)
 	}
+	names = append(names, PassfileConnStrings(entries)...)
+	sort.Strings(names)
+	return names
+}
+
+// PassfileConnStrings returns the connection strings of the passfile entries,
+// for completion. Wildcard fields are omitted.
+func PassfileConnStrings(entries []passfile.Entry) []string {
+	var names []string
 	for _, entry := range entries {
 		if entry.Protocol == "*" {
 			continue
@@ -810,20 +1053,25 @@ func (h *Handler) connStrings() []string {
 		}
 		names = append(names, fmt.Sprintf("%s://%s%s%s%s", entry.Protocol, user, host, port, dbname))
 	}
-	sort.Strings(names)
 	return names
 }
 
 // forceParams forces connection parameters on a database URL, adding any
 // driver specific required parameters, and the username/password when a
-// matching entry exists in the PASS file.
+// matching entry exists in the PASS file or, when KEYCHAIN is on, the OS
+// keychain.
 func (h *Handler) forceParams(u *dburl.URL) {
 	// force driver parameters
 	drivers.ForceParams(u)
 	// see if password entry is present
 	user, err := passfile.Match(u, h.user.HomeDir, text.PassfileName)
+	// only open the keychain when enabled, as it may display dialogs
+	if err == nil && user == nil && env.Get("KEYCHAIN") == "on" {
+		user, err = keychain.Match(u)
+	}
 	switch {
 	case err != nil:
+		h.checkPassphrase(err)
 		fmt.Fprintln(h.l.Stderr(), "error:", err)
 	case user != nil:
 		u.User = user
@@ -833,6 +1081,46 @@ func (h *Handler) forceParams(u *dburl.URL) {
 	*u = *z
 }
 
+// applyPset applies the display settings from entries in the display
+// settings defaults file matching the current connection, in file order.
+func (h *Handler) applyPset() {
+	entries, err := env.ParsePsetFile(env.PsetFile(h.user))
+	if err != nil {
+		fmt.Fprintln(h.l.Stderr(), "error:", err)
+		return
+	}
+	h.psetApplied, h.psetSaved = make(map[string]string), make(map[string]string)
+	for _, entry := range entries {
+		if !entry.Match(h.u) {
+			continue
+		}
+		prev, err := env.Pget(entry.Name)
+		if err == nil {
+			var v string
+			if v, err = env.Pset(entry.Name, entry.Value); err == nil {
+				if _, ok := h.psetSaved[entry.Name]; !ok {
+					h.psetSaved[entry.Name] = prev
+				}
+				h.psetApplied[entry.Name] = v
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(h.l.Stderr(), "error:", err)
+		}
+	}
+}
+
+// restorePset restores the display settings replaced by applyPset, unless
+// they were changed since being applied.
+func (h *Handler) restorePset() {
+	for name, v := range h.psetApplied {
+		if cur, _ := env.Pget(name); cur == v {
+			_, _ = env.Pset(name, h.psetSaved[name])
+		}
+	}
+	h.psetApplied, h.psetSaved = nil, nil
+}
+
 // Password collects a password from input, and returns a modified DSN
 // including the collected password.
 func (h *Handler) Password(dsn string) (string, error) {
@@ -861,9 +1149,14 @@ func (h *Handler) Close() error {
 		return text.ErrPreviousTransactionExists
 	}
 	if h.db != nil {
+		h.restorePset()
+		h.deallocateAll()
+		if err := h.CloseReplica(); err != nil {
+			return err
+		}
 		err := h.db.Close()
 		drv := h.u.Driver
-		h.db, h.u = nil, nil
+		h.db, h.u, h.lastResult = nil, nil, nil
 		return drivers.WrapErr(drv, err)
 	}
 	return nil
@@ -986,6 +1279,12 @@ func (h *Handler) Print(format string, a ...interface{}) {
 
 // execWatch repeatedly executes a query against the database.
 func (h *Handler) execWatch(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	if opt.WatchDiff {
+		h.watchDiff = new(watchDiff)
+		defer func() {
+			h.watchDiff = nil
+		}()
+	}
 	for {
 		// this is the actual output that psql has: "Mon Jan 2006 3:04:05 PM MST"
 		// fmt.Fprintf(w, "%s (every %fs)\n\n", time.Now().Format("Mon Jan 2006 3:04:05 PM MST"), float64(opt.Watch)/float64(time.Second))
@@ -1016,10 +1315,37 @@ func (h *Handler) execSingle(ctx context.Context, w io.Writer, opt metacmd.Optio
 	return f(ctx, w, opt, prefix, sqlstr)
 }
 
+// execDesc describes the result columns of the query, without executing it.
+func (h *Handler) execDesc(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	sqlstr, args, err := h.bindArgs(opt, sqlstr)
+	if err != nil {
+		return err
+	}
+	cols, err := drivers.Describe(ctx, h.u, h.DB(), sqlstr, qtyp, args...)
+	switch {
+	case err != nil:
+		return err
+	case len(cols) == 0:
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	e := &cacheEntry{
+		cols: []string{"Column", "Type"},
+	}
+	for _, c := range cols {
+		e.rows = append(e.rows, []interface{}{c.Name, c.Type})
+	}
+	return env.EncodeAll(w, &cachedRows{e: e}, env.Pall())
+}
+
 // execSet executes a SQL query, setting all returned columns as variables.
 func (h *Handler) execSet(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, _ bool) error {
+	sqlstr, args, err := h.bindArgs(opt, sqlstr)
+	if err != nil {
+		return err
+	}
 	// query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
+	rows, err := h.DB().QueryContext(ctx, sqlstr, args...)
 	if err != nil {
 		return err
 	}
@@ -1057,9 +1383,13 @@ func (h *Handler) execSet(ctx context.Context, w io.Writer, opt metacmd.Option,
 
 // execExec executes a query and re-executes all columns of all rows as if they
 // were their own queries.
-func (h *Handler) execExec(ctx context.Context, w io.Writer, _ metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+func (h *Handler) execExec(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	sqlstr, args, err := h.bindArgs(opt, sqlstr)
+	if err != nil {
+		return err
+	}
 	// query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
+	rows, err := h.DB().QueryContext(ctx, sqlstr, args...)
 	if err != nil {
 		return err
 	}
@@ -1078,45 +1408,167 @@ func (h *Handler) execExec(ctx context.Context, w io.Writer, _ metacmd.Option, p
 
 // query executes a query against the database.
 func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, typ, sqlstr string) error {
-	start := time.Now()
-	// run query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
 	params := env.Pall()
 	params["time"] = env.GoTime()
 	for k, v := range opt.Params {
 		params[k] = v
 	}
+	// use cached results of read only queries outside of transactions
+	readOnly := drivers.ReadOnly(typ, sqlstr)
+	key := cacheKey{h.u.String(), normalizeQuery(sqlstr)}
+	cacheable := h.cache.enabled && h.tx == nil && readOnly && cacheableQuery(typ) && opt.Bind == nil &&
+		opt.Exec != metacmd.ExecWatch && params["format"] != "count" &&
+		!drivers.UseColumnTypes(h.u)
+	var cached *cacheEntry
+	if cacheable {
+		cached = h.cache.get(key)
+	}
+	var rows *sql.Rows
+	var estimate int64
+	var estimated bool
+	var outParams func() (*sql.Rows, error)
+	start := time.Now()
+	if cached == nil {
+		sqlstr, args, err := h.bindArgs(opt, sqlstr)
+		if err != nil {
+			return err
+		}
+		var outQuery string
+		if drivers.IsProcedureCall(typ) {
+			sqlstr, outQuery = drivers.OutParams(h.u, sqlstr)
+		}
+		ctx, resultID := drivers.WithResultID(ctx, h.u)
+		ctx, db, release, err := h.conn(ctx, opt, readOnly)
+		if err != nil {
+			return err
+		}
+		defer release()
+		if opt.Prepared == "" {
+			if ctx, sqlstr, err = drivers.TagQuery(ctx, h.u, db, env.Get("QUERY_TAG"), sqlstr); err != nil {
+				return err
+			}
+		}
+		// retrieve the planner's row estimate
+		if params["rowcount_estimate"] == "on" && cacheableQuery(typ) && args == nil {
+			estimate, estimated = drivers.EstimateRows(ctx, h.u, db, sqlstr)
+		}
+		// run query
+		start = time.Now()
+		rows, err = db.QueryContext(ctx, sqlstr, args...)
+		h.lastQueryID = resultID()
+		if !readOnly {
+			// queries such as INSERT ... RETURNING may have changed the
+			// results of cached queries
+			h.cache.invalidate(h.u.String())
+		}
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		if outQuery != "" {
+			outParams = func() (*sql.Rows, error) {
+				return db.QueryContext(ctx, outQuery)
+			}
+		}
+	} else {
+		h.lastQueryID = ""
+	}
+	h.timings[phaseExecute] = time.Since(start)
+	var err error
 	var pipe io.WriteCloser
 	var cmd *exec.Cmd
+	var obj *objectWriter
+	var sink sinks.RowSink
 	if pipeName := params["pipe"]; pipeName != "" || h.out != nil {
 		if params["expanded"] == "auto" && params["columns"] == "" {
 			// don't rely on terminal size when piping output to a file or cmd
 			params["expanded"] = "off"
 		}
 		if pipeName != "" {
-			if pipeName[0] == '|' {
+			switch {
+			case pipeName[0] == '|':
 				pipe, cmd, err = env.Pipe(pipeName[1:])
-			} else {
+			case isObjectURL(pipeName):
+				if obj, err = openObject(ctx, pipeName); err == nil {
+					// discard partially uploaded results on error
+					defer obj.abort()
+					pipe = obj
+				}
+			case isSinkURL(pipeName):
+				if sink, err = openSink(ctx, pipeName); err == nil {
+					// discard unflushed rows on error
+					defer sink.Abort()
+				}
+			default:
 				pipe, err = os.OpenFile(pipeName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
 			}
 			if err != nil {
 				return err
 			}
-			w = pipe
+			if sink == nil {
+				method := params["compression"]
+				if method == "" && cmd == nil {
+					method = env.Compression(pipeName)
+				}
+				if method != "" {
+					if pipe, err = env.Compress(pipe, method); err != nil {
+						return err
+					}
+				}
+				w = pipe
+			}
 		}
 	} else if opt.Exec != metacmd.ExecWatch {
 		params["pager_cmd"] = env.All()["PAGER"]
 	}
+	// browse results only on an interactive terminal
+	if params["format"] == "interactive" && (pipe != nil || h.out != nil || !h.l.Interactive() || opt.Exec == metacmd.ExecWatch) {
+		params["format"] = "aligned"
+	}
+	// display progress when sending results to a file or pipe
+	var p *progress
+	if (pipe != nil || sink != nil || h.out != nil) && env.All()["PROGRESS"] == "on" {
+		p = newProgress(h.IO().Stderr(), progressWrite)
+		defer p.done()
+		w = p.writer(w)
+	}
 	useColumnTypes := drivers.UseColumnTypes(h.u)
-	// wrap query with crosstab
-	resultSet := tblfmt.ResultSet(rows)
+	start = time.Now()
+	// record or replay cached results, and wrap query with crosstab
+	var resultSet tblfmt.ResultSet
+	var recorder *cacheRecorder
+	switch {
+	case cached != nil:
+		resultSet, h.rowCount = &cachedRows{e: cached}, int64(len(cached.rows))
+	case cacheable:
+		recorder = newCacheRecorder(timedRows{rows, &h.timings[phaseFetch], &h.rowCount, p})
+		resultSet = recorder
+	default:
+		resultSet = timedRows{rows, &h.timings[phaseFetch], &h.rowCount, p}
+	}
+	// display the output parameters of stored procedure calls as the final
+	// result set
+	if outParams != nil {
+		out := &outParamRows{ResultSet: resultSet, query: outParams}
+		defer out.Close()
+		resultSet = out
+	}
+	// sort and select the columns of the results client-side
+	if params["sort"] != "" || params["cols"] != "" {
+		if resultSet, err = arrangeResults(resultSet, params["sort"], params["cols"]); err != nil {
+			return err
+		}
+		delete(params, "sort")
+		delete(params, "cols")
+	}
 	if opt.Exec == metacmd.ExecCrosstab {
 		var err error
-		resultSet, err = tblfmt.NewCrosstabView(rows, tblfmt.WithParams(opt.Crosstab...), tblfmt.WithUseColumnTypes(useColumnTypes))
+		resultSet, err = tblfmt.NewCrosstabView(
+			resultSet,
+			tblfmt.WithParams(opt.Crosstab...),
+			tblfmt.WithLowerColumnNames(drivers.LowerColumnNames(h.u)),
+			tblfmt.WithUseColumnTypes(useColumnTypes),
+		)
 		if err != nil {
 			return err
 		}
@@ -1128,35 +1580,120 @@ func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, ty
 	if useColumnTypes {
 		params["use_column_types"] = "true"
 	}
+	// record results written to \tee, unless only counted
+	recordable := params["format"] != "count"
+	var teeRec *teeRecorder
+	if h.tee != nil && recordable {
+		teeRec = &teeRecorder{ResultSet: resultSet}
+		resultSet = teeRec
+	}
+	// record the last result for \browse, \filter, \map, and \store in
+	// interactive sessions
+	var lastRec *lastRecorder
+	if recordable && h.l.Interactive() {
+		lastRec = &lastRecorder{ResultSet: resultSet}
+		resultSet = lastRec
+	}
+	// mark the rows and values changed since the previous execution
+	if opt.Exec == metacmd.ExecWatch && h.watchDiff != nil {
+		resultSet = h.watchDiff.rows(resultSet)
+		params["watch_diff"] = "on"
+	}
 	// encode and handle error conditions
-	switch err := tblfmt.EncodeAll(w, resultSet, params); {
+	encode := func() error {
+		return encodeResultSets(w, resultSet, params)
+	}
+	switch {
+	case sink != nil:
+		encode = func() error {
+			return writeSink(sink, resultSet)
+		}
+	case params["format"] == "chart":
+		encode = func() error {
+			return h.chart(w, resultSet, params)
+		}
+	case params["format"] == "count":
+		encode = func() error {
+			return countResults(w, resultSet)
+		}
+	case params["format"] == "interactive":
+		encode = func() error {
+			sets, err := readResults(resultSet)
+			if err != nil {
+				return err
+			}
+			return h.browse(sets[0], params)
+		}
+	case isPlanResult(typ, rows, params):
+		encode = func() error {
+			return h.encodePlan(w, resultSet, params)
+		}
+	case structuredPager(params):
+		encode = func() error {
+			return h.encodePager(w, resultSet, params)
+		}
+	}
+	switch err := encode(); {
 	case err != nil && cmd != nil && errors.Is(err, syscall.EPIPE):
 		// broken pipe means pager quit before consuming all data, which might be expected
 		return nil
-	case err != nil && h.u.Driver == "sqlserver" && err == tblfmt.ErrResultSetHasNoColumns && strings.HasPrefix(typ, "EXEC"):
-		// sqlserver EXEC statements sometimes do not have results, fake that
-		// it was executed as a exec and not a query
+	case err == tblfmt.ErrResultSetHasNoColumns && drivers.ProcedureResults(h.u) && drivers.IsProcedureCall(typ):
+		// stored procedure calls sometimes do not have results, fake that it
+		// was executed as a exec and not a query
 		fmt.Fprintln(w, typ)
 	case err != nil:
 		return err
 	case params["format"] == "aligned":
+		if params["footer"] != "off" && params["tuples_only"] != "on" {
+			if estimated {
+				fmt.Fprintf(w, text.RowEstimateDesc+"\n", estimate)
+			}
+			if cached != nil {
+				fmt.Fprintln(w, text.CachedDesc)
+			}
+			if h.lastQueryID != "" {
+				fmt.Fprintf(w, text.QueryIDDesc+"\n", h.lastQueryID)
+			}
+			if h.limit != 0 && h.rowCount >= int64(h.limit) {
+				fmt.Fprintf(w, text.ImplicitLimitDesc+"\n", h.limit)
+			}
+		}
 		fmt.Fprintln(w)
 	}
-	if h.timing {
-		d := time.Since(start)
-		format := text.TimingDesc
-		v := []interface{}{float64(d.Microseconds()) / 1000}
-		if d > 1*time.Second {
-			format += " (%v)"
-			v = append(v, d.Round(1*time.Millisecond))
+	if teeRec != nil {
+		if err := h.tee.write(teeRec, params); err != nil {
+			return err
 		}
-		h.Print(format, v...)
 	}
+	if recorder != nil {
+		h.cache.put(key, recorder)
+	}
+	if lastRec != nil && lastRec.e != nil {
+		h.lastResult = lastRec.e
+	}
+	h.timings[phaseRender] = time.Since(start) - h.timings[phaseFetch]
+	if params["format"] == "count" && !h.timing {
+		// counted results always display their timing
+		h.Print(text.TimingDesc, float64(h.timings.total().Microseconds())/1000)
+	}
+	h.printTiming()
 	if pipe != nil {
-		pipe.Close()
+		closeErr := pipe.Close()
 		if cmd != nil {
 			cmd.Wait()
 		}
+		if obj != nil {
+			if closeErr != nil {
+				return closeErr
+			}
+			h.Print(text.CopyToSummary, h.rowCount)
+		}
+	}
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			return err
+		}
+		h.Print(text.CopyToSummary, h.rowCount)
 	}
 	return err
 }
@@ -1189,7 +1726,7 @@ func (h *Handler) execRows(ctx context.Context, w io.Writer, rows *sql.Rows) err
 }
 
 // scan scans a row.
-func (h *Handler) scan(rows *sql.Rows, clen int, tfmt string) ([]string, error) {
+func (h *Handler) scan(rows tblfmt.ResultSet, clen int, tfmt string) ([]string, error) {
 	// scan to []interface{}
 	r := make([]interface{}, clen)
 	for i := range r {
@@ -1198,58 +1735,76 @@ func (h *Handler) scan(rows *sql.Rows, clen int, tfmt string) ([]string, error)
 	if err := rows.Scan(r...); err != nil {
 		return nil, err
 	}
-	// get conversion funcs
-	cb, cm, cs, cd := drivers.ConvertBytes(h.u), drivers.ConvertMap(h.u), drivers.ConvertSlice(h.u), drivers.ConvertDefault(h.u)
 	row := make([]string, clen)
 	for n, z := range r {
-		j := z.(*interface{})
-		switch x := (*j).(type) {
-		case []byte:
-			if x != nil {
-				var err error
-				if row[n], err = cb(x, tfmt); err != nil {
-					return nil, err
-				}
-			}
-		case string:
-			row[n] = x
-		case time.Time:
-			row[n] = x.Format(tfmt)
-		case fmt.Stringer:
-			row[n] = x.String()
-		case map[string]interface{}:
-			if x != nil {
-				var err error
-				if row[n], err = cm(x); err != nil {
-					return nil, err
-				}
-			}
-		case []interface{}:
-			if x != nil {
-				var err error
-				if row[n], err = cs(x); err != nil {
-					return nil, err
-				}
-			}
-		default:
-			if x != nil {
-				var err error
-				if row[n], err = cd(x); err != nil {
-					return nil, err
-				}
-			}
+		var err error
+		if row[n], err = h.convert(*z.(*interface{}), tfmt); err != nil {
+			return nil, err
 		}
 	}
 	return row, nil
 }
 
+// convert converts a scanned value to a string, using the driver's
+// conversion funcs.
+func (h *Handler) convert(v interface{}, tfmt string) (string, error) {
+	switch x := v.(type) {
+	case []byte:
+		if x != nil {
+			return drivers.ConvertBytes(h.u)(x, tfmt)
+		}
+	case string:
+		return x, nil
+	case time.Time:
+		return x.Format(tfmt), nil
+	case fmt.Stringer:
+		return x.String(), nil
+	case map[string]interface{}:
+		if x != nil {
+			return drivers.ConvertMap(h.u)(x)
+		}
+	case []interface{}:
+		if x != nil {
+			return drivers.ConvertSlice(h.u)(x)
+		}
+	default:
+		if x != nil {
+			return drivers.ConvertDefault(h.u)(x)
+		}
+	}
+	return "", nil
+}
+
 // exec does a database exec.
-func (h *Handler) exec(ctx context.Context, w io.Writer, _ metacmd.Option, typ, sqlstr string) error {
-	res, err := h.DB().ExecContext(ctx, sqlstr)
+func (h *Handler) exec(ctx context.Context, w io.Writer, opt metacmd.Option, typ, sqlstr string) error {
+	if table, opts, ok := drivers.ParseCopyIn(h.u, sqlstr); ok {
+		return h.execCopyIn(ctx, w, typ, table, opts)
+	}
+	sqlstr, args, err := h.bindArgs(opt, sqlstr)
+	if err != nil {
+		return err
+	}
+	ctx, resultID := drivers.WithResultID(ctx, h.u)
+	ctx, db, release, err := h.conn(ctx, opt, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if opt.Prepared == "" {
+		if ctx, sqlstr, err = drivers.TagQuery(ctx, h.u, db, env.Get("QUERY_TAG"), sqlstr); err != nil {
+			return err
+		}
+	}
+	start := time.Now()
+	res, err := db.ExecContext(ctx, sqlstr, args...)
+	h.lastQueryID = resultID()
 	if err != nil {
 		_ = env.Set("ROW_COUNT", "0")
 		return err
 	}
+	h.timings[phaseExecute] = time.Since(start)
+	// statements may have changed the results of cached queries
+	h.cache.invalidate(h.u.String())
 	// get affected
 	count, err := drivers.RowsAffected(h.u, res)
 	if err != nil {
@@ -1257,15 +1812,139 @@ func (h *Handler) exec(ctx context.Context, w io.Writer, _ metacmd.Option, typ,
 		return err
 	}
 	// print name
+	start = time.Now()
 	fmt.Fprint(w, typ)
 	// print count
 	if count > 0 {
 		fmt.Fprint(w, " ", count)
 	}
 	fmt.Fprintln(w)
+	h.timings[phaseRender] = time.Since(start)
+	h.printTiming()
+	h.rowCount = count
 	return env.Set("ROW_COUNT", strconv.FormatInt(count, 10))
 }
 
+// Bind binds parameters to the next executed query, as by \bind.
+func (h *Handler) Bind(params []string) {
+	if params == nil {
+		params = []string{}
+	}
+	h.bindParams = params
+}
+
+// bindArgs returns the query to execute, and the values of its placeholders,
+// for the parameters bound to the query.
+func (h *Handler) bindArgs(opt metacmd.Option, sqlstr string) (string, []interface{}, error) {
+	switch {
+	case opt.Prepared != "":
+		args, err := h.preparedArgs(opt)
+		return sqlstr, args, err
+	case opt.Bind == nil:
+		return sqlstr, nil, nil
+	}
+	return drivers.Bind(h.u, sqlstr, opt.Bind)
+}
+
+// conn returns the database to execute a statement on, along with the context
+// to execute the statement with, so that it is canceled on the database server
+// when interrupted. When not in a transaction, a connection is retrieved from
+// the pool, recording the time spent acquiring it, and replaying the session
+// statements on it after reconnecting. Statements executed by
+// \execute are executed on their prepared statement instead. Read only
+// statements are routed to the read replica connection, when connected. The
+// server-side statement timeout of the connection is set when
+// STATEMENT_TIMEOUT is set, and reset when the connection is released. The
+// returned func releases the connection.
+func (h *Handler) conn(ctx context.Context, opt metacmd.Option, readOnly bool) (context.Context, drivers.Conn, func(), error) {
+	if opt.Prepared != "" {
+		s, release, err := h.preparedStmt(ctx, opt.Prepared)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return ctx, stmtConn{s}, release, nil
+	}
+	u, db := h.routed(readOnly)
+	if h.tx != nil {
+		ctx, stop := drivers.WithCancel(ctx, h.u, h.db, h.tx)
+		ctx, reset, err := h.statementTimeout(ctx, opt, h.u, h.tx)
+		if err != nil {
+			stop()
+			return nil, nil, nil, err
+		}
+		return ctx, h.tx, func() {
+			reset()
+			stop()
+		}, nil
+	}
+	start := time.Now()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h.timings[phaseConnect] = time.Since(start)
+	if db == h.db {
+		h.replaySession(ctx, conn)
+	}
+	ctx, stop := drivers.WithCancel(ctx, u, db, conn)
+	ctx, reset, err := h.statementTimeout(ctx, opt, u, conn)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, nil, err
+	}
+	return ctx, conn, func() {
+		reset()
+		stop()
+		conn.Close()
+	}, nil
+}
+
+// statementTimeout sets the server-side statement timeout of the connection
+// to STATEMENT_TIMEOUT, returning a func resetting it to the session default.
+// As the timeout is reset before the connection is released, the other
+// connections of the pool (used by the metadata queries, and the replica)
+// are never left with a timeout. Queries executed by \watch are not timed
+// out.
+func (h *Handler) statementTimeout(ctx context.Context, opt metacmd.Option, u *dburl.URL, conn drivers.Conn) (context.Context, func(), error) {
+	d := env.StatementTimeout()
+	if d == 0 || opt.Exec == metacmd.ExecWatch {
+		return ctx, func() {}, nil
+	}
+	ctx, err := drivers.StatementTimeout(ctx, u, conn, d)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, func() {
+		// the statement's context may be canceled, and the reset fails in
+		// an aborted transaction, which is undone by its rollback
+		_, _ = drivers.StatementTimeout(context.Background(), u, conn, 0)
+	}, nil
+}
+
+// printTiming adds the timings of the current statement to the session's
+// statistics, and prints them when timing is enabled.
+func (h *Handler) printTiming() {
+	h.stats.add(h.timings)
+	if !h.timing {
+		return
+	}
+	d := h.timings.total()
+	format := text.TimingDesc
+	v := []interface{}{float64(d.Microseconds()) / 1000}
+	if d > 1*time.Second {
+		format += " (%v)"
+		v = append(v, d.Round(1*time.Millisecond))
+	}
+	if h.timingVerbose {
+		format += " " + text.TimingPhasesDesc
+		for _, p := range h.timings {
+			v = append(v, float64(p.Microseconds())/1000)
+		}
+	}
+	h.Print(format, v...)
+}
+
 // Begin begins a transaction.
 func (h *Handler) Begin(txOpts *sql.TxOptions) error {
 	return h.BeginTx(context.Background(), txOpts)
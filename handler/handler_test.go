@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	_ "github.com/ildus/usql/drivers/sqlite3"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/rline"
+	"github.com/mattn/go-sqlite3"
+)
+
+// timeouts are the statement timeouts set by the timeouttest driver, with
+// timeoutErr returned when set.
+var (
+	timeouts   []time.Duration
+	timeoutErr error
+)
+
+func init() {
+	sql.Register("timeouttest", &sqlite3.SQLiteDriver{})
+	dburl.Register(dburl.Scheme{Driver: "timeouttest", Generator: dburl.GenOpaque, Opaque: true, Aliases: []string{"tt"}})
+	drivers.Register("timeouttest", drivers.Driver{
+		StatementTimeout: func(ctx context.Context, _ drivers.Conn, d time.Duration) (context.Context, error) {
+			timeouts = append(timeouts, d)
+			return ctx, timeoutErr
+		},
+	})
+}
+
+// newTestHandler creates a non-interactive handler connected to a new sqlite3
+// database, writing its output to out.
+func newTestHandler(t *testing.T, out *bytes.Buffer) *Handler {
+	t.Helper()
+	return newTestHandlerDriver(t, out, "sqlite3")
+}
+
+// newTestHandlerDriver creates a non-interactive handler connected to a new
+// database file using the sqlite3 compatible driver, writing its output to
+// out.
+func newTestHandlerDriver(t *testing.T, out *bytes.Buffer, driver string) *Handler {
+	t.Helper()
+	wd := t.TempDir()
+	u, err := user.Current()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	h := New(&rline.Rline{Out: out, Err: out}, u, wd, true)
+	if err := h.Open(context.Background(), driver+":"+filepath.Join(wd, "test.db")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Cleanup(func() {
+		h.Close()
+	})
+	return h
+}
+
+func TestStatementTimeout(t *testing.T) {
+	h := newTestHandlerDriver(t, new(bytes.Buffer), "timeouttest")
+	ctx := context.Background()
+	defer env.Set("STATEMENT_TIMEOUT", "")
+	tests := []struct {
+		timeout string
+		tx      bool
+		err     error
+		exp     []time.Duration
+	}{
+		{"", false, nil, nil},
+		// the timeout is reset before the connection is released
+		{"2s", false, nil, []time.Duration{2 * time.Second, 0}},
+		{"2s", true, nil, []time.Duration{2 * time.Second, 0}},
+		{"2s", false, errors.New("timeout not set"), []time.Duration{2 * time.Second}},
+		{"0", false, nil, nil},
+	}
+	for i, test := range tests {
+		if err := env.Set("STATEMENT_TIMEOUT", test.timeout); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if test.tx {
+			if err := h.BeginTx(ctx, nil); err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+		}
+		timeouts, timeoutErr = nil, test.err
+		err := h.Execute(ctx, new(bytes.Buffer), metacmd.Option{}, "SELECT", "SELECT 1", false)
+		if test.tx {
+			if err := h.Rollback(); err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+		}
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+		if !reflect.DeepEqual(timeouts, test.exp) {
+			t.Errorf("test %d expected timeouts %v, got: %v", i, test.exp, timeouts)
+		}
+	}
+	// queries executed by \watch are not timed out
+	timeouts, timeoutErr = nil, nil
+	if err := env.Set("STATEMENT_TIMEOUT", "2s"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, reset, err := h.statementTimeout(ctx, metacmd.Option{Exec: metacmd.ExecWatch}, h.u, h.db)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	reset()
+	if len(timeouts) != 0 {
+		t.Errorf("expected no timeouts, got: %v", timeouts)
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	h := newTestHandler(t, new(bytes.Buffer))
+	path := filepath.Join(t.TempDir(), "other.db")
+	tests := []struct {
+		urlstr string
+		err    bool
+	}{
+		{"sqlite3:" + path, false},
+		// paths on disk (created by the previous test) are opened as with \connect
+		{path, false},
+		{filepath.Join(t.TempDir(), "missing", "other.db"), true},
+	}
+	for i, test := range tests {
+		u, db, err := h.OpenDB(context.Background(), test.urlstr)
+		switch {
+		case test.err && err == nil:
+			db.Close()
+			t.Errorf("test %d expected error, got nil", i)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if u.Driver != "sqlite3" {
+			t.Errorf("test %d expected driver sqlite3, got: %q", i, u.Driver)
+		}
+		if db == h.db {
+			t.Errorf("test %d expected a separate connection", i)
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+)
+
+// limitWords are the words of a statement already limiting (or redirecting)
+// its rows, or conflicting with a limit clause.
+var limitWords = map[string]bool{
+	"LIMIT":  true,
+	"TOP":    true,
+	"FETCH":  true,
+	"OFFSET": true,
+	"INTO":   true,
+	"FOR":    true,
+}
+
+// implicitLimit returns the statement limited to the rows set by the
+// IMPLICIT_LIMIT variable, and the limit, when the statement is a bare SELECT
+// entered interactively without a limit of its own, with its results
+// displayed on the terminal.
+func (h *Handler) implicitLimit(opt metacmd.Option, prefix, sqlstr string, qtyp bool) (string, int) {
+	n := env.ImplicitLimit()
+	switch {
+	case n == 0,
+		!qtyp || prefix != "SELECT",
+		!h.l.Interactive(),
+		opt.Exec != metacmd.ExecNone && opt.Exec != metacmd.ExecOnly && opt.Exec != metacmd.ExecCrosstab,
+		opt.Params["pipe"] != "" || h.out != nil,
+		opt.Params["format"] == "count" || opt.Params["format"] == "" && env.Pall()["format"] == "count",
+		hasLimit(sqlstr):
+		return sqlstr, 0
+	}
+	trimmed := strings.TrimRight(sqlstr, "; \t\r\n")
+	limited := drivers.Limit(h.u, trimmed, n)
+	if limited == trimmed {
+		// the driver could not limit the statement
+		return sqlstr, 0
+	}
+	return limited, n
+}
+
+// hasLimit returns true when sqlstr contains any of the limit words, outside
+// of quoted strings, quoted identifiers, and comments.
+func hasLimit(sqlstr string) bool {
+	r := []rune(sqlstr)
+	for i := 0; i < len(r); i++ {
+		switch c, next := r[i], grab(r, i+1, len(r)); {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			if c == '[' {
+				c = ']'
+			}
+			for i++; i < len(r) && r[i] != c; i++ {
+			}
+		case c == '-' && next == '-':
+			for ; i < len(r) && r[i] != '\n'; i++ {
+			}
+		case c == '/' && next == '*':
+			for i += 2; i < len(r) && (r[i] != '*' || grab(r, i+1, len(r)) != '/'); i++ {
+			}
+			i++
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(r) && (unicode.IsLetter(r[end]) || unicode.IsDigit(r[end]) || r[end] == '_' || r[end] == '$') {
+				end++
+			}
+			if limitWords[strings.ToUpper(string(r[i:end]))] {
+				return true
+			}
+			i = end - 1
+		}
+	}
+	return false
+}
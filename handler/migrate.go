@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/metadata"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+const (
+	// migrateDirDefault is the default directory of the migration files.
+	migrateDirDefault = "migrations"
+	// migrateTableDefault is the default name of the table tracking the
+	// applied migrations.
+	migrateTableDefault = "schema_version"
+)
+
+// migrationRE matches the names of migration files: VERSION_NAME.up.sql and
+// VERSION_NAME.down.sql, or VERSION_NAME.sql for migrations that can not be
+// reverted.
+var migrationRE = regexp.MustCompile(`^(\d+)_(.+?)(?:\.(up|down))?\.sql$`)
+
+// migration is a migration in the migration directory.
+type migration struct {
+	version  int64
+	name     string
+	up, down string
+	// checksum is the SHA-256 checksum of the up file.
+	checksum string
+}
+
+// appliedMigration is a migration recorded in the tracking table.
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt string
+}
+
+// Migrate applies (up), reverts (down), or lists (status) the migrations in
+// the migration directory, recording the applied migrations in the tracking
+// table. Each migration is applied or reverted in its own transaction.
+func (h *Handler) Migrate(ctx context.Context, action string, opts metacmd.MigrateOptions) error {
+	switch {
+	case h.db == nil:
+		return text.ErrNotConnected
+	case h.tx != nil:
+		return text.ErrPreviousTransactionExists
+	case action != "up" && action != "down" && action != "status":
+		return fmt.Errorf(text.MigrateInvalidAction, action)
+	}
+	dir, table := opts.Dir, opts.Table
+	if dir == "" {
+		dir = migrateDirDefault
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(h.wd, dir)
+	}
+	if table == "" {
+		table = migrateTableDefault
+	}
+	migrations, err := loadMigrations(dir)
+	if err != nil {
+		return err
+	}
+	applied, err := h.appliedMigrations(ctx, table, action != "status" && !opts.DryRun)
+	if err != nil {
+		return err
+	}
+	out := h.l.Stdout()
+	if h.out != nil {
+		out = h.out
+	}
+	switch action {
+	case "up":
+		return h.migrateUp(ctx, out, table, migrations, applied, opts)
+	case "down":
+		return h.migrateDown(ctx, out, table, migrations, applied, opts)
+	}
+	return migrateStatus(out, migrations, applied)
+}
+
+// loadMigrations reads the migrations in dir, ordered by version.
+func loadMigrations(dir string) ([]*migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]*migration)
+	for _, entry := range entries {
+		v := migrationRE.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || v == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(v[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(text.MigrateBadVersion, entry.Name())
+		}
+		mig, ok := m[version]
+		switch {
+		case !ok:
+			mig = &migration{version: version, name: v[2]}
+			m[version] = mig
+		case mig.name != v[2]:
+			return nil, fmt.Errorf(text.MigrateDuplicate, version)
+		}
+		path := filepath.Join(dir, entry.Name())
+		if v[3] == "down" {
+			if mig.down != "" {
+				return nil, fmt.Errorf(text.MigrateDuplicate, version)
+			}
+			mig.down = path
+			continue
+		}
+		if mig.up != "" {
+			return nil, fmt.Errorf(text.MigrateDuplicate, version)
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(buf)
+		mig.up, mig.checksum = path, hex.EncodeToString(sum[:])
+	}
+	migrations := make([]*migration, 0, len(m))
+	for _, mig := range m {
+		if mig.up == "" {
+			return nil, fmt.Errorf(text.MigrateNoUp, mig.version, mig.name)
+		}
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// appliedMigrations returns the migrations recorded in the tracking table,
+// ordered by version. When the tracking table does not exist, it is created
+// when create is true.
+func (h *Handler) appliedMigrations(ctx context.Context, table string, create bool) ([]appliedMigration, error) {
+	exists, err := h.tableExists(ctx, table)
+	switch {
+	case err != nil:
+		return nil, err
+	case !exists && !create:
+		return nil, nil
+	case !exists:
+		if _, err := h.db.ExecContext(ctx, `CREATE TABLE `+table+` (
+  version NUMERIC(19) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  checksum VARCHAR(64) NOT NULL,
+  applied_at VARCHAR(32) NOT NULL
+)`); err != nil {
+			return nil, drivers.WrapErr(h.u.Driver, err)
+		}
+		return nil, nil
+	}
+	rows, err := h.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+table+` ORDER BY version`)
+	if err != nil {
+		return nil, drivers.WrapErr(h.u.Driver, err)
+	}
+	defer rows.Close()
+	var applied []appliedMigration
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, drivers.WrapErr(h.u.Driver, err)
+		}
+		applied = append(applied, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, drivers.WrapErr(h.u.Driver, err)
+	}
+	return applied, nil
+}
+
+// tableExists returns whether the table, optionally qualified with its
+// schema, exists, using the driver's metadata reader. For drivers without a
+// table reader, the table is assumed to exist when it can be queried.
+func (h *Handler) tableExists(ctx context.Context, table string) (bool, error) {
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i != -1 {
+		schema, name = table[:i], table[i+1:]
+	}
+	r, err := drivers.NewMetadataReader(ctx, h.u, h.db, h.l.Stdout())
+	if tr, ok := r.(metadata.TableReader); err == nil && ok {
+		res, err := tr.Tables(metadata.Filter{Schema: schema, OnlyVisible: schema == ""})
+		if err != nil {
+			return false, err
+		}
+		defer res.Close()
+		for res.Next() {
+			t := res.Get()
+			if strings.EqualFold(t.Name, name) && (schema == "" || strings.EqualFold(t.Schema, schema)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	rows, err := h.db.QueryContext(ctx, `SELECT 1 FROM `+table+` WHERE 1=0`)
+	if err != nil {
+		return false, nil
+	}
+	rows.Close()
+	return true, nil
+}
+
+// migrateUp applies the pending migrations in order, after verifying the
+// checksums of the applied migrations.
+func (h *Handler) migrateUp(ctx context.Context, w io.Writer, table string, migrations []*migration, applied []appliedMigration, opts metacmd.MigrateOptions) error {
+	done := make(map[int64]appliedMigration, len(applied))
+	for _, a := range applied {
+		done[a.version] = a
+	}
+	var pending []*migration
+	for _, mig := range migrations {
+		a, ok := done[mig.version]
+		switch {
+		case !ok:
+			pending = append(pending, mig)
+		case a.checksum != mig.checksum:
+			return fmt.Errorf(text.MigrateChanged, mig.version, mig.name)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Fprintln(w, text.MigrateUpToDate)
+		return nil
+	}
+	if opts.Steps > 0 && opts.Steps < len(pending) {
+		pending = pending[:opts.Steps]
+	}
+	for _, mig := range pending {
+		record := fmt.Sprintf(
+			`INSERT INTO %s (version, name, checksum, applied_at) VALUES (%d, %s, %s, %s)`,
+			table, mig.version,
+			drivers.QuoteLiteral(h.u, mig.name),
+			drivers.QuoteLiteral(h.u, mig.checksum),
+			drivers.QuoteLiteral(h.u, time.Now().UTC().Format(time.RFC3339)),
+		)
+		if err := h.runMigration(ctx, w, mig.up, record, opts.DryRun); err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			fmt.Fprintf(w, text.MigrateApplied, mig.version, mig.name)
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// migrateDown reverts the last applied migrations in reverse order.
+func (h *Handler) migrateDown(ctx context.Context, w io.Writer, table string, migrations []*migration, applied []appliedMigration, opts metacmd.MigrateOptions) error {
+	if len(applied) == 0 {
+		fmt.Fprintln(w, text.MigrateNoneApplied)
+		return nil
+	}
+	files := make(map[int64]*migration, len(migrations))
+	for _, mig := range migrations {
+		files[mig.version] = mig
+	}
+	// check all migrations can be reverted before reverting any
+	var revert []appliedMigration
+	for i := len(applied) - 1; i >= 0 && len(revert) < max(opts.Steps, 1); i-- {
+		a := applied[i]
+		if mig, ok := files[a.version]; !ok || mig.down == "" {
+			return fmt.Errorf(text.MigrateNoDown, a.version, a.name)
+		}
+		revert = append(revert, a)
+	}
+	for _, a := range revert {
+		record := fmt.Sprintf(`DELETE FROM %s WHERE version = %d`, table, a.version)
+		if err := h.runMigration(ctx, w, files[a.version].down, record, opts.DryRun); err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			fmt.Fprintf(w, text.MigrateReverted, a.version, a.name)
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// runMigration executes the statements of the migration file at path, and
+// the statement recording it in the tracking table, in a transaction. When
+// dryRun is true, the statements are written to w instead.
+func (h *Handler) runMigration(ctx context.Context, w io.Writer, path, record string, dryRun bool) error {
+	stmts, err := h.readScript(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "-- %s\n", filepath.Base(path))
+		for _, s := range stmts {
+			fmt.Fprintln(w, s)
+		}
+		fmt.Fprintln(w, record+";")
+		return nil
+	}
+	if err := h.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	for _, s := range stmts {
+		if err := h.Execute(ctx, io.Discard, metacmd.Option{}, stmt.FindPrefix(s, true, true, true), s, false); err != nil {
+			_ = h.Rollback()
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	if _, err := h.tx.ExecContext(ctx, record); err != nil {
+		_ = h.Rollback()
+		return drivers.WrapErr(h.u.Driver, err)
+	}
+	return h.Commit()
+}
+
+// migrateStatus writes the migrations in the migration directory and the
+// tracking table, with their status: applied, pending, changed (when the up
+// file changed after it was applied), or missing (when the files of an
+// applied migration were removed).
+func migrateStatus(w io.Writer, migrations []*migration, applied []appliedMigration) error {
+	files := make(map[int64]*migration, len(migrations))
+	for _, mig := range migrations {
+		files[mig.version] = mig
+	}
+	e := &cacheEntry{
+		cols: []string{"Version", "Name", "Status", "Applied at"},
+	}
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.version] = true
+		status := "applied"
+		switch mig, ok := files[a.version]; {
+		case !ok:
+			status = "missing"
+		case mig.checksum != a.checksum:
+			status = "changed"
+		}
+		e.rows = append(e.rows, []interface{}{a.version, a.name, status, a.appliedAt})
+	}
+	for _, mig := range migrations {
+		if !done[mig.version] {
+			e.rows = append(e.rows, []interface{}{mig.version, mig.name, "pending", nil})
+		}
+	}
+	sort.SliceStable(e.rows, func(i, j int) bool {
+		return e.rows[i][0].(int64) < e.rows[j][0].(int64)
+	})
+	params := env.Pall()
+	params["title"] = "List of migrations"
+	return env.EncodeAll(w, &cachedRows{e: e}, params)
+}
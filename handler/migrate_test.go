@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ildus/usql/metacmd"
+)
+
+func TestMigrateTrackingTable(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	h := newTestHandler(t, &out)
+	dir := filepath.Join(h.wd, "migrations")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "1_init.up.sql"), []byte("CREATE TABLE a (id INTEGER);\n"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exists := func(table string) bool {
+		ok, err := h.tableExists(ctx, table)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return ok
+	}
+	// status does not create the tracking table
+	if err := h.Migrate(ctx, "status", metacmd.MigrateOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exists(migrateTableDefault) {
+		t.Fatalf("expected %s to not exist", migrateTableDefault)
+	}
+	if err := h.Migrate(ctx, "up", metacmd.MigrateOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !exists(migrateTableDefault) || !exists("a") {
+		t.Fatalf("expected %s and a to exist", migrateTableDefault)
+	}
+	applied, err := h.appliedMigrations(ctx, migrateTableDefault, false)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(applied) != 1 || applied[0].version != 1 {
+		t.Errorf("expected migration 1 to be applied, got: %v", applied)
+	}
+	// errors reading an existing tracking table are returned, instead of
+	// creating the table
+	if _, err := h.db.ExecContext(ctx, "CREATE TABLE other_version (id INTEGER)"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, create := range []bool{false, true} {
+		if _, err := h.appliedMigrations(ctx, "other_version", create); err == nil {
+			t.Errorf("create %t expected error, got nil", create)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ildus/usql/sinks"
+	"github.com/ildus/usql/text"
+)
+
+// isObjectURL returns true when name is the url of a registered object
+// storage service.
+func isObjectURL(name string) bool {
+	s, ok := sinks.Lookup(name)
+	return ok && s.Upload != nil
+}
+
+// objectWriter streams writes to an object in a object storage service.
+type objectWriter struct {
+	pw     *io.PipeWriter
+	cancel context.CancelFunc
+	done   chan error
+	closed bool
+}
+
+// openObject opens an object storage url for writing, uploading written data
+// in the background until the writer is closed.
+//
+// Urls are SCHEME://BUCKET/KEY, such as s3://BUCKET/KEY, gs://BUCKET/OBJECT,
+// or azblob://CONTAINER/BLOB, for the object storage services registered in
+// the sinks package.
+func openObject(ctx context.Context, urlstr string) (*objectWriter, error) {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := sinks.Lookup(urlstr)
+	if !ok || s.Upload == nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf(text.InvalidObjectURL, urlstr)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	w := &objectWriter{
+		pw:     pw,
+		cancel: cancel,
+		done:   make(chan error, 1),
+	}
+	go func() {
+		err := s.Upload(ctx, u, pr)
+		// unblock writes when the upload fails
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w, nil
+}
+
+// Write satisfies the io.Writer interface.
+func (w *objectWriter) Write(buf []byte) (int, error) {
+	return w.pw.Write(buf)
+}
+
+// Close completes the upload, returning any upload error.
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.pw.Close()
+	err := <-w.done
+	w.cancel()
+	return err
+}
+
+// abort aborts the upload, discarding any written data, if the writer has
+// not been closed.
+func (w *objectWriter) abort() {
+	if w.closed {
+		return
+	}
+	w.closed = true
+	w.cancel()
+	w.pw.CloseWithError(context.Canceled)
+	<-w.done
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+	"github.com/nathan-fiscaletti/consolesize-go"
+	"github.com/xo/tblfmt"
+)
+
+// structuredPager returns true when results displayed with the params are
+// sent to the pager in a structured format (pager_format).
+func structuredPager(params map[string]string) bool {
+	switch {
+	case params["pager_format"] == "", params["pager_format"] == "text",
+		params["pager_cmd"] == "", params["pager"] == "off":
+		return false
+	}
+	return params["format"] == "aligned" || params["format"] == "wrapped"
+}
+
+// encodePager writes the results to the pager in the structured pager
+// format, passing the format and the column metadata to the pager in the
+// USQL_PAGER_FORMAT and USQL_PAGER_COLUMNS environment variables. Results
+// that fit the terminal, or that have more than one result set, are written
+// as text.
+func (h *Handler) encodePager(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	sets, err := readResults(resultSet)
+	if err != nil {
+		return err
+	}
+	if len(sets) != 1 || !usePager(sets[0], params) {
+		for i, e := range sets {
+			if i != 0 {
+				fmt.Fprintln(w)
+			}
+			if err := env.EncodeAll(w, &cachedRows{e: e}, params); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	e, format := sets[0], params["pager_format"]
+	buf, err := json.Marshal(env.Columns(e.cols, e.types))
+	if err != nil {
+		return err
+	}
+	// encode params
+	p := make(map[string]string, len(params))
+	for k, v := range params {
+		p[k] = v
+	}
+	p["format"], p["expanded"] = format, "off"
+	if format == "tsv" {
+		p["format"], p["csv_fieldsep"] = "csv", "\t"
+	}
+	delete(p, "pager_cmd")
+	// start pager
+	shell, param := env.Getshell()
+	if shell == "" {
+		return text.ErrNoShellAvailable
+	}
+	prefix := text.CommandUpper() + "_PAGER_"
+	cmd := exec.Command(shell, param, params["pager_cmd"])
+	cmd.Env = append(os.Environ(), prefix+"FORMAT="+format, prefix+"COLUMNS="+string(buf))
+	cmd.Stdout, cmd.Stderr = w, w
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = env.EncodeAll(in, &cachedRows{e: e}, p)
+	in.Close()
+	if werr := cmd.Wait(); err == nil {
+		err = werr
+	}
+	if errors.Is(err, syscall.EPIPE) {
+		// pager quit before consuming all data
+		return nil
+	}
+	return err
+}
+
+// usePager returns true when the result does not fit the terminal, or the
+// pager is always used.
+func usePager(e *cacheEntry, params map[string]string) bool {
+	if params["pager"] == "always" {
+		return true
+	}
+	_, height := consolesize.GetConsoleSize()
+	if n, _ := strconv.Atoi(params["pager_min_lines"]); n != 0 {
+		height = n
+	}
+	// header, divider, and footer lines
+	return len(e.rows)+3 > height
+}
+
+// readResults reads all result sets of the result set.
+func readResults(resultSet tblfmt.ResultSet) ([]*cacheEntry, error) {
+	var sets []*cacheEntry
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return nil, err
+		}
+		e := &cacheEntry{cols: cols}
+		if z, ok := resultSet.(interface {
+			ColumnTypes() ([]*sql.ColumnType, error)
+		}); ok {
+			e.types, _ = z.ColumnTypes()
+		}
+		for resultSet.Next() {
+			row, v := make([]interface{}, len(cols)), make([]interface{}, len(cols))
+			for i := range v {
+				v[i] = &row[i]
+			}
+			if err := resultSet.Scan(v...); err != nil {
+				return nil, err
+			}
+			e.rows = append(e.rows, row)
+		}
+		if err := resultSet.Err(); err != nil {
+			return nil, err
+		}
+		sets = append(sets, e)
+		if !resultSet.NextResultSet() {
+			return sets, nil
+		}
+	}
+}
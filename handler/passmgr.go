@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ildus/usql/dburl/passfile"
+	"github.com/ildus/usql/dburl/passfile/keychain"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+)
+
+// passphraseEnv returns the name of the environment variable containing the
+// passfile's master passphrase (ie, $USQLPASS_PASSPHRASE).
+func passphraseEnv() string {
+	return strings.ToUpper(text.PassfileName) + "_PASSPHRASE"
+}
+
+// passfilePassphrase returns the master passphrase for an encrypted passfile,
+// from $USQLPASS_PASSPHRASE, or by prompting the user once per session.
+func (h *Handler) passfilePassphrase(file string) (string, error) {
+	if h.passphrase != "" {
+		return h.passphrase, nil
+	}
+	if s := os.Getenv(passphraseEnv()); s != "" {
+		return s, nil
+	}
+	if !h.l.Interactive() {
+		return "", passfile.ErrPassphraseRequired
+	}
+	s, err := h.l.Password(fmt.Sprintf(text.EnterPassphrase, file))
+	if err != nil {
+		return "", err
+	}
+	h.passphrase = s
+	return s, nil
+}
+
+// passfileLocked returns true when file is encrypted, and its passphrase is
+// not yet known.
+func (h *Handler) passfileLocked(file string) bool {
+	if h.passphrase != "" || os.Getenv(passphraseEnv()) != "" {
+		return false
+	}
+	buf, err := os.ReadFile(file)
+	return err == nil && passfile.IsEncrypted(buf)
+}
+
+// readPassfile reads the contents and entries of the passfile, returning the
+// passfile's path and whether it is encrypted.
+func (h *Handler) readPassfile() (string, []byte, []passfile.Entry, bool, error) {
+	file := passfile.Path(h.user.HomeDir, text.PassfileName)
+	buf, encrypted, err := passfile.ReadFile(file)
+	if err != nil {
+		h.checkPassphrase(err)
+		return "", nil, nil, false, err
+	}
+	entries, err := passfile.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return "", nil, nil, false, &passfile.FileError{File: file, Err: err}
+	}
+	return file, buf, entries, encrypted, nil
+}
+
+// writePassfile updates the entries of the passfile, preserving its comments
+// and unchanged lines, and keeping the passfile encrypted when it was.
+func (h *Handler) writePassfile(file string, buf []byte, entries []passfile.Entry, encrypted bool) error {
+	var passphrase string
+	if encrypted {
+		var err error
+		if passphrase, err = h.passfilePassphrase(file); err != nil {
+			return err
+		}
+	}
+	buf, err := passfile.Update(buf, entries)
+	if err != nil {
+		return err
+	}
+	return passfile.WriteFile(file, buf, passphrase)
+}
+
+// checkPassphrase forgets the prompted passphrase when err is an incorrect
+// passphrase error, so that the user is prompted again.
+func (h *Handler) checkPassphrase(err error) {
+	if errors.Is(err, passfile.ErrIncorrectPassphrase) {
+		h.passphrase = ""
+	}
+}
+
+// PassAdd adds the password entry to the passfile, or the OS keychain,
+// replacing any existing entry for the same protocol, host, port, database,
+// and username. Prompts for the password when not contained in the entry.
+func (h *Handler) PassAdd(spec string, useKeychain bool) error {
+	entry, ok, err := parsePassEntry(spec)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if !h.l.Interactive() {
+			return text.ErrNotInteractive
+		}
+		if entry.Password, err = h.l.Password(text.EnterPassword); err != nil {
+			return err
+		}
+		if entry.Password == "" {
+			return fmt.Errorf(text.PassInvalidEntry, spec)
+		}
+	}
+	if useKeychain {
+		if err := keychain.Add(entry); err != nil {
+			return err
+		}
+		// use the keychain entries for the rest of the session
+		return env.Set("KEYCHAIN", "on")
+	}
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	file, buf, entries, encrypted, err := h.readPassfile()
+	if err != nil {
+		return err
+	}
+	key, replaced := keychain.Key(entry), false
+	for i := range entries {
+		if keychain.Key(entries[i]) == key {
+			entries[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	return h.writePassfile(file, buf, entries, encrypted)
+}
+
+// PassRemove removes the password entry from the passfile, or the OS
+// keychain.
+func (h *Handler) PassRemove(spec string, useKeychain bool) error {
+	entry, _, err := parsePassEntry(spec)
+	if err != nil {
+		return err
+	}
+	if useKeychain {
+		if err := keychain.Remove(entry); errors.Is(err, keychain.ErrNotFound) {
+			return fmt.Errorf(text.PassNotFound, spec)
+		} else if err != nil {
+			return err
+		}
+		return nil
+	}
+	file, buf, entries, encrypted, err := h.readPassfile()
+	if err != nil {
+		return err
+	}
+	key, n := keychain.Key(entry), len(entries)
+	for i := 0; i < len(entries); i++ {
+		if keychain.Key(entries[i]) == key {
+			entries = append(entries[:i], entries[i+1:]...)
+			i--
+		}
+	}
+	if len(entries) == n {
+		return fmt.Errorf(text.PassNotFound, spec)
+	}
+	return h.writePassfile(file, buf, entries, encrypted)
+}
+
+// PassList writes the password entries of the passfile and the OS keychain,
+// masking the passwords.
+func (h *Handler) PassList(w io.Writer) error {
+	_, _, entries, encrypted, err := h.readPassfile()
+	if err != nil {
+		return err
+	}
+	store := "passfile"
+	if encrypted {
+		store += " (encrypted)"
+	}
+	e := &cacheEntry{
+		cols: []string{"Protocol", "Host", "Port", "Database", "Username", "Password", "Store"},
+	}
+	add := func(entries []passfile.Entry, store string) {
+		for _, entry := range entries {
+			e.rows = append(e.rows, []interface{}{
+				entry.Protocol,
+				entry.Host,
+				entry.Port,
+				entry.DBName,
+				entry.Username,
+				strings.Repeat("*", 8),
+				store,
+			})
+		}
+	}
+	add(entries, store)
+	switch entries, err := keychain.Entries(); {
+	case errors.Is(err, keychain.ErrNotAvailable):
+	case err != nil:
+		return err
+	default:
+		add(entries, "keychain")
+	}
+	params := env.Pall()
+	params["title"] = "List of password entries"
+	return env.EncodeAll(w, &cachedRows{e: e}, params)
+}
+
+// PassEncrypt encrypts the passfile with a master passphrase, or decrypts the
+// passfile. Encrypting an encrypted passfile changes its passphrase.
+func (h *Handler) PassEncrypt(encrypt bool) error {
+	file, buf, _, encrypted, err := h.readPassfile()
+	switch {
+	case err != nil:
+		return err
+	case !encrypt && !encrypted:
+		return nil
+	case !encrypt:
+		return passfile.WriteFile(file, buf, "")
+	}
+	passphrase := os.Getenv(passphraseEnv())
+	if passphrase == "" {
+		if !h.l.Interactive() {
+			return text.ErrNotInteractive
+		}
+		if passphrase, err = h.l.Password(fmt.Sprintf(text.EnterPassphrase, file)); err != nil {
+			return err
+		}
+		confirm, err := h.l.Password(text.ConfirmPassphrase)
+		switch {
+		case err != nil:
+			return err
+		case passphrase != confirm:
+			return text.ErrPassphraseMismatch
+		case passphrase == "":
+			return passfile.ErrPassphraseRequired
+		}
+	}
+	if err := passfile.WriteFile(file, buf, passphrase); err != nil {
+		return err
+	}
+	h.passphrase = passphrase
+	return nil
+}
+
+// parsePassEntry parses a PROTOCOL:HOST:PORT:DBNAME:USERNAME[:PASSWORD]
+// password entry, returning true when the entry contains the password.
+func parsePassEntry(spec string) (passfile.Entry, bool, error) {
+	v := strings.Split(spec, ":")
+	if len(v) != 5 && len(v) != 6 {
+		return passfile.Entry{}, false, fmt.Errorf(text.PassInvalidEntry, spec)
+	}
+	for _, s := range v {
+		if s == "" {
+			return passfile.Entry{}, false, fmt.Errorf(text.PassInvalidEntry, spec)
+		}
+	}
+	return passfile.NewEntry(v), len(v) == 6, nil
+}
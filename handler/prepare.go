@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+// preparedStmt is a statement prepared by \prepare.
+type preparedStmt struct {
+	stmt   *sql.Stmt
+	prefix string
+	query  string
+	qtyp   bool
+	// pos are the parameter numbers of the driver's placeholders.
+	pos []int
+	// params is the number of positional parameters of the query.
+	params  int
+	created time.Time
+}
+
+// Prepare prepares a statement on the database, registering it with the
+// name for execution with \execute.
+func (h *Handler) Prepare(ctx context.Context, name, sqlstr string) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	if _, ok := h.prepared[name]; ok {
+		return fmt.Errorf(text.PreparedExists, name)
+	}
+	prefix, sqlstr, qtyp, err := drivers.Process(h.u, stmt.FindPrefix(sqlstr, true, true, true), sqlstr)
+	if err != nil {
+		return drivers.WrapErr(h.u.Driver, err)
+	}
+	query, pos := drivers.Placeholders(h.u, sqlstr)
+	s, err := h.db.PrepareContext(ctx, query)
+	if err != nil {
+		return drivers.WrapErr(h.u.Driver, err)
+	}
+	if h.prepared == nil {
+		h.prepared = make(map[string]*preparedStmt)
+	}
+	h.prepared[name] = &preparedStmt{
+		stmt:    s,
+		prefix:  prefix,
+		query:   sqlstr,
+		qtyp:    qtyp,
+		pos:     pos,
+		params:  drivers.NumParams(sqlstr),
+		created: time.Now(),
+	}
+	h.Print("PREPARE")
+	return nil
+}
+
+// ExecutePrepared executes the prepared statement with the parameters,
+// writing the results to w.
+func (h *Handler) ExecutePrepared(ctx context.Context, w io.Writer, name string, params []string) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	p, ok := h.prepared[name]
+	if !ok {
+		return fmt.Errorf(text.PreparedNotFound, name)
+	}
+	if params == nil {
+		params = []string{}
+	}
+	start := time.Now()
+	h.timings, h.rowCount = timings{}, 0
+	opt := metacmd.Option{Exec: metacmd.ExecOnly, Prepared: name, Bind: params}
+	err := drivers.WrapErr(h.u.Driver, h.execSingle(ctx, w, opt, p.prefix, p.query, p.qtyp))
+	h.audit(start, p.query, err)
+	return err
+}
+
+// Deallocate closes the prepared statement, or all prepared statements when
+// name is all.
+func (h *Handler) Deallocate(name string) error {
+	if name == "all" {
+		h.deallocateAll()
+		h.Print("DEALLOCATE ALL")
+		return nil
+	}
+	p, ok := h.prepared[name]
+	if !ok {
+		return fmt.Errorf(text.PreparedNotFound, name)
+	}
+	delete(h.prepared, name)
+	if err := p.stmt.Close(); err != nil {
+		return drivers.WrapErr(h.u.Driver, err)
+	}
+	h.Print("DEALLOCATE")
+	return nil
+}
+
+// deallocateAll closes all prepared statements.
+func (h *Handler) deallocateAll() {
+	for _, p := range h.prepared {
+		p.stmt.Close()
+	}
+	h.prepared = nil
+}
+
+// ListPrepared writes the prepared statements.
+func (h *Handler) ListPrepared(w io.Writer) error {
+	names := make([]string, 0, len(h.prepared))
+	for name := range h.prepared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	e := &cacheEntry{
+		cols: []string{"Name", "Statement", "Parameters", "Prepared"},
+	}
+	for _, name := range names {
+		p := h.prepared[name]
+		e.rows = append(e.rows, []interface{}{name, p.query, int64(p.params), p.created.Format(time.DateTime)})
+	}
+	params := env.Pall()
+	params["title"] = "List of prepared statements"
+	return env.EncodeAll(w, &cachedRows{e: e}, params)
+}
+
+// preparedArgs returns the values of the prepared statement's placeholders
+// for the parameters bound by \execute.
+func (h *Handler) preparedArgs(opt metacmd.Option) ([]interface{}, error) {
+	p, ok := h.prepared[opt.Prepared]
+	if !ok {
+		return nil, fmt.Errorf(text.PreparedNotFound, opt.Prepared)
+	}
+	return drivers.BindArgs(p.pos, opt.Bind)
+}
+
+// preparedStmt returns the prepared statement executed by \execute, for use
+// in the current transaction, if any. The returned func releases the
+// statement.
+func (h *Handler) preparedStmt(ctx context.Context, name string) (*sql.Stmt, func(), error) {
+	p, ok := h.prepared[name]
+	if !ok {
+		return nil, nil, fmt.Errorf(text.PreparedNotFound, name)
+	}
+	if h.tx == nil {
+		return p.stmt, func() {}, nil
+	}
+	s := h.tx.StmtContext(ctx, p.stmt)
+	return s, func() { s.Close() }, nil
+}
+
+// stmtConn wraps a prepared statement as a connection, executing the
+// prepared statement in place of the passed query.
+type stmtConn struct {
+	s *sql.Stmt
+}
+
+// ExecContext satisfies the drivers.Conn interface.
+func (c stmtConn) ExecContext(ctx context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	return c.s.ExecContext(ctx, args...)
+}
+
+// QueryContext satisfies the drivers.Conn interface.
+func (c stmtConn) QueryContext(ctx context.Context, _ string, args ...interface{}) (*sql.Rows, error) {
+	return c.s.QueryContext(ctx, args...)
+}
+
+// QueryRowContext satisfies the drivers.Conn interface.
+func (c stmtConn) QueryRowContext(ctx context.Context, _ string, args ...interface{}) *sql.Row {
+	return c.s.QueryRowContext(ctx, args...)
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ildus/usql/text"
+)
+
+// progressInterval is the minimum interval between progress line updates.
+const progressInterval = 250 * time.Millisecond
+
+// progressKind is the kind of operation a progress tracks.
+type progressKind int
+
+// Progress kinds.
+const (
+	// progressWrite tracks the rows fetched and the bytes written.
+	progressWrite progressKind = iota
+	// progressRead tracks the bytes read.
+	progressRead
+	// progressCopy tracks the elapsed time, and the rows copied once done.
+	progressCopy
+)
+
+// progress tracks and renders the progress of writing a result set to a file
+// or pipe, of copying data read from a file or the input into a table, or of
+// copying a result set between databases.
+type progress struct {
+	w     io.Writer
+	kind  progressKind
+	start time.Time
+	last  time.Time
+	rows  int64
+	bytes int64
+}
+
+// newProgress creates a progress of kind that renders to w.
+func newProgress(w io.Writer, kind progressKind) *progress {
+	now := time.Now()
+	return &progress{
+		w:     w,
+		kind:  kind,
+		start: now,
+		last:  now,
+	}
+}
+
+// row records a fetched row, rendering the progress line when the update
+// interval has elapsed.
+func (p *progress) row() {
+	p.rows++
+	p.update()
+}
+
+// update renders the progress line when the update interval has elapsed.
+func (p *progress) update() {
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.render()
+	}
+}
+
+// render renders the progress line.
+func (p *progress) render() {
+	elapsed := time.Since(p.start)
+	n := p.rows
+	if p.kind == progressRead {
+		n = p.bytes
+	}
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(n) / elapsed.Seconds()
+	}
+	d := elapsed.Round(time.Millisecond)
+	switch p.kind {
+	case progressRead:
+		fmt.Fprintf(p.w, "\r"+text.ProgressReadDesc+"\x1b[K", formatBytes(p.bytes), d, formatBytes(int64(rate)))
+	case progressCopy:
+		fmt.Fprintf(p.w, "\r"+text.ProgressCopyDesc+"\x1b[K", p.rows, d, rate)
+	default:
+		fmt.Fprintf(p.w, "\r"+text.ProgressDesc+"\x1b[K", p.rows, formatBytes(p.bytes), d, rate)
+	}
+}
+
+// ticker renders the progress line every update interval until the returned
+// func is called, for operations that do not report their progress.
+func (p *progress) ticker() func() {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				p.render()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// done renders the final progress line.
+func (p *progress) done() {
+	p.render()
+	fmt.Fprintln(p.w)
+}
+
+// writer wraps w, counting the bytes written to it.
+func (p *progress) writer(w io.Writer) io.Writer {
+	return progressWriter{w, p}
+}
+
+// progressWriter wraps a writer, counting the bytes written.
+type progressWriter struct {
+	io.Writer
+	p *progress
+}
+
+// Write satisfies the io.Writer interface.
+func (w progressWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	w.p.bytes += int64(n)
+	return n, err
+}
+
+// reader wraps r, counting the bytes read from it.
+func (p *progress) reader(r io.Reader) io.Reader {
+	return progressReader{r, p}
+}
+
+// progressReader wraps a reader, counting the bytes read and rendering the
+// progress line.
+type progressReader struct {
+	io.Reader
+	p *progress
+}
+
+// Read satisfies the io.Reader interface.
+func (r progressReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	r.p.bytes += int64(n)
+	r.p.update()
+	return n, err
+}
+
+// formatBytes formats n as a human readable size.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for i := n / unit; i >= unit; i /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%0.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+)
+
+// sessionStmt is a statement that changed the session state of the
+// connection (such as the search path, current database, or role), and is
+// replayed after reconnecting.
+type sessionStmt struct {
+	key    string
+	sqlstr string
+}
+
+// sessionRE matches statements changing the session state, capturing the
+// verb and the name of the changed setting.
+var sessionRE = regexp.MustCompile(`(?is)^\s*(use|set|reset|discard|alter\s+session\s+set)\b\s*(?:session\s+)?([^\s=;,]*)`)
+
+// trackSession records sqlstr when it changes the session state of the
+// connection, replacing any earlier statement changing the same setting.
+func (h *Handler) trackSession(sqlstr string) {
+	m := sessionRE.FindStringSubmatch(sqlstr)
+	if m == nil || h.tx != nil {
+		return
+	}
+	verb, name := strings.ToLower(strings.Join(strings.Fields(m[1]), " ")), strings.ToLower(m[2])
+	var key string
+	switch verb {
+	case "use":
+		key = "use"
+	case "set", "alter session set":
+		switch name {
+		case "", "transaction", "local", "constraints", "characteristics":
+			return
+		}
+		key = "set " + name
+	case "reset", "discard":
+		if name == "all" {
+			h.session = nil
+			return
+		}
+		if verb == "reset" {
+			h.forgetSession("set " + name)
+		}
+		return
+	}
+	h.forgetSession(key)
+	h.session = append(h.session, sessionStmt{key: key, sqlstr: sqlstr})
+}
+
+// forgetSession removes the recorded session statement for key.
+func (h *Handler) forgetSession(key string) {
+	for i, s := range h.session {
+		if s.key == key {
+			h.session = append(h.session[:i], h.session[i+1:]...)
+			return
+		}
+	}
+}
+
+// canReconnect returns true when err indicates the connection to the
+// database was lost, and the RECONNECT variable is enabled. The connection is
+// not reconnected while in a transaction, as its state cannot be restored.
+func (h *Handler) canReconnect(err error) bool {
+	return err != nil && h.tx == nil && env.Get("RECONNECT") == "on" && drivers.IsConnErr(h.u, err)
+}
+
+// reconnect reopens the connection to the database after it was lost,
+// retrying with an exponential backoff as set by the RECONNECT_ATTEMPTS and
+// RECONNECT_BACKOFF variables. The statements that changed the session state
+// are replayed on each connection of the new pool before its first use (see
+// replaySession).
+func (h *Handler) reconnect(ctx context.Context) error {
+	attempts, backoff, err := reconnectOpts()
+	if err != nil {
+		return err
+	}
+	var db *sql.DB
+	for i := 1; ; i++ {
+		if db, err = drivers.Open(ctx, h.u, h.GetOutput, h.IO().Stderr); err == nil {
+			if err = drivers.Ping(ctx, h.u, db); err == nil {
+				break
+			}
+			db.Close()
+		}
+		if i >= attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	// prepared statements were lost with the connection
+	h.deallocateAll()
+	h.db.Close()
+	h.db, h.replayed = db, make(map[driver.Conn]bool)
+	stderr := h.l.Stderr()
+	fmt.Fprintf(stderr, text.Reconnected, h.u.Short(), len(h.session))
+	fmt.Fprintln(stderr)
+	return nil
+}
+
+// replaySession replays the statements that changed the session state on
+// conn, when conn was opened after reconnecting and the statements were not
+// yet replayed on it.
+func (h *Handler) replaySession(ctx context.Context, conn *sql.Conn) {
+	if h.replayed == nil || len(h.session) == 0 {
+		return
+	}
+	var dc driver.Conn
+	if err := conn.Raw(func(v interface{}) error {
+		dc, _ = v.(driver.Conn)
+		return nil
+	}); err != nil || dc == nil || h.replayed[dc] {
+		return
+	}
+	stderr := h.l.Stderr()
+	for _, s := range h.session {
+		if _, err := conn.ExecContext(ctx, s.sqlstr); err != nil {
+			fmt.Fprintln(stderr, "error:", drivers.WrapErr(h.u.Driver, err))
+		}
+	}
+	// remove the closed connections, when the driver reports them
+	for c := range h.replayed {
+		if v, ok := c.(driver.Validator); ok && !v.IsValid() {
+			delete(h.replayed, c)
+		}
+	}
+	h.replayed[dc] = true
+}
+
+// reconnectOpts returns the reconnect attempts and initial backoff from the
+// RECONNECT_ATTEMPTS and RECONNECT_BACKOFF variables.
+func reconnectOpts() (int, time.Duration, error) {
+	vars := env.All()
+	attempts, err := strconv.Atoi(vars["RECONNECT_ATTEMPTS"])
+	if err != nil || attempts < 1 {
+		return 0, 0, fmt.Errorf(text.FormatFieldInvalidValue, vars["RECONNECT_ATTEMPTS"], "RECONNECT_ATTEMPTS", "positive integer")
+	}
+	backoff, err := time.ParseDuration(vars["RECONNECT_BACKOFF"])
+	if err != nil || backoff < 0 {
+		return 0, 0, fmt.Errorf(text.FormatFieldInvalidValue, vars["RECONNECT_BACKOFF"], "RECONNECT_BACKOFF", "duration")
+	}
+	return attempts, backoff, nil
+}
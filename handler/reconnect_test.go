@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+	"github.com/mattn/go-sqlite3"
+)
+
+// errConnLost is the fake lost connection error returned by the
+// lose_connection function of the reconnecttest driver.
+var errConnLost = errors.New("connection lost")
+
+// connLosses is the number of remaining calls of the lose_connection function
+// that fail.
+var connLosses int
+
+func init() {
+	sql.Register("reconnecttest", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lose_connection", func() (int64, error) {
+				if connLosses > 0 {
+					connLosses--
+					return 0, errConnLost
+				}
+				return 1, nil
+			}, false)
+		},
+	})
+	dburl.Register(dburl.Scheme{Driver: "reconnecttest", Generator: dburl.GenOpaque, Opaque: true, Aliases: []string{"rc"}})
+	drivers.Register("reconnecttest", drivers.Driver{
+		// statements with a RETURNING clause are queries, as with postgres
+		Process: func(prefix, sqlstr string) (string, string, bool, error) {
+			typ, q := drivers.QueryExecType(prefix, sqlstr)
+			return typ, sqlstr, q || strings.Contains(strings.ToUpper(sqlstr), "RETURNING"), nil
+		},
+		IsConnErr: func(err error) bool {
+			return strings.Contains(err.Error(), errConnLost.Error())
+		},
+	})
+}
+
+func TestReconnect(t *testing.T) {
+	if err := env.Set("RECONNECT", "on"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer env.Set("RECONNECT", "off")
+	out := new(bytes.Buffer)
+	h := newTestHandlerDriver(t, out, "reconnecttest")
+	ctx := context.Background()
+	exec := func(sqlstr string) error {
+		return h.Execute(ctx, out, metacmd.Option{}, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false)
+	}
+	if err := exec(`CREATE TABLE t (a INTEGER)`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// temporary tables only exist on the connection they were created on
+	h.session = []sessionStmt{{"temp", `CREATE TEMP TABLE IF NOT EXISTS s (a INTEGER)`}}
+	tests := []struct {
+		sqlstr string
+		rows   string
+		err    bool
+	}{
+		// read only queries are executed again
+		{`SELECT lose_connection()`, "", false},
+		// writes are not, including writing queries
+		{`INSERT INTO t VALUES (lose_connection())`, "", true},
+		{`INSERT INTO t VALUES (lose_connection()) RETURNING a`, "", true},
+		{`INSERT INTO t VALUES (2)`, "2", false},
+	}
+	for i, test := range tests {
+		connLosses = 1
+		out.Reset()
+		err := exec(test.sqlstr)
+		switch {
+		case test.err && (err == nil || !strings.Contains(err.Error(), text.ReconnectNotRetried)):
+			t.Errorf("test %d expected error not executed again, got: %v", i, err)
+		case !test.err && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		}
+		if s := out.String(); !strings.Contains(s, "Reconnected") && strings.Contains(test.sqlstr, "lose_connection") {
+			t.Errorf("test %d expected reconnect, got: %q", i, s)
+		}
+		var rows sql.NullString
+		if err := h.db.QueryRowContext(ctx, `SELECT group_concat(a, ',') FROM t`).Scan(&rows); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if rows.String != test.rows {
+			t.Errorf("test %d expected rows %q, got: %q", i, test.rows, rows.String)
+		}
+	}
+	// the session statements were replayed on the connection
+	if err := exec(`SELECT COUNT(*) FROM temp.s`); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
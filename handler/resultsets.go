@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/text"
+	"github.com/xo/tblfmt"
+)
+
+// encodeResultSets encodes each of the result sets separately, so that the
+// display settings (such as the column widths, expanded auto mode, and the
+// footer) apply to each result set. Result sets without columns, such as the
+// status of a stored procedure call, are skipped. Result sets after the first
+// are separated by the result set number, unless the format is machine
+// readable or only tuples are displayed.
+func encodeResultSets(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	separate := params["tuples_only"] != "on"
+	switch params["format"] {
+	case "aligned", "wrapped", "unaligned", "vertical":
+	default:
+		separate = false
+	}
+	var n int
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return err
+		}
+		if len(cols) != 0 {
+			if n++; n > 1 {
+				fmt.Fprintln(w)
+				if separate {
+					fmt.Fprintf(w, text.ResultSetDesc, n)
+					fmt.Fprintln(w)
+				}
+			}
+			if err := env.EncodeAll(w, singleResultSet{resultSet}, params); err != nil {
+				return err
+			}
+		}
+		if !resultSet.NextResultSet() {
+			break
+		}
+	}
+	if err := resultSet.Err(); err != nil {
+		return err
+	}
+	if n == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	return nil
+}
+
+// isPlanResult returns true when the results of the query may be a query plan
+// displayed as a tree, as the results of an EXPLAIN statement with a single
+// column displayed as a table.
+func isPlanResult(typ string, rows *sql.Rows, params map[string]string) bool {
+	switch {
+	case rows == nil, params["watch_diff"] == "on",
+		params["format"] != "aligned" && params["format"] != "wrapped":
+		return false
+	}
+	if prefix, _, _ := strings.Cut(typ, " "); prefix != "EXPLAIN" {
+		return false
+	}
+	cols, err := rows.Columns()
+	return err == nil && len(cols) == 1
+}
+
+// encodePlan writes the query plan returned by an EXPLAIN statement as a tree,
+// when the results are a single value parsed as a query plan by the driver
+// (ie, the plan returned by EXPLAIN (FORMAT JSON) for PostgreSQL). Other
+// results are encoded as usual.
+func (h *Handler) encodePlan(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	sets, err := readResults(resultSet)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 1 && len(sets[0].cols) == 1 && len(sets[0].rows) == 1 {
+		if plan, ok := drivers.ParsePlan(h.u, sets[0].rows[0][0]); ok {
+			_, err := plan.WriteTo(w)
+			return err
+		}
+	}
+	for i, e := range sets {
+		if i != 0 {
+			fmt.Fprintln(w)
+		}
+		if err := env.EncodeAll(w, &cachedRows{e: e}, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// singleResultSet wraps a result set, hiding the result sets following the
+// current result set.
+type singleResultSet struct {
+	tblfmt.ResultSet
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (singleResultSet) NextResultSet() bool {
+	return false
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r singleResultSet) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// outParamRows wraps the result sets of a stored procedure call, followed by
+// the result set of the query retrieving the values of its output parameters.
+type outParamRows struct {
+	tblfmt.ResultSet
+	query func() (*sql.Rows, error)
+	err   error
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *outParamRows) NextResultSet() bool {
+	if r.ResultSet.NextResultSet() {
+		return true
+	}
+	query := r.query
+	if query == nil || r.ResultSet.Err() != nil {
+		return false
+	}
+	r.query = nil
+	// the call's results must be closed before querying the connection
+	if r.err = r.ResultSet.Close(); r.err != nil {
+		return false
+	}
+	rows, err := query()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.ResultSet = rows
+	return true
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *outParamRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// Err satisfies the tblfmt.ResultSet interface.
+func (r *outParamRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.ResultSet.Err()
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// replica is a read replica connection, that read only statements are routed
+// to.
+type replica struct {
+	u  *dburl.URL
+	db *sql.DB
+}
+
+// ConnectReplica opens the read replica connection, routing read only
+// statements executed outside of transactions to it.
+func (h *Handler) ConnectReplica(ctx context.Context, urlstr string) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	u, db, err := h.OpenDB(ctx, urlstr)
+	if err != nil {
+		return err
+	}
+	if u.Driver != h.u.Driver {
+		db.Close()
+		return fmt.Errorf(text.RouteDriverMismatch, u.Driver, h.u.Driver)
+	}
+	if err := h.CloseReplica(); err != nil {
+		db.Close()
+		return err
+	}
+	h.replica = &replica{u: u, db: db}
+	return nil
+}
+
+// CloseReplica closes the read replica connection, routing all statements to
+// the primary connection.
+func (h *Handler) CloseReplica() error {
+	if h.replica == nil {
+		return nil
+	}
+	r := h.replica
+	h.replica, h.lastRoute = nil, ""
+	return drivers.WrapErr(r.u.Driver, r.db.Close())
+}
+
+// SetRoute sets the routing of statements: auto routes read only statements
+// to the read replica, while primary and replica route all statements to the
+// primary or the read replica connection.
+func (h *Handler) SetRoute(route string) {
+	h.route = route
+}
+
+// RouteStatus writes the primary and read replica connections, the routing
+// of statements, and the connection the last statement was routed to.
+func (h *Handler) RouteStatus(w io.Writer) error {
+	route := h.route
+	if route == "" {
+		route = "auto"
+	}
+	primary, rep, last := text.RouteNone, text.RouteNone, text.RouteNone
+	if h.u != nil {
+		primary = shortURL(h.u)
+	}
+	if h.replica != nil {
+		rep = shortURL(h.replica.u)
+	}
+	if h.lastRoute != "" {
+		last = h.lastRoute
+	}
+	_, err := fmt.Fprintf(w, text.RouteStatusDesc+"\n", primary, rep, route, last)
+	return err
+}
+
+// routed returns the connection to execute a statement on. Outside of
+// transactions, read only statements are routed to the read replica
+// connection, unless overridden by \route.
+func (h *Handler) routed(readOnly bool) (*dburl.URL, *sql.DB) {
+	route := "primary"
+	switch {
+	case h.replica == nil || h.tx != nil:
+	case h.route == "replica", h.route != "primary" && readOnly:
+		route = "replica"
+	}
+	if h.replica != nil {
+		h.lastRoute = route
+	}
+	if route == "replica" {
+		return h.replica.u, h.replica.db
+	}
+	return h.u, h.db
+}
+
+// shortURL returns the short description of the database URL, or the driver
+// name when connected with driver parameters.
+func shortURL(u *dburl.URL) string {
+	if s := u.Short(); s != "" {
+		return s
+	}
+	return u.Driver
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+// runStats are the statement counts of a script run.
+type runStats struct {
+	executed, failed, retried int
+}
+
+// RunScript executes the statements in the file at path, wrapping them in a
+// transaction and savepoints, and retrying serialization failures, as
+// specified by opts. A summary of the executed and failed statements is
+// written on completion.
+func (h *Handler) RunScript(path string, opts metacmd.RunOptions) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(h.wd, path)
+	}
+	stmts, err := h.readScript(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	out := h.l.Stdout()
+	if h.out != nil {
+		out = h.out
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var stats runStats
+	if opts.SingleTransaction || opts.SavepointPerStatement {
+		err = h.runTx(ctx, out, stmts, opts, &stats)
+	} else {
+		err = h.runStmts(ctx, out, stmts, opts, &stats)
+	}
+	fmt.Fprintf(out, text.RunSummary, stats.executed, stats.failed, stats.retried)
+	fmt.Fprintln(out)
+	return err
+}
+
+// readScript reads the statements from the file at path, using the
+// statement parsing rules of the current driver.
+func (h *Handler) readScript(path string) ([]string, error) {
+	_, f, err := env.OpenFile(h.user, path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return drivers.Statements(h.u, f, h.unquote())
+}
+
+// runStmts executes the statements individually, retrying each statement on
+// a serialization failure.
+func (h *Handler) runStmts(ctx context.Context, w io.Writer, stmts []string, opts metacmd.RunOptions, stats *runStats) error {
+	for _, s := range stmts {
+		var err error
+		for i := 0; ; i++ {
+			err = h.Execute(ctx, w, metacmd.Option{}, stmt.FindPrefix(s, true, true, true), s, false)
+			if err == nil || i >= opts.RetrySerialization || !drivers.IsSerializationErr(h.u, err) {
+				break
+			}
+			stats.retried++
+		}
+		if err == nil {
+			stats.executed++
+			continue
+		}
+		stats.failed++
+		if env.All()["ON_ERROR_STOP"] == "on" {
+			return err
+		}
+		fmt.Fprintln(h.l.Stderr(), "error:", err)
+	}
+	return nil
+}
+
+// runTx executes the statements in a single transaction. When the
+// transaction was started by runTx, it is retried as a whole on a
+// serialization failure.
+func (h *Handler) runTx(ctx context.Context, w io.Writer, stmts []string, opts metacmd.RunOptions, stats *runStats) error {
+	owned := h.tx == nil
+	for i := 0; ; i++ {
+		if owned {
+			if err := h.BeginTx(ctx, nil); err != nil {
+				return err
+			}
+		}
+		s := runStats{retried: stats.retried}
+		err := h.runTxStmts(ctx, w, stmts, opts, &s)
+		switch {
+		case err == nil && owned:
+			err = h.Commit()
+		case err != nil && owned:
+			_ = h.Rollback()
+		}
+		if err != nil && owned && i < opts.RetrySerialization && drivers.IsSerializationErr(h.u, err) {
+			stats.retried = s.retried + 1
+			continue
+		}
+		*stats = s
+		return err
+	}
+}
+
+// runTxStmts executes the statements in the current transaction. When
+// savepoints are enabled, a failed statement is rolled back to its savepoint
+// and retried on a serialization failure, otherwise the first failure is
+// returned.
+func (h *Handler) runTxStmts(ctx context.Context, w io.Writer, stmts []string, opts metacmd.RunOptions, stats *runStats) error {
+	for n, s := range stmts {
+		prefix := stmt.FindPrefix(s, true, true, true)
+		if !opts.SavepointPerStatement {
+			if err := h.Execute(ctx, w, metacmd.Option{}, prefix, s, false); err != nil {
+				stats.failed++
+				return err
+			}
+			stats.executed++
+			continue
+		}
+		save, release, rollback := drivers.Savepoint(h.u, fmt.Sprintf("usql_run_%d", n+1))
+		var err error
+		for i := 0; ; i++ {
+			if _, err := h.tx.ExecContext(ctx, save); err != nil {
+				return drivers.WrapErr(h.u.Driver, err)
+			}
+			if err = h.Execute(ctx, w, metacmd.Option{}, prefix, s, false); err == nil {
+				break
+			}
+			if _, rerr := h.tx.ExecContext(ctx, rollback); rerr != nil {
+				return errors.Join(err, drivers.WrapErr(h.u.Driver, rerr))
+			}
+			if i >= opts.RetrySerialization || !drivers.IsSerializationErr(h.u, err) {
+				break
+			}
+			stats.retried++
+		}
+		if err == nil {
+			if release != "" {
+				if _, err := h.tx.ExecContext(ctx, release); err != nil {
+					return drivers.WrapErr(h.u.Driver, err)
+				}
+			}
+			stats.executed++
+			continue
+		}
+		stats.failed++
+		if env.All()["ON_ERROR_STOP"] == "on" {
+			return err
+		}
+		fmt.Fprintln(h.l.Stderr(), "error:", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/mattn/go-sqlite3"
+)
+
+// errSerialization is the fake serialization failure returned by the
+// serialization_failure function of the runtest driver.
+var errSerialization = errors.New("serialization failure")
+
+// serializationFailures is the number of remaining calls of the
+// serialization_failure function that fail.
+var serializationFailures int
+
+func init() {
+	sql.Register("runtest", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("serialization_failure", func() (int64, error) {
+				if serializationFailures > 0 {
+					serializationFailures--
+					return 0, errSerialization
+				}
+				return 1, nil
+			}, false)
+		},
+	})
+	dburl.Register(dburl.Scheme{Driver: "runtest", Generator: dburl.GenOpaque, Opaque: true, Aliases: []string{"rt"}})
+	drivers.Register("runtest", drivers.Driver{
+		AllowMultilineComments: true,
+		IsSerializationErr: func(err error) bool {
+			return strings.Contains(err.Error(), errSerialization.Error())
+		},
+	})
+}
+
+func TestRunScript(t *testing.T) {
+	stmts := []string{
+		`INSERT INTO t VALUES (1);`,
+		`INSERT INTO missing VALUES (2);`,
+		`INSERT INTO t VALUES (3);`,
+	}
+	retry := []string{
+		`INSERT INTO t VALUES (1);`,
+		`INSERT INTO t VALUES (serialization_failure() + 1);`,
+	}
+	tests := []struct {
+		stmts    []string
+		opts     metacmd.RunOptions
+		failures int
+		stop     bool
+		exp      string
+		rows     string
+		err      bool
+	}{
+		{stmts, metacmd.RunOptions{}, 0, false, "RUN 2 executed, 1 failed, 0 retried", "1,3", false},
+		{stmts, metacmd.RunOptions{}, 0, true, "RUN 1 executed, 1 failed, 0 retried", "1", true},
+		// the first failure rolls back the transaction
+		{stmts, metacmd.RunOptions{SingleTransaction: true}, 0, false, "RUN 1 executed, 1 failed, 0 retried", "", true},
+		// a failed statement is rolled back to its savepoint
+		{stmts, metacmd.RunOptions{SavepointPerStatement: true}, 0, false, "RUN 2 executed, 1 failed, 0 retried", "1,3", false},
+		{stmts, metacmd.RunOptions{SavepointPerStatement: true}, 0, true, "RUN 1 executed, 1 failed, 0 retried", "", true},
+		// statements are retried individually
+		{retry, metacmd.RunOptions{RetrySerialization: 2}, 2, false, "RUN 2 executed, 0 failed, 2 retried", "1,2", false},
+		{retry, metacmd.RunOptions{RetrySerialization: 1}, 2, false, "RUN 1 executed, 1 failed, 1 retried", "1", false},
+		// the transaction is retried as a whole
+		{retry, metacmd.RunOptions{SingleTransaction: true, RetrySerialization: 2}, 2, false, "RUN 2 executed, 0 failed, 2 retried", "1,2", false},
+		{retry, metacmd.RunOptions{SingleTransaction: true, RetrySerialization: 1}, 2, false, "RUN 1 executed, 1 failed, 1 retried", "", true},
+		// statements are retried from their savepoint
+		{retry, metacmd.RunOptions{SavepointPerStatement: true, RetrySerialization: 2}, 2, false, "RUN 2 executed, 0 failed, 2 retried", "1,2", false},
+		{retry, metacmd.RunOptions{SavepointPerStatement: true, RetrySerialization: 1}, 2, false, "RUN 1 executed, 1 failed, 1 retried", "1", false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if test.stop {
+				if err := env.Set("ON_ERROR_STOP", "on"); err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				defer env.Set("ON_ERROR_STOP", "off")
+			}
+			out := new(bytes.Buffer)
+			h := newTestHandlerDriver(t, out, "runtest")
+			if _, err := h.db.Exec(`CREATE TABLE t (i INTEGER)`); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			script := filepath.Join(h.wd, "script.sql")
+			if err := os.WriteFile(script, []byte(strings.Join(test.stmts, "\n")+"\n"), 0o644); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			serializationFailures = test.failures
+			err := h.RunScript("script.sql", test.opts)
+			switch {
+			case test.err && err == nil:
+				t.Errorf("test %d expected error, got nil", i)
+			case !test.err && err != nil:
+				t.Errorf("test %d expected no error, got: %v", i, err)
+			}
+			if s := out.String(); !strings.Contains(s, test.exp+"\n") {
+				t.Errorf("test %d expected output to contain %q, got: %q", i, test.exp, s)
+			}
+			var rows sql.NullString
+			if err := h.db.QueryRow(`SELECT group_concat(i, ',') FROM (SELECT i FROM t ORDER BY i)`).Scan(&rows); err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			if rows.String != test.rows {
+				t.Errorf("test %d expected rows %q, got: %q", i, test.rows, rows.String)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/stmt"
+	"github.com/ildus/usql/text"
+)
+
+// sampleMaxColWidth is the maximum column width of the sampled rows, when the
+// maxcolwidth display setting is not set, so that the rows of wide tables
+// remain readable.
+const sampleMaxColWidth = 40
+
+// Sample displays a sample of n rows of the table, using the driver's
+// sampling query.
+func (h *Handler) Sample(ctx context.Context, table string, n int) error {
+	if h.db == nil {
+		return text.ErrNotConnected
+	}
+	sqlstr, err := drivers.Sample(ctx, h.u, h.DB(), table, n)
+	if err != nil {
+		return err
+	}
+	opt := metacmd.Option{Exec: metacmd.ExecOnly, Params: make(map[string]string)}
+	if v, _ := env.Pget("maxcolwidth"); v == "0" {
+		opt.Params["maxcolwidth"] = strconv.Itoa(sampleMaxColWidth)
+	}
+	return h.Execute(ctx, h.GetOutput(), opt, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/ildus/usql/sinks"
+	"github.com/xo/tblfmt"
+)
+
+// isSinkURL returns true when name is the url of a registered row sink.
+func isSinkURL(name string) bool {
+	s, ok := sinks.Lookup(name)
+	return ok && s.Open != nil
+}
+
+// openSink opens the row sink for the url.
+func openSink(ctx context.Context, urlstr string) (sinks.RowSink, error) {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	s, _ := sinks.Lookup(urlstr)
+	return s.Open(ctx, u)
+}
+
+// writeSink writes the rows of each of the result sets to the sink. Result
+// sets without columns are skipped.
+func writeSink(sink sinks.RowSink, resultSet tblfmt.ResultSet) error {
+	var n int
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return err
+		}
+		if len(cols) != 0 {
+			n++
+			if err := sink.Columns(cols); err != nil {
+				return err
+			}
+			row := make([]interface{}, len(cols))
+			ptrs := make([]interface{}, len(cols))
+			for i := range row {
+				ptrs[i] = &row[i]
+			}
+			for resultSet.Next() {
+				if err := resultSet.Scan(ptrs...); err != nil {
+					return err
+				}
+				if err := sink.Write(row); err != nil {
+					return err
+				}
+			}
+			if err := resultSet.Err(); err != nil {
+				return err
+			}
+		}
+		if !resultSet.NextResultSet() {
+			break
+		}
+	}
+	if err := resultSet.Err(); err != nil {
+		return err
+	}
+	if n == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	return nil
+}
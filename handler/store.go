@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// storeNameRE matches valid stored result names.
+var storeNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store stores the last query result as name, to be referenced as ::name in
+// subsequent statements, on any connection.
+func (h *Handler) Store(name string) error {
+	switch {
+	case !storeNameRE.MatchString(name):
+		return fmt.Errorf(text.StoreInvalidName, name)
+	case h.lastResult == nil:
+		return text.ErrNoLastResult
+	case h.lastResult.truncated:
+		return text.ErrStoreTruncated
+	}
+	if h.stored == nil {
+		h.stored = make(map[string]*cacheEntry)
+	}
+	h.stored[name] = h.lastResult
+	h.Print(text.StoreSaved, len(h.lastResult.rows), name)
+	return nil
+}
+
+// StoreList writes the stored results.
+func (h *Handler) StoreList(w io.Writer) error {
+	if len(h.stored) == 0 {
+		fmt.Fprintln(w, text.StoreNone)
+		return nil
+	}
+	names := make([]string, 0, len(h.stored))
+	for name := range h.stored {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e := h.stored[name]
+		fmt.Fprintf(w, text.StoreDesc+"\n", name, len(e.rows), strings.Join(e.cols, ", "))
+	}
+	return nil
+}
+
+// expandStored replaces the ::name references to stored results in sqlstr
+// with the driver's derived table inlining their rows. A reference must not
+// directly follow an expression (as with PostgreSQL's x::type casts), and
+// references in quoted strings, quoted identifiers, and comments are left
+// as is.
+func (h *Handler) expandStored(sqlstr string) string {
+	if len(h.stored) == 0 || !strings.Contains(sqlstr, "::") {
+		return sqlstr
+	}
+	var sb strings.Builder
+	r, last := []rune(sqlstr), 0
+	for i := 0; i < len(r); i++ {
+		switch c, next := r[i], grab(r, i+1, len(r)); {
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(r) && r[i] != c; i++ {
+			}
+		case c == '-' && next == '-':
+			for ; i < len(r) && r[i] != '\n'; i++ {
+			}
+		case c == '/' && next == '*':
+			for i += 2; i < len(r) && (r[i] != '*' || grab(r, i+1, len(r)) != '/'); i++ {
+			}
+			i++
+		case c == ':' && next == ':' && (i == 0 || !isExprEnd(r[i-1])):
+			end := i + 2
+			for end < len(r) && isNameRune(r[end]) {
+				end++
+			}
+			e, ok := h.stored[string(r[i+2:end])]
+			if !ok {
+				i++
+				continue
+			}
+			sb.WriteString(string(r[last:i]))
+			sb.WriteString(h.inlineStored(string(r[i+2:end]), e))
+			last, i = end, end-1
+		}
+	}
+	if last == 0 {
+		return sqlstr
+	}
+	sb.WriteString(string(r[last:]))
+	return sb.String()
+}
+
+// inlineStored returns the driver's derived table inlining the rows of the
+// stored result.
+func (h *Handler) inlineStored(name string, e *cacheEntry) string {
+	cols := make([]string, len(e.cols))
+	for i, col := range e.cols {
+		cols[i] = drivers.QuoteIdentifier(h.u, col)
+	}
+	rows := make([][]string, len(e.rows))
+	for i, row := range e.rows {
+		rows[i] = make([]string, len(row))
+		for j, v := range row {
+			rows[i][j] = h.literal(v)
+		}
+	}
+	return drivers.InlineRows(h.u, name, cols, rows)
+}
+
+// literal returns v as a SQL literal for the driver.
+func (h *Handler) literal(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(x)
+	case float32:
+		return h.literal(float64(x))
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return drivers.QuoteLiteral(h.u, strconv.FormatFloat(x, 'g', -1, 64))
+		}
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case []byte:
+		return drivers.QuoteLiteral(h.u, string(x))
+	case string:
+		return drivers.QuoteLiteral(h.u, x)
+	case time.Time:
+		return drivers.QuoteLiteral(h.u, x.Format(time.RFC3339Nano))
+	}
+	return drivers.QuoteLiteral(h.u, fmt.Sprint(v))
+}
+
+// isExprEnd returns true when r ends an expression, such that a following ::
+// is a cast.
+func isExprEnd(r rune) bool {
+	return isNameRune(r) || strings.ContainsRune(")]'\"`:", r)
+}
+
+// isNameRune returns true when r is valid in a stored result name.
+func isNameRune(r rune) bool {
+	return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/ildus/usql/env"
+	"github.com/xo/tblfmt"
+)
+
+// tee is the output, and its format, that query results are additionally
+// written to by \tee.
+type tee struct {
+	w      io.WriteCloser
+	format string
+}
+
+// SetTee sets the output that query results are additionally written to in
+// the format, closing the previous output, if any. A nil output stops writing
+// results to the previous output.
+func (h *Handler) SetTee(w io.WriteCloser, format string) {
+	if h.tee != nil {
+		h.tee.w.Close()
+		h.tee = nil
+	}
+	if w != nil {
+		h.tee = &tee{w: w, format: format}
+	}
+}
+
+// write writes the recorded result sets in the tee's format, using the
+// remaining params of the query's output.
+func (t *tee) write(r *teeRecorder, params map[string]string) error {
+	p := make(map[string]string, len(params))
+	for k, v := range params {
+		p[k] = v
+	}
+	p["format"], p["expanded"] = t.format, "off"
+	delete(p, "pager_cmd")
+	delete(p, "use_column_types")
+	for _, e := range r.sets {
+		if err := env.EncodeAll(t.w, &cachedRows{e: e}, p); err != nil {
+			return err
+		}
+		if t.format == "aligned" {
+			fmt.Fprintln(t.w)
+		}
+	}
+	return nil
+}
+
+// teeRecorder wraps a result set, recording the scanned rows of all its
+// result sets for writing to the tee.
+type teeRecorder struct {
+	tblfmt.ResultSet
+	sets []*cacheEntry
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *teeRecorder) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err == nil && len(r.sets) == 0 {
+		r.sets = append(r.sets, &cacheEntry{cols: append([]string{}, cols...)})
+	}
+	return cols, err
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (r *teeRecorder) ColumnTypes() ([]*sql.ColumnType, error) {
+	if z, ok := r.ResultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return z.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *teeRecorder) Scan(v ...interface{}) error {
+	if err := r.ResultSet.Scan(v...); err != nil || len(r.sets) == 0 {
+		return err
+	}
+	row := make([]interface{}, len(v))
+	for i, z := range v {
+		if p, ok := z.(*interface{}); ok {
+			row[i] = *p
+		}
+	}
+	e := r.sets[len(r.sets)-1]
+	e.rows = append(e.rows, row)
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *teeRecorder) NextResultSet() bool {
+	if !r.ResultSet.NextResultSet() {
+		return false
+	}
+	if cols, err := r.ResultSet.Columns(); err == nil {
+		r.sets = append(r.sets, &cacheEntry{cols: cols})
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ildus/usql/text"
+)
+
+// timingPhase is a phase of a statement's execution.
+type timingPhase int
+
+// Timing phases.
+const (
+	// phaseParse is the time spent processing the statement before sending
+	// it to the database.
+	phaseParse timingPhase = iota
+	// phaseConnect is the time spent acquiring a database connection.
+	phaseConnect
+	// phaseExecute is the time until the database returns the first result.
+	phaseExecute
+	// phaseFetch is the time spent retrieving rows from the database.
+	phaseFetch
+	// phaseRender is the time spent formatting and writing the results.
+	phaseRender
+	phaseCount
+)
+
+// timingPhaseNames are the names of the timing phases.
+var timingPhaseNames = [phaseCount]string{
+	"parse",
+	"connect",
+	"execute",
+	"fetch",
+	"render",
+}
+
+// timings are the durations of the phases of a statement.
+type timings [phaseCount]time.Duration
+
+// total returns the total duration of all phases.
+func (t timings) total() time.Duration {
+	var d time.Duration
+	for _, v := range t {
+		d += v
+	}
+	return d
+}
+
+// timingBuckets are the upper bounds of the timing histogram buckets.
+var timingBuckets = []struct {
+	d     time.Duration
+	label string
+}{
+	{1 * time.Millisecond, "1 ms"},
+	{10 * time.Millisecond, "10 ms"},
+	{100 * time.Millisecond, "100 ms"},
+	{1 * time.Second, "1 s"},
+	{10 * time.Second, "10 s"},
+	{1 * time.Minute, "1 min"},
+}
+
+// timingStats are the statement timing statistics of a session.
+type timingStats struct {
+	count              int64
+	sum                timings
+	min, max           timings
+	minTotal, maxTotal time.Duration
+	buckets            []int64
+}
+
+// add adds a statement's timings to the statistics.
+func (s *timingStats) add(t timings) {
+	if s.buckets == nil {
+		s.buckets = make([]int64, len(timingBuckets)+1)
+	}
+	for i, d := range t {
+		if s.count == 0 || d < s.min[i] {
+			s.min[i] = d
+		}
+		if d > s.max[i] {
+			s.max[i] = d
+		}
+		s.sum[i] += d
+	}
+	total := t.total()
+	if s.count == 0 || total < s.minTotal {
+		s.minTotal = total
+	}
+	if total > s.maxTotal {
+		s.maxTotal = total
+	}
+	s.count++
+	s.buckets[sort.Search(len(timingBuckets), func(i int) bool {
+		return total < timingBuckets[i].d
+	})]++
+}
+
+// write writes a summary of the statistics per phase, followed by a histogram
+// of the total statement durations.
+func (s *timingStats) write(w io.Writer) error {
+	if s.count == 0 {
+		_, err := fmt.Fprintln(w, text.TimingNoStats)
+		return err
+	}
+	fmt.Fprintf(w, text.TimingStatsDesc+"\n\n", s.count)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Phase\tTotal\tMean\tMin\tMax\t")
+	for i := timingPhase(0); i < phaseCount; i++ {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", timingPhaseNames[i], ms(s.sum[i]), ms(s.sum[i]/time.Duration(s.count)), ms(s.min[i]), ms(s.max[i]))
+	}
+	total := s.sum.total()
+	fmt.Fprintf(tw, "total\t%s\t%s\t%s\t%s\t\n", ms(total), ms(total/time.Duration(s.count)), ms(s.minTotal), ms(s.maxTotal))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	var most int64
+	for _, n := range s.buckets {
+		if n > most {
+			most = n
+		}
+	}
+	tw = tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for i, n := range s.buckets {
+		label := "≥ " + timingBuckets[len(timingBuckets)-1].label
+		if i < len(timingBuckets) {
+			label = "< " + timingBuckets[i].label
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", label, n, strings.Repeat("█", int(n*40/most)))
+	}
+	return tw.Flush()
+}
+
+// ms formats d in milliseconds.
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%0.3f ms", float64(d.Microseconds())/1000)
+}
+
+// timedRows wraps a result set, accumulating the time spent fetching rows,
+// and recording the fetched rows to the progress, if any.
+type timedRows struct {
+	*sql.Rows
+	d *time.Duration
+	n *int64
+	p *progress
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (r timedRows) Next() bool {
+	start := time.Now()
+	ok := r.Rows.Next()
+	*r.d += time.Since(start)
+	if ok {
+		*r.n++
+	}
+	if ok && r.p != nil {
+		r.p.row()
+	}
+	return ok
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r timedRows) Scan(v ...interface{}) error {
+	start := time.Now()
+	defer func() { *r.d += time.Since(start) }()
+	return r.Rows.Scan(v...)
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r timedRows) NextResultSet() bool {
+	start := time.Now()
+	defer func() { *r.d += time.Since(start) }()
+	return r.Rows.NextResultSet()
+}
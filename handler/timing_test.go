@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ildus/usql/text"
+)
+
+func TestTimingStats(t *testing.T) {
+	var s timingStats
+	var buf bytes.Buffer
+	if err := s.write(&buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := text.TimingNoStats + "\n"; buf.String() != exp {
+		t.Errorf("expected %q, got: %q", exp, buf.String())
+	}
+	// totals of 0.5 ms, 1 ms (bucket bounds are exclusive), 5 ms, 50 ms twice,
+	// 2 s, and 2 min
+	for _, d := range []timings{
+		{0, 0, 500 * time.Microsecond},
+		{0, 0, 1 * time.Millisecond},
+		{1 * time.Millisecond, 0, 3 * time.Millisecond, 1 * time.Millisecond},
+		{0, 10 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond},
+		{0, 0, 50 * time.Millisecond},
+		{0, 0, 1 * time.Second, 1 * time.Second},
+		{0, 0, 2 * time.Minute},
+	} {
+		s.add(d)
+	}
+	if exp := []int64{1, 2, 2, 0, 1, 0, 1}; !reflect.DeepEqual(s.buckets, exp) {
+		t.Errorf("expected buckets %v, got: %v", exp, s.buckets)
+	}
+	if s.minTotal != 500*time.Microsecond || s.maxTotal != 2*time.Minute {
+		t.Errorf("expected min and max total 0.5ms and 2m, got: %v and %v", s.minTotal, s.maxTotal)
+	}
+	buf.Reset()
+	if err := s.write(&buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := fmt.Sprintf(text.TimingStatsDesc, 7) + `
+
+    Phase          Total          Mean       Min            Max
+    parse       1.000 ms      0.142 ms  0.000 ms       1.000 ms
+  connect      10.000 ms      1.428 ms  0.000 ms      10.000 ms
+  execute  121074.500 ms  17296.357 ms  0.500 ms  120000.000 ms
+    fetch    1011.000 ms    144.428 ms  0.000 ms    1000.000 ms
+   render      10.000 ms      1.428 ms  0.000 ms      10.000 ms
+    total  122106.500 ms  17443.785 ms  0.500 ms  120000.000 ms
+
+< 1 ms   1 ████████████████████
+< 10 ms  2 ████████████████████████████████████████
+< 100 ms 2 ████████████████████████████████████████
+< 1 s    0 
+< 10 s   1 ████████████████████
+< 1 min  0 
+≥ 1 min  1 ████████████████████
+`
+	if s := buf.String(); s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ildus/usql/env"
+	"github.com/xo/tblfmt"
+)
+
+// watchDiff keeps the results of the previous execution of a watched query
+// (\watch --diff), to mark the rows and values changed since.
+type watchDiff struct {
+	// snapshots are the rows of each result set of the previous execution.
+	snapshots []*snapshot
+}
+
+// snapshot are the rows of a result set, keyed by the value of their first
+// column.
+type snapshot struct {
+	cols int
+	keys []string
+	rows map[string][]interface{}
+}
+
+// add adds a row to the snapshot, returning its key. Rows with the same value
+// in the first column are keyed by their order.
+func (s *snapshot) add(row []interface{}) string {
+	var first string
+	if len(row) != 0 {
+		first = diffString(row[0])
+	}
+	key := first
+	for n := 2; ; n++ {
+		if _, ok := s.rows[key]; !ok {
+			break
+		}
+		key = first + "\x00" + strconv.Itoa(n)
+	}
+	s.keys = append(s.keys, key)
+	s.rows[key] = row
+	return key
+}
+
+// rows wraps the result set, marking the rows added (+), changed (~), and
+// removed (-) since the previous execution in a leading column, and wrapping
+// the changed values as env.Changed.
+func (d *watchDiff) rows(resultSet tblfmt.ResultSet) tblfmt.ResultSet {
+	return &diffRows{ResultSet: resultSet, d: d}
+}
+
+// diffRows wraps the result set of a watched query, comparing its rows with
+// the previous execution. The removed rows follow the rows of each result
+// set.
+type diffRows struct {
+	tblfmt.ResultSet
+	d *watchDiff
+	// set is the index of the current result set.
+	set  int
+	prev *snapshot
+	cur  *snapshot
+	seen map[string]bool
+	// removed are the keys of the removed rows not yet returned, and
+	// done indicates the rows of the wrapped result set were consumed.
+	removed []string
+	done    bool
+	row     []interface{}
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *diffRows) Columns() ([]string, error) {
+	cols, err := r.ResultSet.Columns()
+	if err != nil || len(cols) == 0 {
+		return cols, err
+	}
+	if r.cur == nil {
+		r.cur = &snapshot{cols: len(cols), rows: make(map[string][]interface{})}
+		r.seen = make(map[string]bool)
+		if r.set < len(r.d.snapshots) && r.d.snapshots[r.set] != nil && r.d.snapshots[r.set].cols == len(cols) {
+			r.prev = r.d.snapshots[r.set]
+		}
+	}
+	return append([]string{"+/-"}, cols...), nil
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (r *diffRows) Next() bool {
+	if !r.done {
+		if r.ResultSet.Next() {
+			return true
+		}
+		r.done = true
+		if r.cur != nil {
+			for r.set >= len(r.d.snapshots) {
+				r.d.snapshots = append(r.d.snapshots, nil)
+			}
+			r.d.snapshots[r.set] = r.cur
+		}
+		if r.prev != nil && r.ResultSet.Err() == nil {
+			for _, key := range r.prev.keys {
+				if !r.seen[key] {
+					r.removed = append(r.removed, key)
+				}
+			}
+		}
+	}
+	if len(r.removed) == 0 {
+		return false
+	}
+	r.row, r.removed = r.prev.rows[r.removed[0]], r.removed[1:]
+	return true
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *diffRows) Scan(v ...interface{}) error {
+	if len(v) == 0 {
+		return r.ResultSet.Scan(v...)
+	}
+	if r.done {
+		// removed row
+		setValue(v[0], "-")
+		for i, val := range r.row {
+			if i+1 < len(v) {
+				setValue(v[i+1], val)
+			}
+		}
+		return nil
+	}
+	if err := r.ResultSet.Scan(v[1:]...); err != nil {
+		return err
+	}
+	row := make([]interface{}, len(v)-1)
+	for i, z := range v[1:] {
+		if p, ok := z.(*interface{}); ok {
+			row[i] = *p
+		}
+	}
+	key := r.cur.add(row)
+	r.seen[key] = true
+	marker := ""
+	if r.prev != nil {
+		prev, ok := r.prev.rows[key]
+		switch {
+		case !ok:
+			marker = "+"
+		default:
+			for i, val := range row {
+				if diffString(val) == diffString(prev[i]) {
+					continue
+				}
+				marker = "~"
+				if p, ok := v[i+1].(*interface{}); ok {
+					*p = env.Changed{V: val}
+				}
+			}
+		}
+	}
+	setValue(v[0], marker)
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *diffRows) NextResultSet() bool {
+	if !r.ResultSet.NextResultSet() {
+		return false
+	}
+	if r.cur != nil {
+		r.set++
+	}
+	r.prev, r.cur, r.seen, r.removed, r.done = nil, nil, nil, nil, false
+	return true
+}
+
+// setValue sets the scanned value v.
+func setValue(v, val interface{}) {
+	if p, ok := v.(*interface{}); ok {
+		*p = val
+	}
+}
+
+// diffString returns the string representation of a value compared by
+// \watch --diff.
+func diffString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "\x00"
+	case []byte:
+		return string(x)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/text"
+)
+
+// xjoinMaxRows is the default maximum number of rows copied from each source
+// of \xjoin.
+const xjoinMaxRows = 100000
+
+// xjoinEngines are the drivers used for the local database of \xjoin, in
+// order of preference.
+var xjoinEngines = []string{"sqlite3", "moderncsqlite"}
+
+// XJoin copies the rows of each source into a table, named after the source,
+// of a local in-memory database, and executes the query on it, displaying the
+// result, and recording it as the last result. A source without a url is
+// fetched from the current connection.
+func (h *Handler) XJoin(ctx context.Context, sources []metacmd.XJoinSource, query string, maxRows int) error {
+	var engine string
+	for _, name := range xjoinEngines {
+		if drivers.Registered(name) {
+			engine = name
+			break
+		}
+	}
+	if engine == "" {
+		return text.ErrNoXJoinEngine
+	}
+	if maxRows == 0 {
+		maxRows = xjoinMaxRows
+	}
+	u, err := dburl.Parse(engine + "::memory:")
+	if err != nil {
+		return err
+	}
+	db, err := drivers.Open(ctx, u, h.GetOutput, h.IO().Stderr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// each connection has its own in-memory database
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, src := range sources {
+		if err := h.xjoinCopy(ctx, conn, src, maxRows); err != nil {
+			return fmt.Errorf(text.XJoinSourceFailed, src.Name, err)
+		}
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	e := new(cacheEntry)
+	if e.cols, err = rows.Columns(); err != nil {
+		return err
+	}
+	e.types, _ = rows.ColumnTypes()
+	for rows.Next() {
+		row, ptrs := make([]interface{}, len(e.cols)), make([]interface{}, len(e.cols))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		e.rows = append(e.rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return h.showResult(e)
+}
+
+// xjoinCopy copies the rows of the source into a table of the local
+// database.
+func (h *Handler) xjoinCopy(ctx context.Context, conn *sql.Conn, src metacmd.XJoinSource, maxRows int) error {
+	var db drivers.DB
+	switch {
+	case src.URL != "":
+		_, srcDB, err := h.OpenDB(ctx, src.URL)
+		if err != nil {
+			return err
+		}
+		defer srcDB.Close()
+		db = srcDB
+	case h.db == nil:
+		return text.ErrNotConnected
+	default:
+		db = h.DB()
+	}
+	query := src.Query
+	if query == "" {
+		query = "SELECT * FROM " + src.Name
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	quoted, placeholders := make([]string, len(cols)), make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i], placeholders[i] = drivers.QuoteIdentifier(nil, col), "?"
+	}
+	table := drivers.QuoteIdentifier(nil, src.Name)
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE "+table+" ("+strings.Join(quoted, ", ")+")"); err != nil {
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO "+table+" VALUES ("+strings.Join(placeholders, ", ")+")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	row, ptrs := make([]interface{}, len(cols)), make([]interface{}, len(cols))
+	for i := range row {
+		ptrs[i] = &row[i]
+	}
+	for n := 0; rows.Next(); n++ {
+		if n == maxRows {
+			return fmt.Errorf(text.XJoinTooManyRows, maxRows)
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		// store text returned as bytes as text, so that it compares equal to
+		// the text of other sources
+		for i, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[i] = string(b)
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/metacmd"
+)
+
+func TestXJoin(t *testing.T) {
+	out := new(bytes.Buffer)
+	h := newTestHandler(t, out)
+	ctx := context.Background()
+	if _, err := h.db.ExecContext(ctx, `CREATE TABLE authors (id INTEGER, name TEXT); INSERT INTO authors VALUES (1, 'one'), (2, 'two')`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// a source on another database, opened as with \connect
+	path := filepath.Join(t.TempDir(), "shop.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `CREATE TABLE orders (author_id INTEGER); INSERT INTO orders VALUES (1), (1), (2)`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sources := []metacmd.XJoinSource{
+		{Name: "authors"},
+		{Name: "orders", URL: path, Query: `SELECT author_id, COUNT(*) AS n FROM orders GROUP BY author_id`},
+	}
+	if err := h.XJoin(ctx, sources, `SELECT a.name, o.n FROM authors a JOIN orders o ON a.id = o.author_id ORDER BY a.name`, 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, exp := range []string{"one", "two", "(2 rows)"} {
+		if s := out.String(); !strings.Contains(s, exp) {
+			t.Errorf("expected output to contain %q, got: %q", exp, s)
+		}
+	}
+	if err := h.XJoin(ctx, sources, `SELECT * FROM authors`, 1); err == nil {
+		t.Errorf("expected error for a source with more rows than the maximum")
+	}
+}
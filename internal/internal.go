@@ -18,6 +18,8 @@ func KnownBuildTags() map[string]string {
 		"couchbase":     "n1ql",          // github.com/couchbase/go_n1ql
 		"csvq":          "csvq",          // github.com/mithrandie/csvq-driver
 		"databend":      "databend",      // github.com/databendcloud/databend-go
+		"databricks":    "databricks",    // github.com/databricks/databricks-sql-go
+		"druid":         "druid",         // github.com/apache/calcite-avatica-go/v5
 		"exasol":        "exasol",        // github.com/exasol/exasol-driver-go
 		"firebird":      "firebirdsql",   // github.com/nakagami/firebirdsql
 		"flightsql":     "flightsql",     // github.com/apache/arrow/go/v12/arrow/flight/flightsql/driver
@@ -42,6 +44,7 @@ func KnownBuildTags() map[string]string {
 		"ql":            "ql",            // modernc.org/ql
 		"sapase":        "tds",           // github.com/thda/tds
 		"saphana":       "hdb",           // github.com/SAP/go-hdb/driver
+		"sidecar":       "sidecar",       // github.com/ildus/usql/drivers/sidecar/wire
 		"snowflake":     "snowflake",     // github.com/snowflakedb/gosnowflake
 		"spanner":       "spanner",       // github.com/googleapis/go-sql-spanner
 		"sqlite3":       "sqlite3",       // github.com/mattn/go-sqlite3
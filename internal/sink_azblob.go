@@ -0,0 +1,7 @@
+//go:build (!no_base || azblob) && !no_azblob
+
+package internal
+
+import (
+	_ "github.com/ildus/usql/sinks/azblob" // Azure Blob Storage sink
+)
@@ -0,0 +1,7 @@
+//go:build (!no_base || gcs) && !no_gcs
+
+package internal
+
+import (
+	_ "github.com/ildus/usql/sinks/gcs" // Google Cloud Storage sink
+)
@@ -0,0 +1,7 @@
+//go:build (!no_base || kafka) && !no_kafka
+
+package internal
+
+import (
+	_ "github.com/ildus/usql/sinks/kafka" // Kafka sink
+)
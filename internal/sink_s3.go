@@ -0,0 +1,7 @@
+//go:build (!no_base || s3) && !no_s3
+
+package internal
+
+import (
+	_ "github.com/ildus/usql/sinks/s3" // Amazon S3 sink
+)
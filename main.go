@@ -38,16 +38,53 @@ func main() {
 		fmt.Fprintf(os.Stdout, "%d", out)
 		return
 	}
+	// run headless server
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		args, err := NewServeArgs(os.Args[2:])
+		if err == nil {
+			err = serve(args)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	// load current user
 	cur, err := user.Current()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	// write shell completions
+	if len(os.Args) > 1 && os.Args[1] == "__complete" {
+		if err := complete(os.Args[2:], cur); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// run migrations
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		args, err := NewMigrateArgs(os.Args[2:])
+		if err == nil {
+			err = migrate(args, cur)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			code := exitFatal
+			var ee *exitError
+			if errors.As(err, &ee) {
+				code = ee.code
+			}
+			os.Exit(code)
+		}
+		return
+	}
 	args := NewArgs()
 	// run
 	err = run(args, cur)
-	if err != nil && err != io.EOF && err != rline.ErrInterrupt {
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, rline.ErrInterrupt) {
 		var he *handler.Error
 		if !errors.As(err, &he) {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -68,8 +105,50 @@ func main() {
 			}
 			fmt.Fprintf(os.Stderr, "\ntry:\n\n  go install -tags %s github.com/ildus/usql@%s\n\n", tag, rev)
 		}
-		os.Exit(1)
+		code := exitFatal
+		var ee *exitError
+		if errors.As(err, &ee) {
+			code = ee.code
+		}
+		os.Exit(code)
+	}
+}
+
+// Exit codes, as used by psql.
+const (
+	// exitFatal is the exit code for fatal errors, such as invalid arguments,
+	// or a file that does not exist.
+	exitFatal = 1
+	// exitConnection is the exit code when the connection to the database
+	// could not be established.
+	exitConnection = 2
+	// exitScript is the exit code when a statement or command failed.
+	exitScript = 3
+)
+
+// exitError is an error with the exit code to use.
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error satisfies the error interface.
+func (e *exitError) Error() string { return e.err.Error() }
+
+// Unwrap returns the original error.
+func (e *exitError) Unwrap() error { return e.err }
+
+// scriptError wraps err as a script error, unless err is from opening a file
+// passed with -f, or the input ended or was interrupted.
+func scriptError(err error) error {
+	switch {
+	case err == text.ErrNoSuchFileOrDirectory,
+		err == text.ErrCannotIncludeDirectories,
+		errors.Is(err, io.EOF),
+		errors.Is(err, rline.ErrInterrupt):
+		return err
 	}
+	return &exitError{exitScript, err}
 }
 
 // run processes args, processing args.CommandOrFiles if non-empty, if
@@ -83,10 +162,19 @@ func run(args *Args, u *user.User) error {
 	// handle variables
 	for _, v := range args.Variables {
 		if i := strings.Index(v, "="); i != -1 {
-			_ = env.Set(v[:i], v[i+1:])
+			err = env.Set(v[:i], v[i+1:])
 		} else {
-			_ = env.Unset(v)
+			err = env.Unset(v)
 		}
+		if err != nil {
+			return fmt.Errorf("--set %s: %w", v, err)
+		}
+	}
+	switch args.OnError {
+	case "stop":
+		_ = env.Set("ON_ERROR_STOP", "on")
+	case "continue":
+		_ = env.Set("ON_ERROR_STOP", "off")
 	}
 	for _, v := range args.PVariables {
 		if i := strings.Index(v, "="); i != -1 {
@@ -115,6 +203,8 @@ func run(args *Args, u *user.User) error {
 	defer l.Close()
 	// create handler
 	h := handler.New(l, u, wd, args.NoPassword)
+	// flush \o output (and any compressed stream) on exit
+	defer h.SetOutput(nil)
 	// force a password ...
 	dsn := args.DSN
 	if args.ForcePassword {
@@ -125,7 +215,7 @@ func run(args *Args, u *user.User) error {
 	}
 	// open dsn
 	if err = h.Open(context.Background(), dsn); err != nil {
-		return err
+		return &exitError{exitConnection, err}
 	}
 	// start transaction
 	if args.SingleTransaction {
@@ -145,11 +235,11 @@ func run(args *Args, u *user.User) error {
 	// setup runner
 	f := h.Run
 	if len(args.CommandOrFiles) != 0 {
-		f = runCommandOrFiles(h, args.CommandOrFiles)
+		f = runCommandOrFiles(h, args.CommandOrFiles, args.OnError == "continue")
 	}
 	// run
 	if err = f(); err != nil {
-		return err
+		return scriptError(err)
 	}
 	// commit
 	if args.SingleTransaction {
@@ -158,22 +248,37 @@ func run(args *Args, u *user.User) error {
 	return nil
 }
 
-// runCommandOrFiles processes all the supplied commands or files.
-func runCommandOrFiles(h *handler.Handler, commandsOrFiles []CommandOrFile) func() error {
+// runCommandOrFiles processes all the supplied commands or files. When cont
+// is true, the remaining commands and files are processed after a command or
+// file fails, returning the first error.
+func runCommandOrFiles(h *handler.Handler, commandsOrFiles []CommandOrFile, cont bool) func() error {
 	return func() error {
+		var first error
 		for _, x := range commandsOrFiles {
 			h.SetSingleLineMode(x.Command)
+			var err error
 			if x.Command {
 				h.Reset([]rune(x.Value))
-				if err := h.Run(); err != nil {
-					return err
-				}
+				err = h.Run()
 			} else {
-				if err := h.Include(x.Value, false); err != nil {
-					return err
-				}
+				err = h.Include(x.Value, false)
+			}
+			switch {
+			case err == nil:
+				continue
+			case !cont:
+				return err
+			}
+			// report errors not already reported by the handler
+			var he *handler.Error
+			if !errors.As(err, &he) {
+				fmt.Fprintln(h.IO().Stderr(), "error:", err)
+				err = handler.WrapErr(x.Value, err)
+			}
+			if first == nil {
+				first = err
 			}
 		}
-		return nil
+		return first
 	}
 }
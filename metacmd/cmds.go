@@ -8,15 +8,21 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/ildus/usql/dburl"
 	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/drivers/help"
+	"github.com/ildus/usql/drivers/kerberos"
+	"github.com/ildus/usql/drivers/metadata"
 	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/rline"
 	"github.com/ildus/usql/text"
 )
 
@@ -38,6 +44,9 @@ var cmdMap map[string]Metacmd
 // sectMap is the map of sections to its respective commands.
 var sectMap map[Section][]Metacmd
 
+// xjoinSourceRE matches the NAME=[URL] sources of \xjoin.
+var xjoinSourceRE = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(\S*)$`)
+
 func init() {
 	cmds = []Cmd{
 		Question: {
@@ -67,6 +76,65 @@ func init() {
 				return nil
 			},
 		},
+		Help: {
+			Section: SectionHelp,
+			Name:    "h",
+			Desc:    Desc{"help on syntax of SQL commands, * for all commands", "[[DRIVER:]NAME]"},
+			Aliases: map[string]Desc{"help": {}},
+			Process: func(p *Params) error {
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				name := strings.Join(params, " ")
+				// determine driver, defaulting to the connected driver
+				var driver string
+				if u := p.Handler.URL(); u != nil {
+					driver = u.Driver
+				}
+				if s, rest, ok := strings.Cut(name, ":"); ok && !strings.ContainsAny(s, " \t") {
+					if d, _ := dburl.SchemeDriverAndAliases(strings.ToLower(s)); d != "" {
+						driver, name = d, strings.TrimSpace(rest)
+					}
+				}
+				names := help.Drivers()
+				switch {
+				case driver != "" && !help.Has(driver):
+					return fmt.Errorf(text.NotSupportedByDriver, "SQL syntax help", driver)
+				case driver != "":
+					names = []string{driver}
+				}
+				prefix, out := len(names) > 1, p.Handler.IO().Stdout()
+				if name == "" {
+					var topics []help.Topic
+					for _, d := range names {
+						topics = append(topics, help.Topics(d)...)
+					}
+					fmt.Fprintln(out, text.HelpAvailable)
+					help.List(out, topics, prefix)
+					return nil
+				}
+				var matches, suggestions []help.Topic
+				for _, d := range names {
+					switch topics, suggest := help.Lookup(d, name); {
+					case suggest:
+						suggestions = append(suggestions, topics...)
+					default:
+						matches = append(matches, topics...)
+					}
+				}
+				switch {
+				case len(matches) != 0:
+					help.Write(out, matches, prefix)
+				case len(suggestions) != 0:
+					fmt.Fprintf(out, text.HelpSuggestTopics+"\n", name)
+					help.List(out, suggestions, prefix)
+				default:
+					fmt.Fprintf(out, text.HelpNoTopic+"\n", name)
+				}
+				return nil
+			},
+		},
 		Quit: {
 			Section: SectionGeneral,
 			Name:    "q",
@@ -178,17 +246,89 @@ func init() {
 				return nil
 			},
 		},
+		Kill: {
+			Section: SectionConnection,
+			Name:    "kill",
+			Desc:    Desc{"terminate a session on the server (see \\dactivity)", "ID"},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				id, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case id == "":
+					return text.ErrMissingRequiredArgument
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return drivers.Kill(ctx, u, db, id)
+			},
+		},
+		Passmgr: {
+			Section: SectionConnection,
+			Name:    "passmgr",
+			Desc:    Desc{"add or remove a password entry, in the passfile or OS keychain", "add|rm [-keychain] ENTRY"},
+			Aliases: map[string]Desc{
+				"passmgr ": {"list password entries, or encrypt/decrypt the passfile", "list|encrypt|decrypt"},
+			},
+			Process: func(p *Params) error {
+				cmd, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				switch cmd {
+				case "", "list":
+					out := p.Handler.GetOutput()
+					if out == nil {
+						out = p.Handler.IO().Stdout()
+					}
+					return p.Handler.PassList(out)
+				case "encrypt", "decrypt":
+					return p.Handler.PassEncrypt(cmd == "encrypt")
+				case "add", "rm":
+				default:
+					return fmt.Errorf(text.InvalidOption, cmd)
+				}
+				ok, entry, err := p.GetOptional(true)
+				switch {
+				case err != nil:
+					return err
+				case ok && entry != "keychain":
+					return fmt.Errorf(text.InvalidOption, entry)
+				case ok:
+					if entry, err = p.Get(true); err != nil {
+						return err
+					}
+				}
+				if entry == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				if cmd == "add" {
+					return p.Handler.PassAdd(entry, ok)
+				}
+				return p.Handler.PassRemove(entry, ok)
+			},
+		},
 		Exec: {
 			Section: SectionQueryExecute,
 			Name:    "g",
 			Desc:    Desc{"execute query (and send results to file or |pipe)", "[(OPTIONS)] [FILE] or ;"},
 			Aliases: map[string]Desc{
+				"gdesc":        {"describe result of query, without executing it", ""},
 				"gexec":        {"execute query and execute each value of the result", ""},
 				"gset":         {"execute query and store results in " + text.CommandName + " variables", "[PREFIX]"},
 				"gx":           {`as \g, but forces expanded output mode`, `[(OPTIONS)] [FILE]`},
 				"G":            {`as \g, but forces vertical output mode`, `[(OPTIONS)] [FILE]`},
+				"gcsv":         {`as \g, but forces csv output format`, `[(OPTIONS)] [FILE]`},
+				"gjson":        {`as \g, but forces json output format`, `[(OPTIONS)] [FILE]`},
+				"gcount":       {`as \g, but only displays the row count`, `[(OPTIONS)] [FILE]`},
+				"gexpanded":    {`as \g, but forces expanded output mode`, `[(OPTIONS)] [FILE]`},
 				"crosstabview": {"execute query and display results in crosstab", "[(OPTIONS)] [COLUMNS]"},
-				"watch":        {"execute query every specified interval", "[(OPTIONS)] [DURATION]"},
+				"chart":        {"execute query and display results as a chart", "[(OPTIONS)] [X Y [TYPE]]"},
+				"watch":        {"execute query every specified interval", "[--diff] [DURATION]"},
 			},
 			Process: func(p *Params) error {
 				p.Option.Exec = ExecOnly
@@ -199,6 +339,8 @@ func init() {
 						return err
 					}
 					p.Option.ParseParams(params, "pipe")
+				case "gdesc":
+					p.Option.Exec = ExecDesc
 				case "gexec":
 					p.Option.Exec = ExecExec
 				case "gset":
@@ -215,29 +357,45 @@ func init() {
 					}
 					p.Option.ParseParams(params, "pipe")
 					p.Option.Params["format"] = "vertical"
-				case "gx":
+				case "gx", "gexpanded":
 					params, err := p.GetAll(true)
 					if err != nil {
 						return err
 					}
 					p.Option.ParseParams(params, "pipe")
 					p.Option.Params["expanded"] = "on"
+				case "gcsv", "gjson", "gcount":
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					p.Option.ParseParams(params, "pipe")
+					p.Option.Params["format"] = strings.TrimPrefix(p.Name, "g")
 				case "crosstabview":
 					p.Option.Exec = ExecCrosstab
-					for i := 0; i < 4; i++ {
-						ok, col, err := p.GetOK(true)
-						if err != nil {
-							return err
-						}
-						p.Option.Crosstab = append(p.Option.Crosstab, col)
-						if !ok {
-							break
-						}
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					if err := p.Option.ParseCrosstab(params); err != nil {
+						return fmt.Errorf(`\crosstabview: %w`, err)
+					}
+				case "chart":
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					if err := p.Option.ParseChart(params); err != nil {
+						return fmt.Errorf(`\chart: %w`, err)
 					}
 				case "watch":
 					p.Option.Exec = ExecWatch
 					p.Option.Watch = 2 * time.Second
 					ok, s, err := p.GetOK(true)
+					if err == nil && ok && s == "--diff" {
+						p.Option.WatchDiff = true
+						ok, s, err = p.GetOK(true)
+					}
 					switch {
 					case err != nil:
 						return err
@@ -311,6 +469,15 @@ func init() {
 				case buf.Len != 0:
 					s = buf.String()
 				}
+				if s != "" && p.Name != "raw" && env.All()["FORMAT_ON_PRINT"] == "on" {
+					opts, err := formatOptions()
+					if err != nil {
+						return err
+					}
+					if s, err = drivers.Format(p.Handler.URL(), s, opts); err != nil {
+						return err
+					}
+				}
 				switch {
 				case s == "":
 					s = text.QueryBufferEmpty
@@ -324,6 +491,40 @@ func init() {
 				return nil
 			},
 		},
+		Format: {
+			Section: SectionQueryBuffer,
+			Name:    "format",
+			Desc:    Desc{"format (pretty-print) the query buffer", ""},
+			Process: func(p *Params) error {
+				// get last statement
+				s, buf := p.Handler.Last(), p.Handler.Buf()
+				if buf.Len != 0 {
+					s = buf.String()
+				}
+				if s == "" {
+					fmt.Fprintln(p.Handler.IO().Stdout(), text.QueryBufferEmpty)
+					return nil
+				}
+				opts, err := formatOptions()
+				if err != nil {
+					return err
+				}
+				if s, err = drivers.Format(p.Handler.URL(), s, opts); err != nil {
+					return err
+				}
+				// keep the formatted statement in the buffer, without executing it
+				s = strings.TrimSpace(strings.TrimSuffix(s, ";"))
+				buf.Reset([]rune(s))
+				if p.Handler.IO().Interactive() && env.All()["SYNTAX_HL"] == "true" {
+					b := new(bytes.Buffer)
+					if p.Handler.Highlight(b, s) == nil {
+						s = b.String()
+					}
+				}
+				fmt.Fprintln(p.Handler.IO().Stdout(), s)
+				return nil
+			},
+		},
 		Reset: {
 			Section: SectionQueryBuffer,
 			Name:    "r",
@@ -420,15 +621,29 @@ func init() {
 		Timing: {
 			Section: SectionOperatingSystem,
 			Name:    "timing",
-			Desc:    Desc{"toggle timing of commands", "[on|off]"},
+			Desc:    Desc{"toggle timing of commands", "[on|off|verbose]"},
+			Aliases: map[string]Desc{
+				"timing ": {"show timing statistics of the session", "stats"},
+			},
 			Process: func(p *Params) error {
 				v, err := p.Get(true)
 				if err != nil {
 					return err
 				}
-				if v == "" {
+				switch {
+				case v == "stats":
+					out := p.Handler.GetOutput()
+					if out == nil {
+						out = p.Handler.IO().Stdout()
+					}
+					return p.Handler.TimingStats(out)
+				case v == "verbose":
+					p.Handler.SetTiming(true)
+					p.Handler.SetTimingVerbose(true)
+				case v == "":
 					p.Handler.SetTiming(!p.Handler.GetTiming())
-				} else {
+					p.Handler.SetTimingVerbose(false)
+				default:
 					s, err := env.ParseBool(v, "\\timing")
 					if err != nil {
 						stderr := p.Handler.IO().Stderr()
@@ -440,9 +655,13 @@ func init() {
 						b = true
 					}
 					p.Handler.SetTiming(b)
+					p.Handler.SetTimingVerbose(false)
 				}
 				setting := "off"
-				if p.Handler.GetTiming() {
+				switch {
+				case p.Handler.GetTimingVerbose():
+					setting = "verbose"
+				case p.Handler.GetTiming():
 					setting = "on"
 				}
 				p.Handler.Print(text.TimingSet, setting)
@@ -483,10 +702,53 @@ func init() {
 				if err != nil {
 					return err
 				}
+				if method := env.Compression(pipe); method != "" && pipe[0] != '|' {
+					if out, err = env.Compress(out, method); err != nil {
+						return err
+					}
+				}
 				p.Handler.SetOutput(out)
 				return nil
 			},
 		},
+		Tee: {
+			Section: SectionInputOutput,
+			Name:    "tee",
+			Desc:    Desc{"also write query results to file or |pipe in format (default: csv)", "[FILE [FORMAT]]"},
+			Process: func(p *Params) error {
+				p.Handler.SetTee(nil, "")
+				name, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case name == "":
+					return nil
+				}
+				format, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case format == "":
+					format = "csv"
+				}
+				switch format {
+				case "aligned", "unaligned", "csv", "json", "html", "asciidoc", "latex", "latex-longtable", "troff-ms", "vertical":
+				default:
+					return fmt.Errorf(text.TeeInvalidFormat, format)
+				}
+				var out io.WriteCloser
+				if name[0] == '|' {
+					out, _, err = env.Pipe(name[1:])
+				} else {
+					out, err = os.OpenFile(name, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+				}
+				if err != nil {
+					return err
+				}
+				p.Handler.SetTee(out, format)
+				return nil
+			},
+		},
 		Include: {
 			Section: SectionInputOutput,
 			Name:    "i",
@@ -679,6 +941,10 @@ func init() {
 				switch p.Name {
 				case "pset":
 					field = val
+					// timefmt is an alias of time
+					if field == "timefmt" {
+						field = "time"
+					}
 					ok, val, err = p.GetOK(true)
 					if err != nil {
 						return err
@@ -739,16 +1005,31 @@ func init() {
 			Name:    "d[S+]",
 			Desc:    Desc{"list tables, views, and sequences or describe table, view, sequence, or index", "[NAME]"},
 			Aliases: map[string]Desc{
-				"da[S+]": {"list aggregates", "[PATTERN]"},
-				"df[S+]": {"list functions", "[PATTERN]"},
-				"dm[S+]": {"list materialized views", "[PATTERN]"},
-				"dv[S+]": {"list views", "[PATTERN]"},
-				"ds[S+]": {"list sequences", "[PATTERN]"},
-				"dn[S+]": {"list schemas", "[PATTERN]"},
-				"dt[S+]": {"list tables", "[PATTERN]"},
-				"di[S+]": {"list indexes", "[PATTERN]"},
-				"dp[S]":  {"list table, view, and sequence access privileges", "[PATTERN]"},
-				"l[+]":   {"list databases", ""},
+				"da[S+]":       {"list aggregates", "[PATTERN]"},
+				"df[S+]":       {"list functions", "[PATTERN]"},
+				"dm[S+]":       {"list materialized views", "[PATTERN]"},
+				"dv[S+]":       {"list views", "[PATTERN]"},
+				"ds[S+]":       {"list sequences", "[PATTERN]"},
+				"dn[S+]":       {"list schemas", "[PATTERN]"},
+				"dt[S+]":       {"list tables", "[PATTERN]"},
+				"di[S+]":       {"list indexes", "[PATTERN]"},
+				"dp[S]":        {"list table, view, and sequence access privileges", "[PATTERN]"},
+				"dgs[S+]":      {"list spatial columns, SRIDs, and spatial indexes", "[PATTERN]"},
+				"dconfig[+]":   {"list server configuration parameters", "[PATTERN]"},
+				"dactivity[+]": {"list server sessions and their current queries", "[USER]"},
+				"dlocks[+]":    {"list locks and the sessions blocking other sessions", "[PATTERN]"},
+				"dsize[S+]":    {"list table (and index) sizes, row estimates, and bloat", "[PATTERN]"},
+				"det[S+]":      {"list foreign tables", "[PATTERN]"},
+				"du[S+]":       {"list roles", "[PATTERN]"},
+				"dg[S+]":       {"list roles", "[PATTERN]"},
+				"dT[S+]":       {"list data types", "[PATTERN]"},
+				"ddict[+]":     {"list dictionaries (+ with definitions)", "[PATTERN]"},
+				"dxstat[S+]":   {"list index usage statistics, flagging unused indexes", "[PATTERN]"},
+				"ddep[S]":      {"show the objects an object depends on, and its dependents", "NAME"},
+				"l[+]":         {"list databases", ""},
+				"sf[+]":        {"show a function's source (+ with line numbers)", "FUNCNAME"},
+				"qlog[+]":      {"list the most recent queries of the server's query log", "[N]"},
+				"qlog ":        {"show the details of a query of the server's query log", "id QUERY_ID"},
 			},
 			Process: func(p *Params) error {
 				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -782,6 +1063,59 @@ func init() {
 					return m.ListAllDbs(p.Handler.URL(), pattern, verbose)
 				case "dp":
 					return m.ListPrivilegeSummaries(p.Handler.URL(), pattern, showSystem)
+				case "dgs":
+					return m.ListSpatialColumns(p.Handler.URL(), pattern, verbose, showSystem)
+				case "dconfig":
+					return m.ListServerConfig(p.Handler.URL(), pattern, verbose)
+				case "dactivity":
+					return m.ListActivity(p.Handler.URL(), pattern, verbose)
+				case "dlocks":
+					return m.ListLocks(p.Handler.URL(), pattern, verbose)
+				case "dsize":
+					return m.ListSizes(p.Handler.URL(), pattern, verbose, showSystem)
+				case "det":
+					return m.ListForeignTables(p.Handler.URL(), pattern, verbose, showSystem)
+				case "du", "dg":
+					return m.ListRoles(p.Handler.URL(), pattern, verbose, showSystem)
+				case "dT":
+					return m.ListTypes(p.Handler.URL(), pattern, verbose, showSystem)
+				case "ddict":
+					return m.ListDictionaries(p.Handler.URL(), pattern, verbose)
+				case "dxstat":
+					return m.ListIndexStats(p.Handler.URL(), pattern, verbose, showSystem)
+				case "ddep":
+					if pattern == "" {
+						return text.ErrMissingRequiredArgument
+					}
+					return m.DescribeDependencies(p.Handler.URL(), pattern, showSystem)
+				case "sf":
+					// the argument types may contain spaces
+					rest, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					if pattern == "" {
+						return text.ErrMissingRequiredArgument
+					}
+					return m.ShowFunctionSource(p.Handler.URL(), strings.Join(append([]string{pattern}, rest...), " "), verbose)
+				case "qlog":
+					if pattern == "id" {
+						id, err := p.Get(true)
+						switch {
+						case err != nil:
+							return err
+						case id == "":
+							return text.ErrMissingRequiredArgument
+						}
+						return m.ListQueryLog(p.Handler.URL(), id, 0, verbose)
+					}
+					n := 20
+					if pattern != "" {
+						if n, err = strconv.Atoi(pattern); err != nil || n <= 0 {
+							return fmt.Errorf(text.QueryLogInvalidSize, pattern)
+						}
+					}
+					return m.ListQueryLog(p.Handler.URL(), "", n, verbose)
 				}
 				return nil
 			},
@@ -824,6 +1158,173 @@ func init() {
 				return m.ShowStats(p.Handler.URL(), name, pattern, verbose, k)
 			},
 		},
+		ERD: {
+			Section: SectionInformational,
+			Name:    "derd",
+			Desc:    Desc{"show tables and foreign key relationships as a diagram", "[SCHEMA] [dot|mermaid]"},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				schema, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				format, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				// the format keyword is recognized in either position, so a
+				// schema named dot or mermaid must precede the format
+				isFormat := func(s string) bool {
+					s = strings.ToLower(s)
+					return s == "dot" || s == "mermaid"
+				}
+				if isFormat(schema) && !isFormat(format) {
+					schema, format = format, schema
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				r, err := drivers.NewMetadataReader(ctx, u, db, out)
+				if err != nil {
+					return err
+				}
+				err = metadata.WriteERD(out, r, schema, strings.ToLower(format))
+				if err == text.ErrNotSupported {
+					return fmt.Errorf(text.NotSupportedByDriver, `\derd`, u.Driver)
+				}
+				return err
+			},
+		},
+		Join: {
+			Section: SectionInformational,
+			Name:    "djoin",
+			Desc:    Desc{"show JOIN clauses for the foreign keys of a table", "TABLE"},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				name, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case name == "":
+					return text.ErrMissingRequiredArgument
+				}
+				schema, table, ok := strings.Cut(name, ".")
+				if !ok {
+					schema, table = "", name
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				r, err := drivers.NewMetadataReader(ctx, u, db, out)
+				if err != nil {
+					return err
+				}
+				err = metadata.WriteJoins(out, r, schema, table, func(s string) string {
+					return drivers.QuoteIdentifier(u, s)
+				})
+				if err == text.ErrNotSupported {
+					return fmt.Errorf(text.NotSupportedByDriver, `\djoin`, u.Driver)
+				}
+				return err
+			},
+		},
+		RunScript: {
+			Section: SectionInputOutput,
+			Name:    "run",
+			Desc:    Desc{"execute statements from file (options: --single-transaction, --savepoint-per-statement, --retry-serialization N)", "[OPTIONS] FILE"},
+			Process: func(p *Params) error {
+				var opts RunOptions
+				var path string
+				for path == "" {
+					ok, n, err := p.GetOptional(true)
+					switch {
+					case err != nil:
+						return err
+					case !ok && n == "":
+						return text.ErrMissingRequiredArgument
+					case !ok:
+						path = n
+						continue
+					}
+					switch n = strings.TrimPrefix(n, "-"); n {
+					case "single-transaction":
+						opts.SingleTransaction = true
+					case "savepoint-per-statement":
+						opts.SavepointPerStatement = true
+					case "retry-serialization":
+						v, err := p.Get(true)
+						if err != nil {
+							return err
+						}
+						if opts.RetrySerialization, err = strconv.Atoi(v); err != nil || opts.RetrySerialization < 0 {
+							return fmt.Errorf(text.InvalidValue, "-"+n, v, "must be a non-negative integer")
+						}
+					default:
+						return fmt.Errorf(text.InvalidOption, "--"+n)
+					}
+				}
+				return p.Handler.RunScript(path, opts)
+			},
+		},
+		Cache: {
+			Section: SectionQueryExecute,
+			Name:    "cache",
+			Desc:    Desc{"toggle caching of query results, with time to live", "[on|off] [TTL]"},
+			Aliases: map[string]Desc{
+				"cache ": {"clear cached query results, or show cache statistics", "clear|stats"},
+			},
+			Process: func(p *Params) error {
+				v, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				switch v {
+				case "clear":
+					p.Handler.ClearCache()
+					p.Handler.Print(text.CacheCleared)
+					return nil
+				case "stats":
+					out := p.Handler.GetOutput()
+					if out == nil {
+						out = p.Handler.IO().Stdout()
+					}
+					return p.Handler.CacheStats(out)
+				case "":
+					v = "on"
+				}
+				s, err := env.ParseBool(v, `\cache`)
+				if err != nil {
+					return err
+				}
+				var ttl time.Duration
+				if s == "on" {
+					ok, t, err := p.GetOK(true)
+					switch {
+					case err != nil:
+						return err
+					case ok:
+						if ttl, err = time.ParseDuration(t); err != nil || ttl <= 0 {
+							return fmt.Errorf(text.CacheInvalidTTL, t)
+						}
+					}
+				}
+				p.Handler.SetCache(s == "on", ttl)
+				p.Handler.Print(text.CacheSet, s)
+				return nil
+			},
+		},
 		Copy: {
 			Section: SectionInputOutput,
 			Name:    "copy",
@@ -833,7 +1334,6 @@ func init() {
 			},
 			Process: func(p *Params) error {
 				ctx := context.Background()
-				stdout, stderr := p.Handler.IO().Stdout, p.Handler.IO().Stderr
 				srcDsn, err := p.Get(true)
 				if err != nil {
 					return err
@@ -858,25 +1358,49 @@ func init() {
 				if err != nil {
 					return err
 				}
-				src, err := drivers.Open(ctx, srcURL, stdout, stderr)
+				ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+				defer cancel()
+				n, err := p.Handler.Copy(ctx, srcURL, destURL, query, table)
 				if err != nil {
 					return err
 				}
-				defer src.Close()
-				dest, err := drivers.Open(ctx, destURL, stdout, stderr)
-				if err != nil {
+				p.Handler.Print("COPY %d", n)
+				return nil
+			},
+		},
+		CopyIn: {
+			Section: SectionInputOutput,
+			Name:    "copyin",
+			Desc:    Desc{"copy data from input or stdin into table (options: csv, text, header)", "TABLE [OPTIONS]"},
+			Aliases: map[string]Desc{
+				"copyin": {"copy data from input or stdin into columns of table", "TABLE(A,...) [OPTIONS]"},
+			},
+			Process: func(p *Params) error {
+				table, err := p.Get(true)
+				switch {
+				case err != nil:
 					return err
+				case table == "":
+					return text.ErrMissingRequiredArgument
 				}
-				defer dest.Close()
-				ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
-				defer cancel()
-				// get the result set
-				r, err := src.QueryContext(ctx, query)
+				params, err := p.GetAll(true)
 				if err != nil {
 					return err
 				}
-				defer r.Close()
-				n, err := drivers.Copy(ctx, destURL, stdout, stderr, r, table)
+				var opts drivers.CopyInOptions
+				for _, s := range params {
+					switch s = strings.ToLower(s); s {
+					case "csv", "text":
+						opts.Format = s
+					case "header":
+						opts.Header = true
+					default:
+						return fmt.Errorf(text.InvalidOption, s)
+					}
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				n, err := p.Handler.CopyIn(ctx, table, opts)
 				if err != nil {
 					return err
 				}
@@ -884,13 +1408,863 @@ func init() {
 				return nil
 			},
 		},
-	}
-	// set up map
-	cmdMap = make(map[string]Metacmd, len(cmds))
-	sectMap = make(map[Section][]Metacmd, len(SectionOrder))
-	for i, c := range cmds {
-		mc := Metacmd(i)
-		if mc == None {
+		CopyTo: {
+			Section: SectionInputOutput,
+			Name:    "copyto",
+			Desc:    Desc{"execute query and stream results to object storage or kafka url, or file (options: format FMT, header, gzip)", "URL [OPTIONS]"},
+			Process: func(p *Params) error {
+				dest, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case dest == "":
+					return text.ErrMissingRequiredArgument
+				}
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				format, header, compression := "csv", false, ""
+				for i := 0; i < len(params); i++ {
+					switch s := strings.ToLower(params[i]); s {
+					case "format":
+						if i++; i == len(params) {
+							return text.ErrMissingRequiredArgument
+						}
+						format = strings.ToLower(params[i])
+					case "csv", "text", "json":
+						format = s
+					case "header":
+						header = true
+					case "gzip":
+						compression = s
+					default:
+						return fmt.Errorf(text.InvalidOption, params[i])
+					}
+				}
+				p.Option.Exec = ExecOnly
+				p.Option.Params = map[string]string{
+					"pipe":        dest,
+					"format":      format,
+					"compression": compression,
+				}
+				switch format {
+				case "csv":
+				case "text":
+					// tab separated, as with COPY
+					p.Option.Params["format"] = "unaligned"
+					p.Option.Params["fieldsep"] = "\t"
+					p.Option.Params["footer"] = "off"
+				case "json":
+					return nil
+				default:
+					return fmt.Errorf(text.CopyToInvalidFormat, format)
+				}
+				if !header {
+					p.Option.Params["tuples_only"] = "on"
+				}
+				return nil
+			},
+		},
+		Seed: {
+			Section: SectionInputOutput,
+			Name:    "seed",
+			Desc:    Desc{"insert rows of synthetic data into table (options: --dry-run, --seed N, --batch N)", "[OPTIONS] TABLE N [SPEC]..."},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				opts := drivers.SeedOptions{
+					Seed:  time.Now().UnixNano(),
+					Specs: make(map[string]string),
+				}
+				var table string
+				for table == "" {
+					ok, n, err := p.GetOptional(true)
+					switch {
+					case err != nil:
+						return err
+					case !ok && n == "":
+						return text.ErrMissingRequiredArgument
+					case !ok:
+						table = n
+						continue
+					}
+					switch n = strings.TrimPrefix(n, "-"); n {
+					case "dry-run":
+						opts.DryRun = true
+					case "seed", "batch":
+						v, err := p.Get(true)
+						if err != nil {
+							return err
+						}
+						i, err := strconv.ParseInt(v, 10, 64)
+						switch {
+						case err != nil:
+							return fmt.Errorf(text.InvalidValue, "-"+n, v, "must be an integer")
+						case n == "seed":
+							opts.Seed = i
+						case i <= 0:
+							return fmt.Errorf(text.InvalidValue, "-"+n, v, "must be a positive integer")
+						default:
+							opts.BatchSize = int(i)
+						}
+					default:
+						return fmt.Errorf(text.InvalidOption, "--"+n)
+					}
+				}
+				rows, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case rows == "":
+					return text.ErrMissingRequiredArgument
+				}
+				if opts.Rows, err = strconv.Atoi(rows); err != nil || opts.Rows < 0 {
+					return text.ErrInvalidValue
+				}
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				for _, s := range params {
+					col, spec, ok := strings.Cut(s, "=")
+					if !ok || col == "" {
+						return fmt.Errorf(text.InvalidOption, s)
+					}
+					opts.Specs[col] = spec
+				}
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				n, err := drivers.Seed(ctx, u, db, out, table, opts)
+				if err != nil {
+					return err
+				}
+				if !opts.DryRun {
+					p.Handler.Print(text.SeedSummary, n)
+				}
+				return nil
+			},
+		},
+		QueryID: {
+			Section: SectionQueryExecute,
+			Name:    "qid",
+			Desc:    Desc{"show the server query id of the last query", "[last]"},
+			Process: func(p *Params) error {
+				v, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case v != "" && v != "last":
+					return text.ErrInvalidValue
+				}
+				if id := p.Handler.LastQueryID(); id != "" {
+					p.Handler.Print("%s", id)
+				} else {
+					p.Handler.Print(text.NoQueryID)
+				}
+				return nil
+			},
+		},
+		QueryResult: {
+			Section: SectionQueryExecute,
+			Name:    "qresult",
+			Desc:    Desc{"retrieve the results, or status, of a query by server query id", "ID"},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				id, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case id == "":
+					return text.ErrMissingRequiredArgument
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				rows, err := drivers.QueryResult(ctx, u, db, id)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				return env.EncodeAll(out, rows, env.Pall())
+			},
+		},
+		Bind: {
+			Section: SectionQueryExecute,
+			Name:    "bind",
+			Desc:    Desc{"set query parameters ($1, $2, ...) for the next query", "[PARAM]..."},
+			Process: func(p *Params) error {
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				p.Handler.Bind(params)
+				return nil
+			},
+		},
+		Prepare: {
+			Section: SectionQueryExecute,
+			Name:    "prepare",
+			Desc:    Desc{"prepare a query (or the query buffer) for repeated execution", "NAME [QUERY]"},
+			Aliases: map[string]Desc{
+				"execute":    {"execute a prepared statement with parameters ($1, $2, ...)", "NAME [PARAM]..."},
+				"deallocate": {"deallocate a prepared statement, or all prepared statements", "NAME|all"},
+				"dprep":      {"list prepared statements", ""},
+			},
+			Process: func(p *Params) error {
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				if p.Name == "dprep" {
+					return p.Handler.ListPrepared(out)
+				}
+				name, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case name == "":
+					return text.ErrMissingRequiredArgument
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				switch p.Name {
+				case "execute":
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					return p.Handler.ExecutePrepared(ctx, out, name, params)
+				case "deallocate":
+					return p.Handler.Deallocate(name)
+				}
+				query := strings.TrimSpace(p.GetRaw())
+				if query == "" {
+					// use current statement buf if not empty
+					if buf := p.Handler.Buf(); buf.Len != 0 {
+						query = buf.String()
+						buf.Reset(nil)
+					}
+				}
+				query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+				if query == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				return p.Handler.Prepare(ctx, name, query)
+			},
+		},
+		Explain: {
+			Section: SectionQueryExecute,
+			Name:    "explain",
+			Desc:    Desc{"show the query plan of a query (or the query buffer) as a tree", "[analyze] [QUERY]"},
+			Process: func(p *Params) error {
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
+					return text.ErrNotConnected
+				}
+				query, analyze := strings.TrimSpace(p.GetRaw()), false
+				if fields := strings.Fields(query); len(fields) != 0 && strings.EqualFold(fields[0], "analyze") {
+					query, analyze = strings.TrimSpace(query[len(fields[0]):]), true
+				}
+				if query == "" {
+					// use current statement buf if not empty
+					query = p.Handler.Last()
+					if buf := p.Handler.Buf(); buf.Len != 0 {
+						query = buf.String()
+					}
+				}
+				query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+				if query == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				plan, err := drivers.Explain(ctx, u, db, query, analyze)
+				if err != nil {
+					return err
+				}
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = p.Handler.IO().Stdout()
+				}
+				_, err = plan.WriteTo(out)
+				return err
+			},
+		},
+		Kerberos: {
+			Section: SectionConnection,
+			Name:    "krb",
+			Desc:    Desc{"display the Kerberos ticket cache state used by auth=gssapi", ""},
+			Process: func(p *Params) error {
+				c, err := kerberos.LoadCache()
+				if err != nil {
+					if os.IsNotExist(err) {
+						p.Handler.Print(text.KrbNoTickets, kerberos.CCachePath())
+						return nil
+					}
+					return err
+				}
+				p.Handler.Print(text.KrbTicketCache, c.Path)
+				p.Handler.Print(text.KrbDefaultPrincipal, c.Principal)
+				if len(c.Tickets) == 0 {
+					return nil
+				}
+				out := p.Handler.IO().Stdout()
+				w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+				fmt.Fprintln(out)
+				fmt.Fprintln(w, "Valid starting\tExpires\tService principal")
+				const layout = "2006-01-02 15:04:05"
+				for _, t := range c.Tickets {
+					expires := t.EndTime.Local().Format(layout)
+					if t.Expired() {
+						expires += " (expired)"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\n", t.StartTime.Local().Format(layout), expires, t.Server)
+				}
+				return w.Flush()
+			},
+		},
+		Diff: {
+			Section: SectionInputOutput,
+			Name:    "diff",
+			Desc:    Desc{"show row differences between query results on source and destination urls", "SRC DST QUERY1 QUERY2 [KEYS]"},
+			Process: func(p *Params) error {
+				ctx := context.Background()
+				stdout := p.Handler.IO().Stdout
+				var dbs [2]drivers.DB
+				for i := range dbs {
+					dsn, err := p.Get(true)
+					if err != nil {
+						return err
+					}
+					// an empty url uses the current connection
+					if dsn == "" {
+						if dbs[i] = p.Handler.DB(); dbs[i] == nil {
+							return text.ErrNotConnected
+						}
+						continue
+					}
+					_, db, err := p.Handler.OpenDB(ctx, dsn)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+					dbs[i] = db
+				}
+				srcQuery, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				dstQuery, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				if srcQuery == "" || dstQuery == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				var keys []string
+				for {
+					ok, k, err := p.GetOK(true)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						break
+					}
+					for _, s := range strings.Split(k, ",") {
+						if s = strings.TrimSpace(s); s != "" {
+							keys = append(keys, s)
+						}
+					}
+				}
+				ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+				defer cancel()
+				out := p.Handler.GetOutput()
+				if out == nil {
+					out = stdout()
+				}
+				stats, err := drivers.Diff(ctx, dbs[0], dbs[1], srcQuery, dstQuery, keys, func(r drivers.DiffRow) error {
+					vals := make([]string, len(r.Columns))
+					for i, c := range r.Columns {
+						vals[i] = c + "=" + r.Values[i]
+					}
+					line := string(r.Kind) + " " + strings.Join(vals, ", ")
+					if len(r.Changed) != 0 {
+						line += " (changed: " + strings.Join(r.Changed, ", ") + ")"
+					}
+					_, err := fmt.Fprintln(out, line)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				p.Handler.Print(text.DiffSummary, stats.Added, stats.Removed, stats.Changed, stats.Same)
+				return nil
+			},
+		},
+		If: {
+			Section: SectionConditional,
+			Name:    "if",
+			Desc:    Desc{"begin conditional block", "EXPR"},
+			Process: func(p *Params) error {
+				buf := p.Handler.Buf()
+				if !buf.Active() {
+					// expression is not evaluated in inactive branches
+					p.Params.GetRaw()
+					buf.If(false)
+					return nil
+				}
+				v, err := condExpr(p)
+				buf.If(v)
+				return err
+			},
+		},
+		Elif: {
+			Section: SectionConditional,
+			Name:    "elif",
+			Desc:    Desc{"alternative within current conditional block", "EXPR"},
+			Process: func(p *Params) error {
+				buf := p.Handler.Buf()
+				var v bool
+				var exprErr error
+				if buf.ElifNeedsValue() {
+					v, exprErr = condExpr(p)
+				} else {
+					p.Params.GetRaw()
+				}
+				if err := buf.Elif(v); err != nil {
+					return fmt.Errorf(`\elif: %w`, err)
+				}
+				return exprErr
+			},
+		},
+		Else: {
+			Section: SectionConditional,
+			Name:    "else",
+			Desc:    Desc{"final alternative within current conditional block", ""},
+			Process: func(p *Params) error {
+				if err := p.Handler.Buf().Else(); err != nil {
+					return fmt.Errorf(`\else: %w`, err)
+				}
+				return nil
+			},
+		},
+		Endif: {
+			Section: SectionConditional,
+			Name:    "endif",
+			Desc:    Desc{"end conditional block", ""},
+			Process: func(p *Params) error {
+				if err := p.Handler.Buf().Endif(); err != nil {
+					return fmt.Errorf(`\endif: %w`, err)
+				}
+				return nil
+			},
+		},
+		While: {
+			Section: SectionConditional,
+			Name:    "while",
+			Desc:    Desc{"begin loop block, repeated while expression is true", "EXPR"},
+			Process: func(p *Params) error {
+				buf := p.Handler.Buf()
+				// expression is re-evaluated on each iteration
+				expr := string(p.Params.R[:p.Params.Len])
+				if !buf.Active() {
+					p.Params.GetRaw()
+					buf.While(false, expr)
+					return nil
+				}
+				v, err := condExpr(p)
+				buf.While(v, expr)
+				return err
+			},
+		},
+		Endwhile: {
+			Section: SectionConditional,
+			Name:    "endwhile",
+			Desc:    Desc{"end loop block", ""},
+			Process: func(p *Params) error {
+				if err := p.Handler.Buf().Endwhile(); err != nil {
+					return fmt.Errorf(`\endwhile: %w`, err)
+				}
+				return nil
+			},
+		},
+		Sleep: {
+			Section: SectionConditional,
+			Name:    "sleep",
+			Desc:    Desc{"wait for the specified duration (in seconds, or e.g. 500ms)", "DURATION"},
+			Process: func(p *Params) error {
+				s, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case s == "":
+					return text.ErrMissingRequiredArgument
+				}
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					f, ferr := strconv.ParseFloat(s, 64)
+					if ferr != nil || f < 0 {
+						return fmt.Errorf(text.InvalidSleepDuration, s)
+					}
+					d = time.Duration(f * float64(time.Second))
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				t := time.NewTimer(d)
+				defer t.Stop()
+				select {
+				case <-t.C:
+					return nil
+				case <-ctx.Done():
+					// stop any loops being run
+					p.Handler.Buf().Break()
+					return rline.ErrInterrupt
+				}
+			},
+		},
+		Browse: {
+			Section: SectionQueryExecute,
+			Name:    "browse",
+			Desc:    Desc{"browse the last query result interactively", ""},
+			Process: func(p *Params) error {
+				return p.Handler.Browse()
+			},
+		},
+		Import: {
+			Section: SectionInputOutput,
+			Name:    "import",
+			Desc:    Desc{"copy data from file into table (options: --create, csv, text, header)", "FILE TABLE [OPTIONS]"},
+			Process: func(p *Params) error {
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				// --create may precede the file
+				var create bool
+				if len(params) != 0 && params[0] == "--create" {
+					create, params = true, params[1:]
+				}
+				if len(params) < 2 {
+					return text.ErrMissingRequiredArgument
+				}
+				var opts drivers.CopyInOptions
+				for _, s := range params[2:] {
+					switch s = strings.ToLower(s); s {
+					case "--create":
+						create = true
+					case "csv", "text":
+						opts.Format = s
+					case "header":
+						opts.Header = true
+					default:
+						return fmt.Errorf(text.InvalidOption, s)
+					}
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				n, err := p.Handler.Import(ctx, params[0], params[1], create, opts)
+				if err != nil {
+					return err
+				}
+				p.Handler.Print("COPY %d", n)
+				return nil
+			},
+		},
+		Unmask: {
+			Section: SectionFormatting,
+			Name:    "unmask",
+			Desc:    Desc{"toggle display of masked columns unmasked", "[on|off]"},
+			Process: func(p *Params) error {
+				v, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				cur, _ := env.Pget("mask")
+				unmask := cur == "on"
+				if v != "" {
+					s, err := env.ParseBool(v, "\\unmask")
+					if err != nil {
+						return err
+					}
+					unmask = s == "on"
+				}
+				mask, setting := "on", "off"
+				if unmask {
+					mask, setting = "off", "on"
+				}
+				if _, err := env.Pset("mask", mask); err != nil {
+					return err
+				}
+				p.Handler.Print(text.UnmaskSet, setting)
+				return nil
+			},
+		},
+		Filter: {
+			Section: SectionQueryExecute,
+			Name:    "filter",
+			Desc:    Desc{"filter the rows of the last query result", "EXPR"},
+			Process: func(p *Params) error {
+				s := strings.TrimSpace(p.GetRaw())
+				if s == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				return p.Handler.FilterResult(s)
+			},
+		},
+		Map: {
+			Section: SectionQueryExecute,
+			Name:    "map",
+			Desc:    Desc{"select and compute columns of the last query result", "EXPR [AS NAME], ..."},
+			Process: func(p *Params) error {
+				s := strings.TrimSpace(p.GetRaw())
+				if s == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				return p.Handler.MapResult(s)
+			},
+		},
+		Store: {
+			Section: SectionQueryExecute,
+			Name:    "store",
+			Desc:    Desc{"store the last query result, referenced as ::NAME in statements", "[NAME]"},
+			Process: func(p *Params) error {
+				name, err := p.Get(false)
+				switch {
+				case err != nil:
+					return err
+				case name == "":
+					out := p.Handler.GetOutput()
+					if out == nil {
+						out = p.Handler.IO().Stdout()
+					}
+					return p.Handler.StoreList(out)
+				}
+				return p.Handler.Store(name)
+			},
+		},
+		XJoin: {
+			Section: SectionQueryExecute,
+			Name:    "xjoin",
+			Desc:    Desc{"execute query on tables copied from connections (options: --max-rows N)", "NAME=[URL] [QUERY]... QUERY"},
+			Process: func(p *Params) error {
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				var maxRows int
+				for len(params) != 0 && strings.HasPrefix(params[0], "--") {
+					switch params[0] {
+					case "--max-rows":
+						if len(params) < 2 {
+							return text.ErrMissingRequiredArgument
+						}
+						if maxRows, err = strconv.Atoi(params[1]); err != nil || maxRows <= 0 {
+							return fmt.Errorf(text.InvalidOption, "--max-rows "+params[1])
+						}
+						params = params[2:]
+					default:
+						return fmt.Errorf(text.InvalidOption, params[0])
+					}
+				}
+				if len(params) < 2 {
+					return text.ErrMissingRequiredArgument
+				}
+				// the last parameter is the query, with each source followed
+				// by its optional query
+				var sources []XJoinSource
+				for _, s := range params[:len(params)-1] {
+					if m := xjoinSourceRE.FindStringSubmatch(s); m != nil {
+						sources = append(sources, XJoinSource{Name: m[1], URL: m[2]})
+						continue
+					}
+					if len(sources) == 0 || sources[len(sources)-1].Query != "" {
+						return fmt.Errorf(text.XJoinInvalidSource, s)
+					}
+					sources[len(sources)-1].Query = s
+				}
+				if len(sources) == 0 {
+					return text.ErrMissingRequiredArgument
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return p.Handler.XJoin(ctx, sources, params[len(params)-1], maxRows)
+			},
+		},
+		Sample: {
+			Section: SectionQueryExecute,
+			Name:    "sample",
+			Desc:    Desc{"display a random sample of the rows of a table", "TABLE [N]"},
+			Process: func(p *Params) error {
+				table, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case table == "":
+					return text.ErrMissingRequiredArgument
+				}
+				n := 10
+				s, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case s != "":
+					if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+						return fmt.Errorf(text.InvalidSampleSize, s)
+					}
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return p.Handler.Sample(ctx, table, n)
+			},
+		},
+		Comment: {
+			Section: SectionQueryExecute,
+			Name:    "comment",
+			Desc:    Desc{"set the comment of a table or column (TYPE table or column)", "on TYPE NAME is 'TEXT'"},
+			Process: func(p *Params) error {
+				params, err := p.GetAll(true)
+				switch {
+				case err != nil:
+					return err
+				case len(params) == 0:
+					return text.ErrMissingRequiredArgument
+				case len(params) != 5 || !strings.EqualFold(params[0], "on") || !strings.EqualFold(params[3], "is"):
+					return text.ErrInvalidComment
+				}
+				typ, name := strings.ToUpper(params[1]), params[2]
+				switch {
+				case typ != "TABLE" && typ != "COLUMN":
+					return text.ErrInvalidComment
+				case typ == "COLUMN" && !strings.Contains(name, "."):
+					return fmt.Errorf(text.CommentInvalidColumn, name)
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return p.Handler.Comment(ctx, typ, name, params[4])
+			},
+		},
+		Route: {
+			Section: SectionConnection,
+			Name:    "route",
+			Desc:    Desc{"show or set the routing of statements to the read replica", "[auto|primary|replica]"},
+			Aliases: map[string]Desc{
+				"route ": {"connect to, or disconnect from, the read replica database url", "connect DSN|disconnect"},
+			},
+			Process: func(p *Params) error {
+				v, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				switch v {
+				case "":
+					out := p.Handler.GetOutput()
+					if out == nil {
+						out = p.Handler.IO().Stdout()
+					}
+					return p.Handler.RouteStatus(out)
+				case "connect":
+					dsn, err := p.Get(true)
+					switch {
+					case err != nil:
+						return err
+					case dsn == "":
+						return text.ErrMissingRequiredArgument
+					}
+					ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+					defer cancel()
+					if err := p.Handler.ConnectReplica(ctx, dsn); err != nil {
+						return err
+					}
+					p.Handler.Print(text.RouteConnected)
+					return nil
+				case "disconnect":
+					if err := p.Handler.CloseReplica(); err != nil {
+						return err
+					}
+					p.Handler.Print(text.RouteDisconnected)
+					return nil
+				case "auto", "primary", "replica":
+					p.Handler.SetRoute(v)
+					p.Handler.Print(text.RouteSet, v)
+					return nil
+				}
+				return fmt.Errorf(text.RouteInvalid, v)
+			},
+		},
+		Migrate: {
+			Section: SectionInputOutput,
+			Name:    "migrate",
+			Desc:    Desc{"apply pending, or revert applied, migrations (options: --dir DIR, --table NAME, --dry-run)", "[OPTIONS] up|down [N]"},
+			Aliases: map[string]Desc{
+				"migrate ": {"list migrations and their status", "[OPTIONS] status"},
+			},
+			Process: func(p *Params) error {
+				var opts MigrateOptions
+				var action string
+				for action == "" {
+					ok, n, err := p.GetOptional(true)
+					switch {
+					case err != nil:
+						return err
+					case !ok && n == "":
+						return text.ErrMissingRequiredArgument
+					case !ok:
+						action = n
+						continue
+					}
+					switch n = strings.TrimPrefix(n, "-"); n {
+					case "dir", "table":
+						v, err := p.Get(true)
+						switch {
+						case err != nil:
+							return err
+						case v == "":
+							return text.ErrMissingRequiredArgument
+						case n == "dir":
+							opts.Dir = v
+						default:
+							opts.Table = v
+						}
+					case "dry-run":
+						opts.DryRun = true
+					default:
+						return fmt.Errorf(text.InvalidOption, "--"+n)
+					}
+				}
+				v, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case v != "":
+					if opts.Steps, err = strconv.Atoi(v); err != nil || opts.Steps <= 0 {
+						return fmt.Errorf(text.MigrateBadSteps, v)
+					}
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return p.Handler.Migrate(ctx, action, opts)
+			},
+		},
+	}
+	// set up map
+	cmdMap = make(map[string]Metacmd, len(cmds))
+	sectMap = make(map[Section][]Metacmd, len(SectionOrder))
+	for i, c := range cmds {
+		mc := Metacmd(i)
+		if mc == None {
 			continue
 		}
 		name := c.Name
@@ -921,3 +2295,45 @@ func init() {
 		sectMap[c.Section] = append(sectMap[c.Section], mc)
 	}
 }
+
+// formatOptions returns the options for formatting statements, using the
+// FORMAT_INDENT and FORMAT_KEYWORD_CASE variables.
+func formatOptions() (drivers.FormatOptions, error) {
+	vars := env.All()
+	opts := drivers.FormatOptions{
+		Indent:      "\t",
+		KeywordCase: vars["FORMAT_KEYWORD_CASE"],
+	}
+	if s := vars["FORMAT_INDENT"]; s != "tab" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf(text.FormatFieldInvalidValue, s, "FORMAT_INDENT", "non-negative integer or tab")
+		}
+		opts.Indent = strings.Repeat(" ", n)
+	}
+	switch opts.KeywordCase {
+	case "upper", "lower", "preserve":
+	default:
+		return opts, fmt.Errorf(text.FormatFieldInvalidValue, opts.KeywordCase, "FORMAT_KEYWORD_CASE", "upper, lower, or preserve")
+	}
+	return opts, nil
+}
+
+// condExpr evaluates the boolean expression of a conditional block command.
+func condExpr(p *Params) (bool, error) {
+	vals, err := p.GetAll(true)
+	if err != nil {
+		return false, err
+	}
+	expr := strings.Join(vals, " ")
+	switch strings.ToLower(expr) {
+	case "":
+		return false, text.ErrMissingRequiredArgument
+	case "y", "ye", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	v, err := env.ParseBool(expr, `\`+p.Name+` expression`)
+	return v == "on", err
+}
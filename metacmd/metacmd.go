@@ -28,6 +28,17 @@ func Decode(name string, params *stmt.Params) (Runner, error) {
 	}), nil
 }
 
+// IsConditional returns whether name is a conditional block command (\if,
+// \elif, \else, \endif, \while, or \endwhile), which are processed even when
+// in an inactive branch of a conditional block.
+func IsConditional(name string) bool {
+	switch cmdMap[name] {
+	case If, Elif, Else, Endif, While, Endwhile:
+		return true
+	}
+	return false
+}
+
 // Command types.
 const (
 	// None is an empty command.
@@ -88,4 +99,79 @@ const (
 	Timing
 	// Stats is the show stats meta command (\ss and variants).
 	Stats
+	// Explain is the query plan meta command (\explain).
+	Explain
+	// Kerberos is the kerberos ticket state meta command (\krb).
+	Kerberos
+	// Diff is the result diff meta command (\diff).
+	Diff
+	// If is the conditional block meta command (\if).
+	If
+	// Elif is the conditional block else if meta command (\elif).
+	Elif
+	// Else is the conditional block else meta command (\else).
+	Else
+	// Endif is the conditional block end meta command (\endif).
+	Endif
+	// ERD is the entity relationship diagram meta command (\derd).
+	ERD
+	// RunScript is the run script meta command (\run).
+	RunScript
+	// Cache is the result cache meta command (\cache).
+	Cache
+	// Join is the foreign key join suggestion meta command (\djoin).
+	Join
+	// CopyIn is the copy from input meta command (\copyin).
+	CopyIn
+	// Format is the format query buffer meta command (\format).
+	Format
+	// Kill is the kill session meta command (\kill).
+	Kill
+	// Bind is the bind parameters meta command (\bind).
+	Bind
+	// Prepare is the prepared statement meta command (\prepare, \execute,
+	// \deallocate, \dprep).
+	Prepare
+	// Tee is the tee output meta command (\tee).
+	Tee
+	// Passmgr is the password entry manager meta command (\passmgr).
+	Passmgr
+	// Seed is the synthetic data meta command (\seed).
+	Seed
+	// Help is the SQL syntax help meta command (\h, \help).
+	Help
+	// QueryID is the server query id meta command (\qid).
+	QueryID
+	// QueryResult is the query result retrieval meta command (\qresult).
+	QueryResult
+	// CopyTo is the copy to object storage meta command (\copyto).
+	CopyTo
+	// While is the loop block meta command (\while).
+	While
+	// Endwhile is the loop block end meta command (\endwhile).
+	Endwhile
+	// Sleep is the sleep meta command (\sleep).
+	Sleep
+	// Browse is the interactive result browser meta command (\browse).
+	Browse
+	// Import is the file import meta command (\import).
+	Import
+	// Unmask is the column unmasking meta command (\unmask).
+	Unmask
+	// Filter is the result filter meta command (\filter).
+	Filter
+	// Map is the result map meta command (\map).
+	Map
+	// Store is the result store meta command (\store).
+	Store
+	// XJoin is the cross connection query meta command (\xjoin).
+	XJoin
+	// Sample is the table sample meta command (\sample).
+	Sample
+	// Comment is the comment meta command (\comment).
+	Comment
+	// Route is the statement routing meta command (\route).
+	Route
+	// Migrate is the schema migration meta command (\migrate).
+	Migrate
 )
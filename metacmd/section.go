@@ -24,6 +24,7 @@ const (
 	SectionHelp            Section = "Help"
 	SectionTransaction     Section = "Transaction"
 	SectionInputOutput     Section = "Input/Output"
+	SectionConditional     Section = "Conditional"
 	SectionInformational   Section = "Informational"
 	SectionFormatting      Section = "Formatting"
 	SectionConnection      Section = "Connection"
@@ -40,7 +41,7 @@ func (s Section) String() string {
 // SectionOrder is the order of sections to display via Listing.
 var SectionOrder = []Section{
 	SectionGeneral, SectionQueryExecute, SectionQueryBuffer, SectionHelp,
-	SectionInputOutput, SectionInformational, SectionFormatting,
+	SectionInputOutput, SectionConditional, SectionInformational, SectionFormatting,
 	SectionTransaction,
 	SectionConnection, SectionOperatingSystem, SectionVariables,
 }
@@ -65,7 +66,11 @@ func Listing(w io.Writer) {
 				aliases = append(aliases, alias)
 			}
 			sort.Slice(aliases, func(i, j int) bool {
-				return strings.ToLower(aliases[i]) < strings.ToLower(aliases[j])
+				a, b := strings.ToLower(aliases[i]), strings.ToLower(aliases[j])
+				if a == b {
+					return aliases[i] < aliases[j]
+				}
+				return a < b
 			})
 			for _, alias := range aliases {
 				s, opts := optText(cmd.Aliases[alias])
@@ -31,6 +31,8 @@ type Handler interface {
 	Last() string
 	// LastRaw returns the last raw (non-interpolated) query.
 	LastRaw() string
+	// LastQueryID returns the server query id of the last executed query.
+	LastQueryID() string
 	// Buf returns the current query buffer.
 	Buf() *stmt.Stmt
 	// Reset resets the last and current query buffer.
@@ -39,12 +41,17 @@ type Handler interface {
 	Open(context.Context, ...string) error
 	// Close closes the current database connection.
 	Close() error
+	// OpenDB opens a database connection separate from the current
+	// connection, the same way as Open.
+	OpenDB(context.Context, string) (*dburl.URL, *sql.DB, error)
 	// ChangePassword changes the password for a user.
 	ChangePassword(string) (string, error)
 	// ReadVar reads a variable of a specified type.
 	ReadVar(string, string) (string, error)
 	// Include includes a file.
 	Include(string, bool) error
+	// RunScript executes the statements in a file.
+	RunScript(string, RunOptions) error
 	// Begin begins a transaction.
 	Begin(*sql.TxOptions) error
 	// Commit commits the current transaction.
@@ -57,16 +64,125 @@ type Handler interface {
 	GetTiming() bool
 	// SetTiming mode.
 	SetTiming(bool)
+	// GetTimingVerbose mode.
+	GetTimingVerbose() bool
+	// SetTimingVerbose mode.
+	SetTimingVerbose(bool)
+	// TimingStats writes the session's timing statistics.
+	TimingStats(io.Writer) error
+	// SetCache sets the result cache mode and time to live.
+	SetCache(bool, time.Duration)
+	// ClearCache removes all cached results.
+	ClearCache()
+	// CacheStats writes the result cache statistics.
+	CacheStats(io.Writer) error
 	// GetOutput writer.
 	GetOutput() io.Writer
 	// SetOutput writer.
 	SetOutput(io.WriteCloser)
+	// SetTee sets the writer, and its format, that query results are
+	// additionally written to.
+	SetTee(io.WriteCloser, string)
+	// Bind binds parameters to the next executed query.
+	Bind([]string)
+	// Prepare prepares a named statement.
+	Prepare(context.Context, string, string) error
+	// ExecutePrepared executes a prepared statement with parameters.
+	ExecutePrepared(context.Context, io.Writer, string, []string) error
+	// Deallocate closes a prepared statement.
+	Deallocate(string) error
+	// ListPrepared writes the prepared statements.
+	ListPrepared(io.Writer) error
+	// PassAdd adds a password entry to the passfile or keychain.
+	PassAdd(string, bool) error
+	// PassRemove removes a password entry from the passfile or keychain.
+	PassRemove(string, bool) error
+	// PassList writes the password entries.
+	PassList(io.Writer) error
+	// PassEncrypt encrypts or decrypts the passfile.
+	PassEncrypt(bool) error
+	// Copy copies the results of a query on a source database to a table on
+	// a destination database.
+	Copy(context.Context, *dburl.URL, *dburl.URL, string, string) (int64, error)
+	// CopyIn copies data from the input into a table.
+	CopyIn(context.Context, string, drivers.CopyInOptions) (int64, error)
+	// Import copies the data of a file into a table, optionally creating the
+	// table.
+	Import(context.Context, string, string, bool, drivers.CopyInOptions) (int64, error)
 	// MetadataWriter retrieves the metadata writer for the handler.
 	MetadataWriter(context.Context) (metadata.Writer, error)
+	// Browse opens the last query result in the interactive result browser.
+	Browse() error
+	// FilterResult filters the rows of the last query result by an expression.
+	FilterResult(string) error
+	// MapResult maps the rows of the last query result to the values of
+	// expressions.
+	MapResult(string) error
+	// Store stores the last query result, to be referenced as ::name in
+	// statements.
+	Store(string) error
+	// StoreList writes the stored results.
+	StoreList(io.Writer) error
+	// XJoin executes a query on a local database, joining the rows copied
+	// from the sources.
+	XJoin(context.Context, []XJoinSource, string, int) error
+	// Sample displays a sample of the rows of a table.
+	Sample(context.Context, string, int) error
+	// Comment sets the comment of a table or column.
+	Comment(context.Context, string, string, string) error
+	// ConnectReplica opens the read replica connection.
+	ConnectReplica(context.Context, string) error
+	// CloseReplica closes the read replica connection.
+	CloseReplica() error
+	// SetRoute sets the routing of statements (auto, primary, or replica).
+	SetRoute(string)
+	// RouteStatus writes the connections and the routing of statements.
+	RouteStatus(io.Writer) error
+	// Migrate applies, reverts, or lists the migrations in a directory.
+	Migrate(context.Context, string, MigrateOptions) error
 	// Print formats according to a format specifier and writes to handler's standard output.
 	Print(string, ...interface{})
 }
 
+// RunOptions are the options for executing the statements in a file (\run).
+type RunOptions struct {
+	// SingleTransaction wraps the statements in a single transaction.
+	SingleTransaction bool
+	// SavepointPerStatement sets a savepoint before each statement, rolling
+	// back to it when the statement fails. Implies SingleTransaction.
+	SavepointPerStatement bool
+	// RetrySerialization is the number of times to retry after a
+	// serialization failure.
+	RetrySerialization int
+}
+
+// XJoinSource is a source of a cross connection query (\xjoin).
+type XJoinSource struct {
+	// Name is the name of the table the rows are copied into.
+	Name string
+	// URL is the database url of the source, with the current connection
+	// used when empty.
+	URL string
+	// Query is the query returning the rows, selecting all the rows of the
+	// table named Name when empty.
+	Query string
+}
+
+// MigrateOptions are the options for applying and reverting migrations
+// (\migrate).
+type MigrateOptions struct {
+	// Dir is the directory of the migration files.
+	Dir string
+	// Table is the name of the table tracking the applied migrations.
+	Table string
+	// Steps is the number of migrations to apply or revert, with 0 applying
+	// all pending migrations, or reverting the last applied migration.
+	Steps int
+	// DryRun displays the statements of the migrations, without executing
+	// them.
+	DryRun bool
+}
+
 // Runner is a runner interface type.
 type Runner interface {
 	Run(Handler) (Option, error)
@@ -99,6 +215,9 @@ const (
 	ExecCrosstab
 	// ExecWatch indicates repeated execution with a fixed time interval.
 	ExecWatch
+	// ExecDesc indicates describing the result columns of the query, without
+	// executing it (\gdesc).
+	ExecDesc
 )
 
 // Option contains parsed result options of a metacmd.
@@ -113,13 +232,20 @@ type Option struct {
 	Crosstab []string
 	// Watch is the watch duration interval.
 	Watch time.Duration
+	// WatchDiff marks the rows and values changed since the previous
+	// execution of a watched query (\watch --diff).
+	WatchDiff bool
+	// Bind are the parameters bound to the query by \bind.
+	Bind []string
+	// Prepared is the name of the prepared statement executed by \execute.
+	Prepared string
 }
 
 func (opt *Option) ParseParams(params []string, defaultKey string) error {
 	if opt.Params == nil {
 		opt.Params = make(map[string]string, len(params))
 	}
-	formatOptions := false
+	formatOptions, key := false, ""
 	for i, param := range params {
 		if len(param) == 0 {
 			continue
@@ -133,10 +259,17 @@ func (opt *Option) ParseParams(params []string, defaultKey string) error {
 			}
 		}
 		parts := strings.SplitN(param, "=", 2)
-		if len(parts) == 1 {
+		switch {
+		case len(parts) == 2:
+			key = strings.TrimLeft(parts[0], "(")
+			opt.Params[key] = strings.TrimRight(parts[1], ")")
+		case key != "" && param[0] != '(':
+			// words without a key continue the value of the previous option,
+			// as with (sort=a desc, b)
+			opt.Params[key] += " " + strings.TrimRight(param, ")")
+		default:
 			return text.ErrInvalidFormatOption
 		}
-		opt.Params[strings.TrimLeft(parts[0], "(")] = strings.TrimRight(parts[1], ")")
 		if formatOptions && param[len(param)-1] == ')' {
 			formatOptions = false
 		}
@@ -144,6 +277,75 @@ func (opt *Option) ParseParams(params []string, defaultKey string) error {
 	return nil
 }
 
+// ParseCrosstab parses \crosstabview parameters, consisting of optional
+// format options followed by the vertical, horizontal, and data columns. As
+// with psql, the horizontal header sort column can be specified as
+// colH:scolH, or as a fourth column.
+func (opt *Option) ParseCrosstab(params []string) error {
+	cols, err := opt.parseFormatOptions(params)
+	if err != nil {
+		return err
+	}
+	if len(cols) > 1 {
+		if h, s, ok := strings.Cut(cols[1], ":"); ok {
+			if len(cols) > 3 {
+				return text.ErrWrongNumberOfArguments
+			}
+			v := make([]string, 4)
+			copy(v, cols)
+			v[1], v[3] = h, s
+			cols = v
+		}
+	}
+	if len(cols) > 4 {
+		return text.ErrWrongNumberOfArguments
+	}
+	opt.Crosstab = cols
+	return nil
+}
+
+// ParseChart parses \chart parameters, consisting of optional format options
+// followed by the x and y columns, the chart type, and the file to write the
+// chart to.
+func (opt *Option) ParseChart(params []string) error {
+	v, err := opt.parseFormatOptions(params)
+	if err != nil {
+		return err
+	}
+	if len(v) > 4 {
+		return text.ErrWrongNumberOfArguments
+	}
+	opt.Params["format"] = "chart"
+	for i, k := range []string{"chart_x", "chart_y", "chart_type", "pipe"} {
+		if i < len(v) {
+			opt.Params[k] = v[i]
+		}
+	}
+	return nil
+}
+
+// parseFormatOptions parses any leading parenthesized format options in
+// params, returning the remaining params.
+func (opt *Option) parseFormatOptions(params []string) ([]string, error) {
+	if opt.Params == nil {
+		opt.Params = make(map[string]string)
+	}
+	if len(params) == 0 || !strings.HasPrefix(params[0], "(") {
+		return params, nil
+	}
+	i := 0
+	for i < len(params) && !strings.HasSuffix(params[i], ")") {
+		i++
+	}
+	if i == len(params) {
+		return nil, text.ErrInvalidFormatOption
+	}
+	if err := opt.ParseParams(params[:i+1], ""); err != nil {
+		return nil, err
+	}
+	return params[i+1:], nil
+}
+
 // Params wraps metacmd parameters.
 type Params struct {
 	// Handler is the process handler.
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"os/user"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/ildus/usql/handler"
+	"github.com/ildus/usql/metacmd"
+	"github.com/ildus/usql/rline"
+	"github.com/ildus/usql/text"
+)
+
+// MigrateArgs are the migrate command line arguments.
+type MigrateArgs struct {
+	DSN        string
+	Action     string
+	Steps      int
+	Dir        string
+	Table      string
+	DryRun     bool
+	NoPassword bool
+}
+
+// NewMigrateArgs parses the migrate command line arguments.
+func NewMigrateArgs(args []string) (*MigrateArgs, error) {
+	migrateArgs := &MigrateArgs{}
+	app := kingpin.New(text.CommandLower()+" migrate", "apply, revert, or list migrations")
+	app.UsageTemplate(text.MigrateUsageTemplate())
+	app.Arg("dsn", "database url").Required().StringVar(&migrateArgs.DSN)
+	app.Arg("action", "migrate action").Default("up").EnumVar(&migrateArgs.Action, "up", "down", "status")
+	app.Arg("n", "number of migrations to apply or revert").IntVar(&migrateArgs.Steps)
+	app.Flag("dir", "directory of the migration files").Short('d').Default("migrations").StringVar(&migrateArgs.Dir)
+	app.Flag("table", "table tracking the applied migrations").Default("schema_version").StringVar(&migrateArgs.Table)
+	app.Flag("dry-run", "display the statements of the migrations, without executing them").BoolVar(&migrateArgs.DryRun)
+	app.Flag("no-password", "never prompt for password").Short('w').BoolVar(&migrateArgs.NoPassword)
+	app.HelpFlag.Short('h').Hidden()
+	if _, err := app.Parse(args); err != nil {
+		return nil, err
+	}
+	return migrateArgs, nil
+}
+
+// migrate applies, reverts, or lists the migrations of the database.
+func migrate(args *MigrateArgs, u *user.User) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	l, err := rline.New(true, "", "")
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	h := handler.New(l, u, wd, args.NoPassword)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := h.Open(ctx, args.DSN); err != nil {
+		return &exitError{exitConnection, err}
+	}
+	defer h.Close()
+	err = h.Migrate(ctx, args.Action, metacmd.MigrateOptions{
+		Dir:    args.Dir,
+		Table:  args.Table,
+		Steps:  args.Steps,
+		DryRun: args.DryRun,
+	})
+	if err != nil {
+		return &exitError{exitScript, err}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package rline
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bracketed paste terminal sequences.
+const (
+	// pasteEnable enables bracketed paste mode on the terminal.
+	pasteEnable = "\x1b[?2004h"
+	// pasteDisable disables bracketed paste mode on the terminal.
+	pasteDisable = "\x1b[?2004l"
+	// pasteStart marks the start of pasted text.
+	pasteStart = "\x1b[200~"
+	// pasteEnd marks the end of pasted text.
+	pasteEnd = "\x1b[201~"
+)
+
+// pasteTimeout is the time to wait for the rest of a partial paste start
+// sequence, before passing it through to the line editor (ie, a lone ESC key
+// press).
+var pasteTimeout = 50 * time.Millisecond
+
+// pasteReader wraps a terminal's input, capturing text pasted in bracketed
+// paste mode so that it is not interpreted as key presses (ie, tabs
+// triggering completion, and newlines ending the line being edited).
+//
+// The first line of pasted text is passed through to the line editor, with
+// any following lines queued, to be read as lines of input once the line
+// being edited has been read. A final line not ending with a newline is kept
+// for editing, as the initial contents of the next line.
+type pasteReader struct {
+	r       io.Reader
+	buf     []byte
+	out     []byte
+	pending chan readResult
+
+	mu    sync.Mutex
+	lines []string
+	edit  string
+	has   bool
+}
+
+// readResult is the result of a read of the terminal's input.
+type readResult struct {
+	b   []byte
+	err error
+}
+
+// newPasteReader creates a paste reader for the terminal input.
+func newPasteReader(r io.Reader) *pasteReader {
+	return &pasteReader{r: r}
+}
+
+// Read satisfies the io.Reader interface.
+func (r *pasteReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		i := bytes.Index(r.buf, []byte(pasteStart))
+		partial := i == -1 && partialPrefix(r.buf, pasteStart)
+		switch {
+		case i == -1 && len(r.buf) != 0 && !partial:
+			r.out, r.buf = r.buf, nil
+			continue
+		case i > 0:
+			r.out, r.buf = r.buf[:i], r.buf[i:]
+			continue
+		case i == 0:
+			if j := bytes.Index(r.buf, []byte(pasteEnd)); j != -1 {
+				r.paste(string(r.buf[len(pasteStart):j]))
+				r.buf = r.buf[j+len(pasteEnd):]
+				continue
+			}
+		}
+		// pass a partial start sequence through when no more input follows
+		var timeout <-chan time.Time
+		if partial {
+			timeout = time.After(pasteTimeout)
+		}
+		var res readResult
+		select {
+		case res = <-r.read():
+			r.pending = nil
+		case <-timeout:
+			r.out, r.buf = r.buf, nil
+			continue
+		}
+		r.buf = append(r.buf, res.b...)
+		if res.err != nil {
+			if len(res.b) == 0 && len(r.buf) != 0 {
+				r.out, r.buf = r.buf, nil
+				break
+			}
+			if len(res.b) == 0 {
+				return 0, res.err
+			}
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// read returns the channel of the pending read of the terminal's input,
+// starting the read when none is pending.
+func (r *pasteReader) read() <-chan readResult {
+	if r.pending == nil {
+		r.pending = make(chan readResult, 1)
+		go func(ch chan<- readResult) {
+			b := make([]byte, 4096)
+			n, err := r.r.Read(b)
+			ch <- readResult{b[:n], err}
+		}(r.pending)
+	}
+	return r.pending
+}
+
+// Close satisfies the io.Closer interface.
+func (r *pasteReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// partialPrefix determines if b ends with a partial prefix of s.
+func partialPrefix(b []byte, s string) bool {
+	for i := len(s) - 1; i > 0; i-- {
+		if bytes.HasSuffix(b, []byte(s[:i])) {
+			return true
+		}
+	}
+	return false
+}
+
+// paste handles pasted text, passing the first line to the line editor, and
+// queuing the remaining lines.
+func (r *pasteReader) paste(s string) {
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s)
+	lines := strings.Split(s, "\n")
+	first := strings.Map(func(c rune) rune {
+		switch {
+		case c == '\t':
+			return ' '
+		case c < ' ' || c == 0x7f:
+			return -1
+		}
+		return c
+	}, lines[0])
+	if len(lines) == 1 {
+		r.out = append(r.out, first...)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, lines[1:len(lines)-1]...)
+	r.edit, r.has = lines[len(lines)-1], true
+	r.out = append(append(r.out, first...), '\n')
+}
+
+// next returns the next queued line of pasted text.
+func (r *pasteReader) next() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) == 0 {
+		return "", false
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return line, true
+}
+
+// take returns the final line of pasted text, to be edited.
+func (r *pasteReader) take() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.has || len(r.lines) != 0 {
+		return "", false
+	}
+	edit := r.edit
+	r.edit, r.has = "", false
+	return edit, true
+}
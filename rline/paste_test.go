@@ -0,0 +1,80 @@
+package rline
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestPasteReader(t *testing.T) {
+	tests := []struct {
+		in    string
+		exp   string
+		lines []string
+		edit  string
+		has   bool
+	}{
+		{"select 1;\n", "select 1;\n", nil, "", false},
+		{"a\x1b[Db", "a\x1b[Db", nil, "", false},
+		{"\x1b[200~select\t1\x1b[201~;\n", "select 1;\n", nil, "", false},
+		{"\x1b[200~select 1;\r\nselect 2;\nsel\x1b[201~", "select 1;\n", []string{"select 2;"}, "sel", true},
+		{"a\x1b[200~b\nc\n\x1b[201~d", "ab\nd", []string{"c"}, "", true},
+	}
+	for i, test := range tests {
+		// read one byte at a time, splitting the paste sequences
+		for _, r := range []io.Reader{strings.NewReader(test.in), iotest.OneByteReader(strings.NewReader(test.in))} {
+			p := newPasteReader(r)
+			b, err := io.ReadAll(p)
+			if err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+			if s := string(b); s != test.exp {
+				t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+			}
+			var lines []string
+			for line, ok := p.next(); ok; line, ok = p.next() {
+				lines = append(lines, line)
+			}
+			if strings.Join(lines, "\n") != strings.Join(test.lines, "\n") {
+				t.Errorf("test %d expected lines %q, got: %q", i, test.lines, lines)
+			}
+			if edit, has := p.take(); edit != test.edit || has != test.has {
+				t.Errorf("test %d expected edit %q (%t), got: %q (%t)", i, test.edit, test.has, edit, has)
+			}
+		}
+	}
+}
+
+func TestPasteReaderEscape(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte("\x1b"))
+	p := newPasteReader(pr)
+	ch := make(chan string)
+	go func() {
+		b := make([]byte, 16)
+		n, _ := p.Read(b)
+		ch <- string(b[:n])
+	}()
+	// a lone escape is passed through without waiting for the next key
+	select {
+	case s := <-ch:
+		if s != "\x1b" {
+			t.Errorf("expected %q, got: %q", "\x1b", s)
+		}
+	case <-time.After(10 * pasteTimeout):
+		t.Fatalf("expected escape to be passed through")
+	}
+	// the following input is read
+	go pw.Write([]byte("[200~a\nb\x1b[201~"))
+	go func() {
+		b := make([]byte, 16)
+		n, _ := p.Read(b)
+		ch <- string(b[:n])
+	}()
+	if s := <-ch; s != "[200~a\nb\x1b[201~" {
+		t.Errorf("expected %q, got: %q", "[200~a\nb\x1b[201~", s)
+	}
+}
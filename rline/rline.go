@@ -3,8 +3,10 @@ package rline
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"runtime"
 
 	"github.com/gohxs/readline"
 	isatty "github.com/mattn/go-isatty"
@@ -166,6 +168,12 @@ func New(forceNonInteractive bool, out, histfile string) (IO, error) {
 	if !cygwin {
 		stderr = readline.Stderr
 	}
+	// capture bracketed paste
+	var paste *pasteReader
+	if interactive && !cygwin && runtime.GOOS != "windows" {
+		paste = newPasteReader(stdin)
+		stdin = paste
+	}
 	if interactive {
 		// wrap it with cancelable stdin
 		stdin = readline.NewCancelableStdin(stdin)
@@ -201,6 +209,31 @@ func New(forceNonInteractive bool, out, histfile string) (IO, error) {
 		}
 		return string(buf), nil
 	}
+	prompt := l.SetPrompt
+	if paste != nil {
+		var p string
+		prompt = func(s string) {
+			p = s
+			l.SetPrompt(s)
+		}
+		n = func() ([]rune, error) {
+			// echo queued lines of pasted text
+			if line, ok := paste.next(); ok {
+				s := line
+				if f := l.Config.Output; f != nil {
+					s = f(s)
+				}
+				fmt.Fprintln(stdout, p+s)
+				return []rune(line), nil
+			}
+			if edit, ok := paste.take(); ok && edit != "" {
+				l.Operation.SetBuffer(edit)
+			}
+			fmt.Fprint(stdout, pasteEnable)
+			defer fmt.Fprint(stdout, pasteDisable)
+			return l.Operation.Runes()
+		}
+	}
 	if forceNonInteractive {
 		n, pw = nil, nil
 	}
@@ -217,7 +250,7 @@ func New(forceNonInteractive bool, out, histfile string) (IO, error) {
 		Err: stderr,
 		Int: interactive || cygwin,
 		Cyg: cygwin,
-		P:   l.SetPrompt,
+		P:   prompt,
 		A: func(a readline.AutoCompleter) {
 			cfg := l.Config.Clone()
 			cfg.AutoComplete = a
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/ildus/usql/env"
+	"github.com/ildus/usql/server"
+	"github.com/ildus/usql/text"
+)
+
+// ServeArgs are the serve command line arguments.
+type ServeArgs struct {
+	Listen      string
+	Token       string
+	Connections []string
+	PVariables  []string
+}
+
+// NewServeArgs parses the serve command line arguments.
+func NewServeArgs(args []string) (*ServeArgs, error) {
+	serveArgs := &ServeArgs{}
+	app := kingpin.New(text.CommandLower()+" serve", "serve queries over HTTP")
+	app.UsageTemplate(text.ServeUsageTemplate())
+	app.Arg("connections", "named database urls").Required().StringsVar(&serveArgs.Connections)
+	app.Flag("listen", "address to listen on").Short('l').Default("localhost:8080").StringVar(&serveArgs.Listen)
+	app.Flag("token", "require bearer token for requests").Envar(text.CommandUpper() + "_SERVE_TOKEN").StringVar(&serveArgs.Token)
+	app.Flag("pset", `set printing option VAR to ARG (see \pset command)`).Short('P').PlaceHolder("VAR[=ARG]").StringsVar(&serveArgs.PVariables)
+	app.HelpFlag.Short('h').Hidden()
+	if _, err := app.Parse(args); err != nil {
+		return nil, err
+	}
+	return serveArgs, nil
+}
+
+// serve runs usql as a HTTP server, executing queries on the named database
+// connections.
+func serve(args *ServeArgs) error {
+	for _, v := range args.PVariables {
+		name, value, _ := strings.Cut(v, "=")
+		if _, err := env.Pset(name, value); err != nil {
+			return err
+		}
+	}
+	urls := make(map[string]string, len(args.Connections))
+	for _, c := range args.Connections {
+		name, urlstr, ok := strings.Cut(c, "=")
+		if !ok || name == "" || urlstr == "" {
+			return fmt.Errorf(text.ServeInvalidConn, c)
+		}
+		urls[name] = urlstr
+	}
+	s, err := server.New(urls, server.WithToken(args.Token))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	srv := &http.Server{
+		Addr:    args.Listen,
+		Handler: s,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	fmt.Fprintf(os.Stderr, text.ServeListening, args.Listen)
+	fmt.Fprintln(os.Stderr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
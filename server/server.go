@@ -0,0 +1,308 @@
+// Package server provides a HTTP/JSON interface for executing queries on
+// named database connections, used by usql's headless serve mode.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ildus/usql/client"
+	"github.com/ildus/usql/dburl"
+	"github.com/ildus/usql/drivers"
+	"github.com/ildus/usql/text"
+)
+
+// Server is a HTTP handler executing queries on named database connections,
+// writing the results with usql's output encoders.
+//
+// Endpoints:
+//
+//	GET  /connections  lists the connection names and drivers
+//	POST /query        executes a query, streaming the formatted results
+type Server struct {
+	token string
+	mux   *http.ServeMux
+	mu    sync.Mutex
+	conns map[string]*conn
+}
+
+// conn is a named database connection, opened on first use.
+type conn struct {
+	u *dburl.URL
+	c *client.Conn
+}
+
+// Option is a server option.
+type Option func(*Server)
+
+// WithToken is a server option to require the token as a bearer token in the
+// Authorization header of every request.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// New creates a new server for the named database urls.
+func New(urls map[string]string, opts ...Option) (*Server, error) {
+	s := &Server{
+		mux:   http.NewServeMux(),
+		conns: make(map[string]*conn, len(urls)),
+	}
+	for name, urlstr := range urls {
+		urlstr, err := dburl.Resolve(context.Background(), urlstr)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", name, err)
+		}
+		u, err := dburl.Parse(urlstr)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", name, err)
+		}
+		drivers.ForceParams(u)
+		s.conns[name] = &conn{u: u}
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.mux.HandleFunc("/connections", s.connections)
+	s.mux.HandleFunc("/query", s.query)
+	return s, nil
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if s.token != "" {
+		auth := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte(s.token)) != 1 {
+			writeError(res, http.StatusUnauthorized, errors.New(http.StatusText(http.StatusUnauthorized)))
+			return
+		}
+	}
+	s.mux.ServeHTTP(res, req)
+}
+
+// Close closes the open database connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	for _, c := range s.conns {
+		if c.c != nil {
+			err = errors.Join(err, c.c.Close())
+			c.c = nil
+		}
+	}
+	return err
+}
+
+// connections lists the connection names and drivers.
+func (s *Server) connections(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(res, http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+		return
+	}
+	type connection struct {
+		Name   string `json:"name"`
+		Driver string `json:"driver"`
+	}
+	conns := make([]connection, 0, len(s.conns))
+	for name, c := range s.conns {
+		conns = append(conns, connection{name, c.u.Driver})
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].Name < conns[j].Name
+	})
+	res.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(res).Encode(conns)
+}
+
+// Request is a query request.
+type Request struct {
+	// Connection is the name of the connection to execute the query on.
+	Connection string `json:"connection"`
+	// SQL is the query to execute.
+	SQL string `json:"sql"`
+	// Format is the output format, defaulting to json.
+	Format string `json:"format,omitempty"`
+	// Params are additional display settings (see \pset), limited to the
+	// settings in displayParams.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// displayParams are the display settings a request may set. Settings that run
+// a process (such as pager and pager_cmd), write files, or disable masking are
+// not allowed.
+var displayParams = map[string]bool{
+	"border":                   true,
+	"columns":                  true,
+	"csv_fieldsep":             true,
+	"expanded":                 true,
+	"expanded_fieldwidth":      true,
+	"expanded_header":          true,
+	"expanded_recordsep":       true,
+	"fieldsep":                 true,
+	"fieldsep_zero":            true,
+	"float_precision":          true,
+	"footer":                   true,
+	"format":                   true,
+	"linestyle":                true,
+	"locale":                   true,
+	"maxcolwidth":              true,
+	"null":                     true,
+	"nullstyle":                true,
+	"numericlocale":            true,
+	"recordsep":                true,
+	"recordsep_zero":           true,
+	"schema_header":            true,
+	"tableattr":                true,
+	"thousands_sep":            true,
+	"time":                     true,
+	"timezone":                 true,
+	"title":                    true,
+	"tuples_only":              true,
+	"unicode_border_linestyle": true,
+	"unicode_column_linestyle": true,
+	"unicode_header_linestyle": true,
+}
+
+// query executes a query, streaming the formatted results.
+func (s *Server) query(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeError(res, http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+		return
+	}
+	var r Request
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		writeError(res, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(r.SQL) == "" {
+		writeError(res, http.StatusBadRequest, text.ErrEmptyQuery)
+		return
+	}
+	ctx := req.Context()
+	c, err := s.open(ctx, r.Connection)
+	switch {
+	case errors.Is(err, text.ErrUnknownConnection):
+		writeError(res, http.StatusNotFound, err)
+		return
+	case err != nil:
+		writeError(res, http.StatusBadGateway, err)
+		return
+	}
+	params := map[string]string{"format": "json"}
+	if r.Format != "" {
+		params["format"] = r.Format
+	}
+	for k, v := range r.Params {
+		if !displayParams[k] {
+			writeError(res, http.StatusBadRequest, fmt.Errorf("%w: %s", text.ErrInvalidFormatOption, k))
+			return
+		}
+		params[k] = v
+	}
+	// errors after the results have started streaming are sent in the
+	// trailer
+	res.Header().Set("Trailer", errorTrailer)
+	w := &responseWriter{ResponseWriter: res}
+	switch err := s.execute(ctx, w, c, r.SQL, params); {
+	case err != nil && w.wrote:
+		res.Header().Set(errorTrailer, err.Error())
+	case err != nil:
+		writeError(res, http.StatusBadRequest, err)
+	}
+}
+
+// open returns the named connection, opening the connection if not already
+// open.
+func (s *Server) open(ctx context.Context, name string) (*client.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.conns[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", text.ErrUnknownConnection, name)
+	}
+	if c.c != nil {
+		return c.c, nil
+	}
+	conn, err := client.OpenURL(ctx, c.u)
+	if err != nil {
+		return nil, err
+	}
+	c.c = conn
+	return c.c, nil
+}
+
+// execute executes the sql on the connection, writing the formatted results
+// to w.
+func (s *Server) execute(ctx context.Context, w *responseWriter, c *client.Conn, sqlstr string, params map[string]string) error {
+	res, err := c.Exec(ctx, sqlstr)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	if res.Rows == nil {
+		w.setContentType("application/json")
+		return json.NewEncoder(w).Encode(struct {
+			Command      string `json:"command"`
+			RowsAffected int64  `json:"rows_affected"`
+		}{res.Command, res.RowsAffected})
+	}
+	w.setContentType(contentType(params["format"]))
+	return res.Rows.Encode(w, params)
+}
+
+// contentType returns the content type for a output format.
+func contentType(format string) string {
+	switch format {
+	case "json":
+		return "application/json"
+	case "csv":
+		return "text/csv; charset=utf-8"
+	case "html":
+		return "text/html; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// errorTrailer is the trailer containing the error of a query that failed
+// after its results started streaming.
+const errorTrailer = "Usql-Error"
+
+// responseWriter wraps a http.ResponseWriter, flushing every write so that
+// results are streamed as they are encoded.
+type responseWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+// setContentType sets the content type of the response.
+func (w *responseWriter) setContentType(typ string) {
+	w.Header().Set("Content-Type", typ)
+}
+
+// Write satisfies the io.Writer interface.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	n, err := w.ResponseWriter.Write(b)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+// writeError writes a JSON error response.
+func writeError(res http.ResponseWriter, code int, err error) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(code)
+	_ = json.NewEncoder(res).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/ildus/usql/drivers/sqlite3"
+)
+
+func TestServer(t *testing.T) {
+	s, err := New(map[string]string{
+		"db": "sqlite3:" + filepath.Join(t.TempDir(), "test.db"),
+	}, WithToken("secret"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer s.Close()
+	tests := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		code   int
+		exp    string
+	}{
+		{"GET", "/connections", "", ``, 401, `{"error":"Unauthorized"}`},
+		{"GET", "/connections", "secret", ``, 200, `[{"name":"db","driver":"sqlite3"}]`},
+		{"GET", "/query", "secret", ``, 405, `{"error":"Method Not Allowed"}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"CREATE TABLE t (a INTEGER, b TEXT)"}`, 200, `{"command":"CREATE TABLE","rows_affected":0}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"INSERT INTO t VALUES (1, 'x'), (2, 'y')"}`, 200, `{"command":"INSERT","rows_affected":2}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM t"}`, 200, `[{"a":1,"b":"x"},{"a":2,"b":"y"}]`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM t","format":"csv"}`, 200, "a,b\n1,x\n2,y"},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM t","format":"csv","params":{"csv_fieldsep":";"}}`, 200, "a;b\n1;x\n2;y"},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM t","params":{"pager":"always"}}`, 400, `{"error":"invalid format option: pager"}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM t","params":{"pager_cmd":"touch pwned"}}`, 400, `{"error":"invalid format option: pager_cmd"}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":"SELECT * FROM missing"}`, 400, `{"error":"no such table: missing"}`},
+		{"POST", "/query", "secret", `{"connection":"other","sql":"SELECT 1"}`, 404, `{"error":"unknown connection: other"}`},
+		{"POST", "/query", "secret", `{"connection":"db","sql":" "}`, 400, `{"error":"empty query"}`},
+	}
+	for i, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		res := httptest.NewRecorder()
+		s.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("test %d expected code %d, got: %d", i, test.code, res.Code)
+		}
+		if body := strings.TrimSpace(res.Body.String()); body != test.exp {
+			t.Errorf("test %d expected body %q, got: %q", i, test.exp, body)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		format string
+		exp    string
+	}{
+		{"json", "application/json"},
+		{"csv", "text/csv; charset=utf-8"},
+		{"aligned", "text/plain; charset=utf-8"},
+	}
+	for i, test := range tests {
+		if s := contentType(test.format); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
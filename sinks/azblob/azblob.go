@@ -0,0 +1,49 @@
+// Package azblob defines and registers usql's Azure Blob Storage object storage
+// sink.
+package azblob
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/ildus/usql/sinks"
+	"github.com/ildus/usql/text"
+)
+
+func init() {
+	sinks.Register("azblob", sinks.Sink{
+		Upload: upload,
+	})
+}
+
+// upload uploads r to an Azure Storage block blob, in blocks.
+//
+// Credentials are retrieved from AZURE_STORAGE_CONNECTION_STRING, or using
+// the default Azure credential for the account in AZURE_STORAGE_ACCOUNT.
+func upload(ctx context.Context, u *url.URL, r io.Reader) error {
+	var client *azblob.Client
+	var err error
+	if s := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); s != "" {
+		client, err = azblob.NewClientFromConnectionString(s, nil)
+	} else {
+		account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+		if account == "" {
+			return text.ErrMissingStorageAccount
+		}
+		var cred *azidentity.DefaultAzureCredential
+		if cred, err = azidentity.NewDefaultAzureCredential(nil); err != nil {
+			return err
+		}
+		client, err = azblob.NewClient("https://"+account+".blob.core.windows.net/", cred, nil)
+	}
+	if err != nil {
+		return err
+	}
+	container, blob := sinks.ObjectPath(u)
+	_, err = client.UploadStream(ctx, container, blob, r, nil)
+	return err
+}
@@ -0,0 +1,40 @@
+// Package gcs defines and registers usql's Google Cloud Storage object storage
+// sink.
+package gcs
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"cloud.google.com/go/storage"
+	"github.com/ildus/usql/sinks"
+)
+
+func init() {
+	sinks.Register("gs", sinks.Sink{
+		Upload: upload,
+	}, "gcs")
+}
+
+// upload uploads r to a Google Cloud Storage object, using resumable uploads.
+//
+// Credentials are retrieved using the application default credentials.
+func upload(ctx context.Context, u *url.URL, r io.Reader) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	bucket, key := sinks.ObjectPath(u)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := client.Bucket(bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		// canceling the context discards the upload
+		cancel()
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,221 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ildus/usql/text"
+)
+
+// avroSchema is the Avro schema of the rows of a result set, registered in a
+// schema registry. Each field is nullable.
+type avroSchema struct {
+	id    int32
+	cols  []string
+	types []string
+}
+
+// newAvroSchema creates the Avro schema of the result set from the types of
+// the values of its first row, and registers it for the topic's value
+// subject in the schema registry. Columns with NULL values in the first row
+// are strings.
+func newAvroSchema(ctx context.Context, registry, topic string, cols []string, row []interface{}) (*avroSchema, error) {
+	s := &avroSchema{
+		cols:  cols,
+		types: make([]string, len(cols)),
+	}
+	type field struct {
+		Name    string      `json:"name"`
+		Type    []string    `json:"type"`
+		Default interface{} `json:"default"`
+	}
+	fields := make([]field, len(cols))
+	for i, col := range cols {
+		s.types[i] = avroType(row[i])
+		fields[i] = field{
+			Name: avroName(col),
+			Type: []string{"null", s.types[i]},
+		}
+	}
+	def, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   avroName(topic),
+		"fields": fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.id, err = registerSchema(ctx, registry, topic+"-value", string(def)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// avroType returns the Avro type of a value.
+func avroType(v interface{}) string {
+	switch x := v.(type) {
+	case int64, int32, int16, int8, int, uint32, uint16, uint8:
+		return "long"
+	case float64, float32:
+		return "double"
+	case bool:
+		return "boolean"
+	case []byte:
+		if !utf8.Valid(x) {
+			return "bytes"
+		}
+	}
+	return "string"
+}
+
+// avroName returns name as a valid Avro name, replacing invalid characters
+// with underscores.
+func avroName(name string) string {
+	var sb strings.Builder
+	for i, c := range name {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+			if i == 0 {
+				sb.WriteByte('_')
+			}
+		default:
+			c = '_'
+		}
+		sb.WriteRune(c)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+// encode encodes the row in the Avro binary encoding, prefixed with the
+// schema id, as expected by the schema registry serializers.
+func (s *avroSchema) encode(row []interface{}) ([]byte, error) {
+	buf := []byte{0}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(s.id))
+	for i, v := range row {
+		if v == nil {
+			buf = binary.AppendVarint(buf, 0)
+			continue
+		}
+		buf = binary.AppendVarint(buf, 1)
+		var err error
+		if buf, err = appendAvro(buf, s.types[i], v); err != nil {
+			return nil, fmt.Errorf(text.KafkaAvroValue, v, s.cols[i], s.types[i])
+		}
+	}
+	return buf, nil
+}
+
+// appendAvro appends the Avro binary encoding of the value converted to typ.
+func appendAvro(buf []byte, typ string, v interface{}) ([]byte, error) {
+	switch typ {
+	case "long":
+		var n int64
+		switch x := v.(type) {
+		case int64:
+			n = x
+		case int32:
+			n = int64(x)
+		case int16:
+			n = int64(x)
+		case int8:
+			n = int64(x)
+		case int:
+			n = int64(x)
+		case uint32:
+			n = int64(x)
+		case uint16:
+			n = int64(x)
+		case uint8:
+			n = int64(x)
+		default:
+			var err error
+			if n, err = strconv.ParseInt(stringValue(v), 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		return binary.AppendVarint(buf, n), nil
+	case "double":
+		var f float64
+		switch x := v.(type) {
+		case float64:
+			f = x
+		case float32:
+			f = float64(x)
+		default:
+			var err error
+			if f, err = strconv.ParseFloat(stringValue(v), 64); err != nil {
+				return nil, err
+			}
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+	case "boolean":
+		b, ok := v.(bool)
+		if !ok {
+			var err error
+			if b, err = strconv.ParseBool(stringValue(v)); err != nil {
+				return nil, err
+			}
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case "bytes":
+		b, ok := v.([]byte)
+		if !ok {
+			b = []byte(stringValue(v))
+		}
+		buf = binary.AppendVarint(buf, int64(len(b)))
+		return append(buf, b...), nil
+	}
+	s := stringValue(v)
+	buf = binary.AppendVarint(buf, int64(len(s)))
+	return append(buf, s...), nil
+}
+
+// registerSchema registers the schema for the subject in the schema registry,
+// returning the schema id. Registering a schema already registered for the
+// subject returns its id.
+func registerSchema(ctx context.Context, registry, subject, schema string) (int32, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+	urlstr := strings.TrimSuffix(registry, "/") + "/subjects/" + subject + "/versions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlstr, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	cl := &http.Client{Timeout: 30 * time.Second}
+	res, err := cl.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	var v struct {
+		ID      int32  `json:"id"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&v)
+	if res.StatusCode != http.StatusOK {
+		if v.Message != "" {
+			return 0, fmt.Errorf("schema registry: %s: %s", res.Status, v.Message)
+		}
+		return 0, fmt.Errorf("schema registry: %s", res.Status)
+	}
+	return v.ID, nil
+}
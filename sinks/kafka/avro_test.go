@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAvroSchemaEncode(t *testing.T) {
+	s := &avroSchema{
+		id:    7,
+		cols:  []string{"a", "b", "c", "d", "e", "f", "g"},
+		types: []string{"long", "string", "double", "boolean", "bytes", "string", "long"},
+	}
+	buf, err := s.encode([]interface{}{int64(42), "hi", 1.5, true, []byte{0xff}, nil, "-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []byte{
+		0, 0, 0, 0, 7, // magic, schema id
+		0x02, 0x54, // long 42
+		0x02, 0x04, 'h', 'i', // string "hi"
+		0x02, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f, // double 1.5
+		0x02, 0x01, // boolean true
+		0x02, 0x02, 0xff, // bytes
+		0x00,       // null
+		0x02, 0x01, // long -1, converted from a string
+	}
+	if !bytes.Equal(buf, exp) {
+		t.Errorf("expected:\n%x\ngot:\n%x", exp, buf)
+	}
+	if _, err := s.encode([]interface{}{"x", nil, nil, nil, nil, nil, nil}); err == nil {
+		t.Errorf("expected error encoding invalid long, got nil")
+	}
+}
@@ -0,0 +1,652 @@
+// Package kafka defines and registers usql's Kafka row sink, streaming the
+// rows of the results as messages to a Kafka topic.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ildus/usql/sinks"
+	"github.com/ildus/usql/text"
+)
+
+func init() {
+	sinks.Register("kafka", sinks.Sink{
+		Open: openKafka,
+	})
+}
+
+const (
+	// kafkaDefaultPort is the default port of Kafka brokers.
+	kafkaDefaultPort = "9092"
+	// kafkaBatchBytes is the maximum size of the records of the batch of a
+	// partition, well under the default maximum record batch size of the
+	// brokers (message.max.bytes, 1048588 bytes).
+	kafkaBatchBytes = 512 << 10
+	// kafkaRecordOverhead is an upper bound of the size of an encoded record,
+	// besides its key and value.
+	kafkaRecordOverhead = 32
+	// kafkaPendingBytes is the size of the pending messages of all partitions
+	// that are flushed to the brokers.
+	kafkaPendingBytes = 4 << 20
+	// kafkaTimeout is the timeout of requests to the brokers.
+	kafkaTimeout = 30 * time.Second
+)
+
+// kafka api keys and versions.
+const (
+	kafkaProduce         int16 = 0
+	kafkaProduceVersion  int16 = 3
+	kafkaMetadata        int16 = 3
+	kafkaMetadataVersion int16 = 1
+)
+
+// kafkaErrors are the names of the kafka error codes commonly returned when
+// producing messages.
+var kafkaErrors = map[int16]string{
+	2:  "CORRUPT_MESSAGE",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_OR_FOLLOWER",
+	7:  "REQUEST_TIMED_OUT",
+	10: "MESSAGE_TOO_LARGE",
+	17: "INVALID_TOPIC_EXCEPTION",
+	18: "RECORD_LIST_TOO_LARGE",
+	19: "NOT_ENOUGH_REPLICAS",
+	20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+	87: "INVALID_RECORD",
+}
+
+// kafkaError returns the error for a kafka error code.
+func kafkaError(code int16) error {
+	name, ok := kafkaErrors[code]
+	if !ok {
+		name = "UNKNOWN"
+	}
+	return fmt.Errorf(text.KafkaError, name, code)
+}
+
+// castagnoli is the crc32c table used for record batch checksums.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// kafkaSink streams each row as a message to a Kafka topic, encoded as a JSON
+// object, or as Avro with the schema registered in a schema registry.
+type kafkaSink struct {
+	ctx      context.Context
+	topic    string
+	format   string
+	keyCol   string
+	registry string
+	tls      *tls.Config
+	// brokers are the addresses of the brokers, by node id.
+	brokers map[int32]string
+	// leaders are the leader node ids of the partitions of the topic.
+	leaders []int32
+	conns   map[int32]*kafkaConn
+	// key is the index of the key column, or -1.
+	key     int
+	cols    []string
+	schema  *avroSchema
+	batches map[int32]*kafkaBatch
+	pending int
+	// next is the partition of messages without key.
+	next   int32
+	closed bool
+}
+
+// openKafka opens a Kafka topic for writing rows as messages.
+//
+// The url is kafka://BROKER[:PORT]/TOPIC, with the optional query parameters
+// format (json or avro), registry (the schema registry url, required for
+// avro), key (the column used as the message key), and tls.
+func openKafka(ctx context.Context, u *url.URL) (sinks.RowSink, error) {
+	topic := strings.Trim(u.Path, "/")
+	if u.Host == "" || topic == "" || strings.Contains(topic, "/") {
+		return nil, fmt.Errorf(text.InvalidKafkaURL, u.Redacted())
+	}
+	q := u.Query()
+	s := &kafkaSink{
+		ctx:      ctx,
+		topic:    topic,
+		format:   strings.ToLower(q.Get("format")),
+		keyCol:   q.Get("key"),
+		registry: q.Get("registry"),
+		conns:    make(map[int32]*kafkaConn),
+		batches:  make(map[int32]*kafkaBatch),
+	}
+	switch s.format {
+	case "":
+		s.format = "json"
+	case "json":
+	case "avro":
+		if s.registry == "" {
+			return nil, text.ErrMissingSchemaRegistry
+		}
+	default:
+		return nil, fmt.Errorf(text.KafkaInvalidFormat, s.format)
+	}
+	if b, _ := strconv.ParseBool(q.Get("tls")); b {
+		s.tls = &tls.Config{ServerName: u.Hostname()}
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), kafkaDefaultPort)
+	}
+	if err := s.metadata(addr); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// metadata retrieves the brokers and the partition leaders of the topic from
+// the bootstrap broker, waiting for the leaders of newly created topics.
+func (s *kafkaSink) metadata(addr string) error {
+	conn, err := dialKafka(s.ctx, addr, s.tls)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	req := new(kafkaEncoder)
+	req.int32(1)
+	req.string(s.topic)
+	for i := 0; ; i++ {
+		d, err := conn.roundTrip(s.ctx, kafkaMetadata, kafkaMetadataVersion, req.b)
+		if err != nil {
+			return err
+		}
+		s.brokers = make(map[int32]string)
+		for n := d.int32(); n > 0 && d.err == nil; n-- {
+			id, host, port := d.int32(), d.string(), d.int32()
+			d.string() // rack
+			s.brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		}
+		d.int32() // controller id
+		var code int16
+		s.leaders = nil
+		for n := d.int32(); n > 0 && d.err == nil; n-- {
+			code = d.int16()
+			d.string() // name
+			d.int8()   // is internal
+			for m := d.int32(); m > 0 && d.err == nil; m-- {
+				d.int16() // error code
+				partition, leader := d.int32(), d.int32()
+				d.skipInt32s() // replicas
+				d.skipInt32s() // isr
+				for int(partition) >= len(s.leaders) {
+					s.leaders = append(s.leaders, -1)
+				}
+				s.leaders[partition] = leader
+			}
+		}
+		if d.err != nil {
+			return d.err
+		}
+		ready := code == 0 && len(s.leaders) != 0
+		for _, leader := range s.leaders {
+			ready = ready && leader != -1
+		}
+		switch {
+		case ready:
+			return nil
+		case code != 0 && code != 5 || i == 10:
+			if code == 0 {
+				code = 5
+			}
+			return kafkaError(code)
+		}
+		// leader not available while the topic is created
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Columns satisfies the sinks.RowSink interface.
+func (s *kafkaSink) Columns(cols []string) error {
+	s.cols, s.schema, s.key = cols, nil, -1
+	if s.keyCol == "" {
+		return nil
+	}
+	for i, col := range cols {
+		if col == s.keyCol || s.key == -1 && strings.EqualFold(col, s.keyCol) {
+			s.key = i
+		}
+	}
+	if s.key == -1 {
+		return fmt.Errorf(text.KafkaKeyNotFound, s.keyCol)
+	}
+	return nil
+}
+
+// Write satisfies the sinks.RowSink interface.
+func (s *kafkaSink) Write(row []interface{}) error {
+	var value []byte
+	var err error
+	switch s.format {
+	case "avro":
+		if s.schema == nil {
+			if s.schema, err = newAvroSchema(s.ctx, s.registry, s.topic, s.cols, row); err != nil {
+				return err
+			}
+		}
+		value, err = s.schema.encode(row)
+	default:
+		value, err = jsonRow(s.cols, row)
+	}
+	if err != nil {
+		return err
+	}
+	partition := s.next
+	var key []byte
+	if s.key != -1 && row[s.key] != nil {
+		key = []byte(stringValue(row[s.key]))
+		partition = (murmur2(key) & 0x7fffffff) % int32(len(s.leaders))
+	}
+	// flush before the batch of the partition would exceed the maximum size
+	b, ok := s.batches[partition]
+	if ok && len(b.records)+len(key)+len(value)+kafkaRecordOverhead > kafkaBatchBytes {
+		if err := s.flush(); err != nil {
+			return err
+		}
+		ok = false
+	}
+	if !ok {
+		b = new(kafkaBatch)
+		s.batches[partition] = b
+	}
+	s.pending += b.add(key, value)
+	if s.pending >= kafkaPendingBytes {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush produces the pending messages to the leaders of their partitions.
+func (s *kafkaSink) flush() error {
+	byLeader := make(map[int32][]int32)
+	for partition := range s.batches {
+		leader := s.leaders[partition]
+		byLeader[leader] = append(byLeader[leader], partition)
+	}
+	for leader, partitions := range byLeader {
+		conn, err := s.conn(leader)
+		if err != nil {
+			return err
+		}
+		req := new(kafkaEncoder)
+		req.int16(-1) // transactional id
+		req.int16(-1) // acks (all)
+		req.int32(int32(kafkaTimeout / time.Millisecond))
+		req.int32(1)
+		req.string(s.topic)
+		req.int32(int32(len(partitions)))
+		for _, partition := range partitions {
+			req.int32(partition)
+			req.bytes(s.batches[partition].encode())
+		}
+		d, err := conn.roundTrip(s.ctx, kafkaProduce, kafkaProduceVersion, req.b)
+		if err != nil {
+			return err
+		}
+		for n := d.int32(); n > 0 && d.err == nil; n-- {
+			d.string() // name
+			for m := d.int32(); m > 0 && d.err == nil; m-- {
+				d.int32() // partition
+				if code := d.int16(); code != 0 && d.err == nil {
+					return kafkaError(code)
+				}
+				d.int64() // base offset
+				d.int64() // log append time
+			}
+		}
+		if d.err != nil {
+			return d.err
+		}
+	}
+	s.batches, s.pending = make(map[int32]*kafkaBatch), 0
+	s.next = (s.next + 1) % int32(len(s.leaders))
+	return nil
+}
+
+// conn returns the connection to the broker, connecting when necessary.
+func (s *kafkaSink) conn(id int32) (*kafkaConn, error) {
+	if conn, ok := s.conns[id]; ok {
+		return conn, nil
+	}
+	addr, ok := s.brokers[id]
+	if !ok {
+		return nil, kafkaError(5)
+	}
+	conn, err := dialKafka(s.ctx, addr, s.tls)
+	if err != nil {
+		return nil, err
+	}
+	s.conns[id] = conn
+	return conn, nil
+}
+
+// Close satisfies the sinks.RowSink interface.
+func (s *kafkaSink) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var err error
+	if len(s.batches) != 0 {
+		err = s.flush()
+	}
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	return err
+}
+
+// Abort satisfies the sinks.RowSink interface. Messages already flushed to the
+// brokers are not removed.
+func (s *kafkaSink) Abort() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// kafkaBatch is a batch of messages for a partition.
+type kafkaBatch struct {
+	records []byte
+	n       int32
+}
+
+// add adds a message to the batch, returning the size of the encoded record.
+func (b *kafkaBatch) add(key, value []byte) int {
+	rec := new(kafkaEncoder)
+	rec.int8(0)            // attributes
+	rec.varint(0)          // timestamp delta
+	rec.varint(int64(b.n)) // offset delta
+	rec.varbytes(key)
+	rec.varbytes(value)
+	rec.varint(0) // headers
+	n := len(b.records)
+	b.records = binary.AppendVarint(b.records, int64(len(rec.b)))
+	b.records = append(b.records, rec.b...)
+	b.n++
+	return len(b.records) - n
+}
+
+// encode encodes the batch as a record batch (magic 2), without compression.
+func (b *kafkaBatch) encode() []byte {
+	ts := time.Now().UnixMilli()
+	body := new(kafkaEncoder)
+	body.int16(0) // attributes
+	body.int32(b.n - 1)
+	body.int64(ts) // base timestamp
+	body.int64(ts) // max timestamp
+	body.int64(-1) // producer id
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(b.n)
+	body.b = append(body.b, b.records...)
+	batch := new(kafkaEncoder)
+	batch.int64(0)                              // base offset
+	batch.int32(int32(4 + 1 + 4 + len(body.b))) // length
+	batch.int32(-1)                             // partition leader epoch
+	batch.int8(2)                               // magic
+	batch.int32(int32(crc32.Checksum(body.b, castagnoli)))
+	batch.b = append(batch.b, body.b...)
+	return batch.b
+}
+
+// kafkaConn is a connection to a Kafka broker.
+type kafkaConn struct {
+	net.Conn
+	correlationID int32
+}
+
+// dialKafka connects to the broker at addr.
+func dialKafka(ctx context.Context, addr string, cfg *tls.Config) (*kafkaConn, error) {
+	dialer := &net.Dialer{Timeout: kafkaTimeout}
+	var conn net.Conn
+	var err error
+	if cfg != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: cfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaConn{Conn: conn}, nil
+}
+
+// roundTrip sends a request to the broker, returning the decoder of the
+// response body.
+func (c *kafkaConn) roundTrip(ctx context.Context, apiKey, version int16, body []byte) (*kafkaDecoder, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(kafkaTimeout + 5*time.Second)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	c.correlationID++
+	req := new(kafkaEncoder)
+	req.int32(0) // size
+	req.int16(apiKey)
+	req.int16(version)
+	req.int32(c.correlationID)
+	req.string("usql")
+	req.b = append(req.b, body...)
+	binary.BigEndian.PutUint32(req.b, uint32(len(req.b)-4))
+	if _, err := c.Write(req.b); err != nil {
+		return nil, err
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(c, size[:]); err != nil {
+		return nil, err
+	}
+	res := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(c, res); err != nil {
+		return nil, err
+	}
+	d := &kafkaDecoder{b: res}
+	if id := d.int32(); d.err == nil && id != c.correlationID {
+		return nil, fmt.Errorf(text.KafkaBadResponse, id, c.correlationID)
+	}
+	return d, d.err
+}
+
+// kafkaEncoder encodes the primitive types of the Kafka protocol.
+type kafkaEncoder struct {
+	b []byte
+}
+
+func (e *kafkaEncoder) int8(v int8) {
+	e.b = append(e.b, byte(v))
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	e.b = binary.BigEndian.AppendUint16(e.b, uint16(v))
+}
+
+func (e *kafkaEncoder) int32(v int32) {
+	e.b = binary.BigEndian.AppendUint32(e.b, uint32(v))
+}
+
+func (e *kafkaEncoder) int64(v int64) {
+	e.b = binary.BigEndian.AppendUint64(e.b, uint64(v))
+}
+
+func (e *kafkaEncoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *kafkaEncoder) bytes(buf []byte) {
+	e.int32(int32(len(buf)))
+	e.b = append(e.b, buf...)
+}
+
+func (e *kafkaEncoder) varint(v int64) {
+	e.b = binary.AppendVarint(e.b, v)
+}
+
+// varbytes encodes buf with a varint length, or as null when nil.
+func (e *kafkaEncoder) varbytes(buf []byte) {
+	if buf == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(buf)))
+	e.b = append(e.b, buf...)
+}
+
+// kafkaDecoder decodes the primitive types of the Kafka protocol, recording
+// the first error.
+type kafkaDecoder struct {
+	b   []byte
+	err error
+}
+
+// next returns the next n bytes.
+func (d *kafkaDecoder) next(n int) []byte {
+	if d.err != nil || n < 0 || len(d.b) < n {
+		if d.err == nil {
+			d.err = io.ErrUnexpectedEOF
+		}
+		return make([]byte, max(n, 8))
+	}
+	buf := d.b[:n]
+	d.b = d.b[n:]
+	return buf
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	return int8(d.next(1)[0])
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	return int16(binary.BigEndian.Uint16(d.next(2)))
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	return int32(binary.BigEndian.Uint32(d.next(4)))
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	return int64(binary.BigEndian.Uint64(d.next(8)))
+}
+
+// string decodes a string, or a nullable string.
+func (d *kafkaDecoder) string() string {
+	n := d.int16()
+	if n == -1 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+// skipInt32s skips an array of int32.
+func (d *kafkaDecoder) skipInt32s() {
+	if n := d.int32(); n > 0 {
+		d.next(4 * int(n))
+	}
+}
+
+// jsonRow encodes the row as a JSON object, in column order. Binary values
+// are encoded as strings when valid UTF-8, otherwise base64 encoded.
+func jsonRow(cols []string, row []interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, col := range cols {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(col)
+		if err != nil {
+			return nil, err
+		}
+		v := row[i]
+		if b, ok := v.([]byte); ok && utf8.Valid(b) {
+			v = string(b)
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			if val, err = json.Marshal(stringValue(v)); err != nil {
+				return nil, err
+			}
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// stringValue returns the string representation of a value.
+func stringValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// murmur2 is the murmur2 hash used by the default partitioner of the Kafka
+// clients, so that messages with the same key are produced to the same
+// partition.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+	)
+	n := len(data)
+	h := seed ^ uint32(n)
+	for i := 0; i+4 <= n; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> 24
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[n&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
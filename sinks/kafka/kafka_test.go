@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+func TestMurmur2(t *testing.T) {
+	// vectors of the Java client's Utils.murmur2
+	tests := []struct {
+		s   string
+		exp int32
+	}{
+		{"21", -973932308},
+		{"foobar", -790332482},
+		{"a-little-bit-long-string", -985981536},
+		{"a-little-bit-longer-string", -1486304829},
+		{"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", -58897971},
+		{"abc", 479470107},
+	}
+	for i, test := range tests {
+		if h := murmur2([]byte(test.s)); h != test.exp {
+			t.Errorf("test %d expected %d, got: %d", i, test.exp, h)
+		}
+	}
+}
+
+func TestKafkaBatchEncode(t *testing.T) {
+	tests := []struct {
+		records [][2][]byte
+		exp     []byte
+	}{
+		{
+			[][2][]byte{{[]byte("k"), []byte("v")}},
+			[]byte{
+				0x10,                             // length
+				0, 0, 0, 0x02, 'k', 0x02, 'v', 0, // attributes, deltas, key, value, headers
+			},
+		},
+		{
+			[][2][]byte{{nil, []byte("a")}, {nil, []byte("bc")}},
+			[]byte{
+				0x0e, 0, 0, 0, 0x01, 0x02, 'a', 0,
+				0x10, 0, 0, 0x02, 0x01, 0x04, 'b', 'c', 0,
+			},
+		},
+	}
+	for i, test := range tests {
+		b := new(kafkaBatch)
+		var n int
+		for _, rec := range test.records {
+			n += b.add(rec[0], rec[1])
+		}
+		if !bytes.Equal(b.records, test.exp) {
+			t.Errorf("test %d expected records %x, got: %x", i, test.exp, b.records)
+		}
+		if n != len(b.records) {
+			t.Errorf("test %d expected added size %d, got: %d", i, len(b.records), n)
+		}
+		buf := b.encode()
+		if l := int(binary.BigEndian.Uint32(buf[8:])); l != len(buf)-12 {
+			t.Errorf("test %d expected length %d, got: %d", i, len(buf)-12, l)
+		}
+		if buf[16] != 2 {
+			t.Errorf("test %d expected magic 2, got: %d", i, buf[16])
+		}
+		if crc := binary.BigEndian.Uint32(buf[17:]); crc != crc32.Checksum(buf[21:], castagnoli) {
+			t.Errorf("test %d expected crc %x, got: %x", i, crc32.Checksum(buf[21:], castagnoli), crc)
+		}
+		if d := int32(binary.BigEndian.Uint32(buf[23:])); d != int32(len(test.records)-1) {
+			t.Errorf("test %d expected last offset delta %d, got: %d", i, len(test.records)-1, d)
+		}
+		if c := int32(binary.BigEndian.Uint32(buf[57:])); c != int32(len(test.records)) {
+			t.Errorf("test %d expected %d records, got: %d", i, len(test.records), c)
+		}
+		if !bytes.Equal(buf[61:], test.exp) {
+			t.Errorf("test %d expected batch records %x, got: %x", i, test.exp, buf[61:])
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package s3 defines and registers usql's Amazon S3 object storage sink.
+package s3
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/ildus/usql/sinks"
+)
+
+func init() {
+	sinks.Register("s3", sinks.Sink{
+		Upload: upload,
+	})
+}
+
+// upload uploads r to an Amazon S3 object, using multipart uploads. The
+// region can be set with the region query parameter, otherwise the bucket's
+// region is used.
+//
+// Credentials are retrieved using the standard credential chain (AWS_PROFILE,
+// AWS_REGION, the shared config and credentials files, and instance roles).
+func upload(ctx context.Context, u *url.URL, r io.Reader) error {
+	cfg := aws.NewConfig()
+	if region := u.Query().Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return err
+	}
+	bucket, key := sinks.ObjectPath(u)
+	if aws.StringValue(sess.Config.Region) == "" {
+		region, err := s3manager.GetBucketRegion(ctx, sess, bucket, "us-east-1")
+		if err != nil {
+			return err
+		}
+		sess.Config.Region = aws.String(region)
+	}
+	_, err = s3manager.NewUploader(sess).UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	})
+	return err
+}
@@ -0,0 +1,65 @@
+// Package sinks handles the registration of the export destinations of query
+// results, such as object storage services and message streams.
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Sink is an export destination of query results, identified by its url
+// scheme. Exactly one of Upload or Open is set.
+type Sink struct {
+	// Upload uploads the data read from the reader to the object of an object
+	// storage url, returning once the reader is exhausted and the upload
+	// completed. Canceling the context discards the upload.
+	Upload func(context.Context, *url.URL, io.Reader) error
+	// Open opens a destination receiving the rows of the results, instead of
+	// the encoded output.
+	Open func(context.Context, *url.URL) (RowSink, error)
+}
+
+// RowSink is an export destination receiving the rows of the results, one at
+// a time, such as a message stream.
+type RowSink interface {
+	// Columns starts a result set with the column names.
+	Columns([]string) error
+	// Write writes a row of the current result set.
+	Write([]interface{}) error
+	// Close flushes the written rows, returning any error.
+	Close() error
+	// Abort discards the unflushed rows, if the sink has not been closed.
+	Abort()
+}
+
+// sinks are the registered sinks, by url scheme.
+var sinks = make(map[string]Sink)
+
+// Register registers a sink for the url scheme and its aliases.
+func Register(scheme string, s Sink, aliases ...string) {
+	for _, name := range append([]string{scheme}, aliases...) {
+		if _, ok := sinks[name]; ok {
+			panic(fmt.Sprintf("sink %s is already registered", name))
+		}
+		sinks[name] = s
+	}
+}
+
+// Lookup returns the sink registered for the scheme of urlstr.
+func Lookup(urlstr string) (Sink, bool) {
+	scheme, _, ok := strings.Cut(urlstr, "://")
+	if !ok {
+		return Sink{}, false
+	}
+	s, ok := sinks[strings.ToLower(scheme)]
+	return s, ok
+}
+
+// ObjectPath returns the bucket (or container) and object key of an object
+// storage url.
+func ObjectPath(u *url.URL) (string, string) {
+	return u.Host, strings.TrimPrefix(u.Path, "/")
+}
@@ -0,0 +1,162 @@
+package stmt
+
+import (
+	"github.com/ildus/usql/text"
+)
+
+// CondState is the state of a conditional block (\if ... \endif).
+type CondState int
+
+// Conditional block states.
+const (
+	// CondNone indicates not being in a conditional block.
+	CondNone CondState = iota
+	// CondTrue indicates being in a true \if or \elif branch.
+	CondTrue
+	// CondFalse indicates being in a false \if or \elif branch, where a
+	// following \elif or \else may still become active.
+	CondFalse
+	// CondIgnored indicates being in a branch that is inactive because a
+	// previous branch was taken, or because the enclosing block is inactive.
+	CondIgnored
+	// CondElseTrue indicates being in an active \else branch.
+	CondElseTrue
+	// CondElseFalse indicates being in an inactive \else branch.
+	CondElseFalse
+)
+
+// cond is a conditional block on the conditional stack.
+type cond struct {
+	state CondState
+	// n is the length of the statement buffer when the block's current branch
+	// was entered.
+	n int
+	// loop is the loop of a \while block, nil for \if blocks.
+	loop *loop
+}
+
+// Cond returns the state of the innermost conditional block.
+func (b *Stmt) Cond() CondState {
+	if len(b.conds) == 0 {
+		return CondNone
+	}
+	return b.conds[len(b.conds)-1].state
+}
+
+// CondDepth returns the number of open conditional blocks.
+func (b *Stmt) CondDepth() int {
+	return len(b.conds)
+}
+
+// Active returns whether statements and commands read by the statement
+// buffer should be processed, ie, the statement buffer is not in an inactive
+// branch of a conditional block.
+func (b *Stmt) Active() bool {
+	switch b.Cond() {
+	case CondNone, CondTrue, CondElseTrue:
+		return true
+	}
+	return false
+}
+
+// If opens a conditional block (\if). The value of v is only relevant when
+// the statement buffer is active.
+func (b *Stmt) If(v bool) {
+	state := CondIgnored
+	switch {
+	case b.Active() && v:
+		state = CondTrue
+	case b.Active():
+		state = CondFalse
+	}
+	b.conds = append(b.conds, cond{state: state, n: b.Len})
+}
+
+// ElifNeedsValue returns whether the next \elif branch of the innermost
+// conditional block could become active, and thus whether its expression
+// needs to be evaluated.
+func (b *Stmt) ElifNeedsValue() bool {
+	return b.Cond() == CondFalse
+}
+
+// Elif switches to the next branch (\elif) of the innermost conditional
+// block.
+func (b *Stmt) Elif(v bool) error {
+	c, err := b.topIf()
+	if err != nil {
+		return err
+	}
+	switch c.state {
+	case CondTrue:
+		c.state = CondIgnored
+	case CondFalse:
+		if v {
+			c.state = CondTrue
+		}
+	case CondElseTrue, CondElseFalse:
+		return text.ErrAfterElse
+	}
+	c.n = b.Len
+	return nil
+}
+
+// Else switches to the \else branch of the innermost conditional block.
+func (b *Stmt) Else() error {
+	c, err := b.topIf()
+	if err != nil {
+		return err
+	}
+	switch c.state {
+	case CondTrue, CondIgnored:
+		c.state = CondElseFalse
+	case CondFalse:
+		c.state = CondElseTrue
+	case CondElseTrue, CondElseFalse:
+		return text.ErrAfterElse
+	}
+	c.n = b.Len
+	return nil
+}
+
+// Endif closes the innermost conditional block (\endif).
+func (b *Stmt) Endif() error {
+	if _, err := b.topIf(); err != nil {
+		return err
+	}
+	b.conds = b.conds[:len(b.conds)-1]
+	return nil
+}
+
+// Discard discards any statement text read since entering the current
+// branch of the innermost conditional block. Used to drop statements from
+// inactive branches, while retaining any statement text preceding the block.
+func (b *Stmt) Discard() {
+	if len(b.conds) == 0 {
+		return
+	}
+	n := b.conds[len(b.conds)-1].n
+	if n < b.Len {
+		b.Buf, b.Len = b.Buf[:n], n
+		for i, v := range b.Vars {
+			if v.I >= n {
+				b.Vars = b.Vars[:i]
+				break
+			}
+		}
+	}
+	if b.Len == 0 {
+		b.Prefix = ""
+	}
+	b.quote, b.quoteDollarTag = 0, ""
+	b.multilineComment = false
+	b.balanceCount, b.blockCount = 0, 0
+	b.ready = false
+}
+
+// topIf returns the innermost conditional block, when it is an \if block.
+func (b *Stmt) topIf() (*cond, error) {
+	if len(b.conds) == 0 || b.conds[len(b.conds)-1].loop != nil {
+		return nil, text.ErrNoMatchingIf
+	}
+	return &b.conds[len(b.conds)-1], nil
+}
@@ -0,0 +1,76 @@
+package stmt
+
+import (
+	"testing"
+
+	"github.com/ildus/usql/text"
+)
+
+func TestCond(t *testing.T) {
+	tests := []struct {
+		ops    []string
+		active []bool
+		err    error
+	}{
+		{[]string{"if t", "endif"}, []bool{true, true}, nil},
+		{[]string{"if f", "endif"}, []bool{false, true}, nil},
+		{[]string{"if f", "else", "endif"}, []bool{false, true, true}, nil},
+		{[]string{"if t", "else", "endif"}, []bool{true, false, true}, nil},
+		{[]string{"if f", "elif f", "elif t", "else", "endif"}, []bool{false, false, true, false, true}, nil},
+		{[]string{"if t", "elif t", "else"}, []bool{true, false, false}, nil},
+		{[]string{"if f", "if t", "elif t", "else", "endif", "else"}, []bool{false, false, false, false, false, true}, nil},
+		{[]string{"if t", "if f", "else", "endif", "endif"}, []bool{true, false, true, true, true}, nil},
+		{[]string{"endif"}, nil, text.ErrNoMatchingIf},
+		{[]string{"elif t"}, nil, text.ErrNoMatchingIf},
+		{[]string{"else"}, nil, text.ErrNoMatchingIf},
+		{[]string{"if t", "endif", "endif"}, []bool{true, true}, text.ErrNoMatchingIf},
+		{[]string{"if t", "else", "else"}, []bool{true, false}, text.ErrAfterElse},
+		{[]string{"if f", "else", "elif t"}, []bool{false, true}, text.ErrAfterElse},
+	}
+	for i, test := range tests {
+		b := new(Stmt)
+		var err error
+		for j, op := range test.ops {
+			switch op {
+			case "if t", "if f":
+				b.If(op == "if t")
+			case "elif t", "elif f":
+				err = b.Elif(op == "elif t")
+			case "else":
+				err = b.Else()
+			case "endif":
+				err = b.Endif()
+			}
+			if err != nil {
+				break
+			}
+			if b.Active() != test.active[j] {
+				t.Errorf("test %d expected active %t after %q (%d), got: %t", i, test.active[j], op, j, b.Active())
+			}
+		}
+		if err != test.err {
+			t.Errorf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+	}
+}
+
+func TestCondDiscard(t *testing.T) {
+	b := new(Stmt)
+	b.AppendString("select 1", "\n")
+	b.If(false)
+	b.AppendString("select 2;", "\n")
+	b.Discard()
+	if s := b.String(); s != "select 1" {
+		t.Errorf("expected `select 1` after discard, got: `%s`", s)
+	}
+	if err := b.Else(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b.AppendString("+ 1", " ")
+	if err := b.Endif(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := b.String(); s != "select 1 + 1" {
+		t.Errorf("expected `select 1 + 1`, got: `%s`", s)
+	}
+}
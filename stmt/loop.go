@@ -0,0 +1,134 @@
+package stmt
+
+import (
+	"strings"
+
+	"github.com/ildus/usql/text"
+)
+
+// loop is the source of a \while loop, recorded while the loop is active.
+type loop struct {
+	// expr is the raw (non-interpolated) loop condition.
+	expr string
+	// depth is the number of enclosing loops.
+	depth int
+	// lines are the raw lines read since entering the loop.
+	lines [][]rune
+}
+
+// line is a line to be read again by the statement buffer.
+type line struct {
+	r []rune
+	// depth is the loop depth of the replayed loop. Lines are only recorded by
+	// loops opened while the loop is replayed.
+	depth int
+}
+
+// read reads the next line, from the lines being replayed or the rune source,
+// recording the line in the active loops.
+func (b *Stmt) read() ([]rune, error) {
+	var r []rune
+	depth := 0
+	if len(b.replay) != 0 {
+		r, depth = b.replay[0].r, b.replay[0].depth
+		b.replay = b.replay[1:]
+	} else {
+		var err error
+		if r, err = b.f(); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range b.conds {
+		if c.loop != nil && c.state == CondTrue && c.loop.depth >= depth {
+			c.loop.lines = append(c.loop.lines, append([]rune(nil), r...))
+		}
+	}
+	return r, nil
+}
+
+// InLoop returns whether the innermost block is a \while loop.
+func (b *Stmt) InLoop() bool {
+	return len(b.conds) != 0 && b.conds[len(b.conds)-1].loop != nil
+}
+
+// While opens a loop block (\while), with the raw loop condition expr. The
+// value of v is only relevant when the statement buffer is active. While the
+// loop is active, the lines read are recorded, and replayed by Endwhile.
+func (b *Stmt) While(v bool, expr string) {
+	var depth int
+	for _, c := range b.conds {
+		if c.loop != nil {
+			depth++
+		}
+	}
+	b.If(v)
+	c := &b.conds[len(b.conds)-1]
+	c.loop = &loop{expr: strings.TrimSpace(expr), depth: depth}
+	// record the remainder of the current line
+	if c.state == CondTrue && b.rlen != 0 {
+		c.loop.lines = append(c.loop.lines, append([]rune(nil), b.r[:b.rlen]...))
+	}
+}
+
+// Endwhile closes the innermost loop block (\endwhile). When the loop is
+// active, the loop is read again, starting with the \while command
+// re-evaluating the loop condition.
+func (b *Stmt) Endwhile() error {
+	if !b.InLoop() {
+		return text.ErrNoMatchingWhile
+	}
+	c := b.conds[len(b.conds)-1]
+	b.conds = b.conds[:len(b.conds)-1]
+	if c.state != CondTrue {
+		return nil
+	}
+	l := c.loop
+	replay := []line{{r: []rune(`\while ` + l.expr), depth: l.depth}}
+	// the last line contains the \endwhile, followed by the unread runes
+	if n := len(l.lines); n != 0 {
+		for _, r := range l.lines[:n-1] {
+			replay = append(replay, line{r: r, depth: l.depth})
+		}
+		last := l.lines[n-1]
+		last = last[:max(0, len(last)-b.rlen)]
+		if i := runesLastIndexString(last, `\endwhile`); i != -1 {
+			last = last[:i]
+		}
+		if !isEmptyLine(last, 0, len(last)) {
+			replay = append(replay, line{r: last, depth: l.depth})
+		}
+	}
+	replay = append(replay, line{r: []rune(`\endwhile`), depth: l.depth})
+	if b.rlen != 0 {
+		replay = append(replay, line{r: append([]rune(nil), b.r[:b.rlen]...), depth: l.depth})
+		b.r, b.rlen = nil, 0
+	}
+	b.replay = append(replay, b.replay...)
+	return nil
+}
+
+// Break stops all open loops, skipping the remainder of the loops (for
+// example, when interrupted).
+func (b *Stmt) Break() {
+	for i, c := range b.conds {
+		if c.loop == nil {
+			continue
+		}
+		for j := i; j < len(b.conds); j++ {
+			b.conds[j].state = CondIgnored
+		}
+		return
+	}
+}
+
+// runesLastIndexString returns the last index of s in r, or -1 if not
+// present.
+func runesLastIndexString(r []rune, s string) int {
+	n := []rune(s)
+	for i := len(r) - len(n); i >= 0; i-- {
+		if string(r[i:i+len(n)]) == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+package stmt
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ildus/usql/text"
+)
+
+func TestWhile(t *testing.T) {
+	tests := []struct {
+		s   string
+		n   int
+		exp []string
+		err error
+	}{
+		{"\\while c\nselect 1;\n\\endwhile\nselect 2;", 2, []string{"select 1;", "select 1;", "select 2;"}, nil},
+		{"\\while c\nselect 1;\n\\endwhile\nselect 2;", 0, []string{"select 2;"}, nil},
+		{"\\while c\nselect 1;\n\\if f\nselect 2;\n\\endif\n\\endwhile", 2, []string{"select 1;", "select 1;"}, nil},
+		{"\\while c\nselect 1; \\endwhile\nselect 2;", 3, []string{"select 1;", "select 1;", "select 1;", "select 2;"}, nil},
+		{"\\while c\n\\while d\nselect 1;\n\\endwhile\nselect 2;\n\\endwhile", 2, []string{"select 1;", "select 2;", "select 1;", "select 2;"}, nil},
+		{"\\while c\nselect 1;\n\\while c\n\\endwhile\n\\endwhile", 1, []string{"select 1;"}, nil},
+		{"\\endwhile", 0, nil, text.ErrNoMatchingWhile},
+		{"\\if t\n\\endwhile", 0, nil, text.ErrNoMatchingWhile},
+		{"\\while c\n\\endif", 1, nil, text.ErrNoMatchingIf},
+		{"\\while c\nselect 1;", 1, []string{"select 1;"}, nil},
+	}
+	for i, test := range tests {
+		b := New(sp(test.s, "\n"))
+		// loop c runs n times, and nested loop d runs once per iteration of c
+		c, d := 0, make(map[int]bool)
+		var stmts []string
+		var err error
+		for {
+			var cmd, params string
+			if cmd, params, err = b.Next(func(s string, _ bool) (bool, string, error) {
+				return false, s, nil
+			}); err != nil {
+				break
+			}
+			if !b.Active() {
+				b.Discard()
+			}
+			if b.Ready() {
+				stmts = append(stmts, strings.TrimSpace(b.String()))
+				b.Reset(nil)
+			}
+			switch cmd {
+			case `\while`:
+				v := false
+				switch strings.TrimSpace(params) {
+				case "c":
+					v, c = c < test.n, c+1
+				case "d":
+					v, d[c] = !d[c], true
+				}
+				b.While(v, params)
+			case `\endwhile`:
+				err = b.Endwhile()
+			case `\if`:
+				b.If(strings.TrimSpace(params) == "t")
+			case `\endif`:
+				err = b.Endif()
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if err != test.err {
+			t.Errorf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+		if s, exp := strings.Join(stmts, "|"), strings.Join(test.exp, "|"); s != exp {
+			t.Errorf("test %d expected %q, got: %q", i, exp, s)
+		}
+	}
+}
+
+func TestWhileBreak(t *testing.T) {
+	b := new(Stmt)
+	b.While(true, "c")
+	b.If(true)
+	b.Break()
+	if b.Active() {
+		t.Errorf("expected inactive after break")
+	}
+	if err := b.Endif(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := b.Endwhile(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !b.Active() || len(b.replay) != 0 {
+		t.Errorf("expected active without replay after break, got: %t, %d", b.Active(), len(b.replay))
+	}
+}
@@ -74,6 +74,16 @@ type Stmt struct {
 	balanceCount int
 	// ready indicates that a complete statement has been parsed
 	ready bool
+	// conds is the stack of open conditional blocks
+	conds []cond
+	// replay are the lines of \while loops to be read again
+	replay []line
+	// term is the driver specific statement terminator
+	term *Terminator
+	// delimiter is the statement delimiter changed by the DELIMITER command
+	delimiter string
+	// blockCount is the BEGIN ... END block nesting count
+	blockCount int
 }
 
 // New creates a new Stmt using the supplied rune source f.
@@ -142,7 +152,7 @@ func (b *Stmt) Reset(r []rune) {
 	// multicomment state
 	b.multilineComment = false
 	// balance state
-	b.balanceCount = 0
+	b.balanceCount, b.blockCount = 0, 0
 	// ready state
 	b.ready = false
 	if r != nil {
@@ -192,12 +202,16 @@ func (b *Stmt) Next(unquote func(string, bool) (bool, string, error)) (string, s
 	var i int
 	// no runes to process, grab more
 	if b.rlen == 0 {
-		b.r, err = b.f()
+		b.r, err = b.read()
 		if err != nil {
 			return "", "", err
 		}
 		b.rlen = len(b.r)
 	}
+	// batch terminator or DELIMITER command
+	if b.readTerminatorLine() {
+		return "", "", nil
+	}
 	var cmd, params string
 	var ok bool
 parse:
@@ -216,6 +230,12 @@ parse:
 		case b.multilineComment:
 			i, ok = readMultilineComment(b.r, i, b.rlen)
 			b.multilineComment = !ok
+		// changed statement delimiter
+		case b.delimiter != "" && b.hasDelimiter(i):
+			b.r = append(b.r[:i], b.r[i+len([]rune(b.delimiter)):]...)
+			b.rlen = len(b.r)
+			b.ready = true
+			break parse
 		// start of single or double quoted string
 		case c == '\'' || c == '"':
 			b.quote = c
@@ -264,6 +284,9 @@ parse:
 			b.balanceCount = max(0, b.balanceCount-1)
 		// continue processing quoted string, multiline comment, or unbalanced statements
 		case b.quote != 0 || b.multilineComment || b.balanceCount != 0:
+		// BEGIN ... END block nesting
+		case b.term != nil && b.term.Blocks && unicode.IsLetter(c):
+			i = b.readBlockWord(b.r, i, b.rlen)
 		// skip escaped backslash, semicolon, colon
 		case c == '\\' && (next == '\\' || next == ';' || next == ':'):
 			// FIXME: the below works, but it may not make sense to keep this enabled.
@@ -289,7 +312,7 @@ parse:
 			b.rlen = len(b.r)
 			break parse
 		// terminated
-		case c == ';':
+		case c == ';' && b.terminates(i):
 			b.ready = true
 			i++
 			break parse
@@ -417,6 +440,14 @@ func WithAllowHashComments(enable bool) Option {
 	}
 }
 
+// WithTerminator is a statement buffer option to set driver specific statement
+// termination (ie, GO batches, DELIMITER, or BEGIN ... END blocks).
+func WithTerminator(t *Terminator) Option {
+	return func(b *Stmt) {
+		b.term, b.delimiter = t, ""
+	}
+}
+
 // IsSpaceOrControl is a special test for either a space or a control (ie, \b)
 // characters.
 func IsSpaceOrControl(r rune) bool {
@@ -0,0 +1,163 @@
+package stmt
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Terminator configures driver specific statement termination, in addition
+// to the standard semicolon terminator.
+type Terminator struct {
+	// Batch is a terminator that ends the statement when on a line by itself
+	// (ie, GO for SQL Server, or / for Oracle).
+	Batch string
+	// BatchPrefixes are the statement prefixes (ie, CREATE PROCEDURE) for
+	// which a semicolon does not end the statement, and only the Batch
+	// terminator does.
+	BatchPrefixes []string
+	// Delimiter allows the DELIMITER command (ie, DELIMITER //) to change the
+	// statement terminator, as with the mysql command-line client.
+	Delimiter bool
+	// Blocks treats semicolons within BEGIN ... END and CASE ... END blocks as
+	// part of the statement.
+	Blocks bool
+	// BlockPrefixes are the statement prefixes (ie, CREATE PROCEDURE) for
+	// which blocks are recognized. When empty, blocks are recognized in all
+	// statements.
+	BlockPrefixes []string
+}
+
+// hasPrefix returns true when prefix starts with one of prefixes.
+func hasPrefix(prefix string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if prefix == p || strings.HasPrefix(prefix, p+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Delimiter returns the statement delimiter changed by the DELIMITER
+// command, if any.
+func (b *Stmt) Delimiter() string {
+	return b.delimiter
+}
+
+// readTerminatorLine reads a batch terminator or DELIMITER command from the
+// unprocessed runes, when they consist of only that line, returning true
+// when the line was consumed.
+func (b *Stmt) readTerminatorLine() bool {
+	if b.term == nil || b.quote != 0 || b.multilineComment {
+		return false
+	}
+	line := strings.TrimSpace(string(b.r[:b.rlen]))
+	switch {
+	case b.term.Batch != "" && strings.EqualFold(line, b.term.Batch):
+		b.ready = b.Len != 0
+	case b.term.Delimiter && b.Len == 0 && len(line) > 10 && strings.EqualFold(line[:10], "delimiter "):
+		b.delimiter = strings.TrimSpace(line[10:])
+		if b.delimiter == ";" {
+			b.delimiter = ""
+		}
+	default:
+		return false
+	}
+	b.r, b.rlen = b.r[:0], 0
+	return true
+}
+
+// terminates returns true when a semicolon at position i in the unprocessed
+// runes ends the statement.
+func (b *Stmt) terminates(i int) bool {
+	switch {
+	case b.term == nil:
+		return true
+	case b.delimiter != "", b.blockCount != 0:
+		return false
+	case len(b.term.BatchPrefixes) != 0:
+		return !hasPrefix(b.prefixAt(i), b.term.BatchPrefixes)
+	}
+	return true
+}
+
+// prefixAt returns the prefix of the statement including the unprocessed
+// runes up to position i.
+func (b *Stmt) prefixAt(i int) string {
+	r := append(append(append([]rune{}, b.Buf...), lineend...), b.r[:i]...)
+	return findPrefix(r, prefixCount, b.allowCComments, b.allowHashComments, b.allowMultilineComments)
+}
+
+// hasDelimiter returns true when the unprocessed runes contain the changed
+// statement delimiter at position i.
+func (b *Stmt) hasDelimiter(i int) bool {
+	d := []rune(b.delimiter)
+	if len(d) == 0 || b.rlen-i < len(d) {
+		return false
+	}
+	for j, c := range d {
+		if unicode.ToLower(b.r[i+j]) != unicode.ToLower(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// readBlockWord reads the word starting at position i in r, adjusting the
+// block count when it begins or ends a BEGIN ... END or CASE ... END block,
+// and returning the position of the last rune of the word.
+func (b *Stmt) readBlockWord(r []rune, i, end int) int {
+	if i > 0 && (isWordRune(r[i-1]) || r[i-1] == '.' || r[i-1] == '`' || r[i-1] == '[') {
+		return readWord(r, i, end) - 1
+	}
+	j := readWord(r, i, end)
+	word := strings.ToUpper(string(r[i:j]))
+	switch {
+	case word != "BEGIN" && word != "CASE" && word != "END":
+		return j - 1
+	case len(b.term.BlockPrefixes) != 0 && !hasPrefix(b.prefixAt(i), b.term.BlockPrefixes):
+		return j - 1
+	}
+	switch word {
+	case "BEGIN":
+		// BEGIN; BEGIN TRANSACTION, etc start a transaction, not a block
+		switch k, _ := findNonSpace(r, j, end); {
+		case k < end && r[k] == ';':
+		case k < end && isTransactionWord(strings.ToUpper(string(r[k:readWord(r, k, end)]))):
+		default:
+			b.blockCount++
+		}
+	case "CASE":
+		b.blockCount++
+	case "END":
+		// END IF, END LOOP, etc end blocks that were not counted
+		k, _ := findNonSpace(r, j, end)
+		switch strings.ToUpper(string(r[k:readWord(r, k, end)])) {
+		case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+		default:
+			b.blockCount = max(0, b.blockCount-1)
+		}
+	}
+	return j - 1
+}
+
+// isTransactionWord returns true when s following BEGIN starts a
+// transaction.
+func isTransactionWord(s string) bool {
+	switch s {
+	case "TRANSACTION", "TRAN", "WORK", "DISTRIBUTED", "DEFERRED", "IMMEDIATE", "EXCLUSIVE":
+		return true
+	}
+	return false
+}
+
+// readWord returns the position after the word starting at position i in r.
+func readWord(r []rune, i, end int) int {
+	for ; i < end && isWordRune(r[i]); i++ {
+	}
+	return i
+}
+
+// isWordRune returns true when c is part of a word.
+func isWordRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,65 @@
+package stmt
+
+import (
+	"io"
+	"os/user"
+	"reflect"
+	"testing"
+
+	"github.com/ildus/usql/env"
+)
+
+func TestTerminator(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	unquote := env.Unquote(u, false, env.Vars{})
+	mysql := &Terminator{Delimiter: true, Blocks: true, BlockPrefixes: []string{"CREATE PROCEDURE"}}
+	sqlserver := &Terminator{Batch: "GO", BatchPrefixes: []string{"CREATE PROCEDURE"}, Blocks: true}
+	oracle := &Terminator{Batch: "/", BatchPrefixes: []string{"CREATE OR REPLACE PROCEDURE", "BEGIN"}}
+	tests := []struct {
+		term  *Terminator
+		s     string
+		stmts []string
+	}{
+		{nil, "select 1;\nselect 2;", []string{"select 1;", "select 2;"}}, // 0
+		{mysql, "select 1;\nselect 2;", []string{"select 1;", "select 2;"}},
+		{mysql, "begin;\nselect 1;", []string{"begin;", "select 1;"}},
+		{mysql, "begin work;\nselect 1;", []string{"begin work;", "select 1;"}},
+		{mysql, "create procedure p()\nbegin\n  select 1;\n  select 2;\nend;", []string{"create procedure p()\nbegin\n  select 1;\n  select 2;\nend;"}},
+		{mysql, "create procedure p() begin if 1 then select 1; end if; end;", []string{"create procedure p() begin if 1 then select 1; end if; end;"}}, // 5
+		{mysql, "select case when 1 then 2 end as `end`;", []string{"select case when 1 then 2 end as `end`;"}},
+		{mysql, "select begin, end from t;", []string{"select begin, end from t;"}},
+		{mysql, "delimiter //\ncreate procedure p() select 1; //\ndelimiter ;\nselect 2;", []string{"create procedure p() select 1; ", "select 2;"}},
+		{mysql, "DELIMITER $$\nselect 1$$ select 2$$", []string{"select 1", "select 2"}},
+		{sqlserver, "select 1;\nselect 2\nGO", []string{"select 1;", "select 2"}},
+		{sqlserver, "create procedure p as\nselect 1;\nselect 2;\ngo\nselect 3;", []string{"create procedure p as\nselect 1;\nselect 2;", "select 3;"}}, // 10
+		{sqlserver, "if 1 = 1 begin\nselect 1;\nend;", []string{"if 1 = 1 begin\nselect 1;\nend;"}},
+		{sqlserver, "begin tran;\nselect 1;", []string{"begin tran;", "select 1;"}},
+		{oracle, "create or replace procedure p as\n  x number;\nbegin\n  null;\nend;\n/\nselect 1 from dual;", []string{"create or replace procedure p as\n  x number;\nbegin\n  null;\nend;", "select 1 from dual;"}},
+		{oracle, "begin\n  null;\nend;\n/", []string{"begin\n  null;\nend;"}},
+		{oracle, "select 1 from dual\n/", []string{"select 1 from dual"}}, // 15
+	}
+	for i, test := range tests {
+		b := New(sp(test.s, "\n"), WithTerminator(test.term), WithAllowMultilineComments(true))
+		var stmts []string
+	loop:
+		for {
+			_, _, err := b.Next(unquote)
+			switch {
+			case err == io.EOF:
+				break loop
+			case err != nil:
+				t.Fatalf("test %d did not expect error, got: %v", i, err)
+			}
+			if b.Ready() {
+				stmts = append(stmts, b.String())
+				b.Reset(nil)
+			}
+		}
+		if !reflect.DeepEqual(stmts, test.stmts) {
+			t.Errorf("test %d expected statements %s, got: %s", i, jj(test.stmts), jj(stmts))
+		}
+	}
+}
@@ -46,7 +46,7 @@ var (
 	// ErrTooManyRows is the too many rows error.
 	ErrTooManyRows = errors.New("too many rows")
 	// ErrInvalidFormatType is the invalid format type error.
-	ErrInvalidFormatType = errors.New(`\pset: allowed formats are unaligned, aligned, wrapped, html, asciidoc, latex, latex-longtable, troff-ms, json, csv`)
+	ErrInvalidFormatType = errors.New(`\pset: allowed formats are unaligned, aligned, wrapped, html, asciidoc, latex, latex-longtable, troff-ms, json, csv, chart, interactive, count`)
 	// ErrInvalidFormatPagerType is the invalid format pager error.
 	ErrInvalidFormatPagerType = errors.New(`\pset: allowed pager values are on, off, always`)
 	// ErrInvalidFormatExpandedType is the invalid format expanded error.
@@ -69,4 +69,61 @@ var (
 	ErrNotSupported = errors.New("not supported")
 	// ErrWrongNumberOfArguments is the wrong number of arguments error.
 	ErrWrongNumberOfArguments = errors.New("wrong number of arguments")
+	// ErrExplainAnalyzeNotSupported is the explain analyze not supported error.
+	ErrExplainAnalyzeNotSupported = errors.New(`\explain analyze not supported by driver`)
+	// ErrDiffColumnsMismatch is the diff columns mismatch error.
+	ErrDiffColumnsMismatch = errors.New(`\diff: queries must return the same columns`)
+	// ErrNoMatchingIf is the no matching if error.
+	ErrNoMatchingIf = errors.New(`no matching \if`)
+	// ErrAfterElse is the cannot occur after else error.
+	ErrAfterElse = errors.New(`cannot occur after \else`)
+	// ErrUnterminatedIf is the unterminated if error.
+	ErrUnterminatedIf = errors.New(`reached EOF without finding closing \endif(s)`)
+	// ErrNoMatchingWhile is the no matching while error.
+	ErrNoMatchingWhile = errors.New(`no matching \while`)
+	// ErrUnterminatedWhile is the unterminated while error.
+	ErrUnterminatedWhile = errors.New(`reached EOF without finding closing \endwhile(s)`)
+	// ErrChartTooFewColumns is the chart too few columns error.
+	ErrChartTooFewColumns = errors.New(`chart results must have at least 2 columns`)
+	// ErrPasswordInvalidCharacter is the password invalid character error.
+	ErrPasswordInvalidCharacter = errors.New("password contains a character not supported by driver")
+	// ErrUnknownConnection is the unknown connection error.
+	ErrUnknownConnection = errors.New("unknown connection")
+	// ErrEmptyQuery is the empty query error.
+	ErrEmptyQuery = errors.New("empty query")
+	// ErrInvalidCopyFormat is the invalid copy format error.
+	ErrInvalidCopyFormat = errors.New(`\copyin: allowed formats are csv, text`)
+	// ErrCopyInTransaction is the copy in transaction error.
+	ErrCopyInTransaction = errors.New("copy from standard input cannot be used within a transaction")
+	// ErrImportTransaction is the import transaction error.
+	ErrImportTransaction = errors.New(`\import cannot be used within a transaction`)
+	// ErrNoColumnsToImport is the no columns to import error.
+	ErrNoColumnsToImport = errors.New("no columns to import")
+	// ErrNoSuchSession is the no such session error.
+	ErrNoSuchSession = errors.New("no such session")
+	// ErrPassphraseMismatch is the passphrase mismatch error.
+	ErrPassphraseMismatch = errors.New("passphrases do not match")
+	// ErrSyslogNotSupported is the syslog not supported error.
+	ErrSyslogNotSupported = errors.New("syslog not supported on this platform")
+	// ErrMissingStorageAccount is the missing storage account error.
+	ErrMissingStorageAccount = errors.New("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING must be set")
+	// ErrMissingSchemaRegistry is the missing schema registry error.
+	ErrMissingSchemaRegistry = errors.New("avro format requires the registry parameter with the schema registry url")
+	// ErrNoResultToBrowse is the no result to browse error.
+	ErrNoResultToBrowse = errors.New("no query result to browse")
+	// ErrNoLastResult is the no last result error.
+	ErrNoLastResult = errors.New("no query result to process")
+	// ErrStoreTruncated is the store truncated result error.
+	ErrStoreTruncated = errors.New("cannot store a result with rows that were not recorded, limit the query instead")
+	// ErrStatementNotConfirmed is the statement not confirmed error.
+	ErrStatementNotConfirmed = errors.New("destructive statement not confirmed, not executed")
+	// ErrStatementTimeout is the statement timeout error.
+	ErrStatementTimeout = errors.New("canceling statement due to statement timeout")
+	// ErrNoXJoinEngine is the no xjoin engine error.
+	ErrNoXJoinEngine = errors.New("\\xjoin requires the sqlite3 or moderncsqlite driver")
+	// ErrInvalidComment is the invalid comment error.
+	ErrInvalidComment = errors.New("invalid comment, must be: on table|column NAME is 'TEXT'")
+	// ErrBackslashCommandsNotSupported is the backslash commands not supported
+	// error.
+	ErrBackslashCommandsNotSupported = errors.New("backslash commands are not supported")
 )
@@ -32,6 +32,8 @@ var (
 	PasswordsDoNotMatch   = `Passwords do not match, trying again ...`
 	NewPassword           = `Enter new password: `
 	ConfirmPassword       = `Confirm password: `
+	EnterPassphrase       = `Enter passphrase for %s: `
+	ConfirmPassphrase     = `Confirm passphrase: `
 	PasswordChangeFailed  = `\password for %q failed: %v`
 	CouldNotSetVariable   = `could not set variable %q`
 	// PasswordChangeSucceeded = `\password succeeded for %q`
@@ -41,7 +43,7 @@ var (
 	HelpCommandPrefix = `Type:  `
 	HelpCommands      = [][]string{
 		{`copyright`, `for distribution terms`},
-		//{`h`, `for help with SQL commands`},
+		{`h`, `for help with SQL commands`},
 		{`?`, `for help with ` + CommandName + ` commands`},
 		{`g`, `or terminate with semicolon to execute query`},
 		{`q`, `to quit`},
@@ -53,22 +55,36 @@ var (
 	FormatFieldNameSetMap   = map[string]string{
 		`border`:                   `Border style is %d.`,
 		`columns`:                  `Target width is %d.`,
+		`compress`:                 `Output compression is %s.`,
 		`expanded`:                 `Expanded display is %s.`,
 		`expanded_auto`:            `Expanded display is used automatically.`,
+		`expanded_fieldwidth`:      `Expanded field name width is %d.`,
+		`expanded_header`:          `Expanded record headers are %s.`,
+		`expanded_recordsep`:       `Expanded record separator is %q.`,
 		`fieldsep`:                 `Field separator is %q.`,
 		`fieldsep_zero`:            `Field separator is zero byte.`,
+		`float_precision`:          `Float precision is %s.`,
 		`footer`:                   `Default footer is %s.`,
 		`format`:                   `Output format is %s.`,
 		`linestyle`:                `Line style is %s.`,
 		`locale`:                   `Locale is %q.`,
+		`mask`:                     `Column masking is %s.`,
+		`mask_columns`:             `Masked columns are %q.`,
+		`maxcolwidth`:              `Maximum column width is %d.`,
 		`null`:                     `Null display is %q.`,
+		`nullstyle`:                `Null style is %s.`,
 		`numericlocale`:            `Locale-adjusted numeric output is %s.`,
 		`pager`:                    `Pager usage is %s.`,
+		`pager_format`:             `Pager format is %s.`,
 		`pager_min_lines`:          `Pager won't be used for less than %d line(s).`,
 		`recordsep`:                `Field separator is %q.`,
 		`recordsep_zero`:           `Record separator is zero byte.`,
+		`rowcount_estimate`:        `Row count estimate is %s.`,
+		`schema_header`:            `Schema header is %s.`,
 		`tableattr`:                `Table attributes are %q.`,
+		`thousands_sep`:            `Thousands separator is %q.`,
 		`time`:                     `Time display is %s.`,
+		`timezone`:                 `Time zone is %q.`,
 		`title`:                    `Title is %q.`,
 		`tuples_only`:              `Tuples only is %s.`,
 		`unicode_border_linestyle`: `Unicode border line style is %q.`,
@@ -76,17 +92,131 @@ var (
 		`unicode_header_linestyle`: `Unicode header line style is %q.`,
 	}
 	FormatFieldNameUnsetMap = map[string]string{
-		`tableattr`: `Table attributes unset.`,
-		`title`:     `Title is unset.`,
+		`expanded_recordsep`: `Expanded record separator is unset.`,
+		`float_precision`:    `Float precision is unset.`,
+		`mask_columns`:       `Masked columns are unset.`,
+		`tableattr`:          `Table attributes unset.`,
+		`thousands_sep`:      `Thousands separator is unset.`,
+		`timezone`:           `Time zone is unset.`,
+		`title`:              `Title is unset.`,
 	}
 	TimingSet            = `Timing is %s.`
+	UnmaskSet            = `Unmasked display is %s.`
+	InvalidExpression    = `invalid expression: %v`
+	ExpressionFailed     = `expression evaluation failed: %v`
+	DestructiveDesc      = `%s is a destructive statement affecting:`
+	DestructiveNoWhere   = `%s without a WHERE clause affects all rows of:`
+	DestructiveObject    = `  %s (%s)`
+	DestructiveRow       = `~1 row`
+	DestructiveRows      = `~%d rows`
+	DestructiveConfirm   = `Type %s to execute the statement: `
+	ResultSetDesc        = `Result set %d:`
 	TimingDesc           = `Time: %0.3f ms`
+	TimingPhasesDesc     = `[parse %0.3f ms, connect %0.3f ms, execute %0.3f ms, fetch %0.3f ms, render %0.3f ms]`
+	TimingStatsDesc      = `Statements: %d`
+	TimingNoStats        = `No statements have been timed.`
 	InvalidValue         = `invalid -%s value %q: %s`
 	NotSupportedByDriver = `%s not supported by %s driver`
 	RelationNotFound     = `Did not find any relation named "%s".`
+	DependencyNotFound   = `Did not find any dependencies of an object named "%s".`
 	InvalidOption        = `invalid option %q`
 	NotificationReceived = `Asynchronous notification %q %sreceived from server process with PID %d.`
 	NotificationPayload  = `with payload %q `
+	KrbTicketCache       = `Ticket cache: FILE:%s`
+	KrbDefaultPrincipal  = `Default principal: %s`
+	KrbNoTickets         = `No Kerberos tickets found in %s.`
+	DiffDuplicateKey     = `\diff: duplicate key (%s), use a unique set of key columns`
+	DiffUnknownColumn    = `\diff: key column %q not found`
+	DiffSummary          = `DIFF %d added, %d removed, %d changed, %d unchanged`
+	ChartInvalidType     = `invalid chart type %q, allowed types are bar, line, sparkline`
+	ChartInvalidValue    = `invalid value %q in chart column %q, must be numeric`
+	ChartUnknownColumn   = `chart column %q not found`
+	ChartNoRows          = `(0 rows)`
+	PsetFileInvalidEntry = `invalid entry at line %d`
+	ProgressDesc         = `%d rows, %s written, %s elapsed, %0.0f rows/s`
+	ProgressReadDesc     = `%s read, %s elapsed, %s/s`
+	ProgressCopyDesc     = `%d rows copied, %s elapsed, %0.0f rows/s`
+	ERDInvalidFormat     = `invalid diagram format %q, allowed formats are dot, mermaid`
+	RunSummary           = `RUN %d executed, %d failed, %d retried`
+	CacheSet             = `Result cache is %s.`
+	CacheCleared         = `Result cache cleared.`
+	CacheStatsDesc       = `Result cache is %s (ttl %s): %d entries, %d rows, %d hits, %d misses`
+	CachedDesc           = `(cached)`
+	RowEstimateDesc      = `(%d rows estimated)`
+	QueryIDDesc          = `(query id %s)`
+	ImplicitLimitDesc    = `(limited to %d rows by IMPLICIT_LIMIT)`
+	CountDesc            = `(%d rows)`
+	NoQueryID            = `No server query id is available.`
+	CacheInvalidTTL      = `invalid cache time to live %q, must be a positive duration`
+	ServeListening       = `listening on %s`
+	ServeInvalidConn     = `invalid connection %q, expected NAME=DSN`
+	TruncatedDesc        = `Values truncated to %d characters: %s`
+	TruncatedColumn      = `%q (longest %d bytes)`
+	StoreInvalidName     = `invalid stored result name %q`
+	StoreSaved           = `Stored %d rows as ::%s.`
+	StoreNone            = `No results are stored.`
+	StoreDesc            = `::%s (%d rows): %s`
+	ResultTruncated      = `warning: only the first %d rows of the result were recorded`
+	XJoinSourceFailed    = `source %s: %w`
+	XJoinInvalidSource   = `invalid source %q, expected NAME=[URL]`
+	XJoinTooManyRows     = `more than %d rows, use --max-rows to raise the limit`
+	QueryLogNotFound     = `query %s not found in the query log`
+	QueryLogInvalidSize  = `invalid number of queries %q`
+	JoinNotFound         = `Did not find any foreign keys for table "%s".`
+	CopyInPrompt         = "Enter data to be copied followed by a newline.\nEnd with a backslash and a period on a line by itself, or an EOF signal."
+	CopyInLinePrompt     = `>> `
+	BindMissingParameter = `no value bound for parameter $%d (%d bound)`
+	PreparedExists       = `prepared statement %q already exists`
+	PreparedNotFound     = `prepared statement %q does not exist`
+	PassInvalidEntry     = `invalid entry %q, expected PROTOCOL:HOST:PORT:DBNAME:USERNAME[:PASSWORD]`
+	PassNotFound         = `password entry %q not found`
+	SeedInvalidSpec      = `invalid seed spec %q for column %q`
+	SeedUnknownColumn    = `seed column %q not found`
+	SeedUserDefined      = `column %q has a user-defined type, specify its generator, such as %s=enum:A,B`
+	SeedSummary          = `INSERT 0 %d`
+	Reconnected          = `The connection to the server was lost. Reconnected to %s (%d session settings restored).`
+	ReconnectNotRetried  = `reconnected, the statement was not executed again, as it may have been executed before the connection was lost`
+	HelpAvailable        = `Available help:`
+	HelpNoTopic          = "No help available for %q.\nTry \\h with no arguments to see available help."
+	HelpSuggestTopics    = `No help available for %q, did you mean:`
+	NoResultColumns      = `The command has no result, or the result has no columns.`
+	InvalidObjectURL     = `invalid object storage url %q, expected s3://BUCKET/KEY, gs://BUCKET/OBJECT, or azblob://CONTAINER/BLOB`
+	InvalidKafkaURL      = `invalid kafka url %q, expected kafka://BROKER[:PORT]/TOPIC`
+	KafkaInvalidFormat   = `invalid kafka format %q, allowed formats are json, avro`
+	KafkaKeyNotFound     = `kafka key column %q not found`
+	KafkaError           = `kafka: %s (error code %d)`
+	KafkaBadResponse     = `kafka: unexpected response correlation id %d, expected %d`
+	KafkaAvroValue       = `value %v of column %q can not be encoded as avro %s`
+	InvalidSleepDuration = `invalid sleep duration %q`
+	InvalidSampleSize    = `invalid sample size %q`
+	CommentInvalidColumn = `invalid column %q, must be qualified by its table`
+	ResultUnknownColumn  = `column %q not found in the result`
+	ResultAmbiguous      = `column %q is ambiguous in the result`
+	ResultInvalidSort    = `invalid sort %q, expected COLUMN [ASC|DESC]`
+	RouteStatusDesc      = `Primary: %s, replica: %s, routing: %s, last statement routed to: %s`
+	RouteNone            = `(none)`
+	RouteConnected       = `Read replica connected, routing read only statements to it.`
+	RouteDisconnected    = `Read replica disconnected, routing all statements to the primary.`
+	RouteSet             = `Statement routing is %s.`
+	RouteInvalid         = `invalid route %q, allowed routes are auto, primary, replica`
+	RouteDriverMismatch  = `read replica driver %q does not match the primary driver %q`
+	MigrateApplied       = `Applied migration %d_%s.`
+	MigrateReverted      = `Reverted migration %d_%s.`
+	MigrateUpToDate      = `No pending migrations.`
+	MigrateNoneApplied   = `No applied migrations to revert.`
+	MigrateInvalidAction = `invalid migrate action %q, allowed actions are up, down, status`
+	MigrateBadVersion    = `invalid migration version of %q`
+	MigrateBadSteps      = `invalid number of migrations %q`
+	MigrateDuplicate     = `more than one migration with version %d`
+	MigrateNoUp          = `migration %d_%s has no up file`
+	MigrateNoDown        = `migration %d_%s has no down file, and can not be reverted`
+	MigrateChanged       = `migration %d_%s was changed after it was applied`
+	FunctionNotFound     = `function %q does not exist`
+	FunctionNotUnique    = `more than one function named %q`
+	FunctionNoSource     = `source of function %q is not available`
+	CopyToSummary        = `COPY %d`
+	CopyToInvalidFormat  = `invalid copy format %q, allowed formats are csv, text, json`
+	TeeInvalidFormat     = `invalid tee format %q, allowed formats are aligned, unaligned, csv, json, html, asciidoc, latex, latex-longtable, troff-ms, vertical`
 )
 
 func init() {
@@ -132,3 +262,113 @@ Arguments:
 Options:
 {{.Context.Flags|FlagsToTwoColumns|FormatTwoColumns}}{{end}}`
 }
+
+// ServeUsageTemplate returns the serve usage template.
+var ServeUsageTemplate = func() string {
+	n := CommandLower()
+	return n + ` serve, ` + Banner + `
+
+Usage:
+  ` + n + ` serve [OPTIONS]... NAME=DSN...
+
+Arguments:
+  NAME=DSN                       named database url
+
+Endpoints:
+  GET  /connections              list connection names and drivers
+  POST /query                    execute {"connection", "sql", "format", "params"}
+
+{{if .Context.Flags}}\
+Options:
+{{.Context.Flags|FlagsToTwoColumns|FormatTwoColumns}}{{end}}`
+}
+
+// BashCompletionScript returns the bash completion script, completing
+// flags with --completion-bash, and database urls with __complete.
+var BashCompletionScript = func() string {
+	n := CommandLower()
+	return `_` + n + `() {
+    local word="${COMP_WORDS[COMP_CWORD]}" line="${COMP_LINE:0:$COMP_POINT}"
+    local cur="${line##*[[:space:]]}" IFS=$'\n'
+    if [[ $cur == -* ]]; then
+        COMPREPLY=( $(compgen -W "$(${COMP_WORDS[0]} --completion-bash "${COMP_WORDS[@]:1:$COMP_CWORD}")" -- "$word") )
+        return 0
+    fi
+    # bash splits words at colons, so complete the whole word, and remove
+    # the part before the word bash replaces
+    local prefix="${cur%"$word"}"
+    COMPREPLY=( $(${COMP_WORDS[0]} __complete "$cur") )
+    COMPREPLY=( "${COMPREPLY[@]#"$prefix"}" )
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]]; then
+        compopt -o nospace
+    fi
+    return 0
+}
+complete -F _` + n + ` -o default ` + n + `
+`
+}
+
+// ZshCompletionScript returns the zsh completion script, completing flags
+// with --completion-bash, and database urls with __complete.
+var ZshCompletionScript = func() string {
+	n := CommandLower()
+	return `#compdef ` + n + `
+
+_` + n + `() {
+    local -a matches dirs
+    if [[ $words[CURRENT] == -* ]]; then
+        matches=(${(f)"$(${words[1]} --completion-bash "${(@)words[2,$CURRENT]}")"})
+        compadd -a matches
+        return
+    fi
+    matches=(${(f)"$(${words[1]} __complete "$words[CURRENT]")"})
+    dirs=(${(M)matches:#*/})
+    matches=(${matches:#*/})
+    compadd -Q -S '' -a dirs
+    compadd -Q -a matches
+    if [[ $compstate[nmatches] -eq 0 ]]; then
+        _files
+    fi
+}
+
+if [[ "$(basename -- ${(%):-%x})" != "_` + n + `" ]]; then
+    compdef _` + n + ` ` + n + `
+fi
+`
+}
+
+// FishCompletionScript returns the fish completion script, completing flags
+// with --completion-bash, and database urls with __complete.
+var FishCompletionScript = func() string {
+	n := CommandLower()
+	return `function __` + n + `_complete
+    set -l cur (commandline -ct)
+    if string match -q -- '-*' $cur
+        ` + n + ` --completion-bash (commandline -cop)[2..-1] $cur
+    else
+        ` + n + ` __complete $cur
+    end
+end
+
+complete -c ` + n + ` -a '(__` + n + `_complete)'
+`
+}
+
+// MigrateUsageTemplate returns the migrate usage template.
+var MigrateUsageTemplate = func() string {
+	n := CommandLower()
+	return n + ` migrate, ` + Banner + `
+
+Usage:
+  ` + n + ` migrate [OPTIONS]... DSN [up|down|status] [N]
+
+Arguments:
+  DSN                            database url
+  up [N]                         apply all, or the next N, pending migrations (default)
+  down [N]                       revert the last applied, or the last N, migrations
+  status                         list the migrations and their status
+
+{{if .Context.Flags}}\
+Options:
+{{.Context.Flags|FlagsToTwoColumns|FormatTwoColumns}}{{end}}`
+}
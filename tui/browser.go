@@ -0,0 +1,400 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	runewidth "github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// maxColumnWidth is the maximum display width of a column.
+const maxColumnWidth = 40
+
+// helpText is the key help displayed in the status line.
+const helpText = "q quit  / filter  s sort  x hide  X show  space select  e export"
+
+// Browser is an interactive terminal browser of a result, supporting
+// scrolling, sorting by column, filtering rows, hiding columns, and exporting
+// the selected rows.
+type Browser struct {
+	v      *view
+	export ExportFunc
+	in     *os.File
+	out    *os.File
+	// widths are the display widths of the columns.
+	widths []int
+	// row and col are the cursor position in the rows and visible columns of
+	// the view.
+	row, col int
+	// top and left are the first displayed row and visible column.
+	top, left int
+	// width and height are the terminal size.
+	width, height int
+	// status is the status message displayed until the next key.
+	status string
+	// pending are the bytes read but not yet handled as keys.
+	pending []byte
+}
+
+// Option is a browser option.
+type Option func(*Browser)
+
+// WithExport is a browser option to set the func exporting the selection.
+func WithExport(export ExportFunc) Option {
+	return func(b *Browser) {
+		b.export = export
+	}
+}
+
+// WithTerminal is a browser option to set the terminal's input and output.
+func WithTerminal(in, out *os.File) Option {
+	return func(b *Browser) {
+		b.in, b.out = in, out
+	}
+}
+
+// New creates a new browser for the result.
+func New(res Result, opts ...Option) *Browser {
+	b := &Browser{
+		v:   newView(res),
+		in:  os.Stdin,
+		out: os.Stdout,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	b.widths = make([]int, len(res.Columns))
+	for i, c := range res.Columns {
+		// room for the sort indicator
+		b.widths[i] = runewidth.StringWidth(c) + 2
+	}
+	for _, row := range res.Rows {
+		for i, s := range row {
+			b.widths[i] = max(b.widths[i], runewidth.StringWidth(clean(s)))
+		}
+	}
+	for i := range b.widths {
+		b.widths[i] = min(max(b.widths[i], 1), maxColumnWidth)
+	}
+	return b
+}
+
+// Run runs the browser until the user quits.
+func (b *Browser) Run() error {
+	state, err := term.MakeRaw(int(b.in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(b.in.Fd()), state)
+	// use the alternate screen, and hide the cursor
+	fmt.Fprint(b.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(b.out, "\x1b[?25h\x1b[?1049l")
+	for {
+		b.draw(b.status)
+		b.status = ""
+		k, _, err := b.readKey()
+		if err != nil {
+			return err
+		}
+		if !b.handle(k) {
+			return nil
+		}
+	}
+}
+
+// Keys that are not a single rune.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEscape    = "esc"
+	keyEnter     = "enter"
+	keyBackspace = "backspace"
+)
+
+// escapeKeys are the keys of escape sequences.
+var escapeKeys = map[string]string{
+	"\x1b[A": keyUp, "\x1bOA": keyUp,
+	"\x1b[B": keyDown, "\x1bOB": keyDown,
+	"\x1b[C": keyRight, "\x1bOC": keyRight,
+	"\x1b[D": keyLeft, "\x1bOD": keyLeft,
+	"\x1b[5~": keyPageUp, "\x1b[6~": keyPageDown,
+	"\x1b[H": keyHome, "\x1bOH": keyHome, "\x1b[1~": keyHome, "\x1b[7~": keyHome,
+	"\x1b[F": keyEnd, "\x1bOF": keyEnd, "\x1b[4~": keyEnd, "\x1b[8~": keyEnd,
+	"\x1b": keyEscape,
+}
+
+// readKey reads the next key, returning the name of the key, or the key's
+// rune.
+func (b *Browser) readKey() (string, rune, error) {
+	if len(b.pending) == 0 {
+		buf := make([]byte, 256)
+		n, err := b.in.Read(buf)
+		if err != nil {
+			return "", 0, err
+		}
+		b.pending = buf[:n]
+	}
+	if b.pending[0] == 0x1b {
+		// match the longest escape sequence
+		var key, seq string
+		for s, k := range escapeKeys {
+			if len(s) > len(seq) && bytes.HasPrefix(b.pending, []byte(s)) {
+				key, seq = k, s
+			}
+		}
+		if key == keyEscape && len(b.pending) != 1 {
+			// unknown escape sequence
+			key, seq = "", string(b.pending)
+		}
+		b.pending = b.pending[len(seq):]
+		return key, 0, nil
+	}
+	r, n := utf8.DecodeRune(b.pending)
+	b.pending = b.pending[n:]
+	switch r {
+	case '\r', '\n':
+		return keyEnter, 0, nil
+	case 0x7f, 0x08:
+		return keyBackspace, 0, nil
+	}
+	return string(r), r, nil
+}
+
+// handle handles the key, returning false when the browser quits.
+func (b *Browser) handle(k string) bool {
+	page := max(b.height-2, 1)
+	switch k {
+	case "q", "Q", keyEscape, "\x03":
+		return false
+	case keyUp, "k":
+		b.row--
+	case keyDown, "j":
+		b.row++
+	case keyLeft, "h":
+		b.col--
+	case keyRight, "l":
+		b.col++
+	case keyPageUp, "\x02":
+		b.row -= page
+	case keyPageDown, "\x06":
+		b.row += page
+	case keyHome, "g":
+		b.row = 0
+	case keyEnd, "G":
+		b.row = len(b.v.rows) - 1
+	case "0", "^":
+		b.col = 0
+	case "$":
+		b.col = len(b.v.cols) - 1
+	case "s":
+		if len(b.v.cols) != 0 {
+			b.v.sortBy(b.v.cols[b.col])
+		}
+	case "x":
+		if len(b.v.cols) != 0 {
+			b.v.hide(b.v.cols[b.col])
+		}
+	case "X":
+		b.v.showAll()
+	case "/":
+		if filter, ok := b.prompt("filter: ", b.v.filter); ok {
+			b.v.setFilter(filter)
+			b.row = 0
+		}
+	case " ":
+		if len(b.v.rows) != 0 {
+			b.v.toggle(b.v.rows[b.row])
+			b.row++
+		}
+	case "e":
+		b.exportSelection()
+	}
+	b.row = max(min(b.row, len(b.v.rows)-1), 0)
+	b.col = max(min(b.col, len(b.v.cols)-1), 0)
+	return true
+}
+
+// exportSelection prompts for a file name, and exports the selection.
+func (b *Browser) exportSelection() {
+	if b.export == nil {
+		b.status = "export not available"
+		return
+	}
+	name, ok := b.prompt("export to file: ", "")
+	if !ok || name == "" {
+		return
+	}
+	rows := b.v.selection()
+	if err := b.export(name, append([]int(nil), b.v.cols...), rows); err != nil {
+		b.status = "error: " + err.Error()
+		return
+	}
+	b.status = fmt.Sprintf("exported %d rows to %s", len(rows), name)
+}
+
+// prompt reads a line of text on the status line, returning false when
+// canceled.
+func (b *Browser) prompt(prompt, s string) (string, bool) {
+	r := []rune(s)
+	fmt.Fprint(b.out, "\x1b[?25h")
+	defer fmt.Fprint(b.out, "\x1b[?25l")
+	for {
+		b.draw(prompt + string(r))
+		k, c, err := b.readKey()
+		switch {
+		case err != nil, k == keyEscape, k == "\x03":
+			return "", false
+		case k == keyEnter:
+			return string(r), true
+		case k == keyBackspace:
+			if len(r) != 0 {
+				r = r[:len(r)-1]
+			}
+		case unicode.IsPrint(c):
+			r = append(r, c)
+		}
+	}
+}
+
+// draw draws the header, the rows, and the status line, or the message
+// instead of the status line when not empty.
+func (b *Browser) draw(msg string) {
+	b.width, b.height = 80, 24
+	if w, h, err := term.GetSize(int(b.out.Fd())); err == nil {
+		b.width, b.height = w, h
+	}
+	page := max(b.height-2, 1)
+	// keep the cursor visible
+	switch {
+	case b.row < b.top:
+		b.top = b.row
+	case b.row >= b.top+page:
+		b.top = b.row - page + 1
+	}
+	if b.col < b.left {
+		b.left = b.col
+	}
+	for b.left < b.col && b.columnsWidth(b.left, b.col) > b.width-2 {
+		b.left++
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H")
+	// header
+	buf.WriteString("\x1b[1;7m  ")
+	b.line(&buf, func(i int) string {
+		name := b.v.res.Columns[i]
+		switch {
+		case i == b.v.sortCol && b.v.desc:
+			name += " ▼"
+		case i == b.v.sortCol:
+			name += " ▲"
+		}
+		return name
+	}, b.col)
+	buf.WriteString("\x1b[0m\x1b[K\r\n")
+	// rows
+	for n := 0; n < page; n++ {
+		i := b.top + n
+		if i < len(b.v.rows) {
+			row := b.v.rows[i]
+			if i == b.row {
+				buf.WriteString("\x1b[7m")
+			}
+			if b.v.selected[row] {
+				buf.WriteString("* ")
+			} else {
+				buf.WriteString("  ")
+			}
+			cursor := -1
+			if i == b.row {
+				cursor = b.col
+			}
+			b.line(&buf, func(j int) string {
+				return b.v.res.Rows[row][j]
+			}, cursor)
+			buf.WriteString("\x1b[0m")
+		}
+		buf.WriteString("\x1b[K\r\n")
+	}
+	// status
+	if msg == "" {
+		msg = b.statusLine()
+	}
+	buf.WriteString("\x1b[1m" + runewidth.Truncate(msg, b.width-1, "…") + "\x1b[0m\x1b[K")
+	b.out.Write(buf.Bytes())
+}
+
+// line writes the values of the visible columns starting at the left column,
+// underlining the cursor column, if any.
+func (b *Browser) line(buf *bytes.Buffer, value func(int) string, cursor int) {
+	width := 2
+	for n := b.left; n < len(b.v.cols) && width < b.width; n++ {
+		i := b.v.cols[n]
+		w := min(b.widths[i], b.width-width)
+		s := runewidth.FillRight(runewidth.Truncate(clean(value(i)), w, "…"), w)
+		if n == cursor {
+			s = "\x1b[4m" + s + "\x1b[24m"
+		}
+		buf.WriteString(s)
+		width += w
+		if width < b.width {
+			buf.WriteString(" ")
+			width++
+		}
+	}
+}
+
+// columnsWidth returns the display width of the visible columns from start
+// to end, inclusive.
+func (b *Browser) columnsWidth(start, end int) int {
+	var width int
+	for n := start; n <= end; n++ {
+		width += b.widths[b.v.cols[n]] + 1
+	}
+	return width
+}
+
+// statusLine returns the status line, with the position, sort, filter,
+// selection, and the key help.
+func (b *Browser) statusLine() string {
+	var s []string
+	if len(b.v.rows) == 0 {
+		s = append(s, "no rows")
+	} else {
+		s = append(s, fmt.Sprintf("row %d of %d", b.row+1, len(b.v.rows)))
+	}
+	if n := len(b.v.res.Rows); n != len(b.v.rows) {
+		s[0] += fmt.Sprintf(" (filtered from %d)", n)
+	}
+	if n := len(b.v.res.Columns) - len(b.v.cols); n != 0 {
+		s = append(s, fmt.Sprintf("%d hidden", n))
+	}
+	if b.v.filter != "" {
+		s = append(s, fmt.Sprintf("filter: %q", b.v.filter))
+	}
+	if n := len(b.v.selected); n != 0 {
+		s = append(s, fmt.Sprintf("%d selected", n))
+	}
+	return strings.Join(append(s, helpText), " | ")
+}
+
+// clean replaces control characters, such as newlines and tabs, with spaces.
+func clean(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,165 @@
+// Package tui provides the interactive terminal result browser used by the
+// \browse command and the interactive output format.
+package tui
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result is a buffered result set, with the values of the rows formatted
+// for display.
+type Result struct {
+	// Columns are the column names.
+	Columns []string
+	// Rows are the formatted values of the rows.
+	Rows [][]string
+}
+
+// ExportFunc exports the rows and columns of the result, given as the
+// indexes of the rows and columns of the result, to the named file.
+type ExportFunc func(name string, cols, rows []int) error
+
+// view is the view of a result, after hiding columns, filtering, and sorting
+// rows.
+type view struct {
+	res Result
+	// cols are the visible columns.
+	cols []int
+	// rows are the rows matching the filter, in sort order.
+	rows []int
+	// hidden are the hidden columns.
+	hidden map[int]bool
+	// selected are the selected rows.
+	selected map[int]bool
+	// sortCol is the column rows are sorted by, or -1.
+	sortCol int
+	// desc sorts rows in descending order.
+	desc bool
+	// filter is the filter text rows must contain.
+	filter string
+}
+
+// newView creates a view of the result showing all columns and rows.
+func newView(res Result) *view {
+	v := &view{
+		res:      res,
+		hidden:   make(map[int]bool),
+		selected: make(map[int]bool),
+		sortCol:  -1,
+	}
+	v.update()
+	return v
+}
+
+// update updates the visible columns and rows of the view.
+func (v *view) update() {
+	v.cols = v.cols[:0]
+	for i := range v.res.Columns {
+		if !v.hidden[i] {
+			v.cols = append(v.cols, i)
+		}
+	}
+	v.rows = v.rows[:0]
+	filter := strings.ToLower(v.filter)
+	for i, row := range v.res.Rows {
+		if filter == "" || v.matches(row, filter) {
+			v.rows = append(v.rows, i)
+		}
+	}
+	if v.sortCol == -1 {
+		return
+	}
+	sort.SliceStable(v.rows, func(i, j int) bool {
+		a, b := v.res.Rows[v.rows[i]][v.sortCol], v.res.Rows[v.rows[j]][v.sortCol]
+		if v.desc {
+			a, b = b, a
+		}
+		return less(a, b)
+	})
+}
+
+// matches returns true when a visible column of the row contains the lower
+// case filter text.
+func (v *view) matches(row []string, filter string) bool {
+	for _, i := range v.cols {
+		if strings.Contains(strings.ToLower(row[i]), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// hide hides the column, unless it is the only visible column.
+func (v *view) hide(col int) {
+	if len(v.cols) < 2 {
+		return
+	}
+	v.hidden[col] = true
+	v.update()
+}
+
+// showAll shows all hidden columns.
+func (v *view) showAll() {
+	v.hidden = make(map[int]bool)
+	v.update()
+}
+
+// sortBy sorts the rows by the column, in ascending order, then in
+// descending order, and then in result order when sorted by the same column
+// again.
+func (v *view) sortBy(col int) {
+	switch {
+	case v.sortCol != col:
+		v.sortCol, v.desc = col, false
+	case !v.desc:
+		v.desc = true
+	default:
+		v.sortCol, v.desc = -1, false
+	}
+	v.update()
+}
+
+// setFilter sets the filter text rows must contain (case insensitive) in one
+// of the visible columns.
+func (v *view) setFilter(filter string) {
+	v.filter = filter
+	v.update()
+}
+
+// toggle toggles the selection of the row.
+func (v *view) toggle(row int) {
+	if v.selected[row] {
+		delete(v.selected, row)
+	} else {
+		v.selected[row] = true
+	}
+}
+
+// selection returns the selected rows in view order, or all rows of the view
+// when no rows are selected.
+func (v *view) selection() []int {
+	if len(v.selected) == 0 {
+		return append([]int(nil), v.rows...)
+	}
+	var rows []int
+	for _, i := range v.rows {
+		if v.selected[i] {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// less compares a and b numerically when both are numbers, and otherwise as
+// strings. Empty values sort first.
+func less(a, b string) bool {
+	x, aerr := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, berr := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if aerr == nil && berr == nil && !math.IsNaN(x) && !math.IsNaN(y) {
+		return x < y
+	}
+	return a < b
+}
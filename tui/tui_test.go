@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	v := newView(Result{
+		Columns: []string{"id", "name", "note"},
+		Rows: [][]string{
+			{"10", "bob", ""},
+			{"9", "Alice", "x"},
+			{"100", "carol", "ALICE's"},
+		},
+	})
+	check := func(name string, exp []int) {
+		t.Helper()
+		if !reflect.DeepEqual(v.rows, exp) {
+			t.Errorf("%s: expected rows %v, got: %v", name, exp, v.rows)
+		}
+	}
+	check("initial", []int{0, 1, 2})
+	v.sortBy(0)
+	check("sort asc", []int{1, 0, 2})
+	v.sortBy(0)
+	check("sort desc", []int{2, 0, 1})
+	v.sortBy(0)
+	check("unsorted", []int{0, 1, 2})
+	v.sortBy(1)
+	check("sort strings", []int{1, 0, 2})
+	v.setFilter("alice")
+	check("filter", []int{1, 2})
+	v.hide(2)
+	check("filter hidden", []int{1})
+	if exp := []int{0, 1}; !reflect.DeepEqual(v.cols, exp) {
+		t.Errorf("expected cols %v, got: %v", exp, v.cols)
+	}
+	v.hide(1)
+	v.hide(0)
+	if exp := []int{0}; !reflect.DeepEqual(v.cols, exp) {
+		t.Errorf("expected cols %v, got: %v", exp, v.cols)
+	}
+	v.showAll()
+	v.setFilter("")
+	check("reset", []int{1, 0, 2})
+	if s := v.selection(); !reflect.DeepEqual(s, []int{1, 0, 2}) {
+		t.Errorf("expected all rows selected, got: %v", s)
+	}
+	v.toggle(2)
+	v.toggle(1)
+	v.toggle(0)
+	v.toggle(0)
+	if s := v.selection(); !reflect.DeepEqual(s, []int{1, 2}) {
+		t.Errorf("expected rows [1 2] selected, got: %v", s)
+	}
+}